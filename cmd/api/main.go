@@ -2,14 +2,13 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
-	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
 
@@ -21,6 +20,7 @@ import (
 	"pipo-edu-project/internal/repository"
 	repo "pipo-edu-project/internal/repository/sqlc"
 	"pipo-edu-project/internal/service"
+	"pipo-edu-project/internal/storage"
 )
 
 func main() {
@@ -42,35 +42,36 @@ func main() {
 	}
 	defer db.Close()
 
+	settings, err := service.SettingsFromConfig(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid settings")
+	}
+
+	files, err := storage.NewLocal(cfg.FileStorageDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("file storage init failed")
+	}
+
 	queries := repo.New(db)
-	svc := service.New(queries)
+	svc := service.New(queries,
+		service.WithSettings(settings),
+		service.WithTxRunner(service.NewTxRunner(db,
+			service.TxIsolation(cfg.TxIsolation),
+			service.TxAttempts(cfg.TxAttempts),
+		)),
+		service.WithFileStore(files),
+	)
 
 	if cfg.BootstrapEmail != "" && cfg.BootstrapPassword != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		var count int
-		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count); err != nil {
-			log.Error().Err(err).Msg("bootstrap check failed")
-		} else if count == 0 {
-			hash, err := auth.HashPassword(cfg.BootstrapPassword)
-			if err != nil {
-				log.Error().Err(err).Msg("bootstrap hash failed")
-			} else {
-				_, err := queries.CreateUser(ctx, repo.CreateUserParams{
-					Email:        cfg.BootstrapEmail,
-					PasswordHash: hash,
-					Role:         string(auth.RoleAdmin),
-					FullName:     cfg.BootstrapName,
-					PlotNumber:   sql.NullString{},
-					CreatedBy:    uuid.NullUUID{},
-					UpdatedBy:    uuid.NullUUID{},
-				})
-				if err != nil {
-					log.Error().Err(err).Msg("bootstrap user create failed")
-				} else {
-					log.Info().Msg("bootstrap admin created")
-				}
-			}
+		hash, err := auth.HashPassword(cfg.BootstrapPassword)
+		if err != nil {
+			log.Error().Err(err).Msg("bootstrap hash failed")
+		} else if created, err := svc.BootstrapAdmin(ctx, cfg.BootstrapEmail, cfg.BootstrapName, hash); err != nil {
+			log.Error().Err(err).Msg("bootstrap user create failed")
+		} else if created {
+			log.Info().Msg("bootstrap admin created")
 		}
 	}
 
@@ -97,6 +98,15 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.GuestExpiryEvery > 0 {
+		go runGuestExpiry(jobsCtx, svc, cfg.GuestExpiryEvery)
+	}
+	if cfg.PhotoRetentionDays > 0 && cfg.PhotoPurgeEvery > 0 {
+		go runPhotoPurge(jobsCtx, svc, cfg.PhotoPurgeEvery)
+	}
+
 	go func() {
 		log.Info().Str("addr", cfg.HTTPAddr).Msg("server started")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -108,6 +118,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info().Msg("shutdown initiated")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -115,3 +126,41 @@ func main() {
 		log.Error().Err(err).Msg("shutdown error")
 	}
 }
+
+// runGuestExpiry periodically closes guest requests whose window has passed.
+func runGuestExpiry(ctx context.Context, svc *service.Service, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		expired, err := svc.ExpireGuestRequests(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("guest expiry failed")
+		} else if expired > 0 {
+			log.Info().Int64("expired", expired).Msg("guest requests expired")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runPhotoPurge periodically deletes gate photos past their retention.
+func runPhotoPurge(ctx context.Context, svc *service.Service, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		purged, err := svc.PurgeEntryPhotos(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("photo purge failed")
+		} else if purged > 0 {
+			log.Info().Int64("purged", purged).Msg("entry photos purged")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
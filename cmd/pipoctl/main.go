@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/config"
+	"pipo-edu-project/internal/repository"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+	"pipo-edu-project/internal/tabular"
+)
+
+const usage = `Usage: pipoctl <command> [flags]
+
+Commands:
+  import              import users and passes from a CSV or XLSX file
+  verify-entry-logs   check the entry log hash chain and report the first broken record
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = runImport(os.Args[2:], os.Stdout)
+	case "verify-entry-logs":
+		err = runVerifyEntryLogs(os.Args[2:], os.Stdout)
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func openService() (*service.Service, *repo.Queries, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	settings, err := service.SettingsFromConfig(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	db, err := repository.Open(cfg.DBDSN)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	queries := repo.New(db)
+	svc := service.New(queries,
+		service.WithSettings(settings),
+		service.WithTxRunner(service.NewTxRunner(db,
+			service.TxIsolation(cfg.TxIsolation),
+			service.TxAttempts(cfg.TxAttempts),
+		)),
+	)
+	return svc, queries, func() { _ = db.Close() }, nil
+}
+
+func runImport(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the file without writing anything")
+	actorEmail := fs.String("actor", "", "email of the admin recorded as created_by/updated_by")
+	restore := fs.Bool("restore", false, "bring back deleted users whose email is in the file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pipoctl import [-dry-run] [-restore] [-actor email] <file.csv|file.xlsx>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("exactly one file is required")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	records, err := tabular.Read(data)
+	if err != nil {
+		return err
+	}
+	rows, err := service.ParseImportRows(records)
+	if err != nil {
+		return err
+	}
+
+	svc, queries, closeDB, err := openService()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	actorID := uuid.Nil
+	if *actorEmail != "" {
+		actor, err := queries.GetUserByEmail(ctx, *actorEmail)
+		if err != nil {
+			return fmt.Errorf("actor %s: %w", *actorEmail, err)
+		}
+		actorID = actor.ID
+	}
+
+	report, err := svc.ImportUsers(ctx, rows, service.ImportOptions{DryRun: *dryRun, ActorID: actorID, Restore: *restore})
+	if err != nil {
+		return err
+	}
+	printImportReport(out, report)
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d row errors", len(report.Errors))
+	}
+	return nil
+}
+
+func printImportReport(out io.Writer, report service.ImportReport) {
+	fmt.Fprintf(out, "rows: %d\n", report.Rows)
+	fmt.Fprintf(out, "users: %d created, %d updated, %d restored\n", report.UsersCreated, report.UsersUpdated, report.UsersRestored)
+	fmt.Fprintf(out, "passes: %d created, %d updated\n", report.PassesCreated, report.PassesUpdated)
+	for _, rowErr := range report.Errors {
+		fmt.Fprintf(out, "line %d, %s: %s\n", rowErr.Line, rowErr.Field, rowErr.Message)
+	}
+	switch {
+	case report.Applied:
+		fmt.Fprintln(out, "import applied")
+	case report.DryRun:
+		fmt.Fprintln(out, "dry run: nothing was written")
+	default:
+		fmt.Fprintln(out, "import rejected: nothing was written")
+	}
+}
+
+func runVerifyEntryLogs(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify-entry-logs", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pipoctl verify-entry-logs")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	svc, _, closeDB, err := openService()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	report, err := svc.VerifyEntryLogChain(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "checked: %d records\n", report.Checked)
+	if report.Break != nil {
+		fmt.Fprintf(out, "last valid: seq %d, hash %s\n", report.HeadSeq, report.HeadHash)
+		return fmt.Errorf("chain broken at seq %d (id %s): %s", report.Break.Seq, report.Break.ID, report.Break.Reason)
+	}
+	fmt.Fprintf(out, "head: seq %d, hash %s\n", report.HeadSeq, report.HeadHash)
+	fmt.Fprintln(out, "chain intact")
+	return nil
+}
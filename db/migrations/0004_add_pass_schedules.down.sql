@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pass_schedules_pass_id;
+DROP TABLE IF EXISTS holidays;
+DROP TABLE IF EXISTS pass_schedules;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS pass_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pass_id UUID NOT NULL REFERENCES passes(id) ON DELETE CASCADE,
+    weekdays SMALLINT NOT NULL CHECK (weekdays BETWEEN 1 AND 127),
+    start_minute SMALLINT NOT NULL CHECK (start_minute BETWEEN 0 AND 1439),
+    end_minute SMALLINT NOT NULL CHECK (end_minute BETWEEN 1 AND 1440),
+    skip_holidays BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (start_minute <> end_minute)
+);
+
+CREATE TABLE IF NOT EXISTS holidays (
+    day DATE PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pass_schedules_pass_id ON pass_schedules (pass_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_watchlist_hits_watchlist_id;
+DROP INDEX IF EXISTS idx_plate_watchlist_plate_active;
+DROP TABLE IF EXISTS watchlist_hits;
+DROP TABLE IF EXISTS plate_watchlist;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS plate_watchlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    plate_number TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('info', 'warning', 'blacklist')),
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_hits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    watchlist_id UUID NOT NULL REFERENCES plate_watchlist(id) ON DELETE CASCADE,
+    plate_number TEXT NOT NULL,
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE SET NULL,
+    user_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    source TEXT NOT NULL CHECK (source IN ('search', 'entry', 'exit')),
+    outcome TEXT NOT NULL CHECK (outcome IN ('flagged', 'blocked', 'overridden')),
+    comment TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plate_watchlist_plate_active ON plate_watchlist (plate_number) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_watchlist_hits_watchlist_id ON watchlist_hits (watchlist_id, created_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_guest_requests_status_valid_to;
+
+ALTER TABLE guest_requests
+    DROP CONSTRAINT IF EXISTS guest_requests_status_check,
+    DROP COLUMN IF EXISTS review_reason,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by;
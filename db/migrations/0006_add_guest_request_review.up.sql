@@ -0,0 +1,12 @@
+UPDATE guest_requests
+SET status = 'pending'
+WHERE status NOT IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'completed');
+
+ALTER TABLE guest_requests
+    ADD COLUMN IF NOT EXISTS reviewed_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS review_reason TEXT NULL,
+    ADD CONSTRAINT guest_requests_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'completed'));
+
+CREATE INDEX IF NOT EXISTS idx_guest_requests_status_valid_to ON guest_requests (status, valid_to);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_entry_logs_guest_request_id;
+
+DELETE FROM entry_logs WHERE pass_id IS NULL;
+
+ALTER TABLE entry_logs
+    DROP CONSTRAINT IF EXISTS entry_logs_subject_check,
+    DROP COLUMN IF EXISTS guest_request_id,
+    ALTER COLUMN pass_id SET NOT NULL;
+
+UPDATE guest_requests SET status = 'completed' WHERE status = 'arrived';
+
+ALTER TABLE guest_requests
+    DROP CONSTRAINT IF EXISTS guest_requests_status_check,
+    ADD CONSTRAINT guest_requests_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'completed'));
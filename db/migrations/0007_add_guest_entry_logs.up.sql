@@ -0,0 +1,11 @@
+ALTER TABLE guest_requests
+    DROP CONSTRAINT IF EXISTS guest_requests_status_check,
+    ADD CONSTRAINT guest_requests_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'arrived', 'completed'));
+
+ALTER TABLE entry_logs
+    ALTER COLUMN pass_id DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE CASCADE,
+    ADD CONSTRAINT entry_logs_subject_check CHECK (num_nonnulls(pass_id, guest_request_id) = 1);
+
+CREATE INDEX IF NOT EXISTS idx_entry_logs_guest_request_id ON entry_logs (guest_request_id, action_at);
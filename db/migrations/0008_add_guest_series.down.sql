@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_guest_series_resident;
+DROP INDEX IF EXISTS idx_guest_series_status_dates;
+DROP INDEX IF EXISTS idx_guest_requests_series_occurrence;
+
+ALTER TABLE guest_requests
+    DROP COLUMN IF EXISTS occurrence_date,
+    DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS guest_series;
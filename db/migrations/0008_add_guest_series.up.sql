@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS guest_series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resident_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    guest_full_name TEXT NOT NULL,
+    plate_number TEXT NOT NULL,
+    rrule TEXT NOT NULL,
+    starts_on DATE NOT NULL,
+    ends_on DATE NULL,
+    start_minute SMALLINT NOT NULL CHECK (start_minute BETWEEN 0 AND 1439),
+    end_minute SMALLINT NOT NULL CHECK (end_minute BETWEEN 1 AND 1440),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    previous_series_id UUID NULL REFERENCES guest_series(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ NULL,
+    review_reason TEXT NULL,
+    CHECK (start_minute <> end_minute),
+    CHECK (ends_on IS NULL OR ends_on >= starts_on)
+);
+
+ALTER TABLE guest_requests
+    ADD COLUMN IF NOT EXISTS series_id UUID NULL REFERENCES guest_series(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS occurrence_date DATE NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guest_requests_series_occurrence ON guest_requests (series_id, occurrence_date);
+CREATE INDEX IF NOT EXISTS idx_guest_series_status_dates ON guest_series (status, starts_on, ends_on);
+CREATE INDEX IF NOT EXISTS idx_guest_series_resident ON guest_series (resident_user_id, created_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_guest_requests_plate_window;
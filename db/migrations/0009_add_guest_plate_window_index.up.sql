@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_guest_requests_plate_window ON guest_requests (plate_number, valid_from, valid_to);
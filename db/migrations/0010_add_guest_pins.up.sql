@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS guest_pins (
+    guest_request_id UUID PRIMARY KEY REFERENCES guest_requests(id) ON DELETE CASCADE,
+    code TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    used_at TIMESTAMPTZ NULL
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_guest_requests_type_window;
+
+ALTER TABLE guest_requests
+    DROP COLUMN IF EXISTS company_name,
+    DROP COLUMN IF EXISTS guest_type;
@@ -0,0 +1,6 @@
+ALTER TABLE guest_requests
+    ADD COLUMN IF NOT EXISTS guest_type TEXT NOT NULL DEFAULT 'vehicle'
+        CHECK (guest_type IN ('vehicle', 'pedestrian', 'taxi', 'delivery', 'service')),
+    ADD COLUMN IF NOT EXISTS company_name TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_guest_requests_type_window ON guest_requests (guest_type, valid_from);
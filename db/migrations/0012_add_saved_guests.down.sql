@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_guest_requests_resident_valid_from;
+DROP INDEX IF EXISTS idx_saved_guests_resident;
+
+DROP TABLE IF EXISTS saved_guests;
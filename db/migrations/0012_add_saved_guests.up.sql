@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS saved_guests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resident_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    guest_full_name TEXT NOT NULL,
+    guest_type TEXT NOT NULL DEFAULT 'vehicle'
+        CHECK (guest_type IN ('vehicle', 'pedestrian', 'taxi', 'delivery', 'service')),
+    plate_number TEXT NOT NULL DEFAULT '',
+    company_name TEXT NULL,
+    notes TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_guests_resident ON saved_guests (resident_user_id, guest_full_name);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_resident_valid_from ON guest_requests (resident_user_id, valid_from);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_entry_logs_guard_user_id;
+DROP INDEX IF EXISTS idx_entry_logs_pass_id;
+DROP INDEX IF EXISTS idx_entry_logs_action_at;
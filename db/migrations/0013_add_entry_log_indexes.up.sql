@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_entry_logs_action_at ON entry_logs (action_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_pass_id ON entry_logs (pass_id, action_at DESC);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_guard_user_id ON entry_logs (guard_user_id, action_at DESC);
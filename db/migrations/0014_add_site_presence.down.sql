@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_site_presence_entered_at;
+DROP INDEX IF EXISTS idx_site_presence_guest_request_id;
+DROP INDEX IF EXISTS idx_site_presence_pass_id;
+
+DROP TABLE IF EXISTS site_presence;
+
+ALTER TABLE entry_logs
+    DROP COLUMN IF EXISTS override_reason,
+    DROP COLUMN IF EXISTS anomaly;
@@ -0,0 +1,35 @@
+ALTER TABLE entry_logs
+    ADD COLUMN IF NOT EXISTS anomaly TEXT NULL CHECK (anomaly IN ('double_entry', 'exit_without_entry')),
+    ADD COLUMN IF NOT EXISTS override_reason TEXT NULL;
+
+CREATE TABLE IF NOT EXISTS site_presence (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE CASCADE,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE CASCADE,
+    entry_log_id UUID NOT NULL REFERENCES entry_logs(id) ON DELETE CASCADE,
+    entered_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT site_presence_subject_check CHECK (num_nonnulls(pass_id, guest_request_id) = 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_site_presence_pass_id ON site_presence (pass_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_site_presence_guest_request_id ON site_presence (guest_request_id);
+CREATE INDEX IF NOT EXISTS idx_site_presence_entered_at ON site_presence (entered_at);
+
+-- Vehicles whose last journal record is an entry are on site.
+INSERT INTO site_presence (pass_id, entry_log_id, entered_at)
+SELECT last.pass_id, last.id, last.action_at
+FROM (
+    SELECT DISTINCT ON (pass_id) pass_id, id, action, action_at
+    FROM entry_logs
+    WHERE pass_id IS NOT NULL
+    ORDER BY pass_id, action_at DESC, id DESC
+) last
+JOIN passes p ON p.id = last.pass_id
+WHERE last.action = 'entry' AND p.deleted_at IS NULL;
+
+INSERT INTO site_presence (guest_request_id, entry_log_id, entered_at)
+SELECT DISTINCT ON (e.guest_request_id) e.guest_request_id, e.id, e.action_at
+FROM entry_logs e
+JOIN guest_requests g ON g.id = e.guest_request_id
+WHERE g.status = 'arrived' AND e.action = 'entry'
+ORDER BY e.guest_request_id, e.action_at DESC, e.id DESC;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_entry_logs_amends_id;
+DROP INDEX IF EXISTS idx_entry_logs_seq;
+
+DELETE FROM entry_logs WHERE amends_id IS NOT NULL;
+
+ALTER TABLE entry_logs
+    ALTER COLUMN action_at SET DEFAULT now(),
+    DROP COLUMN IF EXISTS corrected_action_at,
+    DROP COLUMN IF EXISTS corrected_action,
+    DROP COLUMN IF EXISTS amends_id,
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS seq;
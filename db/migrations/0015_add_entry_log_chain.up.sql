@@ -0,0 +1,45 @@
+ALTER TABLE entry_logs
+    ADD COLUMN IF NOT EXISTS seq BIGINT NULL,
+    ADD COLUMN IF NOT EXISTS prev_hash TEXT NULL,
+    ADD COLUMN IF NOT EXISTS hash TEXT NULL,
+    ADD COLUMN IF NOT EXISTS amends_id UUID NULL REFERENCES entry_logs(id),
+    ADD COLUMN IF NOT EXISTS corrected_action TEXT NULL CHECK (corrected_action IN ('entry', 'exit', 'void')),
+    ADD COLUMN IF NOT EXISTS corrected_action_at TIMESTAMPTZ NULL;
+
+-- Seal the existing journal in time order. The canonical form must match
+-- entryLogCanonical in internal/service/entry_log_chain.go: one
+-- "key:byte length:value" line per field, NULL fields left out.
+DO $$
+DECLARE
+    r RECORD;
+    n BIGINT := 0;
+    prev TEXT := repeat('0', 64);
+    body TEXT;
+    h TEXT;
+BEGIN
+    FOR r IN SELECT * FROM entry_logs ORDER BY action_at, id LOOP
+        n := n + 1;
+        body := 'seq:' || octet_length(n::text) || ':' || n || E'\n'
+            || 'id:36:' || r.id || E'\n'
+            || COALESCE('pass_id:36:' || r.pass_id || E'\n', '')
+            || COALESCE('guest_request_id:36:' || r.guest_request_id || E'\n', '')
+            || 'guard_user_id:36:' || r.guard_user_id || E'\n'
+            || 'action:' || octet_length(r.action) || ':' || r.action || E'\n'
+            || 'action_at:27:' || to_char(r.action_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"') || E'\n'
+            || COALESCE('comment:' || octet_length(r.comment) || ':' || r.comment || E'\n', '')
+            || COALESCE('anomaly:' || octet_length(r.anomaly) || ':' || r.anomaly || E'\n', '')
+            || COALESCE('override_reason:' || octet_length(r.override_reason) || ':' || r.override_reason || E'\n', '');
+        h := encode(sha256(convert_to(prev || E'\n' || body, 'UTF8')), 'hex');
+        UPDATE entry_logs SET seq = n, prev_hash = prev, hash = h WHERE id = r.id;
+        prev := h;
+    END LOOP;
+END $$;
+
+ALTER TABLE entry_logs
+    ALTER COLUMN seq SET NOT NULL,
+    ALTER COLUMN prev_hash SET NOT NULL,
+    ALTER COLUMN hash SET NOT NULL,
+    ALTER COLUMN action_at DROP DEFAULT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entry_logs_seq ON entry_logs (seq);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_amends_id ON entry_logs (amends_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_entry_logs_gate_id;
+
+ALTER TABLE entry_logs
+    DROP COLUMN IF EXISTS gate_id;
+
+DROP TABLE IF EXISTS guest_type_gates;
+DROP TABLE IF EXISTS pass_gates;
+DROP TABLE IF EXISTS guard_gates;
+DROP TABLE IF EXISTS gates;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS gates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gates_name ON gates (lower(name)) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS guard_gates (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gate_id UUID NOT NULL REFERENCES gates(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, gate_id)
+);
+
+CREATE TABLE IF NOT EXISTS pass_gates (
+    pass_id UUID NOT NULL REFERENCES passes(id) ON DELETE CASCADE,
+    gate_id UUID NOT NULL REFERENCES gates(id) ON DELETE CASCADE,
+    PRIMARY KEY (pass_id, gate_id)
+);
+
+CREATE TABLE IF NOT EXISTS guest_type_gates (
+    guest_type TEXT NOT NULL CHECK (guest_type IN ('vehicle', 'pedestrian', 'taxi', 'delivery', 'service')),
+    gate_id UUID NOT NULL REFERENCES gates(id) ON DELETE CASCADE,
+    PRIMARY KEY (guest_type, gate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pass_gates_gate_id ON pass_gates (gate_id);
+CREATE INDEX IF NOT EXISTS idx_guest_type_gates_gate_id ON guest_type_gates (gate_id);
+
+ALTER TABLE entry_logs
+    ADD COLUMN IF NOT EXISTS gate_id UUID NULL REFERENCES gates(id);
+
+CREATE INDEX IF NOT EXISTS idx_entry_logs_gate_id ON entry_logs (gate_id, action_at DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_entry_logs_shift_id;
+
+ALTER TABLE entry_logs
+    DROP COLUMN IF EXISTS shift_id;
+
+DROP TABLE IF EXISTS guard_shifts;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS guard_shifts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    guard_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gate_id UUID NULL REFERENCES gates(id),
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ended_at TIMESTAMPTZ NULL,
+    ended_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    handover_note TEXT NULL,
+    handover_from_id UUID NULL REFERENCES guard_shifts(id) ON DELETE SET NULL,
+    on_site_at_end INTEGER NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guard_shifts_open ON guard_shifts (guard_user_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_guard_shifts_started_at ON guard_shifts (started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_guard_shifts_handover_from_id ON guard_shifts (handover_from_id);
+
+ALTER TABLE entry_logs
+    ADD COLUMN IF NOT EXISTS shift_id UUID NULL REFERENCES guard_shifts(id);
+
+CREATE INDEX IF NOT EXISTS idx_entry_logs_shift_id ON entry_logs (shift_id);
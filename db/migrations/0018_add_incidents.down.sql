@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS incident_attachments;
+DROP TABLE IF EXISTS incident_comments;
+DROP TABLE IF EXISTS incidents;
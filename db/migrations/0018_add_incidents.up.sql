@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    category TEXT NOT NULL CHECK (category IN ('barrier_damage', 'aggressive_visitor', 'unknown_vehicle', 'property_damage', 'other')),
+    severity TEXT NOT NULL CHECK (severity IN ('low', 'medium', 'high', 'critical')),
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'in_progress', 'resolved', 'dismissed')),
+    title TEXT NOT NULL,
+    description TEXT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    reported_by UUID NOT NULL REFERENCES users(id),
+    gate_id UUID NULL REFERENCES gates(id),
+    shift_id UUID NULL REFERENCES guard_shifts(id) ON DELETE SET NULL,
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE SET NULL,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE SET NULL,
+    plate_number TEXT NULL,
+    resolved_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS incident_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL,
+    status_from TEXT NULL,
+    status_to TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS incident_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_occurred_at ON incidents (occurred_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_incidents_status ON incidents (status, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_incidents_shift_id ON incidents (shift_id);
+CREATE INDEX IF NOT EXISTS idx_incidents_plate_number ON incidents (plate_number);
+CREATE INDEX IF NOT EXISTS idx_incident_comments_incident_id ON incident_comments (incident_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_incident_attachments_incident_id ON incident_attachments (incident_id, created_at);
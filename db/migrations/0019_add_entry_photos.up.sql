@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS entry_photos (
+    id UUID PRIMARY KEY,
+    entry_log_id UUID NOT NULL UNIQUE REFERENCES entry_logs(id) ON DELETE CASCADE,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    thumbnail_key TEXT NOT NULL UNIQUE,
+    uploaded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_entry_photos_created_at ON entry_photos (created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS plate_reads;
+DROP TABLE IF EXISTS cameras;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS cameras (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    gate_id UUID NULL REFERENCES gates(id),
+    direction TEXT NOT NULL CHECK (direction IN ('entry', 'exit')),
+    operator_user_id UUID NOT NULL REFERENCES users(id),
+    token_hash TEXT NOT NULL UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT true,
+    last_seen_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cameras_name ON cameras (lower(name)) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS plate_reads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    camera_id UUID NOT NULL REFERENCES cameras(id),
+    plate_raw TEXT NOT NULL,
+    plate_number TEXT NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    captured_at TIMESTAMPTZ NOT NULL,
+    decision TEXT NOT NULL CHECK (decision IN ('allow', 'deny', 'manual_review')),
+    reason TEXT NOT NULL,
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE SET NULL,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE SET NULL,
+    entry_log_id UUID NULL REFERENCES entry_logs(id),
+    review_status TEXT NULL CHECK (review_status IN ('pending', 'allowed', 'denied')),
+    reviewed_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ NULL,
+    review_comment TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_plate_reads_camera_id ON plate_reads (camera_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_plate_reads_pending ON plate_reads (created_at) WHERE review_status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS barrier_events;
+DROP TABLE IF EXISTS gate_barriers;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS gate_barriers (
+    gate_id UUID PRIMARY KEY REFERENCES gates(id) ON DELETE CASCADE,
+    driver TEXT NOT NULL CHECK (driver IN ('http', 'mqtt', 'noop')),
+    address TEXT NULL,
+    topic TEXT NULL,
+    username TEXT NULL,
+    secret TEXT NULL,
+    auto_open BOOLEAN NOT NULL DEFAULT true,
+    hold_seconds INTEGER NOT NULL DEFAULT 0 CHECK (hold_seconds >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS barrier_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gate_id UUID NOT NULL REFERENCES gates(id),
+    command TEXT NOT NULL CHECK (command IN ('open', 'hold', 'status')),
+    driver TEXT NOT NULL,
+    entry_log_id UUID NULL REFERENCES entry_logs(id),
+    user_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    success BOOLEAN NOT NULL,
+    state TEXT NULL,
+    error TEXT NULL,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_barrier_events_gate_id ON barrier_events (gate_id, created_at DESC);
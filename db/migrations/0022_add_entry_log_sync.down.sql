@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_guest_requests_updated_at;
+DROP INDEX IF EXISTS idx_passes_updated_at;
+
+ALTER TABLE entry_logs
+    DROP COLUMN IF EXISTS device_at;
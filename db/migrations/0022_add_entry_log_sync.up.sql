@@ -0,0 +1,7 @@
+-- Entries synced from a guard device that was offline keep the time the
+-- device logged them; action_at stays the time the server received them.
+ALTER TABLE entry_logs
+    ADD COLUMN IF NOT EXISTS device_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_passes_updated_at ON passes (updated_at);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_updated_at ON guest_requests (updated_at);
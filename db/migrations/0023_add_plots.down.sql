@@ -0,0 +1,11 @@
+-- users.plot_number keeps the normalized values.
+DROP INDEX IF EXISTS idx_guest_requests_plot_id;
+DROP INDEX IF EXISTS idx_passes_plot_id;
+
+ALTER TABLE guest_requests
+    DROP COLUMN IF EXISTS plot_id;
+ALTER TABLE passes
+    DROP COLUMN IF EXISTS plot_id;
+
+DROP TABLE IF EXISTS plot_residents;
+DROP TABLE IF EXISTS plots;
@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS plots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    number TEXT NOT NULL,
+    street TEXT NULL,
+    contact_name TEXT NULL,
+    contact_phone TEXT NULL,
+    contact_email TEXT NULL,
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'archived')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plots_number ON plots (number);
+
+CREATE TABLE IF NOT EXISTS plot_residents (
+    plot_id UUID NOT NULL REFERENCES plots(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    relation TEXT NOT NULL CHECK (relation IN ('owner', 'family', 'tenant')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    PRIMARY KEY (plot_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_plot_residents_user_id ON plot_residents (user_id);
+
+ALTER TABLE passes
+    ADD COLUMN IF NOT EXISTS plot_id UUID NULL REFERENCES plots(id) ON DELETE SET NULL;
+ALTER TABLE guest_requests
+    ADD COLUMN IF NOT EXISTS plot_id UUID NULL REFERENCES plots(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_passes_plot_id ON passes (plot_id);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_plot_id ON guest_requests (plot_id);
+
+-- Free-text plot numbers are brought to the form NormalizePlotNumber
+-- produces: upper case, no "уч."/"участок"/"№" prefix, no spaces, Cyrillic
+-- letters that look like Latin ones written in Latin. "12а", "12 A" and
+-- "уч. 12А" all become "12A". The original spelling is not kept.
+UPDATE users
+SET plot_number = NULLIF(translate(
+        regexp_replace(
+            regexp_replace(upper(btrim(plot_number)), '^((УЧАСТОК|УЧ\.?|№|#)\s*)+', '', 'i'),
+            '\s+', '', 'g'),
+        'АВЕКМНОРСТУХавекмнорстух', 'ABEKMHOPCTYXABEKMHOPCTYX'), '')
+WHERE plot_number IS NOT NULL;
+
+-- Every resident becomes the owner of their plot; households with family
+-- members or tenants are corrected by hand afterwards.
+INSERT INTO plots (number)
+SELECT DISTINCT plot_number FROM users WHERE plot_number IS NOT NULL
+ON CONFLICT (number) DO NOTHING;
+
+INSERT INTO plot_residents (plot_id, user_id, relation)
+SELECT p.id, u.id, 'owner'
+FROM users u
+JOIN plots p ON p.number = u.plot_number
+ON CONFLICT (plot_id, user_id) DO NOTHING;
+
+UPDATE passes
+SET plot_id = p.id
+FROM users u
+JOIN plots p ON p.number = u.plot_number
+WHERE u.id = passes.owner_user_id AND passes.plot_id IS NULL;
+
+UPDATE guest_requests
+SET plot_id = p.id
+FROM users u
+JOIN plots p ON p.number = u.plot_number
+WHERE u.id = guest_requests.resident_user_id AND guest_requests.plot_id IS NULL;
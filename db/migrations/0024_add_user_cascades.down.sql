@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_user_cascade_items_open;
+DROP TABLE IF EXISTS user_cascade_items;
+
+UPDATE guest_requests SET status = 'cancelled' WHERE status = 'suspended';
+
+ALTER TABLE guest_requests
+    DROP CONSTRAINT IF EXISTS guest_requests_status_check,
+    ADD CONSTRAINT guest_requests_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'arrived', 'completed'));
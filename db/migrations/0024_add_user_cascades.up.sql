@@ -0,0 +1,24 @@
+-- Blocking a user suspends their pending guest requests; unblocking brings
+-- them back to pending.
+ALTER TABLE guest_requests
+    DROP CONSTRAINT IF EXISTS guest_requests_status_check,
+    ADD CONSTRAINT guest_requests_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'arrived', 'completed', 'suspended'));
+
+-- Every pass and guest request status changed because its owner was blocked
+-- or deleted, so that unblocking or restoring the user undoes only those.
+CREATE TABLE IF NOT EXISTS user_cascade_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('block', 'delete')),
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE CASCADE,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE CASCADE,
+    previous_status TEXT NOT NULL,
+    new_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    restored_at TIMESTAMPTZ NULL,
+    CONSTRAINT user_cascade_items_subject_check CHECK (num_nonnulls(pass_id, guest_request_id) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_cascade_items_open ON user_cascade_items (user_id, action) WHERE restored_at IS NULL;
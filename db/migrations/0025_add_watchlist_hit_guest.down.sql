@@ -0,0 +1 @@
+ALTER TABLE watchlist_hits DROP COLUMN IF EXISTS guest_request_id;
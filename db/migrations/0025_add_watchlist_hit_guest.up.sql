@@ -0,0 +1,4 @@
+-- Hits on guest vehicles point at the guest request they came with, like
+-- hits on residents' cars point at the pass.
+ALTER TABLE watchlist_hits
+    ADD COLUMN IF NOT EXISTS guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE SET NULL;
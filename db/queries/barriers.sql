@@ -0,0 +1,32 @@
+-- name: UpsertGateBarrier :one
+INSERT INTO gate_barriers (gate_id, driver, address, topic, username, secret, auto_open, hold_seconds, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (gate_id) DO UPDATE
+SET driver = EXCLUDED.driver,
+    address = EXCLUDED.address,
+    topic = EXCLUDED.topic,
+    username = EXCLUDED.username,
+    secret = EXCLUDED.secret,
+    auto_open = EXCLUDED.auto_open,
+    hold_seconds = EXCLUDED.hold_seconds,
+    updated_at = now(),
+    updated_by = EXCLUDED.updated_by
+RETURNING *;
+
+-- name: GetGateBarrier :one
+SELECT * FROM gate_barriers WHERE gate_id = $1;
+
+-- name: DeleteGateBarrier :execrows
+DELETE FROM gate_barriers WHERE gate_id = $1;
+
+-- name: CreateBarrierEvent :one
+INSERT INTO barrier_events (gate_id, command, driver, entry_log_id, user_id, success, state, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: ListBarrierEvents :many
+SELECT * FROM barrier_events
+WHERE gate_id = sqlc.arg(gate_id)
+  AND (NOT sqlc.arg(failed_only)::boolean OR NOT success)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
@@ -0,0 +1,112 @@
+-- name: CreateCamera :one
+INSERT INTO cameras (name, gate_id, direction, operator_user_id, token_hash, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $6)
+RETURNING *;
+
+-- name: GetCamera :one
+SELECT * FROM cameras WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetCameraByName :one
+SELECT * FROM cameras WHERE lower(name) = lower(sqlc.arg(name)) AND deleted_at IS NULL;
+
+-- name: GetCameraByToken :one
+SELECT * FROM cameras WHERE token_hash = $1 AND active AND deleted_at IS NULL;
+
+-- name: ListCameras :many
+SELECT * FROM cameras
+WHERE deleted_at IS NULL
+ORDER BY name;
+
+-- name: UpdateCamera :one
+UPDATE cameras
+SET name = $2,
+    gate_id = $3,
+    direction = $4,
+    operator_user_id = $5,
+    active = $6,
+    updated_at = now(),
+    updated_by = $7
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetCameraToken :execrows
+UPDATE cameras
+SET token_hash = $2,
+    updated_at = now(),
+    updated_by = $3
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: TouchCamera :exec
+UPDATE cameras SET last_seen_at = $2 WHERE id = $1;
+
+-- name: SoftDeleteCamera :execrows
+UPDATE cameras
+SET deleted_at = now(),
+    active = false,
+    updated_at = now(),
+    updated_by = $2
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListActivePassesByPlate :many
+SELECT p.* FROM passes p
+JOIN users u ON u.id = p.owner_user_id
+WHERE p.plate_number = $1 AND p.status = 'active' AND p.deleted_at IS NULL
+  AND u.deleted_at IS NULL AND u.blocked_at IS NULL
+ORDER BY p.created_at DESC;
+
+-- name: ListExitPassesByPlate :many
+SELECT p.* FROM passes p
+LEFT JOIN site_presence sp ON sp.pass_id = p.id
+WHERE p.plate_number = $1 AND (sp.pass_id IS NOT NULL OR p.deleted_at IS NULL)
+ORDER BY sp.pass_id IS NULL, p.created_at DESC;
+
+-- name: ListCurrentGuestsByPlate :many
+SELECT g.* FROM guest_requests g
+JOIN users u ON u.id = g.resident_user_id
+WHERE g.plate_number = sqlc.arg(plate_number) AND g.deleted_at IS NULL AND u.deleted_at IS NULL AND u.blocked_at IS NULL
+  AND (g.status = 'arrived'
+       OR (g.status = 'approved' AND g.valid_from <= sqlc.arg(at)::timestamptz AND g.valid_to > sqlc.arg(at)::timestamptz))
+ORDER BY g.valid_from, g.id;
+
+-- name: CreatePlateRead :one
+INSERT INTO plate_reads (camera_id, plate_raw, plate_number, confidence, captured_at, decision, reason, pass_id, guest_request_id, entry_log_id, review_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetPlateRead :one
+SELECT * FROM plate_reads WHERE id = $1;
+
+-- name: ListPlateReads :many
+SELECT r.id, r.camera_id, r.plate_raw, r.plate_number, r.confidence, r.captured_at, r.decision, r.reason,
+       r.pass_id, r.guest_request_id, r.entry_log_id, r.review_status, r.reviewed_by, r.reviewed_at, r.review_comment, r.created_at,
+       c.name AS camera_name, c.direction, c.gate_id,
+       g.name AS gate_name
+FROM plate_reads r
+JOIN cameras c ON c.id = r.camera_id
+LEFT JOIN gates g ON g.id = c.gate_id
+WHERE (sqlc.narg(review_status)::text IS NULL OR r.review_status = sqlc.narg(review_status))
+  AND (sqlc.narg(decision)::text IS NULL OR r.decision = sqlc.narg(decision))
+  AND (sqlc.narg(camera_id)::uuid IS NULL OR r.camera_id = sqlc.narg(camera_id))
+  AND (sqlc.narg(gate_id)::uuid IS NULL OR c.gate_id = sqlc.narg(gate_id))
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: ClaimPlateRead :one
+UPDATE plate_reads
+SET review_status = sqlc.arg(review_status)::text,
+    reviewed_by = sqlc.arg(reviewed_by),
+    reviewed_at = now(),
+    review_comment = sqlc.narg(review_comment)
+WHERE id = sqlc.arg(id) AND review_status = 'pending'
+RETURNING *;
+
+-- name: ReopenPlateRead :exec
+UPDATE plate_reads
+SET review_status = 'pending',
+    reviewed_by = NULL,
+    reviewed_at = NULL,
+    review_comment = NULL
+WHERE id = $1;
+
+-- name: SetPlateReadEntryLog :exec
+UPDATE plate_reads SET entry_log_id = $2 WHERE id = $1;
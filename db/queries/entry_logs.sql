@@ -1,10 +1,55 @@
 -- name: CreateEntryLog :one
-INSERT INTO entry_logs (pass_id, guard_user_id, action, comment)
-VALUES ($1, $2, $3, $4)
+INSERT INTO entry_logs (
+    id, pass_id, guest_request_id, guard_user_id, action, action_at, comment, anomaly, override_reason,
+    amends_id, corrected_action, corrected_action_at, gate_id, shift_id, device_at, seq, prev_hash, hash
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING *;
 
--- name: ListEntryLogsByPass :many
+-- name: GetEntryLog :one
+SELECT * FROM entry_logs WHERE id = $1;
+
+-- name: LockEntryLogChain :exec
+SELECT pg_advisory_xact_lock(hashtext('entry_logs_chain'));
+
+-- name: GetEntryLogChainHead :one
+SELECT seq, hash FROM entry_logs
+ORDER BY seq DESC
+LIMIT 1;
+
+-- name: ListEntryLogChain :many
 SELECT * FROM entry_logs
-WHERE pass_id = $1
-ORDER BY action_at DESC
-LIMIT $2 OFFSET $3;
+WHERE seq > $1
+ORDER BY seq
+LIMIT $2;
+
+-- name: ListEntryLogs :many
+SELECT e.id, e.pass_id, e.guest_request_id, e.guard_user_id, e.action, e.action_at, e.comment,
+       e.anomaly, e.override_reason, e.seq, e.hash, e.amends_id, e.corrected_action, e.corrected_action_at,
+       e.gate_id, e.device_at, gt.name AS gate_name,
+       COALESCE(p.plate_number, g.plate_number) AS plate_number,
+       COALESCE(p.owner_user_id, g.resident_user_id) AS owner_user_id,
+       COALESCE(o.full_name, r.full_name) AS owner_full_name,
+       COALESCE(o.plot_number, r.plot_number) AS owner_plot_number,
+       g.guest_full_name,
+       gu.full_name AS guard_full_name,
+       ph.id AS photo_id
+FROM entry_logs e
+LEFT JOIN passes p ON p.id = e.pass_id
+LEFT JOIN users o ON o.id = p.owner_user_id
+LEFT JOIN guest_requests g ON g.id = e.guest_request_id
+LEFT JOIN users r ON r.id = g.resident_user_id
+JOIN users gu ON gu.id = e.guard_user_id
+LEFT JOIN gates gt ON gt.id = e.gate_id
+LEFT JOIN entry_photos ph ON ph.entry_log_id = e.id
+WHERE (sqlc.narg(pass_id)::uuid IS NULL OR e.pass_id = sqlc.narg(pass_id))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR e.action_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR e.action_at < sqlc.narg(to_time))
+  AND (sqlc.narg(action)::text IS NULL OR e.action = sqlc.narg(action))
+  AND (sqlc.narg(guard_user_id)::uuid IS NULL OR e.guard_user_id = sqlc.narg(guard_user_id))
+  AND (sqlc.narg(plate_pattern)::text IS NULL OR COALESCE(p.plate_number, g.plate_number) LIKE sqlc.narg(plate_pattern))
+  AND (sqlc.narg(gate_id)::uuid IS NULL OR e.gate_id = sqlc.narg(gate_id))
+  AND (sqlc.narg(plot_number)::text IS NULL OR COALESCE(o.plot_number, r.plot_number) = sqlc.narg(plot_number))
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR COALESCE(p.owner_user_id, g.resident_user_id) = sqlc.narg(owner_user_id))
+ORDER BY e.action_at DESC, e.id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
@@ -0,0 +1,19 @@
+-- name: CreateEntryPhoto :one
+INSERT INTO entry_photos (id, entry_log_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, uploaded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetEntryPhoto :one
+SELECT * FROM entry_photos WHERE id = $1;
+
+-- name: GetEntryPhotoByLog :one
+SELECT * FROM entry_photos WHERE entry_log_id = $1;
+
+-- name: ListEntryPhotosBefore :many
+SELECT * FROM entry_photos
+WHERE created_at < $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: DeleteEntryPhoto :exec
+DELETE FROM entry_photos WHERE id = $1;
@@ -0,0 +1,54 @@
+-- name: ExportUsers :many
+SELECT * FROM users
+WHERE (sqlc.arg(include_deleted)::bool OR deleted_at IS NULL)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY created_at, id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ExportPasses :many
+SELECT p.id, p.owner_user_id, p.plate_number, p.vehicle_brand, p.vehicle_color, p.status, p.created_at, p.updated_at, p.deleted_at,
+       u.email AS owner_email, u.full_name AS owner_full_name, u.plot_number AS owner_plot_number
+FROM passes p
+JOIN users u ON u.id = p.owner_user_id
+WHERE (sqlc.arg(include_deleted)::bool OR p.deleted_at IS NULL)
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR p.owner_user_id = sqlc.narg(owner_user_id))
+  AND (p.created_at, p.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY p.created_at, p.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ExportGuestRequests :many
+SELECT g.id, g.resident_user_id, g.guest_full_name, g.plate_number, g.valid_from, g.valid_to, g.status, g.created_at, g.deleted_at,
+       u.full_name AS resident_full_name, u.plot_number AS resident_plot_number
+FROM guest_requests g
+JOIN users u ON u.id = g.resident_user_id
+WHERE (sqlc.arg(include_deleted)::bool OR g.deleted_at IS NULL)
+  AND (sqlc.narg(resident_user_id)::uuid IS NULL OR g.resident_user_id = sqlc.narg(resident_user_id))
+  AND (g.created_at, g.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY g.created_at, g.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ExportEntryLogs :many
+SELECT e.id, e.pass_id, e.guest_request_id, e.action, e.action_at, e.comment,
+       COALESCE(p.plate_number, g.plate_number) AS plate_number,
+       COALESCE(o.full_name, r.full_name) AS owner_full_name,
+       COALESCE(o.plot_number, r.plot_number) AS owner_plot_number,
+       g.guest_full_name,
+       gu.full_name AS guard_full_name
+FROM entry_logs e
+LEFT JOIN passes p ON p.id = e.pass_id
+LEFT JOIN users o ON o.id = p.owner_user_id
+LEFT JOIN guest_requests g ON g.id = e.guest_request_id
+LEFT JOIN users r ON r.id = g.resident_user_id
+JOIN users gu ON gu.id = e.guard_user_id
+WHERE (sqlc.narg(owner_user_id)::uuid IS NULL OR COALESCE(p.owner_user_id, g.resident_user_id) = sqlc.narg(owner_user_id))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR e.action_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR e.action_at < sqlc.narg(to_time))
+  AND (sqlc.narg(pass_id)::uuid IS NULL OR e.pass_id = sqlc.narg(pass_id))
+  AND (sqlc.narg(action)::text IS NULL OR e.action = sqlc.narg(action))
+  AND (sqlc.narg(guard_user_id)::uuid IS NULL OR e.guard_user_id = sqlc.narg(guard_user_id))
+  AND (sqlc.narg(plate_pattern)::text IS NULL OR COALESCE(p.plate_number, g.plate_number) LIKE sqlc.narg(plate_pattern))
+  AND (sqlc.narg(gate_id)::uuid IS NULL OR e.gate_id = sqlc.narg(gate_id))
+  AND (sqlc.narg(plot_number)::text IS NULL OR COALESCE(o.plot_number, r.plot_number) = sqlc.narg(plot_number))
+  AND (e.action_at, e.id) > (sqlc.arg(after_action_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY e.action_at, e.id
+LIMIT sqlc.arg(batch_size);
@@ -0,0 +1,96 @@
+-- name: CreateGate :one
+INSERT INTO gates (name, description, created_by, updated_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetGate :one
+SELECT * FROM gates WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetGateByName :one
+SELECT * FROM gates WHERE lower(name) = lower(sqlc.arg(name)) AND deleted_at IS NULL;
+
+-- name: ListGates :many
+SELECT * FROM gates
+WHERE deleted_at IS NULL
+ORDER BY name;
+
+-- name: UpdateGate :one
+UPDATE gates
+SET name = $2,
+    description = $3,
+    updated_at = now(),
+    updated_by = $4
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteGate :execrows
+UPDATE gates
+SET deleted_at = now(),
+    updated_at = now(),
+    updated_by = $2
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: CountGateRestrictions :one
+SELECT (SELECT count(*) FROM pass_gates pg WHERE pg.gate_id = $1)
+     + (SELECT count(*) FROM guest_type_gates tg WHERE tg.gate_id = $1) AS restrictions;
+
+-- name: ListGuardGates :many
+SELECT g.* FROM gates g
+JOIN guard_gates gg ON gg.gate_id = g.id
+WHERE gg.user_id = $1 AND g.deleted_at IS NULL
+ORDER BY g.name;
+
+-- name: AddGuardGate :exec
+INSERT INTO guard_gates (user_id, gate_id) VALUES ($1, $2);
+
+-- name: ClearGuardGates :exec
+DELETE FROM guard_gates WHERE user_id = $1;
+
+-- name: DeleteGateAssignments :exec
+DELETE FROM guard_gates WHERE gate_id = $1;
+
+-- name: ListPassGates :many
+SELECT g.* FROM gates g
+JOIN pass_gates pg ON pg.gate_id = g.id
+WHERE pg.pass_id = $1 AND g.deleted_at IS NULL
+ORDER BY g.name;
+
+-- name: AddPassGate :exec
+INSERT INTO pass_gates (pass_id, gate_id) VALUES ($1, $2);
+
+-- name: ClearPassGates :exec
+DELETE FROM pass_gates WHERE pass_id = $1;
+
+-- name: ListGuestTypeGates :many
+SELECT g.* FROM gates g
+JOIN guest_type_gates tg ON tg.gate_id = g.id
+WHERE tg.guest_type = $1 AND g.deleted_at IS NULL
+ORDER BY g.name;
+
+-- name: ListAllGuestTypeGates :many
+SELECT tg.guest_type, g.id AS gate_id, g.name AS gate_name
+FROM guest_type_gates tg
+JOIN gates g ON g.id = tg.gate_id
+WHERE g.deleted_at IS NULL
+ORDER BY tg.guest_type, g.name;
+
+-- name: AddGuestTypeGate :exec
+INSERT INTO guest_type_gates (guest_type, gate_id) VALUES ($1, $2);
+
+-- name: ClearGuestTypeGates :exec
+DELETE FROM guest_type_gates WHERE guest_type = $1;
+
+-- name: GateStats :many
+SELECT g.id, g.name,
+       count(e.id) FILTER (WHERE e.action = 'entry') AS entries,
+       count(e.id) FILTER (WHERE e.action = 'exit') AS exits,
+       count(e.id) FILTER (WHERE e.action = 'entry' AND e.guest_request_id IS NOT NULL) AS guest_entries,
+       count(e.id) FILTER (WHERE e.anomaly IS NOT NULL) AS anomalies,
+       max(e.action_at) AS last_action_at
+FROM gates g
+LEFT JOIN entry_logs e ON e.gate_id = g.id
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR e.action_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR e.action_at < sqlc.narg(to_time))
+GROUP BY g.id, g.name, g.deleted_at
+HAVING g.deleted_at IS NULL OR count(e.id) > 0
+ORDER BY g.name;
@@ -0,0 +1,35 @@
+-- name: GetGuestPin :one
+SELECT * FROM guest_pins WHERE guest_request_id = $1;
+
+-- name: DeleteGuestPin :exec
+DELETE FROM guest_pins WHERE guest_request_id = $1;
+
+-- name: ReleaseGuestPinCode :exec
+DELETE FROM guest_pins p
+USING guest_requests g
+WHERE g.id = p.guest_request_id
+  AND p.code = sqlc.arg(code)
+  AND (p.used_at IS NOT NULL OR g.deleted_at IS NOT NULL
+       OR g.status <> 'approved' OR g.valid_to <= sqlc.arg(now)::timestamptz);
+
+-- name: CreateGuestPin :one
+INSERT INTO guest_pins (guest_request_id, code, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (code) DO NOTHING
+RETURNING *;
+
+-- name: GetGuestByPin :one
+SELECT g.id, g.guest_full_name, g.plate_number, g.valid_from, g.valid_to, g.status,
+       g.guest_type, g.company_name,
+       u.full_name AS resident_full_name, u.plot_number AS resident_plot_number
+FROM guest_pins p
+JOIN guest_requests g ON g.id = p.guest_request_id
+JOIN users u ON u.id = g.resident_user_id
+WHERE p.code = sqlc.arg(code) AND p.used_at IS NULL
+  AND g.deleted_at IS NULL AND u.deleted_at IS NULL
+  AND g.status = 'approved'
+  AND g.valid_to > sqlc.arg(now)::timestamptz;
+
+-- name: UseGuestPin :exec
+UPDATE guest_pins SET used_at = now()
+WHERE guest_request_id = $1 AND used_at IS NULL;
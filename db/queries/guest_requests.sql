@@ -1,6 +1,11 @@
 -- name: CreateGuestRequest :one
-INSERT INTO guest_requests (resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_by, updated_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO guest_requests (resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_by, updated_by, reviewed_by, reviewed_at, review_reason, guest_type, company_name, plot_id)
+VALUES (sqlc.arg(resident_user_id), sqlc.arg(guest_full_name), sqlc.arg(plate_number), sqlc.arg(valid_from), sqlc.arg(valid_to), sqlc.arg(status), sqlc.arg(created_by), sqlc.arg(updated_by),
+        sqlc.arg(reviewed_by), sqlc.arg(reviewed_at), sqlc.arg(review_reason), sqlc.arg(guest_type), sqlc.arg(company_name),
+        COALESCE(sqlc.narg(plot_id)::uuid, (
+            SELECT p.id FROM users u
+            JOIN plots p ON p.number = u.plot_number
+            WHERE u.id = sqlc.arg(resident_user_id) AND p.status = 'active')))
 RETURNING *;
 
 -- name: GetGuestRequestByID :one
@@ -9,6 +14,9 @@ SELECT * FROM guest_requests WHERE id = $1 AND deleted_at IS NULL;
 -- name: GetGuestRequestByIDAny :one
 SELECT * FROM guest_requests WHERE id = $1;
 
+-- name: GetGuestRequestByIDForUpdate :one
+SELECT * FROM guest_requests WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;
+
 -- name: ListGuestRequestsByResident :many
 SELECT * FROM guest_requests
 WHERE resident_user_id = $1 AND (($2::bool) OR deleted_at IS NULL)
@@ -23,14 +31,16 @@ LIMIT $2 OFFSET $3;
 
 -- name: UpdateGuestRequest :one
 UPDATE guest_requests
-SET guest_full_name = $2,
-    plate_number = $3,
-    valid_from = $4,
-    valid_to = $5,
-    status = $6,
+SET guest_full_name = sqlc.arg(guest_full_name),
+    plate_number = sqlc.arg(plate_number),
+    valid_from = sqlc.arg(valid_from),
+    valid_to = sqlc.arg(valid_to),
+    guest_type = sqlc.arg(guest_type),
+    company_name = sqlc.arg(company_name),
+    plot_id = COALESCE(sqlc.narg(plot_id)::uuid, plot_id),
     updated_at = now(),
-    updated_by = $7
-WHERE id = $1 AND deleted_at IS NULL
+    updated_by = sqlc.arg(updated_by)
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL AND status IN ('pending', 'approved')
 RETURNING *;
 
 -- name: SoftDeleteGuestRequest :exec
@@ -46,3 +56,107 @@ SET deleted_at = NULL,
     updated_at = now(),
     updated_by = $2
 WHERE id = $1;
+
+-- name: ReviewGuestRequest :one
+UPDATE guest_requests
+SET status = $2,
+    reviewed_by = $3,
+    reviewed_at = now(),
+    review_reason = $4,
+    updated_at = now(),
+    updated_by = $3
+WHERE id = $1 AND deleted_at IS NULL AND status = 'pending'
+RETURNING *;
+
+-- name: SetGuestRequestStatus :one
+UPDATE guest_requests
+SET status = sqlc.arg(status),
+    updated_at = now(),
+    updated_by = sqlc.arg(updated_by)
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: ExpireGuestRequests :execrows
+UPDATE guest_requests
+SET status = 'expired',
+    updated_at = now()
+WHERE deleted_at IS NULL AND status IN ('pending', 'approved', 'suspended') AND valid_to <= $1;
+
+-- name: ListGateGuests :many
+SELECT g.id, g.guest_full_name, g.plate_number, g.valid_from, g.valid_to, g.status,
+       g.guest_type, g.company_name,
+       u.full_name AS resident_full_name, u.plot_number AS resident_plot_number
+FROM guest_requests g
+JOIN users u ON u.id = g.resident_user_id
+WHERE g.deleted_at IS NULL AND u.deleted_at IS NULL
+  AND g.status IN ('approved', 'arrived')
+  AND g.valid_to > sqlc.arg(window_start)::timestamptz
+  AND g.valid_from <= sqlc.arg(window_end)::timestamptz
+  AND (sqlc.narg(plate_pattern)::text IS NULL OR g.plate_number ILIKE sqlc.narg(plate_pattern))
+  AND (sqlc.narg(guest_type)::text IS NULL OR g.guest_type = sqlc.narg(guest_type))
+ORDER BY g.valid_from, g.id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: MaterializeGuestOccurrence :exec
+INSERT INTO guest_requests (resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_by, updated_by, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, plot_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, (
+    SELECT p.id FROM users u
+    JOIN plots p ON p.number = u.plot_number
+    WHERE u.id = $1 AND p.status = 'active'))
+ON CONFLICT (series_id, occurrence_date) DO NOTHING;
+
+-- name: GetGuestOccurrence :one
+SELECT * FROM guest_requests WHERE series_id = $1 AND occurrence_date = $2;
+
+-- name: ListGuestOccurrences :many
+SELECT * FROM guest_requests
+WHERE series_id = sqlc.arg(series_id)::uuid AND deleted_at IS NULL
+  AND occurrence_date >= sqlc.arg(first_day)::date
+  AND occurrence_date <= sqlc.arg(last_day)::date
+ORDER BY occurrence_date;
+
+-- name: UpdateGuestOccurrence :one
+UPDATE guest_requests
+SET guest_full_name = $2,
+    plate_number = $3,
+    valid_from = $4,
+    valid_to = $5,
+    status = $6,
+    reviewed_by = $7,
+    reviewed_at = $8,
+    review_reason = $9,
+    updated_at = now(),
+    updated_by = $10
+WHERE id = $1 AND deleted_at IS NULL AND series_id IS NOT NULL AND status IN ('pending', 'approved')
+RETURNING *;
+
+-- name: DeleteGuestOccurrencesFrom :execrows
+DELETE FROM guest_requests
+WHERE series_id = $1 AND occurrence_date >= $2 AND status IN ('pending', 'approved');
+
+-- name: ReviewGuestOccurrences :execrows
+UPDATE guest_requests
+SET status = $2,
+    reviewed_by = $3,
+    reviewed_at = now(),
+    review_reason = $4,
+    updated_at = now(),
+    updated_by = $3
+WHERE series_id = $1 AND deleted_at IS NULL AND status = 'pending';
+
+-- name: ListOverlappingGuestRequests :many
+SELECT id FROM guest_requests
+WHERE plate_number = sqlc.arg(plate_number)
+  AND deleted_at IS NULL
+  AND status IN ('pending', 'approved', 'arrived')
+  AND valid_from < sqlc.arg(valid_to)
+  AND valid_to > sqlc.arg(valid_from)
+  AND id <> sqlc.arg(exclude_id)
+ORDER BY valid_from
+LIMIT 20;
+
+-- name: ListGuestRequestsByPlot :many
+SELECT * FROM guest_requests
+WHERE plot_id = $1 AND deleted_at IS NULL
+ORDER BY valid_from DESC
+LIMIT $2 OFFSET $3;
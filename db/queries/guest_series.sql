@@ -0,0 +1,71 @@
+-- name: CreateGuestSeries :one
+INSERT INTO guest_series (resident_user_id, guest_full_name, plate_number, rrule, starts_on, ends_on, start_minute, end_minute, status, previous_series_id, created_by, updated_by, reviewed_by, reviewed_at, review_reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING *;
+
+-- name: GetGuestSeriesByID :one
+SELECT * FROM guest_series WHERE id = $1;
+
+-- name: ListGuestSeries :many
+SELECT * FROM guest_series
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ListGuestSeriesByResident :many
+SELECT * FROM guest_series
+WHERE resident_user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListActiveGuestSeries :many
+SELECT s.* FROM guest_series s
+JOIN users u ON u.id = s.resident_user_id
+WHERE s.status = 'approved' AND u.deleted_at IS NULL
+  AND s.starts_on <= sqlc.arg(last_day)::date
+  AND (s.ends_on IS NULL OR s.ends_on >= sqlc.arg(first_day)::date)
+ORDER BY s.starts_on, s.id;
+
+-- name: UpdateGuestSeries :one
+UPDATE guest_series
+SET guest_full_name = $2,
+    plate_number = $3,
+    rrule = $4,
+    starts_on = $5,
+    ends_on = $6,
+    start_minute = $7,
+    end_minute = $8,
+    status = $9,
+    reviewed_by = $10,
+    reviewed_at = $11,
+    review_reason = $12,
+    updated_at = now(),
+    updated_by = $13
+WHERE id = $1 AND status IN ('pending', 'approved')
+RETURNING *;
+
+-- name: EndGuestSeries :one
+UPDATE guest_series
+SET ends_on = $2,
+    updated_at = now(),
+    updated_by = $3
+WHERE id = $1 AND status IN ('pending', 'approved')
+RETURNING *;
+
+-- name: ReviewGuestSeries :one
+UPDATE guest_series
+SET status = $2,
+    reviewed_by = $3,
+    reviewed_at = now(),
+    review_reason = $4,
+    updated_at = now(),
+    updated_by = $3
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: SetGuestSeriesStatus :one
+UPDATE guest_series
+SET status = sqlc.arg(status),
+    updated_at = now(),
+    updated_by = sqlc.arg(updated_by)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
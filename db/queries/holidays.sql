@@ -0,0 +1,17 @@
+-- name: CreateHoliday :one
+INSERT INTO holidays (day, name, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (day) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: ListHolidays :many
+SELECT * FROM holidays
+WHERE day >= sqlc.arg(from_day)::date AND day <= sqlc.arg(to_day)::date
+ORDER BY day;
+
+-- name: IsHoliday :one
+SELECT EXISTS (SELECT 1 FROM holidays WHERE day = $1) AS holiday;
+
+-- name: DeleteHoliday :execrows
+DELETE FROM holidays
+WHERE day = $1;
@@ -0,0 +1,63 @@
+-- name: CreateIncident :one
+INSERT INTO incidents (category, severity, title, description, occurred_at, reported_by, gate_id, shift_id, pass_id, guest_request_id, plate_number, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $6)
+RETURNING *;
+
+-- name: GetIncident :one
+SELECT * FROM incidents WHERE id = $1;
+
+-- name: ListIncidents :many
+SELECT i.id, i.category, i.severity, i.status, i.title, i.occurred_at, i.reported_by, i.gate_id, i.pass_id, i.guest_request_id, i.plate_number, i.resolved_at,
+       u.full_name AS reporter_full_name,
+       g.name AS gate_name,
+       (SELECT count(*) FROM incident_attachments a WHERE a.incident_id = i.id) AS attachments
+FROM incidents i
+JOIN users u ON u.id = i.reported_by
+LEFT JOIN gates g ON g.id = i.gate_id
+WHERE (sqlc.narg(status)::text IS NULL OR i.status = sqlc.narg(status))
+  AND (sqlc.narg(category)::text IS NULL OR i.category = sqlc.narg(category))
+  AND (sqlc.narg(severity)::text IS NULL OR i.severity = sqlc.narg(severity))
+  AND (sqlc.narg(reported_by)::uuid IS NULL OR i.reported_by = sqlc.narg(reported_by))
+  AND (sqlc.narg(plate_number)::text IS NULL OR i.plate_number = sqlc.narg(plate_number))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR i.occurred_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR i.occurred_at < sqlc.narg(to_time))
+ORDER BY i.occurred_at DESC, i.id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: UpdateIncidentStatus :one
+UPDATE incidents
+SET status = sqlc.arg(status),
+    resolved_at = sqlc.narg(resolved_at),
+    updated_at = now(),
+    updated_by = sqlc.arg(updated_by)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(current_status)
+RETURNING *;
+
+-- name: CreateIncidentComment :one
+INSERT INTO incident_comments (incident_id, author_id, body, status_from, status_to)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListIncidentComments :many
+SELECT c.id, c.incident_id, c.author_id, c.body, c.status_from, c.status_to, c.created_at,
+       u.full_name AS author_full_name
+FROM incident_comments c
+JOIN users u ON u.id = c.author_id
+WHERE c.incident_id = $1
+ORDER BY c.created_at, c.id;
+
+-- name: CreateIncidentAttachment :one
+INSERT INTO incident_attachments (id, incident_id, file_name, content_type, size_bytes, storage_key, uploaded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetIncidentAttachment :one
+SELECT * FROM incident_attachments WHERE id = $1;
+
+-- name: ListIncidentAttachments :many
+SELECT * FROM incident_attachments
+WHERE incident_id = $1
+ORDER BY created_at, id;
+
+-- name: DeleteIncidentAttachment :execrows
+DELETE FROM incident_attachments WHERE id = $1;
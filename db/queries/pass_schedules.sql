@@ -0,0 +1,13 @@
+-- name: CreatePassSchedule :one
+INSERT INTO pass_schedules (pass_id, weekdays, start_minute, end_minute, skip_holidays, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListPassSchedules :many
+SELECT * FROM pass_schedules
+WHERE pass_id = $1
+ORDER BY start_minute, created_at;
+
+-- name: DeletePassSchedule :execrows
+DELETE FROM pass_schedules
+WHERE id = $1 AND pass_id = $2;
@@ -1,6 +1,10 @@
 -- name: CreatePass :one
-INSERT INTO passes (owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_by, updated_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO passes (owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_by, updated_by, plot_id)
+VALUES (sqlc.arg(owner_user_id), sqlc.arg(plate_number), sqlc.arg(vehicle_brand), sqlc.arg(vehicle_color), sqlc.arg(status), sqlc.arg(created_by), sqlc.arg(updated_by),
+        COALESCE(sqlc.narg(plot_id)::uuid, (
+            SELECT p.id FROM users u
+            JOIN plots p ON p.number = u.plot_number
+            WHERE u.id = sqlc.arg(owner_user_id) AND p.status = 'active')))
 RETURNING *;
 
 -- name: GetPassByID :one
@@ -9,6 +13,12 @@ SELECT * FROM passes WHERE id = $1 AND deleted_at IS NULL;
 -- name: GetPassByIDAny :one
 SELECT * FROM passes WHERE id = $1;
 
+-- name: GetPassByIDAnyForUpdate :one
+SELECT * FROM passes WHERE id = $1 FOR UPDATE;
+
+-- name: GetPassByIDForUpdate :one
+SELECT * FROM passes WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;
+
 -- name: ListPassesByOwner :many
 SELECT * FROM passes
 WHERE owner_user_id = $1 AND (($2::bool) OR deleted_at IS NULL)
@@ -29,13 +39,14 @@ LIMIT $2 OFFSET $3;
 
 -- name: UpdatePass :one
 UPDATE passes
-SET plate_number = $2,
-    vehicle_brand = $3,
-    vehicle_color = $4,
-    status = $5,
+SET plate_number = sqlc.arg(plate_number),
+    vehicle_brand = sqlc.arg(vehicle_brand),
+    vehicle_color = sqlc.arg(vehicle_color),
+    status = sqlc.arg(status),
+    plot_id = COALESCE(sqlc.narg(plot_id)::uuid, plot_id),
     updated_at = now(),
-    updated_by = $6
-WHERE id = $1 AND deleted_at IS NULL
+    updated_by = sqlc.arg(updated_by)
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
 RETURNING *;
 
 -- name: SoftDeletePass :exec
@@ -51,3 +62,15 @@ SET deleted_at = NULL,
     updated_at = now(),
     updated_by = $2
 WHERE id = $1;
+
+-- name: GetPassByOwnerAndPlate :one
+SELECT * FROM passes
+WHERE owner_user_id = $1 AND plate_number = $2 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListPassesByPlot :many
+SELECT * FROM passes
+WHERE plot_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,95 @@
+-- name: CreatePlot :one
+INSERT INTO plots (number, street, contact_name, contact_phone, contact_email, status, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: EnsurePlot :one
+INSERT INTO plots (number, created_by, updated_by)
+VALUES (sqlc.arg(number), sqlc.arg(actor_id), sqlc.arg(actor_id))
+ON CONFLICT (number) DO UPDATE SET number = EXCLUDED.number
+RETURNING *;
+
+-- name: GetPlot :one
+SELECT * FROM plots WHERE id = $1;
+
+-- name: GetPlotByNumber :one
+SELECT * FROM plots WHERE number = $1;
+
+-- name: ListPlots :many
+SELECT * FROM plots
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(number_prefix)::text IS NULL OR number LIKE sqlc.narg(number_prefix) || '%')
+ORDER BY length(number), number
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: UpdatePlot :one
+UPDATE plots
+SET number = $2,
+    street = $3,
+    contact_name = $4,
+    contact_phone = $5,
+    contact_email = $6,
+    status = $7,
+    updated_at = now(),
+    updated_by = $8
+WHERE id = $1
+RETURNING *;
+
+-- name: RenameUserPlotNumbers :exec
+UPDATE users
+SET plot_number = sqlc.arg(new_number),
+    updated_at = now()
+WHERE plot_number = sqlc.arg(old_number);
+
+-- name: ListPlotResidents :many
+SELECT r.user_id, u.full_name, u.email, u.role, r.relation, r.created_at
+FROM plot_residents r
+JOIN users u ON u.id = r.user_id
+WHERE r.plot_id = $1 AND u.deleted_at IS NULL
+ORDER BY r.relation = 'owner' DESC, r.created_at;
+
+-- name: ListUserPlots :many
+SELECT p.*, r.relation
+FROM plots p
+JOIN plot_residents r ON r.plot_id = p.id
+WHERE r.user_id = $1
+ORDER BY r.relation = 'owner' DESC, r.created_at;
+
+-- name: GetUserPlot :one
+SELECT p.*, r.relation
+FROM plots p
+JOIN plot_residents r ON r.plot_id = p.id
+WHERE p.id = $1 AND r.user_id = $2;
+
+-- name: UpsertPlotResident :one
+INSERT INTO plot_residents (plot_id, user_id, relation, created_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (plot_id, user_id) DO UPDATE SET relation = EXCLUDED.relation
+RETURNING *;
+
+-- name: AddPlotResident :execrows
+INSERT INTO plot_residents (plot_id, user_id, relation, created_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (plot_id, user_id) DO NOTHING;
+
+-- name: DeletePlotResident :execrows
+DELETE FROM plot_residents WHERE plot_id = $1 AND user_id = $2;
+
+-- name: UnlinkPreviousUserPlot :exec
+DELETE FROM plot_residents r
+USING users u, plots p
+WHERE u.id = sqlc.arg(user_id) AND r.user_id = u.id AND p.id = r.plot_id
+  AND p.number = u.plot_number
+  AND p.number IS DISTINCT FROM sqlc.narg(plot_number)::text;
+
+-- name: RefreshUserPlotNumber :exec
+UPDATE users u
+SET plot_number = (
+        SELECT p.number FROM plot_residents r
+        JOIN plots p ON p.id = r.plot_id
+        WHERE r.user_id = u.id
+        ORDER BY r.relation = 'owner' DESC, r.created_at
+        LIMIT 1),
+    updated_at = now()
+WHERE u.id = sqlc.arg(user_id)
+  AND (u.plot_number IS NULL OR u.plot_number = sqlc.narg(stale_number)::text);
@@ -0,0 +1,44 @@
+-- name: GetPassPresence :one
+SELECT * FROM site_presence
+WHERE pass_id = $1;
+
+-- name: UpsertPassPresence :exec
+INSERT INTO site_presence (pass_id, entry_log_id, entered_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (pass_id) DO UPDATE
+SET entry_log_id = EXCLUDED.entry_log_id,
+    entered_at = EXCLUDED.entered_at;
+
+-- name: DeletePassPresence :execrows
+DELETE FROM site_presence
+WHERE pass_id = $1;
+
+-- name: UpsertGuestPresence :exec
+INSERT INTO site_presence (guest_request_id, entry_log_id, entered_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (guest_request_id) DO UPDATE
+SET entry_log_id = EXCLUDED.entry_log_id,
+    entered_at = EXCLUDED.entered_at;
+
+-- name: DeleteGuestPresence :execrows
+DELETE FROM site_presence
+WHERE guest_request_id = $1;
+
+-- name: ListSitePresence :many
+SELECT sp.id, sp.pass_id, sp.guest_request_id, sp.entered_at,
+       COALESCE(p.plate_number, g.plate_number) AS plate_number,
+       g.guest_full_name,
+       g.guest_type,
+       COALESCE(p.owner_user_id, g.resident_user_id) AS owner_user_id,
+       COALESCE(o.full_name, r.full_name) AS owner_full_name,
+       COALESCE(o.plot_number, r.plot_number) AS owner_plot_number,
+       gu.full_name AS guard_full_name
+FROM site_presence sp
+JOIN entry_logs e ON e.id = sp.entry_log_id
+LEFT JOIN passes p ON p.id = sp.pass_id
+LEFT JOIN users o ON o.id = p.owner_user_id
+LEFT JOIN guest_requests g ON g.id = sp.guest_request_id
+LEFT JOIN users r ON r.id = g.resident_user_id
+JOIN users gu ON gu.id = e.guard_user_id
+WHERE (sqlc.narg(plot_number)::text IS NULL OR COALESCE(o.plot_number, r.plot_number) = sqlc.narg(plot_number))
+ORDER BY sp.entered_at, sp.id;
@@ -0,0 +1,47 @@
+-- name: CreateSavedGuest :one
+INSERT INTO saved_guests (resident_user_id, guest_full_name, guest_type, plate_number, company_name, notes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetSavedGuest :one
+SELECT * FROM saved_guests WHERE id = $1 AND resident_user_id = $2;
+
+-- name: ListSavedGuests :many
+SELECT * FROM saved_guests
+WHERE resident_user_id = $1
+ORDER BY guest_full_name, created_at
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateSavedGuest :one
+UPDATE saved_guests
+SET guest_full_name = $3,
+    guest_type = $4,
+    plate_number = $5,
+    company_name = $6,
+    notes = $7,
+    updated_at = now()
+WHERE id = $1 AND resident_user_id = $2
+RETURNING *;
+
+-- name: DeleteSavedGuest :execrows
+DELETE FROM saved_guests WHERE id = $1 AND resident_user_id = $2;
+
+-- name: ListFrequentGuests :many
+SELECT g.guest_full_name, g.guest_type, g.plate_number, g.company_name,
+       COUNT(*) AS visits, MAX(g.valid_from)::timestamptz AS last_visit_at
+FROM guest_requests g
+WHERE g.resident_user_id = sqlc.arg(resident_user_id)
+  AND g.deleted_at IS NULL
+  AND g.series_id IS NULL
+  AND g.status <> 'rejected'
+  AND g.valid_from >= sqlc.arg(since)
+  AND NOT EXISTS (
+      SELECT 1 FROM saved_guests s
+      WHERE s.resident_user_id = g.resident_user_id
+        AND lower(s.guest_full_name) = lower(g.guest_full_name)
+        AND s.plate_number = g.plate_number
+  )
+GROUP BY g.guest_full_name, g.guest_type, g.plate_number, g.company_name
+HAVING COUNT(*) >= sqlc.arg(min_visits)::int
+ORDER BY visits DESC, last_visit_at DESC
+LIMIT sqlc.arg(page_size);
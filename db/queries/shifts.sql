@@ -0,0 +1,63 @@
+-- name: CreateGuardShift :one
+INSERT INTO guard_shifts (guard_user_id, gate_id, started_at, handover_from_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetGuardShift :one
+SELECT * FROM guard_shifts WHERE id = $1;
+
+-- name: GetOpenGuardShift :one
+SELECT * FROM guard_shifts
+WHERE guard_user_id = $1 AND ended_at IS NULL;
+
+-- name: GetPendingHandover :one
+SELECT s.* FROM guard_shifts s
+WHERE s.ended_at IS NOT NULL
+  AND s.handover_note IS NOT NULL
+  AND s.gate_id IS NOT DISTINCT FROM sqlc.narg(gate_id)
+  AND NOT EXISTS (SELECT 1 FROM guard_shifts n WHERE n.handover_from_id = s.id)
+ORDER BY s.ended_at DESC
+LIMIT 1;
+
+-- name: EndGuardShift :one
+UPDATE guard_shifts
+SET ended_at = $2,
+    ended_by = $3,
+    handover_note = $4,
+    on_site_at_end = $5
+WHERE id = $1 AND ended_at IS NULL
+RETURNING *;
+
+-- name: ListGuardShifts :many
+SELECT s.id, s.guard_user_id, s.gate_id, s.started_at, s.ended_at, s.handover_note, s.handover_from_id, s.on_site_at_end,
+       u.full_name AS guard_full_name,
+       g.name AS gate_name
+FROM guard_shifts s
+JOIN users u ON u.id = s.guard_user_id
+LEFT JOIN gates g ON g.id = s.gate_id
+WHERE (sqlc.narg(guard_user_id)::uuid IS NULL OR s.guard_user_id = sqlc.narg(guard_user_id))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR COALESCE(s.ended_at, 'infinity') >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR s.started_at < sqlc.narg(to_time))
+  AND (NOT sqlc.arg(open_only)::boolean OR s.ended_at IS NULL)
+ORDER BY s.started_at DESC, s.id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: GuardShiftSummary :one
+SELECT count(*) FILTER (WHERE e.action = 'entry') AS entries,
+       count(*) FILTER (WHERE e.action = 'exit') AS exits,
+       count(*) FILTER (WHERE e.action = 'entry' AND e.guest_request_id IS NOT NULL) AS guest_entries,
+       count(*) FILTER (WHERE e.anomaly IS NOT NULL) AS anomalies,
+       (SELECT count(*) FROM watchlist_hits h
+        WHERE h.user_id = sqlc.arg(guard_user_id)
+          AND h.source IN ('entry', 'exit')
+          AND h.created_at >= sqlc.arg(from_time)
+          AND (sqlc.narg(to_time)::timestamptz IS NULL OR h.created_at < sqlc.narg(to_time))) AS watchlist_alerts,
+       (SELECT count(*) FROM incidents i WHERE i.shift_id = sqlc.arg(shift_id)) AS incidents
+FROM entry_logs e
+WHERE e.shift_id = sqlc.arg(shift_id);
+
+-- name: CountVehiclesOnSite :one
+SELECT count(*) AS vehicles
+FROM site_presence sp
+LEFT JOIN guest_requests g ON g.id = sp.guest_request_id
+WHERE sp.pass_id IS NOT NULL OR COALESCE(g.plate_number, '') <> '';
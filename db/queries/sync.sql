@@ -0,0 +1,28 @@
+-- name: ListSyncPasses :many
+SELECT p.id, p.plate_number, p.vehicle_brand, p.vehicle_color, p.owner_user_id,
+       u.full_name AS owner_full_name, u.plot_number AS owner_plot_number,
+       (p.status = 'active' AND p.deleted_at IS NULL AND u.deleted_at IS NULL AND u.blocked_at IS NULL)::boolean AS active,
+       GREATEST(p.updated_at, u.updated_at)::timestamptz AS updated_at
+FROM passes p
+JOIN users u ON u.id = p.owner_user_id
+WHERE CASE WHEN sqlc.narg(since)::timestamptz IS NULL
+           THEN p.status = 'active' AND p.deleted_at IS NULL AND u.deleted_at IS NULL AND u.blocked_at IS NULL
+           ELSE p.updated_at > sqlc.narg(since) OR u.updated_at > sqlc.narg(since)
+      END
+ORDER BY p.id;
+
+-- name: ListSyncGuests :many
+SELECT g.id, g.guest_full_name, g.plate_number, g.guest_type, g.company_name, g.valid_from, g.valid_to, g.status,
+       u.full_name AS resident_full_name, u.plot_number AS resident_plot_number,
+       (g.status IN ('approved', 'arrived') AND g.deleted_at IS NULL AND u.deleted_at IS NULL)::boolean AS active,
+       GREATEST(g.updated_at, u.updated_at)::timestamptz AS updated_at
+FROM guest_requests g
+JOIN users u ON u.id = g.resident_user_id
+WHERE g.valid_from <= sqlc.arg(window_end)::timestamptz
+  AND (g.valid_to > sqlc.arg(window_start)::timestamptz OR g.status = 'arrived')
+  AND CASE WHEN sqlc.narg(since)::timestamptz IS NULL
+           THEN g.status IN ('approved', 'arrived') AND g.deleted_at IS NULL AND u.deleted_at IS NULL
+           ELSE g.updated_at > sqlc.narg(since) OR u.updated_at > sqlc.narg(since)
+                OR g.valid_from > sqlc.narg(upcoming_from)::timestamptz
+      END
+ORDER BY g.valid_from, g.id;
@@ -0,0 +1,105 @@
+-- name: SuspendUserPasses :many
+WITH targets AS (
+    SELECT id, status FROM passes
+    WHERE owner_user_id = sqlc.arg(user_id) AND deleted_at IS NULL AND status = 'active'
+    FOR UPDATE
+), changed AS (
+    UPDATE passes p
+    SET status = 'suspended',
+        updated_at = now(),
+        updated_by = sqlc.arg(actor_id)
+    FROM targets t
+    WHERE p.id = t.id
+    RETURNING p.id, t.status AS previous_status
+), logged AS (
+    INSERT INTO user_cascade_items (user_id, action, pass_id, previous_status, new_status, created_by)
+    SELECT sqlc.arg(user_id), 'block', id, previous_status, 'suspended', sqlc.arg(actor_id) FROM changed
+)
+SELECT id FROM changed;
+
+-- name: RevokeUserPasses :many
+WITH targets AS (
+    SELECT id, status FROM passes
+    WHERE owner_user_id = sqlc.arg(user_id) AND deleted_at IS NULL AND status IN ('active', 'suspended')
+    FOR UPDATE
+), changed AS (
+    UPDATE passes p
+    SET status = 'revoked',
+        updated_at = now(),
+        updated_by = sqlc.arg(actor_id)
+    FROM targets t
+    WHERE p.id = t.id
+    RETURNING p.id, t.status AS previous_status
+), logged AS (
+    INSERT INTO user_cascade_items (user_id, action, pass_id, previous_status, new_status, created_by)
+    SELECT sqlc.arg(user_id), 'delete', id, previous_status, 'revoked', sqlc.arg(actor_id) FROM changed
+)
+SELECT id FROM changed;
+
+-- name: SuspendUserGuestRequests :many
+WITH targets AS (
+    SELECT id, status FROM guest_requests
+    WHERE resident_user_id = sqlc.arg(user_id) AND deleted_at IS NULL AND status IN ('pending', 'approved')
+    FOR UPDATE
+), changed AS (
+    UPDATE guest_requests g
+    SET status = 'suspended',
+        updated_at = now(),
+        updated_by = sqlc.arg(actor_id)
+    FROM targets t
+    WHERE g.id = t.id
+    RETURNING g.id, t.status AS previous_status
+), logged AS (
+    INSERT INTO user_cascade_items (user_id, action, guest_request_id, previous_status, new_status, created_by)
+    SELECT sqlc.arg(user_id), 'block', id, previous_status, 'suspended', sqlc.arg(actor_id) FROM changed
+)
+SELECT id FROM changed;
+
+-- name: CancelUserGuestRequests :many
+WITH targets AS (
+    SELECT id, status FROM guest_requests
+    WHERE resident_user_id = sqlc.arg(user_id) AND deleted_at IS NULL AND status IN ('pending', 'approved', 'suspended')
+    FOR UPDATE
+), changed AS (
+    UPDATE guest_requests g
+    SET status = 'cancelled',
+        updated_at = now(),
+        updated_by = sqlc.arg(actor_id)
+    FROM targets t
+    WHERE g.id = t.id
+    RETURNING g.id, t.status AS previous_status
+), logged AS (
+    INSERT INTO user_cascade_items (user_id, action, guest_request_id, previous_status, new_status, created_by)
+    SELECT sqlc.arg(user_id), 'delete', id, previous_status, 'cancelled', sqlc.arg(actor_id) FROM changed
+)
+SELECT id FROM changed;
+
+-- name: RestoreCascadePasses :many
+WITH items AS (
+    UPDATE user_cascade_items
+    SET restored_at = now()
+    WHERE user_id = sqlc.arg(user_id) AND action = sqlc.arg(action) AND pass_id IS NOT NULL AND restored_at IS NULL
+    RETURNING pass_id, previous_status, new_status
+)
+UPDATE passes p
+SET status = i.previous_status,
+    updated_at = now(),
+    updated_by = sqlc.arg(actor_id)
+FROM items i
+WHERE p.id = i.pass_id AND p.deleted_at IS NULL AND p.status = i.new_status
+RETURNING p.id;
+
+-- name: RestoreCascadeGuestRequests :many
+WITH items AS (
+    UPDATE user_cascade_items
+    SET restored_at = now()
+    WHERE user_id = sqlc.arg(user_id) AND action = sqlc.arg(action) AND guest_request_id IS NOT NULL AND restored_at IS NULL
+    RETURNING guest_request_id, previous_status, new_status
+)
+UPDATE guest_requests g
+SET status = i.previous_status,
+    updated_at = now(),
+    updated_by = sqlc.arg(actor_id)
+FROM items i
+WHERE g.id = i.guest_request_id AND g.deleted_at IS NULL AND g.status = i.new_status AND g.valid_to > now()
+RETURNING g.id;
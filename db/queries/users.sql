@@ -9,6 +9,15 @@ SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL;
 -- name: GetUserByIDAny :one
 SELECT * FROM users WHERE id = $1;
 
+-- name: GetUserByIDForUpdate :one
+SELECT * FROM users WHERE id = $1 FOR UPDATE;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: LockUserBootstrap :exec
+SELECT pg_advisory_xact_lock(hashtext('bootstrap_admin'));
+
 -- name: GetUserByEmail :one
 SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL;
 
@@ -64,3 +73,19 @@ SET blocked_at = NULL,
     updated_at = now(),
     updated_by = $2
 WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetUserByEmailAny :one
+SELECT * FROM users WHERE email = $1;
+
+-- name: UpsertUserByEmail :one
+INSERT INTO users (email, password_hash, role, full_name, plot_number, created_by, updated_by)
+VALUES (sqlc.arg(email), sqlc.arg(password_hash), sqlc.arg(role), sqlc.arg(full_name), sqlc.arg(plot_number), sqlc.arg(actor_id), sqlc.arg(actor_id))
+ON CONFLICT (email) DO UPDATE
+SET password_hash = CASE WHEN sqlc.arg(replace_password)::bool THEN EXCLUDED.password_hash ELSE users.password_hash END,
+    role = EXCLUDED.role,
+    full_name = EXCLUDED.full_name,
+    plot_number = EXCLUDED.plot_number,
+    deleted_at = NULL,
+    updated_at = now(),
+    updated_by = EXCLUDED.updated_by
+RETURNING *;
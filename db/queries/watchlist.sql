@@ -0,0 +1,45 @@
+-- name: CreateWatchlistEntry :one
+INSERT INTO plate_watchlist (plate_number, severity, reason, expires_at, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetWatchlistEntryByID :one
+SELECT * FROM plate_watchlist WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetWatchlistEntryByPlate :one
+SELECT * FROM plate_watchlist WHERE plate_number = $1 AND deleted_at IS NULL;
+
+-- name: ListWatchlist :many
+SELECT * FROM plate_watchlist
+WHERE deleted_at IS NULL AND (($1::bool) OR expires_at IS NULL OR expires_at > now())
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateWatchlistEntry :one
+UPDATE plate_watchlist
+SET plate_number = $2,
+    severity = $3,
+    reason = $4,
+    expires_at = $5,
+    updated_at = now(),
+    updated_by = $6
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteWatchlistEntry :execrows
+UPDATE plate_watchlist
+SET deleted_at = now(),
+    updated_at = now(),
+    updated_by = $2
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: CreateWatchlistHit :one
+INSERT INTO watchlist_hits (watchlist_id, plate_number, pass_id, guest_request_id, user_id, source, outcome, comment)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListWatchlistHits :many
+SELECT * FROM watchlist_hits
+WHERE watchlist_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
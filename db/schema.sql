@@ -15,6 +15,29 @@ CREATE TABLE IF NOT EXISTS users (
     deleted_at TIMESTAMPTZ NULL
 );
 
+CREATE TABLE IF NOT EXISTS plots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    number TEXT NOT NULL,
+    street TEXT NULL,
+    contact_name TEXT NULL,
+    contact_phone TEXT NULL,
+    contact_email TEXT NULL,
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'archived')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS plot_residents (
+    plot_id UUID NOT NULL REFERENCES plots(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    relation TEXT NOT NULL CHECK (relation IN ('owner', 'family', 'tenant')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    PRIMARY KEY (plot_id, user_id)
+);
+
 CREATE TABLE IF NOT EXISTS passes (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -26,7 +49,32 @@ CREATE TABLE IF NOT EXISTS passes (
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
     updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
-    deleted_at TIMESTAMPTZ NULL
+    deleted_at TIMESTAMPTZ NULL,
+    plot_id UUID NULL REFERENCES plots(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS guest_series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resident_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    guest_full_name TEXT NOT NULL,
+    plate_number TEXT NOT NULL,
+    rrule TEXT NOT NULL,
+    starts_on DATE NOT NULL,
+    ends_on DATE NULL,
+    start_minute SMALLINT NOT NULL CHECK (start_minute BETWEEN 0 AND 1439),
+    end_minute SMALLINT NOT NULL CHECK (end_minute BETWEEN 1 AND 1440),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    previous_series_id UUID NULL REFERENCES guest_series(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ NULL,
+    review_reason TEXT NULL,
+    CHECK (start_minute <> end_minute),
+    CHECK (ends_on IS NULL OR ends_on >= starts_on)
 );
 
 CREATE TABLE IF NOT EXISTS guest_requests (
@@ -36,7 +84,28 @@ CREATE TABLE IF NOT EXISTS guest_requests (
     plate_number TEXT NOT NULL,
     valid_from TIMESTAMPTZ NOT NULL,
     valid_to TIMESTAMPTZ NOT NULL,
-    status TEXT NOT NULL DEFAULT 'pending',
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'arrived', 'completed', 'suspended')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMPTZ NULL,
+    reviewed_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ NULL,
+    review_reason TEXT NULL,
+    series_id UUID NULL REFERENCES guest_series(id) ON DELETE CASCADE,
+    occurrence_date DATE NULL,
+    guest_type TEXT NOT NULL DEFAULT 'vehicle'
+        CHECK (guest_type IN ('vehicle', 'pedestrian', 'taxi', 'delivery', 'service')),
+    company_name TEXT NULL,
+    plot_id UUID NULL REFERENCES plots(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS gates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
@@ -44,13 +113,262 @@ CREATE TABLE IF NOT EXISTS guest_requests (
     deleted_at TIMESTAMPTZ NULL
 );
 
+CREATE TABLE IF NOT EXISTS guard_gates (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gate_id UUID NOT NULL REFERENCES gates(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, gate_id)
+);
+
+CREATE TABLE IF NOT EXISTS pass_gates (
+    pass_id UUID NOT NULL REFERENCES passes(id) ON DELETE CASCADE,
+    gate_id UUID NOT NULL REFERENCES gates(id) ON DELETE CASCADE,
+    PRIMARY KEY (pass_id, gate_id)
+);
+
+CREATE TABLE IF NOT EXISTS guest_type_gates (
+    guest_type TEXT NOT NULL CHECK (guest_type IN ('vehicle', 'pedestrian', 'taxi', 'delivery', 'service')),
+    gate_id UUID NOT NULL REFERENCES gates(id) ON DELETE CASCADE,
+    PRIMARY KEY (guest_type, gate_id)
+);
+
+CREATE TABLE IF NOT EXISTS guard_shifts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    guard_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    gate_id UUID NULL REFERENCES gates(id),
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ended_at TIMESTAMPTZ NULL,
+    ended_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    handover_note TEXT NULL,
+    handover_from_id UUID NULL REFERENCES guard_shifts(id) ON DELETE SET NULL,
+    on_site_at_end INTEGER NULL
+);
+
 CREATE TABLE IF NOT EXISTS entry_logs (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    pass_id UUID NOT NULL REFERENCES passes(id) ON DELETE CASCADE,
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE CASCADE,
     guard_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     action TEXT NOT NULL,
-    action_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    comment TEXT NULL
+    action_at TIMESTAMPTZ NOT NULL,
+    comment TEXT NULL,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE CASCADE,
+    anomaly TEXT NULL CHECK (anomaly IN ('double_entry', 'exit_without_entry')),
+    override_reason TEXT NULL,
+    seq BIGINT NOT NULL,
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    amends_id UUID NULL REFERENCES entry_logs(id),
+    corrected_action TEXT NULL CHECK (corrected_action IN ('entry', 'exit', 'void')),
+    corrected_action_at TIMESTAMPTZ NULL,
+    gate_id UUID NULL REFERENCES gates(id),
+    shift_id UUID NULL REFERENCES guard_shifts(id),
+    device_at TIMESTAMPTZ NULL,
+    CONSTRAINT entry_logs_subject_check CHECK (num_nonnulls(pass_id, guest_request_id) = 1)
+);
+
+CREATE TABLE IF NOT EXISTS site_presence (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE CASCADE,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE CASCADE,
+    entry_log_id UUID NOT NULL REFERENCES entry_logs(id) ON DELETE CASCADE,
+    entered_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT site_presence_subject_check CHECK (num_nonnulls(pass_id, guest_request_id) = 1)
+);
+
+CREATE TABLE IF NOT EXISTS pass_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pass_id UUID NOT NULL REFERENCES passes(id) ON DELETE CASCADE,
+    weekdays SMALLINT NOT NULL CHECK (weekdays BETWEEN 1 AND 127),
+    start_minute SMALLINT NOT NULL CHECK (start_minute BETWEEN 0 AND 1439),
+    end_minute SMALLINT NOT NULL CHECK (end_minute BETWEEN 1 AND 1440),
+    skip_holidays BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (start_minute <> end_minute)
+);
+
+CREATE TABLE IF NOT EXISTS holidays (
+    day DATE PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS plate_watchlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    plate_number TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('info', 'warning', 'blacklist')),
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_hits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    watchlist_id UUID NOT NULL REFERENCES plate_watchlist(id) ON DELETE CASCADE,
+    plate_number TEXT NOT NULL,
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE SET NULL,
+    user_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    source TEXT NOT NULL CHECK (source IN ('search', 'entry', 'exit')),
+    outcome TEXT NOT NULL CHECK (outcome IN ('flagged', 'blocked', 'overridden')),
+    comment TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS guest_pins (
+    guest_request_id UUID PRIMARY KEY REFERENCES guest_requests(id) ON DELETE CASCADE,
+    code TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    used_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE IF NOT EXISTS saved_guests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resident_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    guest_full_name TEXT NOT NULL,
+    guest_type TEXT NOT NULL DEFAULT 'vehicle'
+        CHECK (guest_type IN ('vehicle', 'pedestrian', 'taxi', 'delivery', 'service')),
+    plate_number TEXT NOT NULL DEFAULT '',
+    company_name TEXT NULL,
+    notes TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    category TEXT NOT NULL CHECK (category IN ('barrier_damage', 'aggressive_visitor', 'unknown_vehicle', 'property_damage', 'other')),
+    severity TEXT NOT NULL CHECK (severity IN ('low', 'medium', 'high', 'critical')),
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'in_progress', 'resolved', 'dismissed')),
+    title TEXT NOT NULL,
+    description TEXT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    reported_by UUID NOT NULL REFERENCES users(id),
+    gate_id UUID NULL REFERENCES gates(id),
+    shift_id UUID NULL REFERENCES guard_shifts(id) ON DELETE SET NULL,
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE SET NULL,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE SET NULL,
+    plate_number TEXT NULL,
+    resolved_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS incident_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL,
+    status_from TEXT NULL,
+    status_to TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS incident_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS entry_photos (
+    id UUID PRIMARY KEY,
+    entry_log_id UUID NOT NULL UNIQUE REFERENCES entry_logs(id) ON DELETE CASCADE,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    thumbnail_key TEXT NOT NULL UNIQUE,
+    uploaded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS cameras (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    gate_id UUID NULL REFERENCES gates(id),
+    direction TEXT NOT NULL CHECK (direction IN ('entry', 'exit')),
+    operator_user_id UUID NOT NULL REFERENCES users(id),
+    token_hash TEXT NOT NULL UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT true,
+    last_seen_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE IF NOT EXISTS plate_reads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    camera_id UUID NOT NULL REFERENCES cameras(id),
+    plate_raw TEXT NOT NULL,
+    plate_number TEXT NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    captured_at TIMESTAMPTZ NOT NULL,
+    decision TEXT NOT NULL CHECK (decision IN ('allow', 'deny', 'manual_review')),
+    reason TEXT NOT NULL,
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE SET NULL,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE SET NULL,
+    entry_log_id UUID NULL REFERENCES entry_logs(id),
+    review_status TEXT NULL CHECK (review_status IN ('pending', 'allowed', 'denied')),
+    reviewed_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ NULL,
+    review_comment TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS gate_barriers (
+    gate_id UUID PRIMARY KEY REFERENCES gates(id) ON DELETE CASCADE,
+    driver TEXT NOT NULL CHECK (driver IN ('http', 'mqtt', 'noop')),
+    address TEXT NULL,
+    topic TEXT NULL,
+    username TEXT NULL,
+    secret TEXT NULL,
+    auto_open BOOLEAN NOT NULL DEFAULT true,
+    hold_seconds INTEGER NOT NULL DEFAULT 0 CHECK (hold_seconds >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_by UUID NULL REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS barrier_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gate_id UUID NOT NULL REFERENCES gates(id),
+    command TEXT NOT NULL CHECK (command IN ('open', 'hold', 'status')),
+    driver TEXT NOT NULL,
+    entry_log_id UUID NULL REFERENCES entry_logs(id),
+    user_id UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    success BOOLEAN NOT NULL,
+    state TEXT NULL,
+    error TEXT NULL,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS user_cascade_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('block', 'delete')),
+    pass_id UUID NULL REFERENCES passes(id) ON DELETE CASCADE,
+    guest_request_id UUID NULL REFERENCES guest_requests(id) ON DELETE CASCADE,
+    previous_status TEXT NOT NULL,
+    new_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    restored_at TIMESTAMPTZ NULL,
+    CONSTRAINT user_cascade_items_subject_check CHECK (num_nonnulls(pass_id, guest_request_id) = 1)
 );
 
 CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
@@ -58,3 +376,49 @@ CREATE INDEX IF NOT EXISTS idx_users_blocked_at ON users (blocked_at);
 CREATE INDEX IF NOT EXISTS idx_passes_deleted_at ON passes (deleted_at);
 CREATE INDEX IF NOT EXISTS idx_guest_requests_deleted_at ON guest_requests (deleted_at);
 CREATE INDEX IF NOT EXISTS idx_passes_plate_number ON passes (plate_number);
+CREATE INDEX IF NOT EXISTS idx_pass_schedules_pass_id ON pass_schedules (pass_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plate_watchlist_plate_active ON plate_watchlist (plate_number) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_watchlist_hits_watchlist_id ON watchlist_hits (watchlist_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_status_valid_to ON guest_requests (status, valid_to);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_guest_request_id ON entry_logs (guest_request_id, action_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guest_requests_series_occurrence ON guest_requests (series_id, occurrence_date);
+CREATE INDEX IF NOT EXISTS idx_guest_series_status_dates ON guest_series (status, starts_on, ends_on);
+CREATE INDEX IF NOT EXISTS idx_guest_series_resident ON guest_series (resident_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_plate_window ON guest_requests (plate_number, valid_from, valid_to);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_type_window ON guest_requests (guest_type, valid_from);
+CREATE INDEX IF NOT EXISTS idx_saved_guests_resident ON saved_guests (resident_user_id, guest_full_name);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_resident_valid_from ON guest_requests (resident_user_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_action_at ON entry_logs (action_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_pass_id ON entry_logs (pass_id, action_at DESC);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_guard_user_id ON entry_logs (guard_user_id, action_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_site_presence_pass_id ON site_presence (pass_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_site_presence_guest_request_id ON site_presence (guest_request_id);
+CREATE INDEX IF NOT EXISTS idx_site_presence_entered_at ON site_presence (entered_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entry_logs_seq ON entry_logs (seq);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_amends_id ON entry_logs (amends_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gates_name ON gates (lower(name)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pass_gates_gate_id ON pass_gates (gate_id);
+CREATE INDEX IF NOT EXISTS idx_guest_type_gates_gate_id ON guest_type_gates (gate_id);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_gate_id ON entry_logs (gate_id, action_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guard_shifts_open ON guard_shifts (guard_user_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_guard_shifts_started_at ON guard_shifts (started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_guard_shifts_handover_from_id ON guard_shifts (handover_from_id);
+CREATE INDEX IF NOT EXISTS idx_entry_logs_shift_id ON entry_logs (shift_id);
+CREATE INDEX IF NOT EXISTS idx_incidents_occurred_at ON incidents (occurred_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_incidents_status ON incidents (status, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_incidents_shift_id ON incidents (shift_id);
+CREATE INDEX IF NOT EXISTS idx_incidents_plate_number ON incidents (plate_number);
+CREATE INDEX IF NOT EXISTS idx_incident_comments_incident_id ON incident_comments (incident_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_incident_attachments_incident_id ON incident_attachments (incident_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_entry_photos_created_at ON entry_photos (created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cameras_name ON cameras (lower(name)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_plate_reads_camera_id ON plate_reads (camera_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_plate_reads_pending ON plate_reads (created_at) WHERE review_status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_barrier_events_gate_id ON barrier_events (gate_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_passes_updated_at ON passes (updated_at);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_updated_at ON guest_requests (updated_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plots_number ON plots (number);
+CREATE INDEX IF NOT EXISTS idx_plot_residents_user_id ON plot_residents (user_id);
+CREATE INDEX IF NOT EXISTS idx_passes_plot_id ON passes (plot_id);
+CREATE INDEX IF NOT EXISTS idx_guest_requests_plot_id ON guest_requests (plot_id);
+CREATE INDEX IF NOT EXISTS idx_user_cascade_items_open ON user_cascade_items (user_id, action) WHERE restored_at IS NULL;
@@ -14,11 +14,18 @@ export interface User {
   deleted_at?: string;
 }
 
+export interface UserStateReport {
+  status: 'deleted' | 'restored' | 'blocked' | 'unblocked';
+  passes: string[];
+  guest_requests: string[];
+}
+
 export interface Pass {
   id: string;
   owner_user_id: string;
   owner_full_name?: string;
   owner_plot_number?: string;
+  plot_id?: string;
   plate_number: string;
   vehicle_brand?: string;
   vehicle_color?: string;
@@ -30,11 +37,24 @@ export interface Pass {
   deleted_at?: string;
 }
 
+export type GuestType = 'vehicle' | 'pedestrian' | 'taxi' | 'delivery' | 'service';
+
+export const guestTypeLabels: Record<GuestType, string> = {
+  vehicle: 'На машине',
+  pedestrian: 'Пешеход',
+  taxi: 'Такси',
+  delivery: 'Доставка',
+  service: 'Службы'
+};
+
 export interface GuestRequest {
   id: string;
   resident_user_id: string;
+  plot_id?: string;
   guest_full_name: string;
+  guest_type: GuestType;
   plate_number: string;
+  company_name?: string;
   valid_from: string;
   valid_to: string;
   status: string;
@@ -43,15 +63,390 @@ export interface GuestRequest {
   created_by?: string;
   updated_by?: string;
   deleted_at?: string;
+  reviewed_by?: string;
+  reviewed_at?: string;
+  review_reason?: string;
+}
+
+export interface SavedGuest {
+  id: string;
+  guest_full_name: string;
+  guest_type: GuestType;
+  plate_number: string;
+  company_name?: string;
+  notes?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface GuestSuggestion {
+  guest_full_name: string;
+  guest_type: GuestType;
+  plate_number: string;
+  company_name?: string;
+  visits: number;
+  last_visit_at: string;
+}
+
+export interface GuestSeries {
+  id: string;
+  resident_user_id: string;
+  guest_full_name: string;
+  plate_number: string;
+  rrule: string;
+  starts_on: string;
+  ends_on?: string;
+  start: string;
+  end: string;
+  status: string;
+  previous_series_id?: string;
+  created_at: string;
+  updated_at: string;
+  reviewed_by?: string;
+  reviewed_at?: string;
+  review_reason?: string;
+}
+
+export interface GateGuest {
+  id: string;
+  guest_full_name: string;
+  guest_type: GuestType;
+  company_name?: string;
+  plate_number: string;
+  valid_from: string;
+  valid_to: string;
+  status: string;
+  resident_full_name: string;
+  resident_plot_number?: string;
+}
+
+export interface GuestPin {
+  guest_request_id: string;
+  pin: string;
+  valid_to: string;
+}
+
+export interface GuestPinCheckInResult {
+  guest: GateGuest;
+  entry: EntryLog;
 }
 
 export interface EntryLog {
   id: string;
-  pass_id: string;
+  pass_id?: string;
+  guest_request_id?: string;
   guard_user_id: string;
   action: string;
   action_at: string;
   comment?: string;
+  anomaly?: EntryAnomaly;
+  override_reason?: string;
+  seq: number;
+  hash: string;
+  amends_id?: string;
+  corrected_action?: 'entry' | 'exit' | 'void';
+  corrected_action_at?: string;
+  gate_id?: string;
+  shift_id?: string;
+  device_at?: string;
+  photo?: EntryPhoto;
+  barrier?: BarrierEvent;
+}
+
+export interface EntryPhoto {
+  id: string;
+  url: string;
+  thumbnail_url: string;
+  url_expires_at: string;
+}
+
+export interface Gate {
+  id: string;
+  name: string;
+  description?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export type BarrierDriver = 'http' | 'mqtt' | 'noop';
+
+export interface GateBarrier {
+  gate_id: string;
+  driver: BarrierDriver;
+  address?: string;
+  topic?: string;
+  username?: string;
+  has_secret: boolean;
+  auto_open: boolean;
+  hold_seconds: number;
+  updated_at: string;
+}
+
+export interface BarrierEvent {
+  id: string;
+  gate_id: string;
+  command: 'open' | 'hold' | 'status';
+  driver: BarrierDriver;
+  entry_log_id?: string;
+  user_id?: string;
+  success: boolean;
+  state?: string;
+  error?: string;
+  duration_ms: number;
+  created_at: string;
+}
+
+export interface Camera {
+  id: string;
+  name: string;
+  gate_id?: string;
+  direction: 'entry' | 'exit';
+  operator_user_id: string;
+  active: boolean;
+  last_seen_at?: string;
+  created_at: string;
+  updated_at: string;
+  token?: string;
+}
+
+export type PlateDecision = 'allow' | 'deny' | 'manual_review';
+
+export interface PlateRead {
+  id: string;
+  camera_id: string;
+  camera_name?: string;
+  direction?: 'entry' | 'exit';
+  gate_id?: string;
+  gate_name?: string;
+  plate_raw: string;
+  plate_number: string;
+  confidence: number;
+  captured_at: string;
+  decision: PlateDecision;
+  reason: string;
+  pass_id?: string;
+  guest_request_id?: string;
+  entry_log_id?: string;
+  review_status?: 'pending' | 'allowed' | 'denied';
+  reviewed_by?: string;
+  reviewed_at?: string;
+  review_comment?: string;
+  created_at: string;
+}
+
+export interface ShiftSummary {
+  entries: number;
+  exits: number;
+  guest_entries: number;
+  anomalies: number;
+  watchlist_alerts: number;
+  incidents: number;
+  vehicles_on_site: number;
+}
+
+export interface GuardShift {
+  id: string;
+  guard_user_id: string;
+  gate_id?: string;
+  started_at: string;
+  ended_at?: string;
+  ended_by?: string;
+  handover_note?: string;
+  summary: ShiftSummary;
+  handover?: {
+    shift_id: string;
+    guard_user_id: string;
+    ended_at?: string;
+    note?: string;
+  };
+}
+
+export type IncidentCategory =
+  | 'barrier_damage'
+  | 'aggressive_visitor'
+  | 'unknown_vehicle'
+  | 'property_damage'
+  | 'other';
+export type IncidentSeverity = 'low' | 'medium' | 'high' | 'critical';
+export type IncidentStatus = 'open' | 'in_progress' | 'resolved' | 'dismissed';
+
+export interface IncidentComment {
+  id: string;
+  author_id: string;
+  author_full_name?: string;
+  body: string;
+  status_from?: IncidentStatus;
+  status_to?: IncidentStatus;
+  created_at: string;
+}
+
+export interface IncidentAttachment {
+  id: string;
+  file_name: string;
+  content_type: string;
+  size_bytes: number;
+  uploaded_by: string;
+  created_at: string;
+  download_url: string;
+  url_expires_at: string;
+}
+
+export interface Incident {
+  id: string;
+  category: IncidentCategory;
+  severity: IncidentSeverity;
+  status: IncidentStatus;
+  title: string;
+  description?: string;
+  occurred_at: string;
+  reported_by: string;
+  gate_id?: string;
+  shift_id?: string;
+  pass_id?: string;
+  guest_request_id?: string;
+  plate_number?: string;
+  resolved_at?: string;
+  created_at: string;
+  updated_at: string;
+  comments?: IncidentComment[];
+  attachments?: IncidentAttachment[];
+}
+
+export type EntryAnomaly = 'double_entry' | 'exit_without_entry';
+
+export interface PresenceConflict {
+  error: string;
+  anomaly: EntryAnomaly;
+  entered_at?: string;
+}
+
+export interface OnSite {
+  pass_id?: string;
+  guest_request_id?: string;
+  plate_number: string;
+  guest_full_name?: string;
+  guest_type?: GuestType;
+  owner_user_id: string;
+  owner_full_name: string;
+  owner_plot_number?: string;
+  entered_at: string;
+  duration_seconds: number;
+  guard_full_name: string;
+}
+
+export const entryActionLabels: Record<EntryLogRecord['action'], string> = {
+  entry: 'въезд',
+  exit: 'выезд',
+  amendment: 'поправка'
+};
+
+export interface EntryLogRecord {
+  id: string;
+  pass_id?: string;
+  guest_request_id?: string;
+  action: 'entry' | 'exit' | 'amendment';
+  action_at: string;
+  comment?: string;
+  anomaly?: EntryAnomaly;
+  override_reason?: string;
+  seq: number;
+  hash: string;
+  amends_id?: string;
+  corrected_action?: 'entry' | 'exit' | 'void';
+  corrected_action_at?: string;
+  gate_id?: string;
+  gate_name?: string;
+  device_at?: string;
+  plate_number: string;
+  guest_full_name?: string;
+  owner_user_id: string;
+  owner_full_name: string;
+  owner_plot_number?: string;
+  guard_user_id: string;
+  guard_full_name: string;
+  photo?: EntryPhoto;
+}
+
+export interface SyncEntry {
+  id: string;
+  pass_id?: string;
+  guest_request_id?: string;
+  action: 'entry' | 'exit';
+  device_time: string;
+  gate_id?: string;
+  comment?: string;
+  override_reason?: string;
+}
+
+export type SyncStatus = 'created' | 'duplicate' | 'conflict' | 'invalid';
+
+export interface SyncResult {
+  id: string;
+  status: SyncStatus;
+  conflict?: string;
+  error?: string;
+  entry?: EntryLog;
+}
+
+export interface SyncPass {
+  id: string;
+  plate_number: string;
+  vehicle_brand?: string;
+  vehicle_color?: string;
+  owner_user_id: string;
+  owner_full_name: string;
+  owner_plot_number?: string;
+  active: boolean;
+  updated_at: string;
+}
+
+export interface SyncGuest {
+  id: string;
+  guest_full_name: string;
+  plate_number: string;
+  guest_type: string;
+  company_name?: string;
+  valid_from: string;
+  valid_to: string;
+  status: string;
+  resident_full_name: string;
+  resident_plot_number?: string;
+  active: boolean;
+  updated_at: string;
+}
+
+export interface SyncDelta {
+  full: boolean;
+  cursor: string;
+  passes: SyncPass[];
+  guests: SyncGuest[];
+}
+
+export type PlotRelation = 'owner' | 'family' | 'tenant';
+
+export interface PlotResident {
+  user_id: string;
+  full_name?: string;
+  email?: string;
+  role?: Role;
+  relation: PlotRelation;
+  created_at: string;
+}
+
+export interface Plot {
+  id: string;
+  number: string;
+  street?: string;
+  contact_name?: string;
+  contact_phone?: string;
+  contact_email?: string;
+  status: 'active' | 'archived';
+  relation?: PlotRelation;
+  residents?: PlotResident[];
+  created_at: string;
+  updated_at: string;
+  created_by?: string;
+  updated_by?: string;
 }
 
 export interface TokenResponse {
@@ -26,6 +26,9 @@ import Restore from '@mui/icons-material/Restore';
 import Check from '@mui/icons-material/Check';
 import Close from '@mui/icons-material/Close';
 import Search from '@mui/icons-material/Search';
+import ThumbUp from '@mui/icons-material/ThumbUp';
+import ThumbDown from '@mui/icons-material/ThumbDown';
+import EventBusy from '@mui/icons-material/EventBusy';
 import { useNavigate } from 'react-router-dom';
 import { useForm } from 'react-hook-form';
 import { z } from 'zod';
@@ -35,7 +38,7 @@ import api from '../api/client';
 import { GuestRequest, User } from '../api/types';
 
 const plateRegex = /^[ABEKMHOPCTYXАВЕКМНОРСТУХ]\d{3}[ABEKMHOPCTYXАВЕКМНОРСТУХ]{2}\d{2,3}$/i;
-const guestStatuses = ['pending', 'approved', 'rejected'] as const;
+const guestStatuses = ['pending', 'approved'] as const;
 
 const createGuestSchema = z
   .object({
@@ -56,8 +59,7 @@ const editGuestSchema = z
     guest_full_name: z.string().min(2, 'Минимум 2 символа'),
     plate_number: z.string().regex(plateRegex, 'Неверный формат номера'),
     valid_from: z.string().min(1, 'Укажите дату'),
-    valid_to: z.string().min(1, 'Укажите дату'),
-    status: z.enum(guestStatuses)
+    valid_to: z.string().min(1, 'Укажите дату')
   })
   .refine((data) => new Date(data.valid_to) >= new Date(data.valid_from), {
     path: ['valid_to'],
@@ -109,14 +111,19 @@ export default function AdminGuestsPage() {
     onSuccess: () => qc.invalidateQueries({ queryKey: ['guest'] })
   });
 
+  const reviewGuest = useMutation({
+    mutationFn: (payload: { id: string; action: 'approve' | 'reject' | 'cancel'; reason?: string }) =>
+      api.post(`/guest-requests/${payload.id}/${payload.action}`, payload.reason ? { reason: payload.reason } : {}),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['guest'] })
+  });
+
   const createForm = useForm<z.infer<typeof createGuestSchema>>({
     resolver: zodResolver(createGuestSchema),
     defaultValues: { resident_user_id: '', status: 'pending' }
   });
 
   const editForm = useForm<z.infer<typeof editGuestSchema>>({
-    resolver: zodResolver(editGuestSchema),
-    defaultValues: { status: 'pending' }
+    resolver: zodResolver(editGuestSchema)
   });
 
   const residents = (usersQuery.data ?? [])
@@ -140,7 +147,7 @@ export default function AdminGuestsPage() {
       .sort((a, b) => new Date(b.valid_from).getTime() - new Date(a.valid_from).getTime());
   }, [search, showDeleted, guestsQuery.data, userByID]);
 
-  const busy = createGuest.isPending || updateGuest.isPending || deleteGuest.isPending || restoreGuest.isPending;
+  const busy = createGuest.isPending || updateGuest.isPending || deleteGuest.isPending || restoreGuest.isPending || reviewGuest.isPending;
 
   return (
     <Layout title="Гостевые заявки">
@@ -187,6 +194,7 @@ export default function AdminGuestsPage() {
                   </Typography>
                   <Typography variant="body2" color="text.secondary">
                     {guest.plate_number} · статус {guest.status}
+                    {guest.review_reason ? ` · ${guest.review_reason}` : ''}
                   </Typography>
                   <Typography variant="body2" color="text.secondary">
                     {resident ? resident.full_name : guest.resident_user_id}
@@ -204,6 +212,43 @@ export default function AdminGuestsPage() {
                   </Stack>
                 </Box>
                 <Stack direction="row" spacing={0.5}>
+                  {guest.status === 'pending' && !guest.deleted_at && (
+                    <>
+                      <Tooltip title="Одобрить">
+                        <span>
+                          <IconButton aria-label="Одобрить заявку" color="success" onClick={() => reviewGuest.mutate({ id: guest.id, action: 'approve' })} disabled={busy}>
+                            <ThumbUp />
+                          </IconButton>
+                        </span>
+                      </Tooltip>
+                      <Tooltip title="Отклонить">
+                        <span>
+                          <IconButton
+                            aria-label="Отклонить заявку"
+                            color="error"
+                            onClick={() => {
+                              const reason = window.prompt('Причина отказа')?.trim();
+                              if (reason) {
+                                reviewGuest.mutate({ id: guest.id, action: 'reject', reason });
+                              }
+                            }}
+                            disabled={busy}
+                          >
+                            <ThumbDown />
+                          </IconButton>
+                        </span>
+                      </Tooltip>
+                    </>
+                  )}
+                  {(guest.status === 'pending' || guest.status === 'approved' || guest.status === 'suspended') && !guest.deleted_at && (
+                    <Tooltip title="Отменить">
+                      <span>
+                        <IconButton aria-label="Отменить заявку" onClick={() => reviewGuest.mutate({ id: guest.id, action: 'cancel' })} disabled={busy}>
+                          <EventBusy />
+                        </IconButton>
+                      </span>
+                    </Tooltip>
+                  )}
                   <Tooltip title="Изменить">
                     <span>
                       <IconButton
@@ -214,11 +259,10 @@ export default function AdminGuestsPage() {
                             guest_full_name: guest.guest_full_name,
                             plate_number: guest.plate_number,
                             valid_from: toLocalDateTimeInput(guest.valid_from),
-                            valid_to: toLocalDateTimeInput(guest.valid_to),
-                            status: guestStatuses.includes(guest.status as (typeof guestStatuses)[number]) ? (guest.status as (typeof guestStatuses)[number]) : 'pending'
+                            valid_to: toLocalDateTimeInput(guest.valid_to)
                           });
                         }}
-                        disabled={!!guest.deleted_at || busy}
+                        disabled={!!guest.deleted_at || busy || (guest.status !== 'pending' && guest.status !== 'approved')}
                       >
                         <Edit />
                       </IconButton>
@@ -394,13 +438,6 @@ export default function AdminGuestsPage() {
               error={!!editForm.formState.errors.valid_to}
               helperText={editForm.formState.errors.valid_to?.message}
             />
-            <TextField label="Статус" size="small" select {...editForm.register('status')}>
-              {guestStatuses.map((status) => (
-                <MenuItem key={status} value={status}>
-                  {status}
-                </MenuItem>
-              ))}
-            </TextField>
           </Stack>
         </DialogContent>
         <DialogActions>
@@ -424,8 +461,7 @@ export default function AdminGuestsPage() {
                         guest_full_name: data.guest_full_name.trim(),
                         plate_number: data.plate_number.trim().toUpperCase(),
                         valid_from: new Date(data.valid_from).toISOString(),
-                        valid_to: new Date(data.valid_to).toISOString(),
-                        status: data.status
+                        valid_to: new Date(data.valid_to).toISOString()
                       }
                     },
                     { onSuccess: () => setEditingGuest(null) }
@@ -35,7 +35,7 @@ import api from '../api/client';
 import { Pass, User } from '../api/types';
 
 const plateRegex = /^[ABEKMHOPCTYXАВЕКМНОРСТУХ]\d{3}[ABEKMHOPCTYXАВЕКМНОРСТУХ]{2}\d{2,3}$/i;
-const passStatuses = ['active', 'inactive', 'revoked'] as const;
+const passStatuses = ['active', 'inactive', 'suspended', 'revoked'] as const;
 
 const createPassSchema = z.object({
   owner_user_id: z.string().uuid('Выберите жителя'),
@@ -34,7 +34,7 @@ import { useForm } from 'react-hook-form';
 import { useNavigate } from 'react-router-dom';
 import { Layout } from '../components/Layout';
 import api from '../api/client';
-import { Role, User } from '../api/types';
+import { Role, User, UserStateReport } from '../api/types';
 import { ROLE_OPTIONS, roleLabel } from '../utils/roles';
 
 const createSchema = z
@@ -91,24 +91,31 @@ export default function AdminUsersPage() {
     onSuccess: () => qc.invalidateQueries({ queryKey: ['users'] })
   });
 
+  // Blocking or deleting a user also changes their passes and guest requests.
+  const onUserState = (report: UserStateReport) => {
+    qc.invalidateQueries({ queryKey: ['users'] });
+    if (report.passes.length > 0) qc.invalidateQueries({ queryKey: ['passes'] });
+    if (report.guest_requests.length > 0) qc.invalidateQueries({ queryKey: ['guest'] });
+  };
+
   const deleteUser = useMutation({
-    mutationFn: (id: string) => api.delete(`/users/${id}`),
-    onSuccess: () => qc.invalidateQueries({ queryKey: ['users'] })
+    mutationFn: async (id: string) => (await api.delete<UserStateReport>(`/users/${id}`)).data,
+    onSuccess: onUserState
   });
 
   const restoreUser = useMutation({
-    mutationFn: (id: string) => api.post(`/users/${id}/restore`, {}),
-    onSuccess: () => qc.invalidateQueries({ queryKey: ['users'] })
+    mutationFn: async (id: string) => (await api.post<UserStateReport>(`/users/${id}/restore`, {})).data,
+    onSuccess: onUserState
   });
 
   const blockUser = useMutation({
-    mutationFn: (id: string) => api.post(`/users/${id}/block`, {}),
-    onSuccess: () => qc.invalidateQueries({ queryKey: ['users'] })
+    mutationFn: async (id: string) => (await api.post<UserStateReport>(`/users/${id}/block`, {})).data,
+    onSuccess: onUserState
   });
 
   const unblockUser = useMutation({
-    mutationFn: (id: string) => api.post(`/users/${id}/unblock`, {}),
-    onSuccess: () => qc.invalidateQueries({ queryKey: ['users'] })
+    mutationFn: async (id: string) => (await api.post<UserStateReport>(`/users/${id}/unblock`, {})).data,
+    onSuccess: onUserState
   });
 
   const createForm = useForm<z.infer<typeof createSchema>>({
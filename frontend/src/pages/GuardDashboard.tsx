@@ -1,13 +1,22 @@
-import { useMutation, useQuery } from '@tanstack/react-query';
-import { Box, Button, Card, CardContent, Stack, TextField, Typography } from '@mui/material';
+import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
+import { Box, Button, Card, CardContent, MenuItem, Stack, TextField, Typography } from '@mui/material';
 import { Layout } from '../components/Layout';
 import api from '../api/client';
-import { Pass } from '../api/types';
+import { AxiosError } from 'axios';
+import { EntryLog, EntryLogRecord, GateGuest, GuestPinCheckInResult, GuestType, OnSite, Pass, PresenceConflict, entryActionLabels, guestTypeLabels } from '../api/types';
 import { useState } from 'react';
 
 export default function GuardDashboard() {
+  const queryClient = useQueryClient();
   const [plate, setPlate] = useState('');
   const [search, setSearch] = useState('');
+  const [pin, setPin] = useState('');
+  const [guestType, setGuestType] = useState<GuestType | ''>('');
+  const [journalAction, setJournalAction] = useState<'' | 'entry' | 'exit'>('');
+  const [journalPlate, setJournalPlate] = useState('');
+  const [conflict, setConflict] = useState<{ pass: Pass; action: 'entry' | 'exit'; details: PresenceConflict } | null>(null);
+  const [overrideReason, setOverrideReason] = useState('');
+  const [barrierError, setBarrierError] = useState<string | null>(null);
 
   const passesQuery = useQuery({
     queryKey: ['passes-search', search],
@@ -15,14 +24,105 @@ export default function GuardDashboard() {
     enabled: !!search
   });
 
-  const entryMutation = useMutation({
-    mutationFn: (id: string) => api.post(`/passes/${id}/entry`, {})
+  const guestsSearchQuery = useQuery({
+    queryKey: ['guest-search', search],
+    queryFn: async () => (await api.get<GateGuest[]>('/guest-requests/search', { params: { plate: search } })).data,
+    enabled: !!search
+  });
+
+  const expectedQuery = useQuery({
+    queryKey: ['guest-expected', guestType],
+    queryFn: async () =>
+      (await api.get<GateGuest[]>('/guest-requests/expected', { params: { type: guestType || undefined } })).data,
+    refetchInterval: 60_000
+  });
+
+  const journalQuery = useQuery({
+    queryKey: ['entry-logs', journalAction, journalPlate],
+    queryFn: async () =>
+      (
+        await api.get<EntryLogRecord[]>('/entry-logs', {
+          params: { action: journalAction || undefined, plate: journalPlate || undefined, limit: 20 }
+        })
+      ).data
+  });
+
+  const describeGuest = (guest: GateGuest) =>
+    [guest.plate_number, guest.guest_full_name, guest.guest_type !== 'vehicle' && guestTypeLabels[guest.guest_type], guest.company_name]
+      .filter(Boolean)
+      .join(' · ');
+
+  const formatWindow = (guest: GateGuest) =>
+    `${new Date(guest.valid_from).toLocaleString('ru-RU')} - ${new Date(guest.valid_to).toLocaleString('ru-RU')}`;
+
+  const onSiteQuery = useQuery({
+    queryKey: ['presence'],
+    queryFn: async () => (await api.get<OnSite[]>('/presence')).data,
+    refetchInterval: 60_000
+  });
+
+  const formatDuration = (seconds: number) => {
+    const minutes = Math.floor(seconds / 60);
+    return minutes < 60 ? `${minutes} мин` : `${Math.floor(minutes / 60)} ч ${minutes % 60} мин`;
+  };
+
+  // An entry logged at a gate with a barrier controller opens it; a failed
+  // command is shown so the guard opens the barrier by hand.
+  const showBarrier = (entry: EntryLog) =>
+    setBarrierError(entry.barrier && !entry.barrier.success ? entry.barrier.error ?? 'нет ответа' : null);
+
+  const movementMutation = useMutation({
+    mutationFn: async ({ pass, action, reason }: { pass: Pass; action: 'entry' | 'exit'; reason?: string }) =>
+      (await api.post<EntryLog>(`/passes/${pass.id}/${action}`, reason ? { override_reason: reason } : {})).data,
+    onSuccess: (entry) => {
+      showBarrier(entry);
+      setConflict(null);
+      setOverrideReason('');
+      queryClient.invalidateQueries({ queryKey: ['entry-logs'] });
+      queryClient.invalidateQueries({ queryKey: ['presence'] });
+    },
+    onError: (error, { pass, action }) => {
+      const response = (error as AxiosError<PresenceConflict>).response;
+      if (response?.status === 409 && response.data?.anomaly) {
+        setConflict({ pass, action, details: response.data });
+      }
+    }
+  });
+
+  const guestVisitMutation = useMutation({
+    mutationFn: async ({ id, action }: { id: string; action: 'check-in' | 'check-out' }) =>
+      (await api.post<EntryLog>(`/guest-requests/${id}/${action}`, {})).data,
+    onSuccess: (entry) => {
+      showBarrier(entry);
+      queryClient.invalidateQueries({ queryKey: ['guest-search'] });
+      queryClient.invalidateQueries({ queryKey: ['guest-expected'] });
+      queryClient.invalidateQueries({ queryKey: ['entry-logs'] });
+      queryClient.invalidateQueries({ queryKey: ['presence'] });
+    }
   });
 
-  const exitMutation = useMutation({
-    mutationFn: (id: string) => api.post(`/passes/${id}/exit`, {})
+  const pinCheckIn = useMutation({
+    mutationFn: async (code: string) =>
+      (await api.post<GuestPinCheckInResult>('/guest-requests/check-in-by-pin', { pin: code })).data,
+    onSuccess: (result) => {
+      showBarrier(result.entry);
+      setPin('');
+      queryClient.invalidateQueries({ queryKey: ['guest-search'] });
+      queryClient.invalidateQueries({ queryKey: ['guest-expected'] });
+    }
   });
 
+  const guestAction = (guest: GateGuest) =>
+    guest.status === 'arrived' ? (
+      <Button size="small" variant="contained" onClick={() => guestVisitMutation.mutate({ id: guest.id, action: 'check-out' })}>
+        Выезд
+      </Button>
+    ) : (
+      <Button size="small" variant="outlined" onClick={() => guestVisitMutation.mutate({ id: guest.id, action: 'check-in' })}>
+        Въезд
+      </Button>
+    );
+
   return (
     <Layout title="Пульт охраны">
       <Card>
@@ -31,7 +131,7 @@ export default function GuardDashboard() {
             Поиск пропуска
           </Typography>
           <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
-            Введите номер авто, чтобы найти активный пропуск
+            Введите номер авто, чтобы найти активный пропуск или гостевую заявку
           </Typography>
           <Stack direction={{ xs: 'column', sm: 'row' }} spacing={2}>
             <TextField
@@ -42,6 +142,42 @@ export default function GuardDashboard() {
             />
             <Button variant="contained" onClick={() => setSearch(plate)}>Найти</Button>
           </Stack>
+          {barrierError && (
+            <Typography variant="body2" color="error" sx={{ mt: 2 }}>
+              Шлагбаум не открылся: {barrierError}. Откройте его вручную.
+            </Typography>
+          )}
+        </CardContent>
+      </Card>
+      <Card sx={{ mt: 3 }}>
+        <CardContent>
+          <Typography variant="h6" sx={{ mb: 1, fontWeight: 700 }}>
+            Въезд по PIN
+          </Typography>
+          <Stack direction={{ xs: 'column', sm: 'row' }} spacing={2}>
+            <TextField
+              label="PIN гостя"
+              value={pin}
+              onChange={(e) => setPin(e.target.value)}
+              inputProps={{ inputMode: 'numeric', maxLength: 6 }}
+              fullWidth
+            />
+            <Button variant="contained" onClick={() => pinCheckIn.mutate(pin)} disabled={pin.length !== 6 || pinCheckIn.isPending}>
+              Впустить
+            </Button>
+          </Stack>
+          {pinCheckIn.data && (
+            <Typography variant="body2" sx={{ mt: 1 }}>
+              {pinCheckIn.data.guest.guest_full_name} · к {pinCheckIn.data.guest.resident_full_name}
+              {pinCheckIn.data.guest.resident_plot_number ? ` · участок ${pinCheckIn.data.guest.resident_plot_number}` : ''}
+              {' · въезд отмечен'}
+            </Typography>
+          )}
+          {pinCheckIn.isError && (
+            <Typography variant="body2" color="error" sx={{ mt: 1 }}>
+              PIN не принят
+            </Typography>
+          )}
         </CardContent>
       </Card>
       <Box sx={{ mt: 3 }}>
@@ -58,17 +194,171 @@ export default function GuardDashboard() {
                 </Typography>
               </Box>
               <Stack direction="row" spacing={1}>
-                <Button variant="outlined" onClick={() => entryMutation.mutate(pass.id)}>
+                <Button variant="outlined" onClick={() => movementMutation.mutate({ pass, action: 'entry' })}>
                   Въезд
                 </Button>
-                <Button variant="contained" onClick={() => exitMutation.mutate(pass.id)}>
+                <Button variant="contained" onClick={() => movementMutation.mutate({ pass, action: 'exit' })}>
                   Выезд
                 </Button>
               </Stack>
+              {conflict?.pass.id === pass.id && (
+                <Stack direction={{ xs: 'column', sm: 'row' }} spacing={2} sx={{ width: '100%' }}>
+                  <Typography variant="body2" color="error" sx={{ flexGrow: 1 }}>
+                    {conflict.details.anomaly === 'double_entry'
+                      ? `Машина уже на территории${
+                          conflict.details.entered_at ? ` с ${new Date(conflict.details.entered_at).toLocaleString('ru-RU')}` : ''
+                        }`
+                      : 'Въезд этой машины не отмечен'}
+                  </Typography>
+                  <TextField
+                    label="Причина"
+                    size="small"
+                    value={overrideReason}
+                    onChange={(e) => setOverrideReason(e.target.value)}
+                  />
+                  <Button
+                    variant="outlined"
+                    color="warning"
+                    disabled={!overrideReason.trim() || movementMutation.isPending}
+                    onClick={() => movementMutation.mutate({ pass, action: conflict.action, reason: overrideReason })}
+                  >
+                    Всё равно отметить
+                  </Button>
+                </Stack>
+              )}
+            </CardContent>
+          </Card>
+        ))}
+        {guestsSearchQuery.data?.map((guest) => (
+          <Card key={guest.id} sx={{ mb: 2 }}>
+            <CardContent sx={{ display: 'flex', alignItems: 'center', justifyContent: 'space-between', flexWrap: 'wrap', gap: 2 }}>
+              <Box>
+                <Typography variant="h6" sx={{ fontWeight: 700 }}>
+                  {guest.plate_number} · гость{guest.status === 'arrived' ? ' · на территории' : ''}
+                </Typography>
+                <Typography variant="body2" color="text.secondary">
+                  {guest.guest_full_name} · к {guest.resident_full_name}
+                  {guest.resident_plot_number ? ` · участок ${guest.resident_plot_number}` : ''}
+                </Typography>
+                <Typography variant="caption" color="text.secondary">
+                  {formatWindow(guest)}
+                </Typography>
+              </Box>
+              {guestAction(guest)}
             </CardContent>
           </Card>
         ))}
       </Box>
+      <Card sx={{ mt: 3 }}>
+        <CardContent>
+          <Stack direction="row" spacing={2} alignItems="center" sx={{ mb: 1 }}>
+            <Typography variant="h6" sx={{ fontWeight: 700, flexGrow: 1 }}>
+              Ожидаемые гости
+            </Typography>
+            <TextField
+              label="Тип"
+              size="small"
+              select
+              value={guestType}
+              onChange={(e) => setGuestType(e.target.value as GuestType | '')}
+              sx={{ minWidth: 160 }}
+            >
+              <MenuItem value="">Все</MenuItem>
+              {(Object.keys(guestTypeLabels) as GuestType[]).map((type) => (
+                <MenuItem key={type} value={type}>
+                  {guestTypeLabels[type]}
+                </MenuItem>
+              ))}
+            </TextField>
+          </Stack>
+          {expectedQuery.data?.length === 0 && (
+            <Typography variant="body2" color="text.secondary">
+              В ближайшие часы гостей не ожидается
+            </Typography>
+          )}
+          {expectedQuery.data?.map((guest) => (
+            <Box key={guest.id} sx={{ mb: 1, display: 'flex', alignItems: 'center', justifyContent: 'space-between', gap: 2 }}>
+              <Box>
+                <Typography variant="body2" sx={{ fontWeight: 600 }}>
+                  {describeGuest(guest)}
+                  {guest.status === 'arrived' ? ' · на территории' : ''}
+                </Typography>
+                <Typography variant="caption" color="text.secondary">
+                  {guest.resident_full_name}
+                  {guest.resident_plot_number ? ` · участок ${guest.resident_plot_number}` : ''} · {formatWindow(guest)}
+                </Typography>
+              </Box>
+              {guestAction(guest)}
+            </Box>
+          ))}
+        </CardContent>
+      </Card>
+      <Card sx={{ mt: 3 }}>
+        <CardContent>
+          <Typography variant="h6" sx={{ mb: 1, fontWeight: 700 }}>
+            На территории{onSiteQuery.data ? `: ${onSiteQuery.data.length}` : ''}
+          </Typography>
+          {onSiteQuery.data?.map((entry) => (
+            <Box key={entry.pass_id ?? entry.guest_request_id} sx={{ mb: 1 }}>
+              <Typography variant="body2" sx={{ fontWeight: 600 }}>
+                {[entry.plate_number, entry.guest_full_name].filter(Boolean).join(' · ')} · {formatDuration(entry.duration_seconds)}
+              </Typography>
+              <Typography variant="caption" color="text.secondary">
+                {entry.owner_full_name}
+                {entry.owner_plot_number ? ` · участок ${entry.owner_plot_number}` : ''} · с{' '}
+                {new Date(entry.entered_at).toLocaleString('ru-RU')}
+              </Typography>
+            </Box>
+          ))}
+        </CardContent>
+      </Card>
+      <Card sx={{ mt: 3 }}>
+        <CardContent>
+          <Stack direction={{ xs: 'column', sm: 'row' }} spacing={2} alignItems={{ sm: 'center' }} sx={{ mb: 1 }}>
+            <Typography variant="h6" sx={{ fontWeight: 700, flexGrow: 1 }}>
+              Журнал
+            </Typography>
+            <TextField
+              label="Номер"
+              size="small"
+              value={journalPlate}
+              onChange={(e) => setJournalPlate(e.target.value)}
+            />
+            <TextField
+              label="Действие"
+              size="small"
+              select
+              value={journalAction}
+              onChange={(e) => setJournalAction(e.target.value as '' | 'entry' | 'exit')}
+              sx={{ minWidth: 140 }}
+            >
+              <MenuItem value="">Все</MenuItem>
+              <MenuItem value="entry">Въезд</MenuItem>
+              <MenuItem value="exit">Выезд</MenuItem>
+            </TextField>
+          </Stack>
+          {journalQuery.data?.length === 0 && (
+            <Typography variant="body2" color="text.secondary">
+              Записей нет
+            </Typography>
+          )}
+          {journalQuery.data?.map((entry) => (
+            <Box key={entry.id} sx={{ mb: 1 }}>
+              <Typography variant="body2" sx={{ fontWeight: 600 }}>
+                {new Date(entry.action_at).toLocaleString('ru-RU')} · {entryActionLabels[entry.action]} ·{' '}
+                {[entry.plate_number, entry.guest_full_name].filter(Boolean).join(' · ')}
+              </Typography>
+              <Typography variant="caption" color="text.secondary">
+                {entry.owner_full_name}
+                {entry.owner_plot_number ? ` · участок ${entry.owner_plot_number}` : ''} · охранник {entry.guard_full_name}
+                {entry.gate_name ? ` · ${entry.gate_name}` : ''}
+                {entry.comment ? ` · ${entry.comment}` : ''}
+                {entry.override_reason ? ` · вручную: ${entry.override_reason}` : ''}
+              </Typography>
+            </Box>
+          ))}
+        </CardContent>
+      </Card>
     </Layout>
   );
 }
@@ -1,13 +1,25 @@
 import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
-import { Box, Button, Card, CardContent, Divider, Grid, Stack, TextField, Typography } from '@mui/material';
+import { Box, Button, Card, CardContent, Divider, Grid, MenuItem, Stack, TextField, Typography } from '@mui/material';
 import { Layout } from '../components/Layout';
 import api from '../api/client';
-import { GuestRequest, Pass } from '../api/types';
+import {
+  GuestPin,
+  GuestRequest,
+  GuestSeries,
+  GuestSuggestion,
+  GuestType,
+  Pass,
+  SavedGuest,
+  guestTypeLabels
+} from '../api/types';
 import { authStore } from '../store/auth';
+import { useState } from 'react';
 
 export default function ResidentDashboard() {
   const qc = useQueryClient();
   const user = authStore.getUser();
+  const [pins, setPins] = useState<Record<string, string>>({});
+  const [guestType, setGuestType] = useState<GuestType>('vehicle');
 
   const passesQuery = useQuery({
     queryKey: ['passes'],
@@ -19,6 +31,21 @@ export default function ResidentDashboard() {
     queryFn: async () => (await api.get<GuestRequest[]>('/guest-requests')).data
   });
 
+  const seriesQuery = useQuery({
+    queryKey: ['guest-series'],
+    queryFn: async () => (await api.get<GuestSeries[]>('/guest-series')).data
+  });
+
+  const savedGuestsQuery = useQuery({
+    queryKey: ['saved-guests'],
+    queryFn: async () => (await api.get<SavedGuest[]>('/saved-guests')).data
+  });
+
+  const suggestionsQuery = useQuery({
+    queryKey: ['saved-guests', 'suggestions'],
+    queryFn: async () => (await api.get<GuestSuggestion[]>('/saved-guests/suggestions')).data
+  });
+
   const createPass = useMutation({
     mutationFn: (payload: { plate_number: string; vehicle_brand?: string; vehicle_color?: string }) =>
       api.post('/passes', payload),
@@ -26,11 +53,73 @@ export default function ResidentDashboard() {
   });
 
   const createGuest = useMutation({
-    mutationFn: (payload: { guest_full_name: string; plate_number: string; valid_from: string; valid_to: string }) =>
-      api.post('/guest-requests', payload),
+    mutationFn: (payload: {
+      guest_full_name: string;
+      guest_type: GuestType;
+      plate_number?: string;
+      company_name?: string;
+      valid_from: string;
+      valid_to?: string;
+    }) => api.post('/guest-requests', payload),
     onSuccess: () => qc.invalidateQueries({ queryKey: ['guest'] })
   });
 
+  const cancelGuest = useMutation({
+    mutationFn: (id: string) => api.post(`/guest-requests/${id}/cancel`),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['guest'] })
+  });
+
+  const guestPin = useMutation({
+    mutationFn: async (id: string) => (await api.get<GuestPin>(`/guest-requests/${id}/pin`)).data,
+    onSuccess: (pin) => setPins((current) => ({ ...current, [pin.guest_request_id]: pin.pin }))
+  });
+
+  const saveGuest = useMutation({
+    mutationFn: (payload: {
+      guest_full_name: string;
+      guest_type: GuestType;
+      plate_number?: string;
+      company_name?: string;
+      notes?: string;
+    }) => api.post('/saved-guests', payload),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['saved-guests'] })
+  });
+
+  const deleteSavedGuest = useMutation({
+    mutationFn: (id: string) => api.delete(`/saved-guests/${id}`),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['saved-guests'] })
+  });
+
+  const inviteSavedGuest = useMutation({
+    mutationFn: (payload: { id: string; valid_from: string; valid_to?: string }) =>
+      api.post(`/saved-guests/${payload.id}/guest-requests`, {
+        valid_from: payload.valid_from,
+        valid_to: payload.valid_to
+      }),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['guest'] })
+  });
+
+  const createSeries = useMutation({
+    mutationFn: (payload: {
+      guest_full_name: string;
+      plate_number: string;
+      weekdays: number[];
+      starts_on: string;
+      until: string;
+      start: string;
+      end: string;
+    }) => api.post('/guest-series', payload),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['guest-series'] })
+  });
+
+  const cancelSeries = useMutation({
+    mutationFn: (id: string) => api.post(`/guest-series/${id}/cancel`),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['guest-series'] });
+      qc.invalidateQueries({ queryKey: ['guest'] });
+    }
+  });
+
   return (
     <Layout title="Мои пропуска">
       {user?.plot_number && (
@@ -94,19 +183,47 @@ export default function ResidentDashboard() {
                 const form = e.currentTarget as HTMLFormElement;
                 const formData = new FormData(form);
                 const validFromRaw = String(formData.get('valid_from'));
-                const validToRaw = String(formData.get('valid_to'));
+                const validToRaw = String(formData.get('valid_to') ?? '');
+                const plate = String(formData.get('plate_number') ?? '');
+                const company = String(formData.get('company_name') ?? '');
                 createGuest.mutate({
                   guest_full_name: String(formData.get('guest_full_name')),
-                  plate_number: String(formData.get('plate_number')),
+                  guest_type: guestType,
+                  plate_number: plate || undefined,
+                  company_name: company || undefined,
                   valid_from: new Date(validFromRaw).toISOString(),
-                  valid_to: new Date(validToRaw).toISOString()
+                  // Taxi and delivery fall back to their default visit length.
+                  valid_to: validToRaw ? new Date(validToRaw).toISOString() : undefined
                 });
                 form.reset();
               }}>
                 <TextField name="guest_full_name" label="ФИО гостя" size="small" required />
-                <TextField name="plate_number" label="Номер авто" size="small" required />
+                <TextField
+                  label="Тип гостя"
+                  size="small"
+                  select
+                  value={guestType}
+                  onChange={(e) => setGuestType(e.target.value as GuestType)}
+                >
+                  {(Object.keys(guestTypeLabels) as GuestType[]).map((type) => (
+                    <MenuItem key={type} value={type}>
+                      {guestTypeLabels[type]}
+                    </MenuItem>
+                  ))}
+                </TextField>
+                {guestType !== 'pedestrian' && (
+                  <TextField name="plate_number" label="Номер авто" size="small" required={guestType === 'vehicle'} />
+                )}
+                {guestType === 'delivery' && <TextField name="company_name" label="Компания" size="small" required />}
                 <TextField name="valid_from" label="С" size="small" type="datetime-local" InputLabelProps={{ shrink: true }} required />
-                <TextField name="valid_to" label="По" size="small" type="datetime-local" InputLabelProps={{ shrink: true }} required />
+                <TextField
+                  name="valid_to"
+                  label="По"
+                  size="small"
+                  type="datetime-local"
+                  InputLabelProps={{ shrink: true }}
+                  required={guestType === 'vehicle' || guestType === 'pedestrian'}
+                />
                 <Button variant="contained" type="submit">Создать</Button>
               </Stack>
               <Divider sx={{ my: 2 }} />
@@ -114,11 +231,181 @@ export default function ResidentDashboard() {
                 {guestsQuery.data?.map((guest) => (
                   <Box key={guest.id} sx={{ mb: 1 }}>
                     <Typography variant="body2" sx={{ fontWeight: 600 }}>
-                      {guest.guest_full_name}
+                      {guest.guest_full_name} · {guestTypeLabels[guest.guest_type] ?? guest.guest_type}
                     </Typography>
                     <Typography variant="caption" color="text.secondary">
                       {guest.status} · {guest.valid_from}
+                      {guest.review_reason ? ` · ${guest.review_reason}` : ''}
+                    </Typography>
+                    <Button
+                      size="small"
+                      disabled={saveGuest.isPending}
+                      onClick={() =>
+                        saveGuest.mutate({
+                          guest_full_name: guest.guest_full_name,
+                          guest_type: guest.guest_type,
+                          plate_number: guest.plate_number || undefined,
+                          company_name: guest.company_name
+                        })
+                      }
+                    >
+                      В мои гости
+                    </Button>
+                    {(guest.status === 'pending' || guest.status === 'approved' || guest.status === 'suspended') && (
+                      <Button size="small" onClick={() => cancelGuest.mutate(guest.id)} disabled={cancelGuest.isPending}>
+                        Отменить
+                      </Button>
+                    )}
+                    {guest.status === 'approved' &&
+                      (pins[guest.id] ? (
+                        <Typography variant="caption" sx={{ ml: 1, fontWeight: 700 }}>
+                          PIN для гостя: {pins[guest.id]}
+                        </Typography>
+                      ) : (
+                        <Button size="small" onClick={() => guestPin.mutate(guest.id)} disabled={guestPin.isPending}>
+                          PIN
+                        </Button>
+                      ))}
+                  </Box>
+                ))}
+              </Box>
+            </CardContent>
+          </Card>
+        </Grid>
+        <Grid item xs={12} md={6}>
+          <Card>
+            <CardContent>
+              <Typography variant="h6" sx={{ mb: 1, fontWeight: 700 }}>
+                Регулярные визиты
+              </Typography>
+              <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
+                Няня, уборка, репетитор — по дням недели до указанной даты
+              </Typography>
+              <Stack spacing={1.5} component="form" onSubmit={(e) => {
+                e.preventDefault();
+                const form = e.currentTarget as HTMLFormElement;
+                const formData = new FormData(form);
+                createSeries.mutate({
+                  guest_full_name: String(formData.get('guest_full_name')),
+                  plate_number: String(formData.get('plate_number')),
+                  weekdays: String(formData.get('weekdays'))
+                    .split(',')
+                    .map((day) => Number(day.trim()))
+                    .filter((day) => day >= 1 && day <= 7),
+                  starts_on: String(formData.get('starts_on')),
+                  until: String(formData.get('until')),
+                  start: String(formData.get('start')),
+                  end: String(formData.get('end'))
+                });
+                form.reset();
+              }}>
+                <TextField name="guest_full_name" label="ФИО гостя" size="small" required />
+                <TextField name="plate_number" label="Номер авто" size="small" required />
+                <TextField name="weekdays" label="Дни недели (1 — пн, через запятую)" size="small" required />
+                <TextField name="starts_on" label="С даты" size="small" type="date" InputLabelProps={{ shrink: true }} required />
+                <TextField name="until" label="По дату" size="small" type="date" InputLabelProps={{ shrink: true }} required />
+                <TextField name="start" label="Время с" size="small" type="time" InputLabelProps={{ shrink: true }} required />
+                <TextField name="end" label="Время до" size="small" type="time" InputLabelProps={{ shrink: true }} required />
+                <Button variant="contained" type="submit">Создать</Button>
+              </Stack>
+              <Divider sx={{ my: 2 }} />
+              <Box sx={{ maxHeight: 240, overflow: 'auto' }}>
+                {seriesQuery.data?.map((series) => (
+                  <Box key={series.id} sx={{ mb: 1 }}>
+                    <Typography variant="body2" sx={{ fontWeight: 600 }}>
+                      {series.guest_full_name} · {series.plate_number}
+                    </Typography>
+                    <Typography variant="caption" color="text.secondary">
+                      {series.status} · {series.rrule} · {series.start}–{series.end}
+                      {series.review_reason ? ` · ${series.review_reason}` : ''}
+                    </Typography>
+                    {(series.status === 'pending' || series.status === 'approved') && (
+                      <Button size="small" onClick={() => cancelSeries.mutate(series.id)} disabled={cancelSeries.isPending}>
+                        Отменить
+                      </Button>
+                    )}
+                  </Box>
+                ))}
+              </Box>
+            </CardContent>
+          </Card>
+        </Grid>
+        <Grid item xs={12} md={6}>
+          <Card>
+            <CardContent>
+              <Typography variant="h6" sx={{ mb: 1, fontWeight: 700 }}>
+                Мои гости
+              </Typography>
+              <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
+                Сохранённые гости: заявка в один клик на выбранное время
+              </Typography>
+              <Stack spacing={1.5} component="form" onSubmit={(e) => {
+                e.preventDefault();
+                const form = e.currentTarget as HTMLFormElement;
+                const formData = new FormData(form);
+                const validFromRaw = String(formData.get('valid_from'));
+                const validToRaw = String(formData.get('valid_to') ?? '');
+                inviteSavedGuest.mutate({
+                  id: String(formData.get('saved_guest_id')),
+                  valid_from: new Date(validFromRaw).toISOString(),
+                  valid_to: validToRaw ? new Date(validToRaw).toISOString() : undefined
+                });
+                form.reset();
+              }}>
+                <TextField name="saved_guest_id" label="Гость" size="small" select required defaultValue="">
+                  {savedGuestsQuery.data?.map((guest) => (
+                    <MenuItem key={guest.id} value={guest.id}>
+                      {[guest.guest_full_name, guest.plate_number, guest.company_name].filter(Boolean).join(' · ')}
+                    </MenuItem>
+                  ))}
+                </TextField>
+                <TextField name="valid_from" label="С" size="small" type="datetime-local" InputLabelProps={{ shrink: true }} required />
+                <TextField name="valid_to" label="По" size="small" type="datetime-local" InputLabelProps={{ shrink: true }} />
+                <Button variant="contained" type="submit" disabled={!savedGuestsQuery.data?.length}>
+                  Создать заявку
+                </Button>
+              </Stack>
+              <Divider sx={{ my: 2 }} />
+              <Box sx={{ maxHeight: 240, overflow: 'auto' }}>
+                {savedGuestsQuery.data?.map((guest) => (
+                  <Box key={guest.id} sx={{ mb: 1 }}>
+                    <Typography variant="body2" sx={{ fontWeight: 600 }}>
+                      {guest.guest_full_name} · {guestTypeLabels[guest.guest_type] ?? guest.guest_type}
+                      {guest.plate_number ? ` · ${guest.plate_number}` : ''}
+                    </Typography>
+                    {guest.notes && (
+                      <Typography variant="caption" color="text.secondary">
+                        {guest.notes}
+                      </Typography>
+                    )}
+                    <Button size="small" onClick={() => deleteSavedGuest.mutate(guest.id)} disabled={deleteSavedGuest.isPending}>
+                      Удалить
+                    </Button>
+                  </Box>
+                ))}
+                {suggestionsQuery.data?.map((suggestion) => (
+                  <Box key={`${suggestion.guest_full_name}-${suggestion.plate_number}`} sx={{ mb: 1 }}>
+                    <Typography variant="body2">
+                      {suggestion.guest_full_name}
+                      {suggestion.plate_number ? ` · ${suggestion.plate_number}` : ''}
+                    </Typography>
+                    <Typography variant="caption" color="text.secondary">
+                      Часто приезжает: {suggestion.visits} раз
                     </Typography>
+                    <Button
+                      size="small"
+                      disabled={saveGuest.isPending}
+                      onClick={() =>
+                        saveGuest.mutate({
+                          guest_full_name: suggestion.guest_full_name,
+                          guest_type: suggestion.guest_type,
+                          plate_number: suggestion.plate_number || undefined,
+                          company_name: suggestion.company_name
+                        })
+                      }
+                    >
+                      Сохранить
+                    </Button>
                   </Box>
                 ))}
               </Box>
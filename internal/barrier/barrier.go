@@ -0,0 +1,127 @@
+package barrier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DriverHTTP = "http"
+	DriverMQTT = "mqtt"
+	DriverNoop = "noop"
+)
+
+const (
+	StateOpen    = "open"
+	StateClosed  = "closed"
+	StateUnknown = "unknown"
+)
+
+const DefaultTimeout = 3 * time.Second
+
+var (
+	ErrUnknownDriver = errors.New("unknown barrier driver")
+	ErrInvalidConfig = errors.New("invalid barrier config")
+)
+
+// Driver controls the barrier of one gate.
+type Driver interface {
+	Open(ctx context.Context) error
+	// HoldOpen keeps the barrier up for the given time, e.g. for a truck or
+	// a convoy; the controller closes it afterwards.
+	HoldOpen(ctx context.Context, d time.Duration) error
+	Status(ctx context.Context) (Status, error)
+}
+
+type Status struct {
+	State  string
+	Detail string
+}
+
+// Config describes how a gate's controller is reached. Address is the relay
+// URL for the HTTP driver and the broker for MQTT; Topic is only used by
+// MQTT.
+type Config struct {
+	Driver   string
+	Address  string
+	Topic    string
+	Username string
+	Secret   string
+	Timeout  time.Duration
+}
+
+// New builds the driver named in cfg.
+func New(cfg Config) (Driver, error) {
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	switch cfg.Driver {
+	case DriverHTTP:
+		return NewHTTPRelay(cfg), nil
+	case DriverMQTT:
+		return NewMQTT(cfg), nil
+	default:
+		return Noop{}, nil
+	}
+}
+
+// Validate checks that cfg names a known driver and carries what it needs.
+func Validate(cfg Config) error {
+	switch cfg.Driver {
+	case DriverHTTP:
+		if !strings.HasPrefix(cfg.Address, "http://") && !strings.HasPrefix(cfg.Address, "https://") {
+			return fmt.Errorf("%w: http driver needs an http(s) address", ErrInvalidConfig)
+		}
+	case DriverMQTT:
+		if cfg.Address == "" || cfg.Topic == "" {
+			return fmt.Errorf("%w: mqtt driver needs a broker address and a topic", ErrInvalidConfig)
+		}
+		if strings.ContainsAny(cfg.Topic, "#+") {
+			return fmt.Errorf("%w: mqtt topic must not contain wildcards", ErrInvalidConfig)
+		}
+		if cfg.Secret != "" && cfg.Username == "" {
+			return fmt.Errorf("%w: mqtt password needs a username", ErrInvalidConfig)
+		}
+	case DriverNoop:
+	default:
+		return ErrUnknownDriver
+	}
+	return nil
+}
+
+// command is the payload sent to HTTP relays and MQTT controllers.
+type command struct {
+	Command string `json:"command"`
+	Seconds int    `json:"seconds,omitempty"`
+}
+
+func openCommand() command {
+	return command{Command: "open"}
+}
+
+func holdCommand(d time.Duration) command {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return command{Command: "hold", Seconds: seconds}
+}
+
+// Noop simulates a barrier that accepts every command and is always back
+// down by the time anyone asks; it stands in for gates without a controller
+// and in tests.
+type Noop struct{}
+
+func (Noop) Open(context.Context) error { return nil }
+
+func (Noop) HoldOpen(context.Context, time.Duration) error { return nil }
+
+func (Noop) Status(context.Context) (Status, error) {
+	return Status{State: StateClosed, Detail: "simulated"}, nil
+}
@@ -0,0 +1,211 @@
+package barrier
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		cfg Config
+		err error
+	}{
+		{Config{Driver: DriverNoop}, nil},
+		{Config{Driver: DriverHTTP, Address: "http://relay.local/gate"}, nil},
+		{Config{Driver: DriverHTTP, Address: "relay.local"}, ErrInvalidConfig},
+		{Config{Driver: DriverMQTT, Address: "broker:1883", Topic: "gates/main"}, nil},
+		{Config{Driver: DriverMQTT, Address: "broker:1883"}, ErrInvalidConfig},
+		{Config{Driver: DriverMQTT, Address: "broker:1883", Topic: "gates/#"}, ErrInvalidConfig},
+		{Config{Driver: DriverMQTT, Address: "broker:1883", Topic: "gates/main", Secret: "pw"}, ErrInvalidConfig},
+		{Config{Driver: "serial"}, ErrUnknownDriver},
+	}
+	for _, tc := range cases {
+		_, err := New(tc.cfg)
+		if tc.err == nil {
+			require.NoError(t, err, tc.cfg)
+		} else {
+			require.ErrorIs(t, err, tc.err, tc.cfg)
+		}
+	}
+	require.Equal(t, "broker:1883", brokerAddress("tcp://broker:1883"))
+	require.Equal(t, "broker:1883", brokerAddress("broker"))
+}
+
+func TestNoop(t *testing.T) {
+	ctx := context.Background()
+	driver, err := New(Config{Driver: DriverNoop})
+	require.NoError(t, err)
+	require.NoError(t, driver.Open(ctx))
+	require.NoError(t, driver.HoldOpen(ctx, time.Minute))
+	status, err := driver.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StateClosed, status.State)
+}
+
+func TestHTTPRelay(t *testing.T) {
+	var commands []command
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "denied", http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"state":"OPEN","detail":"loop occupied"}`))
+			return
+		}
+		var cmd command
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&cmd))
+		commands = append(commands, cmd)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	driver, err := New(Config{Driver: DriverHTTP, Address: server.URL, Secret: "s3cret"})
+	require.NoError(t, err)
+	require.NoError(t, driver.Open(ctx))
+	require.NoError(t, driver.HoldOpen(ctx, 90*time.Second))
+	require.Equal(t, []command{{Command: "open"}, {Command: "hold", Seconds: 90}}, commands)
+	status, err := driver.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, Status{State: StateOpen, Detail: "loop occupied"}, status)
+
+	denied, err := New(Config{Driver: DriverHTTP, Address: server.URL})
+	require.NoError(t, err)
+	err = denied.Open(ctx)
+	require.ErrorContains(t, err, "relay responded 401")
+}
+
+// fakeBroker accepts MQTT connections and hands every command published to
+// it to the test. With noAck set it never acknowledges a publish.
+type fakeBroker struct {
+	listener net.Listener
+	retained map[string]string
+	noAck    bool
+	commands chan *packets.PublishPacket
+	users    chan string
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &fakeBroker{listener: listener, retained: map[string]string{}, commands: make(chan *packets.PublishPacket, 16), users: make(chan string, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	packet, err := packets.ReadPacket(conn)
+	if err != nil {
+		return
+	}
+	connect, ok := packet.(*packets.ConnectPacket)
+	// Like a real broker, refuse a password sent without a username.
+	if !ok || (connect.PasswordFlag && !connect.UsernameFlag) {
+		return
+	}
+	b.users <- connect.Username
+	if err := packets.NewControlPacket(packets.Connack).Write(conn); err != nil {
+		return
+	}
+	for {
+		packet, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		switch p := packet.(type) {
+		case *packets.DisconnectPacket:
+			return
+		case *packets.PingreqPacket:
+			_ = packets.NewControlPacket(packets.Pingresp).Write(conn)
+		case *packets.PublishPacket:
+			b.commands <- p
+			if p.Qos > 0 && !b.noAck {
+				ack := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				ack.MessageID = p.MessageID
+				_ = ack.Write(conn)
+			}
+		case *packets.SubscribePacket:
+			ack := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			ack.MessageID = p.MessageID
+			ack.ReturnCodes = p.Qoss
+			_ = ack.Write(conn)
+			if payload, ok := b.retained[p.Topics[0]]; ok {
+				msg := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+				msg.TopicName = p.Topics[0]
+				msg.Retain = true
+				msg.Payload = []byte(payload)
+				_ = msg.Write(conn)
+			}
+		}
+	}
+}
+
+func TestMQTT(t *testing.T) {
+	broker := newFakeBroker(t)
+	broker.retained["gates/main/status"] = `{"state":"closed"}`
+	ctx := context.Background()
+	driver, err := New(Config{Driver: DriverMQTT, Address: "tcp://" + broker.listener.Addr().String(), Topic: "gates/main", Username: "gate", Secret: "pw", Timeout: time.Second})
+	require.NoError(t, err)
+
+	require.NoError(t, driver.HoldOpen(ctx, 30*time.Second))
+	require.Equal(t, "gate", <-broker.users)
+	cmd := <-broker.commands
+	require.Equal(t, "gates/main", cmd.TopicName)
+	require.Equal(t, byte(1), cmd.Qos)
+	require.JSONEq(t, `{"command":"hold","seconds":30}`, string(cmd.Payload))
+
+	status, err := driver.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StateClosed, status.State)
+	<-broker.users
+
+	silent, err := New(Config{Driver: DriverMQTT, Address: broker.listener.Addr().String(), Topic: "gates/back", Timeout: 200 * time.Millisecond})
+	require.NoError(t, err)
+	status, err = silent.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, StateUnknown, status.State)
+	<-broker.users
+
+	// A driver built around the config check still connects, without the
+	// lone password.
+	cfg := Config{Driver: DriverMQTT, Address: broker.listener.Addr().String(), Topic: "gates/main", Secret: "pw", Timeout: time.Second}
+	_, err = New(cfg)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.NoError(t, NewMQTT(cfg).Open(ctx))
+	require.Equal(t, "", <-broker.users)
+
+	// A command the broker never acknowledged is reported as failed.
+	unacked := newFakeBroker(t)
+	unacked.noAck = true
+	lost, err := New(Config{Driver: DriverMQTT, Address: unacked.listener.Addr().String(), Topic: "gates/main", Timeout: 200 * time.Millisecond})
+	require.NoError(t, err)
+	err = lost.Open(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "mqtt publish")
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := closed.Addr().String()
+	closed.Close()
+	unreachable, err := New(Config{Driver: DriverMQTT, Address: addr, Topic: "gates/main", Timeout: time.Second})
+	require.NoError(t, err)
+	require.ErrorContains(t, unreachable.Open(ctx), "mqtt connect")
+}
@@ -0,0 +1,96 @@
+package barrier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPRelay drives a network relay board: commands are POSTed to the
+// address as JSON and a GET on the same address reports the barrier state.
+type HTTPRelay struct {
+	address string
+	secret  string
+	client  *http.Client
+}
+
+func NewHTTPRelay(cfg Config) *HTTPRelay {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &HTTPRelay{address: cfg.Address, secret: cfg.Secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (d *HTTPRelay) Open(ctx context.Context) error {
+	return d.send(ctx, openCommand())
+}
+
+func (d *HTTPRelay) HoldOpen(ctx context.Context, hold time.Duration) error {
+	return d.send(ctx, holdCommand(hold))
+}
+
+func (d *HTTPRelay) Status(ctx context.Context) (Status, error) {
+	resp, err := d.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return Status{}, err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		State  string `json:"state"`
+		Detail string `json:"detail"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err != nil {
+		return Status{}, fmt.Errorf("relay status: %w", err)
+	}
+	state := strings.ToLower(strings.TrimSpace(body.State))
+	if state == "" {
+		state = StateUnknown
+	}
+	return Status{State: state, Detail: body.Detail}, nil
+}
+
+func (d *HTTPRelay) send(ctx context.Context, cmd command) error {
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	resp, err := d.do(ctx, http.MethodPost, payload)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.Body.Close()
+}
+
+func (d *HTTPRelay) do(ctx context.Context, method string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, d.address, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if d.secret != "" {
+		req.Header.Set("Authorization", "Bearer "+d.secret)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("relay request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("relay responded %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
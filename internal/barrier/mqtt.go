@@ -0,0 +1,169 @@
+package barrier
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTT publishes commands to a controller listening on an MQTT 3.1.1 topic.
+// The controller is expected to keep its state as a retained message on
+// "<topic>/status", either as JSON with a "state" field or as plain text.
+//
+// Every call opens its own short-lived connection. Commands go out with
+// QoS 1 and a call only succeeds once the broker acknowledged the command.
+type MQTT struct {
+	address  string
+	topic    string
+	username string
+	secret   string
+	timeout  time.Duration
+}
+
+const (
+	mqttQoS         = 1
+	mqttDefaultPort = "1883"
+)
+
+func NewMQTT(cfg Config) *MQTT {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &MQTT{address: cfg.Address, topic: cfg.Topic, username: cfg.Username, secret: cfg.Secret, timeout: timeout}
+}
+
+func (d *MQTT) Open(ctx context.Context) error {
+	return d.publish(ctx, openCommand())
+}
+
+func (d *MQTT) HoldOpen(ctx context.Context, hold time.Duration) error {
+	return d.publish(ctx, holdCommand(hold))
+}
+
+// Status reads the retained state message; a controller that never
+// published one is reported as unknown once the timeout runs out.
+func (d *MQTT) Status(ctx context.Context) (Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	client, err := d.connect(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	defer client.Disconnect(0)
+	statusTopic := d.topic + "/status"
+	messages := make(chan []byte, 1)
+	token := client.Subscribe(statusTopic, mqttQoS, func(_ mqtt.Client, msg mqtt.Message) {
+		select {
+		case messages <- msg.Payload():
+		default:
+		}
+	})
+	if err := wait(ctx, token); err != nil {
+		return Status{}, fmt.Errorf("mqtt subscribe: %w", err)
+	}
+	if code := token.(*mqtt.SubscribeToken).Result()[statusTopic]; code == 0x80 {
+		return Status{}, errors.New("mqtt subscribe rejected")
+	}
+	select {
+	case payload := <-messages:
+		return parseMQTTStatus(payload), nil
+	case <-ctx.Done():
+		return Status{State: StateUnknown, Detail: "no retained status"}, nil
+	}
+}
+
+func (d *MQTT) publish(ctx context.Context, cmd command) error {
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	client, err := d.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(0)
+	if err := wait(ctx, client.Publish(d.topic, mqttQoS, false, payload)); err != nil {
+		return fmt.Errorf("mqtt publish: %w", err)
+	}
+	return nil
+}
+
+func (d *MQTT) connect(ctx context.Context) (mqtt.Client, error) {
+	timeout := d.timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	// MQTT 3.1.1 allows a password only together with a username; the
+	// client leaves a lone password out.
+	opts := mqtt.NewClientOptions().
+		AddBroker("tcp://" + brokerAddress(d.address)).
+		SetClientID(clientID()).
+		SetUsername(d.username).
+		SetPassword(d.secret).
+		SetProtocolVersion(4).
+		SetCleanSession(true).
+		SetConnectTimeout(timeout).
+		SetWriteTimeout(timeout).
+		SetAutoReconnect(false)
+	client := mqtt.NewClient(opts)
+	if err := wait(ctx, client.Connect()); err != nil {
+		return nil, fmt.Errorf("mqtt connect: %w", err)
+	}
+	return client, nil
+}
+
+// wait blocks until the broker answered token or ctx is done.
+func wait(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// brokerAddress accepts "host:port", "tcp://host:port" or a bare host.
+func brokerAddress(address string) string {
+	if strings.Contains(address, "://") {
+		if parsed, err := url.Parse(address); err == nil {
+			address = parsed.Host
+		}
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return net.JoinHostPort(address, mqttDefaultPort)
+	}
+	return address
+}
+
+func clientID() string {
+	buf := make([]byte, 6)
+	_, _ = rand.Read(buf)
+	return "pipo-barrier-" + hex.EncodeToString(buf)
+}
+
+func parseMQTTStatus(payload []byte) Status {
+	var body struct {
+		State  string `json:"state"`
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		body.State = string(payload)
+	}
+	state := strings.ToLower(strings.TrimSpace(body.State))
+	if state == "" {
+		state = StateUnknown
+	}
+	return Status{State: state, Detail: body.Detail}
+}
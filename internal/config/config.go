@@ -1,8 +1,11 @@
 package config
 
 import (
+	"database/sql"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -21,6 +24,38 @@ type Config struct {
 	BootstrapEmail    string
 	BootstrapPassword string
 	BootstrapName     string
+	SiteTimezone      string
+	SiteLocation      *time.Location
+	GuestAutoApprove  time.Duration
+	GuestExpiryEvery  time.Duration
+	GuestHorizon      time.Duration
+	GuestOverlap      string
+	// GuestTypeDurations overrides the default visit length per guest type,
+	// e.g. "taxi=30m,delivery=1h".
+	GuestTypeDurations map[string]time.Duration
+	PresencePolicy     string
+	ShiftRequired      bool
+	FileStorageDir     string
+	AttachmentMaxBytes int64
+	AttachmentTypes    []string
+	FileLinkSecret     string
+	FileLinkTTL        time.Duration
+	// PhotoRetentionDays is how long gate photos are kept; 0 keeps them.
+	PhotoRetentionDays int
+	PhotoPurgeEvery    time.Duration
+	// ANPRMinConfidence is the plate-recognition confidence below which a
+	// camera read is left to a guard.
+	ANPRMinConfidence float64
+	BarrierTimeout    time.Duration
+	// BlockCascade and DeleteCascade decide what happens to a user's passes
+	// and guest requests when the user is blocked or deleted.
+	BlockCascade   string
+	DeleteCascade  string
+	RestoreCascade bool
+	// TxIsolation and TxAttempts apply to service transactions; conflicting
+	// ones are retried up to TxAttempts times.
+	TxIsolation sql.IsolationLevel
+	TxAttempts  int
 }
 
 func Load() (Config, error) {
@@ -39,6 +74,70 @@ func Load() (Config, error) {
 		BootstrapEmail:    getEnv("BOOTSTRAP_ADMIN_EMAIL", ""),
 		BootstrapPassword: getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
 		BootstrapName:     getEnv("BOOTSTRAP_ADMIN_NAME", "Администратор"),
+		SiteTimezone:      getEnv("SITE_TIMEZONE", "Europe/Moscow"),
+		GuestAutoApprove:  getEnvDuration("GUEST_AUTO_APPROVE_MAX_DURATION", 0),
+		GuestExpiryEvery:  getEnvDuration("GUEST_EXPIRY_INTERVAL", 5*time.Minute),
+		GuestHorizon:      getEnvDuration("GUEST_BOOKING_HORIZON", 90*24*time.Hour),
+		GuestOverlap:      getEnv("GUEST_OVERLAP_POLICY", "warn"),
+		PresencePolicy:    getEnv("PRESENCE_POLICY", "reject"),
+		ShiftRequired:     getEnvBool("SHIFT_REQUIRED", false),
+		FileStorageDir:    getEnv("FILE_STORAGE_DIR", "data/files"),
+		AttachmentTypes:   getEnvCSV("ATTACHMENT_TYPES", "image/jpeg,image/png"),
+		FileLinkSecret:    getEnv("FILE_LINK_SECRET", "change-me-files"),
+		FileLinkTTL:       getEnvDuration("FILE_LINK_TTL", 15*time.Minute),
+		PhotoPurgeEvery:   getEnvDuration("PHOTO_PURGE_INTERVAL", time.Hour),
+		BarrierTimeout:    getEnvDuration("BARRIER_TIMEOUT", 3*time.Second),
+		BlockCascade:      getEnv("USER_BLOCK_CASCADE", "suspend"),
+		DeleteCascade:     getEnv("USER_DELETE_CASCADE", "revoke"),
+		RestoreCascade:    getEnvBool("USER_RESTORE_CASCADE", true),
+	}
+
+	location, err := time.LoadLocation(cfg.SiteTimezone)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid SITE_TIMEZONE: %w", err)
+	}
+	cfg.SiteLocation = location
+	if cfg.GuestOverlap != "warn" && cfg.GuestOverlap != "reject" {
+		return Config{}, fmt.Errorf("invalid GUEST_OVERLAP_POLICY: %q", cfg.GuestOverlap)
+	}
+	if cfg.PresencePolicy != "reject" && cfg.PresencePolicy != "flag" {
+		return Config{}, fmt.Errorf("invalid PRESENCE_POLICY: %q", cfg.PresencePolicy)
+	}
+	if cfg.BlockCascade != "suspend" && cfg.BlockCascade != "none" {
+		return Config{}, fmt.Errorf("invalid USER_BLOCK_CASCADE: %q", cfg.BlockCascade)
+	}
+	if cfg.DeleteCascade != "revoke" && cfg.DeleteCascade != "none" {
+		return Config{}, fmt.Errorf("invalid USER_DELETE_CASCADE: %q", cfg.DeleteCascade)
+	}
+	switch isolation := getEnv("DB_TX_ISOLATION", "read_committed"); isolation {
+	case "read_committed":
+		cfg.TxIsolation = sql.LevelReadCommitted
+	case "repeatable_read":
+		cfg.TxIsolation = sql.LevelRepeatableRead
+	case "serializable":
+		cfg.TxIsolation = sql.LevelSerializable
+	default:
+		return Config{}, fmt.Errorf("invalid DB_TX_ISOLATION: %q", isolation)
+	}
+	cfg.TxAttempts, err = strconv.Atoi(getEnv("DB_TX_ATTEMPTS", "3"))
+	if err != nil || cfg.TxAttempts < 1 {
+		return Config{}, fmt.Errorf("invalid DB_TX_ATTEMPTS: %q", os.Getenv("DB_TX_ATTEMPTS"))
+	}
+	cfg.AttachmentMaxBytes, err = strconv.ParseInt(getEnv("ATTACHMENT_MAX_BYTES", "10485760"), 10, 64)
+	if err != nil || cfg.AttachmentMaxBytes <= 0 {
+		return Config{}, fmt.Errorf("invalid ATTACHMENT_MAX_BYTES: %q", os.Getenv("ATTACHMENT_MAX_BYTES"))
+	}
+	cfg.PhotoRetentionDays, err = strconv.Atoi(getEnv("PHOTO_RETENTION_DAYS", "30"))
+	if err != nil || cfg.PhotoRetentionDays < 0 {
+		return Config{}, fmt.Errorf("invalid PHOTO_RETENTION_DAYS: %q", os.Getenv("PHOTO_RETENTION_DAYS"))
+	}
+	cfg.ANPRMinConfidence, err = strconv.ParseFloat(getEnv("ANPR_MIN_CONFIDENCE", "0.8"), 64)
+	if err != nil || cfg.ANPRMinConfidence <= 0 || cfg.ANPRMinConfidence > 1 {
+		return Config{}, fmt.Errorf("invalid ANPR_MIN_CONFIDENCE: %q", os.Getenv("ANPR_MIN_CONFIDENCE"))
+	}
+	cfg.GuestTypeDurations, err = getEnvDurationMap("GUEST_TYPE_DURATIONS", "taxi=30m,delivery=1h")
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid GUEST_TYPE_DURATIONS: %w", err)
 	}
 
 	return cfg, nil
@@ -97,3 +196,22 @@ func getEnvCSV(key, fallback string) []string {
 	}
 	return items
 }
+
+// getEnvDurationMap parses a "key=duration,..." list.
+func getEnvDurationMap(key, fallback string) (map[string]time.Duration, error) {
+	items := getEnvCSV(key, fallback)
+	values := make(map[string]time.Duration, len(items))
+	for _, item := range items {
+		name, raw, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected key=duration, got %q", item)
+		}
+		parsed, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid duration for %s: %q", name, raw)
+		}
+		values[name] = parsed
+	}
+	return values, nil
+}
@@ -0,0 +1,272 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+// GateBarrierRequest replaces the controller config of a gate. An omitted
+// secret keeps the stored one; an empty string clears it.
+type GateBarrierRequest struct {
+	Driver      string  `json:"driver"`
+	Address     string  `json:"address"`
+	Topic       string  `json:"topic"`
+	Username    string  `json:"username"`
+	Secret      *string `json:"secret"`
+	AutoOpen    *bool   `json:"auto_open"`
+	HoldSeconds int32   `json:"hold_seconds"`
+}
+
+// GateBarrierResponse never carries the secret, only whether one is set.
+type GateBarrierResponse struct {
+	GateID      uuid.UUID `json:"gate_id"`
+	Driver      string    `json:"driver"`
+	Address     *string   `json:"address,omitempty"`
+	Topic       *string   `json:"topic,omitempty"`
+	Username    *string   `json:"username,omitempty"`
+	HasSecret   bool      `json:"has_secret"`
+	AutoOpen    bool      `json:"auto_open"`
+	HoldSeconds int32     `json:"hold_seconds"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type BarrierOpenRequest struct {
+	HoldSeconds int32 `json:"hold_seconds"`
+}
+
+type BarrierEventResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	GateID     uuid.UUID  `json:"gate_id"`
+	Command    string     `json:"command"`
+	Driver     string     `json:"driver"`
+	EntryLogID *uuid.UUID `json:"entry_log_id,omitempty"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Success    bool       `json:"success"`
+	State      *string    `json:"state,omitempty"`
+	Error      *string    `json:"error,omitempty"`
+	DurationMs int32      `json:"duration_ms"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type BarrierStatusResponse struct {
+	State  string `json:"state"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (h *Handler) HandleGetGateBarrier(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	config, err := h.Service.GetGateBarrier(r.Context(), id)
+	if err != nil {
+		writeBarrierError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGateBarrier(config))
+}
+
+func (h *Handler) HandleSetGateBarrier(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req GateBarrierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	autoOpen := true
+	if req.AutoOpen != nil {
+		autoOpen = *req.AutoOpen
+	}
+	config, err := h.Service.SetGateBarrier(r.Context(), service.GateBarrierInput{
+		GateID:      id,
+		Driver:      req.Driver,
+		Address:     req.Address,
+		Topic:       req.Topic,
+		Username:    req.Username,
+		Secret:      req.Secret,
+		AutoOpen:    autoOpen,
+		HoldSeconds: req.HoldSeconds,
+		ActorID:     actorFromContext(r),
+	})
+	if err != nil {
+		writeBarrierError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGateBarrier(config))
+}
+
+func (h *Handler) HandleDeleteGateBarrier(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.Service.DeleteGateBarrier(r.Context(), id); err != nil {
+		writeBarrierError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleOpenBarrier lets a guard open the barrier by hand; the body is
+// optional and only needed to hold it open.
+func (h *Handler) HandleOpenBarrier(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req BarrierOpenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	event, err := h.Service.OpenBarrier(r.Context(), service.BarrierCommandInput{
+		GateID:  id,
+		ActorID: actorFromContext(r),
+		Hold:    time.Duration(req.HoldSeconds) * time.Second,
+	})
+	switch {
+	case errors.Is(err, service.ErrBarrierFailed):
+		h.countBarrierFailure()
+		WriteJSON(w, http.StatusBadGateway, mapBarrierEvent(event))
+		return
+	case errors.Is(err, service.ErrGateNotAssigned):
+		WriteError(w, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		writeBarrierError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapBarrierEvent(event))
+}
+
+func (h *Handler) HandleBarrierStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	status, err := h.Service.BarrierStatus(r.Context(), id, actorFromContext(r))
+	if errors.Is(err, service.ErrBarrierFailed) {
+		h.countBarrierFailure()
+		WriteError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if err != nil {
+		writeBarrierError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, BarrierStatusResponse{State: status.State, Detail: status.Detail})
+}
+
+// HandleListBarrierEvents lists the commands sent to a gate's barrier,
+// newest first; failed=true keeps only the failures.
+func (h *Handler) HandleListBarrierEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	limit, offset := parsePagination(r)
+	events, err := h.Service.ListBarrierEvents(r.Context(), id, r.URL.Query().Get("failed") == "true", limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	resp := make([]BarrierEventResponse, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, mapBarrierEvent(event))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// triggerBarrier opens the gate's barrier after an entry was logged. The
+// entry stands whatever the controller does, so errors only show up in the
+// returned event.
+func (h *Handler) triggerBarrier(r *http.Request, entry repo.EntryLog) *BarrierEventResponse {
+	event, err := h.Service.TriggerBarrier(r.Context(), entry)
+	if err != nil || event == nil {
+		return nil
+	}
+	if !event.Success {
+		h.countBarrierFailure()
+	}
+	resp := mapBarrierEvent(*event)
+	return &resp
+}
+
+func (h *Handler) countBarrierFailure() {
+	if h.Metrics != nil {
+		h.Metrics.Passes.WithLabelValues("barrier_failed").Inc()
+	}
+}
+
+func writeBarrierError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "gate not found")
+	case errors.Is(err, service.ErrBarrierNotConfigured):
+		WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrBarrierConfig), errors.Is(err, service.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, "barrier error")
+	}
+}
+
+func mapGateBarrier(config repo.GateBarrier) GateBarrierResponse {
+	resp := GateBarrierResponse{
+		GateID:      config.GateID,
+		Driver:      config.Driver,
+		HasSecret:   config.Secret.Valid,
+		AutoOpen:    config.AutoOpen,
+		HoldSeconds: config.HoldSeconds,
+		UpdatedAt:   config.UpdatedAt,
+	}
+	if config.Address.Valid {
+		resp.Address = &config.Address.String
+	}
+	if config.Topic.Valid {
+		resp.Topic = &config.Topic.String
+	}
+	if config.Username.Valid {
+		resp.Username = &config.Username.String
+	}
+	return resp
+}
+
+func mapBarrierEvent(event repo.BarrierEvent) BarrierEventResponse {
+	resp := BarrierEventResponse{
+		ID:         event.ID,
+		GateID:     event.GateID,
+		Command:    event.Command,
+		Driver:     event.Driver,
+		EntryLogID: nullUUIDPtr(event.EntryLogID),
+		UserID:     nullUUIDPtr(event.UserID),
+		Success:    event.Success,
+		DurationMs: event.DurationMs,
+		CreatedAt:  event.CreatedAt,
+	}
+	if event.State.Valid {
+		resp.State = &event.State.String
+	}
+	if event.Error.Valid {
+		resp.Error = &event.Error.String
+	}
+	return resp
+}
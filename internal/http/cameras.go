@@ -0,0 +1,445 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+// CameraTokenHeader carries the device token of a plate-recognition camera.
+const CameraTokenHeader = "X-Camera-Token"
+
+type CameraRequest struct {
+	Name           string     `json:"name"`
+	GateID         *uuid.UUID `json:"gate_id"`
+	Direction      string     `json:"direction"`
+	OperatorUserID uuid.UUID  `json:"operator_user_id"`
+}
+
+// CameraUpdateRequest is a partial update of a camera; an all-zero gate_id
+// detaches it from its gate.
+type CameraUpdateRequest struct {
+	Name           *string    `json:"name"`
+	GateID         *uuid.UUID `json:"gate_id"`
+	Direction      *string    `json:"direction"`
+	OperatorUserID *uuid.UUID `json:"operator_user_id"`
+	Active         *bool      `json:"active"`
+}
+
+type CameraResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	GateID         *uuid.UUID `json:"gate_id,omitempty"`
+	Direction      string     `json:"direction"`
+	OperatorUserID uuid.UUID  `json:"operator_user_id"`
+	Active         bool       `json:"active"`
+	LastSeenAt     *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// Token is only returned when the camera is registered or its token
+	// rotated.
+	Token string `json:"token,omitempty"`
+}
+
+type CameraTokenResponse struct {
+	Token string `json:"token"`
+}
+
+type PlateReadRequest struct {
+	Plate      string     `json:"plate"`
+	Confidence float64    `json:"confidence"`
+	CapturedAt *time.Time `json:"captured_at"`
+}
+
+// PlateDecisionResponse tells the camera what to do with the vehicle.
+type PlateDecisionResponse struct {
+	ReadID         uuid.UUID  `json:"read_id"`
+	Decision       string     `json:"decision"`
+	Reason         string     `json:"reason"`
+	PlateNumber    string     `json:"plate_number"`
+	PassID         *uuid.UUID `json:"pass_id,omitempty"`
+	GuestRequestID *uuid.UUID `json:"guest_request_id,omitempty"`
+	EntryLogID     *uuid.UUID `json:"entry_log_id,omitempty"`
+}
+
+type PlateReadResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	CameraID       uuid.UUID  `json:"camera_id"`
+	CameraName     string     `json:"camera_name,omitempty"`
+	Direction      string     `json:"direction,omitempty"`
+	GateID         *uuid.UUID `json:"gate_id,omitempty"`
+	GateName       *string    `json:"gate_name,omitempty"`
+	PlateRaw       string     `json:"plate_raw"`
+	PlateNumber    string     `json:"plate_number"`
+	Confidence     float64    `json:"confidence"`
+	CapturedAt     time.Time  `json:"captured_at"`
+	Decision       string     `json:"decision"`
+	Reason         string     `json:"reason"`
+	PassID         *uuid.UUID `json:"pass_id,omitempty"`
+	GuestRequestID *uuid.UUID `json:"guest_request_id,omitempty"`
+	EntryLogID     *uuid.UUID `json:"entry_log_id,omitempty"`
+	ReviewStatus   *string    `json:"review_status,omitempty"`
+	ReviewedBy     *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	ReviewComment  *string    `json:"review_comment,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// PlateReviewRequest resolves a queued read. pass_id or guest_request_id
+// pick who is let in when the read matched no one or the wrong vehicle.
+type PlateReviewRequest struct {
+	Allow          bool       `json:"allow"`
+	PassID         *uuid.UUID `json:"pass_id"`
+	GuestRequestID *uuid.UUID `json:"guest_request_id"`
+	Comment        string     `json:"comment"`
+}
+
+func (h *Handler) HandleListCameras(w http.ResponseWriter, r *http.Request) {
+	cameras, err := h.Service.ListCameras(r.Context())
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	resp := make([]CameraResponse, 0, len(cameras))
+	for _, camera := range cameras {
+		resp = append(resp, mapCamera(camera))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleCreateCamera(w http.ResponseWriter, r *http.Request) {
+	var req CameraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	camera, token, err := h.Service.CreateCamera(r.Context(), service.CameraInput{
+		Name:       req.Name,
+		GateID:     derefUUID(req.GateID),
+		Direction:  req.Direction,
+		OperatorID: req.OperatorUserID,
+		ActorID:    actorFromContext(r),
+	})
+	if err != nil {
+		writeCameraError(w, err)
+		return
+	}
+	resp := mapCamera(camera)
+	resp.Token = token
+	WriteJSON(w, http.StatusCreated, resp)
+}
+
+func (h *Handler) HandleGetCamera(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	camera, err := h.Service.GetCamera(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapCamera(camera))
+}
+
+func (h *Handler) HandleUpdateCamera(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req CameraUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	camera, err := h.Service.GetCamera(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	input := service.CameraInput{
+		ID:         id,
+		Name:       camera.Name,
+		GateID:     camera.GateID.UUID,
+		Direction:  camera.Direction,
+		OperatorID: camera.OperatorUserID,
+		Active:     camera.Active,
+		ActorID:    actorFromContext(r),
+	}
+	if req.Name != nil {
+		input.Name = *req.Name
+	}
+	if req.GateID != nil {
+		input.GateID = *req.GateID
+	}
+	if req.Direction != nil {
+		input.Direction = *req.Direction
+	}
+	if req.OperatorUserID != nil {
+		input.OperatorID = *req.OperatorUserID
+	}
+	if req.Active != nil {
+		input.Active = *req.Active
+	}
+	updated, err := h.Service.UpdateCamera(r.Context(), input)
+	if err != nil {
+		writeCameraError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapCamera(updated))
+}
+
+func (h *Handler) HandleDeleteCamera(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.Service.DeleteCamera(r.Context(), id, actorFromContext(r)); err != nil {
+		writeCameraError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleRotateCameraToken(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	token, err := h.Service.RotateCameraToken(r.Context(), id, actorFromContext(r))
+	if err != nil {
+		writeCameraError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, CameraTokenResponse{Token: token})
+}
+
+// HandleCameraPlateRead takes a plate recognized by a camera, which signs
+// in with its device token rather than a user session.
+func (h *Handler) HandleCameraPlateRead(w http.ResponseWriter, r *http.Request) {
+	camera, err := h.Service.AuthenticateCamera(r.Context(), r.Header.Get(CameraTokenHeader))
+	switch {
+	case errors.Is(err, service.ErrInvalidCameraToken):
+		WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "camera error")
+		return
+	}
+	var req PlateReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	input := service.PlateReadInput{Plate: req.Plate, Confidence: req.Confidence}
+	if req.CapturedAt != nil {
+		input.CapturedAt = *req.CapturedAt
+	}
+	read, err := h.Service.RecordPlateRead(r.Context(), camera, input)
+	switch {
+	case errors.Is(err, service.ErrInvalidConfidence), errors.Is(err, service.ErrInvalidPlate):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "plate read error")
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Passes.WithLabelValues("camera_" + read.Decision).Inc()
+	}
+	WriteJSON(w, http.StatusCreated, PlateDecisionResponse{
+		ReadID:         read.ID,
+		Decision:       read.Decision,
+		Reason:         read.Reason,
+		PlateNumber:    read.PlateNumber,
+		PassID:         nullUUIDPtr(read.PassID),
+		GuestRequestID: nullUUIDPtr(read.GuestRequestID),
+		EntryLogID:     nullUUIDPtr(read.EntryLogID),
+	})
+}
+
+func (h *Handler) HandleListPlateReads(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := service.PlateReadFilter{
+		ReviewStatus: query.Get("review_status"),
+		Decision:     query.Get("decision"),
+	}
+	h.listPlateReads(w, r, filter)
+}
+
+// HandlePlateReviewQueue lists the reads waiting for a guard, newest first.
+func (h *Handler) HandlePlateReviewQueue(w http.ResponseWriter, r *http.Request) {
+	h.listPlateReads(w, r, service.PlateReadFilter{ReviewStatus: service.PlateReviewPending})
+}
+
+func (h *Handler) listPlateReads(w http.ResponseWriter, r *http.Request, filter service.PlateReadFilter) {
+	query := r.URL.Query()
+	var err error
+	if raw := query.Get("camera_id"); raw != "" {
+		if filter.CameraID, err = uuid.Parse(raw); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid camera_id")
+			return
+		}
+	}
+	if raw := query.Get("gate_id"); raw != "" {
+		if filter.GateID, err = uuid.Parse(raw); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid gate_id")
+			return
+		}
+	}
+	limit, offset := parsePagination(r)
+	rows, err := h.Service.ListPlateReads(r.Context(), filter, limit, offset)
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, "invalid filter")
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	resp := make([]PlateReadResponse, 0, len(rows))
+	for _, row := range rows {
+		item := mapPlateRead(repo.PlateRead{
+			ID:             row.ID,
+			CameraID:       row.CameraID,
+			PlateRaw:       row.PlateRaw,
+			PlateNumber:    row.PlateNumber,
+			Confidence:     row.Confidence,
+			CapturedAt:     row.CapturedAt,
+			Decision:       row.Decision,
+			Reason:         row.Reason,
+			PassID:         row.PassID,
+			GuestRequestID: row.GuestRequestID,
+			EntryLogID:     row.EntryLogID,
+			ReviewStatus:   row.ReviewStatus,
+			ReviewedBy:     row.ReviewedBy,
+			ReviewedAt:     row.ReviewedAt,
+			ReviewComment:  row.ReviewComment,
+			CreatedAt:      row.CreatedAt,
+		})
+		item.CameraName = row.CameraName
+		item.Direction = row.Direction
+		item.GateID = nullUUIDPtr(row.GateID)
+		if row.GateName.Valid {
+			item.GateName = &row.GateName.String
+		}
+		resp = append(resp, item)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleReviewPlateRead(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req PlateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	read, err := h.Service.ReviewPlateRead(r.Context(), service.PlateReviewInput{
+		ID:      id,
+		GuardID: actorFromContext(r),
+		Allow:   req.Allow,
+		PassID:  derefUUID(req.PassID),
+		GuestID: derefUUID(req.GuestRequestID),
+		Comment: req.Comment,
+	})
+	var conflict *service.PresenceConflictError
+	switch {
+	case errors.As(err, &conflict):
+		WriteJSON(w, http.StatusConflict, mapPresenceConflict(conflict))
+		return
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	case errors.Is(err, service.ErrPlateReadResolved), errors.Is(err, service.ErrGuestTransition),
+		errors.Is(err, service.ErrResidentInactive):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, service.ErrPlateReadTarget):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, service.ErrPlateBlacklisted), errors.Is(err, service.ErrOutsideGuestWindow):
+		WriteError(w, http.StatusForbidden, err.Error())
+		return
+	case writeMovementError(w, err):
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "review error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapPlateRead(read))
+}
+
+func writeCameraError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrCameraExists):
+		WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidInput), errors.Is(err, service.ErrInvalidEntryAction),
+		errors.Is(err, service.ErrUnknownGate), errors.Is(err, service.ErrCameraOperator):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, "camera error")
+	}
+}
+
+func mapCamera(camera repo.Camera) CameraResponse {
+	resp := CameraResponse{
+		ID:             camera.ID,
+		Name:           camera.Name,
+		GateID:         nullUUIDPtr(camera.GateID),
+		Direction:      camera.Direction,
+		OperatorUserID: camera.OperatorUserID,
+		Active:         camera.Active,
+		CreatedAt:      camera.CreatedAt,
+		UpdatedAt:      camera.UpdatedAt,
+	}
+	if camera.LastSeenAt.Valid {
+		resp.LastSeenAt = &camera.LastSeenAt.Time
+	}
+	return resp
+}
+
+func mapPlateRead(read repo.PlateRead) PlateReadResponse {
+	resp := PlateReadResponse{
+		ID:             read.ID,
+		CameraID:       read.CameraID,
+		PlateRaw:       read.PlateRaw,
+		PlateNumber:    read.PlateNumber,
+		Confidence:     read.Confidence,
+		CapturedAt:     read.CapturedAt,
+		Decision:       read.Decision,
+		Reason:         read.Reason,
+		PassID:         nullUUIDPtr(read.PassID),
+		GuestRequestID: nullUUIDPtr(read.GuestRequestID),
+		EntryLogID:     nullUUIDPtr(read.EntryLogID),
+		ReviewedBy:     nullUUIDPtr(read.ReviewedBy),
+		CreatedAt:      read.CreatedAt,
+	}
+	if read.ReviewStatus.Valid {
+		resp.ReviewStatus = &read.ReviewStatus.String
+	}
+	if read.ReviewedAt.Valid {
+		resp.ReviewedAt = &read.ReviewedAt.Time
+	}
+	if read.ReviewComment.Valid {
+		resp.ReviewComment = &read.ReviewComment.String
+	}
+	return resp
+}
@@ -2,10 +2,12 @@ package http
 
 import (
 	"context"
-	"database/sql"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
 
+	"pipo-edu-project/internal/barrier"
 	repo "pipo-edu-project/internal/repository/sqlc"
 	"pipo-edu-project/internal/service"
 )
@@ -21,10 +23,10 @@ type UserService interface {
 	ListUsers(ctx context.Context, includeDeleted bool, limit, offset int32) ([]repo.User, error)
 	UpdateUser(ctx context.Context, input service.UserUpdateInput) (repo.User, error)
 	UpdateUserPassword(ctx context.Context, input service.UserPasswordInput, passwordHash string) (repo.User, error)
-	SoftDeleteUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
-	RestoreUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
-	BlockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
-	UnblockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
+	SoftDeleteUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error)
+	RestoreUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error)
+	BlockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error)
+	UnblockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error)
 }
 
 type PassService interface {
@@ -35,6 +37,7 @@ type PassService interface {
 	ListPassesByOwner(ctx context.Context, owner uuid.UUID, includeDeleted bool, limit, offset int32) ([]repo.Pass, error)
 	SearchPasses(ctx context.Context, plate string, limit, offset int32) ([]repo.Pass, error)
 	UpdatePass(ctx context.Context, input service.PassUpdateInput) (repo.Pass, error)
+	ModifyPass(ctx context.Context, id uuid.UUID, fn func(current repo.Pass) (service.PassUpdateInput, error)) (repo.Pass, error)
 	SoftDeletePass(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
 	RestorePass(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
 }
@@ -46,11 +49,180 @@ type GuestService interface {
 	ListGuestRequests(ctx context.Context, includeDeleted bool, limit, offset int32) ([]repo.GuestRequest, error)
 	ListGuestRequestsByResident(ctx context.Context, resident uuid.UUID, includeDeleted bool, limit, offset int32) ([]repo.GuestRequest, error)
 	UpdateGuestRequest(ctx context.Context, input service.GuestUpdateInput) (repo.GuestRequest, error)
+	ModifyGuestRequest(ctx context.Context, id uuid.UUID, fn func(current repo.GuestRequest) (service.GuestUpdateInput, error)) (repo.GuestRequest, error)
 	SoftDeleteGuestRequest(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
 	RestoreGuestRequest(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
+	ApproveGuestRequest(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestRequest, error)
+	RejectGuestRequest(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestRequest, error)
+	CancelGuestRequest(ctx context.Context, id, actor uuid.UUID) (repo.GuestRequest, error)
+	ExpectedGuests(ctx context.Context, ahead time.Duration, guestType string, limit, offset int32) ([]repo.ListGateGuestsRow, error)
+	SearchGuestsByPlate(ctx context.Context, plate, guestType string, limit, offset int32) ([]repo.ListGateGuestsRow, error)
+	GuestConflicts(ctx context.Context, guest repo.GuestRequest) ([]uuid.UUID, error)
+}
+
+type GuestSeriesService interface {
+	CreateGuestSeries(ctx context.Context, input service.GuestSeriesInput) (repo.GuestSeries, error)
+	GetGuestSeries(ctx context.Context, id uuid.UUID) (repo.GuestSeries, error)
+	ListGuestSeries(ctx context.Context, limit, offset int32) ([]repo.GuestSeries, error)
+	ListGuestSeriesByResident(ctx context.Context, resident uuid.UUID, limit, offset int32) ([]repo.GuestSeries, error)
+	UpdateGuestSeries(ctx context.Context, input service.GuestSeriesInput) (repo.GuestSeries, error)
+	ApproveGuestSeries(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestSeries, error)
+	RejectGuestSeries(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestSeries, error)
+	CancelGuestSeries(ctx context.Context, id, actor uuid.UUID) (repo.GuestSeries, error)
+	ListGuestOccurrences(ctx context.Context, seriesID uuid.UUID, from, to string) ([]service.GuestOccurrence, error)
+	GetGuestOccurrence(ctx context.Context, seriesID uuid.UUID, day string) (repo.GuestRequest, error)
+	SkipGuestOccurrence(ctx context.Context, seriesID uuid.UUID, day string, actor uuid.UUID) (repo.GuestRequest, error)
+	UpdateGuestOccurrence(ctx context.Context, input service.GuestOccurrenceInput) (repo.GuestRequest, error)
+}
+
+type GuestPinService interface {
+	GuestPin(ctx context.Context, guest repo.GuestRequest, actor uuid.UUID) (repo.GuestPin, error)
+	RegenerateGuestPin(ctx context.Context, guest repo.GuestRequest, actor uuid.UUID) (repo.GuestPin, error)
+	GuestByPin(ctx context.Context, code string, guardID uuid.UUID) (repo.GetGuestByPinRow, error)
+}
+
+type SavedGuestService interface {
+	CreateSavedGuest(ctx context.Context, input service.SavedGuestInput) (repo.SavedGuest, error)
+	GetSavedGuest(ctx context.Context, id, resident uuid.UUID) (repo.SavedGuest, error)
+	ListSavedGuests(ctx context.Context, resident uuid.UUID, limit, offset int32) ([]repo.SavedGuest, error)
+	UpdateSavedGuest(ctx context.Context, input service.SavedGuestInput) (repo.SavedGuest, error)
+	DeleteSavedGuest(ctx context.Context, id, resident uuid.UUID) error
+	SuggestSavedGuests(ctx context.Context, resident uuid.UUID, limit int32) ([]repo.ListFrequentGuestsRow, error)
 }
 
 type EntryService interface {
-	CreateEntryLog(ctx context.Context, passID, guardID uuid.UUID, action string, comment sql.NullString) (repo.EntryLog, error)
-	ListEntryLogs(ctx context.Context, passID uuid.UUID, limit, offset int32) ([]repo.EntryLog, error)
+	ListEntryLogs(ctx context.Context, filter service.EntryLogFilter, limit, offset int32) ([]repo.ListEntryLogsRow, error)
+	AmendEntryLog(ctx context.Context, input service.EntryLogAmendment) (repo.EntryLog, error)
+	VerifyEntryLogChain(ctx context.Context) (service.ChainReport, error)
+	CheckInGuest(ctx context.Context, input service.GuestVisitInput) (repo.EntryLog, error)
+	CheckOutGuest(ctx context.Context, input service.GuestVisitInput) (repo.EntryLog, error)
+}
+
+type PresenceService interface {
+	RecordPassMovement(ctx context.Context, input service.PassMovementInput) (repo.EntryLog, error)
+	OnSite(ctx context.Context, plot string) ([]service.OnSiteEntry, error)
+}
+
+type ScheduleService interface {
+	CreatePassSchedule(ctx context.Context, input service.PassScheduleInput) (repo.PassSchedule, error)
+	ListPassSchedules(ctx context.Context, passID uuid.UUID) ([]repo.PassSchedule, error)
+	DeletePassSchedule(ctx context.Context, passID, scheduleID uuid.UUID) error
+	CheckPassSchedule(ctx context.Context, passID uuid.UUID) (service.ScheduleStatus, error)
+	CreateHoliday(ctx context.Context, input service.HolidayInput) (repo.Holiday, error)
+	ListHolidays(ctx context.Context, from, to time.Time) ([]repo.Holiday, error)
+	DeleteHoliday(ctx context.Context, day time.Time) error
+}
+
+type ImportService interface {
+	ImportUsers(ctx context.Context, rows []service.ImportRow, opts service.ImportOptions) (service.ImportReport, error)
+}
+
+type ExportService interface {
+	Location() *time.Location
+	ExportUsers(ctx context.Context, filter service.ExportFilter, fn func(repo.User) error) error
+	ExportPasses(ctx context.Context, filter service.ExportFilter, fn func(repo.ExportPassesRow) error) error
+	ExportGuestRequests(ctx context.Context, filter service.ExportFilter, fn func(repo.ExportGuestRequestsRow) error) error
+	ExportEntryLogs(ctx context.Context, filter service.EntryLogFilter, fn func(repo.ExportEntryLogsRow) error) error
+}
+
+type WatchlistService interface {
+	CreateWatchlistEntry(ctx context.Context, input service.WatchlistInput) (repo.PlateWatchlist, error)
+	GetWatchlistEntry(ctx context.Context, id uuid.UUID) (repo.PlateWatchlist, error)
+	ListWatchlist(ctx context.Context, includeExpired bool, limit, offset int32) ([]repo.PlateWatchlist, error)
+	UpdateWatchlistEntry(ctx context.Context, input service.WatchlistInput) (repo.PlateWatchlist, error)
+	DeleteWatchlistEntry(ctx context.Context, id uuid.UUID, actor uuid.UUID) error
+	ListWatchlistHits(ctx context.Context, id uuid.UUID, limit, offset int32) ([]repo.WatchlistHit, error)
+	FlagPasses(ctx context.Context, passes []repo.Pass, userID uuid.UUID) (map[uuid.UUID]repo.PlateWatchlist, error)
+	CheckGate(ctx context.Context, input service.GateCheckInput) (*service.WatchlistHit, error)
+}
+
+type GateService interface {
+	CreateGate(ctx context.Context, input service.GateInput) (repo.Gate, error)
+	GetGate(ctx context.Context, id uuid.UUID) (repo.Gate, error)
+	ListGates(ctx context.Context) ([]repo.Gate, error)
+	UpdateGate(ctx context.Context, input service.GateInput) (repo.Gate, error)
+	DeleteGate(ctx context.Context, id, actor uuid.UUID) error
+	GuardGates(ctx context.Context, userID uuid.UUID) ([]repo.Gate, error)
+	SetGuardGates(ctx context.Context, userID uuid.UUID, gateIDs []uuid.UUID) ([]repo.Gate, error)
+	PassGates(ctx context.Context, passID uuid.UUID) ([]repo.Gate, error)
+	SetPassGates(ctx context.Context, passID uuid.UUID, gateIDs []uuid.UUID) ([]repo.Gate, error)
+	GuestTypeGates(ctx context.Context) ([]repo.ListAllGuestTypeGatesRow, error)
+	SetGuestTypeGates(ctx context.Context, guestType string, gateIDs []uuid.UUID) ([]repo.Gate, error)
+	GateStats(ctx context.Context, from, to time.Time) ([]repo.GateStatsRow, error)
+}
+
+type ShiftService interface {
+	StartShift(ctx context.Context, input service.ShiftStartInput) (service.ShiftReport, error)
+	EndShift(ctx context.Context, input service.ShiftEndInput) (service.ShiftReport, error)
+	CurrentShift(ctx context.Context, guardID uuid.UUID) (service.ShiftReport, error)
+	GetShift(ctx context.Context, id uuid.UUID) (service.ShiftReport, error)
+	ListShifts(ctx context.Context, filter service.ShiftFilter, limit, offset int32) ([]repo.ListGuardShiftsRow, error)
+}
+
+type IncidentService interface {
+	CreateIncident(ctx context.Context, input service.IncidentInput) (repo.Incident, error)
+	GetIncident(ctx context.Context, id uuid.UUID) (service.IncidentDetails, error)
+	ListIncidents(ctx context.Context, filter service.IncidentFilter, limit, offset int32) ([]repo.ListIncidentsRow, error)
+	ChangeIncidentStatus(ctx context.Context, input service.IncidentStatusInput) (repo.Incident, error)
+	AddIncidentComment(ctx context.Context, input service.IncidentCommentInput) (repo.IncidentComment, error)
+	AddIncidentAttachment(ctx context.Context, input service.AttachmentInput) (repo.IncidentAttachment, error)
+	OpenIncidentAttachment(ctx context.Context, id uuid.UUID) (repo.IncidentAttachment, io.ReadCloser, error)
+	DeleteIncidentAttachment(ctx context.Context, incidentID, id uuid.UUID) error
+}
+
+// PhotoService serves the vehicle photos taken at the gate.
+type PhotoService interface {
+	EntryPhoto(ctx context.Context, entryLogID uuid.UUID) (repo.EntryPhoto, error)
+	OpenEntryPhoto(ctx context.Context, id uuid.UUID, thumbnail bool) (repo.EntryPhoto, io.ReadCloser, error)
+}
+
+// CameraService registers plate-recognition cameras and decides on the
+// plates they read.
+type CameraService interface {
+	CreateCamera(ctx context.Context, input service.CameraInput) (repo.Camera, string, error)
+	GetCamera(ctx context.Context, id uuid.UUID) (repo.Camera, error)
+	ListCameras(ctx context.Context) ([]repo.Camera, error)
+	UpdateCamera(ctx context.Context, input service.CameraInput) (repo.Camera, error)
+	RotateCameraToken(ctx context.Context, id, actor uuid.UUID) (string, error)
+	DeleteCamera(ctx context.Context, id, actor uuid.UUID) error
+	AuthenticateCamera(ctx context.Context, token string) (repo.Camera, error)
+	RecordPlateRead(ctx context.Context, camera repo.Camera, input service.PlateReadInput) (repo.PlateRead, error)
+	ListPlateReads(ctx context.Context, filter service.PlateReadFilter, limit, offset int32) ([]repo.ListPlateReadsRow, error)
+	ReviewPlateRead(ctx context.Context, input service.PlateReviewInput) (repo.PlateRead, error)
+}
+
+type BarrierService interface {
+	GetGateBarrier(ctx context.Context, gateID uuid.UUID) (repo.GateBarrier, error)
+	SetGateBarrier(ctx context.Context, input service.GateBarrierInput) (repo.GateBarrier, error)
+	DeleteGateBarrier(ctx context.Context, gateID uuid.UUID) error
+	OpenBarrier(ctx context.Context, input service.BarrierCommandInput) (repo.BarrierEvent, error)
+	BarrierStatus(ctx context.Context, gateID, actor uuid.UUID) (barrier.Status, error)
+	TriggerBarrier(ctx context.Context, entry repo.EntryLog) (*repo.BarrierEvent, error)
+	ListBarrierEvents(ctx context.Context, gateID uuid.UUID, failedOnly bool, limit, offset int32) ([]repo.BarrierEvent, error)
+}
+
+// SyncService serves guard devices that keep working while offline.
+type SyncService interface {
+	SyncEntryLogs(ctx context.Context, guardID uuid.UUID, items []service.SyncEntryInput) ([]service.SyncResult, error)
+	SyncDelta(ctx context.Context, since time.Time) (service.SyncSnapshot, error)
+}
+
+// PlotService keeps the plots of the settlement and who lives on them.
+type PlotService interface {
+	CreatePlot(ctx context.Context, input service.PlotInput) (repo.Plot, error)
+	GetPlot(ctx context.Context, id uuid.UUID) (repo.Plot, error)
+	ListPlots(ctx context.Context, filter service.PlotFilter) ([]repo.Plot, error)
+	UpdatePlot(ctx context.Context, input service.PlotInput) (repo.Plot, error)
+	ListPlotResidents(ctx context.Context, plotID uuid.UUID) ([]repo.ListPlotResidentsRow, error)
+	ListUserPlots(ctx context.Context, userID uuid.UUID) ([]repo.ListUserPlotsRow, error)
+	SetPlotResident(ctx context.Context, input service.PlotResidentInput) (repo.PlotResident, error)
+	RemovePlotResident(ctx context.Context, plotID, userID uuid.UUID) error
+	ListPlotPasses(ctx context.Context, plotID uuid.UUID, limit, offset int32) ([]repo.Pass, error)
+	ListPlotGuestRequests(ctx context.Context, plotID uuid.UUID, limit, offset int32) ([]repo.GuestRequest, error)
+}
+
+// FileService signs and checks the links files are downloaded by.
+type FileService interface {
+	SignFileLink(id uuid.UUID) service.FileLink
+	VerifyFileLink(id uuid.UUID, expires int64, signature string) error
 }
@@ -0,0 +1,257 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+// EntryLogRecordResponse is a gate journal row with the pass or guest and
+// the guard resolved to names.
+type EntryLogRecordResponse struct {
+	ID                uuid.UUID           `json:"id"`
+	PassID            *uuid.UUID          `json:"pass_id,omitempty"`
+	GuestRequestID    *uuid.UUID          `json:"guest_request_id,omitempty"`
+	Action            string              `json:"action"`
+	ActionAt          time.Time           `json:"action_at"`
+	Comment           *string             `json:"comment,omitempty"`
+	Anomaly           *string             `json:"anomaly,omitempty"`
+	OverrideReason    *string             `json:"override_reason,omitempty"`
+	Seq               int64               `json:"seq"`
+	Hash              string              `json:"hash"`
+	AmendsID          *uuid.UUID          `json:"amends_id,omitempty"`
+	CorrectedAction   *string             `json:"corrected_action,omitempty"`
+	CorrectedActionAt *time.Time          `json:"corrected_action_at,omitempty"`
+	GateID            *uuid.UUID          `json:"gate_id,omitempty"`
+	GateName          *string             `json:"gate_name,omitempty"`
+	DeviceAt          *time.Time          `json:"device_at,omitempty"`
+	PlateNumber       string              `json:"plate_number"`
+	GuestFullName     *string             `json:"guest_full_name,omitempty"`
+	OwnerUserID       uuid.UUID           `json:"owner_user_id"`
+	OwnerFullName     string              `json:"owner_full_name"`
+	OwnerPlotNumber   *string             `json:"owner_plot_number,omitempty"`
+	GuardUserID       uuid.UUID           `json:"guard_user_id"`
+	GuardFullName     string              `json:"guard_full_name"`
+	Photo             *EntryPhotoResponse `json:"photo,omitempty"`
+}
+
+// EntryLogAmendmentRequest corrects a journal record. Action is "entry",
+// "exit" or "void"; omitted fields are left as recorded.
+type EntryLogAmendmentRequest struct {
+	Reason   string     `json:"reason"`
+	Action   *string    `json:"action"`
+	ActionAt *time.Time `json:"action_at"`
+}
+
+type ChainBreakResponse struct {
+	Seq    int64     `json:"seq"`
+	ID     uuid.UUID `json:"id"`
+	Reason string    `json:"reason"`
+}
+
+type ChainReportResponse struct {
+	OK       bool                `json:"ok"`
+	Checked  int64               `json:"checked"`
+	HeadSeq  int64               `json:"head_seq"`
+	HeadHash string              `json:"head_hash"`
+	Break    *ChainBreakResponse `json:"break,omitempty"`
+}
+
+func (h *Handler) HandleListEntryLogs(w http.ResponseWriter, r *http.Request) {
+	filter, ok := h.entryLogFilter(w, r)
+	if !ok {
+		return
+	}
+	h.writeEntryLogs(w, r, filter)
+}
+
+// HandleListPassEntryLogs is the journal of a single pass; residents may
+// read it for their own passes only.
+func (h *Handler) HandleListPassEntryLogs(w http.ResponseWriter, r *http.Request) {
+	passID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	pass, err := h.Service.GetPass(r.Context(), passID)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if roleFromContext(r) == string(auth.RoleResident) && pass.OwnerUserID != actorFromContext(r) {
+		WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	filter, ok := h.entryLogFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.PassID = pass.ID
+	h.writeEntryLogs(w, r, filter)
+}
+
+func (h *Handler) entryLogFilter(w http.ResponseWriter, r *http.Request) (service.EntryLogFilter, bool) {
+	query := r.URL.Query()
+	loc := h.Service.Location()
+	filter := service.EntryLogFilter{
+		Action: query.Get("action"),
+		Plate:  query.Get("plate"),
+		Plot:   query.Get("plot"),
+	}
+	var err error
+	if filter.From, err = parseExportTime(query.Get("from"), loc, false); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid from")
+		return filter, false
+	}
+	if filter.To, err = parseExportTime(query.Get("to"), loc, true); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid to")
+		return filter, false
+	}
+	if raw := query.Get("guard_id"); raw != "" {
+		if filter.GuardID, err = uuid.Parse(raw); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid guard_id")
+			return filter, false
+		}
+	}
+	if raw := query.Get("gate_id"); raw != "" {
+		if filter.GateID, err = uuid.Parse(raw); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid gate_id")
+			return filter, false
+		}
+	}
+	return filter, true
+}
+
+func (h *Handler) writeEntryLogs(w http.ResponseWriter, r *http.Request, filter service.EntryLogFilter) {
+	limit, offset := parsePagination(r)
+	rows, err := h.Service.ListEntryLogs(r.Context(), filter, limit, offset)
+	switch {
+	case errors.Is(err, service.ErrInvalidRange), errors.Is(err, service.ErrInvalidEntryAction):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	resp := make([]EntryLogRecordResponse, 0, len(rows))
+	for _, row := range rows {
+		item := mapEntryLogRecord(row)
+		if row.PhotoID.Valid {
+			item.Photo = h.mapEntryPhoto(row.PhotoID.UUID)
+		}
+		resp = append(resp, item)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func mapEntryLogRecord(row repo.ListEntryLogsRow) EntryLogRecordResponse {
+	resp := EntryLogRecordResponse{
+		ID:            row.ID,
+		Action:        row.Action,
+		ActionAt:      row.ActionAt,
+		Seq:           row.Seq,
+		Hash:          row.Hash,
+		PlateNumber:   row.PlateNumber,
+		OwnerUserID:   row.OwnerUserID,
+		OwnerFullName: row.OwnerFullName,
+		GuardUserID:   row.GuardUserID,
+		GuardFullName: row.GuardFullName,
+	}
+	if row.PassID.Valid {
+		resp.PassID = &row.PassID.UUID
+	}
+	if row.GuestRequestID.Valid {
+		resp.GuestRequestID = &row.GuestRequestID.UUID
+	}
+	if row.Comment.Valid {
+		resp.Comment = &row.Comment.String
+	}
+	if row.Anomaly.Valid {
+		resp.Anomaly = &row.Anomaly.String
+	}
+	if row.OverrideReason.Valid {
+		resp.OverrideReason = &row.OverrideReason.String
+	}
+	if row.AmendsID.Valid {
+		resp.AmendsID = &row.AmendsID.UUID
+	}
+	if row.CorrectedAction.Valid {
+		resp.CorrectedAction = &row.CorrectedAction.String
+	}
+	if row.CorrectedActionAt.Valid {
+		resp.CorrectedActionAt = &row.CorrectedActionAt.Time
+	}
+	if row.DeviceAt.Valid {
+		resp.DeviceAt = &row.DeviceAt.Time
+	}
+	if row.GateID.Valid {
+		resp.GateID = &row.GateID.UUID
+	}
+	if row.GateName.Valid {
+		resp.GateName = &row.GateName.String
+	}
+	if row.GuestFullName.Valid {
+		resp.GuestFullName = &row.GuestFullName.String
+	}
+	if row.OwnerPlotNumber.Valid {
+		resp.OwnerPlotNumber = &row.OwnerPlotNumber.String
+	}
+	return resp
+}
+
+func (h *Handler) HandleAmendEntryLog(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req EntryLogAmendmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	input := service.EntryLogAmendment{EntryLogID: id, AuthorID: actorFromContext(r), Reason: req.Reason}
+	if req.Action != nil {
+		input.Action = *req.Action
+	}
+	if req.ActionAt != nil {
+		input.ActionAt = *req.ActionAt
+	}
+	entry, err := h.Service.AmendEntryLog(r.Context(), input)
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	case errors.Is(err, service.ErrAmendmentReason), errors.Is(err, service.ErrInvalidEntryAction), errors.Is(err, service.ErrInvalidRange):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, service.ErrAmendmentTarget):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "amend error")
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapEntryLog(entry))
+}
+
+func (h *Handler) HandleVerifyEntryLogChain(w http.ResponseWriter, r *http.Request) {
+	report, err := h.Service.VerifyEntryLogChain(r.Context())
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "verify error")
+		return
+	}
+	resp := ChainReportResponse{OK: report.OK(), Checked: report.Checked, HeadSeq: report.HeadSeq, HeadHash: report.HeadHash}
+	if report.Break != nil {
+		resp.Break = &ChainBreakResponse{Seq: report.Break.Seq, ID: report.Break.ID, Reason: report.Break.Reason}
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,324 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+	"pipo-edu-project/internal/tabular"
+)
+
+const (
+	exportTimeout    = 10 * time.Minute
+	exportTimeLayout = "2006-01-02 15:04:05"
+)
+
+type exportColumn struct {
+	ru string
+	en string
+}
+
+var (
+	userExportColumns = []exportColumn{
+		{"ID", "ID"},
+		{"E-mail", "Email"},
+		{"ФИО", "Full name"},
+		{"Роль", "Role"},
+		{"Участок", "Plot"},
+		{"Заблокирован", "Blocked at"},
+		{"Создан", "Created at"},
+		{"Удалён", "Deleted at"},
+	}
+	passExportColumns = []exportColumn{
+		{"ID", "ID"},
+		{"Госномер", "Plate number"},
+		{"Марка", "Vehicle brand"},
+		{"Цвет", "Vehicle color"},
+		{"Статус", "Status"},
+		{"Владелец", "Owner"},
+		{"E-mail владельца", "Owner email"},
+		{"Участок", "Plot"},
+		{"Создан", "Created at"},
+		{"Удалён", "Deleted at"},
+	}
+	guestExportColumns = []exportColumn{
+		{"ID", "ID"},
+		{"Гость", "Guest"},
+		{"Госномер", "Plate number"},
+		{"Действует с", "Valid from"},
+		{"Действует до", "Valid to"},
+		{"Статус", "Status"},
+		{"Житель", "Resident"},
+		{"Участок", "Plot"},
+		{"Создана", "Created at"},
+		{"Удалена", "Deleted at"},
+	}
+	entryLogExportColumns = []exportColumn{
+		{"ID", "ID"},
+		{"Время", "Time"},
+		{"Действие", "Action"},
+		{"Госномер", "Plate number"},
+		{"Гость", "Guest"},
+		{"Владелец", "Owner"},
+		{"Участок", "Plot"},
+		{"Охранник", "Guard"},
+		{"Комментарий", "Comment"},
+	}
+)
+
+// exportStream defers the response headers until the first row, so a
+// failure before any data is produced still yields a JSON error.
+type exportStream struct {
+	w        http.ResponseWriter
+	format   string
+	name     string
+	header   []string
+	loc      *time.Location
+	writer   tabular.RowWriter
+	streamed bool
+}
+
+func (h *Handler) newExportStream(w http.ResponseWriter, r *http.Request, name string, columns []exportColumn) (*exportStream, bool) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = tabular.FormatCSV
+	}
+	if format != tabular.FormatCSV && format != tabular.FormatXLSX {
+		WriteError(w, http.StatusBadRequest, "invalid format")
+		return nil, false
+	}
+	english := exportInEnglish(r)
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if english {
+			header = append(header, column.en)
+		} else {
+			header = append(header, column.ru)
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Now().Add(exportTimeout))
+	return &exportStream{w: w, format: format, name: name, header: header, loc: h.Service.Location()}, true
+}
+
+func exportInEnglish(r *http.Request) bool {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return strings.HasPrefix(strings.ToLower(lang), "en")
+	}
+	return strings.HasPrefix(strings.ToLower(r.Header.Get("Accept-Language")), "en")
+}
+
+func (s *exportStream) start() error {
+	if s.streamed {
+		return nil
+	}
+	s.streamed = true
+	filename := s.name + "-" + time.Now().In(s.loc).Format("20060102-150405") + "." + s.format
+	s.w.Header().Set("Content-Type", tabular.ContentType(s.format))
+	s.w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	s.w.WriteHeader(http.StatusOK)
+	writer, err := tabular.NewWriter(s.w, s.format)
+	if err != nil {
+		return err
+	}
+	s.writer = writer
+	return s.writer.WriteRow(s.header)
+}
+
+func (s *exportStream) row(cells ...string) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	return s.writer.WriteRow(cells)
+}
+
+// finish closes the file or reports err. Once rows have been sent the status
+// line is gone, so the connection is aborted to make the truncation visible.
+func (s *exportStream) finish(err error) {
+	if err == nil {
+		err = s.start()
+	}
+	if err == nil {
+		err = s.writer.Close()
+	}
+	if err == nil {
+		return
+	}
+	if !s.streamed {
+		if errors.Is(err, service.ErrInvalidRange) || errors.Is(err, service.ErrInvalidEntryAction) {
+			WriteError(s.w, http.StatusBadRequest, err.Error())
+			return
+		}
+		WriteError(s.w, http.StatusInternalServerError, "export error")
+		return
+	}
+	log.Error().Err(err).Str("export", s.name).Msg("export aborted")
+	panic(http.ErrAbortHandler)
+}
+
+func (s *exportStream) time(value time.Time) string {
+	return value.In(s.loc).Format(exportTimeLayout)
+}
+
+func (s *exportStream) nullTime(value sql.NullTime) string {
+	if !value.Valid {
+		return ""
+	}
+	return s.time(value.Time)
+}
+
+func (h *Handler) HandleExportUsers(w http.ResponseWriter, r *http.Request) {
+	stream, ok := h.newExportStream(w, r, "users", userExportColumns)
+	if !ok {
+		return
+	}
+	filter := service.ExportFilter{IncludeDeleted: r.URL.Query().Get("includeDeleted") == "true"}
+	err := h.Service.ExportUsers(r.Context(), filter, func(user repo.User) error {
+		return stream.row(
+			user.ID.String(),
+			user.Email,
+			user.FullName,
+			user.Role,
+			user.PlotNumber.String,
+			stream.nullTime(user.BlockedAt),
+			stream.time(user.CreatedAt),
+			stream.nullTime(user.DeletedAt),
+		)
+	})
+	stream.finish(err)
+}
+
+func (h *Handler) HandleExportPasses(w http.ResponseWriter, r *http.Request) {
+	filter, ok := exportOwnerFilter(w, r)
+	if !ok {
+		return
+	}
+	stream, ok := h.newExportStream(w, r, "passes", passExportColumns)
+	if !ok {
+		return
+	}
+	err := h.Service.ExportPasses(r.Context(), filter, func(pass repo.ExportPassesRow) error {
+		return stream.row(
+			pass.ID.String(),
+			pass.PlateNumber,
+			pass.VehicleBrand.String,
+			pass.VehicleColor.String,
+			pass.Status,
+			pass.OwnerFullName,
+			pass.OwnerEmail,
+			pass.OwnerPlotNumber.String,
+			stream.time(pass.CreatedAt),
+			stream.nullTime(pass.DeletedAt),
+		)
+	})
+	stream.finish(err)
+}
+
+func (h *Handler) HandleExportGuests(w http.ResponseWriter, r *http.Request) {
+	filter, ok := exportOwnerFilter(w, r)
+	if !ok {
+		return
+	}
+	stream, ok := h.newExportStream(w, r, "guest-requests", guestExportColumns)
+	if !ok {
+		return
+	}
+	err := h.Service.ExportGuestRequests(r.Context(), filter, func(guest repo.ExportGuestRequestsRow) error {
+		return stream.row(
+			guest.ID.String(),
+			guest.GuestFullName,
+			guest.PlateNumber,
+			stream.time(guest.ValidFrom),
+			stream.time(guest.ValidTo),
+			guest.Status,
+			guest.ResidentFullName,
+			guest.ResidentPlotNumber.String,
+			stream.time(guest.CreatedAt),
+			stream.nullTime(guest.DeletedAt),
+		)
+	})
+	stream.finish(err)
+}
+
+func (h *Handler) HandleExportEntryLogs(w http.ResponseWriter, r *http.Request) {
+	var owner uuid.UUID
+	switch roleFromContext(r) {
+	case string(auth.RoleAdmin), string(auth.RoleGuard):
+	case string(auth.RoleResident):
+		owner = actorFromContext(r)
+		if owner == uuid.Nil {
+			WriteError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+	default:
+		WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	// The file holds the rows GET /entry-logs shows for the same query.
+	filter, ok := h.entryLogFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.OwnerID = owner
+	stream, ok := h.newExportStream(w, r, "entry-logs", entryLogExportColumns)
+	if !ok {
+		return
+	}
+	err := h.Service.ExportEntryLogs(r.Context(), filter, func(entry repo.ExportEntryLogsRow) error {
+		return stream.row(
+			entry.ID.String(),
+			stream.time(entry.ActionAt),
+			entry.Action,
+			entry.PlateNumber,
+			entry.GuestFullName.String,
+			entry.OwnerFullName,
+			entry.OwnerPlotNumber.String,
+			entry.GuardFullName,
+			entry.Comment.String,
+		)
+	})
+	stream.finish(err)
+}
+
+// exportOwnerFilter applies the same visibility as the list endpoints:
+// admins see everything, residents only their own records.
+func exportOwnerFilter(w http.ResponseWriter, r *http.Request) (service.ExportFilter, bool) {
+	switch roleFromContext(r) {
+	case string(auth.RoleAdmin):
+		return service.ExportFilter{IncludeDeleted: r.URL.Query().Get("includeDeleted") == "true"}, true
+	case string(auth.RoleResident):
+		if owner := actorFromContext(r); owner != uuid.Nil {
+			return service.ExportFilter{OwnerID: owner}, true
+		}
+	}
+	WriteError(w, http.StatusForbidden, "forbidden")
+	return service.ExportFilter{}, false
+}
+
+// parseExportTime accepts RFC3339 or a YYYY-MM-DD day in the site time zone.
+// A day used as the upper bound covers the whole day.
+func parseExportTime(value string, loc *time.Location, upper bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upper {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
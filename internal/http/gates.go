@@ -0,0 +1,316 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+type GateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// GateUpdateRequest is a partial update of a gate.
+type GateUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// GateListRequest replaces the gates of a guard, a pass or a guest type.
+type GateListRequest struct {
+	GateIDs []uuid.UUID `json:"gate_ids"`
+}
+
+type GateResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type GuestTypeGatesResponse struct {
+	GuestType string         `json:"guest_type"`
+	Gates     []GateResponse `json:"gates"`
+}
+
+type GateStatsResponse struct {
+	GateID       uuid.UUID  `json:"gate_id"`
+	GateName     string     `json:"gate_name"`
+	Entries      int64      `json:"entries"`
+	Exits        int64      `json:"exits"`
+	GuestEntries int64      `json:"guest_entries"`
+	Anomalies    int64      `json:"anomalies"`
+	LastActionAt *time.Time `json:"last_action_at,omitempty"`
+}
+
+func (h *Handler) HandleListGates(w http.ResponseWriter, r *http.Request) {
+	gates, err := h.Service.ListGates(r.Context())
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGates(gates))
+}
+
+func (h *Handler) HandleCreateGate(w http.ResponseWriter, r *http.Request) {
+	var req GateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	gate, err := h.Service.CreateGate(r.Context(), service.GateInput{
+		Name:        req.Name,
+		Description: req.Description,
+		ActorID:     actorFromContext(r),
+	})
+	if err != nil {
+		writeGateAdminError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapGate(gate))
+}
+
+func (h *Handler) HandleGetGate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	gate, err := h.Service.GetGate(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGate(gate))
+}
+
+func (h *Handler) HandleUpdateGate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req GateUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	gate, err := h.Service.GetGate(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	input := service.GateInput{ID: id, Name: gate.Name, Description: gate.Description.String, ActorID: actorFromContext(r)}
+	if req.Name != nil {
+		input.Name = *req.Name
+	}
+	if req.Description != nil {
+		input.Description = *req.Description
+	}
+	updated, err := h.Service.UpdateGate(r.Context(), input)
+	if err != nil {
+		writeGateAdminError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGate(updated))
+}
+
+func (h *Handler) HandleDeleteGate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.Service.DeleteGate(r.Context(), id, actorFromContext(r)); err != nil {
+		writeGateAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleGateStats(w http.ResponseWriter, r *http.Request) {
+	loc := h.Service.Location()
+	from, err := parseExportTime(r.URL.Query().Get("from"), loc, false)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+	to, err := parseExportTime(r.URL.Query().Get("to"), loc, true)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid to")
+		return
+	}
+	rows, err := h.Service.GateStats(r.Context(), from, to)
+	switch {
+	case errors.Is(err, service.ErrInvalidRange):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "stats error")
+		return
+	}
+	resp := make([]GateStatsResponse, 0, len(rows))
+	for _, row := range rows {
+		item := GateStatsResponse{
+			GateID:       row.ID,
+			GateName:     row.Name,
+			Entries:      row.Entries,
+			Exits:        row.Exits,
+			GuestEntries: row.GuestEntries,
+			Anomalies:    row.Anomalies,
+		}
+		if row.LastActionAt.Valid {
+			item.LastActionAt = &row.LastActionAt.Time
+		}
+		resp = append(resp, item)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleGetGuardGates(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	gates, err := h.Service.GuardGates(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGates(gates))
+}
+
+func (h *Handler) HandleSetGuardGates(w http.ResponseWriter, r *http.Request) {
+	h.setGates(w, r, h.Service.SetGuardGates)
+}
+
+func (h *Handler) HandleGetPassGates(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	gates, err := h.Service.PassGates(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGates(gates))
+}
+
+func (h *Handler) HandleSetPassGates(w http.ResponseWriter, r *http.Request) {
+	h.setGates(w, r, h.Service.SetPassGates)
+}
+
+func (h *Handler) HandleListGuestTypeGates(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.Service.GuestTypeGates(r.Context())
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	resp := make([]GuestTypeGatesResponse, 0)
+	for _, row := range rows {
+		if len(resp) == 0 || resp[len(resp)-1].GuestType != row.GuestType {
+			resp = append(resp, GuestTypeGatesResponse{GuestType: row.GuestType})
+		}
+		last := &resp[len(resp)-1]
+		last.Gates = append(last.Gates, GateResponse{ID: row.GateID, Name: row.GateName})
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleSetGuestTypeGates(w http.ResponseWriter, r *http.Request) {
+	var req GateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	guestType := chi.URLParam(r, "type")
+	gates, err := h.Service.SetGuestTypeGates(r.Context(), guestType, req.GateIDs)
+	if err != nil {
+		writeGateAdminError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, GuestTypeGatesResponse{GuestType: guestType, Gates: mapGates(gates)})
+}
+
+func (h *Handler) setGates(w http.ResponseWriter, r *http.Request, set func(ctx context.Context, id uuid.UUID, gateIDs []uuid.UUID) ([]repo.Gate, error)) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req GateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	gates, err := set(r.Context(), id, req.GateIDs)
+	if err != nil {
+		writeGateAdminError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGates(gates))
+}
+
+func writeGateAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrGateExists), errors.Is(err, service.ErrGateInUse):
+		WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidInput), errors.Is(err, service.ErrUnknownGate),
+		errors.Is(err, service.ErrNotGuard), errors.Is(err, service.ErrInvalidGuestType):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		WriteError(w, http.StatusInternalServerError, "gate error")
+	}
+}
+
+// writeMovementError answers a movement refused because of the gate it
+// was recorded at or because the guard is not on a shift; it reports false
+// for any other error.
+func writeMovementError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrUnknownGate), errors.Is(err, service.ErrGateRequired):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrGateNotAllowed), errors.Is(err, service.ErrGateNotAssigned),
+		errors.Is(err, service.ErrNoOpenShift):
+		WriteError(w, http.StatusForbidden, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+func mapGate(gate repo.Gate) GateResponse {
+	resp := GateResponse{
+		ID:        gate.ID,
+		Name:      gate.Name,
+		CreatedAt: gate.CreatedAt,
+		UpdatedAt: gate.UpdatedAt,
+	}
+	if gate.Description.Valid {
+		resp.Description = &gate.Description.String
+	}
+	return resp
+}
+
+func mapGates(gates []repo.Gate) []GateResponse {
+	resp := make([]GateResponse, 0, len(gates))
+	for _, gate := range gates {
+		resp = append(resp, mapGate(gate))
+	}
+	return resp
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+// GateGuestResponse is the guard's view of a guest request: enough to let the
+// car in and call the resident, nothing else.
+type GateGuestResponse struct {
+	ID                 uuid.UUID `json:"id"`
+	GuestFullName      string    `json:"guest_full_name"`
+	PlateNumber        string    `json:"plate_number"`
+	ValidFrom          time.Time `json:"valid_from"`
+	ValidTo            time.Time `json:"valid_to"`
+	Status             string    `json:"status"`
+	GuestType          string    `json:"guest_type"`
+	CompanyName        *string   `json:"company_name,omitempty"`
+	ResidentFullName   string    `json:"resident_full_name"`
+	ResidentPlotNumber *string   `json:"resident_plot_number,omitempty"`
+}
+
+func (h *Handler) HandleExpectedGuests(w http.ResponseWriter, r *http.Request) {
+	ahead := service.DefaultExpectedWindow
+	if value := r.URL.Query().Get("hours"); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid hours")
+			return
+		}
+		ahead = time.Duration(hours) * time.Hour
+	}
+	limit, offset := parsePagination(r)
+	guests, err := h.Service.ExpectedGuests(r.Context(), ahead, r.URL.Query().Get("type"), limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGateGuests(guests))
+}
+
+func (h *Handler) HandleSearchGuests(w http.ResponseWriter, r *http.Request) {
+	plate := r.URL.Query().Get("plate")
+	if plate == "" {
+		WriteError(w, http.StatusBadRequest, "missing plate")
+		return
+	}
+	limit, offset := parsePagination(r)
+	guests, err := h.Service.SearchGuestsByPlate(r.Context(), plate, r.URL.Query().Get("type"), limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGateGuests(guests))
+}
+
+func mapGateGuests(guests []repo.ListGateGuestsRow) []GateGuestResponse {
+	resp := make([]GateGuestResponse, 0, len(guests))
+	for _, guest := range guests {
+		resp = append(resp, mapGateGuest(guest))
+	}
+	return resp
+}
+
+func mapGateGuest(guest repo.ListGateGuestsRow) GateGuestResponse {
+	resp := GateGuestResponse{
+		ID:               guest.ID,
+		GuestFullName:    guest.GuestFullName,
+		PlateNumber:      guest.PlateNumber,
+		ValidFrom:        guest.ValidFrom,
+		ValidTo:          guest.ValidTo,
+		Status:           guest.Status,
+		GuestType:        guest.GuestType,
+		ResidentFullName: guest.ResidentFullName,
+	}
+	if guest.CompanyName.Valid {
+		resp.CompanyName = &guest.CompanyName.String
+	}
+	if guest.ResidentPlotNumber.Valid {
+		resp.ResidentPlotNumber = &guest.ResidentPlotNumber.String
+	}
+	return resp
+}
+
+func (h *Handler) HandleGuestCheckIn(w http.ResponseWriter, r *http.Request) {
+	h.guestVisit(w, r, "checked_in", service.WatchlistSourceEntry, h.Service.CheckInGuest)
+}
+
+func (h *Handler) HandleGuestCheckOut(w http.ResponseWriter, r *http.Request) {
+	h.guestVisit(w, r, "checked_out", service.WatchlistSourceExit, h.Service.CheckOutGuest)
+}
+
+func (h *Handler) guestVisit(w http.ResponseWriter, r *http.Request, label, source string, visit func(context.Context, service.GuestVisitInput) (repo.EntryLog, error)) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req EntryRequest
+	photo, ok := decodeGateRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	guest, err := h.Service.GetGuestRequest(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "guest request not found")
+		return
+	}
+	hit, ok := h.checkGate(w, r, req, service.GateCheckInput{
+		GuestID:     guest.ID,
+		PlateNumber: guest.PlateNumber,
+		Source:      source,
+	})
+	if !ok {
+		return
+	}
+	entry, err := visit(r.Context(), service.GuestVisitInput{
+		GuestID:   id,
+		GuardID:   actorFromContext(r),
+		GateID:    derefUUID(req.GateID),
+		Comment:   toNullString(req.Comment),
+		Photo:     photo,
+		Watchlist: hit,
+	})
+	switch {
+	case errors.Is(err, service.ErrOutsideGuestWindow):
+		WriteError(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, service.ErrResidentInactive):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case writeMovementError(w, err), writeUploadError(w, err):
+		return
+	case err != nil:
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues(label).Inc()
+	}
+	resp := mapEntryLog(entry)
+	resp.Watchlist = mapGateWatchlist(hit)
+	if photo != nil {
+		resp.Photo = h.entryLogPhoto(r, entry.ID)
+	}
+	resp.Barrier = h.triggerBarrier(r, entry)
+	WriteJSON(w, http.StatusCreated, resp)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+type GuestPinResponse struct {
+	GuestRequestID uuid.UUID `json:"guest_request_id"`
+	Pin            string    `json:"pin"`
+	ValidTo        time.Time `json:"valid_to"`
+}
+
+type GuestPinCheckInRequest struct {
+	Pin string `json:"pin"`
+	// PlateNumber is the car the guest actually came in, when it differs
+	// from the announced one; the watchlist is checked against it.
+	PlateNumber *string    `json:"plate_number"`
+	Comment     *string    `json:"comment"`
+	Override    bool       `json:"override"`
+	GateID      *uuid.UUID `json:"gate_id"`
+}
+
+type GuestPinCheckInResponse struct {
+	Guest GateGuestResponse `json:"guest"`
+	Entry EntryLogResponse  `json:"entry"`
+}
+
+func (h *Handler) HandleGetGuestPin(w http.ResponseWriter, r *http.Request) {
+	h.guestPin(w, r, h.Service.GuestPin)
+}
+
+func (h *Handler) HandleRegenerateGuestPin(w http.ResponseWriter, r *http.Request) {
+	h.guestPin(w, r, h.Service.RegenerateGuestPin)
+}
+
+func (h *Handler) guestPin(w http.ResponseWriter, r *http.Request, get func(context.Context, repo.GuestRequest, uuid.UUID) (repo.GuestPin, error)) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	actorID := actorFromContext(r)
+	guest, err := h.Service.GetGuestRequest(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if roleFromContext(r) != string(auth.RoleAdmin) && guest.ResidentUserID != actorID {
+		WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	pin, err := get(r.Context(), guest, actorID)
+	switch {
+	case errors.Is(err, service.ErrGuestPinUnavailable):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "guest PIN error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, GuestPinResponse{GuestRequestID: guest.ID, Pin: pin.Code, ValidTo: guest.ValidTo})
+}
+
+func (h *Handler) HandleGuestPinCheckIn(w http.ResponseWriter, r *http.Request) {
+	var req GuestPinCheckInRequest
+	photo, ok := decodeGateRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	guest, err := h.Service.GuestByPin(r.Context(), req.Pin, actorFromContext(r))
+	switch {
+	case errors.Is(err, service.ErrGuestPinAttempts):
+		WriteError(w, http.StatusTooManyRequests, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidGuestPin):
+		if h.Metrics != nil {
+			h.Metrics.Guest.WithLabelValues("pin_rejected").Inc()
+		}
+		WriteError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "guest PIN error")
+		return
+	}
+	plate := guest.PlateNumber
+	if req.PlateNumber != nil && *req.PlateNumber != "" {
+		if err := service.ValidatePlate(*req.PlateNumber); err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		plate = *req.PlateNumber
+	}
+	entryReq := EntryRequest{Comment: req.Comment, Override: req.Override}
+	hit, ok := h.checkGate(w, r, entryReq, service.GateCheckInput{
+		GuestID:     guest.ID,
+		PlateNumber: plate,
+		Source:      service.WatchlistSourceEntry,
+	})
+	if !ok {
+		return
+	}
+	entry, err := h.Service.CheckInGuest(r.Context(), service.GuestVisitInput{
+		GuestID:   guest.ID,
+		GuardID:   actorFromContext(r),
+		GateID:    derefUUID(req.GateID),
+		Comment:   toNullString(req.Comment),
+		Photo:     photo,
+		Watchlist: hit,
+	})
+	switch {
+	case errors.Is(err, service.ErrOutsideGuestWindow):
+		WriteError(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, service.ErrResidentInactive):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case writeMovementError(w, err), writeUploadError(w, err):
+		return
+	case err != nil:
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues("pin_checked_in").Inc()
+	}
+	resp := GuestPinCheckInResponse{Guest: mapGateGuest(repo.ListGateGuestsRow(guest)), Entry: mapEntryLog(entry)}
+	resp.Guest.Status = service.GuestStatusArrived
+	resp.Entry.Watchlist = mapGateWatchlist(hit)
+	if photo != nil {
+		resp.Entry.Photo = h.entryLogPhoto(r, entry.ID)
+	}
+	resp.Entry.Barrier = h.triggerBarrier(r, entry)
+	WriteJSON(w, http.StatusCreated, resp)
+}
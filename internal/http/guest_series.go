@@ -0,0 +1,389 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+// GuestSeriesRequest describes a recurring visit: either rrule or weekdays
+// with until.
+type GuestSeriesRequest struct {
+	ResidentUserID *uuid.UUID `json:"resident_user_id,omitempty"`
+	GuestFullName  string     `json:"guest_full_name"`
+	PlateNumber    string     `json:"plate_number"`
+	RRule          string     `json:"rrule,omitempty"`
+	Weekdays       []int      `json:"weekdays,omitempty"`
+	Until          string     `json:"until,omitempty"`
+	StartsOn       string     `json:"starts_on"`
+	Start          string     `json:"start"`
+	End            string     `json:"end"`
+	Status         *string    `json:"status,omitempty"`
+}
+
+type GuestSeriesResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	ResidentUserID   uuid.UUID  `json:"resident_user_id"`
+	GuestFullName    string     `json:"guest_full_name"`
+	PlateNumber      string     `json:"plate_number"`
+	RRule            string     `json:"rrule"`
+	StartsOn         string     `json:"starts_on"`
+	EndsOn           *string    `json:"ends_on,omitempty"`
+	Start            string     `json:"start"`
+	End              string     `json:"end"`
+	Status           string     `json:"status"`
+	PreviousSeriesID *uuid.UUID `json:"previous_series_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	CreatedBy        *uuid.UUID `json:"created_by,omitempty"`
+	UpdatedBy        *uuid.UUID `json:"updated_by,omitempty"`
+	ReviewedBy       *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	ReviewReason     *string    `json:"review_reason,omitempty"`
+}
+
+type GuestOccurrenceResponse struct {
+	Day            string     `json:"day"`
+	ValidFrom      time.Time  `json:"valid_from"`
+	ValidTo        time.Time  `json:"valid_to"`
+	GuestFullName  string     `json:"guest_full_name"`
+	PlateNumber    string     `json:"plate_number"`
+	Status         string     `json:"status"`
+	GuestRequestID *uuid.UUID `json:"guest_request_id,omitempty"`
+}
+
+func (h *Handler) HandleCreateGuestSeries(w http.ResponseWriter, r *http.Request) {
+	var req GuestSeriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	actorID := actorFromContext(r)
+	residentID := actorID
+	status := ""
+	if roleFromContext(r) == string(auth.RoleAdmin) {
+		if req.ResidentUserID != nil {
+			residentID = *req.ResidentUserID
+		}
+		if req.Status != nil {
+			status = *req.Status
+		}
+	}
+	series, err := h.Service.CreateGuestSeries(r.Context(), service.GuestSeriesInput{
+		ResidentID:  residentID,
+		GuestName:   req.GuestFullName,
+		PlateNumber: req.PlateNumber,
+		RRule:       req.RRule,
+		Weekdays:    req.Weekdays,
+		Until:       req.Until,
+		StartsOn:    req.StartsOn,
+		Start:       req.Start,
+		End:         req.End,
+		Status:      status,
+		ActorID:     actorID,
+	})
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues("series_created").Inc()
+	}
+	WriteJSON(w, http.StatusCreated, mapGuestSeries(series))
+}
+
+func (h *Handler) HandleListGuestSeries(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+	var (
+		series []repo.GuestSeries
+		err    error
+	)
+	if roleFromContext(r) == string(auth.RoleAdmin) {
+		series, err = h.Service.ListGuestSeries(r.Context(), limit, offset)
+	} else {
+		series, err = h.Service.ListGuestSeriesByResident(r.Context(), actorFromContext(r), limit, offset)
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	resp := make([]GuestSeriesResponse, 0, len(series))
+	for _, item := range series {
+		resp = append(resp, mapGuestSeries(item))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleGetGuestSeries(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.ownGuestSeries(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGuestSeries(series))
+}
+
+// HandleUpdateGuestSeries merges the payload into the current series. Edits
+// by residents go through review again; see service.UpdateGuestSeries for
+// how history is kept.
+func (h *Handler) HandleUpdateGuestSeries(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.ownGuestSeries(w, r)
+	if !ok {
+		return
+	}
+	var req GuestSeriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if req.Status != nil {
+		WriteError(w, http.StatusBadRequest, "status is changed via approve, reject or cancel")
+		return
+	}
+	input := service.GuestSeriesInput{
+		ID:          series.ID,
+		GuestName:   series.GuestFullName,
+		PlateNumber: series.PlateNumber,
+		RRule:       series.Rrule,
+		StartsOn:    series.StartsOn.Format(time.DateOnly),
+		Start:       service.FormatClock(series.StartMinute),
+		End:         service.FormatClock(series.EndMinute),
+		ActorID:     actorFromContext(r),
+	}
+	if roleFromContext(r) == string(auth.RoleAdmin) {
+		input.Status = series.Status
+	}
+	if req.GuestFullName != "" {
+		input.GuestName = req.GuestFullName
+	}
+	if req.PlateNumber != "" {
+		input.PlateNumber = req.PlateNumber
+	}
+	if req.RRule != "" || len(req.Weekdays) > 0 || req.Until != "" {
+		input.RRule, input.Weekdays, input.Until = req.RRule, req.Weekdays, req.Until
+	}
+	if req.StartsOn != "" {
+		input.StartsOn = req.StartsOn
+	}
+	if req.Start != "" {
+		input.Start = req.Start
+	}
+	if req.End != "" {
+		input.End = req.End
+	}
+	updated, err := h.Service.UpdateGuestSeries(r.Context(), input)
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapGuestSeries(updated))
+}
+
+func (h *Handler) HandleApproveGuestSeries(w http.ResponseWriter, r *http.Request) {
+	h.reviewGuestSeries(w, r, "series_approved", h.Service.ApproveGuestSeries)
+}
+
+func (h *Handler) HandleRejectGuestSeries(w http.ResponseWriter, r *http.Request) {
+	h.reviewGuestSeries(w, r, "series_rejected", h.Service.RejectGuestSeries)
+}
+
+func (h *Handler) reviewGuestSeries(w http.ResponseWriter, r *http.Request, label string, review func(context.Context, uuid.UUID, uuid.UUID, string) (repo.GuestSeries, error)) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req GuestReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+	series, err := review(r.Context(), id, actorFromContext(r), req.Reason)
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues(label).Inc()
+	}
+	WriteJSON(w, http.StatusOK, mapGuestSeries(series))
+}
+
+func (h *Handler) HandleCancelGuestSeries(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.ownGuestSeries(w, r)
+	if !ok {
+		return
+	}
+	cancelled, err := h.Service.CancelGuestSeries(r.Context(), series.ID, actorFromContext(r))
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues("series_cancelled").Inc()
+	}
+	WriteJSON(w, http.StatusOK, mapGuestSeries(cancelled))
+}
+
+func (h *Handler) HandleListGuestOccurrences(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.ownGuestSeries(w, r)
+	if !ok {
+		return
+	}
+	occurrences, err := h.Service.ListGuestOccurrences(r.Context(), series.ID, r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	resp := make([]GuestOccurrenceResponse, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		item := GuestOccurrenceResponse{
+			Day:           occurrence.Day.Format(time.DateOnly),
+			ValidFrom:     occurrence.ValidFrom,
+			ValidTo:       occurrence.ValidTo,
+			GuestFullName: occurrence.GuestName,
+			PlateNumber:   occurrence.PlateNumber,
+			Status:        occurrence.Status,
+		}
+		if occurrence.Request != nil {
+			item.GuestRequestID = &occurrence.Request.ID
+		}
+		resp = append(resp, item)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleUpdateGuestOccurrence edits one visit of a series, which turns it
+// into its own guest request.
+func (h *Handler) HandleUpdateGuestOccurrence(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.ownGuestSeries(w, r)
+	if !ok {
+		return
+	}
+	var req GuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if req.Status != nil {
+		WriteError(w, http.StatusBadRequest, "status is changed via skip")
+		return
+	}
+	guest, err := h.Service.GetGuestOccurrence(r.Context(), series.ID, chi.URLParam(r, "day"))
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	input := service.GuestOccurrenceInput{
+		ID:          guest.ID,
+		GuestName:   guest.GuestFullName,
+		PlateNumber: guest.PlateNumber,
+		ValidFrom:   guest.ValidFrom,
+		ValidTo:     guest.ValidTo,
+		ActorID:     actorFromContext(r),
+	}
+	if roleFromContext(r) == string(auth.RoleAdmin) {
+		input.Status = guest.Status
+	}
+	if req.GuestFullName != "" {
+		input.GuestName = req.GuestFullName
+	}
+	if req.PlateNumber != nil && *req.PlateNumber != "" {
+		input.PlateNumber = *req.PlateNumber
+	}
+	if !req.ValidFrom.IsZero() {
+		input.ValidFrom = req.ValidFrom
+	}
+	if !req.ValidTo.IsZero() {
+		input.ValidTo = req.ValidTo
+	}
+	updated, err := h.Service.UpdateGuestOccurrence(r.Context(), input)
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, h.mapGuestWithConflicts(r, updated))
+}
+
+func (h *Handler) HandleSkipGuestOccurrence(w http.ResponseWriter, r *http.Request) {
+	series, ok := h.ownGuestSeries(w, r)
+	if !ok {
+		return
+	}
+	guest, err := h.Service.SkipGuestOccurrence(r.Context(), series.ID, chi.URLParam(r, "day"), actorFromContext(r))
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues("cancelled").Inc()
+	}
+	WriteJSON(w, http.StatusOK, mapGuest(guest))
+}
+
+// ownGuestSeries loads the series from the URL; residents only see their own.
+func (h *Handler) ownGuestSeries(w http.ResponseWriter, r *http.Request) (repo.GuestSeries, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return repo.GuestSeries{}, false
+	}
+	series, err := h.Service.GetGuestSeries(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return repo.GuestSeries{}, false
+	}
+	if roleFromContext(r) != string(auth.RoleAdmin) && series.ResidentUserID != actorFromContext(r) {
+		WriteError(w, http.StatusForbidden, "forbidden")
+		return repo.GuestSeries{}, false
+	}
+	return series, true
+}
+
+func mapGuestSeries(series repo.GuestSeries) GuestSeriesResponse {
+	resp := GuestSeriesResponse{
+		ID:             series.ID,
+		ResidentUserID: series.ResidentUserID,
+		GuestFullName:  series.GuestFullName,
+		PlateNumber:    series.PlateNumber,
+		RRule:          series.Rrule,
+		StartsOn:       series.StartsOn.Format(time.DateOnly),
+		Start:          service.FormatClock(series.StartMinute),
+		End:            service.FormatClock(series.EndMinute),
+		Status:         series.Status,
+		CreatedAt:      series.CreatedAt,
+		UpdatedAt:      series.UpdatedAt,
+	}
+	if series.EndsOn.Valid {
+		endsOn := series.EndsOn.Time.Format(time.DateOnly)
+		resp.EndsOn = &endsOn
+	}
+	if series.PreviousSeriesID.Valid {
+		resp.PreviousSeriesID = &series.PreviousSeriesID.UUID
+	}
+	if series.CreatedBy.Valid {
+		resp.CreatedBy = &series.CreatedBy.UUID
+	}
+	if series.UpdatedBy.Valid {
+		resp.UpdatedBy = &series.UpdatedBy.UUID
+	}
+	if series.ReviewedBy.Valid {
+		resp.ReviewedBy = &series.ReviewedBy.UUID
+	}
+	if series.ReviewedAt.Valid {
+		resp.ReviewedAt = &series.ReviewedAt.Time
+	}
+	if series.ReviewReason.Valid {
+		resp.ReviewReason = &series.ReviewReason.String
+	}
+	return resp
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+type GuestReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *Handler) HandleApproveGuest(w http.ResponseWriter, r *http.Request) {
+	h.reviewGuest(w, r, "approved", h.Service.ApproveGuestRequest)
+}
+
+func (h *Handler) HandleRejectGuest(w http.ResponseWriter, r *http.Request) {
+	h.reviewGuest(w, r, "rejected", h.Service.RejectGuestRequest)
+}
+
+func (h *Handler) reviewGuest(w http.ResponseWriter, r *http.Request, label string, review func(context.Context, uuid.UUID, uuid.UUID, string) (repo.GuestRequest, error)) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req GuestReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+	guest, err := review(r.Context(), id, actorFromContext(r), req.Reason)
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues(label).Inc()
+	}
+	WriteJSON(w, http.StatusOK, mapGuest(guest))
+}
+
+func (h *Handler) HandleCancelGuest(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	actorID := actorFromContext(r)
+	guest, err := h.Service.GetGuestRequest(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if roleFromContext(r) != string(auth.RoleAdmin) && guest.ResidentUserID != actorID {
+		WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	cancelled, err := h.Service.CancelGuestRequest(r.Context(), id, actorID)
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues("cancelled").Inc()
+	}
+	WriteJSON(w, http.StatusOK, mapGuest(cancelled))
+}
+
+type GuestConflictResponse struct {
+	Error                 string      `json:"error"`
+	ConflictingRequestIDs []uuid.UUID `json:"conflicting_request_ids"`
+}
+
+func writeGuestError(w http.ResponseWriter, err error) {
+	var conflict *service.GuestConflictError
+	switch {
+	case errors.As(err, &conflict):
+		WriteJSON(w, http.StatusConflict, GuestConflictResponse{Error: err.Error(), ConflictingRequestIDs: conflict.IDs})
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrForbidden):
+		WriteError(w, http.StatusForbidden, "forbidden")
+	case errors.Is(err, service.ErrGuestTransition), errors.Is(err, service.ErrGuestReviewed):
+		WriteError(w, http.StatusConflict, err.Error())
+	default:
+		WriteError(w, http.StatusBadRequest, err.Error())
+	}
+}
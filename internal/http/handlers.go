@@ -3,6 +3,7 @@ package http
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -45,6 +46,14 @@ type UserResponse struct {
 	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
 
+// UserStateResponse answers a block, unblock, delete or restore with the
+// passes and guest requests whose status changed along with the user.
+type UserStateResponse struct {
+	Status        string      `json:"status"`
+	Passes        []uuid.UUID `json:"passes"`
+	GuestRequests []uuid.UUID `json:"guest_requests"`
+}
+
 type CreateUserRequest struct {
 	Email      string `json:"email"`
 	Password   string `json:"password"`
@@ -67,38 +76,48 @@ type PassRequest struct {
 	VehicleBrand *string    `json:"vehicle_brand,omitempty"`
 	VehicleColor *string    `json:"vehicle_color,omitempty"`
 	Status       *string    `json:"status,omitempty"`
+	PlotID       *uuid.UUID `json:"plot_id,omitempty"`
 }
 
 type PassResponse struct {
-	ID              uuid.UUID  `json:"id"`
-	OwnerUserID     uuid.UUID  `json:"owner_user_id"`
-	OwnerFullName   *string    `json:"owner_full_name,omitempty"`
-	OwnerPlotNumber *string    `json:"owner_plot_number,omitempty"`
-	PlateNumber     string     `json:"plate_number"`
-	VehicleBrand    *string    `json:"vehicle_brand,omitempty"`
-	VehicleColor    *string    `json:"vehicle_color,omitempty"`
-	Status          string     `json:"status"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
-	CreatedBy       *uuid.UUID `json:"created_by,omitempty"`
-	UpdatedBy       *uuid.UUID `json:"updated_by,omitempty"`
-	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	ID              uuid.UUID              `json:"id"`
+	OwnerUserID     uuid.UUID              `json:"owner_user_id"`
+	OwnerFullName   *string                `json:"owner_full_name,omitempty"`
+	OwnerPlotNumber *string                `json:"owner_plot_number,omitempty"`
+	PlotID          *uuid.UUID             `json:"plot_id,omitempty"`
+	PlateNumber     string                 `json:"plate_number"`
+	VehicleBrand    *string                `json:"vehicle_brand,omitempty"`
+	VehicleColor    *string                `json:"vehicle_color,omitempty"`
+	Status          string                 `json:"status"`
+	AccessWindow    *AccessWindowResponse  `json:"access_window,omitempty"`
+	Watchlist       *WatchlistFlagResponse `json:"watchlist,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+	CreatedBy       *uuid.UUID             `json:"created_by,omitempty"`
+	UpdatedBy       *uuid.UUID             `json:"updated_by,omitempty"`
+	DeletedAt       *time.Time             `json:"deleted_at,omitempty"`
 }
 
 type GuestRequest struct {
 	ResidentUserID *uuid.UUID `json:"resident_user_id,omitempty"`
 	GuestFullName  string     `json:"guest_full_name"`
-	PlateNumber    string     `json:"plate_number"`
+	GuestType      *string    `json:"guest_type,omitempty"`
+	PlateNumber    *string    `json:"plate_number,omitempty"`
+	CompanyName    *string    `json:"company_name,omitempty"`
 	ValidFrom      time.Time  `json:"valid_from"`
 	ValidTo        time.Time  `json:"valid_to"`
 	Status         *string    `json:"status,omitempty"`
+	PlotID         *uuid.UUID `json:"plot_id,omitempty"`
 }
 
 type GuestResponse struct {
 	ID             uuid.UUID  `json:"id"`
 	ResidentUserID uuid.UUID  `json:"resident_user_id"`
+	PlotID         *uuid.UUID `json:"plot_id,omitempty"`
 	GuestFullName  string     `json:"guest_full_name"`
+	GuestType      string     `json:"guest_type"`
 	PlateNumber    string     `json:"plate_number"`
+	CompanyName    *string    `json:"company_name,omitempty"`
 	ValidFrom      time.Time  `json:"valid_from"`
 	ValidTo        time.Time  `json:"valid_to"`
 	Status         string     `json:"status"`
@@ -107,10 +126,48 @@ type GuestResponse struct {
 	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
 	UpdatedBy      *uuid.UUID `json:"updated_by,omitempty"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	ReviewedBy     *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	ReviewReason   *string    `json:"review_reason,omitempty"`
+	// ConflictingRequestIDs warns about other active requests for the same
+	// plate with an overlapping window.
+	ConflictingRequestIDs []uuid.UUID `json:"conflicting_request_ids,omitempty"`
 }
 
 type EntryRequest struct {
-	Comment *string `json:"comment"`
+	Comment  *string `json:"comment"`
+	Override bool    `json:"override"`
+	// OverrideReason lets a guard record an entry of a vehicle already on
+	// site or an exit without an entry.
+	OverrideReason *string `json:"override_reason"`
+	// GateID is where the movement happens; a guard assigned to a single
+	// gate may leave it out.
+	GateID *uuid.UUID `json:"gate_id"`
+}
+
+type EntryLogResponse struct {
+	ID                uuid.UUID              `json:"id"`
+	PassID            *uuid.UUID             `json:"pass_id,omitempty"`
+	GuestRequestID    *uuid.UUID             `json:"guest_request_id,omitempty"`
+	GuardUserID       uuid.UUID              `json:"guard_user_id"`
+	Action            string                 `json:"action"`
+	ActionAt          time.Time              `json:"action_at"`
+	Comment           *string                `json:"comment,omitempty"`
+	Anomaly           *string                `json:"anomaly,omitempty"`
+	OverrideReason    *string                `json:"override_reason,omitempty"`
+	Seq               int64                  `json:"seq"`
+	Hash              string                 `json:"hash"`
+	AmendsID          *uuid.UUID             `json:"amends_id,omitempty"`
+	CorrectedAction   *string                `json:"corrected_action,omitempty"`
+	CorrectedActionAt *time.Time             `json:"corrected_action_at,omitempty"`
+	GateID            *uuid.UUID             `json:"gate_id,omitempty"`
+	ShiftID           *uuid.UUID             `json:"shift_id,omitempty"`
+	DeviceAt          *time.Time             `json:"device_at,omitempty"`
+	AccessWindow      *AccessWindowResponse  `json:"access_window,omitempty"`
+	Watchlist         *WatchlistFlagResponse `json:"watchlist,omitempty"`
+	Photo             *EntryPhotoResponse    `json:"photo,omitempty"`
+	// Barrier is set when the entry triggered the gate's barrier.
+	Barrier *BarrierEventResponse `json:"barrier,omitempty"`
 }
 
 func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
@@ -303,14 +360,15 @@ func (h *Handler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	actorID := actorFromContext(r)
-	if err := h.Service.SoftDeleteUser(r.Context(), id, actorID); err != nil {
-		WriteError(w, http.StatusBadRequest, err.Error())
+	report, err := h.Service.SoftDeleteUser(r.Context(), id, actorID)
+	if err != nil {
+		writeUserStateError(w, err)
 		return
 	}
 	if h.Metrics != nil {
 		h.Metrics.Users.WithLabelValues("deleted").Inc()
 	}
-	WriteJSON(w, http.StatusNoContent, nil)
+	WriteJSON(w, http.StatusOK, mapUserState("deleted", report))
 }
 
 func (h *Handler) HandleRestoreUser(w http.ResponseWriter, r *http.Request) {
@@ -320,14 +378,15 @@ func (h *Handler) HandleRestoreUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	actorID := actorFromContext(r)
-	if err := h.Service.RestoreUser(r.Context(), id, actorID); err != nil {
-		WriteError(w, http.StatusBadRequest, err.Error())
+	report, err := h.Service.RestoreUser(r.Context(), id, actorID)
+	if err != nil {
+		writeUserStateError(w, err)
 		return
 	}
 	if h.Metrics != nil {
 		h.Metrics.Users.WithLabelValues("restored").Inc()
 	}
-	WriteJSON(w, http.StatusOK, map[string]string{"status": "restored"})
+	WriteJSON(w, http.StatusOK, mapUserState("restored", report))
 }
 
 func (h *Handler) HandleBlockUser(w http.ResponseWriter, r *http.Request) {
@@ -337,14 +396,15 @@ func (h *Handler) HandleBlockUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	actorID := actorFromContext(r)
-	if err := h.Service.BlockUser(r.Context(), id, actorID); err != nil {
-		WriteError(w, http.StatusBadRequest, err.Error())
+	report, err := h.Service.BlockUser(r.Context(), id, actorID)
+	if err != nil {
+		writeUserStateError(w, err)
 		return
 	}
 	if h.Metrics != nil {
 		h.Metrics.Users.WithLabelValues("blocked").Inc()
 	}
-	WriteJSON(w, http.StatusOK, map[string]string{"status": "blocked"})
+	WriteJSON(w, http.StatusOK, mapUserState("blocked", report))
 }
 
 func (h *Handler) HandleUnblockUser(w http.ResponseWriter, r *http.Request) {
@@ -354,14 +414,40 @@ func (h *Handler) HandleUnblockUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	actorID := actorFromContext(r)
-	if err := h.Service.UnblockUser(r.Context(), id, actorID); err != nil {
-		WriteError(w, http.StatusBadRequest, err.Error())
+	report, err := h.Service.UnblockUser(r.Context(), id, actorID)
+	if err != nil {
+		writeUserStateError(w, err)
 		return
 	}
 	if h.Metrics != nil {
 		h.Metrics.Users.WithLabelValues("unblocked").Inc()
 	}
-	WriteJSON(w, http.StatusOK, map[string]string{"status": "unblocked"})
+	WriteJSON(w, http.StatusOK, mapUserState("unblocked", report))
+}
+
+func writeUserStateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrNotFound) {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	WriteError(w, http.StatusBadRequest, err.Error())
+}
+
+// mapUserState reports the new user state with the passes and guest
+// requests that changed along with it.
+func mapUserState(status string, report service.CascadeReport) UserStateResponse {
+	resp := UserStateResponse{
+		Status:        status,
+		Passes:        report.Passes,
+		GuestRequests: report.GuestRequests,
+	}
+	if resp.Passes == nil {
+		resp.Passes = []uuid.UUID{}
+	}
+	if resp.GuestRequests == nil {
+		resp.GuestRequests = []uuid.UUID{}
+	}
+	return resp
 }
 
 func (h *Handler) HandleCreatePass(w http.ResponseWriter, r *http.Request) {
@@ -392,6 +478,7 @@ func (h *Handler) HandleCreatePass(w http.ResponseWriter, r *http.Request) {
 		VehicleBrand: toNullString(req.VehicleBrand),
 		VehicleColor: toNullString(req.VehicleColor),
 		Status:       status,
+		PlotID:       derefUUID(req.PlotID),
 		ActorID:      actorID,
 	})
 	if err != nil {
@@ -475,14 +562,24 @@ func (h *Handler) HandleSearchPasses(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	flags, err := h.Service.FlagPasses(r.Context(), passes, actorFromContext(r))
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "watchlist error")
+		return
+	}
 	resp := make([]PassResponse, 0, len(passes))
 	for _, pass := range passes {
-		owner, err := h.Service.GetUserAny(r.Context(), pass.OwnerUserID)
-		if err != nil {
-			resp = append(resp, mapPass(pass))
-			continue
+		item := mapPass(pass)
+		if owner, err := h.Service.GetUserAny(r.Context(), pass.OwnerUserID); err == nil {
+			item = mapPassWithOwner(pass, &owner)
 		}
-		resp = append(resp, mapPassWithOwner(pass, &owner))
+		if status, err := h.Service.CheckPassSchedule(r.Context(), pass.ID); err == nil {
+			item.AccessWindow = mapScheduleStatus(status)
+		}
+		if flag, ok := flags[pass.ID]; ok {
+			item.Watchlist = mapWatchlistFlag(&flag)
+		}
+		resp = append(resp, item)
 	}
 	WriteJSON(w, http.StatusOK, resp)
 }
@@ -500,45 +597,44 @@ func (h *Handler) HandleUpdatePass(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid payload")
 		return
 	}
-	pass, err := h.Service.GetPass(r.Context(), id)
-	if err != nil {
-		WriteError(w, http.StatusNotFound, "not found")
-		return
-	}
-	if role == string(auth.RoleResident) && pass.OwnerUserID != actorID {
-		WriteError(w, http.StatusForbidden, "forbidden")
-		return
-	}
 	if role == string(auth.RoleGuard) {
 		WriteError(w, http.StatusForbidden, "forbidden")
 		return
 	}
-
-	plate := pass.PlateNumber
-	if req.PlateNumber != "" {
-		plate = req.PlateNumber
-	}
-	brand := pass.VehicleBrand
-	color := pass.VehicleColor
-	if req.VehicleBrand != nil {
-		brand = toNullString(req.VehicleBrand)
-	}
-	if req.VehicleColor != nil {
-		color = toNullString(req.VehicleColor)
-	}
-	status := pass.Status
-	if req.Status != nil && role == string(auth.RoleAdmin) {
-		status = *req.Status
-	}
-	updated, err := h.Service.UpdatePass(r.Context(), service.PassUpdateInput{
-		ID:           id,
-		PlateNumber:  plate,
-		VehicleBrand: brand,
-		VehicleColor: color,
-		Status:       status,
-		ActorID:      actorID,
+	updated, err := h.Service.ModifyPass(r.Context(), id, func(pass repo.Pass) (service.PassUpdateInput, error) {
+		if role == string(auth.RoleResident) && pass.OwnerUserID != actorID {
+			return service.PassUpdateInput{}, service.ErrForbidden
+		}
+		input := service.PassUpdateInput{
+			PlateNumber:  pass.PlateNumber,
+			VehicleBrand: pass.VehicleBrand,
+			VehicleColor: pass.VehicleColor,
+			Status:       pass.Status,
+			PlotID:       derefUUID(req.PlotID),
+			ActorID:      actorID,
+		}
+		if req.PlateNumber != "" {
+			input.PlateNumber = req.PlateNumber
+		}
+		if req.VehicleBrand != nil {
+			input.VehicleBrand = toNullString(req.VehicleBrand)
+		}
+		if req.VehicleColor != nil {
+			input.VehicleColor = toNullString(req.VehicleColor)
+		}
+		if req.Status != nil && role == string(auth.RoleAdmin) {
+			input.Status = *req.Status
+		}
+		return input, nil
 	})
-	if err != nil {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	case errors.Is(err, service.ErrForbidden):
+		WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	case err != nil:
 		WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -611,11 +707,11 @@ func (h *Handler) HandleRestorePass(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) HandleEntry(w http.ResponseWriter, r *http.Request) {
-	h.handleEntryExit(w, r, "entry")
+	h.handleEntryExit(w, r, service.EntryActionEntry)
 }
 
 func (h *Handler) HandleExit(w http.ResponseWriter, r *http.Request) {
-	h.handleEntryExit(w, r, "exit")
+	h.handleEntryExit(w, r, service.EntryActionExit)
 }
 
 func (h *Handler) handleEntryExit(w http.ResponseWriter, r *http.Request, action string) {
@@ -630,22 +726,98 @@ func (h *Handler) handleEntryExit(w http.ResponseWriter, r *http.Request, action
 		return
 	}
 	var req EntryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
-		WriteError(w, http.StatusBadRequest, "invalid payload")
+	photo, ok := decodeGateRequest(w, r, &req)
+	if !ok {
 		return
 	}
-	_, err = h.Service.GetPass(r.Context(), passID)
+	pass, err := h.Service.GetPass(r.Context(), passID)
 	if err != nil {
 		WriteError(w, http.StatusNotFound, "pass not found")
 		return
 	}
 	comment := toNullString(req.Comment)
-	logEntry, err := h.Service.CreateEntryLog(r.Context(), passID, actorFromContext(r), action, comment)
-	if err != nil {
+	hit, ok := h.checkGate(w, r, req, service.GateCheckInput{
+		PassID:      passID,
+		PlateNumber: pass.PlateNumber,
+		Source:      action,
+	})
+	if !ok {
+		return
+	}
+	logEntry, err := h.Service.RecordPassMovement(r.Context(), service.PassMovementInput{
+		PassID:         passID,
+		GuardID:        actorFromContext(r),
+		Action:         action,
+		Comment:        comment,
+		OverrideReason: derefString(req.OverrideReason),
+		GateID:         derefUUID(req.GateID),
+		Photo:          photo,
+		Watchlist:      hit,
+	})
+	var conflict *service.PresenceConflictError
+	switch {
+	case errors.As(err, &conflict):
+		if h.Metrics != nil {
+			h.Metrics.Passes.WithLabelValues("presence_rejected").Inc()
+		}
+		WriteJSON(w, http.StatusConflict, mapPresenceConflict(conflict))
+		return
+	case errors.Is(err, service.ErrPassInactive):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case writeMovementError(w, err), writeUploadError(w, err):
+		return
+	case err != nil:
 		WriteError(w, http.StatusInternalServerError, "log error")
 		return
 	}
-	WriteJSON(w, http.StatusCreated, logEntry)
+	if logEntry.Anomaly.Valid && h.Metrics != nil {
+		h.Metrics.Passes.WithLabelValues("presence_anomaly").Inc()
+	}
+	resp := mapEntryLog(logEntry)
+	resp.Watchlist = mapGateWatchlist(hit)
+	if photo != nil {
+		resp.Photo = h.entryLogPhoto(r, logEntry.ID)
+	}
+	if status, err := h.Service.CheckPassSchedule(r.Context(), passID); err == nil {
+		resp.AccessWindow = mapScheduleStatus(status)
+	}
+	resp.Barrier = h.triggerBarrier(r, logEntry)
+	WriteJSON(w, http.StatusCreated, resp)
+}
+
+// checkGate runs the watchlist check for an entry or exit and writes the
+// error response when the vehicle must not pass. The returned hit goes with
+// the movement, which logs it once the journal accepts the vehicle.
+func (h *Handler) checkGate(w http.ResponseWriter, r *http.Request, req EntryRequest, input service.GateCheckInput) (*service.WatchlistHit, bool) {
+	// Only a supervisor (admin) may let a blacklisted plate through.
+	if req.Override && roleFromContext(r) != string(auth.RoleAdmin) {
+		WriteError(w, http.StatusForbidden, "override requires a supervisor")
+		return nil, false
+	}
+	input.UserID = actorFromContext(r)
+	input.Override = req.Override
+	input.Comment = toNullString(req.Comment).String
+	hit, err := h.Service.CheckGate(r.Context(), input)
+	switch {
+	case errors.Is(err, service.ErrPlateBlacklisted):
+		if h.Metrics != nil {
+			counter := h.Metrics.Passes
+			if input.PassID == uuid.Nil {
+				counter = h.Metrics.Guest
+			}
+			counter.WithLabelValues("watchlist_blocked").Inc()
+		}
+		WriteJSON(w, http.StatusForbidden, WatchlistBlockedResponse{Error: err.Error(), Watchlist: *mapGateWatchlist(hit)})
+		return nil, false
+	case errors.Is(err, service.ErrOverrideComment):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "watchlist error")
+		return nil, false
+	}
+	return hit, true
 }
 
 func (h *Handler) HandleCreateGuest(w http.ResponseWriter, r *http.Request) {
@@ -664,27 +836,32 @@ func (h *Handler) HandleCreateGuest(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusForbidden, "forbidden")
 		return
 	}
-	status := "pending"
+	// Residents always go through review; an admin may file the request as
+	// already approved.
+	status := ""
 	if req.Status != nil && role == string(auth.RoleAdmin) {
 		status = *req.Status
 	}
 	guest, err := h.Service.CreateGuestRequest(r.Context(), service.GuestCreateInput{
 		ResidentID:  residentID,
 		GuestName:   req.GuestFullName,
-		PlateNumber: req.PlateNumber,
+		GuestType:   derefString(req.GuestType),
+		PlateNumber: derefString(req.PlateNumber),
+		CompanyName: derefString(req.CompanyName),
 		ValidFrom:   req.ValidFrom,
 		ValidTo:     req.ValidTo,
 		Status:      status,
+		PlotID:      derefUUID(req.PlotID),
 		ActorID:     actorID,
 	})
 	if err != nil {
-		WriteError(w, http.StatusBadRequest, err.Error())
+		writeGuestError(w, err)
 		return
 	}
 	if h.Metrics != nil {
 		h.Metrics.Guest.WithLabelValues("created").Inc()
 	}
-	WriteJSON(w, http.StatusCreated, mapGuest(guest))
+	WriteJSON(w, http.StatusCreated, h.mapGuestWithConflicts(r, guest))
 }
 
 func (h *Handler) HandleListGuest(w http.ResponseWriter, r *http.Request) {
@@ -753,57 +930,65 @@ func (h *Handler) HandleUpdateGuest(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, http.StatusBadRequest, "invalid payload")
 		return
 	}
-	guest, err := h.Service.GetGuestRequest(r.Context(), id)
-	if err != nil {
-		WriteError(w, http.StatusNotFound, "not found")
-		return
-	}
-	if role == string(auth.RoleResident) && guest.ResidentUserID != actorID {
-		WriteError(w, http.StatusForbidden, "forbidden")
-		return
-	}
 	if role == string(auth.RoleGuard) {
 		WriteError(w, http.StatusForbidden, "forbidden")
 		return
 	}
-
-	guestName := guest.GuestFullName
-	plate := guest.PlateNumber
-	validFrom := guest.ValidFrom
-	validTo := guest.ValidTo
-	status := guest.Status
-	if req.GuestFullName != "" {
-		guestName = req.GuestFullName
-	}
-	if req.PlateNumber != "" {
-		plate = req.PlateNumber
-	}
-	if !req.ValidFrom.IsZero() {
-		validFrom = req.ValidFrom
-	}
-	if !req.ValidTo.IsZero() {
-		validTo = req.ValidTo
-	}
-	if req.Status != nil && role == string(auth.RoleAdmin) {
-		status = *req.Status
+	if req.Status != nil {
+		WriteError(w, http.StatusBadRequest, "status is changed via approve, reject or cancel")
+		return
 	}
-	updated, err := h.Service.UpdateGuestRequest(r.Context(), service.GuestUpdateInput{
-		ID:          id,
-		GuestName:   guestName,
-		PlateNumber: plate,
-		ValidFrom:   validFrom,
-		ValidTo:     validTo,
-		Status:      status,
-		ActorID:     actorID,
+	updated, err := h.Service.ModifyGuestRequest(r.Context(), id, func(guest repo.GuestRequest) (service.GuestUpdateInput, error) {
+		if role == string(auth.RoleResident) && guest.ResidentUserID != actorID {
+			return service.GuestUpdateInput{}, service.ErrForbidden
+		}
+		// An approved request is edited only by an admin, otherwise a resident
+		// could stretch the window after review.
+		if role == string(auth.RoleResident) && guest.Status != service.GuestStatusPending {
+			return service.GuestUpdateInput{}, service.ErrGuestReviewed
+		}
+		input := service.GuestUpdateInput{
+			GuestName:   guest.GuestFullName,
+			GuestType:   guest.GuestType,
+			PlateNumber: guest.PlateNumber,
+			CompanyName: guest.CompanyName.String,
+			ValidFrom:   guest.ValidFrom,
+			ValidTo:     guest.ValidTo,
+			PlotID:      derefUUID(req.PlotID),
+			ActorID:     actorID,
+		}
+		if req.GuestFullName != "" {
+			input.GuestName = req.GuestFullName
+		}
+		if req.GuestType != nil {
+			input.GuestType = *req.GuestType
+			// A guest who now comes on foot drops the old plate.
+			if input.GuestType == service.GuestTypePedestrian && req.PlateNumber == nil {
+				input.PlateNumber = ""
+			}
+		}
+		if req.PlateNumber != nil {
+			input.PlateNumber = *req.PlateNumber
+		}
+		if req.CompanyName != nil {
+			input.CompanyName = *req.CompanyName
+		}
+		if !req.ValidFrom.IsZero() {
+			input.ValidFrom = req.ValidFrom
+		}
+		if !req.ValidTo.IsZero() {
+			input.ValidTo = req.ValidTo
+		}
+		return input, nil
 	})
 	if err != nil {
-		WriteError(w, http.StatusBadRequest, err.Error())
+		writeGuestError(w, err)
 		return
 	}
 	if h.Metrics != nil {
 		h.Metrics.Guest.WithLabelValues("updated").Inc()
 	}
-	WriteJSON(w, http.StatusOK, mapGuest(updated))
+	WriteJSON(w, http.StatusOK, h.mapGuestWithConflicts(r, updated))
 }
 
 func (h *Handler) HandleDeleteGuest(w http.ResponseWriter, r *http.Request) {
@@ -904,6 +1089,9 @@ func mapPass(pass repo.Pass) PassResponse {
 		CreatedAt:   pass.CreatedAt,
 		UpdatedAt:   pass.UpdatedAt,
 	}
+	if pass.PlotID.Valid {
+		resp.PlotID = &pass.PlotID.UUID
+	}
 	if pass.VehicleBrand.Valid {
 		resp.VehicleBrand = &pass.VehicleBrand.String
 	}
@@ -942,11 +1130,57 @@ func mapPasses(passes []repo.Pass) []PassResponse {
 	return resp
 }
 
+func mapEntryLog(entry repo.EntryLog) EntryLogResponse {
+	resp := EntryLogResponse{
+		ID:          entry.ID,
+		GuardUserID: entry.GuardUserID,
+		Action:      entry.Action,
+		ActionAt:    entry.ActionAt,
+		Seq:         entry.Seq,
+		Hash:        entry.Hash,
+	}
+	if entry.PassID.Valid {
+		resp.PassID = &entry.PassID.UUID
+	}
+	if entry.GuestRequestID.Valid {
+		resp.GuestRequestID = &entry.GuestRequestID.UUID
+	}
+	if entry.Comment.Valid {
+		resp.Comment = &entry.Comment.String
+	}
+	if entry.Anomaly.Valid {
+		resp.Anomaly = &entry.Anomaly.String
+	}
+	if entry.OverrideReason.Valid {
+		resp.OverrideReason = &entry.OverrideReason.String
+	}
+	if entry.AmendsID.Valid {
+		resp.AmendsID = &entry.AmendsID.UUID
+	}
+	if entry.CorrectedAction.Valid {
+		resp.CorrectedAction = &entry.CorrectedAction.String
+	}
+	if entry.CorrectedActionAt.Valid {
+		resp.CorrectedActionAt = &entry.CorrectedActionAt.Time
+	}
+	if entry.GateID.Valid {
+		resp.GateID = &entry.GateID.UUID
+	}
+	if entry.ShiftID.Valid {
+		resp.ShiftID = &entry.ShiftID.UUID
+	}
+	if entry.DeviceAt.Valid {
+		resp.DeviceAt = &entry.DeviceAt.Time
+	}
+	return resp
+}
+
 func mapGuest(guest repo.GuestRequest) GuestResponse {
 	resp := GuestResponse{
 		ID:             guest.ID,
 		ResidentUserID: guest.ResidentUserID,
 		GuestFullName:  guest.GuestFullName,
+		GuestType:      guest.GuestType,
 		PlateNumber:    guest.PlateNumber,
 		ValidFrom:      guest.ValidFrom,
 		ValidTo:        guest.ValidTo,
@@ -954,6 +1188,12 @@ func mapGuest(guest repo.GuestRequest) GuestResponse {
 		CreatedAt:      guest.CreatedAt,
 		UpdatedAt:      guest.UpdatedAt,
 	}
+	if guest.PlotID.Valid {
+		resp.PlotID = &guest.PlotID.UUID
+	}
+	if guest.CompanyName.Valid {
+		resp.CompanyName = &guest.CompanyName.String
+	}
 	if guest.CreatedBy.Valid {
 		resp.CreatedBy = &guest.CreatedBy.UUID
 	}
@@ -963,6 +1203,25 @@ func mapGuest(guest repo.GuestRequest) GuestResponse {
 	if guest.DeletedAt.Valid {
 		resp.DeletedAt = &guest.DeletedAt.Time
 	}
+	if guest.ReviewedBy.Valid {
+		resp.ReviewedBy = &guest.ReviewedBy.UUID
+	}
+	if guest.ReviewedAt.Valid {
+		resp.ReviewedAt = &guest.ReviewedAt.Time
+	}
+	if guest.ReviewReason.Valid {
+		resp.ReviewReason = &guest.ReviewReason.String
+	}
+	return resp
+}
+
+// mapGuestWithConflicts adds the overlap warning to a saved request; the
+// lookup is best effort since the request is already stored.
+func (h *Handler) mapGuestWithConflicts(r *http.Request, guest repo.GuestRequest) GuestResponse {
+	resp := mapGuest(guest)
+	if conflicts, err := h.Service.GuestConflicts(r.Context(), guest); err == nil {
+		resp.ConflictingRequestIDs = conflicts
+	}
 	return resp
 }
 
@@ -1010,6 +1269,34 @@ func parsePagination(r *http.Request) (int32, int32) {
 	return limit, offset
 }
 
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func derefUUID(value *uuid.UUID) uuid.UUID {
+	if value == nil {
+		return uuid.Nil
+	}
+	return *value
+}
+
+func nullUUIDPtr(value uuid.NullUUID) *uuid.UUID {
+	if !value.Valid {
+		return nil
+	}
+	return &value.UUID
+}
+
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
 func toNullString(value *string) sql.NullString {
 	if value == nil {
 		return sql.NullString{Valid: false}
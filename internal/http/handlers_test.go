@@ -5,16 +5,22 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 
 	"pipo-edu-project/internal/auth"
+	"pipo-edu-project/internal/barrier"
 	repo "pipo-edu-project/internal/repository/sqlc"
 	"pipo-edu-project/internal/service"
+	"pipo-edu-project/internal/tabular"
 )
 
 type stubService struct{}
@@ -47,28 +53,38 @@ func (s stubService) UpdateUserPassword(ctx context.Context, input service.UserP
 	return repo.User{ID: input.ID}, nil
 }
 
-func (s stubService) SoftDeleteUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return nil
+func (s stubService) SoftDeleteUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error) {
+	if id == uuid.Nil {
+		return service.CascadeReport{}, service.ErrNotFound
+	}
+	return service.CascadeReport{Passes: []uuid.UUID{uuid.New()}, GuestRequests: []uuid.UUID{uuid.New()}}, nil
 }
 
-func (s stubService) RestoreUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return nil
+func (s stubService) RestoreUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error) {
+	return service.CascadeReport{}, nil
 }
 
-func (s stubService) BlockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return nil
+func (s stubService) BlockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error) {
+	return service.CascadeReport{Passes: []uuid.UUID{uuid.New()}}, nil
 }
 
-func (s stubService) UnblockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return nil
+func (s stubService) UnblockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (service.CascadeReport, error) {
+	return service.CascadeReport{}, nil
 }
 
 func (s stubService) CreatePass(ctx context.Context, input service.PassCreateInput) (repo.Pass, error) {
 	return repo.Pass{ID: uuid.New(), OwnerUserID: input.OwnerID, PlateNumber: input.PlateNumber, Status: input.Status, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
 }
 
+// ownedPassID is the pass of guestOwnerID; any other pass has a random owner.
+var ownedPassID = uuid.MustParse("0b7f3e52-6c1d-4a98-8e2f-93d4a5c1b760")
+
 func (s stubService) GetPass(ctx context.Context, id uuid.UUID) (repo.Pass, error) {
-	return repo.Pass{ID: id, OwnerUserID: uuid.New(), PlateNumber: "A123BC77", Status: "active", CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
+	owner := uuid.New()
+	if id == ownedPassID {
+		owner = guestOwnerID
+	}
+	return repo.Pass{ID: id, OwnerUserID: owner, PlateNumber: "A123BC77", Status: "active", CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
 }
 
 func (s stubService) GetPassAny(ctx context.Context, id uuid.UUID) (repo.Pass, error) {
@@ -91,6 +107,16 @@ func (s stubService) UpdatePass(ctx context.Context, input service.PassUpdateInp
 	return repo.Pass{ID: input.ID, PlateNumber: input.PlateNumber, Status: input.Status, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
 }
 
+func (s stubService) ModifyPass(ctx context.Context, id uuid.UUID, fn func(current repo.Pass) (service.PassUpdateInput, error)) (repo.Pass, error) {
+	current, _ := s.GetPass(ctx, id)
+	input, err := fn(current)
+	if err != nil {
+		return repo.Pass{}, err
+	}
+	input.ID = id
+	return s.UpdatePass(ctx, input)
+}
+
 func (s stubService) SoftDeletePass(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
 	return nil
 }
@@ -100,10 +126,25 @@ func (s stubService) RestorePass(ctx context.Context, id uuid.UUID, actor uuid.U
 }
 
 func (s stubService) CreateGuestRequest(ctx context.Context, input service.GuestCreateInput) (repo.GuestRequest, error) {
-	return repo.GuestRequest{ID: uuid.New(), ResidentUserID: input.ResidentID, GuestFullName: input.GuestName, PlateNumber: input.PlateNumber, Status: input.Status, ValidFrom: input.ValidFrom, ValidTo: input.ValidTo, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
+	if input.PlateNumber == "C333CC77" {
+		return repo.GuestRequest{}, &service.GuestConflictError{IDs: []uuid.UUID{approvedGuestID}}
+	}
+	if !input.ValidTo.After(time.Now()) {
+		return repo.GuestRequest{}, service.ErrGuestWindowPast
+	}
+	return repo.GuestRequest{ID: uuid.New(), ResidentUserID: input.ResidentID, GuestFullName: input.GuestName, GuestType: input.GuestType, PlateNumber: input.PlateNumber, CompanyName: sql.NullString{String: input.CompanyName, Valid: input.CompanyName != ""}, Status: input.Status, ValidFrom: input.ValidFrom, ValidTo: input.ValidTo, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
 }
 
+var (
+	approvedGuestID = uuid.MustParse("0b7f3d52-5e1a-4f8c-8d2e-93c4a6b1f702")
+	guestOwnerID    = uuid.MustParse("c4e81a07-2f6b-4d39-b5a8-7e0d9f3c2a64")
+	orphanGuestID   = uuid.MustParse("5d2c9e41-7a3b-4f06-9c18-e6b0a4d7f253")
+)
+
 func (s stubService) GetGuestRequest(ctx context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+	if id == approvedGuestID {
+		return repo.GuestRequest{ID: id, ResidentUserID: guestOwnerID, GuestFullName: "Guest", GuestType: service.GuestTypeVehicle, PlateNumber: "A123BC77", Status: service.GuestStatusApproved, ValidFrom: time.Now(), ValidTo: time.Now().Add(2 * time.Hour)}, nil
+	}
 	return repo.GuestRequest{ID: id, ResidentUserID: uuid.New(), GuestFullName: "Guest", PlateNumber: "A123BC77", Status: "pending", ValidFrom: time.Now(), ValidTo: time.Now().Add(2 * time.Hour), CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
 }
 
@@ -120,7 +161,17 @@ func (s stubService) ListGuestRequestsByResident(ctx context.Context, resident u
 }
 
 func (s stubService) UpdateGuestRequest(ctx context.Context, input service.GuestUpdateInput) (repo.GuestRequest, error) {
-	return repo.GuestRequest{ID: input.ID, GuestFullName: input.GuestName, PlateNumber: input.PlateNumber, Status: input.Status, ValidFrom: input.ValidFrom, ValidTo: input.ValidTo, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
+	return repo.GuestRequest{ID: input.ID, GuestFullName: input.GuestName, GuestType: input.GuestType, PlateNumber: input.PlateNumber, CompanyName: sql.NullString{String: input.CompanyName, Valid: input.CompanyName != ""}, Status: input.Status, ValidFrom: input.ValidFrom, ValidTo: input.ValidTo, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
+}
+
+func (s stubService) ModifyGuestRequest(ctx context.Context, id uuid.UUID, fn func(current repo.GuestRequest) (service.GuestUpdateInput, error)) (repo.GuestRequest, error) {
+	current, _ := s.GetGuestRequest(ctx, id)
+	input, err := fn(current)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	input.ID, input.Status = id, current.Status
+	return s.UpdateGuestRequest(ctx, input)
 }
 
 func (s stubService) SoftDeleteGuestRequest(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
@@ -131,102 +182,2745 @@ func (s stubService) RestoreGuestRequest(ctx context.Context, id uuid.UUID, acto
 	return nil
 }
 
-func (s stubService) CreateEntryLog(ctx context.Context, passID, guardID uuid.UUID, action string, comment sql.NullString) (repo.EntryLog, error) {
-	return repo.EntryLog{ID: uuid.New(), PassID: passID, GuardUserID: guardID, Action: action, ActionAt: time.Now()}, nil
+func (s stubService) ApproveGuestRequest(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestRequest, error) {
+	if id == approvedGuestID {
+		return repo.GuestRequest{}, service.ErrGuestTransition
+	}
+	return repo.GuestRequest{ID: id, Status: service.GuestStatusApproved, ReviewedBy: uuid.NullUUID{UUID: reviewer, Valid: true}}, nil
 }
 
-func (s stubService) ListEntryLogs(ctx context.Context, passID uuid.UUID, limit, offset int32) ([]repo.EntryLog, error) {
-	return []repo.EntryLog{}, nil
+func (s stubService) RejectGuestRequest(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestRequest, error) {
+	if reason == "" {
+		return repo.GuestRequest{}, service.ErrReviewReason
+	}
+	return repo.GuestRequest{ID: id, Status: service.GuestStatusRejected, ReviewReason: sql.NullString{String: reason, Valid: true}}, nil
 }
 
-func newAuthToken(role auth.Role) string {
-	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
-	access, _, _ := manager.GenerateTokens(uuid.New(), role)
-	return access
+func (s stubService) CancelGuestRequest(ctx context.Context, id, actor uuid.UUID) (repo.GuestRequest, error) {
+	return repo.GuestRequest{ID: id, Status: service.GuestStatusCancelled}, nil
 }
 
-func setupRouter() http.Handler {
-	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
-	return NewRouter(&Handler{Auth: manager, Service: stubService{}})
+func (s stubService) ExpectedGuests(ctx context.Context, ahead time.Duration, guestType string, limit, offset int32) ([]repo.ListGateGuestsRow, error) {
+	if ahead <= 0 || ahead > service.MaxExpectedWindow {
+		return nil, service.ErrInvalidRange
+	}
+	switch guestType {
+	case "":
+	case service.GuestTypeDelivery:
+		return []repo.ListGateGuestsRow{{ID: uuid.New(), GuestFullName: "Courier", GuestType: guestType, CompanyName: sql.NullString{String: "Fast Food", Valid: true}, Status: service.GuestStatusApproved, ResidentFullName: "Resident"}}, nil
+	default:
+		return nil, service.ErrInvalidGuestType
+	}
+	return []repo.ListGateGuestsRow{{ID: uuid.New(), GuestFullName: "Guest", GuestType: service.GuestTypeVehicle, PlateNumber: "A123BC77", Status: service.GuestStatusApproved, ResidentFullName: "Resident", ResidentPlotNumber: sql.NullString{String: "12", Valid: true}}}, nil
 }
 
-func TestGuardCannotCreatePass(t *testing.T) {
-	router := setupRouter()
-	payload := map[string]string{"plate_number": "A123BC77"}
-	body, _ := json.Marshal(payload)
+func (s stubService) SearchGuestsByPlate(ctx context.Context, plate, guestType string, limit, offset int32) ([]repo.ListGateGuestsRow, error) {
+	if err := service.ValidatePlate(plate); err != nil {
+		return nil, err
+	}
+	return []repo.ListGateGuestsRow{{ID: uuid.New(), PlateNumber: service.NormalizePlate(plate), Status: service.GuestStatusApproved, ResidentFullName: "Resident"}}, nil
+}
 
-	req := httptest.NewRequest(http.MethodPost, "/passes", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
-	resp := httptest.NewRecorder()
+var guestSeriesID = uuid.MustParse("5d2c9e41-7a3b-4c6f-9e18-2b4f6a8d0c35")
 
-	router.ServeHTTP(resp, req)
-	if resp.Code != http.StatusForbidden {
-		t.Fatalf("expected 403, got %d", resp.Code)
+func (s stubService) CreateGuestSeries(ctx context.Context, input service.GuestSeriesInput) (repo.GuestSeries, error) {
+	if input.RRule == "" && len(input.Weekdays) == 0 {
+		return repo.GuestSeries{}, service.ErrInvalidRecurrence
 	}
+	return repo.GuestSeries{ID: uuid.New(), ResidentUserID: input.ResidentID, GuestFullName: input.GuestName, PlateNumber: input.PlateNumber, Rrule: input.RRule, StartsOn: time.Now(), Status: service.GuestStatusPending}, nil
 }
 
-func TestAdminCanCreateUser(t *testing.T) {
-	router := setupRouter()
-	payload := map[string]string{"email": "user@example.com", "password": "secret", "role": "resident", "full_name": "User", "plot_number": "12A"}
-	body, _ := json.Marshal(payload)
+func (s stubService) GetGuestSeries(ctx context.Context, id uuid.UUID) (repo.GuestSeries, error) {
+	if id != guestSeriesID {
+		return repo.GuestSeries{}, service.ErrNotFound
+	}
+	return repo.GuestSeries{ID: id, ResidentUserID: guestOwnerID, GuestFullName: "Nanny", PlateNumber: "A123BC77", Rrule: "FREQ=WEEKLY;BYDAY=TU", StartsOn: time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC), StartMinute: 540, EndMinute: 1080, Status: service.GuestStatusApproved}, nil
+}
 
-	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
-	resp := httptest.NewRecorder()
+func (s stubService) ListGuestSeries(ctx context.Context, limit, offset int32) ([]repo.GuestSeries, error) {
+	return []repo.GuestSeries{}, nil
+}
 
-	router.ServeHTTP(resp, req)
-	if resp.Code != http.StatusCreated {
-		t.Fatalf("expected 201, got %d", resp.Code)
+func (s stubService) ListGuestSeriesByResident(ctx context.Context, resident uuid.UUID, limit, offset int32) ([]repo.GuestSeries, error) {
+	return []repo.GuestSeries{}, nil
+}
+
+func (s stubService) UpdateGuestSeries(ctx context.Context, input service.GuestSeriesInput) (repo.GuestSeries, error) {
+	status := input.Status
+	if status == "" {
+		status = service.GuestStatusPending
 	}
+	return repo.GuestSeries{ID: uuid.New(), ResidentUserID: guestOwnerID, GuestFullName: input.GuestName, PlateNumber: input.PlateNumber, Rrule: input.RRule, Status: status, PreviousSeriesID: uuid.NullUUID{UUID: input.ID, Valid: true}}, nil
 }
 
-func TestResidentCannotAccessUsers(t *testing.T) {
-	router := setupRouter()
-	req := httptest.NewRequest(http.MethodGet, "/users", nil)
-	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleResident))
-	resp := httptest.NewRecorder()
+func (s stubService) ApproveGuestSeries(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestSeries, error) {
+	return repo.GuestSeries{}, service.ErrGuestTransition
+}
 
-	router.ServeHTTP(resp, req)
-	if resp.Code != http.StatusForbidden {
-		t.Fatalf("expected 403, got %d", resp.Code)
+func (s stubService) RejectGuestSeries(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestSeries, error) {
+	if reason == "" {
+		return repo.GuestSeries{}, service.ErrReviewReason
 	}
+	return repo.GuestSeries{ID: id, Status: service.GuestStatusRejected}, nil
 }
 
-func TestAdminCanBlockUser(t *testing.T) {
-	router := setupRouter()
-	req := httptest.NewRequest(http.MethodPost, "/users/"+uuid.New().String()+"/block", nil)
-	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
-	resp := httptest.NewRecorder()
+func (s stubService) CancelGuestSeries(ctx context.Context, id, actor uuid.UUID) (repo.GuestSeries, error) {
+	return repo.GuestSeries{ID: id, Status: service.GuestStatusCancelled}, nil
+}
 
-	router.ServeHTTP(resp, req)
-	if resp.Code != http.StatusOK {
-		t.Fatalf("expected 200, got %d", resp.Code)
+func (s stubService) ListGuestOccurrences(ctx context.Context, seriesID uuid.UUID, from, to string) ([]service.GuestOccurrence, error) {
+	if from == "bad" {
+		return nil, service.ErrInvalidRange
 	}
+	day := time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC)
+	skipped := repo.GuestRequest{ID: uuid.New(), Status: service.GuestStatusCancelled}
+	return []service.GuestOccurrence{
+		{Day: day, Status: service.GuestStatusApproved},
+		{Day: day.AddDate(0, 0, 7), Status: service.GuestStatusCancelled, Request: &skipped},
+	}, nil
 }
 
-func TestResidentCanCreatePass(t *testing.T) {
-	router := setupRouter()
-	payload := map[string]string{"plate_number": "A123BC77"}
-	body, _ := json.Marshal(payload)
+func (s stubService) GetGuestOccurrence(ctx context.Context, seriesID uuid.UUID, day string) (repo.GuestRequest, error) {
+	if day != "2025-05-06" {
+		return repo.GuestRequest{}, service.ErrNotFound
+	}
+	return repo.GuestRequest{ID: uuid.New(), ResidentUserID: guestOwnerID, GuestFullName: "Nanny", PlateNumber: "A123BC77", Status: service.GuestStatusApproved}, nil
+}
 
-	req := httptest.NewRequest(http.MethodPost, "/passes", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleResident))
-	resp := httptest.NewRecorder()
+func (s stubService) SkipGuestOccurrence(ctx context.Context, seriesID uuid.UUID, day string, actor uuid.UUID) (repo.GuestRequest, error) {
+	guest, err := s.GetGuestOccurrence(ctx, seriesID, day)
+	guest.Status = service.GuestStatusCancelled
+	return guest, err
+}
 
-	router.ServeHTTP(resp, req)
-	if resp.Code != http.StatusCreated {
-		t.Fatalf("expected 201, got %d", resp.Code)
+func (s stubService) UpdateGuestOccurrence(ctx context.Context, input service.GuestOccurrenceInput) (repo.GuestRequest, error) {
+	status := input.Status
+	if status == "" {
+		status = service.GuestStatusPending
 	}
+	return repo.GuestRequest{ID: input.ID, GuestFullName: input.GuestName, PlateNumber: input.PlateNumber, Status: status}, nil
 }
 
-func TestGuardCanSearchPasses(t *testing.T) {
-	router := setupRouter()
-	req := httptest.NewRequest(http.MethodGet, "/passes/search?plate=A123BC77", nil)
-	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
-	resp := httptest.NewRecorder()
+func (s stubService) GuestConflicts(ctx context.Context, guest repo.GuestRequest) ([]uuid.UUID, error) {
+	if guest.PlateNumber == "B456CE99" {
+		return []uuid.UUID{approvedGuestID}, nil
+	}
+	return nil, nil
+}
 
-	router.ServeHTTP(resp, req)
-	if resp.Code != http.StatusOK {
-		t.Fatalf("expected 200, got %d", resp.Code)
+func (s stubService) GuestPin(ctx context.Context, guest repo.GuestRequest, actor uuid.UUID) (repo.GuestPin, error) {
+	if guest.Status != service.GuestStatusApproved {
+		return repo.GuestPin{}, service.ErrGuestPinUnavailable
+	}
+	return repo.GuestPin{GuestRequestID: guest.ID, Code: "042917"}, nil
+}
+
+func (s stubService) RegenerateGuestPin(ctx context.Context, guest repo.GuestRequest, actor uuid.UUID) (repo.GuestPin, error) {
+	if guest.Status != service.GuestStatusApproved {
+		return repo.GuestPin{}, service.ErrGuestPinUnavailable
+	}
+	return repo.GuestPin{GuestRequestID: guest.ID, Code: "318604"}, nil
+}
+
+func (s stubService) GuestByPin(ctx context.Context, code string, guardID uuid.UUID) (repo.GetGuestByPinRow, error) {
+	switch code {
+	case "042917":
+		return repo.GetGuestByPinRow{ID: approvedGuestID, GuestFullName: "Guest", PlateNumber: "A123BC77", Status: service.GuestStatusApproved, ResidentFullName: "Owner"}, nil
+	case "999999":
+		return repo.GetGuestByPinRow{}, service.ErrGuestPinAttempts
+	}
+	return repo.GetGuestByPinRow{}, service.ErrInvalidGuestPin
+}
+
+var savedGuestID = uuid.MustParse("5d2c9e14-7a3b-4f60-9c81-2e4b7d0a6f35")
+
+func (s stubService) CreateSavedGuest(ctx context.Context, input service.SavedGuestInput) (repo.SavedGuest, error) {
+	if input.GuestName == "" {
+		return repo.SavedGuest{}, service.ErrInvalidInput
+	}
+	return repo.SavedGuest{ID: uuid.New(), ResidentUserID: input.ResidentID, GuestFullName: input.GuestName, GuestType: input.GuestType, PlateNumber: input.PlateNumber}, nil
+}
+
+func (s stubService) GetSavedGuest(ctx context.Context, id, resident uuid.UUID) (repo.SavedGuest, error) {
+	if id != savedGuestID || resident != guestOwnerID {
+		return repo.SavedGuest{}, service.ErrNotFound
+	}
+	return repo.SavedGuest{ID: id, ResidentUserID: resident, GuestFullName: "Nanny", GuestType: service.GuestTypeVehicle, PlateNumber: "A123BC77", Notes: sql.NullString{String: "Tuesdays", Valid: true}}, nil
+}
+
+func (s stubService) ListSavedGuests(ctx context.Context, resident uuid.UUID, limit, offset int32) ([]repo.SavedGuest, error) {
+	guest, err := s.GetSavedGuest(ctx, savedGuestID, resident)
+	if err != nil {
+		return []repo.SavedGuest{}, nil
+	}
+	return []repo.SavedGuest{guest}, nil
+}
+
+func (s stubService) UpdateSavedGuest(ctx context.Context, input service.SavedGuestInput) (repo.SavedGuest, error) {
+	if input.GuestType == service.GuestTypePedestrian && input.PlateNumber != "" {
+		return repo.SavedGuest{}, service.ErrPedestrianPlate
+	}
+	return repo.SavedGuest{ID: input.ID, ResidentUserID: input.ResidentID, GuestFullName: input.GuestName, GuestType: input.GuestType, PlateNumber: input.PlateNumber, Notes: sql.NullString{String: input.Notes, Valid: input.Notes != ""}}, nil
+}
+
+func (s stubService) DeleteSavedGuest(ctx context.Context, id, resident uuid.UUID) error {
+	_, err := s.GetSavedGuest(ctx, id, resident)
+	return err
+}
+
+func (s stubService) SuggestSavedGuests(ctx context.Context, resident uuid.UUID, limit int32) ([]repo.ListFrequentGuestsRow, error) {
+	return []repo.ListFrequentGuestsRow{{GuestFullName: "Plumber", GuestType: service.GuestTypeService, Visits: int64(limit), LastVisitAt: time.Now()}}, nil
+}
+
+// onSitePassID is a pass whose vehicle is on site, so entering it again is a
+// double entry.
+var onSitePassID = uuid.MustParse("9a41c6d2-3e5f-4b87-a0d9-6c2e8f1b5a34")
+
+// suspendedPassID is a pass the journal refuses to let in.
+var suspendedPassID = uuid.MustParse("2f8c4a61-9d3e-4b70-8a15-6e0b3d7c9f42")
+
+func (s stubService) RecordPassMovement(ctx context.Context, input service.PassMovementInput) (repo.EntryLog, error) {
+	if input.GateID == closedGateID {
+		return repo.EntryLog{}, service.ErrGateNotAssigned
+	}
+	if len(input.Photo) > 0 && !strings.HasPrefix(string(input.Photo), "\x89PNG") {
+		return repo.EntryLog{}, service.ErrAttachmentType
+	}
+	if input.PassID == suspendedPassID && input.Action == service.EntryActionEntry {
+		return repo.EntryLog{}, service.ErrPassInactive
+	}
+	if input.PassID == blacklistedPassID && input.Watchlist == nil {
+		return repo.EntryLog{}, errors.New("the watchlist hit must be logged with the movement")
+	}
+	entry := repo.EntryLog{ID: uuid.New(), PassID: uuid.NullUUID{UUID: input.PassID, Valid: true}, GuardUserID: input.GuardID, Action: input.Action, ActionAt: time.Now(), Comment: input.Comment, GateID: uuid.NullUUID{UUID: input.GateID, Valid: input.GateID != uuid.Nil}}
+	if input.PassID == onSitePassID && input.Action == service.EntryActionEntry {
+		if input.OverrideReason == "" {
+			return repo.EntryLog{}, &service.PresenceConflictError{Anomaly: service.EntryAnomalyDoubleEntry, EnteredAt: time.Now().Add(-time.Hour)}
+		}
+		entry.Anomaly = sql.NullString{String: service.EntryAnomalyDoubleEntry, Valid: true}
+		entry.OverrideReason = sql.NullString{String: input.OverrideReason, Valid: true}
+	}
+	return entry, nil
+}
+
+func (s stubService) OnSite(ctx context.Context, plot string) ([]service.OnSiteEntry, error) {
+	return []service.OnSiteEntry{{
+		ListSitePresenceRow: repo.ListSitePresenceRow{
+			PassID:          uuid.NullUUID{UUID: onSitePassID, Valid: true},
+			PlateNumber:     "A123BC77",
+			OwnerFullName:   "Resident",
+			OwnerPlotNumber: sql.NullString{String: plot, Valid: plot != ""},
+			EnteredAt:       time.Now().Add(-90 * time.Minute),
+			GuardFullName:   "Guard",
+		},
+		Duration: 90 * time.Minute,
+	}}, nil
+}
+
+// amendmentLogID is an amendment record; any other journal id is an
+// ordinary entry.
+var amendmentLogID = uuid.MustParse("5e2d8b41-7a3c-4f69-b1e0-2c9d7f4a6b83")
+
+func (s stubService) AmendEntryLog(ctx context.Context, input service.EntryLogAmendment) (repo.EntryLog, error) {
+	switch {
+	case strings.TrimSpace(input.Reason) == "":
+		return repo.EntryLog{}, service.ErrAmendmentReason
+	case input.Action != "" && input.Action != service.EntryActionEntry && input.Action != service.EntryActionExit && input.Action != service.AmendmentVoid:
+		return repo.EntryLog{}, service.ErrInvalidEntryAction
+	case input.EntryLogID == amendmentLogID:
+		return repo.EntryLog{}, service.ErrAmendmentTarget
+	}
+	return repo.EntryLog{
+		ID:              uuid.New(),
+		GuardUserID:     input.AuthorID,
+		Action:          service.EntryActionAmendment,
+		ActionAt:        time.Now(),
+		Comment:         sql.NullString{String: input.Reason, Valid: true},
+		AmendsID:        uuid.NullUUID{UUID: input.EntryLogID, Valid: true},
+		CorrectedAction: sql.NullString{String: input.Action, Valid: input.Action != ""},
+	}, nil
+}
+
+func (s stubService) VerifyEntryLogChain(ctx context.Context) (service.ChainReport, error) {
+	return service.ChainReport{Checked: 41, HeadSeq: 41, HeadHash: strings.Repeat("a", 64), Break: &service.ChainBreak{Seq: 42, ID: amendmentLogID, Reason: service.ChainBreakHash}}, nil
+}
+
+func (s stubService) ListEntryLogs(ctx context.Context, filter service.EntryLogFilter, limit, offset int32) ([]repo.ListEntryLogsRow, error) {
+	switch filter.Action {
+	case "", service.EntryActionEntry, service.EntryActionExit, service.EntryActionAmendment:
+	default:
+		return nil, service.ErrInvalidEntryAction
+	}
+	return []repo.ListEntryLogsRow{{
+		ID:              uuid.New(),
+		PassID:          uuid.NullUUID{UUID: filter.PassID, Valid: filter.PassID != uuid.Nil},
+		Action:          service.EntryActionEntry,
+		PlateNumber:     "A123BC77",
+		OwnerFullName:   "Resident",
+		OwnerPlotNumber: sql.NullString{String: filter.Plot, Valid: filter.Plot != ""},
+		GuardUserID:     filter.GuardID,
+		GuardFullName:   "Guard",
+		PhotoID:         uuid.NullUUID{UUID: uuid.New(), Valid: filter.PassID != uuid.Nil},
+	}}, nil
+}
+
+func (s stubService) CheckInGuest(ctx context.Context, input service.GuestVisitInput) (repo.EntryLog, error) {
+	if input.GuestID == orphanGuestID {
+		return repo.EntryLog{}, service.ErrResidentInactive
+	}
+	if input.GuestID != approvedGuestID {
+		return repo.EntryLog{}, service.ErrOutsideGuestWindow
+	}
+	if input.GateID == closedGateID {
+		return repo.EntryLog{}, service.ErrGateNotAllowed
+	}
+	return repo.EntryLog{ID: uuid.New(), GuestRequestID: uuid.NullUUID{UUID: input.GuestID, Valid: true}, GuardUserID: input.GuardID, Action: "entry", ActionAt: time.Now(), Comment: input.Comment, GateID: uuid.NullUUID{UUID: input.GateID, Valid: input.GateID != uuid.Nil}}, nil
+}
+
+func (s stubService) CheckOutGuest(ctx context.Context, input service.GuestVisitInput) (repo.EntryLog, error) {
+	if input.GuestID == approvedGuestID {
+		return repo.EntryLog{}, service.ErrGuestTransition
+	}
+	return repo.EntryLog{ID: uuid.New(), GuestRequestID: uuid.NullUUID{UUID: input.GuestID, Valid: true}, GuardUserID: input.GuardID, Action: "exit", ActionAt: time.Now(), Comment: input.Comment}, nil
+}
+
+// closedGateID is a gate the stub refuses movements at and will not
+// delete; every other gate id exists.
+var closedGateID = uuid.MustParse("9a4c2e71-3b8d-4f50-a6e2-7d1c5b9f0e34")
+
+func (s stubService) CreateGate(ctx context.Context, input service.GateInput) (repo.Gate, error) {
+	switch strings.TrimSpace(input.Name) {
+	case "":
+		return repo.Gate{}, service.ErrInvalidInput
+	case "Главные ворота":
+		return repo.Gate{}, service.ErrGateExists
+	}
+	return repo.Gate{ID: uuid.New(), Name: input.Name, Description: sql.NullString{String: input.Description, Valid: input.Description != ""}, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
+}
+
+func (s stubService) GetGate(ctx context.Context, id uuid.UUID) (repo.Gate, error) {
+	return repo.Gate{ID: id, Name: "Главные ворота", Description: sql.NullString{String: "въезд с шоссе", Valid: true}}, nil
+}
+
+func (s stubService) ListGates(ctx context.Context) ([]repo.Gate, error) {
+	return []repo.Gate{{ID: closedGateID, Name: "Главные ворота"}}, nil
+}
+
+func (s stubService) UpdateGate(ctx context.Context, input service.GateInput) (repo.Gate, error) {
+	return repo.Gate{ID: input.ID, Name: input.Name, Description: sql.NullString{String: input.Description, Valid: input.Description != ""}}, nil
+}
+
+func (s stubService) DeleteGate(ctx context.Context, id, actor uuid.UUID) error {
+	if id == closedGateID {
+		return service.ErrGateInUse
+	}
+	return nil
+}
+
+func (s stubService) GuardGates(ctx context.Context, userID uuid.UUID) ([]repo.Gate, error) {
+	return []repo.Gate{}, nil
+}
+
+func (s stubService) SetGuardGates(ctx context.Context, userID uuid.UUID, gateIDs []uuid.UUID) ([]repo.Gate, error) {
+	return stubGates(gateIDs)
+}
+
+func (s stubService) PassGates(ctx context.Context, passID uuid.UUID) ([]repo.Gate, error) {
+	return []repo.Gate{{ID: closedGateID, Name: "Главные ворота"}}, nil
+}
+
+func (s stubService) SetPassGates(ctx context.Context, passID uuid.UUID, gateIDs []uuid.UUID) ([]repo.Gate, error) {
+	return stubGates(gateIDs)
+}
+
+func (s stubService) GuestTypeGates(ctx context.Context) ([]repo.ListAllGuestTypeGatesRow, error) {
+	return []repo.ListAllGuestTypeGatesRow{
+		{GuestType: service.GuestTypeDelivery, GateID: closedGateID, GateName: "Главные ворота"},
+		{GuestType: service.GuestTypeDelivery, GateID: uuid.New(), GateName: "Хозяйственные ворота"},
+		{GuestType: service.GuestTypeTaxi, GateID: closedGateID, GateName: "Главные ворота"},
+	}, nil
+}
+
+func (s stubService) SetGuestTypeGates(ctx context.Context, guestType string, gateIDs []uuid.UUID) ([]repo.Gate, error) {
+	if err := service.ValidateGuestType(guestType); err != nil {
+		return nil, err
+	}
+	return stubGates(gateIDs)
+}
+
+func (s stubService) GateStats(ctx context.Context, from, to time.Time) ([]repo.GateStatsRow, error) {
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, service.ErrInvalidRange
+	}
+	return []repo.GateStatsRow{{ID: closedGateID, Name: "Главные ворота", Entries: 5, Exits: 4, GuestEntries: 2, LastActionAt: sql.NullTime{Time: time.Now(), Valid: true}}}, nil
+}
+
+// endedShiftID is a shift the stub reports as already closed.
+var endedShiftID = uuid.MustParse("4e7b1d93-6a2c-4f85-b0d1-8c3e5a9f2b67")
+
+func (s stubService) StartShift(ctx context.Context, input service.ShiftStartInput) (service.ShiftReport, error) {
+	if input.GateID == closedGateID {
+		return service.ShiftReport{}, service.ErrGateNotAssigned
+	}
+	report := service.ShiftReport{GuardShift: repo.GuardShift{ID: uuid.New(), GuardUserID: input.GuardID, GateID: uuid.NullUUID{UUID: input.GateID, Valid: input.GateID != uuid.Nil}, StartedAt: time.Now()}}
+	report.Handover = &repo.GuardShift{ID: endedShiftID, GuardUserID: uuid.New(), EndedAt: sql.NullTime{Time: time.Now(), Valid: true}, HandoverNote: sql.NullString{String: "ключи от шлагбаума у коменданта", Valid: true}}
+	return report, nil
+}
+
+func (s stubService) EndShift(ctx context.Context, input service.ShiftEndInput) (service.ShiftReport, error) {
+	if input.ShiftID == endedShiftID {
+		return service.ShiftReport{}, service.ErrNoOpenShift
+	}
+	shift := repo.GuardShift{ID: input.ShiftID, GuardUserID: input.GuardID, StartedAt: time.Now().Add(-8 * time.Hour), EndedAt: sql.NullTime{Time: time.Now(), Valid: true}, HandoverNote: sql.NullString{String: input.HandoverNote, Valid: input.HandoverNote != ""}, OnSiteAtEnd: sql.NullInt32{Int32: 3, Valid: true}}
+	return service.ShiftReport{GuardShift: shift, Summary: service.ShiftSummary{Entries: 10, Exits: 8, VehiclesOnSite: 3}}, nil
+}
+
+func (s stubService) CurrentShift(ctx context.Context, guardID uuid.UUID) (service.ShiftReport, error) {
+	return service.ShiftReport{GuardShift: repo.GuardShift{ID: uuid.New(), GuardUserID: guardID, StartedAt: time.Now()}, Summary: service.ShiftSummary{Entries: 2}}, nil
+}
+
+func (s stubService) GetShift(ctx context.Context, id uuid.UUID) (service.ShiftReport, error) {
+	if id == endedShiftID {
+		return service.ShiftReport{GuardShift: repo.GuardShift{ID: id, EndedAt: sql.NullTime{Time: time.Now(), Valid: true}}}, nil
+	}
+	return service.ShiftReport{}, service.ErrNotFound
+}
+
+func (s stubService) ListShifts(ctx context.Context, filter service.ShiftFilter, limit, offset int32) ([]repo.ListGuardShiftsRow, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, service.ErrInvalidRange
+	}
+	return []repo.ListGuardShiftsRow{{ID: endedShiftID, GuardUserID: uuid.New(), GuardFullName: "Охранник", StartedAt: time.Now().Add(-8 * time.Hour), EndedAt: sql.NullTime{Time: time.Now(), Valid: true}, OnSiteAtEnd: sql.NullInt32{Int32: 3, Valid: true}}}, nil
+}
+
+// resolvedIncidentID is an incident the stub reports as resolved, so it can
+// only be reopened.
+var resolvedIncidentID = uuid.MustParse("c3d9a2e4-7b16-4f08-9e5a-1d2b6c8f4a70")
+
+func (s stubService) CreateIncident(ctx context.Context, input service.IncidentInput) (repo.Incident, error) {
+	if err := service.ValidateIncidentCategory(input.Category); err != nil {
+		return repo.Incident{}, err
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		return repo.Incident{}, service.ErrInvalidInput
+	}
+	return repo.Incident{ID: uuid.New(), Category: input.Category, Severity: input.Severity, Status: service.IncidentStatusOpen, Title: input.Title, ReportedBy: input.ReporterID, OccurredAt: time.Now(), PassID: uuid.NullUUID{UUID: input.PassID, Valid: input.PassID != uuid.Nil}}, nil
+}
+
+func (s stubService) GetIncident(ctx context.Context, id uuid.UUID) (service.IncidentDetails, error) {
+	if id == uuid.Nil {
+		return service.IncidentDetails{}, service.ErrNotFound
+	}
+	status := service.IncidentStatusOpen
+	if id == resolvedIncidentID {
+		status = service.IncidentStatusResolved
+	}
+	return service.IncidentDetails{
+		Incident:    repo.Incident{ID: id, Status: status, Category: service.IncidentCategoryBarrierDamage, Severity: service.IncidentSeverityHigh, Title: "Шлагбаум"},
+		Comments:    []repo.ListIncidentCommentsRow{{ID: uuid.New(), IncidentID: id, Body: "Вызвали мастера", StatusFrom: sql.NullString{String: service.IncidentStatusOpen, Valid: true}, StatusTo: sql.NullString{String: status, Valid: true}, AuthorFullName: "Администратор"}},
+		Attachments: []repo.IncidentAttachment{{ID: uuid.New(), IncidentID: id, FileName: "barrier.png", ContentType: "image/png", SizeBytes: 4}},
+	}, nil
+}
+
+func (s stubService) ListIncidents(ctx context.Context, filter service.IncidentFilter, limit, offset int32) ([]repo.ListIncidentsRow, error) {
+	if filter.Status != "" {
+		if err := service.ValidateIncidentStatus(filter.Status); err != nil {
+			return nil, err
+		}
+	}
+	return []repo.ListIncidentsRow{{ID: uuid.New(), Status: service.IncidentStatusOpen, ReporterFullName: "Охранник", Attachments: 2}}, nil
+}
+
+func (s stubService) ChangeIncidentStatus(ctx context.Context, input service.IncidentStatusInput) (repo.Incident, error) {
+	if err := service.ValidateIncidentStatus(input.Status); err != nil {
+		return repo.Incident{}, err
+	}
+	if input.ID == resolvedIncidentID && input.Status != service.IncidentStatusOpen {
+		return repo.Incident{}, service.ErrIncidentTransition
+	}
+	return repo.Incident{ID: input.ID, Status: input.Status}, nil
+}
+
+func (s stubService) AddIncidentComment(ctx context.Context, input service.IncidentCommentInput) (repo.IncidentComment, error) {
+	if strings.TrimSpace(input.Body) == "" {
+		return repo.IncidentComment{}, service.ErrInvalidInput
+	}
+	return repo.IncidentComment{ID: uuid.New(), IncidentID: input.IncidentID, AuthorID: input.AuthorID, Body: input.Body}, nil
+}
+
+func (s stubService) AddIncidentAttachment(ctx context.Context, input service.AttachmentInput) (repo.IncidentAttachment, error) {
+	if len(input.Data) == 0 {
+		return repo.IncidentAttachment{}, service.ErrInvalidInput
+	}
+	if !bytes.HasPrefix(input.Data, []byte("\x89PNG")) {
+		return repo.IncidentAttachment{}, service.ErrAttachmentType
+	}
+	return repo.IncidentAttachment{ID: uuid.New(), IncidentID: input.IncidentID, FileName: input.FileName, ContentType: "image/png", SizeBytes: int64(len(input.Data)), UploadedBy: input.ActorID}, nil
+}
+
+func (s stubService) OpenIncidentAttachment(ctx context.Context, id uuid.UUID) (repo.IncidentAttachment, io.ReadCloser, error) {
+	return repo.IncidentAttachment{ID: id, FileName: "шлагбаум.png", ContentType: "image/png", SizeBytes: 4}, io.NopCloser(strings.NewReader("\x89PNG")), nil
+}
+
+func (s stubService) DeleteIncidentAttachment(ctx context.Context, incidentID, id uuid.UUID) error {
+	return nil
+}
+
+func (s stubService) SignFileLink(id uuid.UUID) service.FileLink {
+	return service.FileLink{Expires: time.Now().Add(time.Minute), Signature: "signed-" + id.String()}
+}
+
+func (s stubService) VerifyFileLink(id uuid.UUID, expires int64, signature string) error {
+	if signature != "signed-"+id.String() {
+		return service.ErrFileLinkInvalid
+	}
+	return nil
+}
+
+func (s stubService) EntryPhoto(ctx context.Context, entryLogID uuid.UUID) (repo.EntryPhoto, error) {
+	return repo.EntryPhoto{ID: uuid.New(), EntryLogID: entryLogID, ContentType: "image/png"}, nil
+}
+
+func (s stubService) OpenEntryPhoto(ctx context.Context, id uuid.UUID, thumbnail bool) (repo.EntryPhoto, io.ReadCloser, error) {
+	content := "\x89PNG"
+	if thumbnail {
+		content = "\xff\xd8\xff"
+	}
+	return repo.EntryPhoto{ID: id, ContentType: "image/png", SizeBytes: int64(len(content))}, io.NopCloser(strings.NewReader(content)), nil
+}
+
+// stubCameraToken is the only device token the stub accepts; reads of
+// resolvedPlateReadID have already been reviewed.
+const stubCameraToken = "camera-token"
+
+var resolvedPlateReadID = uuid.MustParse("7c2e9a14-5b3d-4f68-a1c7-0e8d2b6f4a95")
+
+func (s stubService) CreateCamera(ctx context.Context, input service.CameraInput) (repo.Camera, string, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return repo.Camera{}, "", service.ErrInvalidInput
+	}
+	if input.OperatorID == uuid.Nil {
+		return repo.Camera{}, "", service.ErrCameraOperator
+	}
+	return repo.Camera{ID: uuid.New(), Name: input.Name, Direction: input.Direction, OperatorUserID: input.OperatorID, Active: true}, stubCameraToken, nil
+}
+
+func (s stubService) GetCamera(ctx context.Context, id uuid.UUID) (repo.Camera, error) {
+	return repo.Camera{ID: id, Name: "Въезд", Direction: service.EntryActionEntry, OperatorUserID: uuid.New(), Active: true}, nil
+}
+
+func (s stubService) ListCameras(ctx context.Context) ([]repo.Camera, error) {
+	return []repo.Camera{{ID: uuid.New(), Name: "Въезд", Direction: service.EntryActionEntry, Active: true, LastSeenAt: sql.NullTime{Time: time.Now(), Valid: true}}}, nil
+}
+
+func (s stubService) UpdateCamera(ctx context.Context, input service.CameraInput) (repo.Camera, error) {
+	if input.Direction != service.EntryActionEntry && input.Direction != service.EntryActionExit {
+		return repo.Camera{}, service.ErrInvalidEntryAction
+	}
+	return repo.Camera{ID: input.ID, Name: input.Name, Direction: input.Direction, OperatorUserID: input.OperatorID, Active: input.Active}, nil
+}
+
+func (s stubService) RotateCameraToken(ctx context.Context, id, actor uuid.UUID) (string, error) {
+	return "rotated-token", nil
+}
+
+func (s stubService) DeleteCamera(ctx context.Context, id, actor uuid.UUID) error {
+	return nil
+}
+
+func (s stubService) AuthenticateCamera(ctx context.Context, token string) (repo.Camera, error) {
+	if token != stubCameraToken {
+		return repo.Camera{}, service.ErrInvalidCameraToken
+	}
+	return repo.Camera{ID: uuid.New(), Name: "Въезд", Direction: service.EntryActionEntry, Active: true}, nil
+}
+
+func (s stubService) RecordPlateRead(ctx context.Context, camera repo.Camera, input service.PlateReadInput) (repo.PlateRead, error) {
+	if input.Confidence < 0 || input.Confidence > 1 {
+		return repo.PlateRead{}, service.ErrInvalidConfidence
+	}
+	read := repo.PlateRead{ID: uuid.New(), CameraID: camera.ID, PlateRaw: input.Plate, PlateNumber: service.NormalizePlate(input.Plate), Confidence: input.Confidence, Decision: service.PlateDecisionDeny, Reason: "no active pass or guest request"}
+	switch {
+	case input.Confidence < service.DefaultMinPlateConfidence:
+		read.Decision, read.Reason = service.PlateDecisionReview, "low confidence"
+		read.ReviewStatus = sql.NullString{String: service.PlateReviewPending, Valid: true}
+	case read.PlateNumber == "A123BC77":
+		read.Decision, read.Reason = service.PlateDecisionAllow, "active pass"
+		read.PassID = uuid.NullUUID{UUID: ownedPassID, Valid: true}
+		read.EntryLogID = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	}
+	return read, nil
+}
+
+func (s stubService) ListPlateReads(ctx context.Context, filter service.PlateReadFilter, limit, offset int32) ([]repo.ListPlateReadsRow, error) {
+	if filter.ReviewStatus != "" && filter.ReviewStatus != service.PlateReviewPending {
+		return nil, service.ErrInvalidInput
+	}
+	return []repo.ListPlateReadsRow{{ID: uuid.New(), PlateNumber: "A123BC77", Decision: service.PlateDecisionReview, ReviewStatus: sql.NullString{String: service.PlateReviewPending, Valid: true}, CameraName: "Въезд", Direction: service.EntryActionEntry, GateName: sql.NullString{String: "Главные ворота", Valid: true}}}, nil
+}
+
+func (s stubService) ReviewPlateRead(ctx context.Context, input service.PlateReviewInput) (repo.PlateRead, error) {
+	if input.ID == resolvedPlateReadID {
+		return repo.PlateRead{}, service.ErrPlateReadResolved
+	}
+	if input.Allow && input.PassID == uuid.Nil && input.GuestID == uuid.Nil {
+		return repo.PlateRead{}, service.ErrPlateReadTarget
+	}
+	read := repo.PlateRead{ID: input.ID, Decision: service.PlateDecisionReview, ReviewStatus: sql.NullString{String: service.PlateReviewDenied, Valid: true}, ReviewedBy: uuid.NullUUID{UUID: input.GuardID, Valid: true}}
+	if input.Allow {
+		read.ReviewStatus.String = service.PlateReviewAllowed
+		read.EntryLogID = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	}
+	return read, nil
+}
+
+// barrierGateID has a working barrier controller and brokenBarrierGateID
+// one that never answers; other gates have none.
+var (
+	barrierGateID       = uuid.MustParse("3f6b1c2d-8e4a-4b7f-9c15-d2a0e6f81b34")
+	brokenBarrierGateID = uuid.MustParse("a94e07b5-2c61-4d8e-b3f0-5e7c1a9d2f68")
+)
+
+func (s stubService) GetGateBarrier(ctx context.Context, gateID uuid.UUID) (repo.GateBarrier, error) {
+	if gateID != barrierGateID && gateID != brokenBarrierGateID {
+		return repo.GateBarrier{}, service.ErrBarrierNotConfigured
+	}
+	return repo.GateBarrier{GateID: gateID, Driver: "http", Address: sql.NullString{String: "http://relay.local", Valid: true}, Secret: sql.NullString{String: "s3cret", Valid: true}, AutoOpen: true}, nil
+}
+
+func (s stubService) SetGateBarrier(ctx context.Context, input service.GateBarrierInput) (repo.GateBarrier, error) {
+	if input.Driver != "http" && input.Driver != "mqtt" && input.Driver != "noop" {
+		return repo.GateBarrier{}, service.ErrBarrierConfig
+	}
+	return repo.GateBarrier{GateID: input.GateID, Driver: input.Driver, Address: sql.NullString{String: input.Address, Valid: input.Address != ""}, Secret: sql.NullString{String: derefString(input.Secret), Valid: derefString(input.Secret) != ""}, AutoOpen: input.AutoOpen, HoldSeconds: input.HoldSeconds}, nil
+}
+
+func (s stubService) DeleteGateBarrier(ctx context.Context, gateID uuid.UUID) error {
+	_, err := s.GetGateBarrier(ctx, gateID)
+	return err
+}
+
+func (s stubService) OpenBarrier(ctx context.Context, input service.BarrierCommandInput) (repo.BarrierEvent, error) {
+	if input.GateID == closedGateID {
+		return repo.BarrierEvent{}, service.ErrGateNotAssigned
+	}
+	if _, err := s.GetGateBarrier(ctx, input.GateID); err != nil {
+		return repo.BarrierEvent{}, err
+	}
+	event := stubBarrierEvent(input.GateID, uuid.Nil)
+	if input.Hold > 0 {
+		event.Command = service.BarrierCommandHold
+	}
+	if !event.Success {
+		return event, service.ErrBarrierFailed
+	}
+	return event, nil
+}
+
+func (s stubService) BarrierStatus(ctx context.Context, gateID, actor uuid.UUID) (barrier.Status, error) {
+	if _, err := s.GetGateBarrier(ctx, gateID); err != nil {
+		return barrier.Status{}, err
+	}
+	if gateID == brokenBarrierGateID {
+		return barrier.Status{}, service.ErrBarrierFailed
+	}
+	return barrier.Status{State: barrier.StateClosed}, nil
+}
+
+func (s stubService) TriggerBarrier(ctx context.Context, entry repo.EntryLog) (*repo.BarrierEvent, error) {
+	if entry.Action != service.EntryActionEntry || (entry.GateID.UUID != barrierGateID && entry.GateID.UUID != brokenBarrierGateID) {
+		return nil, nil
+	}
+	event := stubBarrierEvent(entry.GateID.UUID, entry.ID)
+	return &event, nil
+}
+
+func (s stubService) ListBarrierEvents(ctx context.Context, gateID uuid.UUID, failedOnly bool, limit, offset int32) ([]repo.BarrierEvent, error) {
+	events := []repo.BarrierEvent{stubBarrierEvent(brokenBarrierGateID, uuid.Nil)}
+	if !failedOnly {
+		events = append(events, stubBarrierEvent(barrierGateID, uuid.New()))
+	}
+	return events, nil
+}
+
+func stubBarrierEvent(gateID, entryLogID uuid.UUID) repo.BarrierEvent {
+	event := repo.BarrierEvent{ID: uuid.New(), GateID: gateID, Command: service.BarrierCommandOpen, Driver: "http", EntryLogID: uuid.NullUUID{UUID: entryLogID, Valid: entryLogID != uuid.Nil}, Success: true, State: sql.NullString{String: barrier.StateOpen, Valid: true}, CreatedAt: time.Now()}
+	if gateID == brokenBarrierGateID {
+		event.Success, event.State = false, sql.NullString{}
+		event.Error = sql.NullString{String: "relay request: timeout", Valid: true}
+	}
+	return event
+}
+
+func (s stubService) SyncEntryLogs(ctx context.Context, guardID uuid.UUID, items []service.SyncEntryInput) ([]service.SyncResult, error) {
+	if len(items) == 0 || len(items) > service.MaxSyncBatch {
+		return nil, service.ErrSyncBatchSize
+	}
+	results := make([]service.SyncResult, 0, len(items))
+	for _, item := range items {
+		result := service.SyncResult{ID: item.ID, Status: service.SyncStatusCreated}
+		switch {
+		case item.ID == uuid.Nil:
+			result.Status, result.Error = service.SyncStatusInvalid, "id is required"
+		case item.PassID != ownedPassID:
+			result.Status, result.Conflict = service.SyncStatusConflict, service.SyncConflictPassInactive
+		default:
+			result.Entry = &repo.EntryLog{ID: item.ID, PassID: uuid.NullUUID{UUID: item.PassID, Valid: true}, GuardUserID: guardID, Action: item.Action, ActionAt: time.Now(), DeviceAt: sql.NullTime{Time: item.DeviceAt, Valid: true}}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s stubService) SyncDelta(ctx context.Context, since time.Time) (service.SyncSnapshot, error) {
+	if since.After(time.Now()) {
+		return service.SyncSnapshot{}, service.ErrInvalidRange
+	}
+	return service.SyncSnapshot{
+		Full:   since.IsZero(),
+		Cursor: time.Now().Add(-time.Minute),
+		Passes: []repo.ListSyncPassesRow{{ID: ownedPassID, PlateNumber: "A123BC77", Active: true, UpdatedAt: time.Now()}},
+	}, nil
+}
+
+var stubPlotID = uuid.MustParse("5d3c1a8e-2f4b-4c6d-9e7f-1a2b3c4d5e6f")
+
+func (s stubService) CreatePlot(ctx context.Context, input service.PlotInput) (repo.Plot, error) {
+	if err := service.ValidatePlotNumber(input.Number); err != nil {
+		return repo.Plot{}, err
+	}
+	number := service.NormalizePlotNumber(input.Number)
+	if number == "12A" {
+		return repo.Plot{}, service.ErrPlotExists
+	}
+	return repo.Plot{ID: uuid.New(), Number: number, Status: service.PlotStatusActive, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
+}
+
+func (s stubService) GetPlot(ctx context.Context, id uuid.UUID) (repo.Plot, error) {
+	if id != stubPlotID {
+		return repo.Plot{}, service.ErrNotFound
+	}
+	return repo.Plot{ID: id, Number: "12A", Status: service.PlotStatusActive}, nil
+}
+
+func (s stubService) ListPlots(ctx context.Context, filter service.PlotFilter) ([]repo.Plot, error) {
+	if filter.Status != "" && filter.Status != service.PlotStatusActive && filter.Status != service.PlotStatusArchived {
+		return nil, service.ErrInvalidPlotStatus
+	}
+	return []repo.Plot{{ID: stubPlotID, Number: "12A", Status: service.PlotStatusActive}}, nil
+}
+
+func (s stubService) UpdatePlot(ctx context.Context, input service.PlotInput) (repo.Plot, error) {
+	if err := service.ValidatePlotNumber(input.Number); err != nil {
+		return repo.Plot{}, err
+	}
+	return repo.Plot{ID: input.ID, Number: service.NormalizePlotNumber(input.Number), Status: input.Status}, nil
+}
+
+func (s stubService) ListPlotResidents(ctx context.Context, plotID uuid.UUID) ([]repo.ListPlotResidentsRow, error) {
+	return []repo.ListPlotResidentsRow{{UserID: uuid.New(), FullName: "Resident", Role: "resident", Relation: service.PlotRelationOwner}}, nil
+}
+
+func (s stubService) ListUserPlots(ctx context.Context, userID uuid.UUID) ([]repo.ListUserPlotsRow, error) {
+	return []repo.ListUserPlotsRow{{ID: stubPlotID, Number: "12A", Status: service.PlotStatusActive, Relation: service.PlotRelationTenant}}, nil
+}
+
+func (s stubService) SetPlotResident(ctx context.Context, input service.PlotResidentInput) (repo.PlotResident, error) {
+	if input.PlotID != stubPlotID {
+		return repo.PlotResident{}, service.ErrNotFound
+	}
+	if input.Relation != service.PlotRelationOwner && input.Relation != service.PlotRelationFamily && input.Relation != service.PlotRelationTenant {
+		return repo.PlotResident{}, service.ErrInvalidRelation
+	}
+	return repo.PlotResident{PlotID: input.PlotID, UserID: input.UserID, Relation: input.Relation}, nil
+}
+
+func (s stubService) RemovePlotResident(ctx context.Context, plotID, userID uuid.UUID) error {
+	if plotID != stubPlotID {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (s stubService) ListPlotPasses(ctx context.Context, plotID uuid.UUID, limit, offset int32) ([]repo.Pass, error) {
+	return []repo.Pass{{ID: ownedPassID, PlotID: uuid.NullUUID{UUID: plotID, Valid: true}, PlateNumber: "A123BC77", Status: "active"}}, nil
+}
+
+func (s stubService) ListPlotGuestRequests(ctx context.Context, plotID uuid.UUID, limit, offset int32) ([]repo.GuestRequest, error) {
+	return []repo.GuestRequest{}, nil
+}
+
+func stubGates(gateIDs []uuid.UUID) ([]repo.Gate, error) {
+	gates := make([]repo.Gate, 0, len(gateIDs))
+	for _, id := range gateIDs {
+		if id == uuid.Nil {
+			return nil, service.ErrUnknownGate
+		}
+		gates = append(gates, repo.Gate{ID: id, Name: "Ворота"})
+	}
+	return gates, nil
+}
+
+func (s stubService) CreatePassSchedule(ctx context.Context, input service.PassScheduleInput) (repo.PassSchedule, error) {
+	return repo.PassSchedule{ID: uuid.New(), PassID: input.PassID, Weekdays: 31, StartMinute: 480, EndMinute: 1200, SkipHolidays: input.SkipHolidays}, nil
+}
+
+func (s stubService) ListPassSchedules(ctx context.Context, passID uuid.UUID) ([]repo.PassSchedule, error) {
+	return []repo.PassSchedule{}, nil
+}
+
+func (s stubService) DeletePassSchedule(ctx context.Context, passID, scheduleID uuid.UUID) error {
+	return nil
+}
+
+func (s stubService) CheckPassSchedule(ctx context.Context, passID uuid.UUID) (service.ScheduleStatus, error) {
+	return service.ScheduleStatus{Within: true, CheckedAt: time.Now()}, nil
+}
+
+func (s stubService) CreateHoliday(ctx context.Context, input service.HolidayInput) (repo.Holiday, error) {
+	return repo.Holiday{Day: time.Now().UTC().Truncate(24 * time.Hour), Name: input.Name}, nil
+}
+
+func (s stubService) ListHolidays(ctx context.Context, from, to time.Time) ([]repo.Holiday, error) {
+	return []repo.Holiday{}, nil
+}
+
+func (s stubService) DeleteHoliday(ctx context.Context, day time.Time) error {
+	return nil
+}
+
+func (s stubService) ImportUsers(ctx context.Context, rows []service.ImportRow, opts service.ImportOptions) (service.ImportReport, error) {
+	if opts.Restore {
+		return service.ImportReport{DryRun: opts.DryRun, Applied: !opts.DryRun, Rows: len(rows), UsersRestored: len(rows)}, nil
+	}
+	return service.ImportReport{DryRun: opts.DryRun, Applied: !opts.DryRun, Rows: len(rows), UsersCreated: len(rows)}, nil
+}
+
+func (s stubService) Location() *time.Location {
+	return time.UTC
+}
+
+func (s stubService) ExportUsers(ctx context.Context, filter service.ExportFilter, fn func(repo.User) error) error {
+	return fn(repo.User{ID: uuid.New(), Email: "a@example.com", FullName: "Иванов; Иван", Role: "resident", CreatedAt: time.Now()})
+}
+
+func (s stubService) ExportPasses(ctx context.Context, filter service.ExportFilter, fn func(repo.ExportPassesRow) error) error {
+	return fn(repo.ExportPassesRow{
+		ID:              uuid.New(),
+		OwnerUserID:     filter.OwnerID,
+		PlateNumber:     "A123BC77",
+		Status:          "active",
+		OwnerFullName:   "Owner",
+		OwnerPlotNumber: sql.NullString{String: "12", Valid: true},
+		CreatedAt:       time.Now(),
+	})
+}
+
+func (s stubService) ExportGuestRequests(ctx context.Context, filter service.ExportFilter, fn func(repo.ExportGuestRequestsRow) error) error {
+	return nil
+}
+
+func (s stubService) ExportEntryLogs(ctx context.Context, filter service.EntryLogFilter, fn func(repo.ExportEntryLogsRow) error) error {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return service.ErrInvalidRange
+	}
+	action := service.EntryActionEntry
+	switch filter.Action {
+	case "":
+	case service.EntryActionEntry, service.EntryActionExit, service.EntryActionAmendment:
+		action = filter.Action
+	default:
+		return service.ErrInvalidEntryAction
+	}
+	return fn(repo.ExportEntryLogsRow{ID: uuid.New(), Action: action, ActionAt: time.Now(), PlateNumber: "A123BC77", GuardFullName: "Guard",
+		OwnerPlotNumber: sql.NullString{String: filter.Plot, Valid: filter.Plot != ""}})
+}
+
+var blacklistedPassID = uuid.MustParse("6f1c2b9e-0d3a-4c1e-9a57-2b8d0f4e7a11")
+
+func (s stubService) CreateWatchlistEntry(ctx context.Context, input service.WatchlistInput) (repo.PlateWatchlist, error) {
+	if input.Severity == "critical" {
+		return repo.PlateWatchlist{}, service.ErrInvalidSeverity
+	}
+	if input.PlateNumber == "M777MM77" {
+		return repo.PlateWatchlist{}, service.ErrWatchlistExists
+	}
+	return repo.PlateWatchlist{ID: uuid.New(), PlateNumber: input.PlateNumber, Severity: input.Severity, Reason: input.Reason, ExpiresAt: input.ExpiresAt}, nil
+}
+
+func (s stubService) GetWatchlistEntry(ctx context.Context, id uuid.UUID) (repo.PlateWatchlist, error) {
+	return repo.PlateWatchlist{
+		ID:          id,
+		PlateNumber: "A123BC77",
+		Severity:    service.SeverityWarning,
+		Reason:      "check",
+		ExpiresAt:   sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}, nil
+}
+
+func (s stubService) ListWatchlist(ctx context.Context, includeExpired bool, limit, offset int32) ([]repo.PlateWatchlist, error) {
+	return []repo.PlateWatchlist{}, nil
+}
+
+func (s stubService) UpdateWatchlistEntry(ctx context.Context, input service.WatchlistInput) (repo.PlateWatchlist, error) {
+	return repo.PlateWatchlist{ID: input.ID, PlateNumber: input.PlateNumber, Severity: input.Severity, Reason: input.Reason, ExpiresAt: input.ExpiresAt}, nil
+}
+
+func (s stubService) DeleteWatchlistEntry(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
+	return nil
+}
+
+func (s stubService) ListWatchlistHits(ctx context.Context, id uuid.UUID, limit, offset int32) ([]repo.WatchlistHit, error) {
+	return []repo.WatchlistHit{{ID: uuid.New(), WatchlistID: id, Source: service.WatchlistSourceEntry, Outcome: service.WatchlistBlocked}}, nil
+}
+
+func (s stubService) FlagPasses(ctx context.Context, passes []repo.Pass, userID uuid.UUID) (map[uuid.UUID]repo.PlateWatchlist, error) {
+	return map[uuid.UUID]repo.PlateWatchlist{}, nil
+}
+
+func (s stubService) CheckGate(ctx context.Context, input service.GateCheckInput) (*service.WatchlistHit, error) {
+	if input.PassID != blacklistedPassID {
+		return nil, nil
+	}
+	hit := &service.WatchlistHit{
+		Entry: repo.PlateWatchlist{ID: uuid.New(), PlateNumber: input.PlateNumber, Severity: service.SeverityBlacklist, Reason: "banned"},
+		Check: input,
+	}
+	switch {
+	case input.Source != service.WatchlistSourceEntry:
+		hit.Outcome = service.WatchlistFlagged
+		return hit, nil
+	case input.Override && input.Comment == "":
+		return hit, service.ErrOverrideComment
+	case input.Override:
+		hit.Outcome = service.WatchlistOverridden
+		return hit, nil
+	default:
+		hit.Outcome = service.WatchlistBlocked
+		return hit, service.ErrPlateBlacklisted
+	}
+}
+
+func newAuthToken(role auth.Role) string {
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	access, _, _ := manager.GenerateTokens(uuid.New(), role)
+	return access
+}
+
+func setupRouter() http.Handler {
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	return NewRouter(&Handler{Auth: manager, Service: stubService{}})
+}
+
+func TestGuardCannotCreatePass(t *testing.T) {
+	router := setupRouter()
+	payload := map[string]string{"plate_number": "A123BC77"}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/passes", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.Code)
+	}
+}
+
+func TestAdminCanCreateUser(t *testing.T) {
+	router := setupRouter()
+	payload := map[string]string{"email": "user@example.com", "password": "secret", "role": "resident", "full_name": "User", "plot_number": "12A"}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.Code)
+	}
+}
+
+func TestResidentCannotAccessUsers(t *testing.T) {
+	router := setupRouter()
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleResident))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.Code)
+	}
+}
+
+func TestAdminCanBlockUser(t *testing.T) {
+	router := setupRouter()
+	req := httptest.NewRequest(http.MethodPost, "/users/"+uuid.New().String()+"/block", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	var got UserStateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Status != "blocked" || len(got.Passes) != 1 || got.GuestRequests == nil {
+		t.Fatalf("unexpected block report: %+v", got)
+	}
+}
+
+func TestUserStateRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	send := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	user := "/users/" + uuid.NewString()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{http.MethodDelete, user, admin, http.StatusOK},
+		{http.MethodDelete, "/users/" + uuid.Nil.String(), admin, http.StatusNotFound},
+		{http.MethodDelete, "/users/bad", admin, http.StatusBadRequest},
+		{http.MethodDelete, user, newAuthToken(auth.RoleGuard), http.StatusForbidden},
+		{http.MethodPost, user + "/restore", admin, http.StatusOK},
+		{http.MethodPost, user + "/unblock", admin, http.StatusOK},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token); resp.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, resp.Code)
+		}
+	}
+
+	var got UserStateResponse
+	if err := json.NewDecoder(send(http.MethodDelete, user, admin).Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Status != "deleted" || len(got.Passes) != 1 || len(got.GuestRequests) != 1 {
+		t.Fatalf("unexpected delete report: %+v", got)
+	}
+}
+
+func TestResidentCanCreatePass(t *testing.T) {
+	router := setupRouter()
+	payload := map[string]string{"plate_number": "A123BC77"}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/passes", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleResident))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.Code)
+	}
+}
+
+func TestGuardCanSearchPasses(t *testing.T) {
+	router := setupRouter()
+	req := httptest.NewRequest(http.MethodGet, "/passes/search?plate=A123BC77", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+}
+
+func TestUpdatePassRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	owner, _, _ := manager.GenerateTokens(guestOwnerID, auth.RoleResident)
+	send := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	own := "/passes/" + ownedPassID.String()
+	cases := []struct {
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{own, owner, `{"vehicle_brand":"Lada"}`, http.StatusOK},
+		{own, owner, `{bad`, http.StatusBadRequest},
+		{"/passes/" + uuid.NewString(), owner, `{}`, http.StatusForbidden},
+		{own, newAuthToken(auth.RoleGuard), `{}`, http.StatusForbidden},
+		{"/passes/bad", admin, `{}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("PATCH %s %s: expected %d, got %d", tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	// Only admins change the status; a resident's update keeps the current one.
+	var got PassResponse
+	if err := json.NewDecoder(send(own, owner, `{"status":"blocked"}`).Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Status != "active" {
+		t.Fatalf("expected the current status, got %q", got.Status)
+	}
+	if err := json.NewDecoder(send(own, admin, `{"status":"blocked"}`).Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Status != "blocked" {
+		t.Fatalf("expected the admin status, got %q", got.Status)
+	}
+}
+
+func TestGuardCannotCreatePassSchedule(t *testing.T) {
+	router := setupRouter()
+	payload := map[string]any{"weekdays": []int{1, 2, 3}, "start": "08:00", "end": "20:00"}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/passes/"+uuid.New().String()+"/schedules", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.Code)
+	}
+}
+
+func TestAdminCanCreatePassSchedule(t *testing.T) {
+	router := setupRouter()
+	payload := map[string]any{"weekdays": []int{1, 2, 3, 4, 5}, "start": "08:00", "end": "20:00"}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/passes/"+uuid.New().String()+"/schedules", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.Code)
+	}
+	var schedule PassScheduleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&schedule); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if schedule.Start != "08:00" || schedule.End != "20:00" || len(schedule.Weekdays) != 5 || !schedule.SkipHolidays {
+		t.Fatalf("unexpected schedule: %+v", schedule)
+	}
+}
+
+func TestEntryReportsAccessWindow(t *testing.T) {
+	router := setupRouter()
+	req := httptest.NewRequest(http.MethodPost, "/passes/"+uuid.New().String()+"/entry", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.Code)
+	}
+	var entry EntryLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if entry.AccessWindow == nil || !entry.AccessWindow.Within {
+		t.Fatalf("expected access window in response, got %+v", entry)
+	}
+}
+
+func TestResidentCannotCreateHoliday(t *testing.T) {
+	router := setupRouter()
+	payload := map[string]string{"day": "2027-01-01", "name": "Новый год"}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/holidays", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleResident))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.Code)
+	}
+}
+
+func TestGuardCanListHolidays(t *testing.T) {
+	router := setupRouter()
+	req := httptest.NewRequest(http.MethodGet, "/holidays?from=2027-01-01&to=2027-01-31", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+}
+
+func TestAdminCanImportUsers(t *testing.T) {
+	router := setupRouter()
+	csvData := "email,full_name,plot_number,password,plate_number\nnew@example.com,New,12,secret,A123BC77\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/users/import?dry_run=true", bytes.NewBufferString(csvData))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	req.Header.Set("Content-Type", "text/csv")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	var report ImportReportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !report.DryRun || report.Applied || report.Rows != 1 || report.Errors == nil {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users/import?dry_run=true&restore=true", bytes.NewBufferString(csvData))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != http.StatusOK || report.UsersRestored != 1 || report.UsersCreated != 0 {
+		t.Fatalf("unexpected restore report: %d %+v", resp.Code, report)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "users.csv")
+	_, _ = part.Write([]byte(csvData))
+	_ = form.Close()
+
+	req = httptest.NewRequest(http.MethodPost, "/users/import", &body)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp = httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+}
+
+func TestImportUsersRejectsBadFiles(t *testing.T) {
+	router := setupRouter()
+	cases := map[string]string{
+		"empty":     "",
+		"no header": "name,plate\nNew,A123BC77\n",
+		"workbook":  "PK\x03\x04broken",
+	}
+	for name, payload := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewBufferString(payload))
+		req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", name, resp.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewBufferString("email\na@example.com\n"))
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.Code)
+	}
+}
+
+func TestAdminCanExportUsers(t *testing.T) {
+	router := setupRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/users/export", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	if !strings.HasPrefix(resp.Header().Get("Content-Disposition"), `attachment; filename="users-`) {
+		t.Fatalf("unexpected disposition: %s", resp.Header().Get("Content-Disposition"))
+	}
+	rows, err := tabular.Read(resp.Body.Bytes())
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 2 || rows[0][2] != "ФИО" || rows[1][2] != "Иванов; Иван" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/export?format=xlsx", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != tabular.ContentType(tabular.FormatXLSX) {
+		t.Fatalf("unexpected xlsx response: %d %s", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	rows, err = tabular.Read(resp.Body.Bytes())
+	if err != nil {
+		t.Fatalf("read xlsx: %v", err)
+	}
+	if len(rows) != 2 || rows[0][2] != "Full name" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestExportRespectsRoles(t *testing.T) {
+	router := setupRouter()
+	residentID := uuid.New()
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	residentToken, _, _ := manager.GenerateTokens(residentID, auth.RoleResident)
+
+	cases := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/users/export", residentToken, http.StatusForbidden},
+		{"/passes/export", newAuthToken(auth.RoleGuard), http.StatusForbidden},
+		{"/guest-requests/export", newAuthToken(auth.RoleGuard), http.StatusForbidden},
+		{"/guest-requests/export", residentToken, http.StatusOK},
+		{"/entry-logs/export", newAuthToken(auth.RoleGuard), http.StatusOK},
+		{"/entry-logs/export?lang=en", residentToken, http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.path, tc.status, resp.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/passes/export?lang=en", nil)
+	req.Header.Set("Authorization", "Bearer "+residentToken)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	rows, err := tabular.Read(resp.Body.Bytes())
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 2 || rows[0][1] != "Plate number" || rows[1][7] != "12" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+
+	// The journal export takes the same filters as GET /entry-logs.
+	req = httptest.NewRequest(http.MethodGet, "/entry-logs/export?action=exit&plot=7&plate=a12", nil)
+	req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleGuard))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	if rows, err = tabular.Read(resp.Body.Bytes()); err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 2 || rows[1][2] != service.EntryActionExit || rows[1][6] != "7" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestExportRejectsBadParams(t *testing.T) {
+	router := setupRouter()
+	paths := []string{
+		"/users/export?format=pdf",
+		"/entry-logs/export?from=yesterday",
+		"/entry-logs/export?to=2025-13-01",
+		"/entry-logs/export?from=2025-03-02&to=2025-03-01",
+		"/entry-logs/export?action=checkin",
+		"/entry-logs/export?guard_id=nobody",
+	}
+	for _, path := range paths {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+newAuthToken(auth.RoleAdmin))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, resp.Code)
+		}
+	}
+}
+
+func TestWatchlistRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/watchlist", admin, `{"plate_number":"A123BC77","severity":"blacklist","reason":"stolen"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.Code)
+	}
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/watchlist", newAuthToken(auth.RoleGuard), `{}`, http.StatusForbidden},
+		{http.MethodPost, "/watchlist", admin, `{bad`, http.StatusBadRequest},
+		{http.MethodPost, "/watchlist", admin, `{"plate_number":"A123BC77","severity":"critical","reason":"x"}`, http.StatusBadRequest},
+		{http.MethodPost, "/watchlist", admin, `{"plate_number":"M777MM77","severity":"info","reason":"x"}`, http.StatusConflict},
+		{http.MethodGet, "/watchlist?includeExpired=true", admin, "", http.StatusOK},
+		{http.MethodGet, "/watchlist/" + uuid.New().String(), admin, "", http.StatusOK},
+		{http.MethodGet, "/watchlist/bad", admin, "", http.StatusBadRequest},
+		{http.MethodPatch, "/watchlist/bad", admin, `{}`, http.StatusBadRequest},
+		{http.MethodPatch, "/watchlist/" + uuid.New().String(), admin, `{bad`, http.StatusBadRequest},
+		{http.MethodDelete, "/watchlist/" + uuid.New().String(), admin, "", http.StatusNoContent},
+		{http.MethodDelete, "/watchlist/bad", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/watchlist/" + uuid.New().String() + "/hits", admin, "", http.StatusOK},
+		{http.MethodGet, "/watchlist/bad/hits", admin, "", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		resp := send(tc.method, tc.path, tc.token, tc.body)
+		if resp.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, resp.Code)
+		}
+	}
+
+	resp = send(http.MethodPatch, "/watchlist/"+uuid.New().String(), admin, `{"severity":"blacklist","reason":"banned","expires_at":null}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	var updated WatchlistResponse
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if updated.Severity != "blacklist" || updated.ExpiresAt != nil || updated.PlateNumber != "A123BC77" {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+}
+
+func TestEntryBlockedByWatchlist(t *testing.T) {
+	router := setupRouter()
+	path := "/passes/" + blacklistedPassID.String()
+	send := func(action string, role auth.Role, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path+"/"+action, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+newAuthToken(role))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send("entry", auth.RoleGuard, "")
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.Code)
+	}
+	var blocked WatchlistBlockedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&blocked); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if blocked.Watchlist.Severity != "blacklist" {
+		t.Fatalf("unexpected body: %+v", blocked)
+	}
+
+	if resp := send("entry", auth.RoleGuard, `{"override":true,"comment":"ok"}`); resp.Code != http.StatusForbidden {
+		t.Fatalf("guard override: expected 403, got %d", resp.Code)
+	}
+	if resp := send("entry", auth.RoleAdmin, `{"override":true}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("override without comment: expected 400, got %d", resp.Code)
+	}
+	resp = send("entry", auth.RoleAdmin, `{"override":true,"comment":"police escort"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("override: expected 201, got %d", resp.Code)
+	}
+	var entry EntryLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if entry.Watchlist == nil {
+		t.Fatalf("expected watchlist flag, got %+v", entry)
+	}
+	if resp := send("exit", auth.RoleGuard, ""); resp.Code != http.StatusCreated {
+		t.Fatalf("exit: expected 201, got %d", resp.Code)
+	}
+}
+
+func TestGuestReviewWorkflow(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	owner, _, _ := manager.GenerateTokens(guestOwnerID, auth.RoleResident)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	pending := "/guest-requests/" + uuid.New().String()
+	approved := "/guest-requests/" + approvedGuestID.String()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodPost, pending + "/approve", admin, "", http.StatusOK},
+		{http.MethodPost, pending + "/approve", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodPost, pending + "/approve", newAuthToken(auth.RoleGuard), "", http.StatusForbidden},
+		{http.MethodPost, approved + "/approve", admin, "", http.StatusConflict},
+		{http.MethodPost, "/guest-requests/bad/approve", admin, "", http.StatusBadRequest},
+		{http.MethodPost, pending + "/reject", admin, `{"reason":"unknown visitor"}`, http.StatusOK},
+		{http.MethodPost, pending + "/reject", admin, `{}`, http.StatusBadRequest},
+		{http.MethodPost, pending + "/reject", admin, `{bad`, http.StatusBadRequest},
+		{http.MethodPost, approved + "/cancel", owner, "", http.StatusOK},
+		{http.MethodPost, approved + "/cancel", admin, "", http.StatusOK},
+		{http.MethodPost, approved + "/cancel", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodPost, approved + "/cancel", newAuthToken(auth.RoleGuard), "", http.StatusForbidden},
+		{http.MethodPost, "/guest-requests/bad/cancel", owner, "", http.StatusBadRequest},
+		{http.MethodPatch, approved, owner, `{"guest_full_name":"Other"}`, http.StatusConflict},
+		{http.MethodPatch, approved, admin, `{"guest_full_name":"Other"}`, http.StatusOK},
+		{http.MethodPatch, approved, admin, `{"status":"completed"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		resp := send(tc.method, tc.path, tc.token, tc.body)
+		if resp.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPost, pending+"/reject", admin, `{"reason":"no parking"}`)
+	var rejected GuestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rejected); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rejected.Status != service.GuestStatusRejected || rejected.ReviewReason == nil || *rejected.ReviewReason != "no parking" {
+		t.Fatalf("unexpected rejection: %+v", rejected)
+	}
+}
+
+func TestGuardSeesExpectedGuests(t *testing.T) {
+	router := setupRouter()
+	guard := newAuthToken(auth.RoleGuard)
+	cases := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/guest-requests/expected", guard, http.StatusOK},
+		{"/guest-requests/expected?hours=6", newAuthToken(auth.RoleAdmin), http.StatusOK},
+		{"/guest-requests/expected", newAuthToken(auth.RoleResident), http.StatusForbidden},
+		{"/guest-requests/expected?hours=abc", guard, http.StatusBadRequest},
+		{"/guest-requests/expected?hours=48", guard, http.StatusBadRequest},
+		{"/guest-requests/expected?type=bogus", guard, http.StatusBadRequest},
+		{"/guest-requests/search?plate=A123BC77", guard, http.StatusOK},
+		{"/guest-requests/search", guard, http.StatusBadRequest},
+		{"/guest-requests/search?plate=bad", guard, http.StatusBadRequest},
+		{"/guest-requests/search?plate=A123BC77", newAuthToken(auth.RoleResident), http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.path, tc.status, resp.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/guest-requests/expected", nil)
+	req.Header.Set("Authorization", "Bearer "+guard)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	var guests []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&guests); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(guests) != 1 || guests[0]["resident_full_name"] != "Resident" || guests[0]["resident_plot_number"] != "12" {
+		t.Fatalf("unexpected guests: %+v", guests)
+	}
+	if _, leaked := guests[0]["resident_user_id"]; leaked {
+		t.Fatalf("guard view must not expose resident id: %+v", guests[0])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/guest-requests/expected?type=delivery", nil)
+	req.Header.Set("Authorization", "Bearer "+guard)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	var deliveries []GateGuestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&deliveries); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].GuestType != "delivery" || deliveries[0].CompanyName == nil || *deliveries[0].CompanyName != "Fast Food" {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+}
+
+func TestGuestCheckInAndOut(t *testing.T) {
+	router := setupRouter()
+	guard := newAuthToken(auth.RoleGuard)
+	approved := "/guest-requests/" + approvedGuestID.String()
+	other := "/guest-requests/" + uuid.New().String()
+	send := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	cases := []struct {
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{approved + "/check-in", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{"/guest-requests/bad/check-in", guard, "", http.StatusBadRequest},
+		{approved + "/check-in", guard, "{", http.StatusBadRequest},
+		{approved + "/check-in", guard, `{"override":true,"comment":"x"}`, http.StatusForbidden},
+		{other + "/check-in", guard, "", http.StatusForbidden},
+		{"/guest-requests/" + orphanGuestID.String() + "/check-in", guard, "", http.StatusConflict},
+		{approved + "/check-out", guard, "", http.StatusConflict},
+		{other + "/check-out", newAuthToken(auth.RoleAdmin), "", http.StatusCreated},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.path, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(approved+"/check-in", guard, `{"comment":"gate 1"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("check-in: expected 201, got %d", resp.Code)
+	}
+	var entry EntryLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if entry.PassID != nil || entry.GuestRequestID == nil || *entry.GuestRequestID != approvedGuestID || entry.Action != "entry" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestGuestPinRoutes(t *testing.T) {
+	router := setupRouter()
+	guard := newAuthToken(auth.RoleGuard)
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	owner, _, _ := manager.GenerateTokens(guestOwnerID, auth.RoleResident)
+	approved := "/guest-requests/" + approvedGuestID.String()
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodGet, approved + "/pin", guard, "", http.StatusForbidden},
+		{http.MethodGet, approved + "/pin", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodGet, "/guest-requests/bad/pin", owner, "", http.StatusBadRequest},
+		{http.MethodGet, "/guest-requests/" + uuid.New().String() + "/pin", newAuthToken(auth.RoleAdmin), "", http.StatusConflict},
+		{http.MethodPost, approved + "/pin", owner, "", http.StatusOK},
+		{http.MethodPost, "/guest-requests/check-in-by-pin", owner, `{"pin":"042917"}`, http.StatusForbidden},
+		{http.MethodPost, "/guest-requests/check-in-by-pin", guard, "{", http.StatusBadRequest},
+		{http.MethodPost, "/guest-requests/check-in-by-pin", guard, `{"pin":"111111"}`, http.StatusNotFound},
+		{http.MethodPost, "/guest-requests/check-in-by-pin", guard, `{"pin":"999999"}`, http.StatusTooManyRequests},
+		{http.MethodPost, "/guest-requests/check-in-by-pin", guard, `{"pin":"042917","plate_number":"bad"}`, http.StatusBadRequest},
+		{http.MethodPost, "/guest-requests/check-in-by-pin", guard, `{"pin":"042917","override":true,"comment":"x"}`, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodGet, approved+"/pin", owner, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("get pin: expected 200, got %d", resp.Code)
+	}
+	var pin GuestPinResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pin); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if pin.Pin != "042917" || pin.GuestRequestID != approvedGuestID || pin.ValidTo.IsZero() {
+		t.Fatalf("unexpected pin: %+v", pin)
+	}
+
+	resp = send(http.MethodPost, "/guest-requests/check-in-by-pin", guard, `{"pin":"042917","plate_number":"M777MM77","comment":"other car"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("check-in by pin: expected 201, got %d", resp.Code)
+	}
+	var checkIn GuestPinCheckInResponse
+	if err := json.NewDecoder(resp.Body).Decode(&checkIn); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if checkIn.Guest.ID != approvedGuestID || checkIn.Guest.Status != service.GuestStatusArrived || checkIn.Entry.Action != "entry" {
+		t.Fatalf("unexpected check-in: %+v", checkIn)
+	}
+}
+
+func TestGuestSeriesRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	owner, _, _ := manager.GenerateTokens(guestOwnerID, auth.RoleResident)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	series := "/guest-series/" + guestSeriesID.String()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/guest-series", owner, `{"guest_full_name":"Nanny","plate_number":"A123BC77","weekdays":[2],"until":"2025-06-30","starts_on":"2025-05-06","start":"09:00","end":"18:00"}`, http.StatusCreated},
+		{http.MethodPost, "/guest-series", owner, `{"guest_full_name":"Nanny"}`, http.StatusBadRequest},
+		{http.MethodPost, "/guest-series", newAuthToken(auth.RoleGuard), `{}`, http.StatusForbidden},
+		{http.MethodGet, "/guest-series", owner, "", http.StatusOK},
+		{http.MethodGet, "/guest-series", admin, "", http.StatusOK},
+		{http.MethodGet, series, owner, "", http.StatusOK},
+		{http.MethodGet, series, newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodGet, "/guest-series/" + uuid.New().String(), admin, "", http.StatusNotFound},
+		{http.MethodGet, "/guest-series/bad", admin, "", http.StatusBadRequest},
+		{http.MethodPatch, series, owner, `{"status":"approved"}`, http.StatusBadRequest},
+		{http.MethodPatch, series, owner, `{bad`, http.StatusBadRequest},
+		{http.MethodPost, series + "/approve", owner, "", http.StatusForbidden},
+		{http.MethodPost, series + "/approve", admin, "", http.StatusConflict},
+		{http.MethodPost, series + "/reject", admin, `{}`, http.StatusBadRequest},
+		{http.MethodPost, series + "/reject", admin, `{"reason":"no"}`, http.StatusOK},
+		{http.MethodPost, series + "/cancel", owner, "", http.StatusOK},
+		{http.MethodGet, series + "/occurrences?from=bad", owner, "", http.StatusBadRequest},
+		{http.MethodPost, series + "/occurrences/2025-05-06/skip", owner, "", http.StatusOK},
+		{http.MethodPost, series + "/occurrences/2025-05-07/skip", owner, "", http.StatusNotFound},
+		{http.MethodPost, series + "/occurrences/2025-05-06/skip", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodPatch, series + "/occurrences/2025-05-06", owner, `{"status":"approved"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		resp := send(tc.method, tc.path, tc.token, tc.body)
+		if resp.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPatch, series, owner, `{"guest_full_name":"Tutor"}`)
+	var updated GuestSeriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if updated.Status != service.GuestStatusPending || updated.GuestFullName != "Tutor" || updated.RRule != "FREQ=WEEKLY;BYDAY=TU" {
+		t.Fatalf("resident edit must keep the rule and go through review: %+v", updated)
+	}
+	if updated.PreviousSeriesID == nil || *updated.PreviousSeriesID != guestSeriesID {
+		t.Fatalf("unexpected previous series: %+v", updated)
+	}
+	resp = send(http.MethodPatch, series, admin, `{"start":"10:00"}`)
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if updated.Status != service.GuestStatusApproved {
+		t.Fatalf("admin edit must keep the status: %+v", updated)
+	}
+
+	resp = send(http.MethodGet, series+"/occurrences", owner, "")
+	var occurrences []GuestOccurrenceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&occurrences); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(occurrences) != 2 || occurrences[0].Day != "2025-05-06" || occurrences[0].GuestRequestID != nil || occurrences[1].GuestRequestID == nil {
+		t.Fatalf("unexpected occurrences: %+v", occurrences)
+	}
+
+	resp = send(http.MethodPatch, series+"/occurrences/2025-05-06", owner, `{"plate_number":"M777MM77"}`)
+	var guest GuestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&guest); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if guest.Status != service.GuestStatusPending || guest.PlateNumber != "M777MM77" || guest.GuestFullName != "Nanny" {
+		t.Fatalf("unexpected occurrence edit: %+v", guest)
+	}
+}
+
+func TestGuestWindowConflicts(t *testing.T) {
+	router := setupRouter()
+	resident := newAuthToken(auth.RoleResident)
+	create := func(plate string, from, to time.Time) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"guest_full_name": "Guest",
+			"plate_number":    plate,
+			"valid_from":      from,
+			"valid_to":        to,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/guest-requests", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+resident)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	now := time.Now()
+
+	resp := create("A123BC77", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("past window: expected 400, got %d", resp.Code)
+	}
+
+	resp = create("C333CC77", now.Add(time.Hour), now.Add(2*time.Hour))
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("conflict: expected 409, got %d", resp.Code)
+	}
+	var conflict GuestConflictResponse
+	if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(conflict.ConflictingRequestIDs) != 1 || conflict.ConflictingRequestIDs[0] != approvedGuestID {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+
+	resp = create("B456CE99", now.Add(time.Hour), now.Add(2*time.Hour))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("warning: expected 201, got %d", resp.Code)
+	}
+	var guest GuestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&guest); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(guest.ConflictingRequestIDs) != 1 || guest.ConflictingRequestIDs[0] != approvedGuestID {
+		t.Fatalf("expected an overlap warning: %+v", guest)
+	}
+
+	resp = create("A123BC77", now.Add(time.Hour), now.Add(2*time.Hour))
+	if strings.Contains(resp.Body.String(), "conflicting_request_ids") {
+		t.Fatalf("unexpected warning: %s", resp.Body.String())
+	}
+}
+
+func TestGuestTypes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	send := func(method, path string, payload map[string]interface{}) GuestResponse {
+		t.Helper()
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+admin)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != http.StatusCreated && resp.Code != http.StatusOK {
+			t.Fatalf("%s %s: unexpected status %d: %s", method, path, resp.Code, resp.Body.String())
+		}
+		var guest GuestResponse
+		if err := json.NewDecoder(resp.Body).Decode(&guest); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return guest
+	}
+
+	guest := send(http.MethodPost, "/guest-requests", map[string]interface{}{
+		"guest_full_name": "Courier",
+		"guest_type":      "delivery",
+		"company_name":    "Fast Food",
+		"valid_from":      time.Now().Add(time.Hour),
+		"valid_to":        time.Now().Add(2 * time.Hour),
+	})
+	if guest.GuestType != "delivery" || guest.CompanyName == nil || *guest.CompanyName != "Fast Food" || guest.PlateNumber != "" {
+		t.Fatalf("unexpected delivery: %+v", guest)
+	}
+
+	// Switching to a pedestrian drops the plate the request had.
+	guest = send(http.MethodPatch, "/guest-requests/"+approvedGuestID.String(), map[string]interface{}{"guest_type": "pedestrian"})
+	if guest.GuestType != "pedestrian" || guest.PlateNumber != "" {
+		t.Fatalf("unexpected pedestrian: %+v", guest)
+	}
+}
+
+func TestSavedGuestRoutes(t *testing.T) {
+	router := setupRouter()
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	owner, _, _ := manager.GenerateTokens(guestOwnerID, auth.RoleResident)
+	saved := "/saved-guests/" + savedGuestID.String()
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/saved-guests", newAuthToken(auth.RoleGuard), "", http.StatusForbidden},
+		{http.MethodGet, "/saved-guests", newAuthToken(auth.RoleAdmin), "", http.StatusForbidden},
+		{http.MethodGet, "/saved-guests", owner, "", http.StatusOK},
+		{http.MethodPost, "/saved-guests", owner, `{"plate_number":"A123BC77"}`, http.StatusBadRequest},
+		{http.MethodPost, "/saved-guests", owner, `{"guest_full_name":"Anna","plate_number":"A123BC77"}`, http.StatusCreated},
+		{http.MethodGet, saved, owner, "", http.StatusOK},
+		{http.MethodGet, saved, newAuthToken(auth.RoleResident), "", http.StatusNotFound},
+		{http.MethodGet, "/saved-guests/bad", owner, "", http.StatusBadRequest},
+		{http.MethodPatch, saved, owner, `{"guest_type":"pedestrian","plate_number":"A123BC77"}`, http.StatusBadRequest},
+		{http.MethodDelete, saved, newAuthToken(auth.RoleResident), "", http.StatusNotFound},
+		{http.MethodDelete, saved, owner, "", http.StatusNoContent},
+		{http.MethodGet, "/saved-guests/suggestions?limit=0", owner, "", http.StatusBadRequest},
+		{http.MethodPost, saved + "/guest-requests", newAuthToken(auth.RoleResident), "{}", http.StatusNotFound},
+		{http.MethodPost, saved + "/guest-requests", owner, `{"valid_from":"2020-01-01T10:00:00Z","valid_to":"2020-01-01T11:00:00Z"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPatch, saved, owner, `{"guest_type":"pedestrian","notes":"on foot"}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d", resp.Code)
+	}
+	var updated SavedGuestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if updated.GuestType != "pedestrian" || updated.PlateNumber != "" || updated.GuestFullName != "Nanny" || updated.Notes == nil || *updated.Notes != "on foot" {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+
+	resp = send(http.MethodGet, "/saved-guests/suggestions?limit=500", owner, "")
+	var suggestions []GuestSuggestionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&suggestions); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Visits != service.MaxGuestSuggestions {
+		t.Fatalf("unexpected suggestions: %+v", suggestions)
+	}
+
+	from := time.Now().Add(time.Hour).UTC()
+	body, _ := json.Marshal(map[string]time.Time{"valid_from": from, "valid_to": from.Add(time.Hour)})
+	resp = send(http.MethodPost, saved+"/guest-requests", owner, string(body))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("visit: expected 201, got %d", resp.Code)
+	}
+	var guest GuestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&guest); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if guest.GuestFullName != "Nanny" || guest.PlateNumber != "A123BC77" || guest.ResidentUserID != guestOwnerID {
+		t.Fatalf("unexpected guest request: %+v", guest)
+	}
+}
+
+func TestEntryLogRoutes(t *testing.T) {
+	router := setupRouter()
+	manager := auth.NewTokenManager("test-access", "test-refresh", time.Hour, time.Hour)
+	owner, _, _ := manager.GenerateTokens(guestOwnerID, auth.RoleResident)
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	ownPass := "/passes/" + ownedPassID.String() + "/entry-logs"
+	cases := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/entry-logs", owner, http.StatusForbidden},
+		{"/entry-logs", newAuthToken(auth.RoleAdmin), http.StatusOK},
+		{"/entry-logs?from=2025-05-01&to=2025-05-02&action=exit&plate=a123", guard, http.StatusOK},
+		{"/entry-logs?from=yesterday", guard, http.StatusBadRequest},
+		{"/entry-logs?to=bad", guard, http.StatusBadRequest},
+		{"/entry-logs?guard_id=bad", guard, http.StatusBadRequest},
+		{"/entry-logs?action=amendment", guard, http.StatusOK},
+		{"/entry-logs?action=checkin", guard, http.StatusBadRequest},
+		{ownPass, owner, http.StatusOK},
+		{ownPass, guard, http.StatusOK},
+		{"/passes/" + uuid.NewString() + "/entry-logs", owner, http.StatusForbidden},
+		{"/passes/bad/entry-logs", owner, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if resp := send(http.MethodGet, tc.path, tc.token); resp.Code != tc.status {
+			t.Fatalf("GET %s: expected %d, got %d", tc.path, tc.status, resp.Code)
+		}
+	}
+
+	guardID := uuid.New()
+	resp := send(http.MethodGet, "/entry-logs?plot=12&guard_id="+guardID.String(), guard)
+	var logs []EntryLogRecordResponse
+	if err := json.NewDecoder(resp.Body).Decode(&logs); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(logs) != 1 || logs[0].GuardUserID != guardID || logs[0].OwnerPlotNumber == nil || *logs[0].OwnerPlotNumber != "12" || logs[0].PassID != nil {
+		t.Fatalf("unexpected logs: %+v", logs)
+	}
+
+	resp = send(http.MethodGet, ownPass, owner)
+	logs = nil
+	if err := json.NewDecoder(resp.Body).Decode(&logs); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Photo == nil || !strings.HasPrefix(logs[0].Photo.ThumbnailURL, "/entry-photos/"+logs[0].Photo.ID.String()+"/thumbnail?") {
+		t.Fatalf("expected photo links: %+v", logs)
+	}
+	if len(logs) != 1 || logs[0].PassID == nil || *logs[0].PassID != ownedPassID || logs[0].GuardFullName != "Guard" {
+		t.Fatalf("unexpected pass logs: %+v", logs)
+	}
+}
+
+func TestEntryLogAmendmentRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	logID := uuid.NewString()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/entry-logs/verify", guard, "", http.StatusForbidden},
+		{http.MethodGet, "/entry-logs/verify", admin, "", http.StatusOK},
+		{http.MethodPost, "/entry-logs/" + logID + "/amendments", newAuthToken(auth.RoleResident), `{"reason":"typo"}`, http.StatusForbidden},
+		{http.MethodPost, "/entry-logs/bad/amendments", guard, `{"reason":"typo"}`, http.StatusBadRequest},
+		{http.MethodPost, "/entry-logs/" + logID + "/amendments", guard, `{`, http.StatusBadRequest},
+		{http.MethodPost, "/entry-logs/" + logID + "/amendments", guard, `{"reason":" "}`, http.StatusBadRequest},
+		{http.MethodPost, "/entry-logs/" + logID + "/amendments", guard, `{"reason":"typo","action":"parked"}`, http.StatusBadRequest},
+		{http.MethodPost, "/entry-logs/" + amendmentLogID.String() + "/amendments", admin, `{"reason":"typo"}`, http.StatusConflict},
+		{http.MethodPost, "/entry-logs/" + logID + "/amendments", guard, `{"reason":"wrong button","action":"void"}`, http.StatusCreated},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPost, "/entry-logs/"+logID+"/amendments", admin, `{"reason":"left earlier","action":"exit","action_at":"2025-05-01T10:00:00Z"}`)
+	var entry EntryLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if entry.Action != service.EntryActionAmendment || entry.AmendsID == nil || entry.AmendsID.String() != logID || entry.CorrectedAction == nil || *entry.CorrectedAction != "exit" {
+		t.Fatalf("unexpected amendment: %+v", entry)
+	}
+
+	resp = send(http.MethodGet, "/entry-logs/verify", admin, "")
+	var report ChainReportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.OK || report.Checked != 41 || report.Break == nil || report.Break.Seq != 42 || report.Break.Reason != service.ChainBreakHash {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestPresenceRoutes(t *testing.T) {
+	router := setupRouter()
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	entry := "/passes/" + onSitePassID.String() + "/entry"
+
+	resp := send(http.MethodPost, entry, guard, "")
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("double entry: expected 409, got %d", resp.Code)
+	}
+	var conflict PresenceConflictResponse
+	if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if conflict.Anomaly != service.EntryAnomalyDoubleEntry || conflict.EnteredAt == nil {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+
+	resp = send(http.MethodPost, entry, guard, `{"override_reason":"exit was not recorded"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("override: expected 201, got %d", resp.Code)
+	}
+	var logged EntryLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&logged); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if logged.Anomaly == nil || *logged.Anomaly != service.EntryAnomalyDoubleEntry || logged.OverrideReason == nil {
+		t.Fatalf("unexpected entry: %+v", logged)
+	}
+	if resp := send(http.MethodPost, "/passes/"+suspendedPassID.String()+"/entry", guard, ""); resp.Code != http.StatusConflict {
+		t.Fatalf("suspended pass: expected 409, got %d", resp.Code)
+	}
+
+	if resp := send(http.MethodGet, "/presence", newAuthToken(auth.RoleResident), ""); resp.Code != http.StatusForbidden {
+		t.Fatalf("resident: expected 403, got %d", resp.Code)
+	}
+	resp = send(http.MethodGet, "/presence?plot=12", guard, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("presence: expected 200, got %d", resp.Code)
+	}
+	var onSite []OnSiteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&onSite); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(onSite) != 1 || onSite[0].DurationSeconds != 5400 || onSite[0].OwnerPlotNumber == nil || *onSite[0].OwnerPlotNumber != "12" {
+		t.Fatalf("unexpected presence: %+v", onSite)
+	}
+
+	resp = send(http.MethodGet, "/presence/export?lang=en", newAuthToken(auth.RoleAdmin), "")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "A123BC77") || !strings.Contains(resp.Body.String(), ";90;") {
+		t.Fatalf("export: unexpected response %d %q", resp.Code, resp.Body.String())
+	}
+	if resp := send(http.MethodGet, "/presence/export?format=pdf", guard, ""); resp.Code != http.StatusBadRequest {
+		t.Fatalf("export format: expected 400, got %d", resp.Code)
+	}
+}
+
+func TestGateRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	gateID := uuid.NewString()
+	closed := closedGateID.String()
+	passID := uuid.NewString()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/gates", guard, "", http.StatusOK},
+		{http.MethodGet, "/gates", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodPost, "/gates", guard, `{"name":"Калитка"}`, http.StatusForbidden},
+		{http.MethodPost, "/gates", admin, `{`, http.StatusBadRequest},
+		{http.MethodPost, "/gates", admin, `{"name":" "}`, http.StatusBadRequest},
+		{http.MethodPost, "/gates", admin, `{"name":"Главные ворота"}`, http.StatusConflict},
+		{http.MethodPost, "/gates", admin, `{"name":"Калитка","description":"пешеходы"}`, http.StatusCreated},
+		{http.MethodGet, "/gates/bad", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/gates/" + gateID, admin, "", http.StatusOK},
+		{http.MethodPatch, "/gates/" + gateID, admin, `{"name":"Северные ворота"}`, http.StatusOK},
+		{http.MethodDelete, "/gates/" + closed, admin, "", http.StatusConflict},
+		{http.MethodDelete, "/gates/" + gateID, admin, "", http.StatusNoContent},
+		{http.MethodGet, "/gates/stats?from=2025-05-02&to=2025-05-01", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/gates/stats?from=2025-05-01", admin, "", http.StatusOK},
+		{http.MethodGet, "/gates/stats", guard, "", http.StatusForbidden},
+		{http.MethodPut, "/gates/guest-types/truck", admin, `{"gate_ids":[]}`, http.StatusBadRequest},
+		{http.MethodPut, "/gates/guest-types/delivery", admin, `{"gate_ids":["` + gateID + `"]}`, http.StatusOK},
+		{http.MethodPut, "/users/" + uuid.NewString() + "/gates", admin, `{"gate_ids":["` + uuid.Nil.String() + `"]}`, http.StatusBadRequest},
+		{http.MethodPut, "/users/" + uuid.NewString() + "/gates", admin, `{"gate_ids":["` + gateID + `"]}`, http.StatusOK},
+		{http.MethodGet, "/users/" + uuid.NewString() + "/gates", guard, "", http.StatusForbidden},
+		{http.MethodGet, "/passes/" + passID + "/gates", guard, "", http.StatusOK},
+		{http.MethodPut, "/passes/" + passID + "/gates", guard, `{"gate_ids":[]}`, http.StatusForbidden},
+		{http.MethodPut, "/passes/" + passID + "/gates", admin, `{"gate_ids":["` + gateID + `"]}`, http.StatusOK},
+		{http.MethodPost, "/passes/" + passID + "/entry", guard, `{"gate_id":"` + closed + `"}`, http.StatusForbidden},
+		{http.MethodPost, "/guest-requests/" + approvedGuestID.String() + "/check-in", guard, `{"gate_id":"` + closed + `"}`, http.StatusForbidden},
+		{http.MethodGet, "/entry-logs?gate_id=bad", guard, "", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPost, "/passes/"+passID+"/entry", guard, `{"gate_id":"`+gateID+`"}`)
+	var entry EntryLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if entry.GateID == nil || entry.GateID.String() != gateID {
+		t.Fatalf("unexpected entry gate: %+v", entry)
+	}
+
+	resp = send(http.MethodGet, "/gates/guest-types", admin, "")
+	var byType []GuestTypeGatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&byType); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(byType) != 2 || byType[0].GuestType != service.GuestTypeDelivery || len(byType[0].Gates) != 2 {
+		t.Fatalf("unexpected guest type gates: %+v", byType)
+	}
+
+	resp = send(http.MethodGet, "/gates/stats", admin, "")
+	var stats []GateStatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Entries != 5 || stats[0].LastActionAt == nil {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestShiftRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	ended := endedShiftID.String()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/shifts", admin, `{}`, http.StatusForbidden},
+		{http.MethodPost, "/shifts", guard, `{`, http.StatusBadRequest},
+		{http.MethodPost, "/shifts", guard, `{"gate_id":"` + closedGateID.String() + `"}`, http.StatusForbidden},
+		{http.MethodPost, "/shifts", guard, `{}`, http.StatusCreated},
+		{http.MethodGet, "/shifts/current", guard, "", http.StatusOK},
+		{http.MethodGet, "/shifts/current", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodPost, "/shifts/current/end", guard, `{"handover_note":"всё спокойно"}`, http.StatusOK},
+		{http.MethodGet, "/shifts", guard, "", http.StatusForbidden},
+		{http.MethodGet, "/shifts?guard_id=bad", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/shifts?from=2025-05-02&to=2025-05-01", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/shifts?open=true", admin, "", http.StatusOK},
+		{http.MethodGet, "/shifts/bad", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/shifts/" + uuid.NewString(), admin, "", http.StatusNotFound},
+		{http.MethodGet, "/shifts/" + ended, admin, "", http.StatusOK},
+		{http.MethodPost, "/shifts/" + ended + "/end", admin, `{}`, http.StatusConflict},
+		{http.MethodPost, "/shifts/" + uuid.NewString() + "/end", guard, `{}`, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPost, "/shifts", guard, `{}`)
+	var started ShiftResponse
+	if err := json.NewDecoder(resp.Body).Decode(&started); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if started.Handover == nil || started.Handover.ShiftID != endedShiftID || started.Handover.Note == nil {
+		t.Fatalf("unexpected handover: %+v", started)
+	}
+
+	resp = send(http.MethodPost, "/shifts/current/end", guard, `{"handover_note":"всё спокойно"}`)
+	var closed ShiftResponse
+	if err := json.NewDecoder(resp.Body).Decode(&closed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if closed.EndedAt == nil || closed.HandoverNote == nil || *closed.HandoverNote != "всё спокойно" || closed.Summary.VehiclesOnSite != 3 {
+		t.Fatalf("unexpected ended shift: %+v", closed)
+	}
+}
+
+func TestIncidentRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	id := uuid.NewString()
+	resolved := resolvedIncidentID.String()
+	png := "\x89PNG\r\n\x1a\n"
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/incidents", newAuthToken(auth.RoleResident), `{}`, http.StatusForbidden},
+		{http.MethodPost, "/incidents", guard, `{`, http.StatusBadRequest},
+		{http.MethodPost, "/incidents", guard, `{"category":"fire","severity":"high","title":"x"}`, http.StatusBadRequest},
+		{http.MethodPost, "/incidents", guard, `{"category":"other","severity":"low","title":" "}`, http.StatusBadRequest},
+		{http.MethodPost, "/incidents", guard, `{"category":"unknown_vehicle","severity":"low","title":"Чужая машина","plate_number":"A123BC77"}`, http.StatusCreated},
+		{http.MethodGet, "/incidents?status=closed", guard, "", http.StatusBadRequest},
+		{http.MethodGet, "/incidents?reported_by=bad", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/incidents?status=open", guard, "", http.StatusOK},
+		{http.MethodGet, "/incidents/bad", guard, "", http.StatusBadRequest},
+		{http.MethodGet, "/incidents/" + uuid.Nil.String(), guard, "", http.StatusNotFound},
+		{http.MethodGet, "/incidents/" + id, guard, "", http.StatusOK},
+		{http.MethodPost, "/incidents/" + id + "/status", guard, `{"status":"resolved"}`, http.StatusForbidden},
+		{http.MethodPost, "/incidents/" + id + "/status", admin, `{"status":"closed"}`, http.StatusBadRequest},
+		{http.MethodPost, "/incidents/" + resolved + "/status", admin, `{"status":"dismissed"}`, http.StatusConflict},
+		{http.MethodPost, "/incidents/" + resolved + "/status", admin, `{"status":"open","comment":"повторилось"}`, http.StatusOK},
+		{http.MethodPost, "/incidents/" + id + "/comments", guard, `{"body":"?"}`, http.StatusForbidden},
+		{http.MethodPost, "/incidents/" + id + "/comments", admin, `{"body":" "}`, http.StatusBadRequest},
+		{http.MethodPost, "/incidents/" + id + "/comments", admin, `{"body":"Передано в УК"}`, http.StatusCreated},
+		{http.MethodPost, "/incidents/" + id + "/attachments", guard, "", http.StatusBadRequest},
+		{http.MethodPost, "/incidents/" + id + "/attachments", guard, "<html>", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/incidents/" + id + "/attachments?file_name=a.png", guard, png, http.StatusCreated},
+		{http.MethodDelete, "/incidents/" + id + "/attachments/" + uuid.NewString(), guard, "", http.StatusForbidden},
+		{http.MethodDelete, "/incidents/" + id + "/attachments/bad", admin, "", http.StatusBadRequest},
+		{http.MethodDelete, "/incidents/" + id + "/attachments/" + uuid.NewString(), admin, "", http.StatusNoContent},
+		{http.MethodGet, "/incident-attachments/" + id, "", "", http.StatusForbidden},
+		{http.MethodGet, "/incident-attachments/" + id + "?expires=1&signature=forged", "", "", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "barrier.png")
+	_, _ = part.Write([]byte(png))
+	_ = form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/incidents/"+id+"/attachments", &body)
+	req.Header.Set("Authorization", "Bearer "+guard)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.Code)
+	}
+	var attachment IncidentAttachmentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&attachment); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if attachment.FileName != "barrier.png" || !strings.HasPrefix(attachment.DownloadURL, "/incident-attachments/"+attachment.ID.String()+"?expires=") {
+		t.Fatalf("unexpected attachment: %+v", attachment)
+	}
+
+	resp = send(http.MethodGet, attachment.DownloadURL, "", "")
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "image/png" || resp.Body.String() != "\x89PNG" {
+		t.Fatalf("unexpected download: %d %v", resp.Code, resp.Header())
+	}
+	if disposition := resp.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "inline; filename*=utf-8''") {
+		t.Fatalf("unexpected disposition: %s", disposition)
+	}
+
+	resp = send(http.MethodGet, "/incidents/"+id, guard, "")
+	var incident IncidentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&incident); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(incident.Comments) != 1 || incident.Comments[0].StatusTo == nil || len(incident.Attachments) != 1 || incident.Attachments[0].DownloadURL == "" {
+		t.Fatalf("unexpected incident: %+v", incident)
+	}
+}
+
+func TestEntryPhotoRoutes(t *testing.T) {
+	router := setupRouter()
+	guard := newAuthToken(auth.RoleGuard)
+	upload := func(path, payload string, photo []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		if payload != "" {
+			_ = form.WriteField("payload", payload)
+		}
+		if photo != nil {
+			part, _ := form.CreateFormFile("photo", "car.png")
+			_, _ = part.Write(photo)
+		}
+		_ = form.Close()
+		req := httptest.NewRequest(http.MethodPost, path, &body)
+		req.Header.Set("Authorization", "Bearer "+guard)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	entry := "/passes/" + uuid.NewString() + "/entry"
+
+	if resp := upload(entry, `{"comment":`, []byte("\x89PNG")); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a broken payload, got %d", resp.Code)
+	}
+	if resp := upload(entry, "", []byte("<html>")); resp.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d", resp.Code)
+	}
+	resp := upload(entry, "", nil)
+	var record EntryLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil || resp.Code != http.StatusCreated || record.Photo != nil {
+		t.Fatalf("unexpected entry without a photo: %d %+v", resp.Code, record)
+	}
+	resp = upload(entry, `{"comment":"номер грязный"}`, []byte("\x89PNG"))
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil || resp.Code != http.StatusCreated {
+		t.Fatalf("unexpected entry: %d %v", resp.Code, err)
+	}
+	if record.Comment == nil || *record.Comment != "номер грязный" || record.Photo == nil {
+		t.Fatalf("unexpected entry: %+v", record)
+	}
+
+	send := func(path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		return resp
+	}
+	if resp := send(record.Photo.URL); resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "image/png" || resp.Body.String() != "\x89PNG" {
+		t.Fatalf("unexpected photo: %d %v", resp.Code, resp.Header())
+	}
+	if resp := send(record.Photo.ThumbnailURL); resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("unexpected thumbnail: %d %v", resp.Code, resp.Header())
+	}
+	if resp := send("/entry-photos/" + record.Photo.ID.String() + "/thumbnail?expires=1&signature=forged"); resp.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.Code)
+	}
+}
+
+func TestCameraRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	cameraID := uuid.NewString()
+	operator := uuid.NewString()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/cameras", guard, "", http.StatusForbidden},
+		{http.MethodGet, "/cameras", admin, "", http.StatusOK},
+		{http.MethodPost, "/cameras", admin, `{`, http.StatusBadRequest},
+		{http.MethodPost, "/cameras", admin, `{"name":"Въезд","direction":"entry"}`, http.StatusBadRequest},
+		{http.MethodGet, "/cameras/bad", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/cameras/" + cameraID, admin, "", http.StatusOK},
+		{http.MethodPatch, "/cameras/" + cameraID, admin, `{"direction":"both"}`, http.StatusBadRequest},
+		{http.MethodPatch, "/cameras/" + cameraID, admin, `{"direction":"exit","active":false}`, http.StatusOK},
+		{http.MethodDelete, "/cameras/" + cameraID, admin, "", http.StatusNoContent},
+		{http.MethodGet, "/plate-reads/review-queue", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodGet, "/plate-reads/review-queue?gate_id=bad", guard, "", http.StatusBadRequest},
+		{http.MethodGet, "/plate-reads/review-queue", guard, "", http.StatusOK},
+		{http.MethodGet, "/plate-reads?review_status=maybe", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/plate-reads?camera_id=" + cameraID, admin, "", http.StatusOK},
+		{http.MethodPost, "/plate-reads/" + resolvedPlateReadID.String() + "/review", guard, `{"allow":false}`, http.StatusConflict},
+		{http.MethodPost, "/plate-reads/" + uuid.NewString() + "/review", guard, `{"allow":true}`, http.StatusBadRequest},
+		{http.MethodPost, "/plate-reads/" + uuid.NewString() + "/review", guard, `{"allow":false,"comment":"чужая машина"}`, http.StatusOK},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPost, "/cameras", admin, `{"name":"Въезд","direction":"entry","operator_user_id":"`+operator+`"}`)
+	var camera CameraResponse
+	if err := json.NewDecoder(resp.Body).Decode(&camera); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != http.StatusCreated || camera.Token != stubCameraToken || camera.OperatorUserID.String() != operator {
+		t.Fatalf("unexpected camera: %d %+v", resp.Code, camera)
+	}
+	resp = send(http.MethodPost, "/cameras/"+cameraID+"/token", admin, "")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "rotated-token") {
+		t.Fatalf("unexpected token rotation: %d %s", resp.Code, resp.Body.String())
+	}
+
+	read := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/camera/plate-reads", bytes.NewBufferString(body))
+		req.Header.Set(CameraTokenHeader, token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	if resp := read("wrong", `{"plate":"A123BC77","confidence":0.9}`); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a wrong token, got %d", resp.Code)
+	}
+	// A user session does not stand in for a device token.
+	req := httptest.NewRequest(http.MethodPost, "/camera/plate-reads", bytes.NewBufferString(`{"plate":"A123BC77","confidence":0.9}`))
+	req.Header.Set("Authorization", "Bearer "+admin)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a device token, got %d", resp.Code)
+	}
+	if resp := read(stubCameraToken, `{"plate":"A123BC77","confidence":1.2}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad confidence, got %d", resp.Code)
+	}
+	for body, decision := range map[string]string{
+		`{"plate":"a123bc77","confidence":0.93,"captured_at":"2025-05-01T10:00:00Z"}`: service.PlateDecisionAllow,
+		`{"plate":"A123BC77","confidence":0.4}`:                                       service.PlateDecisionReview,
+		`{"plate":"B456CE99","confidence":0.97}`:                                      service.PlateDecisionDeny,
+	} {
+		resp := read(stubCameraToken, body)
+		var decided PlateDecisionResponse
+		if err := json.NewDecoder(resp.Body).Decode(&decided); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.Code != http.StatusCreated || decided.Decision != decision {
+			t.Fatalf("%s: unexpected decision %d %+v", body, resp.Code, decided)
+		}
+		if (decided.EntryLogID != nil) != (decision == service.PlateDecisionAllow) {
+			t.Fatalf("%s: unexpected entry log %+v", body, decided)
+		}
+	}
+
+	resp = send(http.MethodGet, "/plate-reads/review-queue", guard, "")
+	var queue []PlateReadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&queue); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(queue) != 1 || queue[0].CameraName != "Въезд" || queue[0].GateName == nil || queue[0].ReviewStatus == nil {
+		t.Fatalf("unexpected review queue: %+v", queue)
+	}
+	resp = send(http.MethodPost, "/plate-reads/"+uuid.NewString()+"/review", guard, `{"allow":true,"pass_id":"`+ownedPassID.String()+`"}`)
+	var reviewed PlateReadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reviewed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Code != http.StatusOK || reviewed.ReviewStatus == nil || *reviewed.ReviewStatus != service.PlateReviewAllowed || reviewed.EntryLogID == nil {
+		t.Fatalf("unexpected review: %d %+v", resp.Code, reviewed)
+	}
+}
+
+func TestBarrierRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	gate := "/gates/" + barrierGateID.String() + "/barrier"
+	broken := "/gates/" + brokenBarrierGateID.String() + "/barrier"
+	bare := "/gates/" + uuid.NewString() + "/barrier"
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodGet, gate, guard, "", http.StatusForbidden},
+		{http.MethodGet, gate, admin, "", http.StatusOK},
+		{http.MethodGet, bare, admin, "", http.StatusNotFound},
+		{http.MethodPut, gate, admin, `{`, http.StatusBadRequest},
+		{http.MethodPut, gate, admin, `{"driver":"serial"}`, http.StatusBadRequest},
+		{http.MethodPut, bare, admin, `{"driver":"noop"}`, http.StatusOK},
+		{http.MethodDelete, gate, admin, "", http.StatusNoContent},
+		{http.MethodDelete, bare, admin, "", http.StatusNotFound},
+		{http.MethodPost, gate + "/open", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodPost, gate + "/open", guard, "", http.StatusOK},
+		{http.MethodPost, gate + "/open", guard, `{"hold_seconds":60}`, http.StatusOK},
+		{http.MethodPost, gate + "/open", guard, `{"hold_seconds":"long"}`, http.StatusBadRequest},
+		{http.MethodPost, "/gates/" + closedGateID.String() + "/barrier/open", guard, "", http.StatusForbidden},
+		{http.MethodPost, bare + "/open", guard, "", http.StatusNotFound},
+		{http.MethodPost, broken + "/open", guard, "", http.StatusBadGateway},
+		{http.MethodGet, gate + "/status", guard, "", http.StatusOK},
+		{http.MethodGet, broken + "/status", guard, "", http.StatusBadGateway},
+		{http.MethodGet, gate + "/events?failed=true", guard, "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPut, gate, admin, `{"driver":"http","address":"http://relay.local","secret":"s3cret","auto_open":false,"hold_seconds":30}`)
+	var config GateBarrierResponse
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !config.HasSecret || config.AutoOpen || config.HoldSeconds != 30 || strings.Contains(resp.Body.String(), "s3cret") {
+		t.Fatalf("unexpected barrier config: %+v", config)
+	}
+
+	for gateID, success := range map[uuid.UUID]bool{barrierGateID: true, brokenBarrierGateID: false} {
+		resp := send(http.MethodPost, "/passes/"+ownedPassID.String()+"/entry", guard, `{"gate_id":"`+gateID.String()+`"}`)
+		var entry EntryLogResponse
+		if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.Code != http.StatusCreated || entry.Barrier == nil || entry.Barrier.Success != success || (entry.Barrier.Error != nil) == success {
+			t.Fatalf("unexpected entry with barrier: %d %+v", resp.Code, entry.Barrier)
+		}
+	}
+	resp = send(http.MethodPost, "/passes/"+ownedPassID.String()+"/entry", guard, `{}`)
+	if resp.Code != http.StatusCreated || strings.Contains(resp.Body.String(), `"barrier"`) {
+		t.Fatalf("entry without a gate must not touch a barrier: %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestSyncRoutes(t *testing.T) {
+	router := setupRouter()
+	guard := newAuthToken(auth.RoleGuard)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	entry := `{"id":"` + uuid.NewString() + `","pass_id":"` + ownedPassID.String() + `","action":"entry","device_time":"2025-05-01T10:00:00Z"}`
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/entry-logs/batch", newAuthToken(auth.RoleResident), `{"entries":[` + entry + `]}`, http.StatusForbidden},
+		{http.MethodPost, "/entry-logs/batch", guard, `{`, http.StatusBadRequest},
+		{http.MethodPost, "/entry-logs/batch", guard, `{"entries":[]}`, http.StatusBadRequest},
+		{http.MethodPost, "/entry-logs/batch", guard, `{"entries":[` + entry + `]}`, http.StatusOK},
+		{http.MethodGet, "/sync/delta", newAuthToken(auth.RoleResident), "", http.StatusForbidden},
+		{http.MethodGet, "/sync/delta", guard, "", http.StatusOK},
+		{http.MethodGet, "/sync/delta?since=yesterday", guard, "", http.StatusBadRequest},
+		{http.MethodGet, "/sync/delta?since=2999-01-01T00:00:00Z", guard, "", http.StatusBadRequest},
+		{http.MethodGet, "/sync/delta?since=2025-05-01T10:00:00Z", newAuthToken(auth.RoleAdmin), "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	resp := send(http.MethodPost, "/entry-logs/batch", guard, `{"entries":[`+entry+`,{"id":"`+uuid.NewString()+`","pass_id":"`+uuid.NewString()+`","action":"entry","device_time":"2025-05-01T10:05:00Z"}]}`)
+	var batch SyncBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(batch.Results) != 2 || batch.Results[0].Status != service.SyncStatusCreated || batch.Results[0].Entry == nil || batch.Results[0].Entry.DeviceAt == nil ||
+		batch.Results[1].Status != service.SyncStatusConflict || batch.Results[1].Conflict != service.SyncConflictPassInactive {
+		t.Fatalf("unexpected batch results: %+v", batch.Results)
+	}
+
+	resp = send(http.MethodGet, "/sync/delta", guard, "")
+	var delta SyncDeltaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&delta); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !delta.Full || len(delta.Passes) != 1 || delta.Guests == nil || delta.Cursor.IsZero() {
+		t.Fatalf("unexpected delta: %+v", delta)
+	}
+}
+
+func TestPlotRoutes(t *testing.T) {
+	router := setupRouter()
+	admin := newAuthToken(auth.RoleAdmin)
+	resident := newAuthToken(auth.RoleResident)
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	plot := "/plots/" + stubPlotID.String()
+	other := "/plots/" + uuid.NewString()
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/plots", admin, "", http.StatusOK},
+		{http.MethodGet, "/plots?status=sold", admin, "", http.StatusBadRequest},
+		{http.MethodGet, "/plots", resident, "", http.StatusOK},
+		{http.MethodPost, "/plots", resident, `{"number":"14"}`, http.StatusForbidden},
+		{http.MethodPost, "/plots", admin, `{`, http.StatusBadRequest},
+		{http.MethodPost, "/plots", admin, `{"number":"дом у реки"}`, http.StatusBadRequest},
+		{http.MethodPost, "/plots", admin, `{"number":"уч. 12а"}`, http.StatusConflict},
+		{http.MethodPost, "/plots", admin, `{"number":"14","street":"Лесная"}`, http.StatusCreated},
+		{http.MethodGet, plot, newAuthToken(auth.RoleGuard), "", http.StatusOK},
+		{http.MethodGet, plot, resident, "", http.StatusOK},
+		{http.MethodGet, other, resident, "", http.StatusForbidden},
+		{http.MethodGet, other, admin, "", http.StatusNotFound},
+		{http.MethodGet, "/plots/bad", admin, "", http.StatusBadRequest},
+		{http.MethodPatch, plot, resident, `{"street":"Лесная"}`, http.StatusForbidden},
+		{http.MethodPatch, plot, admin, `{"number":"A"}`, http.StatusBadRequest},
+		{http.MethodPatch, plot, admin, `{"street":"Лесная"}`, http.StatusOK},
+		{http.MethodPatch, other, admin, `{"street":"Лесная"}`, http.StatusNotFound},
+		{http.MethodGet, plot + "/passes", resident, "", http.StatusOK},
+		{http.MethodGet, other + "/guest-requests", resident, "", http.StatusForbidden},
+		{http.MethodGet, plot + "/guest-requests", admin, "", http.StatusOK},
+		{http.MethodPut, plot + "/residents/" + uuid.NewString(), resident, `{"relation":"family"}`, http.StatusForbidden},
+		{http.MethodPut, plot + "/residents/" + uuid.NewString(), admin, `{"relation":"friend"}`, http.StatusBadRequest},
+		{http.MethodPut, plot + "/residents/bad", admin, `{"relation":"family"}`, http.StatusBadRequest},
+		{http.MethodPut, other + "/residents/" + uuid.NewString(), admin, `{"relation":"family"}`, http.StatusNotFound},
+		{http.MethodPut, plot + "/residents/" + uuid.NewString(), admin, `{"relation":"family"}`, http.StatusOK},
+		{http.MethodDelete, plot + "/residents/" + uuid.NewString(), admin, "", http.StatusNoContent},
+		{http.MethodDelete, other + "/residents/" + uuid.NewString(), admin, "", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if resp := send(tc.method, tc.path, tc.token, tc.body); resp.Code != tc.status {
+			t.Fatalf("%s %s %s: expected %d, got %d", tc.method, tc.path, tc.body, tc.status, resp.Code)
+		}
+	}
+
+	var got PlotResponse
+	if err := json.NewDecoder(send(http.MethodGet, plot, resident, "").Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Relation != service.PlotRelationTenant || len(got.Residents) != 1 {
+		t.Fatalf("unexpected plot: %+v", got)
+	}
+	var passes []PassResponse
+	if err := json.NewDecoder(send(http.MethodGet, plot+"/passes", admin, "").Body).Decode(&passes); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(passes) != 1 || passes[0].PlotID == nil || *passes[0].PlotID != stubPlotID {
+		t.Fatalf("unexpected plot passes: %+v", passes)
 	}
 }
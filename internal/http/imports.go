@@ -0,0 +1,117 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"pipo-edu-project/internal/service"
+	"pipo-edu-project/internal/tabular"
+)
+
+const (
+	maxImportSize = 10 << 20
+	importTimeout = 5 * time.Minute
+)
+
+type ImportRowErrorResponse struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ImportReportResponse struct {
+	DryRun        bool                     `json:"dry_run"`
+	Applied       bool                     `json:"applied"`
+	Rows          int                      `json:"rows"`
+	UsersCreated  int                      `json:"users_created"`
+	UsersUpdated  int                      `json:"users_updated"`
+	UsersRestored int                      `json:"users_restored"`
+	PassesCreated int                      `json:"passes_created"`
+	PassesUpdated int                      `json:"passes_updated"`
+	Errors        []ImportRowErrorResponse `json:"errors"`
+}
+
+func (h *Handler) HandleImportUsers(w http.ResponseWriter, r *http.Request) {
+	// Uploading and hashing passwords for a large file outlives the server timeouts.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(importTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(importTimeout))
+
+	data, err := readImportFile(w, r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	records, err := tabular.Read(data)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid file: "+err.Error())
+		return
+	}
+	rows, err := service.ParseImportRows(records)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	report, err := h.Service.ImportUsers(r.Context(), rows, service.ImportOptions{
+		DryRun:  dryRun,
+		ActorID: actorFromContext(r),
+		Restore: r.URL.Query().Get("restore") == "true",
+	})
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "import error")
+		return
+	}
+	if report.Applied && h.Metrics != nil {
+		h.Metrics.Users.WithLabelValues("imported").Add(float64(report.UsersCreated + report.UsersUpdated + report.UsersRestored))
+	}
+	status := http.StatusOK
+	if !dryRun && !report.Applied {
+		status = http.StatusUnprocessableEntity
+	}
+	WriteJSON(w, status, mapImportReport(report))
+}
+
+// readImportFile accepts either a multipart form with a "file" field or the
+// raw file as the request body.
+func readImportFile(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var source io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.New("file field is required")
+		}
+		defer file.Close()
+		source = file
+	}
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return nil, errors.New("file is too large or unreadable")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	return data, nil
+}
+
+func mapImportReport(report service.ImportReport) ImportReportResponse {
+	resp := ImportReportResponse{
+		DryRun:        report.DryRun,
+		Applied:       report.Applied,
+		Rows:          report.Rows,
+		UsersCreated:  report.UsersCreated,
+		UsersUpdated:  report.UsersUpdated,
+		UsersRestored: report.UsersRestored,
+		PassesCreated: report.PassesCreated,
+		PassesUpdated: report.PassesUpdated,
+		Errors:        make([]ImportRowErrorResponse, 0, len(report.Errors)),
+	}
+	for _, rowErr := range report.Errors {
+		resp.Errors = append(resp.Errors, ImportRowErrorResponse{Line: rowErr.Line, Field: rowErr.Field, Message: rowErr.Message})
+	}
+	return resp
+}
@@ -0,0 +1,477 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+// maxUploadSize bounds what is read from an upload; the service applies the
+// configured, usually smaller, limit.
+const maxUploadSize = 32 << 20
+
+type IncidentRequest struct {
+	Category       string     `json:"category"`
+	Severity       string     `json:"severity"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	OccurredAt     *time.Time `json:"occurred_at"`
+	GateID         *uuid.UUID `json:"gate_id"`
+	PassID         *uuid.UUID `json:"pass_id"`
+	GuestRequestID *uuid.UUID `json:"guest_request_id"`
+	PlateNumber    string     `json:"plate_number"`
+}
+
+type IncidentStatusRequest struct {
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+}
+
+type IncidentCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type IncidentCommentResponse struct {
+	ID             uuid.UUID `json:"id"`
+	AuthorID       uuid.UUID `json:"author_id"`
+	AuthorFullName string    `json:"author_full_name,omitempty"`
+	Body           string    `json:"body"`
+	StatusFrom     *string   `json:"status_from,omitempty"`
+	StatusTo       *string   `json:"status_to,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type IncidentAttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	// DownloadURL works without a bearer token until URLExpiresAt.
+	DownloadURL  string    `json:"download_url"`
+	URLExpiresAt time.Time `json:"url_expires_at"`
+}
+
+type IncidentResponse struct {
+	ID             uuid.UUID                    `json:"id"`
+	Category       string                       `json:"category"`
+	Severity       string                       `json:"severity"`
+	Status         string                       `json:"status"`
+	Title          string                       `json:"title"`
+	Description    *string                      `json:"description,omitempty"`
+	OccurredAt     time.Time                    `json:"occurred_at"`
+	ReportedBy     uuid.UUID                    `json:"reported_by"`
+	GateID         *uuid.UUID                   `json:"gate_id,omitempty"`
+	ShiftID        *uuid.UUID                   `json:"shift_id,omitempty"`
+	PassID         *uuid.UUID                   `json:"pass_id,omitempty"`
+	GuestRequestID *uuid.UUID                   `json:"guest_request_id,omitempty"`
+	PlateNumber    *string                      `json:"plate_number,omitempty"`
+	ResolvedAt     *time.Time                   `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time                    `json:"created_at"`
+	UpdatedAt      time.Time                    `json:"updated_at"`
+	Comments       []IncidentCommentResponse    `json:"comments,omitempty"`
+	Attachments    []IncidentAttachmentResponse `json:"attachments,omitempty"`
+}
+
+type IncidentListItemResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	Category         string     `json:"category"`
+	Severity         string     `json:"severity"`
+	Status           string     `json:"status"`
+	Title            string     `json:"title"`
+	OccurredAt       time.Time  `json:"occurred_at"`
+	ReportedBy       uuid.UUID  `json:"reported_by"`
+	ReporterFullName string     `json:"reporter_full_name"`
+	GateID           *uuid.UUID `json:"gate_id,omitempty"`
+	GateName         *string    `json:"gate_name,omitempty"`
+	PassID           *uuid.UUID `json:"pass_id,omitempty"`
+	GuestRequestID   *uuid.UUID `json:"guest_request_id,omitempty"`
+	PlateNumber      *string    `json:"plate_number,omitempty"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+	Attachments      int64      `json:"attachments"`
+}
+
+func (h *Handler) HandleCreateIncident(w http.ResponseWriter, r *http.Request) {
+	var req IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	input := service.IncidentInput{
+		ReporterID:  actorFromContext(r),
+		Category:    req.Category,
+		Severity:    req.Severity,
+		Title:       req.Title,
+		Description: req.Description,
+		GateID:      derefUUID(req.GateID),
+		PassID:      derefUUID(req.PassID),
+		GuestID:     derefUUID(req.GuestRequestID),
+		PlateNumber: req.PlateNumber,
+	}
+	if req.OccurredAt != nil {
+		input.OccurredAt = *req.OccurredAt
+	}
+	incident, err := h.Service.CreateIncident(r.Context(), input)
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapIncident(incident))
+}
+
+func (h *Handler) HandleListIncidents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	loc := h.Service.Location()
+	filter := service.IncidentFilter{
+		Status:   query.Get("status"),
+		Category: query.Get("category"),
+		Severity: query.Get("severity"),
+		Plate:    query.Get("plate"),
+	}
+	var err error
+	if filter.From, err = parseExportTime(query.Get("from"), loc, false); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+	if filter.To, err = parseExportTime(query.Get("to"), loc, true); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid to")
+		return
+	}
+	if raw := query.Get("reported_by"); raw != "" {
+		if filter.ReporterID, err = uuid.Parse(raw); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid reported_by")
+			return
+		}
+	}
+	limit, offset := parsePagination(r)
+	rows, err := h.Service.ListIncidents(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	resp := make([]IncidentListItemResponse, 0, len(rows))
+	for _, row := range rows {
+		item := IncidentListItemResponse{
+			ID:               row.ID,
+			Category:         row.Category,
+			Severity:         row.Severity,
+			Status:           row.Status,
+			Title:            row.Title,
+			OccurredAt:       row.OccurredAt,
+			ReportedBy:       row.ReportedBy,
+			ReporterFullName: row.ReporterFullName,
+			Attachments:      row.Attachments,
+		}
+		if row.GateID.Valid {
+			item.GateID = &row.GateID.UUID
+		}
+		if row.GateName.Valid {
+			item.GateName = &row.GateName.String
+		}
+		if row.PassID.Valid {
+			item.PassID = &row.PassID.UUID
+		}
+		if row.GuestRequestID.Valid {
+			item.GuestRequestID = &row.GuestRequestID.UUID
+		}
+		if row.PlateNumber.Valid {
+			item.PlateNumber = &row.PlateNumber.String
+		}
+		if row.ResolvedAt.Valid {
+			item.ResolvedAt = &row.ResolvedAt.Time
+		}
+		resp = append(resp, item)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleGetIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	details, err := h.Service.GetIncident(r.Context(), id)
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	resp := mapIncident(details.Incident)
+	for _, comment := range details.Comments {
+		resp.Comments = append(resp.Comments, mapIncidentComment(repo.IncidentComment{
+			ID:         comment.ID,
+			IncidentID: comment.IncidentID,
+			AuthorID:   comment.AuthorID,
+			Body:       comment.Body,
+			StatusFrom: comment.StatusFrom,
+			StatusTo:   comment.StatusTo,
+			CreatedAt:  comment.CreatedAt,
+		}, comment.AuthorFullName))
+	}
+	for _, attachment := range details.Attachments {
+		resp.Attachments = append(resp.Attachments, h.mapIncidentAttachment(attachment))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleChangeIncidentStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req IncidentStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	incident, err := h.Service.ChangeIncidentStatus(r.Context(), service.IncidentStatusInput{
+		ID:      id,
+		Status:  req.Status,
+		Comment: req.Comment,
+		ActorID: actorFromContext(r),
+	})
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapIncident(incident))
+}
+
+func (h *Handler) HandleAddIncidentComment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req IncidentCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	comment, err := h.Service.AddIncidentComment(r.Context(), service.IncidentCommentInput{
+		IncidentID: id,
+		AuthorID:   actorFromContext(r),
+		Body:       req.Body,
+	})
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapIncidentComment(comment, ""))
+}
+
+// HandleUploadIncidentAttachment accepts a multipart form with a "file"
+// field or the raw file as the body, named by the file_name query parameter.
+func (h *Handler) HandleUploadIncidentAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	var source io.Reader = r.Body
+	fileName := r.URL.Query().Get("file_name")
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "file field is required")
+			return
+		}
+		defer file.Close()
+		source, fileName = file, header.Filename
+	}
+	data, err := io.ReadAll(source)
+	if err != nil {
+		WriteError(w, http.StatusRequestEntityTooLarge, service.ErrAttachmentTooLarge.Error())
+		return
+	}
+	attachment, err := h.Service.AddIncidentAttachment(r.Context(), service.AttachmentInput{
+		IncidentID: id,
+		ActorID:    actorFromContext(r),
+		FileName:   fileName,
+		Data:       data,
+	})
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, h.mapIncidentAttachment(attachment))
+}
+
+func (h *Handler) HandleDeleteIncidentAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	attachmentID, err := uuid.Parse(chi.URLParam(r, "attachmentId"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid attachment id")
+		return
+	}
+	if err := h.Service.DeleteIncidentAttachment(r.Context(), id, attachmentID); err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleDownloadIncidentAttachment serves a file by a signed link, so it
+// sits outside the bearer-token routes.
+func (h *Handler) HandleDownloadIncidentAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.verifyFileLink(w, r)
+	if !ok {
+		return
+	}
+	attachment, content, err := h.Service.OpenIncidentAttachment(r.Context(), id)
+	if err != nil {
+		writeIncidentError(w, err)
+		return
+	}
+	defer content.Close()
+	writeFile(w, content, attachment.ContentType, attachment.SizeBytes, attachment.FileName)
+}
+
+// verifyFileLink checks the signed link a file is downloaded by and returns
+// the file id.
+func (h *Handler) verifyFileLink(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return uuid.Nil, false
+	}
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil || h.Service.VerifyFileLink(id, expires, r.URL.Query().Get("signature")) != nil {
+		WriteError(w, http.StatusForbidden, service.ErrFileLinkInvalid.Error())
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// writeFile sends a stored file for display in the browser; a negative size
+// leaves out Content-Length.
+func writeFile(w http.ResponseWriter, content io.Reader, contentType string, size int64, fileName string) {
+	w.Header().Set("Content-Type", contentType)
+	if size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": fileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+func writeIncidentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrInvalidIncidentCategory), errors.Is(err, service.ErrInvalidIncidentSeverity),
+		errors.Is(err, service.ErrInvalidIncidentStatus), errors.Is(err, service.ErrIncidentLink),
+		errors.Is(err, service.ErrUnknownGate), errors.Is(err, service.ErrInvalidRange):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidInput):
+		WriteError(w, http.StatusBadRequest, "invalid input")
+	case errors.Is(err, service.ErrIncidentTransition):
+		WriteError(w, http.StatusConflict, err.Error())
+	case writeUploadError(w, err):
+	default:
+		WriteError(w, http.StatusInternalServerError, "incident error")
+	}
+}
+
+// writeUploadError answers a file refused by the attachment rules; it
+// reports false for any other error.
+func writeUploadError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		WriteError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrAttachmentType):
+		WriteError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, service.ErrFilesDisabled):
+		WriteError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+func mapIncident(incident repo.Incident) IncidentResponse {
+	resp := IncidentResponse{
+		ID:         incident.ID,
+		Category:   incident.Category,
+		Severity:   incident.Severity,
+		Status:     incident.Status,
+		Title:      incident.Title,
+		OccurredAt: incident.OccurredAt,
+		ReportedBy: incident.ReportedBy,
+		CreatedAt:  incident.CreatedAt,
+		UpdatedAt:  incident.UpdatedAt,
+	}
+	if incident.Description.Valid {
+		resp.Description = &incident.Description.String
+	}
+	if incident.GateID.Valid {
+		resp.GateID = &incident.GateID.UUID
+	}
+	if incident.ShiftID.Valid {
+		resp.ShiftID = &incident.ShiftID.UUID
+	}
+	if incident.PassID.Valid {
+		resp.PassID = &incident.PassID.UUID
+	}
+	if incident.GuestRequestID.Valid {
+		resp.GuestRequestID = &incident.GuestRequestID.UUID
+	}
+	if incident.PlateNumber.Valid {
+		resp.PlateNumber = &incident.PlateNumber.String
+	}
+	if incident.ResolvedAt.Valid {
+		resp.ResolvedAt = &incident.ResolvedAt.Time
+	}
+	return resp
+}
+
+func mapIncidentComment(comment repo.IncidentComment, authorName string) IncidentCommentResponse {
+	resp := IncidentCommentResponse{
+		ID:             comment.ID,
+		AuthorID:       comment.AuthorID,
+		AuthorFullName: authorName,
+		Body:           comment.Body,
+		CreatedAt:      comment.CreatedAt,
+	}
+	if comment.StatusFrom.Valid {
+		resp.StatusFrom = &comment.StatusFrom.String
+	}
+	if comment.StatusTo.Valid {
+		resp.StatusTo = &comment.StatusTo.String
+	}
+	return resp
+}
+
+func (h *Handler) mapIncidentAttachment(attachment repo.IncidentAttachment) IncidentAttachmentResponse {
+	link := h.Service.SignFileLink(attachment.ID)
+	return IncidentAttachmentResponse{
+		ID:           attachment.ID,
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		SizeBytes:    attachment.SizeBytes,
+		UploadedBy:   attachment.UploadedBy,
+		CreatedAt:    attachment.CreatedAt,
+		DownloadURL:  "/incident-attachments/" + attachment.ID.String() + "?expires=" + strconv.FormatInt(link.Expires.Unix(), 10) + "&signature=" + link.Signature,
+		URLExpiresAt: link.Expires,
+	}
+}
@@ -6,12 +6,19 @@ package http
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,6 +28,8 @@ import (
 	"pipo-edu-project/internal/auth"
 	repo "pipo-edu-project/internal/repository/sqlc"
 	"pipo-edu-project/internal/service"
+	"pipo-edu-project/internal/storage"
+	"pipo-edu-project/internal/tabular"
 	"pipo-edu-project/internal/testutil"
 )
 
@@ -29,6 +38,7 @@ type testApp struct {
 	client       *http.Client
 	tokens       *auth.TokenManager
 	queries      *repo.Queries
+	db           *sql.DB
 	users        testutil.SeedUsers
 	adminAccess  string
 	guardAccess  string
@@ -43,7 +53,21 @@ func setupTestApp(t *testing.T) *testApp {
 
 	tdb := testutil.StartPostgres(t)
 	users := testutil.SeedDefaultUsers(t, tdb.Queries)
-	svc := service.New(tdb.Queries)
+	files, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	svc := service.New(tdb.Queries,
+		service.WithSettings(service.Settings{
+			GuestApproval: service.GuestApprovalRules{MaxDuration: 30 * time.Minute},
+			Attachments:   service.AttachmentRules{LinkSecret: []byte("test-files")},
+			Cascade: service.CascadeRules{
+				OnBlock:  service.CascadeSuspend,
+				OnDelete: service.CascadeRevoke,
+				Restore:  true,
+			},
+		}),
+		service.WithTxRunner(service.NewTxRunner(tdb.DB)),
+		service.WithFileStore(files),
+	)
 	tokens := auth.NewTokenManager("test-access", "test-refresh", time.Hour, 24*time.Hour)
 
 	adminAccess, adminRefresh, err := tokens.GenerateTokens(users.Admin.ID, auth.RoleAdmin)
@@ -67,6 +91,7 @@ func setupTestApp(t *testing.T) *testApp {
 		client:       server.Client(),
 		tokens:       tokens,
 		queries:      tdb.Queries,
+		db:           tdb.DB,
 		users:        users,
 		adminAccess:  adminAccess,
 		guardAccess:  guardAccess,
@@ -255,7 +280,7 @@ func TestAPIIntegration_AllRoutes(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
 		resp, _ = app.request(t, http.MethodDelete, "/users/"+createdUserID.String(), app.adminAccess, nil)
-		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
 
 		resp, _ = app.request(t, http.MethodDelete, "/users/not-a-uuid", app.adminAccess, nil)
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
@@ -279,6 +304,52 @@ func TestAPIIntegration_AllRoutes(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("users import routes", func(t *testing.T) {
+		csvData := "email;full_name;role;plot_number;password;plate_number;vehicle_brand\n" +
+			"import1@example.com;Импорт Один;resident;41;import123;A111AA77;Lada\n" +
+			"import1@example.com;;;;;B222BB77;\n" +
+			"import2@example.com;Импорт Два;resident;42;import123;bad;\n"
+
+		resp, _ := app.requestRaw(t, http.MethodPost, "/users/import", app.guardAccess, csvData)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, body := app.requestRaw(t, http.MethodPost, "/users/import", app.adminAccess, csvData)
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		var report ImportReportResponse
+		require.NoError(t, json.Unmarshal(body, &report))
+		require.False(t, report.Applied)
+		require.Len(t, report.Errors, 1)
+		require.Equal(t, 4, report.Errors[0].Line)
+		_, err := app.queries.GetUserByEmailAny(ctx, "import1@example.com")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		csvData = strings.Replace(csvData, ";bad;", ";C333CC77;", 1)
+		resp, body = app.requestRaw(t, http.MethodPost, "/users/import?dry_run=true", app.adminAccess, csvData)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &report))
+		require.True(t, report.DryRun)
+		require.Equal(t, 2, report.UsersCreated)
+		require.Equal(t, 3, report.PassesCreated)
+
+		resp, body = app.requestRaw(t, http.MethodPost, "/users/import", app.adminAccess, csvData)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &report))
+		require.True(t, report.Applied)
+
+		imported, err := app.queries.GetUserByEmail(ctx, "import1@example.com")
+		require.NoError(t, err)
+		require.Equal(t, "41", imported.PlotNumber.String)
+		passes, err := app.queries.ListPassesByOwner(ctx, repo.ListPassesByOwnerParams{OwnerUserID: imported.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, passes, 2)
+
+		resp, body = app.requestRaw(t, http.MethodPost, "/users/import", app.adminAccess, csvData)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &report))
+		require.Equal(t, 2, report.UsersUpdated)
+		require.Equal(t, 3, report.PassesUpdated)
+	})
+
 	t.Run("passes routes", func(t *testing.T) {
 		resp, _ := app.request(t, http.MethodPost, "/passes", app.guardAccess, map[string]string{
 			"plate_number": "A123BC77",
@@ -411,6 +482,90 @@ func TestAPIIntegration_AllRoutes(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("schedules and holidays routes", func(t *testing.T) {
+		schedulePath := "/passes/" + createdPassID.String() + "/schedules"
+		resp, _ := app.request(t, http.MethodPost, schedulePath, app.resAccess, map[string]interface{}{
+			"weekdays": []int{1, 2, 3, 4, 5, 6, 7},
+			"start":    "00:00",
+			"end":      "24:00",
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, schedulePath, app.adminAccess, map[string]interface{}{
+			"weekdays": []int{9},
+			"start":    "08:00",
+			"end":      "20:00",
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, body := app.request(t, http.MethodPost, schedulePath, app.adminAccess, map[string]interface{}{
+			"weekdays":      []int{1, 2, 3, 4, 5, 6, 7},
+			"start":         "00:00",
+			"end":           "24:00",
+			"skip_holidays": false,
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		scheduleID := parseUUIDField(t, body, "id")
+
+		resp, _ = app.request(t, http.MethodGet, schedulePath, app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, schedulePath, secondResidentToken, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/passes/search?plate=A123BC77", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var found []PassResponse
+		require.NoError(t, json.Unmarshal(body, &found))
+		require.NotEmpty(t, found)
+		require.NotNil(t, found[0].AccessWindow)
+		require.True(t, found[0].AccessWindow.Restricted)
+		require.True(t, found[0].AccessWindow.Within)
+
+		resp, _ = app.request(t, http.MethodDelete, schedulePath+"/"+scheduleID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodDelete, schedulePath+"/"+scheduleID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/holidays", app.guardAccess, map[string]string{
+			"day":  "2027-01-01",
+			"name": "Новый год",
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/holidays", app.adminAccess, map[string]string{
+			"day":  "01.01.2027",
+			"name": "Новый год",
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/holidays", app.adminAccess, map[string]string{
+			"day":  "2027-01-01",
+			"name": "Новый год",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/holidays?from=2027-01-01&to=2027-01-31", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var holidays []HolidayResponse
+		require.NoError(t, json.Unmarshal(body, &holidays))
+		require.Len(t, holidays, 1)
+		require.Equal(t, "2027-01-01", holidays[0].Day)
+
+		resp, _ = app.request(t, http.MethodGet, "/holidays?from=2027-02-01&to=2027-01-01", app.adminAccess, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, "/holidays", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodDelete, "/holidays/2027-01-01", app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodDelete, "/holidays/2027-01-01", app.adminAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
 	t.Run("guest requests routes", func(t *testing.T) {
 		now := time.Now().UTC()
 		resp, _ := app.request(t, http.MethodPost, "/guest-requests", app.guardAccess, map[string]interface{}{
@@ -442,6 +597,28 @@ func TestAPIIntegration_AllRoutes(t *testing.T) {
 		})
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
+		resp, _ = app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+			"guest_full_name": "Guest",
+			"plate_number":    "A123BC77",
+			"valid_from":      now.Add(-3 * time.Hour),
+			"valid_to":        now.Add(-2 * time.Hour),
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// Overlaps are allowed by default and reported back.
+		resp, body = app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+			"guest_full_name": "Guest again",
+			"plate_number":    "A123BC77",
+			"valid_from":      now.Add(90 * time.Minute),
+			"valid_to":        now.Add(3 * time.Hour),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var overlapping GuestResponse
+		require.NoError(t, json.Unmarshal(body, &overlapping))
+		require.Contains(t, overlapping.ConflictingRequestIDs, createdGuestID)
+		resp, _ = app.request(t, http.MethodPost, "/guest-requests/"+overlapping.ID.String()+"/cancel", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
 		resp, _ = app.request(t, http.MethodPost, "/guest-requests", app.adminAccess, map[string]interface{}{
 			"resident_user_id": app.users.Resident.ID.String(),
 			"guest_full_name":  "Guest 2",
@@ -509,6 +686,1407 @@ func TestAPIIntegration_AllRoutes(t *testing.T) {
 		resp, _ = app.request(t, http.MethodPost, "/guest-requests/not-a-uuid/restore", app.adminAccess, nil)
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
+
+	t.Run("export routes", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodGet, "/users/export?includeDeleted=true", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Disposition"), "users-")
+		rows, err := tabular.Read(body)
+		require.NoError(t, err)
+		require.Equal(t, "E-mail", rows[0][1])
+		require.Greater(t, len(rows), 3)
+
+		resp, body = app.request(t, http.MethodGet, "/passes/export?format=xlsx&lang=en", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		rows, err = tabular.Read(body)
+		require.NoError(t, err)
+		require.Equal(t, "Owner", rows[0][5])
+		require.Greater(t, len(rows), 1)
+
+		resp, body = app.request(t, http.MethodGet, "/passes/export", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		rows, err = tabular.Read(body)
+		require.NoError(t, err)
+		for _, row := range rows[1:] {
+			require.Equal(t, app.users.Resident.Email, row[6])
+		}
+
+		resp, _ = app.request(t, http.MethodGet, "/passes/export", app.guardAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/guest-requests/export", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		rows, err = tabular.Read(body)
+		require.NoError(t, err)
+		require.Equal(t, "Гость", rows[0][1])
+
+		resp, _ = app.request(t, http.MethodGet, "/entry-logs/export?from=2020-01-01", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, "/entry-logs/export", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, "/entry-logs/export?from=2025-02-01&to=2025-01-01", app.adminAccess, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, "/users/export?format=pdf", app.adminAccess, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("watchlist routes", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodPost, "/passes", app.adminAccess, map[string]interface{}{
+			"owner_user_id": app.users.Resident.ID.String(),
+			"plate_number":  "X999XX99",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		passID := parseUUIDField(t, body, "id")
+
+		resp, _ = app.request(t, http.MethodPost, "/watchlist", app.guardAccess, map[string]string{
+			"plate_number": "X999XX99", "severity": "blacklist", "reason": "banned",
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, "/watchlist", app.adminAccess, map[string]string{
+			"plate_number": "x999xx99", "severity": "blacklist", "reason": "banned ex-tenant",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		watchID := parseUUIDField(t, body, "id")
+
+		resp, _ = app.request(t, http.MethodPost, "/watchlist", app.adminAccess, map[string]string{
+			"plate_number": "X999XX99", "severity": "info", "reason": "duplicate",
+		})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/watchlist", app.adminAccess, map[string]interface{}{
+			"plate_number": "C333CC77", "severity": "info", "reason": "past", "expires_at": time.Now().Add(-time.Hour),
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/passes/search?plate=X999XX99", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var found []map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &found))
+		require.Len(t, found, 1)
+		flag, ok := found[0]["watchlist"].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, "blacklist", flag["severity"])
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+passID.String()+"/entry", app.guardAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+passID.String()+"/entry", app.guardAccess, map[string]interface{}{
+			"override": true, "comment": "let in",
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+passID.String()+"/entry", app.adminAccess, map[string]interface{}{
+			"override": true,
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+passID.String()+"/entry", app.adminAccess, map[string]interface{}{
+			"override": true, "comment": "police escort",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		// The car is on site now, so the journal refuses a second entry and
+		// its override is not logged.
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+passID.String()+"/entry", app.adminAccess, map[string]interface{}{
+			"override": true, "comment": "again",
+		})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/watchlist/"+watchID.String()+"/hits", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var hits []map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &hits))
+		require.Len(t, hits, 3)
+		require.Equal(t, "overridden", hits[0]["outcome"])
+
+		resp, body = app.request(t, http.MethodPatch, "/watchlist/"+watchID.String(), app.adminAccess, map[string]interface{}{
+			"severity": "warning",
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, string(body), `"severity":"warning"`)
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+passID.String()+"/exit", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+passID.String()+"/entry", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, "/watchlist", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, "/watchlist/"+watchID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodDelete, "/watchlist/"+watchID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodDelete, "/watchlist/"+watchID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodGet, "/watchlist/"+watchID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("guest review workflow", func(t *testing.T) {
+		now := time.Now().UTC()
+		resp, body := app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+			"guest_full_name": "Courier",
+			"plate_number":    "B456CE99",
+			"valid_from":      now.Add(1 * time.Hour),
+			"valid_to":        now.Add(80 * time.Minute),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var guest GuestResponse
+		require.NoError(t, json.Unmarshal(body, &guest))
+		require.Equal(t, "approved", guest.Status)
+		require.NotNil(t, guest.ReviewReason)
+		require.Nil(t, guest.ReviewedBy)
+
+		resp, body = app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+			"guest_full_name": "Relatives",
+			"plate_number":    "C333CC77",
+			"valid_from":      now.Add(1 * time.Hour),
+			"valid_to":        now.Add(6 * time.Hour),
+			"status":          "approved",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		pendingID := parseUUIDField(t, body, "id")
+		path := "/guest-requests/" + pendingID.String()
+
+		resp, _ = app.request(t, http.MethodPost, path+"/approve", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/reject", app.adminAccess, map[string]string{})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, path+"/approve", app.adminAccess, map[string]string{"reason": "family"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &guest))
+		require.Equal(t, "approved", guest.Status)
+		require.NotNil(t, guest.ReviewedBy)
+		require.Equal(t, app.users.Admin.ID, *guest.ReviewedBy)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/reject", app.adminAccess, map[string]string{"reason": "changed mind"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPatch, path, app.resAccess, map[string]interface{}{"guest_full_name": "Cousins"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPatch, path, app.adminAccess, map[string]interface{}{"status": "completed"})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, path+"/cancel", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &guest))
+		require.Equal(t, "cancelled", guest.Status)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/cancel", app.resAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPatch, path, app.adminAccess, map[string]interface{}{"guest_full_name": "Cousins"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("expected guests for guards", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodGet, "/guest-requests/expected", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var expected []GateGuestResponse
+		require.NoError(t, json.Unmarshal(body, &expected))
+		var courier *GateGuestResponse
+		for i := range expected {
+			require.Equal(t, "approved", expected[i].Status)
+			if expected[i].PlateNumber == "B456CE99" {
+				courier = &expected[i]
+			}
+		}
+		require.NotNil(t, courier)
+		require.Equal(t, app.users.Resident.FullName, courier.ResidentFullName)
+
+		resp, body = app.request(t, http.MethodGet, "/guest-requests/search?plate=b456ce99", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var found []GateGuestResponse
+		require.NoError(t, json.Unmarshal(body, &found))
+		require.Len(t, found, 1)
+		require.Equal(t, courier.ID, found[0].ID)
+
+		resp, body = app.request(t, http.MethodGet, "/guest-requests/search?plate=C333CC77", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.JSONEq(t, "[]", string(body))
+
+		resp, _ = app.request(t, http.MethodGet, "/guest-requests/expected", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("guest check-in and check-out", func(t *testing.T) {
+		now := time.Now().UTC()
+		resp, body := app.request(t, http.MethodPost, "/guest-requests", app.adminAccess, map[string]interface{}{
+			"resident_user_id": app.users.Resident.ID,
+			"guest_full_name":  "Plumber",
+			"plate_number":     "K555KK77",
+			"valid_from":       now.Add(-10 * time.Minute),
+			"valid_to":         now.Add(1 * time.Hour),
+			"status":           "approved",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		guestID := parseUUIDField(t, body, "id")
+		path := "/guest-requests/" + guestID.String()
+
+		resp, body = app.request(t, http.MethodPost, "/guest-requests", app.adminAccess, map[string]interface{}{
+			"resident_user_id": app.users.Resident.ID,
+			"guest_full_name":  "Late guest",
+			"plate_number":     "K555KK77",
+			"valid_from":       now.Add(2 * time.Hour),
+			"valid_to":         now.Add(3 * time.Hour),
+			"status":           "approved",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		lateID := parseUUIDField(t, body, "id")
+
+		resp, _ = app.request(t, http.MethodPost, "/guest-requests/"+lateID.String()+"/check-in", app.guardAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, path+"/check-out", app.guardAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, path+"/check-in", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, path+"/check-in", app.guardAccess, map[string]string{"comment": "gate 1"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var entry EntryLogResponse
+		require.NoError(t, json.Unmarshal(body, &entry))
+		require.Nil(t, entry.PassID)
+		require.NotNil(t, entry.GuestRequestID)
+		require.Equal(t, guestID, *entry.GuestRequestID)
+		require.Equal(t, app.users.Guard.ID, entry.GuardUserID)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/check-in", app.guardAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/guest-requests/search?plate=K555KK77", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var found []GateGuestResponse
+		require.NoError(t, json.Unmarshal(body, &found))
+		require.Len(t, found, 1)
+		require.Equal(t, "arrived", found[0].Status)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/check-out", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, path, app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var guest GuestResponse
+		require.NoError(t, json.Unmarshal(body, &guest))
+		require.Equal(t, "completed", guest.Status)
+
+		resp, body = app.request(t, http.MethodGet, "/entry-logs/export", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		rows, err := tabular.Read(body)
+		require.NoError(t, err)
+		var visits int
+		for _, row := range rows[1:] {
+			if row[4] == "Plumber" {
+				require.Equal(t, "K555KK77", row[3])
+				visits++
+			}
+		}
+		require.Equal(t, 2, visits)
+	})
+
+	t.Run("guest PIN check-in", func(t *testing.T) {
+		now := time.Now().UTC()
+		resp, body := app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+			"guest_full_name": "Courier",
+			"plate_number":    "T222TT77",
+			"valid_from":      now.Add(-10 * time.Minute),
+			"valid_to":        now.Add(time.Hour),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		guestID := parseUUIDField(t, body, "id")
+		path := "/guest-requests/" + guestID.String()
+
+		resp, _ = app.request(t, http.MethodGet, path+"/pin", app.resAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, path+"/approve", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodGet, path+"/pin", app.guardAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, path+"/pin", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var first GuestPinResponse
+		require.NoError(t, json.Unmarshal(body, &first))
+		require.Len(t, first.Pin, 6)
+		resp, body = app.request(t, http.MethodGet, path+"/pin", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var same GuestPinResponse
+		require.NoError(t, json.Unmarshal(body, &same))
+		require.Equal(t, first.Pin, same.Pin)
+
+		resp, body = app.request(t, http.MethodPost, path+"/pin", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var pin GuestPinResponse
+		require.NoError(t, json.Unmarshal(body, &pin))
+		if pin.Pin != first.Pin {
+			resp, _ = app.request(t, http.MethodPost, "/guest-requests/check-in-by-pin", app.guardAccess, map[string]string{"pin": first.Pin})
+			require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		}
+
+		resp, body = app.request(t, http.MethodPost, "/guest-requests/check-in-by-pin", app.guardAccess, map[string]string{
+			"pin":          pin.Pin,
+			"plate_number": "T333TT77",
+			"comment":      "came by taxi",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var checkIn GuestPinCheckInResponse
+		require.NoError(t, json.Unmarshal(body, &checkIn))
+		require.Equal(t, guestID, checkIn.Guest.ID)
+		require.Equal(t, "arrived", checkIn.Guest.Status)
+		require.Equal(t, app.users.Guard.ID, checkIn.Entry.GuardUserID)
+
+		// The PIN is single use.
+		resp, _ = app.request(t, http.MethodPost, "/guest-requests/check-in-by-pin", app.guardAccess, map[string]string{"pin": pin.Pin})
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodGet, path+"/pin", app.resAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("guest types", func(t *testing.T) {
+		now := time.Now().UTC()
+		resp, body := app.request(t, http.MethodPost, "/guest-requests", app.adminAccess, map[string]interface{}{
+			"resident_user_id": app.users.Resident.ID,
+			"guest_full_name":  "Neighbour",
+			"guest_type":       "pedestrian",
+			"plate_number":     "E111EE77",
+			"valid_from":       now.Add(-10 * time.Minute),
+			"valid_to":         now.Add(time.Hour),
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, "/guest-requests", app.adminAccess, map[string]interface{}{
+			"resident_user_id": app.users.Resident.ID,
+			"guest_full_name":  "Neighbour",
+			"guest_type":       "pedestrian",
+			"valid_from":       now.Add(-10 * time.Minute),
+			"valid_to":         now.Add(time.Hour),
+			"status":           "approved",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		pedestrianID := parseUUIDField(t, body, "id")
+
+		resp, body = app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+			"guest_full_name": "Driver",
+			"guest_type":      "taxi",
+			"valid_from":      now.Add(time.Hour),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var taxi GuestResponse
+		require.NoError(t, json.Unmarshal(body, &taxi))
+		require.Equal(t, "taxi", taxi.GuestType)
+		require.Empty(t, taxi.PlateNumber)
+		require.WithinDuration(t, taxi.ValidFrom.Add(30*time.Minute), taxi.ValidTo, time.Second)
+
+		resp, _ = app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+			"guest_full_name": "Courier",
+			"guest_type":      "delivery",
+			"valid_from":      now.Add(time.Hour),
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/guest-requests/expected?type=pedestrian", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var expected []GateGuestResponse
+		require.NoError(t, json.Unmarshal(body, &expected))
+		require.Len(t, expected, 1)
+		require.Equal(t, pedestrianID, expected[0].ID)
+		require.Equal(t, "pedestrian", expected[0].GuestType)
+		require.Empty(t, expected[0].PlateNumber)
+		resp, _ = app.request(t, http.MethodGet, "/guest-requests/expected?type=bicycle", app.guardAccess, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, "/guest-requests/"+pedestrianID.String()+"/check-in", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("saved guests", func(t *testing.T) {
+		now := time.Now().UTC()
+		for i := 0; i < 2; i++ {
+			resp, _ := app.request(t, http.MethodPost, "/guest-requests", app.resAccess, map[string]interface{}{
+				"guest_full_name": "Gardener",
+				"plate_number":    "H888HH77",
+				"valid_from":      now.Add(time.Duration(24*(i+1)) * time.Hour),
+				"valid_to":        now.Add(time.Duration(24*(i+1)+2) * time.Hour),
+			})
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+		}
+		findGardener := func() *GuestSuggestionResponse {
+			resp, body := app.request(t, http.MethodGet, "/saved-guests/suggestions", app.resAccess, nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			var suggestions []GuestSuggestionResponse
+			require.NoError(t, json.Unmarshal(body, &suggestions))
+			for i := range suggestions {
+				if suggestions[i].GuestFullName == "Gardener" {
+					return &suggestions[i]
+				}
+			}
+			return nil
+		}
+		gardener := findGardener()
+		require.NotNil(t, gardener)
+		require.EqualValues(t, 2, gardener.Visits)
+		require.Equal(t, "H888HH77", gardener.PlateNumber)
+
+		resp, body := app.request(t, http.MethodPost, "/saved-guests", app.resAccess, map[string]string{
+			"guest_full_name": "gardener",
+			"plate_number":    "h888hh77",
+			"notes":           "every other Monday",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var saved SavedGuestResponse
+		require.NoError(t, json.Unmarshal(body, &saved))
+		require.Equal(t, "H888HH77", saved.PlateNumber)
+		require.Nil(t, findGardener())
+
+		path := "/saved-guests/" + saved.ID.String()
+		resp, _ = app.request(t, http.MethodGet, path, app.adminAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, body = app.request(t, http.MethodPatch, path, app.resAccess, map[string]string{"guest_full_name": "Gardener"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &saved))
+		require.Equal(t, "Gardener", saved.GuestFullName)
+		require.NotNil(t, saved.Notes)
+
+		resp, body = app.request(t, http.MethodGet, "/saved-guests", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var book []SavedGuestResponse
+		require.NoError(t, json.Unmarshal(body, &book))
+		require.Len(t, book, 1)
+
+		resp, body = app.request(t, http.MethodPost, path+"/guest-requests", app.resAccess, map[string]interface{}{
+			"valid_from": now.Add(72 * time.Hour),
+			"valid_to":   now.Add(74 * time.Hour),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var guest GuestResponse
+		require.NoError(t, json.Unmarshal(body, &guest))
+		require.Equal(t, "Gardener", guest.GuestFullName)
+		require.Equal(t, "H888HH77", guest.PlateNumber)
+		require.Equal(t, app.users.Resident.ID, guest.ResidentUserID)
+		require.Equal(t, "pending", guest.Status)
+
+		resp, _ = app.request(t, http.MethodDelete, path, app.resAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodGet, path, app.resAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("entry log journal", func(t *testing.T) {
+		resp, _ := app.request(t, http.MethodGet, "/entry-logs", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, body := app.request(t, http.MethodGet, "/entry-logs?action=entry&plate=a123&guard_id="+app.users.Guard.ID.String(), app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var logs []EntryLogRecordResponse
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.NotEmpty(t, logs)
+		for _, entry := range logs {
+			require.Equal(t, "entry", entry.Action)
+			require.Contains(t, entry.PlateNumber, "A123")
+			require.Equal(t, app.users.Guard.ID, entry.GuardUserID)
+			require.NotEmpty(t, entry.GuardFullName)
+			require.NotEmpty(t, entry.OwnerFullName)
+		}
+
+		resp, body = app.request(t, http.MethodGet, "/entry-logs?action=exit&plate=A123BC77", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &logs))
+		for _, entry := range logs {
+			require.Equal(t, "exit", entry.Action)
+		}
+
+		resp, _ = app.request(t, http.MethodGet, "/entry-logs?from=2030-01-02&to=2030-01-01", app.adminAccess, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		path := "/passes/" + createdPassID.String() + "/entry-logs"
+		resp, body = app.request(t, http.MethodGet, path, app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.NotEmpty(t, logs)
+		for _, entry := range logs {
+			require.NotNil(t, entry.PassID)
+			require.Equal(t, createdPassID, *entry.PassID)
+		}
+		resp, body = app.request(t, http.MethodGet, path+"?from=2000-01-01&to=2000-01-02", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.Empty(t, logs)
+	})
+
+	t.Run("on-site presence", func(t *testing.T) {
+		pass := "/passes/" + createdPassID.String()
+		findPass := func() *OnSiteResponse {
+			resp, body := app.request(t, http.MethodGet, "/presence", app.guardAccess, nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			var onSite []OnSiteResponse
+			require.NoError(t, json.Unmarshal(body, &onSite))
+			for i := range onSite {
+				if onSite[i].PassID != nil && *onSite[i].PassID == createdPassID {
+					return &onSite[i]
+				}
+			}
+			return nil
+		}
+		require.Nil(t, findPass())
+
+		resp, body := app.request(t, http.MethodPost, pass+"/exit", app.guardAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		var conflict PresenceConflictResponse
+		require.NoError(t, json.Unmarshal(body, &conflict))
+		require.Equal(t, "exit_without_entry", conflict.Anomaly)
+
+		resp, body = app.request(t, http.MethodPost, pass+"/exit", app.guardAccess, map[string]string{"override_reason": "entered through the side gate"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var entry EntryLogResponse
+		require.NoError(t, json.Unmarshal(body, &entry))
+		require.NotNil(t, entry.Anomaly)
+		require.Equal(t, "exit_without_entry", *entry.Anomaly)
+
+		resp, _ = app.request(t, http.MethodPost, pass+"/entry", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		onSite := findPass()
+		require.NotNil(t, onSite)
+		require.Equal(t, "A123BC77", onSite.PlateNumber)
+		require.NotEmpty(t, onSite.OwnerFullName)
+		require.GreaterOrEqual(t, onSite.DurationSeconds, int64(0))
+
+		resp, body = app.request(t, http.MethodPost, pass+"/entry", app.guardAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &conflict))
+		require.Equal(t, "double_entry", conflict.Anomaly)
+		require.NotNil(t, conflict.EnteredAt)
+
+		resp, body = app.request(t, http.MethodGet, "/entry-logs?plate=A123BC77&action=exit", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var logs []EntryLogRecordResponse
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.NotEmpty(t, logs)
+		require.NotNil(t, logs[0].OverrideReason)
+		require.Equal(t, "entered through the side gate", *logs[0].OverrideReason)
+
+		resp, _ = app.request(t, http.MethodPost, pass+"/exit", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Nil(t, findPass())
+
+		resp, _ = app.request(t, http.MethodGet, "/presence", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("entry log chain and amendments", func(t *testing.T) {
+		verify := func() ChainReportResponse {
+			resp, body := app.request(t, http.MethodGet, "/entry-logs/verify", app.adminAccess, nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			var report ChainReportResponse
+			require.NoError(t, json.Unmarshal(body, &report))
+			return report
+		}
+		report := verify()
+		require.True(t, report.OK)
+		require.Positive(t, report.Checked)
+		require.Equal(t, report.Checked, report.HeadSeq)
+
+		resp, body := app.request(t, http.MethodPost, "/passes/"+createdPassID.String()+"/entry", app.guardAccess, map[string]string{"override_reason": "chain check"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var entry EntryLogResponse
+		require.NoError(t, json.Unmarshal(body, &entry))
+		require.Equal(t, report.HeadSeq+1, entry.Seq)
+
+		resp, body = app.request(t, http.MethodPost, "/entry-logs/"+entry.ID.String()+"/amendments", app.guardAccess, map[string]string{"reason": "pressed entry by mistake", "action": "void"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var amendment EntryLogResponse
+		require.NoError(t, json.Unmarshal(body, &amendment))
+		require.Equal(t, "amendment", amendment.Action)
+		require.NotNil(t, amendment.AmendsID)
+		require.Equal(t, entry.ID, *amendment.AmendsID)
+
+		resp, _ = app.request(t, http.MethodPost, "/entry-logs/"+amendment.ID.String()+"/amendments", app.adminAccess, map[string]string{"reason": "again"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/entry-logs?action=amendment", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var logs []EntryLogRecordResponse
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.Len(t, logs, 1)
+		require.NotNil(t, logs[0].CorrectedAction)
+		require.Equal(t, "void", *logs[0].CorrectedAction)
+
+		report = verify()
+		require.True(t, report.OK)
+		require.Equal(t, amendment.Seq, report.HeadSeq)
+		require.Equal(t, amendment.Hash, report.HeadHash)
+
+		ctx := context.Background()
+		_, err := app.db.ExecContext(ctx, `UPDATE entry_logs SET comment = 'nothing happened' WHERE id = $1`, entry.ID)
+		require.NoError(t, err)
+		report = verify()
+		require.False(t, report.OK)
+		require.NotNil(t, report.Break)
+		require.Equal(t, entry.ID, report.Break.ID)
+		require.Equal(t, "hash_mismatch", report.Break.Reason)
+		_, err = app.db.ExecContext(ctx, `UPDATE entry_logs SET comment = NULL WHERE id = $1`, entry.ID)
+		require.NoError(t, err)
+		require.True(t, verify().OK)
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+createdPassID.String()+"/exit", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("gates", func(t *testing.T) {
+		createGate := func(name string) GateResponse {
+			resp, body := app.request(t, http.MethodPost, "/gates", app.adminAccess, map[string]string{"name": name})
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+			var gate GateResponse
+			require.NoError(t, json.Unmarshal(body, &gate))
+			return gate
+		}
+		mainGate := createGate("Main gate")
+		serviceGate := createGate("Service gate")
+		resp, _ := app.request(t, http.MethodPost, "/gates", app.adminAccess, map[string]string{"name": "main GATE"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		passGates := "/passes/" + createdPassID.String() + "/gates"
+		resp, _ = app.request(t, http.MethodPut, passGates, app.adminAccess, map[string]interface{}{"gate_ids": []uuid.UUID{serviceGate.ID}})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		entryPath := "/passes/" + createdPassID.String() + "/entry"
+		resp, _ = app.request(t, http.MethodPost, entryPath, app.guardAccess, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, entryPath, app.guardAccess, map[string]interface{}{"gate_id": mainGate.ID})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, body := app.request(t, http.MethodPost, entryPath, app.guardAccess, map[string]interface{}{"gate_id": serviceGate.ID})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var entry EntryLogResponse
+		require.NoError(t, json.Unmarshal(body, &entry))
+		require.NotNil(t, entry.GateID)
+		require.Equal(t, serviceGate.ID, *entry.GateID)
+
+		guardGates := "/users/" + app.users.Guard.ID.String() + "/gates"
+		resp, _ = app.request(t, http.MethodPut, guardGates, app.adminAccess, map[string]interface{}{"gate_ids": []uuid.UUID{mainGate.ID}})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPut, "/users/"+app.users.Resident.ID.String()+"/gates", app.adminAccess, map[string]interface{}{"gate_ids": []uuid.UUID{mainGate.ID}})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		exitPath := "/passes/" + createdPassID.String() + "/exit"
+		resp, _ = app.request(t, http.MethodPost, exitPath, app.guardAccess, map[string]interface{}{"gate_id": serviceGate.ID})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPut, guardGates, app.adminAccess, map[string]interface{}{"gate_ids": []uuid.UUID{}})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, exitPath, app.guardAccess, map[string]interface{}{"gate_id": serviceGate.ID})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, "/entry-logs?gate_id="+serviceGate.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var logs []EntryLogRecordResponse
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.Len(t, logs, 2)
+		require.NotNil(t, logs[0].GateName)
+		require.Equal(t, "Service gate", *logs[0].GateName)
+
+		resp, body = app.request(t, http.MethodGet, "/gates/stats", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var stats []GateStatsResponse
+		require.NoError(t, json.Unmarshal(body, &stats))
+		byGate := map[uuid.UUID]GateStatsResponse{}
+		for _, row := range stats {
+			byGate[row.GateID] = row
+		}
+		require.Equal(t, int64(1), byGate[serviceGate.ID].Entries)
+		require.Equal(t, int64(1), byGate[serviceGate.ID].Exits)
+		require.Zero(t, byGate[mainGate.ID].Entries)
+
+		resp, _ = app.request(t, http.MethodDelete, "/gates/"+serviceGate.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPut, passGates, app.adminAccess, map[string]interface{}{"gate_ids": []uuid.UUID{}})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodDelete, "/gates/"+serviceGate.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp, body = app.request(t, http.MethodGet, "/gates", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var gates []GateResponse
+		require.NoError(t, json.Unmarshal(body, &gates))
+		for _, gate := range gates {
+			require.NotEqual(t, serviceGate.ID, gate.ID)
+		}
+
+		resp, body = app.request(t, http.MethodGet, "/entry-logs/verify", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var report ChainReportResponse
+		require.NoError(t, json.Unmarshal(body, &report))
+		require.True(t, report.OK)
+	})
+
+	t.Run("guard shifts", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodPost, "/gates", app.adminAccess, map[string]string{"name": "Night gate"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var gate GateResponse
+		require.NoError(t, json.Unmarshal(body, &gate))
+
+		resp, _ = app.request(t, http.MethodGet, "/shifts/current", app.guardAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, body = app.request(t, http.MethodPost, "/shifts", app.guardAccess, map[string]interface{}{"gate_id": gate.ID})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var first ShiftResponse
+		require.NoError(t, json.Unmarshal(body, &first))
+		require.Nil(t, first.Handover)
+		resp, _ = app.request(t, http.MethodPost, "/shifts", app.guardAccess, map[string]interface{}{"gate_id": gate.ID})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, "/passes/"+createdPassID.String()+"/entry", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var entry EntryLogResponse
+		require.NoError(t, json.Unmarshal(body, &entry))
+		require.NotNil(t, entry.ShiftID)
+		require.Equal(t, first.ID, *entry.ShiftID)
+		require.NotNil(t, entry.GateID)
+		require.Equal(t, gate.ID, *entry.GateID)
+
+		resp, body = app.request(t, http.MethodGet, "/shifts/current", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var current ShiftResponse
+		require.NoError(t, json.Unmarshal(body, &current))
+		require.Equal(t, int64(1), current.Summary.Entries)
+		require.Positive(t, current.Summary.VehiclesOnSite)
+
+		resp, body = app.request(t, http.MethodPost, "/shifts/current/end", app.guardAccess, map[string]string{"handover_note": "Barrier sticks in the rain"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var ended ShiftResponse
+		require.NoError(t, json.Unmarshal(body, &ended))
+		require.NotNil(t, ended.EndedAt)
+		require.Equal(t, current.Summary.VehiclesOnSite, ended.Summary.VehiclesOnSite)
+		resp, _ = app.request(t, http.MethodPost, "/shifts/current/end", app.guardAccess, map[string]string{})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, "/shifts", app.guardAccess, map[string]interface{}{"gate_id": gate.ID})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var second ShiftResponse
+		require.NoError(t, json.Unmarshal(body, &second))
+		require.NotNil(t, second.Handover)
+		require.Equal(t, first.ID, second.Handover.ShiftID)
+		require.Equal(t, "Barrier sticks in the rain", *second.Handover.Note)
+
+		resp, _ = app.request(t, http.MethodPost, "/passes/"+createdPassID.String()+"/exit", app.guardAccess, nil)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp, body = app.request(t, http.MethodGet, "/shifts?open=true&guard_id="+app.users.Guard.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var open []ShiftListItemResponse
+		require.NoError(t, json.Unmarshal(body, &open))
+		require.Len(t, open, 1)
+		require.Equal(t, second.ID, open[0].ID)
+		require.NotNil(t, open[0].GateName)
+		require.Equal(t, "Night gate", *open[0].GateName)
+
+		resp, body = app.request(t, http.MethodPost, "/shifts/"+second.ID.String()+"/end", app.adminAccess, map[string]string{})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &ended))
+		require.Equal(t, int64(1), ended.Summary.Exits)
+		require.Nil(t, ended.HandoverNote)
+		resp, body = app.request(t, http.MethodGet, "/shifts/"+first.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var report ShiftResponse
+		require.NoError(t, json.Unmarshal(body, &report))
+		require.Equal(t, int64(1), report.Summary.Entries)
+		require.Zero(t, report.Summary.Exits)
+
+		resp, _ = app.request(t, http.MethodDelete, "/gates/"+gate.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("incidents", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodPost, "/incidents", app.guardAccess, map[string]interface{}{
+			"category": "unknown_vehicle",
+			"severity": "medium",
+			"title":    "Car parked at the fire lane",
+			"pass_id":  createdPassID,
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var incident IncidentResponse
+		require.NoError(t, json.Unmarshal(body, &incident))
+		require.Equal(t, "open", incident.Status)
+		require.NotNil(t, incident.PlateNumber)
+		resp, _ = app.request(t, http.MethodPost, "/incidents", app.guardAccess, map[string]string{"category": "fire", "severity": "low", "title": "x"})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodGet, "/incidents", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		path := "/incidents/" + incident.ID.String()
+		png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+		resp, body = app.requestRaw(t, http.MethodPost, path+"/attachments?file_name=car.png", app.guardAccess, png)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var attachment IncidentAttachmentResponse
+		require.NoError(t, json.Unmarshal(body, &attachment))
+		require.Equal(t, "image/png", attachment.ContentType)
+		require.Equal(t, "car.png", attachment.FileName)
+		resp, _ = app.requestRaw(t, http.MethodPost, path+"/attachments?file_name=notes.txt", app.guardAccess, "plain text")
+		require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+		download, err := app.client.Get(app.server.URL + attachment.DownloadURL)
+		require.NoError(t, err)
+		var content bytes.Buffer
+		_, err = content.ReadFrom(download.Body)
+		require.NoError(t, err)
+		_ = download.Body.Close()
+		require.Equal(t, http.StatusOK, download.StatusCode)
+		require.Equal(t, "image/png", download.Header.Get("Content-Type"))
+		require.Equal(t, png, content.String())
+		resp, _ = app.request(t, http.MethodGet, "/incident-attachments/"+attachment.ID.String()+"?expires=4102444800&signature=bad", "", nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/status", app.guardAccess, map[string]string{"status": "resolved"})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, body = app.request(t, http.MethodPost, path+"/status", app.adminAccess, map[string]string{"status": "resolved", "comment": "Towed"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &incident))
+		require.Equal(t, "resolved", incident.Status)
+		require.NotNil(t, incident.ResolvedAt)
+		resp, _ = app.request(t, http.MethodPost, path+"/status", app.adminAccess, map[string]string{"status": "in_progress"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, path+"/comments", app.adminAccess, map[string]string{"body": "Owner warned"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, path, app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &incident))
+		require.Len(t, incident.Comments, 2)
+		require.Len(t, incident.Attachments, 1)
+
+		resp, body = app.request(t, http.MethodGet, "/incidents?status=resolved&category=unknown_vehicle", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var list []IncidentListItemResponse
+		require.NoError(t, json.Unmarshal(body, &list))
+		require.Len(t, list, 1)
+		require.Equal(t, int64(1), list[0].Attachments)
+
+		resp, _ = app.request(t, http.MethodDelete, path+"/attachments/"+attachment.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodGet, attachment.DownloadURL, "", nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("entry photos", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 640, 480))
+		var photo bytes.Buffer
+		require.NoError(t, png.Encode(&photo, img))
+		var upload bytes.Buffer
+		form := multipart.NewWriter(&upload)
+		require.NoError(t, form.WriteField("payload", `{"comment":"photo at the gate"}`))
+		part, err := form.CreateFormFile("photo", "car.png")
+		require.NoError(t, err)
+		_, err = part.Write(photo.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+		req, err := http.NewRequest(http.MethodPost, app.server.URL+"/passes/"+createdPassID.String()+"/entry", &upload)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+app.guardAccess)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		resp, err := app.client.Do(req)
+		require.NoError(t, err)
+		var entry EntryLogResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entry))
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.NotNil(t, entry.Photo)
+		require.Equal(t, "photo at the gate", *entry.Comment)
+
+		resp, body := app.request(t, http.MethodGet, "/passes/"+createdPassID.String()+"/entry-logs?action=entry", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var logs []EntryLogRecordResponse
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.NotEmpty(t, logs)
+		require.Equal(t, entry.ID, logs[0].ID)
+		require.NotNil(t, logs[0].Photo)
+		require.Equal(t, entry.Photo.ID, logs[0].Photo.ID)
+
+		resp, content := app.request(t, http.MethodGet, logs[0].Photo.URL, "", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, photo.Bytes(), content)
+		resp, content = app.request(t, http.MethodGet, logs[0].Photo.ThumbnailURL, "", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		thumb, err := jpeg.Decode(bytes.NewReader(content))
+		require.NoError(t, err)
+		require.Equal(t, 320, thumb.Bounds().Dx())
+
+		resp, _ = app.requestRaw(t, http.MethodPost, "/passes/"+createdPassID.String()+"/exit", app.guardAccess, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("plate recognition cameras", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodPost, "/cameras", app.adminAccess, map[string]interface{}{
+			"name":             "Entry camera",
+			"direction":        "entry",
+			"operator_user_id": app.users.Resident.ID,
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp, body = app.request(t, http.MethodPost, "/cameras", app.adminAccess, map[string]interface{}{
+			"name":             "Entry camera",
+			"direction":        "entry",
+			"operator_user_id": app.users.Guard.ID,
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var camera CameraResponse
+		require.NoError(t, json.Unmarshal(body, &camera))
+		require.NotEmpty(t, camera.Token)
+
+		sendRead := func(token string, payload map[string]interface{}) (*http.Response, []byte) {
+			raw, err := json.Marshal(payload)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, app.server.URL+"/camera/plate-reads", bytes.NewReader(raw))
+			require.NoError(t, err)
+			req.Header.Set(CameraTokenHeader, token)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			content, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return resp, content
+		}
+		resp, _ = sendRead("wrong", map[string]interface{}{"plate": "A123BC77", "confidence": 0.95})
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp, body = sendRead(camera.Token, map[string]interface{}{"plate": "a 123 bc 77", "confidence": 0.95})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var decision PlateDecisionResponse
+		require.NoError(t, json.Unmarshal(body, &decision))
+		require.Equal(t, "allow", decision.Decision)
+		require.Equal(t, "A123BC77", decision.PlateNumber)
+		require.NotNil(t, decision.PassID)
+		require.Equal(t, createdPassID, *decision.PassID)
+		require.NotNil(t, decision.EntryLogID)
+
+		resp, body = app.request(t, http.MethodGet, "/passes/"+createdPassID.String()+"/entry-logs?action=entry", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var logs []EntryLogRecordResponse
+		require.NoError(t, json.Unmarshal(body, &logs))
+		require.NotEmpty(t, logs)
+		require.Equal(t, *decision.EntryLogID, logs[0].ID)
+		require.Equal(t, app.users.Guard.ID, logs[0].GuardUserID)
+
+		resp, body = sendRead(camera.Token, map[string]interface{}{"plate": "A123BC77", "confidence": 0.4})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &decision))
+		require.Equal(t, "manual_review", decision.Decision)
+		require.Nil(t, decision.EntryLogID)
+
+		resp, _ = app.request(t, http.MethodGet, "/plate-reads/review-queue", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, body = app.request(t, http.MethodGet, "/plate-reads/review-queue", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var queue []PlateReadResponse
+		require.NoError(t, json.Unmarshal(body, &queue))
+		require.Len(t, queue, 1)
+		require.Equal(t, decision.ReadID, queue[0].ID)
+		require.Equal(t, "Entry camera", queue[0].CameraName)
+
+		reviewPath := "/plate-reads/" + decision.ReadID.String() + "/review"
+		resp, body = app.request(t, http.MethodPost, reviewPath, app.guardAccess, map[string]interface{}{"allow": false, "comment": "already inside"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var reviewed PlateReadResponse
+		require.NoError(t, json.Unmarshal(body, &reviewed))
+		require.NotNil(t, reviewed.ReviewStatus)
+		require.Equal(t, "denied", *reviewed.ReviewStatus)
+		resp, _ = app.request(t, http.MethodPost, reviewPath, app.guardAccess, map[string]interface{}{"allow": true})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodPost, "/cameras/"+camera.ID.String()+"/token", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var rotated CameraTokenResponse
+		require.NoError(t, json.Unmarshal(body, &rotated))
+		resp, _ = sendRead(camera.Token, map[string]interface{}{"plate": "A123BC77", "confidence": 0.95})
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodDelete, "/cameras/"+camera.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp, _ = sendRead(rotated.Token, map[string]interface{}{"plate": "A123BC77", "confidence": 0.95})
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp, _ = app.requestRaw(t, http.MethodPost, "/passes/"+createdPassID.String()+"/exit", app.guardAccess, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("gate barriers", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodPost, "/gates", app.adminAccess, map[string]string{"name": "Barrier gate"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var gate GateResponse
+		require.NoError(t, json.Unmarshal(body, &gate))
+		barrierPath := "/gates/" + gate.ID.String() + "/barrier"
+
+		resp, _ = app.request(t, http.MethodGet, barrierPath, app.adminAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPut, barrierPath, app.adminAccess, map[string]interface{}{"driver": "mqtt", "address": "broker:1883"})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPut, barrierPath, app.guardAccess, map[string]interface{}{"driver": "noop"})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, body = app.request(t, http.MethodPut, barrierPath, app.adminAccess, map[string]interface{}{"driver": "noop", "secret": "s3cret"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var config GateBarrierResponse
+		require.NoError(t, json.Unmarshal(body, &config))
+		require.True(t, config.AutoOpen)
+		require.True(t, config.HasSecret)
+		require.NotContains(t, string(body), "s3cret")
+
+		resp, body = app.request(t, http.MethodPost, "/passes/"+createdPassID.String()+"/entry", app.guardAccess, map[string]interface{}{"gate_id": gate.ID})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var entry EntryLogResponse
+		require.NoError(t, json.Unmarshal(body, &entry))
+		require.NotNil(t, entry.Barrier)
+		require.True(t, entry.Barrier.Success)
+		require.Equal(t, "open", entry.Barrier.Command)
+
+		resp, body = app.request(t, http.MethodPost, barrierPath+"/open", app.guardAccess, map[string]interface{}{"hold_seconds": 60})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var event BarrierEventResponse
+		require.NoError(t, json.Unmarshal(body, &event))
+		require.Equal(t, "hold", event.Command)
+		resp, body = app.request(t, http.MethodGet, barrierPath+"/status", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var status BarrierStatusResponse
+		require.NoError(t, json.Unmarshal(body, &status))
+		require.Equal(t, "closed", status.State)
+
+		resp, body = app.request(t, http.MethodGet, barrierPath+"/events", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var events []BarrierEventResponse
+		require.NoError(t, json.Unmarshal(body, &events))
+		require.Len(t, events, 2)
+		require.Equal(t, event.ID, events[0].ID)
+		require.NotNil(t, events[1].EntryLogID)
+		require.Equal(t, entry.ID, *events[1].EntryLogID)
+		resp, body = app.request(t, http.MethodGet, barrierPath+"/events?failed=true", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &events))
+		require.Empty(t, events)
+
+		resp, _ = app.request(t, http.MethodDelete, barrierPath, app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, barrierPath+"/open", app.guardAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, _ = app.requestRaw(t, http.MethodPost, "/passes/"+createdPassID.String()+"/exit", app.guardAccess, "")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("offline sync", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodGet, "/sync/delta", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var delta SyncDeltaResponse
+		require.NoError(t, json.Unmarshal(body, &delta))
+		require.True(t, delta.Full)
+		found := false
+		for _, pass := range delta.Passes {
+			require.True(t, pass.Active)
+			found = found || pass.ID == createdPassID
+		}
+		require.True(t, found, "the full snapshot lists active passes")
+
+		entryID, exitID := uuid.New(), uuid.New()
+		deviceAt := time.Now().Add(-10 * time.Minute).UTC().Truncate(time.Second)
+		batch := map[string]interface{}{"entries": []map[string]interface{}{
+			{"id": exitID, "pass_id": createdPassID, "action": "exit", "device_time": deviceAt.Add(5 * time.Minute)},
+			{"id": entryID, "pass_id": createdPassID, "action": "entry", "device_time": deviceAt},
+			{"id": uuid.New(), "pass_id": uuid.New(), "action": "entry", "device_time": deviceAt},
+		}}
+		resp, body = app.request(t, http.MethodPost, "/entry-logs/batch", app.guardAccess, batch)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var uploaded SyncBatchResponse
+		require.NoError(t, json.Unmarshal(body, &uploaded))
+		require.Len(t, uploaded.Results, 3)
+		require.Equal(t, "created", uploaded.Results[0].Status)
+		require.Equal(t, "created", uploaded.Results[1].Status)
+		require.NotNil(t, uploaded.Results[1].Entry.DeviceAt)
+		require.True(t, deviceAt.Equal(*uploaded.Results[1].Entry.DeviceAt))
+		require.Less(t, uploaded.Results[1].Entry.Seq, uploaded.Results[0].Entry.Seq, "entries are applied in device time order")
+		require.Equal(t, "conflict", uploaded.Results[2].Status)
+		require.Equal(t, "not_found", uploaded.Results[2].Conflict)
+
+		resp, body = app.request(t, http.MethodPost, "/entry-logs/batch", app.guardAccess, batch)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &uploaded))
+		require.Equal(t, "duplicate", uploaded.Results[0].Status)
+		require.Equal(t, "duplicate", uploaded.Results[1].Status)
+		require.Equal(t, entryID, uploaded.Results[1].Entry.ID)
+
+		resp, body = app.request(t, http.MethodGet, "/entry-logs?plate=A123BC77&limit=5", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var records []EntryLogRecordResponse
+		require.NoError(t, json.Unmarshal(body, &records))
+		require.NotEmpty(t, records)
+		require.Equal(t, exitID, records[0].ID, "a retried upload adds no records")
+		require.NotNil(t, records[0].DeviceAt)
+
+		resp, _ = app.request(t, http.MethodPost, "/entry-logs/batch", app.resAccess, batch)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodGet, "/sync/delta?since="+time.Now().Add(time.Hour).UTC().Format(time.RFC3339), app.guardAccess, nil)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp, body = app.request(t, http.MethodGet, "/sync/delta?since="+delta.Cursor.UTC().Format(time.RFC3339Nano), app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var next SyncDeltaResponse
+		require.NoError(t, json.Unmarshal(body, &next))
+		require.False(t, next.Full)
+		require.False(t, next.Cursor.Before(delta.Cursor))
+	})
+
+	t.Run("plots", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodPost, "/plots", app.adminAccess, map[string]string{
+			"number": "уч. 12а",
+			"street": "Лесная",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var plot PlotResponse
+		require.NoError(t, json.Unmarshal(body, &plot))
+		require.Equal(t, "12A", plot.Number)
+		plotPath := "/plots/" + plot.ID.String()
+
+		resp, _ = app.request(t, http.MethodPost, "/plots", app.adminAccess, map[string]string{"number": "12A"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, "/plots", app.resAccess, map[string]string{"number": "77"})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodGet, plotPath, app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, _ = app.request(t, http.MethodPut, plotPath+"/residents/"+app.users.Resident.ID.String(), app.adminAccess, map[string]string{"relation": "owner"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, body = app.request(t, http.MethodGet, "/plots", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var own []PlotResponse
+		require.NoError(t, json.Unmarshal(body, &own))
+		require.Len(t, own, 1)
+		require.Equal(t, "owner", own[0].Relation)
+
+		// Passes and guests go to the primary plot unless another one is named.
+		resp, body = app.request(t, http.MethodPost, "/passes", app.resAccess, map[string]string{"plate_number": "K111KK77"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var pass PassResponse
+		require.NoError(t, json.Unmarshal(body, &pass))
+		require.NotNil(t, pass.PlotID)
+		require.Equal(t, plot.ID, *pass.PlotID)
+		resp, body = app.request(t, http.MethodGet, plotPath+"/passes", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var plotPasses []PassResponse
+		require.NoError(t, json.Unmarshal(body, &plotPasses))
+		require.NotEmpty(t, plotPasses)
+
+		resp, body = app.request(t, http.MethodPost, "/plots", app.adminAccess, map[string]string{"number": "77"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var other PlotResponse
+		require.NoError(t, json.Unmarshal(body, &other))
+		resp, _ = app.request(t, http.MethodPost, "/passes", app.resAccess, map[string]string{"plate_number": "K222KK77", "plot_id": other.ID.String()})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// A user saved with a new plot number gets the plot and an owner link.
+		resp, body = app.request(t, http.MethodPost, "/users", app.adminAccess, map[string]string{
+			"email":       "plot21@example.com",
+			"password":    "resident123",
+			"role":        "resident",
+			"full_name":   "Plot Resident",
+			"plot_number": "Участок № 21",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var user UserResponse
+		require.NoError(t, json.Unmarshal(body, &user))
+		require.Equal(t, "21", *user.PlotNumber)
+		resp, body = app.request(t, http.MethodGet, "/plots?number=21", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var found []PlotResponse
+		require.NoError(t, json.Unmarshal(body, &found))
+		require.Len(t, found, 1)
+		plot21 := "/plots/" + found[0].ID.String()
+
+		resp, _ = app.request(t, http.MethodPatch, plot21, app.adminAccess, map[string]string{"number": "21а"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, body = app.request(t, http.MethodGet, "/users/"+user.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &user))
+		require.Equal(t, "21A", *user.PlotNumber, "renumbering a plot renames its residents' plot number")
+
+		resp, _ = app.request(t, http.MethodDelete, plot21+"/residents/"+user.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodDelete, plot21+"/residents/"+user.ID.String(), app.adminAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPatch, plot21, app.adminAccess, map[string]string{"status": "archived"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPut, plot21+"/residents/"+user.ID.String(), app.adminAccess, map[string]string{"relation": "tenant"})
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("user block and delete cascade", func(t *testing.T) {
+		resp, body := app.request(t, http.MethodPost, "/users", app.adminAccess, map[string]string{
+			"email":     "cascade@example.com",
+			"password":  "resident123",
+			"role":      "resident",
+			"full_name": "Cascade Resident",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var user UserResponse
+		require.NoError(t, json.Unmarshal(body, &user))
+		userPath := "/users/" + user.ID.String()
+
+		resp, body = app.request(t, http.MethodPost, "/passes", app.adminAccess, map[string]interface{}{
+			"owner_user_id": user.ID,
+			"plate_number":  "M321MM77",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var pass PassResponse
+		require.NoError(t, json.Unmarshal(body, &pass))
+		resp, _ = app.request(t, http.MethodPost, "/passes", app.adminAccess, map[string]interface{}{
+			"owner_user_id": user.ID,
+			"plate_number":  "M322MM77",
+			"status":        "inactive",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		now := time.Now().UTC()
+		resp, body = app.request(t, http.MethodPost, "/guest-requests", app.adminAccess, map[string]interface{}{
+			"resident_user_id": user.ID,
+			"guest_full_name":  "Cascade Guest",
+			"plate_number":     "O321OO77",
+			"valid_from":       now.Add(time.Hour),
+			"valid_to":         now.Add(4 * time.Hour),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var guest GuestResponse
+		require.NoError(t, json.Unmarshal(body, &guest))
+		require.Equal(t, service.GuestStatusPending, guest.Status)
+
+		state := func(method, path string) UserStateResponse {
+			resp, body := app.request(t, method, path, app.adminAccess, nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			var report UserStateResponse
+			require.NoError(t, json.Unmarshal(body, &report))
+			return report
+		}
+		statuses := func() (string, string) {
+			_, body := app.request(t, http.MethodGet, "/passes/"+pass.ID.String(), app.adminAccess, nil)
+			var gotPass PassResponse
+			require.NoError(t, json.Unmarshal(body, &gotPass))
+			_, body = app.request(t, http.MethodGet, "/guest-requests/"+guest.ID.String(), app.adminAccess, nil)
+			var gotGuest GuestResponse
+			require.NoError(t, json.Unmarshal(body, &gotGuest))
+			return gotPass.Status, gotGuest.Status
+		}
+
+		report := state(http.MethodPost, userPath+"/block")
+		require.Equal(t, []uuid.UUID{pass.ID}, report.Passes, "the inactive pass is left alone")
+		require.Equal(t, []uuid.UUID{guest.ID}, report.GuestRequests)
+		passStatus, guestStatus := statuses()
+		require.Equal(t, service.PassStatusSuspended, passStatus)
+		require.Equal(t, service.GuestStatusSuspended, guestStatus)
+
+		report = state(http.MethodPost, userPath+"/unblock")
+		require.Equal(t, []uuid.UUID{pass.ID}, report.Passes)
+		passStatus, guestStatus = statuses()
+		require.Equal(t, service.PassStatusActive, passStatus)
+		require.Equal(t, service.GuestStatusPending, guestStatus)
+		require.Empty(t, state(http.MethodPost, userPath+"/unblock").Passes, "a cascade is restored once")
+
+		// Deleting a blocked user revokes the suspended pass; restoring the
+		// user brings it back to suspended until the user is unblocked.
+		state(http.MethodPost, userPath+"/block")
+		report = state(http.MethodDelete, userPath)
+		require.Equal(t, []uuid.UUID{pass.ID}, report.Passes)
+		require.Equal(t, []uuid.UUID{guest.ID}, report.GuestRequests)
+		passStatus, guestStatus = statuses()
+		require.Equal(t, service.PassStatusRevoked, passStatus)
+		require.Equal(t, service.GuestStatusCancelled, guestStatus)
+
+		state(http.MethodPost, userPath+"/restore")
+		passStatus, guestStatus = statuses()
+		require.Equal(t, service.PassStatusSuspended, passStatus)
+		require.Equal(t, service.GuestStatusSuspended, guestStatus)
+		state(http.MethodPost, userPath+"/unblock")
+		passStatus, guestStatus = statuses()
+		require.Equal(t, service.PassStatusActive, passStatus)
+		require.Equal(t, service.GuestStatusPending, guestStatus)
+
+		resp, _ = app.request(t, http.MethodPost, "/users/"+uuid.NewString()+"/block", app.adminAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("recurring guest series", func(t *testing.T) {
+		today := time.Now().UTC()
+		day := func(offset int) string { return today.AddDate(0, 0, offset).Format(time.DateOnly) }
+		resp, body := app.request(t, http.MethodPost, "/guest-series", app.adminAccess, map[string]interface{}{
+			"resident_user_id": app.users.Resident.ID,
+			"guest_full_name":  "Nanny",
+			"plate_number":     "E111EE77",
+			"rrule":            "FREQ=DAILY;COUNT=5",
+			"starts_on":        day(0),
+			"start":            "00:00",
+			"end":              "24:00",
+			"status":           "approved",
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var series GuestSeriesResponse
+		require.NoError(t, json.Unmarshal(body, &series))
+		require.Equal(t, "approved", series.Status)
+		require.NotNil(t, series.EndsOn)
+		require.Equal(t, day(4), *series.EndsOn)
+		path := "/guest-series/" + series.ID.String()
+
+		resp, _ = app.request(t, http.MethodGet, path, app.guardAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// Guards see today's visit as a plain guest request.
+		resp, body = app.request(t, http.MethodGet, "/guest-requests/search?plate=E111EE77", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var found []GateGuestResponse
+		require.NoError(t, json.Unmarshal(body, &found))
+		require.NotEmpty(t, found)
+		require.Equal(t, "Nanny", found[0].GuestFullName)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/occurrences/"+day(1)+"/skip", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, path+"/occurrences/"+day(9)+"/skip", app.resAccess, nil)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, body = app.request(t, http.MethodPatch, path+"/occurrences/"+day(2), app.resAccess, map[string]string{"guest_full_name": "Nanny Anna"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var edited GuestResponse
+		require.NoError(t, json.Unmarshal(body, &edited))
+		require.Equal(t, "Nanny Anna", edited.GuestFullName)
+		require.Equal(t, "pending", edited.Status)
+
+		resp, body = app.request(t, http.MethodGet, path+"/occurrences", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var occurrences []GuestOccurrenceResponse
+		require.NoError(t, json.Unmarshal(body, &occurrences))
+		require.Len(t, occurrences, 5)
+		require.NotNil(t, occurrences[0].GuestRequestID)
+		require.Equal(t, "cancelled", occurrences[1].Status)
+		require.Equal(t, "Nanny Anna", occurrences[2].GuestFullName)
+		require.Nil(t, occurrences[3].GuestRequestID)
+
+		// A resident edit of the series goes back to review; skipped visits
+		// stay skipped.
+		resp, body = app.request(t, http.MethodPatch, path, app.resAccess, map[string]string{"start": "08:00", "end": "20:00"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &series))
+		require.Equal(t, "pending", series.Status)
+		require.Equal(t, "08:00", series.Start)
+		resp, _ = app.request(t, http.MethodPost, path+"/approve", app.resAccess, nil)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp, _ = app.request(t, http.MethodPost, path+"/approve", app.adminAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, body = app.request(t, http.MethodGet, path+"/occurrences?from="+day(0)+"&to="+day(6), app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.Unmarshal(body, &occurrences))
+		require.Len(t, occurrences, 5)
+		require.Nil(t, occurrences[0].GuestRequestID)
+		require.Equal(t, "cancelled", occurrences[1].Status)
+		require.Equal(t, "Nanny", occurrences[2].GuestFullName)
+
+		resp, _ = app.request(t, http.MethodPost, path+"/cancel", app.resAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, body = app.request(t, http.MethodGet, "/guest-requests/search?plate=E111EE77", app.guardAccess, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.JSONEq(t, "[]", string(body))
+	})
 }
 
 func parseUUIDField(t *testing.T, body []byte, key string) uuid.UUID {
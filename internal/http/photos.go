@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/service"
+)
+
+// EntryPhotoResponse links the vehicle photo taken with a journal record;
+// the links work without a bearer token until URLExpiresAt.
+type EntryPhotoResponse struct {
+	ID           uuid.UUID `json:"id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	URLExpiresAt time.Time `json:"url_expires_at"`
+}
+
+// decodeGateRequest reads the JSON body of an entry, exit or check-in. The
+// request may also come as multipart/form-data with the JSON in the
+// "payload" field and a vehicle photo in "photo", which is returned. An
+// empty body leaves dst as is.
+func decodeGateRequest(w http.ResponseWriter, r *http.Request, dst interface{}) ([]byte, bool) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := json.NewDecoder(r.Body).Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+			WriteError(w, http.StatusBadRequest, "invalid payload")
+			return nil, false
+		}
+		return nil, true
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteError(w, http.StatusRequestEntityTooLarge, service.ErrAttachmentTooLarge.Error())
+			return nil, false
+		}
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return nil, false
+	}
+	if payload := r.FormValue("payload"); payload != "" {
+		if err := json.Unmarshal([]byte(payload), dst); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid payload")
+			return nil, false
+		}
+	}
+	file, _, err := r.FormFile("photo")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, true
+	}
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid photo")
+		return nil, false
+	}
+	defer file.Close()
+	photo, err := io.ReadAll(file)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid photo")
+		return nil, false
+	}
+	return photo, true
+}
+
+func (h *Handler) HandleDownloadEntryPhoto(w http.ResponseWriter, r *http.Request) {
+	h.downloadEntryPhoto(w, r, false)
+}
+
+func (h *Handler) HandleDownloadEntryPhotoThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.downloadEntryPhoto(w, r, true)
+}
+
+// downloadEntryPhoto serves a photo by a signed link. Links are only handed
+// out with journal records the caller may read, which keeps residents to
+// the photos of their own vehicles.
+func (h *Handler) downloadEntryPhoto(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	id, ok := h.verifyFileLink(w, r)
+	if !ok {
+		return
+	}
+	photo, content, err := h.Service.OpenEntryPhoto(r.Context(), id, thumbnail)
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	case writeUploadError(w, err):
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "photo error")
+		return
+	}
+	defer content.Close()
+	if thumbnail {
+		writeFile(w, content, "image/jpeg", -1, "thumbnail.jpg")
+		return
+	}
+	writeFile(w, content, photo.ContentType, photo.SizeBytes, "photo"+photoExtension(photo.ContentType))
+}
+
+// entryLogPhoto links the photo taken with an entry log, if any.
+func (h *Handler) entryLogPhoto(r *http.Request, entryLogID uuid.UUID) *EntryPhotoResponse {
+	photo, err := h.Service.EntryPhoto(r.Context(), entryLogID)
+	if err != nil {
+		return nil
+	}
+	return h.mapEntryPhoto(photo.ID)
+}
+
+func (h *Handler) mapEntryPhoto(id uuid.UUID) *EntryPhotoResponse {
+	link := h.Service.SignFileLink(id)
+	query := "?expires=" + strconv.FormatInt(link.Expires.Unix(), 10) + "&signature=" + link.Signature
+	return &EntryPhotoResponse{
+		ID:           id,
+		URL:          "/entry-photos/" + id.String() + query,
+		ThumbnailURL: "/entry-photos/" + id.String() + "/thumbnail" + query,
+		URLExpiresAt: link.Expires,
+	}
+}
+
+func photoExtension(contentType string) string {
+	if contentType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
@@ -0,0 +1,354 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+type PlotRequest struct {
+	Number       string `json:"number"`
+	Street       string `json:"street"`
+	ContactName  string `json:"contact_name"`
+	ContactPhone string `json:"contact_phone"`
+	ContactEmail string `json:"contact_email"`
+	Status       string `json:"status"`
+}
+
+// PlotUpdateRequest is a partial update; an empty string clears a contact
+// field.
+type PlotUpdateRequest struct {
+	Number       *string `json:"number"`
+	Street       *string `json:"street"`
+	ContactName  *string `json:"contact_name"`
+	ContactPhone *string `json:"contact_phone"`
+	ContactEmail *string `json:"contact_email"`
+	Status       *string `json:"status"`
+}
+
+type PlotResidentRequest struct {
+	Relation string `json:"relation"`
+}
+
+type PlotResidentResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	FullName  string    `json:"full_name,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	Relation  string    `json:"relation"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PlotResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	Number       string     `json:"number"`
+	Street       *string    `json:"street,omitempty"`
+	ContactName  *string    `json:"contact_name,omitempty"`
+	ContactPhone *string    `json:"contact_phone,omitempty"`
+	ContactEmail *string    `json:"contact_email,omitempty"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	UpdatedBy    *uuid.UUID `json:"updated_by,omitempty"`
+	// Relation is how the requesting resident lives on the plot.
+	Relation  string                 `json:"relation,omitempty"`
+	Residents []PlotResidentResponse `json:"residents,omitempty"`
+}
+
+// HandleListPlots lists all plots for admins and guards and the caller's own
+// plots for residents.
+func (h *Handler) HandleListPlots(w http.ResponseWriter, r *http.Request) {
+	if roleFromContext(r) == string(auth.RoleResident) {
+		plots, err := h.Service.ListUserPlots(r.Context(), actorFromContext(r))
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "error")
+			return
+		}
+		resp := make([]PlotResponse, 0, len(plots))
+		for _, plot := range plots {
+			resp = append(resp, mapUserPlot(plot))
+		}
+		WriteJSON(w, http.StatusOK, resp)
+		return
+	}
+	limit, offset := parsePagination(r)
+	plots, err := h.Service.ListPlots(r.Context(), service.PlotFilter{
+		Status: r.URL.Query().Get("status"),
+		Number: r.URL.Query().Get("number"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		writePlotError(w, err)
+		return
+	}
+	resp := make([]PlotResponse, 0, len(plots))
+	for _, plot := range plots {
+		resp = append(resp, mapPlot(plot))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleCreatePlot(w http.ResponseWriter, r *http.Request) {
+	var req PlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	plot, err := h.Service.CreatePlot(r.Context(), service.PlotInput{
+		Number:       req.Number,
+		Street:       req.Street,
+		ContactName:  req.ContactName,
+		ContactPhone: req.ContactPhone,
+		ContactEmail: req.ContactEmail,
+		Status:       req.Status,
+		ActorID:      actorFromContext(r),
+	})
+	if err != nil {
+		writePlotError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapPlot(plot))
+}
+
+func (h *Handler) HandleGetPlot(w http.ResponseWriter, r *http.Request) {
+	resp, ok := h.plotForRequest(w, r)
+	if !ok {
+		return
+	}
+	residents, err := h.Service.ListPlotResidents(r.Context(), resp.ID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	for _, resident := range residents {
+		resp.Residents = append(resp.Residents, PlotResidentResponse{
+			UserID:    resident.UserID,
+			FullName:  resident.FullName,
+			Email:     resident.Email,
+			Role:      resident.Role,
+			Relation:  resident.Relation,
+			CreatedAt: resident.CreatedAt,
+		})
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleUpdatePlot(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req PlotUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	plot, err := h.Service.GetPlot(r.Context(), id)
+	if err != nil {
+		writePlotError(w, err)
+		return
+	}
+	input := service.PlotInput{
+		ID:           id,
+		Number:       plot.Number,
+		Street:       plot.Street.String,
+		ContactName:  plot.ContactName.String,
+		ContactPhone: plot.ContactPhone.String,
+		ContactEmail: plot.ContactEmail.String,
+		Status:       plot.Status,
+		ActorID:      actorFromContext(r),
+	}
+	if req.Number != nil {
+		input.Number = *req.Number
+	}
+	if req.Street != nil {
+		input.Street = *req.Street
+	}
+	if req.ContactName != nil {
+		input.ContactName = *req.ContactName
+	}
+	if req.ContactPhone != nil {
+		input.ContactPhone = *req.ContactPhone
+	}
+	if req.ContactEmail != nil {
+		input.ContactEmail = *req.ContactEmail
+	}
+	if req.Status != nil {
+		input.Status = *req.Status
+	}
+	updated, err := h.Service.UpdatePlot(r.Context(), input)
+	if err != nil {
+		writePlotError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapPlot(updated))
+}
+
+func (h *Handler) HandleSetPlotResident(w http.ResponseWriter, r *http.Request) {
+	plotID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	var req PlotResidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	resident, err := h.Service.SetPlotResident(r.Context(), service.PlotResidentInput{
+		PlotID:   plotID,
+		UserID:   userID,
+		Relation: req.Relation,
+		ActorID:  actorFromContext(r),
+	})
+	if err != nil {
+		writePlotError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, PlotResidentResponse{
+		UserID:    resident.UserID,
+		Relation:  resident.Relation,
+		CreatedAt: resident.CreatedAt,
+	})
+}
+
+func (h *Handler) HandleRemovePlotResident(w http.ResponseWriter, r *http.Request) {
+	plotID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if err := h.Service.RemovePlotResident(r.Context(), plotID, userID); err != nil {
+		writePlotError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleListPlotPasses(w http.ResponseWriter, r *http.Request) {
+	plot, ok := h.plotForRequest(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := parsePagination(r)
+	passes, err := h.Service.ListPlotPasses(r.Context(), plot.ID, limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapPasses(passes))
+}
+
+func (h *Handler) HandleListPlotGuests(w http.ResponseWriter, r *http.Request) {
+	plot, ok := h.plotForRequest(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := parsePagination(r)
+	guests, err := h.Service.ListPlotGuestRequests(r.Context(), plot.ID, limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	resp := make([]GuestResponse, 0, len(guests))
+	for _, guest := range guests {
+		resp = append(resp, mapGuest(guest))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// plotForRequest loads the plot in the URL. Residents only see the plots
+// they are linked to, with their relation to it.
+func (h *Handler) plotForRequest(w http.ResponseWriter, r *http.Request) (PlotResponse, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return PlotResponse{}, false
+	}
+	if roleFromContext(r) != string(auth.RoleResident) {
+		plot, err := h.Service.GetPlot(r.Context(), id)
+		if err != nil {
+			writePlotError(w, err)
+			return PlotResponse{}, false
+		}
+		return mapPlot(plot), true
+	}
+	plots, err := h.Service.ListUserPlots(r.Context(), actorFromContext(r))
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return PlotResponse{}, false
+	}
+	for _, plot := range plots {
+		if plot.ID == id {
+			return mapUserPlot(plot), true
+		}
+	}
+	WriteError(w, http.StatusForbidden, "forbidden")
+	return PlotResponse{}, false
+}
+
+func writePlotError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrPlotExists), errors.Is(err, service.ErrPlotArchived):
+		WriteError(w, http.StatusConflict, err.Error())
+	default:
+		WriteError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func mapPlot(plot repo.Plot) PlotResponse {
+	return PlotResponse{
+		ID:           plot.ID,
+		Number:       plot.Number,
+		Street:       nullStringPtr(plot.Street),
+		ContactName:  nullStringPtr(plot.ContactName),
+		ContactPhone: nullStringPtr(plot.ContactPhone),
+		ContactEmail: nullStringPtr(plot.ContactEmail),
+		Status:       plot.Status,
+		CreatedAt:    plot.CreatedAt,
+		UpdatedAt:    plot.UpdatedAt,
+		CreatedBy:    nullUUIDPtr(plot.CreatedBy),
+		UpdatedBy:    nullUUIDPtr(plot.UpdatedBy),
+	}
+}
+
+func mapUserPlot(plot repo.ListUserPlotsRow) PlotResponse {
+	resp := mapPlot(repo.Plot{
+		ID:           plot.ID,
+		Number:       plot.Number,
+		Street:       plot.Street,
+		ContactName:  plot.ContactName,
+		ContactPhone: plot.ContactPhone,
+		ContactEmail: plot.ContactEmail,
+		Status:       plot.Status,
+		CreatedAt:    plot.CreatedAt,
+		UpdatedAt:    plot.UpdatedAt,
+		CreatedBy:    plot.CreatedBy,
+		UpdatedBy:    plot.UpdatedBy,
+	})
+	resp.Relation = plot.Relation
+	return resp
+}
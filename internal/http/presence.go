@@ -0,0 +1,114 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/service"
+)
+
+// PresenceConflictResponse is returned with 409 when an entry or exit does
+// not match the presence state; resend with override_reason to record it.
+type PresenceConflictResponse struct {
+	Error     string     `json:"error"`
+	Anomaly   string     `json:"anomaly"`
+	EnteredAt *time.Time `json:"entered_at,omitempty"`
+}
+
+type OnSiteResponse struct {
+	PassID          *uuid.UUID `json:"pass_id,omitempty"`
+	GuestRequestID  *uuid.UUID `json:"guest_request_id,omitempty"`
+	PlateNumber     string     `json:"plate_number"`
+	GuestFullName   *string    `json:"guest_full_name,omitempty"`
+	GuestType       *string    `json:"guest_type,omitempty"`
+	OwnerUserID     uuid.UUID  `json:"owner_user_id"`
+	OwnerFullName   string     `json:"owner_full_name"`
+	OwnerPlotNumber *string    `json:"owner_plot_number,omitempty"`
+	EnteredAt       time.Time  `json:"entered_at"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	GuardFullName   string     `json:"guard_full_name"`
+}
+
+var onSiteExportColumns = []exportColumn{
+	{"Госномер", "Plate number"},
+	{"Гость", "Guest"},
+	{"Владелец", "Owner"},
+	{"Участок", "Plot"},
+	{"Въезд", "Entered at"},
+	{"На территории, мин", "On site, min"},
+	{"Охранник", "Guard"},
+}
+
+func (h *Handler) HandleOnSite(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.Service.OnSite(r.Context(), r.URL.Query().Get("plot"))
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	resp := make([]OnSiteResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, mapOnSite(entry))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleExportOnSite is the roll-call list as a file for printing during an
+// evacuation.
+func (h *Handler) HandleExportOnSite(w http.ResponseWriter, r *http.Request) {
+	stream, ok := h.newExportStream(w, r, "on-site", onSiteExportColumns)
+	if !ok {
+		return
+	}
+	entries, err := h.Service.OnSite(r.Context(), r.URL.Query().Get("plot"))
+	for i := 0; err == nil && i < len(entries); i++ {
+		entry := entries[i]
+		err = stream.row(
+			entry.PlateNumber,
+			entry.GuestFullName.String,
+			entry.OwnerFullName,
+			entry.OwnerPlotNumber.String,
+			stream.time(entry.EnteredAt),
+			strconv.FormatInt(int64(entry.Duration/time.Minute), 10),
+			entry.GuardFullName,
+		)
+	}
+	stream.finish(err)
+}
+
+func mapOnSite(entry service.OnSiteEntry) OnSiteResponse {
+	resp := OnSiteResponse{
+		PlateNumber:     entry.PlateNumber,
+		OwnerUserID:     entry.OwnerUserID,
+		OwnerFullName:   entry.OwnerFullName,
+		EnteredAt:       entry.EnteredAt,
+		DurationSeconds: int64(entry.Duration / time.Second),
+		GuardFullName:   entry.GuardFullName,
+	}
+	if entry.PassID.Valid {
+		resp.PassID = &entry.PassID.UUID
+	}
+	if entry.GuestRequestID.Valid {
+		resp.GuestRequestID = &entry.GuestRequestID.UUID
+	}
+	if entry.GuestFullName.Valid {
+		resp.GuestFullName = &entry.GuestFullName.String
+	}
+	if entry.GuestType.Valid {
+		resp.GuestType = &entry.GuestType.String
+	}
+	if entry.OwnerPlotNumber.Valid {
+		resp.OwnerPlotNumber = &entry.OwnerPlotNumber.String
+	}
+	return resp
+}
+
+func mapPresenceConflict(err *service.PresenceConflictError) PresenceConflictResponse {
+	resp := PresenceConflictResponse{Error: err.Error(), Anomaly: err.Anomaly}
+	if !err.EnteredAt.IsZero() {
+		resp.EnteredAt = &err.EnteredAt
+	}
+	return resp
+}
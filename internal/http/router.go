@@ -25,7 +25,24 @@ type Service interface {
 	UserService
 	PassService
 	GuestService
+	GuestSeriesService
+	GuestPinService
+	SavedGuestService
 	EntryService
+	PresenceService
+	ScheduleService
+	ImportService
+	ExportService
+	WatchlistService
+	GateService
+	ShiftService
+	IncidentService
+	PhotoService
+	CameraService
+	BarrierService
+	SyncService
+	FileService
+	PlotService
 }
 
 func NewRouter(handler *Handler) http.Handler {
@@ -58,6 +75,11 @@ func NewRouter(handler *Handler) http.Handler {
 		r.Post("/refresh", handler.HandleRefresh)
 	})
 
+	r.Get("/incident-attachments/{id}", handler.HandleDownloadIncidentAttachment)
+	r.Get("/entry-photos/{id}", handler.HandleDownloadEntryPhoto)
+	r.Get("/entry-photos/{id}/thumbnail", handler.HandleDownloadEntryPhotoThumbnail)
+	r.Post("/camera/plate-reads", handler.HandleCameraPlateRead)
+
 	r.Group(func(r chi.Router) {
 		r.Use(auth.AuthMiddleware(handler.Auth))
 
@@ -65,16 +87,21 @@ func NewRouter(handler *Handler) http.Handler {
 			r.Use(auth.RequireRoles(auth.RoleAdmin))
 			r.Post("/", handler.HandleCreateUser)
 			r.Get("/", handler.HandleListUsers)
+			r.Get("/export", handler.HandleExportUsers)
+			r.Post("/import", handler.HandleImportUsers)
 			r.Get("/{id}", handler.HandleGetUser)
 			r.Patch("/{id}", handler.HandleUpdateUser)
 			r.Delete("/{id}", handler.HandleDeleteUser)
 			r.Post("/{id}/restore", handler.HandleRestoreUser)
 			r.Post("/{id}/block", handler.HandleBlockUser)
 			r.Post("/{id}/unblock", handler.HandleUnblockUser)
+			r.Get("/{id}/gates", handler.HandleGetGuardGates)
+			r.Put("/{id}/gates", handler.HandleSetGuardGates)
 		})
 
 		r.Route("/passes", func(r chi.Router) {
 			r.Get("/search", handler.HandleSearchPasses)
+			r.Get("/export", handler.HandleExportPasses)
 			r.Post("/", handler.HandleCreatePass)
 			r.Get("/", handler.HandleListPasses)
 			r.Get("/{id}", handler.HandleGetPass)
@@ -83,15 +110,159 @@ func NewRouter(handler *Handler) http.Handler {
 			r.Post("/{id}/restore", handler.HandleRestorePass)
 			r.Post("/{id}/entry", handler.HandleEntry)
 			r.Post("/{id}/exit", handler.HandleExit)
+			r.Get("/{id}/schedules", handler.HandleListPassSchedules)
+			r.Get("/{id}/entry-logs", handler.HandleListPassEntryLogs)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/schedules", handler.HandleCreatePassSchedule)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Delete("/{id}/schedules/{scheduleId}", handler.HandleDeletePassSchedule)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/{id}/gates", handler.HandleGetPassGates)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Put("/{id}/gates", handler.HandleSetPassGates)
+		})
+
+		r.Route("/gates", func(r chi.Router) {
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/", handler.HandleListGates)
+			r.Group(func(r chi.Router) {
+				r.Use(auth.RequireRoles(auth.RoleAdmin))
+				r.Post("/", handler.HandleCreateGate)
+				r.Get("/stats", handler.HandleGateStats)
+				r.Get("/guest-types", handler.HandleListGuestTypeGates)
+				r.Put("/guest-types/{type}", handler.HandleSetGuestTypeGates)
+				r.Get("/{id}", handler.HandleGetGate)
+				r.Patch("/{id}", handler.HandleUpdateGate)
+				r.Delete("/{id}", handler.HandleDeleteGate)
+				r.Get("/{id}/barrier", handler.HandleGetGateBarrier)
+				r.Put("/{id}/barrier", handler.HandleSetGateBarrier)
+				r.Delete("/{id}/barrier", handler.HandleDeleteGateBarrier)
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard))
+				r.Post("/{id}/barrier/open", handler.HandleOpenBarrier)
+				r.Get("/{id}/barrier/status", handler.HandleBarrierStatus)
+				r.Get("/{id}/barrier/events", handler.HandleListBarrierEvents)
+			})
+		})
+
+		r.Route("/cameras", func(r chi.Router) {
+			r.Use(auth.RequireRoles(auth.RoleAdmin))
+			r.Get("/", handler.HandleListCameras)
+			r.Post("/", handler.HandleCreateCamera)
+			r.Get("/{id}", handler.HandleGetCamera)
+			r.Patch("/{id}", handler.HandleUpdateCamera)
+			r.Delete("/{id}", handler.HandleDeleteCamera)
+			r.Post("/{id}/token", handler.HandleRotateCameraToken)
+		})
+
+		r.Route("/plate-reads", func(r chi.Router) {
+			r.Use(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard))
+			r.Get("/", handler.HandleListPlateReads)
+			r.Get("/review-queue", handler.HandlePlateReviewQueue)
+			r.Post("/{id}/review", handler.HandleReviewPlateRead)
+		})
+
+		r.Route("/shifts", func(r chi.Router) {
+			r.With(auth.RequireRoles(auth.RoleGuard)).Post("/", handler.HandleStartShift)
+			r.With(auth.RequireRoles(auth.RoleGuard)).Get("/current", handler.HandleCurrentShift)
+			r.With(auth.RequireRoles(auth.RoleGuard)).Post("/current/end", handler.HandleEndCurrentShift)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Get("/", handler.HandleListShifts)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Get("/{id}", handler.HandleGetShift)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/end", handler.HandleEndShift)
+		})
+
+		r.Route("/incidents", func(r chi.Router) {
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/", handler.HandleListIncidents)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Post("/", handler.HandleCreateIncident)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/{id}", handler.HandleGetIncident)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/status", handler.HandleChangeIncidentStatus)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/comments", handler.HandleAddIncidentComment)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Post("/{id}/attachments", handler.HandleUploadIncidentAttachment)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Delete("/{id}/attachments/{attachmentId}", handler.HandleDeleteIncidentAttachment)
+		})
+
+		r.Route("/holidays", func(r chi.Router) {
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/", handler.HandleListHolidays)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/", handler.HandleCreateHoliday)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Delete("/{day}", handler.HandleDeleteHoliday)
+		})
+
+		r.Route("/watchlist", func(r chi.Router) {
+			r.Use(auth.RequireRoles(auth.RoleAdmin))
+			r.Get("/", handler.HandleListWatchlist)
+			r.Post("/", handler.HandleCreateWatchlist)
+			r.Get("/{id}", handler.HandleGetWatchlist)
+			r.Patch("/{id}", handler.HandleUpdateWatchlist)
+			r.Delete("/{id}", handler.HandleDeleteWatchlist)
+			r.Get("/{id}/hits", handler.HandleListWatchlistHits)
 		})
 
 		r.Route("/guest-requests", func(r chi.Router) {
 			r.Post("/", handler.HandleCreateGuest)
 			r.Get("/", handler.HandleListGuest)
+			r.Get("/export", handler.HandleExportGuests)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/expected", handler.HandleExpectedGuests)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/search", handler.HandleSearchGuests)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Post("/check-in-by-pin", handler.HandleGuestPinCheckIn)
 			r.Get("/{id}", handler.HandleGetGuest)
 			r.Patch("/{id}", handler.HandleUpdateGuest)
 			r.Delete("/{id}", handler.HandleDeleteGuest)
 			r.Post("/{id}/restore", handler.HandleRestoreGuest)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/approve", handler.HandleApproveGuest)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/reject", handler.HandleRejectGuest)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleResident)).Post("/{id}/cancel", handler.HandleCancelGuest)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Post("/{id}/check-in", handler.HandleGuestCheckIn)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Post("/{id}/check-out", handler.HandleGuestCheckOut)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleResident)).Get("/{id}/pin", handler.HandleGetGuestPin)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleResident)).Post("/{id}/pin", handler.HandleRegenerateGuestPin)
+		})
+
+		r.Route("/guest-series", func(r chi.Router) {
+			r.Use(auth.RequireRoles(auth.RoleAdmin, auth.RoleResident))
+			r.Post("/", handler.HandleCreateGuestSeries)
+			r.Get("/", handler.HandleListGuestSeries)
+			r.Get("/{id}", handler.HandleGetGuestSeries)
+			r.Patch("/{id}", handler.HandleUpdateGuestSeries)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/approve", handler.HandleApproveGuestSeries)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/{id}/reject", handler.HandleRejectGuestSeries)
+			r.Post("/{id}/cancel", handler.HandleCancelGuestSeries)
+			r.Get("/{id}/occurrences", handler.HandleListGuestOccurrences)
+			r.Patch("/{id}/occurrences/{day}", handler.HandleUpdateGuestOccurrence)
+			r.Post("/{id}/occurrences/{day}/skip", handler.HandleSkipGuestOccurrence)
+		})
+
+		r.Route("/saved-guests", func(r chi.Router) {
+			r.Use(auth.RequireRoles(auth.RoleResident))
+			r.Get("/", handler.HandleListSavedGuests)
+			r.Post("/", handler.HandleCreateSavedGuest)
+			r.Get("/suggestions", handler.HandleSuggestSavedGuests)
+			r.Get("/{id}", handler.HandleGetSavedGuest)
+			r.Patch("/{id}", handler.HandleUpdateSavedGuest)
+			r.Delete("/{id}", handler.HandleDeleteSavedGuest)
+			r.Post("/{id}/guest-requests", handler.HandleCreateGuestFromSaved)
+		})
+
+		r.Route("/plots", func(r chi.Router) {
+			r.Get("/", handler.HandleListPlots)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Post("/", handler.HandleCreatePlot)
+			r.Get("/{id}", handler.HandleGetPlot)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Patch("/{id}", handler.HandleUpdatePlot)
+			r.Get("/{id}/passes", handler.HandleListPlotPasses)
+			r.Get("/{id}/guest-requests", handler.HandleListPlotGuests)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Put("/{id}/residents/{userId}", handler.HandleSetPlotResident)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Delete("/{id}/residents/{userId}", handler.HandleRemovePlotResident)
+		})
+
+		r.Route("/presence", func(r chi.Router) {
+			r.Use(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard))
+			r.Get("/", handler.HandleOnSite)
+			r.Get("/export", handler.HandleExportOnSite)
+		})
+
+		r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/sync/delta", handler.HandleSyncDelta)
+
+		r.Route("/entry-logs", func(r chi.Router) {
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Get("/", handler.HandleListEntryLogs)
+			r.Get("/export", handler.HandleExportEntryLogs)
+			r.With(auth.RequireRoles(auth.RoleAdmin)).Get("/verify", handler.HandleVerifyEntryLogChain)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Post("/batch", handler.HandleSyncEntryLogs)
+			r.With(auth.RequireRoles(auth.RoleAdmin, auth.RoleGuard)).Post("/{id}/amendments", handler.HandleAmendEntryLog)
 		})
 	})
 
@@ -0,0 +1,249 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+// SavedGuestRequest creates an address book entry; on PATCH only the fields
+// present are changed.
+type SavedGuestRequest struct {
+	GuestFullName *string `json:"guest_full_name"`
+	GuestType     *string `json:"guest_type"`
+	PlateNumber   *string `json:"plate_number"`
+	CompanyName   *string `json:"company_name"`
+	Notes         *string `json:"notes"`
+}
+
+type SavedGuestResponse struct {
+	ID            uuid.UUID `json:"id"`
+	GuestFullName string    `json:"guest_full_name"`
+	GuestType     string    `json:"guest_type"`
+	PlateNumber   string    `json:"plate_number"`
+	CompanyName   *string   `json:"company_name,omitempty"`
+	Notes         *string   `json:"notes,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type GuestSuggestionResponse struct {
+	GuestFullName string    `json:"guest_full_name"`
+	GuestType     string    `json:"guest_type"`
+	PlateNumber   string    `json:"plate_number"`
+	CompanyName   *string   `json:"company_name,omitempty"`
+	Visits        int64     `json:"visits"`
+	LastVisitAt   time.Time `json:"last_visit_at"`
+}
+
+// SavedGuestVisitRequest is the window of a guest request made from an
+// address book entry; omitted times fall back to the guest type defaults.
+type SavedGuestVisitRequest struct {
+	ValidFrom time.Time `json:"valid_from"`
+	ValidTo   time.Time `json:"valid_to"`
+}
+
+func (h *Handler) HandleListSavedGuests(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+	guests, err := h.Service.ListSavedGuests(r.Context(), actorFromContext(r), limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	resp := make([]SavedGuestResponse, 0, len(guests))
+	for _, guest := range guests {
+		resp = append(resp, mapSavedGuest(guest))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleCreateSavedGuest(w http.ResponseWriter, r *http.Request) {
+	var req SavedGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	guest, err := h.Service.CreateSavedGuest(r.Context(), service.SavedGuestInput{
+		ResidentID:  actorFromContext(r),
+		GuestName:   derefString(req.GuestFullName),
+		GuestType:   derefString(req.GuestType),
+		PlateNumber: derefString(req.PlateNumber),
+		CompanyName: derefString(req.CompanyName),
+		Notes:       derefString(req.Notes),
+	})
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapSavedGuest(guest))
+}
+
+func (h *Handler) HandleGetSavedGuest(w http.ResponseWriter, r *http.Request) {
+	guest, ok := h.savedGuest(w, r)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapSavedGuest(guest))
+}
+
+func (h *Handler) HandleUpdateSavedGuest(w http.ResponseWriter, r *http.Request) {
+	var req SavedGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	guest, ok := h.savedGuest(w, r)
+	if !ok {
+		return
+	}
+	input := service.SavedGuestInput{
+		ID:          guest.ID,
+		ResidentID:  guest.ResidentUserID,
+		GuestName:   guest.GuestFullName,
+		GuestType:   guest.GuestType,
+		PlateNumber: guest.PlateNumber,
+		CompanyName: guest.CompanyName.String,
+		Notes:       guest.Notes.String,
+	}
+	if req.GuestFullName != nil {
+		input.GuestName = *req.GuestFullName
+	}
+	if req.GuestType != nil {
+		input.GuestType = *req.GuestType
+		if input.GuestType == service.GuestTypePedestrian && req.PlateNumber == nil {
+			input.PlateNumber = ""
+		}
+	}
+	if req.PlateNumber != nil {
+		input.PlateNumber = *req.PlateNumber
+	}
+	if req.CompanyName != nil {
+		input.CompanyName = *req.CompanyName
+	}
+	if req.Notes != nil {
+		input.Notes = *req.Notes
+	}
+	updated, err := h.Service.UpdateSavedGuest(r.Context(), input)
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapSavedGuest(updated))
+}
+
+func (h *Handler) HandleDeleteSavedGuest(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.Service.DeleteSavedGuest(r.Context(), id, actorFromContext(r)); err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleSuggestSavedGuests(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			WriteError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	rows, err := h.Service.SuggestSavedGuests(r.Context(), actorFromContext(r), int32(min(limit, service.MaxGuestSuggestions)))
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	resp := make([]GuestSuggestionResponse, 0, len(rows))
+	for _, row := range rows {
+		suggestion := GuestSuggestionResponse{
+			GuestFullName: row.GuestFullName,
+			GuestType:     row.GuestType,
+			PlateNumber:   row.PlateNumber,
+			Visits:        row.Visits,
+			LastVisitAt:   row.LastVisitAt,
+		}
+		if row.CompanyName.Valid {
+			suggestion.CompanyName = &row.CompanyName.String
+		}
+		resp = append(resp, suggestion)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleCreateGuestFromSaved files a guest request for an address book entry,
+// so the resident only picks the time.
+func (h *Handler) HandleCreateGuestFromSaved(w http.ResponseWriter, r *http.Request) {
+	var req SavedGuestVisitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	saved, ok := h.savedGuest(w, r)
+	if !ok {
+		return
+	}
+	actorID := actorFromContext(r)
+	guest, err := h.Service.CreateGuestRequest(r.Context(), service.GuestCreateInput{
+		ResidentID:  actorID,
+		GuestName:   saved.GuestFullName,
+		GuestType:   saved.GuestType,
+		PlateNumber: saved.PlateNumber,
+		CompanyName: saved.CompanyName.String,
+		ValidFrom:   req.ValidFrom,
+		ValidTo:     req.ValidTo,
+		ActorID:     actorID,
+	})
+	if err != nil {
+		writeGuestError(w, err)
+		return
+	}
+	if h.Metrics != nil {
+		h.Metrics.Guest.WithLabelValues("created").Inc()
+	}
+	WriteJSON(w, http.StatusCreated, h.mapGuestWithConflicts(r, guest))
+}
+
+func (h *Handler) savedGuest(w http.ResponseWriter, r *http.Request) (repo.SavedGuest, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return repo.SavedGuest{}, false
+	}
+	guest, err := h.Service.GetSavedGuest(r.Context(), id, actorFromContext(r))
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return repo.SavedGuest{}, false
+	}
+	return guest, true
+}
+
+func mapSavedGuest(guest repo.SavedGuest) SavedGuestResponse {
+	resp := SavedGuestResponse{
+		ID:            guest.ID,
+		GuestFullName: guest.GuestFullName,
+		GuestType:     guest.GuestType,
+		PlateNumber:   guest.PlateNumber,
+		CreatedAt:     guest.CreatedAt,
+		UpdatedAt:     guest.UpdatedAt,
+	}
+	if guest.CompanyName.Valid {
+		resp.CompanyName = &guest.CompanyName.String
+	}
+	if guest.Notes.Valid {
+		resp.Notes = &guest.Notes.String
+	}
+	return resp
+}
@@ -0,0 +1,240 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+type AccessWindowResponse struct {
+	Restricted bool      `json:"restricted"`
+	Within     bool      `json:"within"`
+	Holiday    bool      `json:"holiday"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+type PassScheduleRequest struct {
+	Weekdays     []int  `json:"weekdays"`
+	Start        string `json:"start"`
+	End          string `json:"end"`
+	SkipHolidays *bool  `json:"skip_holidays,omitempty"`
+}
+
+type PassScheduleResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	PassID       uuid.UUID  `json:"pass_id"`
+	Weekdays     []int      `json:"weekdays"`
+	Start        string     `json:"start"`
+	End          string     `json:"end"`
+	SkipHolidays bool       `json:"skip_holidays"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+}
+
+type HolidayRequest struct {
+	Day  string `json:"day"`
+	Name string `json:"name"`
+}
+
+type HolidayResponse struct {
+	Day       string     `json:"day"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+}
+
+func (h *Handler) HandleListPassSchedules(w http.ResponseWriter, r *http.Request) {
+	passID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	pass, err := h.Service.GetPass(r.Context(), passID)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if roleFromContext(r) == string(auth.RoleResident) && pass.OwnerUserID != actorFromContext(r) {
+		WriteError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	schedules, err := h.Service.ListPassSchedules(r.Context(), passID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	resp := make([]PassScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		resp = append(resp, mapPassSchedule(schedule))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleCreatePassSchedule(w http.ResponseWriter, r *http.Request) {
+	passID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req PassScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if _, err := h.Service.GetPass(r.Context(), passID); err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	skipHolidays := true
+	if req.SkipHolidays != nil {
+		skipHolidays = *req.SkipHolidays
+	}
+	schedule, err := h.Service.CreatePassSchedule(r.Context(), service.PassScheduleInput{
+		PassID:       passID,
+		Weekdays:     req.Weekdays,
+		Start:        req.Start,
+		End:          req.End,
+		SkipHolidays: skipHolidays,
+		ActorID:      actorFromContext(r),
+	})
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapPassSchedule(schedule))
+}
+
+func (h *Handler) HandleDeletePassSchedule(w http.ResponseWriter, r *http.Request) {
+	passID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "scheduleId"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.Service.DeletePassSchedule(r.Context(), passID, scheduleID); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "delete error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleListHolidays(w http.ResponseWriter, r *http.Request) {
+	// The default year is the one already under way at the site.
+	now := time.Now().In(h.Service.Location())
+	from := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), 12, 31, 0, 0, 0, 0, time.UTC)
+	if value := r.URL.Query().Get("from"); value != "" {
+		day, err := service.ParseDay(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid from")
+			return
+		}
+		from = day
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		day, err := service.ParseDay(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid to")
+			return
+		}
+		to = day
+	}
+	holidays, err := h.Service.ListHolidays(r.Context(), from, to)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := make([]HolidayResponse, 0, len(holidays))
+	for _, holiday := range holidays {
+		resp = append(resp, mapHoliday(holiday))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleCreateHoliday(w http.ResponseWriter, r *http.Request) {
+	var req HolidayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	holiday, err := h.Service.CreateHoliday(r.Context(), service.HolidayInput{
+		Day:     req.Day,
+		Name:    req.Name,
+		ActorID: actorFromContext(r),
+	})
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapHoliday(holiday))
+}
+
+func (h *Handler) HandleDeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	day, err := service.ParseDay(chi.URLParam(r, "day"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid day")
+		return
+	}
+	if err := h.Service.DeleteHoliday(r.Context(), day); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			WriteError(w, http.StatusNotFound, "not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "delete error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mapScheduleStatus(status service.ScheduleStatus) *AccessWindowResponse {
+	return &AccessWindowResponse{
+		Restricted: status.Restricted,
+		Within:     status.Within,
+		Holiday:    status.Holiday,
+		CheckedAt:  status.CheckedAt,
+	}
+}
+
+func mapPassSchedule(schedule repo.PassSchedule) PassScheduleResponse {
+	resp := PassScheduleResponse{
+		ID:           schedule.ID,
+		PassID:       schedule.PassID,
+		Weekdays:     service.MaskWeekdays(schedule.Weekdays),
+		Start:        service.FormatClock(schedule.StartMinute),
+		End:          service.FormatClock(schedule.EndMinute),
+		SkipHolidays: schedule.SkipHolidays,
+		CreatedAt:    schedule.CreatedAt,
+	}
+	if schedule.CreatedBy.Valid {
+		resp.CreatedBy = &schedule.CreatedBy.UUID
+	}
+	return resp
+}
+
+func mapHoliday(holiday repo.Holiday) HolidayResponse {
+	resp := HolidayResponse{
+		Day:       holiday.Day.Format(time.DateOnly),
+		Name:      holiday.Name,
+		CreatedAt: holiday.CreatedAt,
+	}
+	if holiday.CreatedBy.Valid {
+		resp.CreatedBy = &holiday.CreatedBy.UUID
+	}
+	return resp
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+type ShiftStartRequest struct {
+	GateID *uuid.UUID `json:"gate_id"`
+}
+
+type ShiftEndRequest struct {
+	HandoverNote string `json:"handover_note"`
+}
+
+type ShiftSummaryResponse struct {
+	Entries         int64 `json:"entries"`
+	Exits           int64 `json:"exits"`
+	GuestEntries    int64 `json:"guest_entries"`
+	Anomalies       int64 `json:"anomalies"`
+	WatchlistAlerts int64 `json:"watchlist_alerts"`
+	Incidents       int64 `json:"incidents"`
+	VehiclesOnSite  int64 `json:"vehicles_on_site"`
+}
+
+// ShiftHandoverResponse is the note left by the previous guard.
+type ShiftHandoverResponse struct {
+	ShiftID     uuid.UUID  `json:"shift_id"`
+	GuardUserID uuid.UUID  `json:"guard_user_id"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	Note        *string    `json:"note,omitempty"`
+}
+
+type ShiftResponse struct {
+	ID           uuid.UUID              `json:"id"`
+	GuardUserID  uuid.UUID              `json:"guard_user_id"`
+	GateID       *uuid.UUID             `json:"gate_id,omitempty"`
+	StartedAt    time.Time              `json:"started_at"`
+	EndedAt      *time.Time             `json:"ended_at,omitempty"`
+	EndedBy      *uuid.UUID             `json:"ended_by,omitempty"`
+	HandoverNote *string                `json:"handover_note,omitempty"`
+	Summary      ShiftSummaryResponse   `json:"summary"`
+	Handover     *ShiftHandoverResponse `json:"handover,omitempty"`
+}
+
+type ShiftListItemResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	GuardUserID   uuid.UUID  `json:"guard_user_id"`
+	GuardFullName string     `json:"guard_full_name"`
+	GateID        *uuid.UUID `json:"gate_id,omitempty"`
+	GateName      *string    `json:"gate_name,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	HandoverNote  *string    `json:"handover_note,omitempty"`
+	OnSiteAtEnd   *int32     `json:"on_site_at_end,omitempty"`
+}
+
+func (h *Handler) HandleStartShift(w http.ResponseWriter, r *http.Request) {
+	var req ShiftStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	report, err := h.Service.StartShift(r.Context(), service.ShiftStartInput{
+		GuardID: actorFromContext(r),
+		GateID:  derefUUID(req.GateID),
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrShiftOpen):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, service.ErrNotGuard):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case writeMovementError(w, err):
+		return
+	default:
+		WriteError(w, http.StatusInternalServerError, "shift error")
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapShift(report))
+}
+
+func (h *Handler) HandleCurrentShift(w http.ResponseWriter, r *http.Request) {
+	report, err := h.Service.CurrentShift(r.Context(), actorFromContext(r))
+	switch {
+	case errors.Is(err, service.ErrNoOpenShift):
+		WriteError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "shift error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapShift(report))
+}
+
+func (h *Handler) HandleEndCurrentShift(w http.ResponseWriter, r *http.Request) {
+	actor := actorFromContext(r)
+	h.endShift(w, r, service.ShiftEndInput{GuardID: actor, ActorID: actor})
+}
+
+func (h *Handler) HandleEndShift(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	h.endShift(w, r, service.ShiftEndInput{ShiftID: id, ActorID: actorFromContext(r)})
+}
+
+func (h *Handler) endShift(w http.ResponseWriter, r *http.Request, input service.ShiftEndInput) {
+	var req ShiftEndRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	input.HandoverNote = req.HandoverNote
+	report, err := h.Service.EndShift(r.Context(), input)
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	case errors.Is(err, service.ErrNoOpenShift):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "shift error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapShift(report))
+}
+
+func (h *Handler) HandleGetShift(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	report, err := h.Service.GetShift(r.Context(), id)
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "shift error")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapShift(report))
+}
+
+func (h *Handler) HandleListShifts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	loc := h.Service.Location()
+	filter := service.ShiftFilter{OpenOnly: query.Get("open") == "true"}
+	var err error
+	if filter.From, err = parseExportTime(query.Get("from"), loc, false); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+	if filter.To, err = parseExportTime(query.Get("to"), loc, true); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid to")
+		return
+	}
+	if raw := query.Get("guard_id"); raw != "" {
+		if filter.GuardID, err = uuid.Parse(raw); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid guard_id")
+			return
+		}
+	}
+	limit, offset := parsePagination(r)
+	rows, err := h.Service.ListShifts(r.Context(), filter, limit, offset)
+	switch {
+	case errors.Is(err, service.ErrInvalidRange):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		WriteError(w, http.StatusInternalServerError, "list error")
+		return
+	}
+	resp := make([]ShiftListItemResponse, 0, len(rows))
+	for _, row := range rows {
+		item := ShiftListItemResponse{
+			ID:            row.ID,
+			GuardUserID:   row.GuardUserID,
+			GuardFullName: row.GuardFullName,
+			StartedAt:     row.StartedAt,
+		}
+		if row.GateID.Valid {
+			item.GateID = &row.GateID.UUID
+		}
+		if row.GateName.Valid {
+			item.GateName = &row.GateName.String
+		}
+		if row.EndedAt.Valid {
+			item.EndedAt = &row.EndedAt.Time
+		}
+		if row.HandoverNote.Valid {
+			item.HandoverNote = &row.HandoverNote.String
+		}
+		if row.OnSiteAtEnd.Valid {
+			item.OnSiteAtEnd = &row.OnSiteAtEnd.Int32
+		}
+		resp = append(resp, item)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func mapShift(report service.ShiftReport) ShiftResponse {
+	resp := ShiftResponse{
+		ID:          report.ID,
+		GuardUserID: report.GuardUserID,
+		StartedAt:   report.StartedAt,
+		Summary: ShiftSummaryResponse{
+			Entries:         report.Summary.Entries,
+			Exits:           report.Summary.Exits,
+			GuestEntries:    report.Summary.GuestEntries,
+			Anomalies:       report.Summary.Anomalies,
+			WatchlistAlerts: report.Summary.WatchlistAlerts,
+			Incidents:       report.Summary.Incidents,
+			VehiclesOnSite:  report.Summary.VehiclesOnSite,
+		},
+	}
+	if report.GateID.Valid {
+		resp.GateID = &report.GateID.UUID
+	}
+	if report.EndedAt.Valid {
+		resp.EndedAt = &report.EndedAt.Time
+	}
+	if report.EndedBy.Valid {
+		resp.EndedBy = &report.EndedBy.UUID
+	}
+	if report.HandoverNote.Valid {
+		resp.HandoverNote = &report.HandoverNote.String
+	}
+	if report.Handover != nil {
+		resp.Handover = mapShiftHandover(*report.Handover)
+	}
+	return resp
+}
+
+func mapShiftHandover(shift repo.GuardShift) *ShiftHandoverResponse {
+	resp := &ShiftHandoverResponse{ShiftID: shift.ID, GuardUserID: shift.GuardUserID}
+	if shift.EndedAt.Valid {
+		resp.EndedAt = &shift.EndedAt.Time
+	}
+	if shift.HandoverNote.Valid {
+		resp.Note = &shift.HandoverNote.String
+	}
+	return resp
+}
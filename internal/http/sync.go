@@ -0,0 +1,180 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/service"
+)
+
+// SyncEntryRequest is a movement logged on a guard device while it was
+// offline. ID is generated on the device; sending it again is a no-op.
+type SyncEntryRequest struct {
+	ID             uuid.UUID  `json:"id"`
+	PassID         *uuid.UUID `json:"pass_id"`
+	GuestRequestID *uuid.UUID `json:"guest_request_id"`
+	Action         string     `json:"action"`
+	DeviceTime     time.Time  `json:"device_time"`
+	GateID         *uuid.UUID `json:"gate_id"`
+	Comment        *string    `json:"comment"`
+	OverrideReason *string    `json:"override_reason"`
+}
+
+type SyncBatchRequest struct {
+	Entries []SyncEntryRequest `json:"entries"`
+}
+
+type SyncResultResponse struct {
+	ID       uuid.UUID         `json:"id"`
+	Status   string            `json:"status"`
+	Conflict string            `json:"conflict,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Entry    *EntryLogResponse `json:"entry,omitempty"`
+}
+
+type SyncBatchResponse struct {
+	Results []SyncResultResponse `json:"results"`
+}
+
+type SyncPassResponse struct {
+	ID              uuid.UUID `json:"id"`
+	PlateNumber     string    `json:"plate_number"`
+	VehicleBrand    *string   `json:"vehicle_brand,omitempty"`
+	VehicleColor    *string   `json:"vehicle_color,omitempty"`
+	OwnerUserID     uuid.UUID `json:"owner_user_id"`
+	OwnerFullName   string    `json:"owner_full_name"`
+	OwnerPlotNumber *string   `json:"owner_plot_number,omitempty"`
+	Active          bool      `json:"active"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type SyncGuestResponse struct {
+	ID                 uuid.UUID `json:"id"`
+	GuestFullName      string    `json:"guest_full_name"`
+	PlateNumber        string    `json:"plate_number"`
+	GuestType          string    `json:"guest_type"`
+	CompanyName        *string   `json:"company_name,omitempty"`
+	ValidFrom          time.Time `json:"valid_from"`
+	ValidTo            time.Time `json:"valid_to"`
+	Status             string    `json:"status"`
+	ResidentFullName   string    `json:"resident_full_name"`
+	ResidentPlotNumber *string   `json:"resident_plot_number,omitempty"`
+	Active             bool      `json:"active"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// SyncDeltaResponse carries the offline lists. Cursor is passed back as
+// since on the next call; inactive rows tell the device what to drop.
+type SyncDeltaResponse struct {
+	Full   bool                `json:"full"`
+	Cursor time.Time           `json:"cursor"`
+	Passes []SyncPassResponse  `json:"passes"`
+	Guests []SyncGuestResponse `json:"guests"`
+}
+
+// HandleSyncEntryLogs uploads the entries a guard device logged offline.
+// Every entry gets its own result, so a refused entry never fails the batch.
+func (h *Handler) HandleSyncEntryLogs(w http.ResponseWriter, r *http.Request) {
+	var req SyncBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	items := make([]service.SyncEntryInput, 0, len(req.Entries))
+	for _, entry := range req.Entries {
+		items = append(items, service.SyncEntryInput{
+			ID:             entry.ID,
+			PassID:         derefUUID(entry.PassID),
+			GuestID:        derefUUID(entry.GuestRequestID),
+			Action:         entry.Action,
+			DeviceAt:       entry.DeviceTime,
+			GateID:         derefUUID(entry.GateID),
+			Comment:        derefString(entry.Comment),
+			OverrideReason: derefString(entry.OverrideReason),
+		})
+	}
+	results, err := h.Service.SyncEntryLogs(r.Context(), actorFromContext(r), items)
+	if errors.Is(err, service.ErrSyncBatchSize) {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "sync error")
+		return
+	}
+	resp := SyncBatchResponse{Results: make([]SyncResultResponse, 0, len(results))}
+	for _, result := range results {
+		item := SyncResultResponse{ID: result.ID, Status: result.Status, Conflict: result.Conflict, Error: result.Error}
+		if result.Entry != nil {
+			entry := mapEntryLog(*result.Entry)
+			item.Entry = &entry
+		}
+		if h.Metrics != nil && (result.Status == service.SyncStatusCreated || result.Status == service.SyncStatusConflict) {
+			h.Metrics.Passes.WithLabelValues("sync_" + result.Status).Inc()
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleSyncDelta returns what changed since the cursor of the previous
+// call, or a full snapshot without since.
+func (h *Handler) HandleSyncDelta(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, raw); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+	}
+	snapshot, err := h.Service.SyncDelta(r.Context(), since)
+	if errors.Is(err, service.ErrInvalidRange) {
+		WriteError(w, http.StatusBadRequest, "since is in the future")
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "sync error")
+		return
+	}
+	resp := SyncDeltaResponse{
+		Full:   snapshot.Full,
+		Cursor: snapshot.Cursor,
+		Passes: make([]SyncPassResponse, 0, len(snapshot.Passes)),
+		Guests: make([]SyncGuestResponse, 0, len(snapshot.Guests)),
+	}
+	for _, pass := range snapshot.Passes {
+		resp.Passes = append(resp.Passes, SyncPassResponse{
+			ID:              pass.ID,
+			PlateNumber:     pass.PlateNumber,
+			VehicleBrand:    nullStringPtr(pass.VehicleBrand),
+			VehicleColor:    nullStringPtr(pass.VehicleColor),
+			OwnerUserID:     pass.OwnerUserID,
+			OwnerFullName:   pass.OwnerFullName,
+			OwnerPlotNumber: nullStringPtr(pass.OwnerPlotNumber),
+			Active:          pass.Active,
+			UpdatedAt:       pass.UpdatedAt,
+		})
+	}
+	for _, guest := range snapshot.Guests {
+		resp.Guests = append(resp.Guests, SyncGuestResponse{
+			ID:                 guest.ID,
+			GuestFullName:      guest.GuestFullName,
+			PlateNumber:        guest.PlateNumber,
+			GuestType:          guest.GuestType,
+			CompanyName:        nullStringPtr(guest.CompanyName),
+			ValidFrom:          guest.ValidFrom,
+			ValidTo:            guest.ValidTo,
+			Status:             guest.Status,
+			ResidentFullName:   guest.ResidentFullName,
+			ResidentPlotNumber: nullStringPtr(guest.ResidentPlotNumber),
+			Active:             guest.Active,
+			UpdatedAt:          guest.UpdatedAt,
+		})
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
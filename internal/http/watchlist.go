@@ -0,0 +1,285 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/service"
+)
+
+type WatchlistRequest struct {
+	PlateNumber string     `json:"plate_number"`
+	Severity    string     `json:"severity"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// WatchlistUpdateRequest is a partial update; an explicit "expires_at": null
+// removes the expiry.
+type WatchlistUpdateRequest struct {
+	PlateNumber *string      `json:"plate_number"`
+	Severity    *string      `json:"severity"`
+	Reason      *string      `json:"reason"`
+	ExpiresAt   optionalTime `json:"expires_at"`
+}
+
+type optionalTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+func (o *optionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+type WatchlistResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	PlateNumber string     `json:"plate_number"`
+	Severity    string     `json:"severity"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty"`
+}
+
+type WatchlistFlagResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Severity  string     `json:"severity"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type WatchlistHitResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	WatchlistID    uuid.UUID  `json:"watchlist_id"`
+	PlateNumber    string     `json:"plate_number"`
+	PassID         *uuid.UUID `json:"pass_id,omitempty"`
+	GuestRequestID *uuid.UUID `json:"guest_request_id,omitempty"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	Source         string     `json:"source"`
+	Outcome        string     `json:"outcome"`
+	Comment        *string    `json:"comment,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type WatchlistBlockedResponse struct {
+	Error     string                `json:"error"`
+	Watchlist WatchlistFlagResponse `json:"watchlist"`
+}
+
+func (h *Handler) HandleListWatchlist(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+	includeExpired := r.URL.Query().Get("includeExpired") == "true"
+	entries, err := h.Service.ListWatchlist(r.Context(), includeExpired, limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	resp := make([]WatchlistResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, mapWatchlist(entry))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) HandleCreateWatchlist(w http.ResponseWriter, r *http.Request) {
+	var req WatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	entry, err := h.Service.CreateWatchlistEntry(r.Context(), service.WatchlistInput{
+		PlateNumber: req.PlateNumber,
+		Severity:    req.Severity,
+		Reason:      req.Reason,
+		ExpiresAt:   toNullTime(req.ExpiresAt),
+		ActorID:     actorFromContext(r),
+	})
+	if err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, mapWatchlist(entry))
+}
+
+func (h *Handler) HandleGetWatchlist(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	entry, err := h.Service.GetWatchlistEntry(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapWatchlist(entry))
+}
+
+func (h *Handler) HandleUpdateWatchlist(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req WatchlistUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	entry, err := h.Service.GetWatchlistEntry(r.Context(), id)
+	if err != nil {
+		WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	input := service.WatchlistInput{
+		ID:          id,
+		PlateNumber: entry.PlateNumber,
+		Severity:    entry.Severity,
+		Reason:      entry.Reason,
+		ExpiresAt:   entry.ExpiresAt,
+		ActorID:     actorFromContext(r),
+	}
+	if req.PlateNumber != nil {
+		input.PlateNumber = *req.PlateNumber
+	}
+	if req.Severity != nil {
+		input.Severity = *req.Severity
+	}
+	if req.Reason != nil {
+		input.Reason = *req.Reason
+	}
+	if req.ExpiresAt.Set {
+		input.ExpiresAt = toNullTime(req.ExpiresAt.Value)
+	}
+	updated, err := h.Service.UpdateWatchlistEntry(r.Context(), input)
+	if err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, mapWatchlist(updated))
+}
+
+func (h *Handler) HandleDeleteWatchlist(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.Service.DeleteWatchlistEntry(r.Context(), id, actorFromContext(r)); err != nil {
+		writeWatchlistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleListWatchlistHits(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	limit, offset := parsePagination(r)
+	hits, err := h.Service.ListWatchlistHits(r.Context(), id, limit, offset)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "error")
+		return
+	}
+	resp := make([]WatchlistHitResponse, 0, len(hits))
+	for _, hit := range hits {
+		resp = append(resp, mapWatchlistHit(hit))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func writeWatchlistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrWatchlistExists):
+		WriteError(w, http.StatusConflict, err.Error())
+	default:
+		WriteError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func mapWatchlist(entry repo.PlateWatchlist) WatchlistResponse {
+	resp := WatchlistResponse{
+		ID:          entry.ID,
+		PlateNumber: entry.PlateNumber,
+		Severity:    entry.Severity,
+		Reason:      entry.Reason,
+		CreatedAt:   entry.CreatedAt,
+		UpdatedAt:   entry.UpdatedAt,
+	}
+	if entry.ExpiresAt.Valid {
+		resp.ExpiresAt = &entry.ExpiresAt.Time
+	}
+	if entry.CreatedBy.Valid {
+		resp.CreatedBy = &entry.CreatedBy.UUID
+	}
+	if entry.UpdatedBy.Valid {
+		resp.UpdatedBy = &entry.UpdatedBy.UUID
+	}
+	return resp
+}
+
+func mapWatchlistFlag(entry *repo.PlateWatchlist) *WatchlistFlagResponse {
+	if entry == nil {
+		return nil
+	}
+	resp := &WatchlistFlagResponse{ID: entry.ID, Severity: entry.Severity, Reason: entry.Reason}
+	if entry.ExpiresAt.Valid {
+		resp.ExpiresAt = &entry.ExpiresAt.Time
+	}
+	return resp
+}
+
+// mapGateWatchlist shows the watchlist entry a gate check matched.
+func mapGateWatchlist(hit *service.WatchlistHit) *WatchlistFlagResponse {
+	if hit == nil {
+		return nil
+	}
+	return mapWatchlistFlag(&hit.Entry)
+}
+
+func mapWatchlistHit(hit repo.WatchlistHit) WatchlistHitResponse {
+	resp := WatchlistHitResponse{
+		ID:          hit.ID,
+		WatchlistID: hit.WatchlistID,
+		PlateNumber: hit.PlateNumber,
+		Source:      hit.Source,
+		Outcome:     hit.Outcome,
+		CreatedAt:   hit.CreatedAt,
+	}
+	if hit.PassID.Valid {
+		resp.PassID = &hit.PassID.UUID
+	}
+	if hit.GuestRequestID.Valid {
+		resp.GuestRequestID = &hit.GuestRequestID.UUID
+	}
+	if hit.UserID.Valid {
+		resp.UserID = &hit.UserID.UUID
+	}
+	if hit.Comment.Valid {
+		resp.Comment = &hit.Comment.String
+	}
+	return resp
+}
+
+func toNullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
@@ -8,30 +8,92 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const createEntryLog = `-- name: CreateEntryLog :one
-INSERT INTO entry_logs (pass_id, guard_user_id, action, comment)
-VALUES ($1, $2, $3, $4)
-RETURNING id, pass_id, guard_user_id, action, action_at, comment
+INSERT INTO entry_logs (
+    id, pass_id, guest_request_id, guard_user_id, action, action_at, comment, anomaly, override_reason,
+    amends_id, corrected_action, corrected_action_at, gate_id, shift_id, device_at, seq, prev_hash, hash
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING id, pass_id, guard_user_id, action, action_at, comment, guest_request_id, anomaly, override_reason, seq, prev_hash, hash, amends_id, corrected_action, corrected_action_at, gate_id, shift_id, device_at
 `
 
 type CreateEntryLogParams struct {
-	PassID      uuid.UUID      `json:"pass_id"`
-	GuardUserID uuid.UUID      `json:"guard_user_id"`
-	Action      string         `json:"action"`
-	Comment     sql.NullString `json:"comment"`
+	ID                uuid.UUID      `json:"id"`
+	PassID            uuid.NullUUID  `json:"pass_id"`
+	GuestRequestID    uuid.NullUUID  `json:"guest_request_id"`
+	GuardUserID       uuid.UUID      `json:"guard_user_id"`
+	Action            string         `json:"action"`
+	ActionAt          time.Time      `json:"action_at"`
+	Comment           sql.NullString `json:"comment"`
+	Anomaly           sql.NullString `json:"anomaly"`
+	OverrideReason    sql.NullString `json:"override_reason"`
+	AmendsID          uuid.NullUUID  `json:"amends_id"`
+	CorrectedAction   sql.NullString `json:"corrected_action"`
+	CorrectedActionAt sql.NullTime   `json:"corrected_action_at"`
+	GateID            uuid.NullUUID  `json:"gate_id"`
+	ShiftID           uuid.NullUUID  `json:"shift_id"`
+	DeviceAt          sql.NullTime   `json:"device_at"`
+	Seq               int64          `json:"seq"`
+	PrevHash          string         `json:"prev_hash"`
+	Hash              string         `json:"hash"`
 }
 
 func (q *Queries) CreateEntryLog(ctx context.Context, arg CreateEntryLogParams) (EntryLog, error) {
 	row := q.db.QueryRowContext(ctx, createEntryLog,
+		arg.ID,
 		arg.PassID,
+		arg.GuestRequestID,
 		arg.GuardUserID,
 		arg.Action,
+		arg.ActionAt,
 		arg.Comment,
+		arg.Anomaly,
+		arg.OverrideReason,
+		arg.AmendsID,
+		arg.CorrectedAction,
+		arg.CorrectedActionAt,
+		arg.GateID,
+		arg.ShiftID,
+		arg.DeviceAt,
+		arg.Seq,
+		arg.PrevHash,
+		arg.Hash,
+	)
+	var i EntryLog
+	err := row.Scan(
+		&i.ID,
+		&i.PassID,
+		&i.GuardUserID,
+		&i.Action,
+		&i.ActionAt,
+		&i.Comment,
+		&i.GuestRequestID,
+		&i.Anomaly,
+		&i.OverrideReason,
+		&i.Seq,
+		&i.PrevHash,
+		&i.Hash,
+		&i.AmendsID,
+		&i.CorrectedAction,
+		&i.CorrectedActionAt,
+		&i.GateID,
+		&i.ShiftID,
+		&i.DeviceAt,
 	)
+	return i, err
+}
+
+const getEntryLog = `-- name: GetEntryLog :one
+SELECT id, pass_id, guard_user_id, action, action_at, comment, guest_request_id, anomaly, override_reason, seq, prev_hash, hash, amends_id, corrected_action, corrected_action_at, gate_id, shift_id, device_at FROM entry_logs WHERE id = $1
+`
+
+func (q *Queries) GetEntryLog(ctx context.Context, id uuid.UUID) (EntryLog, error) {
+	row := q.db.QueryRowContext(ctx, getEntryLog, id)
 	var i EntryLog
 	err := row.Scan(
 		&i.ID,
@@ -40,25 +102,57 @@ func (q *Queries) CreateEntryLog(ctx context.Context, arg CreateEntryLogParams)
 		&i.Action,
 		&i.ActionAt,
 		&i.Comment,
+		&i.GuestRequestID,
+		&i.Anomaly,
+		&i.OverrideReason,
+		&i.Seq,
+		&i.PrevHash,
+		&i.Hash,
+		&i.AmendsID,
+		&i.CorrectedAction,
+		&i.CorrectedActionAt,
+		&i.GateID,
+		&i.ShiftID,
+		&i.DeviceAt,
+	)
+	return i, err
+}
+
+const getEntryLogChainHead = `-- name: GetEntryLogChainHead :one
+SELECT seq, hash FROM entry_logs
+ORDER BY seq DESC
+LIMIT 1
+`
+
+type GetEntryLogChainHeadRow struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+func (q *Queries) GetEntryLogChainHead(ctx context.Context) (GetEntryLogChainHeadRow, error) {
+	row := q.db.QueryRowContext(ctx, getEntryLogChainHead)
+	var i GetEntryLogChainHeadRow
+	err := row.Scan(
+		&i.Seq,
+		&i.Hash,
 	)
 	return i, err
 }
 
-const listEntryLogsByPass = `-- name: ListEntryLogsByPass :many
-SELECT id, pass_id, guard_user_id, action, action_at, comment FROM entry_logs
-WHERE pass_id = $1
-ORDER BY action_at DESC
-LIMIT $2 OFFSET $3
+const listEntryLogChain = `-- name: ListEntryLogChain :many
+SELECT id, pass_id, guard_user_id, action, action_at, comment, guest_request_id, anomaly, override_reason, seq, prev_hash, hash, amends_id, corrected_action, corrected_action_at, gate_id, shift_id, device_at FROM entry_logs
+WHERE seq > $1
+ORDER BY seq
+LIMIT $2
 `
 
-type ListEntryLogsByPassParams struct {
-	PassID uuid.UUID `json:"pass_id"`
-	Limit  int32     `json:"limit"`
-	Offset int32     `json:"offset"`
+type ListEntryLogChainParams struct {
+	Seq   int64 `json:"seq"`
+	Limit int32 `json:"limit"`
 }
 
-func (q *Queries) ListEntryLogsByPass(ctx context.Context, arg ListEntryLogsByPassParams) ([]EntryLog, error) {
-	rows, err := q.db.QueryContext(ctx, listEntryLogsByPass, arg.PassID, arg.Limit, arg.Offset)
+func (q *Queries) ListEntryLogChain(ctx context.Context, arg ListEntryLogChainParams) ([]EntryLog, error) {
+	rows, err := q.db.QueryContext(ctx, listEntryLogChain, arg.Seq, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +167,151 @@ func (q *Queries) ListEntryLogsByPass(ctx context.Context, arg ListEntryLogsByPa
 			&i.Action,
 			&i.ActionAt,
 			&i.Comment,
+			&i.GuestRequestID,
+			&i.Anomaly,
+			&i.OverrideReason,
+			&i.Seq,
+			&i.PrevHash,
+			&i.Hash,
+			&i.AmendsID,
+			&i.CorrectedAction,
+			&i.CorrectedActionAt,
+			&i.GateID,
+			&i.ShiftID,
+			&i.DeviceAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntryLogs = `-- name: ListEntryLogs :many
+SELECT e.id, e.pass_id, e.guest_request_id, e.guard_user_id, e.action, e.action_at, e.comment,
+       e.anomaly, e.override_reason, e.seq, e.hash, e.amends_id, e.corrected_action, e.corrected_action_at,
+       e.gate_id, e.device_at, gt.name AS gate_name,
+       COALESCE(p.plate_number, g.plate_number) AS plate_number,
+       COALESCE(p.owner_user_id, g.resident_user_id) AS owner_user_id,
+       COALESCE(o.full_name, r.full_name) AS owner_full_name,
+       COALESCE(o.plot_number, r.plot_number) AS owner_plot_number,
+       g.guest_full_name,
+       gu.full_name AS guard_full_name,
+       ph.id AS photo_id
+FROM entry_logs e
+LEFT JOIN passes p ON p.id = e.pass_id
+LEFT JOIN users o ON o.id = p.owner_user_id
+LEFT JOIN guest_requests g ON g.id = e.guest_request_id
+LEFT JOIN users r ON r.id = g.resident_user_id
+JOIN users gu ON gu.id = e.guard_user_id
+LEFT JOIN gates gt ON gt.id = e.gate_id
+LEFT JOIN entry_photos ph ON ph.entry_log_id = e.id
+WHERE ($1::uuid IS NULL OR e.pass_id = $1)
+  AND ($2::timestamptz IS NULL OR e.action_at >= $2)
+  AND ($3::timestamptz IS NULL OR e.action_at < $3)
+  AND ($4::text IS NULL OR e.action = $4)
+  AND ($5::uuid IS NULL OR e.guard_user_id = $5)
+  AND ($6::text IS NULL OR COALESCE(p.plate_number, g.plate_number) LIKE $6)
+  AND ($7::uuid IS NULL OR e.gate_id = $7)
+  AND ($8::text IS NULL OR COALESCE(o.plot_number, r.plot_number) = $8)
+  AND ($9::uuid IS NULL OR COALESCE(p.owner_user_id, g.resident_user_id) = $9)
+ORDER BY e.action_at DESC, e.id DESC
+LIMIT $10 OFFSET $11
+`
+
+type ListEntryLogsParams struct {
+	PassID       uuid.NullUUID  `json:"pass_id"`
+	FromTime     sql.NullTime   `json:"from_time"`
+	ToTime       sql.NullTime   `json:"to_time"`
+	Action       sql.NullString `json:"action"`
+	GuardUserID  uuid.NullUUID  `json:"guard_user_id"`
+	PlatePattern sql.NullString `json:"plate_pattern"`
+	GateID       uuid.NullUUID  `json:"gate_id"`
+	PlotNumber   sql.NullString `json:"plot_number"`
+	OwnerUserID  uuid.NullUUID  `json:"owner_user_id"`
+	PageSize     int32          `json:"page_size"`
+	PageOffset   int32          `json:"page_offset"`
+}
+
+type ListEntryLogsRow struct {
+	ID                uuid.UUID      `json:"id"`
+	PassID            uuid.NullUUID  `json:"pass_id"`
+	GuestRequestID    uuid.NullUUID  `json:"guest_request_id"`
+	GuardUserID       uuid.UUID      `json:"guard_user_id"`
+	Action            string         `json:"action"`
+	ActionAt          time.Time      `json:"action_at"`
+	Comment           sql.NullString `json:"comment"`
+	Anomaly           sql.NullString `json:"anomaly"`
+	OverrideReason    sql.NullString `json:"override_reason"`
+	Seq               int64          `json:"seq"`
+	Hash              string         `json:"hash"`
+	AmendsID          uuid.NullUUID  `json:"amends_id"`
+	CorrectedAction   sql.NullString `json:"corrected_action"`
+	CorrectedActionAt sql.NullTime   `json:"corrected_action_at"`
+	GateID            uuid.NullUUID  `json:"gate_id"`
+	DeviceAt          sql.NullTime   `json:"device_at"`
+	GateName          sql.NullString `json:"gate_name"`
+	PlateNumber       string         `json:"plate_number"`
+	OwnerUserID       uuid.UUID      `json:"owner_user_id"`
+	OwnerFullName     string         `json:"owner_full_name"`
+	OwnerPlotNumber   sql.NullString `json:"owner_plot_number"`
+	GuestFullName     sql.NullString `json:"guest_full_name"`
+	GuardFullName     string         `json:"guard_full_name"`
+	PhotoID           uuid.NullUUID  `json:"photo_id"`
+}
+
+func (q *Queries) ListEntryLogs(ctx context.Context, arg ListEntryLogsParams) ([]ListEntryLogsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntryLogs,
+		arg.PassID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Action,
+		arg.GuardUserID,
+		arg.PlatePattern,
+		arg.GateID,
+		arg.PlotNumber,
+		arg.OwnerUserID,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEntryLogsRow
+	for rows.Next() {
+		var i ListEntryLogsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PassID,
+			&i.GuestRequestID,
+			&i.GuardUserID,
+			&i.Action,
+			&i.ActionAt,
+			&i.Comment,
+			&i.Anomaly,
+			&i.OverrideReason,
+			&i.Seq,
+			&i.Hash,
+			&i.AmendsID,
+			&i.CorrectedAction,
+			&i.CorrectedActionAt,
+			&i.GateID,
+			&i.DeviceAt,
+			&i.GateName,
+			&i.PlateNumber,
+			&i.OwnerUserID,
+			&i.OwnerFullName,
+			&i.OwnerPlotNumber,
+			&i.GuestFullName,
+			&i.GuardFullName,
+			&i.PhotoID,
 		); err != nil {
 			return nil, err
 		}
@@ -86,3 +325,12 @@ func (q *Queries) ListEntryLogsByPass(ctx context.Context, arg ListEntryLogsByPa
 	}
 	return items, nil
 }
+
+const lockEntryLogChain = `-- name: LockEntryLogChain :exec
+SELECT pg_advisory_xact_lock(hashtext('entry_logs_chain'))
+`
+
+func (q *Queries) LockEntryLogChain(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockEntryLogChain)
+	return err
+}
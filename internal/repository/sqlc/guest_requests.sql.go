@@ -7,26 +7,38 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createGuestRequest = `-- name: CreateGuestRequest :one
-INSERT INTO guest_requests (resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_by, updated_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at
+INSERT INTO guest_requests (resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_by, updated_by, reviewed_by, reviewed_at, review_reason, guest_type, company_name, plot_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+        $9, $10, $11, $12, $13,
+        COALESCE($14::uuid, (
+            SELECT p.id FROM users u
+            JOIN plots p ON p.number = u.plot_number
+            WHERE u.id = $1 AND p.status = 'active')))
+RETURNING id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id
 `
 
 type CreateGuestRequestParams struct {
-	ResidentUserID uuid.UUID     `json:"resident_user_id"`
-	GuestFullName  string        `json:"guest_full_name"`
-	PlateNumber    string        `json:"plate_number"`
-	ValidFrom      time.Time     `json:"valid_from"`
-	ValidTo        time.Time     `json:"valid_to"`
-	Status         string        `json:"status"`
-	CreatedBy      uuid.NullUUID `json:"created_by"`
-	UpdatedBy      uuid.NullUUID `json:"updated_by"`
+	ResidentUserID uuid.UUID      `json:"resident_user_id"`
+	GuestFullName  string         `json:"guest_full_name"`
+	PlateNumber    string         `json:"plate_number"`
+	ValidFrom      time.Time      `json:"valid_from"`
+	ValidTo        time.Time      `json:"valid_to"`
+	Status         string         `json:"status"`
+	CreatedBy      uuid.NullUUID  `json:"created_by"`
+	UpdatedBy      uuid.NullUUID  `json:"updated_by"`
+	ReviewedBy     uuid.NullUUID  `json:"reviewed_by"`
+	ReviewedAt     sql.NullTime   `json:"reviewed_at"`
+	ReviewReason   sql.NullString `json:"review_reason"`
+	GuestType      string         `json:"guest_type"`
+	CompanyName    sql.NullString `json:"company_name"`
+	PlotID         uuid.NullUUID  `json:"plot_id"`
 }
 
 func (q *Queries) CreateGuestRequest(ctx context.Context, arg CreateGuestRequestParams) (GuestRequest, error) {
@@ -39,6 +51,12 @@ func (q *Queries) CreateGuestRequest(ctx context.Context, arg CreateGuestRequest
 		arg.Status,
 		arg.CreatedBy,
 		arg.UpdatedBy,
+		arg.ReviewedBy,
+		arg.ReviewedAt,
+		arg.ReviewReason,
+		arg.GuestType,
+		arg.CompanyName,
+		arg.PlotID,
 	)
 	var i GuestRequest
 	err := row.Scan(
@@ -54,12 +72,90 @@ func (q *Queries) CreateGuestRequest(ctx context.Context, arg CreateGuestRequest
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
+	)
+	return i, err
+}
+
+const deleteGuestOccurrencesFrom = `-- name: DeleteGuestOccurrencesFrom :execrows
+DELETE FROM guest_requests
+WHERE series_id = $1 AND occurrence_date >= $2 AND status IN ('pending', 'approved')
+`
+
+type DeleteGuestOccurrencesFromParams struct {
+	SeriesID       uuid.NullUUID `json:"series_id"`
+	OccurrenceDate sql.NullTime  `json:"occurrence_date"`
+}
+
+func (q *Queries) DeleteGuestOccurrencesFrom(ctx context.Context, arg DeleteGuestOccurrencesFromParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteGuestOccurrencesFrom, arg.SeriesID, arg.OccurrenceDate)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const expireGuestRequests = `-- name: ExpireGuestRequests :execrows
+UPDATE guest_requests
+SET status = 'expired',
+    updated_at = now()
+WHERE deleted_at IS NULL AND status IN ('pending', 'approved', 'suspended') AND valid_to <= $1
+`
+
+func (q *Queries) ExpireGuestRequests(ctx context.Context, validTo time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireGuestRequests, validTo)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getGuestOccurrence = `-- name: GetGuestOccurrence :one
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests WHERE series_id = $1 AND occurrence_date = $2
+`
+
+type GetGuestOccurrenceParams struct {
+	SeriesID       uuid.NullUUID `json:"series_id"`
+	OccurrenceDate sql.NullTime  `json:"occurrence_date"`
+}
+
+func (q *Queries) GetGuestOccurrence(ctx context.Context, arg GetGuestOccurrenceParams) (GuestRequest, error) {
+	row := q.db.QueryRowContext(ctx, getGuestOccurrence, arg.SeriesID, arg.OccurrenceDate)
+	var i GuestRequest
+	err := row.Scan(
+		&i.ID,
+		&i.ResidentUserID,
+		&i.GuestFullName,
+		&i.PlateNumber,
+		&i.ValidFrom,
+		&i.ValidTo,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
 	)
 	return i, err
 }
 
 const getGuestRequestByID = `-- name: GetGuestRequestByID :one
-SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at FROM guest_requests WHERE id = $1 AND deleted_at IS NULL
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetGuestRequestByID(ctx context.Context, id uuid.UUID) (GuestRequest, error) {
@@ -78,12 +174,20 @@ func (q *Queries) GetGuestRequestByID(ctx context.Context, id uuid.UUID) (GuestR
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
 	)
 	return i, err
 }
 
 const getGuestRequestByIDAny = `-- name: GetGuestRequestByIDAny :one
-SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at FROM guest_requests WHERE id = $1
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests WHERE id = $1
 `
 
 func (q *Queries) GetGuestRequestByIDAny(ctx context.Context, id uuid.UUID) (GuestRequest, error) {
@@ -102,12 +206,189 @@ func (q *Queries) GetGuestRequestByIDAny(ctx context.Context, id uuid.UUID) (Gue
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
 	)
 	return i, err
 }
 
+const getGuestRequestByIDForUpdate = `-- name: GetGuestRequestByIDForUpdate :one
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+`
+
+func (q *Queries) GetGuestRequestByIDForUpdate(ctx context.Context, id uuid.UUID) (GuestRequest, error) {
+	row := q.db.QueryRowContext(ctx, getGuestRequestByIDForUpdate, id)
+	var i GuestRequest
+	err := row.Scan(
+		&i.ID,
+		&i.ResidentUserID,
+		&i.GuestFullName,
+		&i.PlateNumber,
+		&i.ValidFrom,
+		&i.ValidTo,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
+	)
+	return i, err
+}
+
+const listGateGuests = `-- name: ListGateGuests :many
+SELECT g.id, g.guest_full_name, g.plate_number, g.valid_from, g.valid_to, g.status,
+       g.guest_type, g.company_name,
+       u.full_name AS resident_full_name, u.plot_number AS resident_plot_number
+FROM guest_requests g
+JOIN users u ON u.id = g.resident_user_id
+WHERE g.deleted_at IS NULL AND u.deleted_at IS NULL
+  AND g.status IN ('approved', 'arrived')
+  AND g.valid_to > $1::timestamptz
+  AND g.valid_from <= $2::timestamptz
+  AND ($3::text IS NULL OR g.plate_number ILIKE $3)
+  AND ($4::text IS NULL OR g.guest_type = $4)
+ORDER BY g.valid_from, g.id
+LIMIT $5 OFFSET $6
+`
+
+type ListGateGuestsParams struct {
+	WindowStart  time.Time      `json:"window_start"`
+	WindowEnd    time.Time      `json:"window_end"`
+	PlatePattern sql.NullString `json:"plate_pattern"`
+	GuestType    sql.NullString `json:"guest_type"`
+	PageSize     int32          `json:"page_size"`
+	PageOffset   int32          `json:"page_offset"`
+}
+
+type ListGateGuestsRow struct {
+	ID                 uuid.UUID      `json:"id"`
+	GuestFullName      string         `json:"guest_full_name"`
+	PlateNumber        string         `json:"plate_number"`
+	ValidFrom          time.Time      `json:"valid_from"`
+	ValidTo            time.Time      `json:"valid_to"`
+	Status             string         `json:"status"`
+	GuestType          string         `json:"guest_type"`
+	CompanyName        sql.NullString `json:"company_name"`
+	ResidentFullName   string         `json:"resident_full_name"`
+	ResidentPlotNumber sql.NullString `json:"resident_plot_number"`
+}
+
+func (q *Queries) ListGateGuests(ctx context.Context, arg ListGateGuestsParams) ([]ListGateGuestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGateGuests,
+		arg.WindowStart,
+		arg.WindowEnd,
+		arg.PlatePattern,
+		arg.GuestType,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGateGuestsRow
+	for rows.Next() {
+		var i ListGateGuestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GuestFullName,
+			&i.PlateNumber,
+			&i.ValidFrom,
+			&i.ValidTo,
+			&i.Status,
+			&i.GuestType,
+			&i.CompanyName,
+			&i.ResidentFullName,
+			&i.ResidentPlotNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGuestOccurrences = `-- name: ListGuestOccurrences :many
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests
+WHERE series_id = $1::uuid AND deleted_at IS NULL
+  AND occurrence_date >= $2::date
+  AND occurrence_date <= $3::date
+ORDER BY occurrence_date
+`
+
+type ListGuestOccurrencesParams struct {
+	SeriesID uuid.UUID `json:"series_id"`
+	FirstDay time.Time `json:"first_day"`
+	LastDay  time.Time `json:"last_day"`
+}
+
+func (q *Queries) ListGuestOccurrences(ctx context.Context, arg ListGuestOccurrencesParams) ([]GuestRequest, error) {
+	rows, err := q.db.QueryContext(ctx, listGuestOccurrences, arg.SeriesID, arg.FirstDay, arg.LastDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GuestRequest
+	for rows.Next() {
+		var i GuestRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.ResidentUserID,
+			&i.GuestFullName,
+			&i.PlateNumber,
+			&i.ValidFrom,
+			&i.ValidTo,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.DeletedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.ReviewReason,
+			&i.SeriesID,
+			&i.OccurrenceDate,
+			&i.GuestType,
+			&i.CompanyName,
+			&i.PlotID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listGuestRequests = `-- name: ListGuestRequests :many
-SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at FROM guest_requests
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests
 WHERE ($1::bool) OR deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -141,6 +422,71 @@ func (q *Queries) ListGuestRequests(ctx context.Context, arg ListGuestRequestsPa
 			&i.CreatedBy,
 			&i.UpdatedBy,
 			&i.DeletedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.ReviewReason,
+			&i.SeriesID,
+			&i.OccurrenceDate,
+			&i.GuestType,
+			&i.CompanyName,
+			&i.PlotID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGuestRequestsByPlot = `-- name: ListGuestRequestsByPlot :many
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests
+WHERE plot_id = $1 AND deleted_at IS NULL
+ORDER BY valid_from DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListGuestRequestsByPlotParams struct {
+	PlotID uuid.NullUUID `json:"plot_id"`
+	Limit  int32         `json:"limit"`
+	Offset int32         `json:"offset"`
+}
+
+func (q *Queries) ListGuestRequestsByPlot(ctx context.Context, arg ListGuestRequestsByPlotParams) ([]GuestRequest, error) {
+	rows, err := q.db.QueryContext(ctx, listGuestRequestsByPlot, arg.PlotID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GuestRequest
+	for rows.Next() {
+		var i GuestRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.ResidentUserID,
+			&i.GuestFullName,
+			&i.PlateNumber,
+			&i.ValidFrom,
+			&i.ValidTo,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.DeletedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.ReviewReason,
+			&i.SeriesID,
+			&i.OccurrenceDate,
+			&i.GuestType,
+			&i.CompanyName,
+			&i.PlotID,
 		); err != nil {
 			return nil, err
 		}
@@ -156,7 +502,7 @@ func (q *Queries) ListGuestRequests(ctx context.Context, arg ListGuestRequestsPa
 }
 
 const listGuestRequestsByResident = `-- name: ListGuestRequestsByResident :many
-SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at FROM guest_requests
+SELECT id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id FROM guest_requests
 WHERE resident_user_id = $1 AND (($2::bool) OR deleted_at IS NULL)
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -196,6 +542,14 @@ func (q *Queries) ListGuestRequestsByResident(ctx context.Context, arg ListGuest
 			&i.CreatedBy,
 			&i.UpdatedBy,
 			&i.DeletedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.ReviewReason,
+			&i.SeriesID,
+			&i.OccurrenceDate,
+			&i.GuestType,
+			&i.CompanyName,
+			&i.PlotID,
 		); err != nil {
 			return nil, err
 		}
@@ -210,6 +564,97 @@ func (q *Queries) ListGuestRequestsByResident(ctx context.Context, arg ListGuest
 	return items, nil
 }
 
+const listOverlappingGuestRequests = `-- name: ListOverlappingGuestRequests :many
+SELECT id FROM guest_requests
+WHERE plate_number = $1
+  AND deleted_at IS NULL
+  AND status IN ('pending', 'approved', 'arrived')
+  AND valid_from < $2
+  AND valid_to > $3
+  AND id <> $4
+ORDER BY valid_from
+LIMIT 20
+`
+
+type ListOverlappingGuestRequestsParams struct {
+	PlateNumber string    `json:"plate_number"`
+	ValidTo     time.Time `json:"valid_to"`
+	ValidFrom   time.Time `json:"valid_from"`
+	ExcludeID   uuid.UUID `json:"exclude_id"`
+}
+
+func (q *Queries) ListOverlappingGuestRequests(ctx context.Context, arg ListOverlappingGuestRequestsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listOverlappingGuestRequests,
+		arg.PlateNumber,
+		arg.ValidTo,
+		arg.ValidFrom,
+		arg.ExcludeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const materializeGuestOccurrence = `-- name: MaterializeGuestOccurrence :exec
+INSERT INTO guest_requests (resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_by, updated_by, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, plot_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, (
+    SELECT p.id FROM users u
+    JOIN plots p ON p.number = u.plot_number
+    WHERE u.id = $1 AND p.status = 'active'))
+ON CONFLICT (series_id, occurrence_date) DO NOTHING
+`
+
+type MaterializeGuestOccurrenceParams struct {
+	ResidentUserID uuid.UUID      `json:"resident_user_id"`
+	GuestFullName  string         `json:"guest_full_name"`
+	PlateNumber    string         `json:"plate_number"`
+	ValidFrom      time.Time      `json:"valid_from"`
+	ValidTo        time.Time      `json:"valid_to"`
+	Status         string         `json:"status"`
+	CreatedBy      uuid.NullUUID  `json:"created_by"`
+	UpdatedBy      uuid.NullUUID  `json:"updated_by"`
+	ReviewedBy     uuid.NullUUID  `json:"reviewed_by"`
+	ReviewedAt     sql.NullTime   `json:"reviewed_at"`
+	ReviewReason   sql.NullString `json:"review_reason"`
+	SeriesID       uuid.NullUUID  `json:"series_id"`
+	OccurrenceDate sql.NullTime   `json:"occurrence_date"`
+}
+
+func (q *Queries) MaterializeGuestOccurrence(ctx context.Context, arg MaterializeGuestOccurrenceParams) error {
+	_, err := q.db.ExecContext(ctx, materializeGuestOccurrence,
+		arg.ResidentUserID,
+		arg.GuestFullName,
+		arg.PlateNumber,
+		arg.ValidFrom,
+		arg.ValidTo,
+		arg.Status,
+		arg.CreatedBy,
+		arg.UpdatedBy,
+		arg.ReviewedBy,
+		arg.ReviewedAt,
+		arg.ReviewReason,
+		arg.SeriesID,
+		arg.OccurrenceDate,
+	)
+	return err
+}
+
 const restoreGuestRequest = `-- name: RestoreGuestRequest :exec
 UPDATE guest_requests
 SET deleted_at = NULL,
@@ -228,6 +673,138 @@ func (q *Queries) RestoreGuestRequest(ctx context.Context, arg RestoreGuestReque
 	return err
 }
 
+const reviewGuestOccurrences = `-- name: ReviewGuestOccurrences :execrows
+UPDATE guest_requests
+SET status = $2,
+    reviewed_by = $3,
+    reviewed_at = now(),
+    review_reason = $4,
+    updated_at = now(),
+    updated_by = $3
+WHERE series_id = $1 AND deleted_at IS NULL AND status = 'pending'
+`
+
+type ReviewGuestOccurrencesParams struct {
+	SeriesID     uuid.NullUUID  `json:"series_id"`
+	Status       string         `json:"status"`
+	ReviewedBy   uuid.NullUUID  `json:"reviewed_by"`
+	ReviewReason sql.NullString `json:"review_reason"`
+}
+
+func (q *Queries) ReviewGuestOccurrences(ctx context.Context, arg ReviewGuestOccurrencesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reviewGuestOccurrences,
+		arg.SeriesID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewReason,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reviewGuestRequest = `-- name: ReviewGuestRequest :one
+UPDATE guest_requests
+SET status = $2,
+    reviewed_by = $3,
+    reviewed_at = now(),
+    review_reason = $4,
+    updated_at = now(),
+    updated_by = $3
+WHERE id = $1 AND deleted_at IS NULL AND status = 'pending'
+RETURNING id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id
+`
+
+type ReviewGuestRequestParams struct {
+	ID           uuid.UUID      `json:"id"`
+	Status       string         `json:"status"`
+	ReviewedBy   uuid.NullUUID  `json:"reviewed_by"`
+	ReviewReason sql.NullString `json:"review_reason"`
+}
+
+func (q *Queries) ReviewGuestRequest(ctx context.Context, arg ReviewGuestRequestParams) (GuestRequest, error) {
+	row := q.db.QueryRowContext(ctx, reviewGuestRequest,
+		arg.ID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewReason,
+	)
+	var i GuestRequest
+	err := row.Scan(
+		&i.ID,
+		&i.ResidentUserID,
+		&i.GuestFullName,
+		&i.PlateNumber,
+		&i.ValidFrom,
+		&i.ValidTo,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
+	)
+	return i, err
+}
+
+const setGuestRequestStatus = `-- name: SetGuestRequestStatus :one
+UPDATE guest_requests
+SET status = $1,
+    updated_at = now(),
+    updated_by = $2
+WHERE id = $3 AND deleted_at IS NULL AND status = $4
+RETURNING id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id
+`
+
+type SetGuestRequestStatusParams struct {
+	Status     string        `json:"status"`
+	UpdatedBy  uuid.NullUUID `json:"updated_by"`
+	ID         uuid.UUID     `json:"id"`
+	FromStatus string        `json:"from_status"`
+}
+
+func (q *Queries) SetGuestRequestStatus(ctx context.Context, arg SetGuestRequestStatusParams) (GuestRequest, error) {
+	row := q.db.QueryRowContext(ctx, setGuestRequestStatus,
+		arg.Status,
+		arg.UpdatedBy,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i GuestRequest
+	err := row.Scan(
+		&i.ID,
+		&i.ResidentUserID,
+		&i.GuestFullName,
+		&i.PlateNumber,
+		&i.ValidFrom,
+		&i.ValidTo,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
+	)
+	return i, err
+}
+
 const softDeleteGuestRequest = `-- name: SoftDeleteGuestRequest :exec
 UPDATE guest_requests
 SET deleted_at = now(),
@@ -246,38 +823,112 @@ func (q *Queries) SoftDeleteGuestRequest(ctx context.Context, arg SoftDeleteGues
 	return err
 }
 
-const updateGuestRequest = `-- name: UpdateGuestRequest :one
+const updateGuestOccurrence = `-- name: UpdateGuestOccurrence :one
 UPDATE guest_requests
 SET guest_full_name = $2,
     plate_number = $3,
     valid_from = $4,
     valid_to = $5,
     status = $6,
+    reviewed_by = $7,
+    reviewed_at = $8,
+    review_reason = $9,
     updated_at = now(),
-    updated_by = $7
-WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at
+    updated_by = $10
+WHERE id = $1 AND deleted_at IS NULL AND series_id IS NOT NULL AND status IN ('pending', 'approved')
+RETURNING id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id
+`
+
+type UpdateGuestOccurrenceParams struct {
+	ID            uuid.UUID      `json:"id"`
+	GuestFullName string         `json:"guest_full_name"`
+	PlateNumber   string         `json:"plate_number"`
+	ValidFrom     time.Time      `json:"valid_from"`
+	ValidTo       time.Time      `json:"valid_to"`
+	Status        string         `json:"status"`
+	ReviewedBy    uuid.NullUUID  `json:"reviewed_by"`
+	ReviewedAt    sql.NullTime   `json:"reviewed_at"`
+	ReviewReason  sql.NullString `json:"review_reason"`
+	UpdatedBy     uuid.NullUUID  `json:"updated_by"`
+}
+
+func (q *Queries) UpdateGuestOccurrence(ctx context.Context, arg UpdateGuestOccurrenceParams) (GuestRequest, error) {
+	row := q.db.QueryRowContext(ctx, updateGuestOccurrence,
+		arg.ID,
+		arg.GuestFullName,
+		arg.PlateNumber,
+		arg.ValidFrom,
+		arg.ValidTo,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewedAt,
+		arg.ReviewReason,
+		arg.UpdatedBy,
+	)
+	var i GuestRequest
+	err := row.Scan(
+		&i.ID,
+		&i.ResidentUserID,
+		&i.GuestFullName,
+		&i.PlateNumber,
+		&i.ValidFrom,
+		&i.ValidTo,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
+	)
+	return i, err
+}
+
+const updateGuestRequest = `-- name: UpdateGuestRequest :one
+UPDATE guest_requests
+SET guest_full_name = $1,
+    plate_number = $2,
+    valid_from = $3,
+    valid_to = $4,
+    guest_type = $5,
+    company_name = $6,
+    plot_id = COALESCE($7::uuid, plot_id),
+    updated_at = now(),
+    updated_by = $8
+WHERE id = $9 AND deleted_at IS NULL AND status IN ('pending', 'approved')
+RETURNING id, resident_user_id, guest_full_name, plate_number, valid_from, valid_to, status, created_at, updated_at, created_by, updated_by, deleted_at, reviewed_by, reviewed_at, review_reason, series_id, occurrence_date, guest_type, company_name, plot_id
 `
 
 type UpdateGuestRequestParams struct {
-	ID            uuid.UUID     `json:"id"`
-	GuestFullName string        `json:"guest_full_name"`
-	PlateNumber   string        `json:"plate_number"`
-	ValidFrom     time.Time     `json:"valid_from"`
-	ValidTo       time.Time     `json:"valid_to"`
-	Status        string        `json:"status"`
-	UpdatedBy     uuid.NullUUID `json:"updated_by"`
+	GuestFullName string         `json:"guest_full_name"`
+	PlateNumber   string         `json:"plate_number"`
+	ValidFrom     time.Time      `json:"valid_from"`
+	ValidTo       time.Time      `json:"valid_to"`
+	GuestType     string         `json:"guest_type"`
+	CompanyName   sql.NullString `json:"company_name"`
+	PlotID        uuid.NullUUID  `json:"plot_id"`
+	UpdatedBy     uuid.NullUUID  `json:"updated_by"`
+	ID            uuid.UUID      `json:"id"`
 }
 
 func (q *Queries) UpdateGuestRequest(ctx context.Context, arg UpdateGuestRequestParams) (GuestRequest, error) {
 	row := q.db.QueryRowContext(ctx, updateGuestRequest,
-		arg.ID,
 		arg.GuestFullName,
 		arg.PlateNumber,
 		arg.ValidFrom,
 		arg.ValidTo,
-		arg.Status,
+		arg.GuestType,
+		arg.CompanyName,
+		arg.PlotID,
 		arg.UpdatedBy,
+		arg.ID,
 	)
 	var i GuestRequest
 	err := row.Scan(
@@ -293,6 +944,14 @@ func (q *Queries) UpdateGuestRequest(ctx context.Context, arg UpdateGuestRequest
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ReviewReason,
+		&i.SeriesID,
+		&i.OccurrenceDate,
+		&i.GuestType,
+		&i.CompanyName,
+		&i.PlotID,
 	)
 	return i, err
 }
@@ -11,23 +11,29 @@ import (
 	"github.com/google/uuid"
 )
 
-type EntryLog struct {
-	ID          uuid.UUID      `json:"id"`
-	PassID      uuid.UUID      `json:"pass_id"`
-	GuardUserID uuid.UUID      `json:"guard_user_id"`
-	Action      string         `json:"action"`
-	ActionAt    time.Time      `json:"action_at"`
-	Comment     sql.NullString `json:"comment"`
+type BarrierEvent struct {
+	ID         uuid.UUID      `json:"id"`
+	GateID     uuid.UUID      `json:"gate_id"`
+	Command    string         `json:"command"`
+	Driver     string         `json:"driver"`
+	EntryLogID uuid.NullUUID  `json:"entry_log_id"`
+	UserID     uuid.NullUUID  `json:"user_id"`
+	Success    bool           `json:"success"`
+	State      sql.NullString `json:"state"`
+	Error      sql.NullString `json:"error"`
+	DurationMs int32          `json:"duration_ms"`
+	CreatedAt  time.Time      `json:"created_at"`
 }
 
-type GuestRequest struct {
+type Camera struct {
 	ID             uuid.UUID     `json:"id"`
-	ResidentUserID uuid.UUID     `json:"resident_user_id"`
-	GuestFullName  string        `json:"guest_full_name"`
-	PlateNumber    string        `json:"plate_number"`
-	ValidFrom      time.Time     `json:"valid_from"`
-	ValidTo        time.Time     `json:"valid_to"`
-	Status         string        `json:"status"`
+	Name           string        `json:"name"`
+	GateID         uuid.NullUUID `json:"gate_id"`
+	Direction      string        `json:"direction"`
+	OperatorUserID uuid.UUID     `json:"operator_user_id"`
+	TokenHash      string        `json:"token_hash"`
+	Active         bool          `json:"active"`
+	LastSeenAt     sql.NullTime  `json:"last_seen_at"`
 	CreatedAt      time.Time     `json:"created_at"`
 	UpdatedAt      time.Time     `json:"updated_at"`
 	CreatedBy      uuid.NullUUID `json:"created_by"`
@@ -35,6 +41,187 @@ type GuestRequest struct {
 	DeletedAt      sql.NullTime  `json:"deleted_at"`
 }
 
+type EntryLog struct {
+	ID                uuid.UUID      `json:"id"`
+	PassID            uuid.NullUUID  `json:"pass_id"`
+	GuardUserID       uuid.UUID      `json:"guard_user_id"`
+	Action            string         `json:"action"`
+	ActionAt          time.Time      `json:"action_at"`
+	Comment           sql.NullString `json:"comment"`
+	GuestRequestID    uuid.NullUUID  `json:"guest_request_id"`
+	Anomaly           sql.NullString `json:"anomaly"`
+	OverrideReason    sql.NullString `json:"override_reason"`
+	Seq               int64          `json:"seq"`
+	PrevHash          string         `json:"prev_hash"`
+	Hash              string         `json:"hash"`
+	AmendsID          uuid.NullUUID  `json:"amends_id"`
+	CorrectedAction   sql.NullString `json:"corrected_action"`
+	CorrectedActionAt sql.NullTime   `json:"corrected_action_at"`
+	GateID            uuid.NullUUID  `json:"gate_id"`
+	ShiftID           uuid.NullUUID  `json:"shift_id"`
+	DeviceAt          sql.NullTime   `json:"device_at"`
+}
+
+type EntryPhoto struct {
+	ID           uuid.UUID `json:"id"`
+	EntryLogID   uuid.UUID `json:"entry_log_id"`
+	ContentType  string    `json:"content_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	StorageKey   string    `json:"storage_key"`
+	ThumbnailKey string    `json:"thumbnail_key"`
+	UploadedBy   uuid.UUID `json:"uploaded_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Gate struct {
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	CreatedBy   uuid.NullUUID  `json:"created_by"`
+	UpdatedBy   uuid.NullUUID  `json:"updated_by"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+}
+
+type GateBarrier struct {
+	GateID      uuid.UUID      `json:"gate_id"`
+	Driver      string         `json:"driver"`
+	Address     sql.NullString `json:"address"`
+	Topic       sql.NullString `json:"topic"`
+	Username    sql.NullString `json:"username"`
+	Secret      sql.NullString `json:"secret"`
+	AutoOpen    bool           `json:"auto_open"`
+	HoldSeconds int32          `json:"hold_seconds"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	UpdatedBy   uuid.NullUUID  `json:"updated_by"`
+}
+
+type GuardGate struct {
+	UserID uuid.UUID `json:"user_id"`
+	GateID uuid.UUID `json:"gate_id"`
+}
+
+type GuardShift struct {
+	ID             uuid.UUID      `json:"id"`
+	GuardUserID    uuid.UUID      `json:"guard_user_id"`
+	GateID         uuid.NullUUID  `json:"gate_id"`
+	StartedAt      time.Time      `json:"started_at"`
+	EndedAt        sql.NullTime   `json:"ended_at"`
+	EndedBy        uuid.NullUUID  `json:"ended_by"`
+	HandoverNote   sql.NullString `json:"handover_note"`
+	HandoverFromID uuid.NullUUID  `json:"handover_from_id"`
+	OnSiteAtEnd    sql.NullInt32  `json:"on_site_at_end"`
+}
+
+type GuestPin struct {
+	GuestRequestID uuid.UUID     `json:"guest_request_id"`
+	Code           string        `json:"code"`
+	CreatedAt      time.Time     `json:"created_at"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+	UsedAt         sql.NullTime  `json:"used_at"`
+}
+
+type GuestRequest struct {
+	ID             uuid.UUID      `json:"id"`
+	ResidentUserID uuid.UUID      `json:"resident_user_id"`
+	GuestFullName  string         `json:"guest_full_name"`
+	PlateNumber    string         `json:"plate_number"`
+	ValidFrom      time.Time      `json:"valid_from"`
+	ValidTo        time.Time      `json:"valid_to"`
+	Status         string         `json:"status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	CreatedBy      uuid.NullUUID  `json:"created_by"`
+	UpdatedBy      uuid.NullUUID  `json:"updated_by"`
+	DeletedAt      sql.NullTime   `json:"deleted_at"`
+	ReviewedBy     uuid.NullUUID  `json:"reviewed_by"`
+	ReviewedAt     sql.NullTime   `json:"reviewed_at"`
+	ReviewReason   sql.NullString `json:"review_reason"`
+	SeriesID       uuid.NullUUID  `json:"series_id"`
+	OccurrenceDate sql.NullTime   `json:"occurrence_date"`
+	GuestType      string         `json:"guest_type"`
+	CompanyName    sql.NullString `json:"company_name"`
+	PlotID         uuid.NullUUID  `json:"plot_id"`
+}
+
+type GuestSeries struct {
+	ID               uuid.UUID      `json:"id"`
+	ResidentUserID   uuid.UUID      `json:"resident_user_id"`
+	GuestFullName    string         `json:"guest_full_name"`
+	PlateNumber      string         `json:"plate_number"`
+	Rrule            string         `json:"rrule"`
+	StartsOn         time.Time      `json:"starts_on"`
+	EndsOn           sql.NullTime   `json:"ends_on"`
+	StartMinute      int16          `json:"start_minute"`
+	EndMinute        int16          `json:"end_minute"`
+	Status           string         `json:"status"`
+	PreviousSeriesID uuid.NullUUID  `json:"previous_series_id"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	CreatedBy        uuid.NullUUID  `json:"created_by"`
+	UpdatedBy        uuid.NullUUID  `json:"updated_by"`
+	ReviewedBy       uuid.NullUUID  `json:"reviewed_by"`
+	ReviewedAt       sql.NullTime   `json:"reviewed_at"`
+	ReviewReason     sql.NullString `json:"review_reason"`
+}
+
+type GuestTypeGate struct {
+	GuestType string    `json:"guest_type"`
+	GateID    uuid.UUID `json:"gate_id"`
+}
+
+type Holiday struct {
+	Day       time.Time     `json:"day"`
+	Name      string        `json:"name"`
+	CreatedAt time.Time     `json:"created_at"`
+	CreatedBy uuid.NullUUID `json:"created_by"`
+}
+
+type Incident struct {
+	ID             uuid.UUID      `json:"id"`
+	Category       string         `json:"category"`
+	Severity       string         `json:"severity"`
+	Status         string         `json:"status"`
+	Title          string         `json:"title"`
+	Description    sql.NullString `json:"description"`
+	OccurredAt     time.Time      `json:"occurred_at"`
+	ReportedBy     uuid.UUID      `json:"reported_by"`
+	GateID         uuid.NullUUID  `json:"gate_id"`
+	ShiftID        uuid.NullUUID  `json:"shift_id"`
+	PassID         uuid.NullUUID  `json:"pass_id"`
+	GuestRequestID uuid.NullUUID  `json:"guest_request_id"`
+	PlateNumber    sql.NullString `json:"plate_number"`
+	ResolvedAt     sql.NullTime   `json:"resolved_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	UpdatedBy      uuid.NullUUID  `json:"updated_by"`
+}
+
+type IncidentAttachment struct {
+	ID          uuid.UUID `json:"id"`
+	IncidentID  uuid.UUID `json:"incident_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	StorageKey  string    `json:"storage_key"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type IncidentComment struct {
+	ID         uuid.UUID      `json:"id"`
+	IncidentID uuid.UUID      `json:"incident_id"`
+	AuthorID   uuid.UUID      `json:"author_id"`
+	Body       string         `json:"body"`
+	StatusFrom sql.NullString `json:"status_from"`
+	StatusTo   sql.NullString `json:"status_to"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
 type Pass struct {
 	ID           uuid.UUID      `json:"id"`
 	OwnerUserID  uuid.UUID      `json:"owner_user_id"`
@@ -47,6 +234,97 @@ type Pass struct {
 	CreatedBy    uuid.NullUUID  `json:"created_by"`
 	UpdatedBy    uuid.NullUUID  `json:"updated_by"`
 	DeletedAt    sql.NullTime   `json:"deleted_at"`
+	PlotID       uuid.NullUUID  `json:"plot_id"`
+}
+
+type PassGate struct {
+	PassID uuid.UUID `json:"pass_id"`
+	GateID uuid.UUID `json:"gate_id"`
+}
+
+type PassSchedule struct {
+	ID           uuid.UUID     `json:"id"`
+	PassID       uuid.UUID     `json:"pass_id"`
+	Weekdays     int16         `json:"weekdays"`
+	StartMinute  int16         `json:"start_minute"`
+	EndMinute    int16         `json:"end_minute"`
+	SkipHolidays bool          `json:"skip_holidays"`
+	CreatedAt    time.Time     `json:"created_at"`
+	CreatedBy    uuid.NullUUID `json:"created_by"`
+}
+
+type PlateRead struct {
+	ID             uuid.UUID      `json:"id"`
+	CameraID       uuid.UUID      `json:"camera_id"`
+	PlateRaw       string         `json:"plate_raw"`
+	PlateNumber    string         `json:"plate_number"`
+	Confidence     float64        `json:"confidence"`
+	CapturedAt     time.Time      `json:"captured_at"`
+	Decision       string         `json:"decision"`
+	Reason         string         `json:"reason"`
+	PassID         uuid.NullUUID  `json:"pass_id"`
+	GuestRequestID uuid.NullUUID  `json:"guest_request_id"`
+	EntryLogID     uuid.NullUUID  `json:"entry_log_id"`
+	ReviewStatus   sql.NullString `json:"review_status"`
+	ReviewedBy     uuid.NullUUID  `json:"reviewed_by"`
+	ReviewedAt     sql.NullTime   `json:"reviewed_at"`
+	ReviewComment  sql.NullString `json:"review_comment"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type PlateWatchlist struct {
+	ID          uuid.UUID     `json:"id"`
+	PlateNumber string        `json:"plate_number"`
+	Severity    string        `json:"severity"`
+	Reason      string        `json:"reason"`
+	ExpiresAt   sql.NullTime  `json:"expires_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+	UpdatedBy   uuid.NullUUID `json:"updated_by"`
+	DeletedAt   sql.NullTime  `json:"deleted_at"`
+}
+
+type Plot struct {
+	ID           uuid.UUID      `json:"id"`
+	Number       string         `json:"number"`
+	Street       sql.NullString `json:"street"`
+	ContactName  sql.NullString `json:"contact_name"`
+	ContactPhone sql.NullString `json:"contact_phone"`
+	ContactEmail sql.NullString `json:"contact_email"`
+	Status       string         `json:"status"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	CreatedBy    uuid.NullUUID  `json:"created_by"`
+	UpdatedBy    uuid.NullUUID  `json:"updated_by"`
+}
+
+type PlotResident struct {
+	PlotID    uuid.UUID     `json:"plot_id"`
+	UserID    uuid.UUID     `json:"user_id"`
+	Relation  string        `json:"relation"`
+	CreatedAt time.Time     `json:"created_at"`
+	CreatedBy uuid.NullUUID `json:"created_by"`
+}
+
+type SavedGuest struct {
+	ID             uuid.UUID      `json:"id"`
+	ResidentUserID uuid.UUID      `json:"resident_user_id"`
+	GuestFullName  string         `json:"guest_full_name"`
+	GuestType      string         `json:"guest_type"`
+	PlateNumber    string         `json:"plate_number"`
+	CompanyName    sql.NullString `json:"company_name"`
+	Notes          sql.NullString `json:"notes"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+type SitePresence struct {
+	ID             uuid.UUID     `json:"id"`
+	PassID         uuid.NullUUID `json:"pass_id"`
+	GuestRequestID uuid.NullUUID `json:"guest_request_id"`
+	EntryLogID     uuid.UUID     `json:"entry_log_id"`
+	EnteredAt      time.Time     `json:"entered_at"`
 }
 
 type User struct {
@@ -63,3 +341,29 @@ type User struct {
 	UpdatedBy    uuid.NullUUID  `json:"updated_by"`
 	DeletedAt    sql.NullTime   `json:"deleted_at"`
 }
+
+type UserCascadeItem struct {
+	ID             uuid.UUID     `json:"id"`
+	UserID         uuid.UUID     `json:"user_id"`
+	Action         string        `json:"action"`
+	PassID         uuid.NullUUID `json:"pass_id"`
+	GuestRequestID uuid.NullUUID `json:"guest_request_id"`
+	PreviousStatus string        `json:"previous_status"`
+	NewStatus      string        `json:"new_status"`
+	CreatedAt      time.Time     `json:"created_at"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+	RestoredAt     sql.NullTime  `json:"restored_at"`
+}
+
+type WatchlistHit struct {
+	ID             uuid.UUID      `json:"id"`
+	WatchlistID    uuid.UUID      `json:"watchlist_id"`
+	PlateNumber    string         `json:"plate_number"`
+	PassID         uuid.NullUUID  `json:"pass_id"`
+	UserID         uuid.NullUUID  `json:"user_id"`
+	Source         string         `json:"source"`
+	Outcome        string         `json:"outcome"`
+	Comment        sql.NullString `json:"comment"`
+	CreatedAt      time.Time      `json:"created_at"`
+	GuestRequestID uuid.NullUUID  `json:"guest_request_id"`
+}
@@ -13,9 +13,13 @@ import (
 )
 
 const createPass = `-- name: CreatePass :one
-INSERT INTO passes (owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_by, updated_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at
+INSERT INTO passes (owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_by, updated_by, plot_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7,
+        COALESCE($8::uuid, (
+            SELECT p.id FROM users u
+            JOIN plots p ON p.number = u.plot_number
+            WHERE u.id = $1 AND p.status = 'active')))
+RETURNING id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id
 `
 
 type CreatePassParams struct {
@@ -26,6 +30,7 @@ type CreatePassParams struct {
 	Status       string         `json:"status"`
 	CreatedBy    uuid.NullUUID  `json:"created_by"`
 	UpdatedBy    uuid.NullUUID  `json:"updated_by"`
+	PlotID       uuid.NullUUID  `json:"plot_id"`
 }
 
 func (q *Queries) CreatePass(ctx context.Context, arg CreatePassParams) (Pass, error) {
@@ -37,6 +42,7 @@ func (q *Queries) CreatePass(ctx context.Context, arg CreatePassParams) (Pass, e
 		arg.Status,
 		arg.CreatedBy,
 		arg.UpdatedBy,
+		arg.PlotID,
 	)
 	var i Pass
 	err := row.Scan(
@@ -51,12 +57,13 @@ func (q *Queries) CreatePass(ctx context.Context, arg CreatePassParams) (Pass, e
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.PlotID,
 	)
 	return i, err
 }
 
 const getPassByID = `-- name: GetPassByID :one
-SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at FROM passes WHERE id = $1 AND deleted_at IS NULL
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetPassByID(ctx context.Context, id uuid.UUID) (Pass, error) {
@@ -74,12 +81,13 @@ func (q *Queries) GetPassByID(ctx context.Context, id uuid.UUID) (Pass, error) {
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.PlotID,
 	)
 	return i, err
 }
 
 const getPassByIDAny = `-- name: GetPassByIDAny :one
-SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at FROM passes WHERE id = $1
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes WHERE id = $1
 `
 
 func (q *Queries) GetPassByIDAny(ctx context.Context, id uuid.UUID) (Pass, error) {
@@ -97,12 +105,93 @@ func (q *Queries) GetPassByIDAny(ctx context.Context, id uuid.UUID) (Pass, error
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.PlotID,
+	)
+	return i, err
+}
+
+const getPassByIDAnyForUpdate = `-- name: GetPassByIDAnyForUpdate :one
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetPassByIDAnyForUpdate(ctx context.Context, id uuid.UUID) (Pass, error) {
+	row := q.db.QueryRowContext(ctx, getPassByIDAnyForUpdate, id)
+	var i Pass
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerUserID,
+		&i.PlateNumber,
+		&i.VehicleBrand,
+		&i.VehicleColor,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.PlotID,
+	)
+	return i, err
+}
+
+const getPassByIDForUpdate = `-- name: GetPassByIDForUpdate :one
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+`
+
+func (q *Queries) GetPassByIDForUpdate(ctx context.Context, id uuid.UUID) (Pass, error) {
+	row := q.db.QueryRowContext(ctx, getPassByIDForUpdate, id)
+	var i Pass
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerUserID,
+		&i.PlateNumber,
+		&i.VehicleBrand,
+		&i.VehicleColor,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.PlotID,
+	)
+	return i, err
+}
+
+const getPassByOwnerAndPlate = `-- name: GetPassByOwnerAndPlate :one
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes
+WHERE owner_user_id = $1 AND plate_number = $2 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetPassByOwnerAndPlateParams struct {
+	OwnerUserID uuid.UUID `json:"owner_user_id"`
+	PlateNumber string    `json:"plate_number"`
+}
+
+func (q *Queries) GetPassByOwnerAndPlate(ctx context.Context, arg GetPassByOwnerAndPlateParams) (Pass, error) {
+	row := q.db.QueryRowContext(ctx, getPassByOwnerAndPlate, arg.OwnerUserID, arg.PlateNumber)
+	var i Pass
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerUserID,
+		&i.PlateNumber,
+		&i.VehicleBrand,
+		&i.VehicleColor,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+		&i.PlotID,
 	)
 	return i, err
 }
 
 const listPasses = `-- name: ListPasses :many
-SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at FROM passes
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes
 WHERE ($1::bool) OR deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -135,6 +224,7 @@ func (q *Queries) ListPasses(ctx context.Context, arg ListPassesParams) ([]Pass,
 			&i.CreatedBy,
 			&i.UpdatedBy,
 			&i.DeletedAt,
+			&i.PlotID,
 		); err != nil {
 			return nil, err
 		}
@@ -150,7 +240,7 @@ func (q *Queries) ListPasses(ctx context.Context, arg ListPassesParams) ([]Pass,
 }
 
 const listPassesByOwner = `-- name: ListPassesByOwner :many
-SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at FROM passes
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes
 WHERE owner_user_id = $1 AND (($2::bool) OR deleted_at IS NULL)
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -189,6 +279,56 @@ func (q *Queries) ListPassesByOwner(ctx context.Context, arg ListPassesByOwnerPa
 			&i.CreatedBy,
 			&i.UpdatedBy,
 			&i.DeletedAt,
+			&i.PlotID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPassesByPlot = `-- name: ListPassesByPlot :many
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes
+WHERE plot_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListPassesByPlotParams struct {
+	PlotID uuid.NullUUID `json:"plot_id"`
+	Limit  int32         `json:"limit"`
+	Offset int32         `json:"offset"`
+}
+
+func (q *Queries) ListPassesByPlot(ctx context.Context, arg ListPassesByPlotParams) ([]Pass, error) {
+	rows, err := q.db.QueryContext(ctx, listPassesByPlot, arg.PlotID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Pass
+	for rows.Next() {
+		var i Pass
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerUserID,
+			&i.PlateNumber,
+			&i.VehicleBrand,
+			&i.VehicleColor,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.DeletedAt,
+			&i.PlotID,
 		); err != nil {
 			return nil, err
 		}
@@ -222,7 +362,7 @@ func (q *Queries) RestorePass(ctx context.Context, arg RestorePassParams) error
 }
 
 const searchPassesByPlate = `-- name: SearchPassesByPlate :many
-SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at FROM passes
+SELECT id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id FROM passes
 WHERE deleted_at IS NULL AND plate_number ILIKE $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -255,6 +395,7 @@ func (q *Queries) SearchPassesByPlate(ctx context.Context, arg SearchPassesByPla
 			&i.CreatedBy,
 			&i.UpdatedBy,
 			&i.DeletedAt,
+			&i.PlotID,
 		); err != nil {
 			return nil, err
 		}
@@ -289,33 +430,36 @@ func (q *Queries) SoftDeletePass(ctx context.Context, arg SoftDeletePassParams)
 
 const updatePass = `-- name: UpdatePass :one
 UPDATE passes
-SET plate_number = $2,
-    vehicle_brand = $3,
-    vehicle_color = $4,
-    status = $5,
+SET plate_number = $1,
+    vehicle_brand = $2,
+    vehicle_color = $3,
+    status = $4,
+    plot_id = COALESCE($5::uuid, plot_id),
     updated_at = now(),
     updated_by = $6
-WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at
+WHERE id = $7 AND deleted_at IS NULL
+RETURNING id, owner_user_id, plate_number, vehicle_brand, vehicle_color, status, created_at, updated_at, created_by, updated_by, deleted_at, plot_id
 `
 
 type UpdatePassParams struct {
-	ID           uuid.UUID      `json:"id"`
 	PlateNumber  string         `json:"plate_number"`
 	VehicleBrand sql.NullString `json:"vehicle_brand"`
 	VehicleColor sql.NullString `json:"vehicle_color"`
 	Status       string         `json:"status"`
+	PlotID       uuid.NullUUID  `json:"plot_id"`
 	UpdatedBy    uuid.NullUUID  `json:"updated_by"`
+	ID           uuid.UUID      `json:"id"`
 }
 
 func (q *Queries) UpdatePass(ctx context.Context, arg UpdatePassParams) (Pass, error) {
 	row := q.db.QueryRowContext(ctx, updatePass,
-		arg.ID,
 		arg.PlateNumber,
 		arg.VehicleBrand,
 		arg.VehicleColor,
 		arg.Status,
+		arg.PlotID,
 		arg.UpdatedBy,
+		arg.ID,
 	)
 	var i Pass
 	err := row.Scan(
@@ -330,6 +474,7 @@ func (q *Queries) UpdatePass(ctx context.Context, arg UpdatePassParams) (Pass, e
 		&i.CreatedBy,
 		&i.UpdatedBy,
 		&i.DeletedAt,
+		&i.PlotID,
 	)
 	return i, err
 }
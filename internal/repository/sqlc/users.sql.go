@@ -30,6 +30,17 @@ func (q *Queries) BlockUser(ctx context.Context, arg BlockUserParams) error {
 	return err
 }
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (email, password_hash, role, full_name, plot_number, created_by, updated_by)
 VALUES ($1, $2, $3, $4, $5, $6, $7)
@@ -98,6 +109,30 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 	return i, err
 }
 
+const getUserByEmailAny = `-- name: GetUserByEmailAny :one
+SELECT id, email, password_hash, role, full_name, plot_number, blocked_at, created_at, updated_at, created_by, updated_by, deleted_at FROM users WHERE email = $1
+`
+
+func (q *Queries) GetUserByEmailAny(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmailAny, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Role,
+		&i.FullName,
+		&i.PlotNumber,
+		&i.BlockedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const getUserByID = `-- name: GetUserByID :one
 SELECT id, email, password_hash, role, full_name, plot_number, blocked_at, created_at, updated_at, created_by, updated_by, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL
 `
@@ -146,6 +181,30 @@ func (q *Queries) GetUserByIDAny(ctx context.Context, id uuid.UUID) (User, error
 	return i, err
 }
 
+const getUserByIDForUpdate = `-- name: GetUserByIDForUpdate :one
+SELECT id, email, password_hash, role, full_name, plot_number, blocked_at, created_at, updated_at, created_by, updated_by, deleted_at FROM users WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetUserByIDForUpdate(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIDForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Role,
+		&i.FullName,
+		&i.PlotNumber,
+		&i.BlockedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT id, email, password_hash, role, full_name, plot_number, blocked_at, created_at, updated_at, created_by, updated_by, deleted_at FROM users
 WHERE ($1::bool) OR deleted_at IS NULL
@@ -195,6 +254,15 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const lockUserBootstrap = `-- name: LockUserBootstrap :exec
+SELECT pg_advisory_xact_lock(hashtext('bootstrap_admin'))
+`
+
+func (q *Queries) LockUserBootstrap(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockUserBootstrap)
+	return err
+}
+
 const restoreUser = `-- name: RestoreUser :exec
 UPDATE users
 SET deleted_at = NULL,
@@ -331,3 +399,55 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 	)
 	return i, err
 }
+
+const upsertUserByEmail = `-- name: UpsertUserByEmail :one
+INSERT INTO users (email, password_hash, role, full_name, plot_number, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $6)
+ON CONFLICT (email) DO UPDATE
+SET password_hash = CASE WHEN $7::bool THEN EXCLUDED.password_hash ELSE users.password_hash END,
+    role = EXCLUDED.role,
+    full_name = EXCLUDED.full_name,
+    plot_number = EXCLUDED.plot_number,
+    deleted_at = NULL,
+    updated_at = now(),
+    updated_by = EXCLUDED.updated_by
+RETURNING id, email, password_hash, role, full_name, plot_number, blocked_at, created_at, updated_at, created_by, updated_by, deleted_at
+`
+
+type UpsertUserByEmailParams struct {
+	Email           string         `json:"email"`
+	PasswordHash    string         `json:"password_hash"`
+	Role            string         `json:"role"`
+	FullName        string         `json:"full_name"`
+	PlotNumber      sql.NullString `json:"plot_number"`
+	ActorID         uuid.NullUUID  `json:"actor_id"`
+	ReplacePassword bool           `json:"replace_password"`
+}
+
+func (q *Queries) UpsertUserByEmail(ctx context.Context, arg UpsertUserByEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserByEmail,
+		arg.Email,
+		arg.PasswordHash,
+		arg.Role,
+		arg.FullName,
+		arg.PlotNumber,
+		arg.ActorID,
+		arg.ReplacePassword,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.Role,
+		&i.FullName,
+		&i.PlotNumber,
+		&i.BlockedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.DeletedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/barrier"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	BarrierCommandOpen   = "open"
+	BarrierCommandHold   = "hold"
+	BarrierCommandStatus = "status"
+
+	maxBarrierHold = time.Hour
+)
+
+var (
+	ErrBarrierNotConfigured = errors.New("gate has no barrier controller")
+	ErrBarrierConfig        = errors.New("invalid barrier config")
+	ErrBarrierFailed        = errors.New("barrier command failed")
+)
+
+// BarrierRules limit how long a request waits for a barrier controller;
+// zero uses barrier.DefaultTimeout.
+type BarrierRules struct {
+	Timeout time.Duration
+}
+
+// BarrierFactory builds the driver of a gate's controller.
+type BarrierFactory func(barrier.Config) (barrier.Driver, error)
+
+func WithBarrierFactory(factory BarrierFactory) Option {
+	return func(s *Service) {
+		if factory != nil {
+			s.barriers = factory
+		}
+	}
+}
+
+// GateBarrierInput configures the controller of a gate. A nil Secret keeps
+// the stored one so it never has to be sent back to the client.
+type GateBarrierInput struct {
+	GateID      uuid.UUID
+	Driver      string
+	Address     string
+	Topic       string
+	Username    string
+	Secret      *string
+	AutoOpen    bool
+	HoldSeconds int32
+	ActorID     uuid.UUID
+}
+
+func (s *Service) SetGateBarrier(ctx context.Context, input GateBarrierInput) (repo.GateBarrier, error) {
+	if _, err := s.GetGate(ctx, input.GateID); err != nil {
+		return repo.GateBarrier{}, err
+	}
+	if input.HoldSeconds < 0 || time.Duration(input.HoldSeconds)*time.Second > maxBarrierHold {
+		return repo.GateBarrier{}, fmt.Errorf("%w: hold_seconds must be between 0 and %d", ErrBarrierConfig, int(maxBarrierHold/time.Second))
+	}
+	secret := ""
+	if input.Secret != nil {
+		secret = *input.Secret
+	} else if current, err := s.q.GetGateBarrier(ctx, input.GateID); err == nil {
+		secret = current.Secret.String
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return repo.GateBarrier{}, err
+	}
+	cfg := barrier.Config{
+		Driver:   strings.TrimSpace(input.Driver),
+		Address:  strings.TrimSpace(input.Address),
+		Topic:    strings.TrimSpace(input.Topic),
+		Username: strings.TrimSpace(input.Username),
+		Secret:   secret,
+	}
+	if err := barrier.Validate(cfg); err != nil {
+		return repo.GateBarrier{}, fmt.Errorf("%w: %s", ErrBarrierConfig, err)
+	}
+	return s.q.UpsertGateBarrier(ctx, repo.UpsertGateBarrierParams{
+		GateID:      input.GateID,
+		Driver:      cfg.Driver,
+		Address:     toNullNotes(cfg.Address),
+		Topic:       toNullNotes(cfg.Topic),
+		Username:    toNullNotes(cfg.Username),
+		Secret:      sql.NullString{String: cfg.Secret, Valid: cfg.Secret != ""},
+		AutoOpen:    input.AutoOpen,
+		HoldSeconds: input.HoldSeconds,
+		UpdatedBy:   uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	})
+}
+
+func (s *Service) GetGateBarrier(ctx context.Context, gateID uuid.UUID) (repo.GateBarrier, error) {
+	config, err := s.q.GetGateBarrier(ctx, gateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.GateBarrier{}, ErrBarrierNotConfigured
+	}
+	return config, err
+}
+
+func (s *Service) DeleteGateBarrier(ctx context.Context, gateID uuid.UUID) error {
+	affected, err := s.q.DeleteGateBarrier(ctx, gateID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrBarrierNotConfigured
+	}
+	return nil
+}
+
+// BarrierCommandInput opens a barrier by hand; a positive Hold keeps it up
+// instead of letting it close after the vehicle.
+type BarrierCommandInput struct {
+	GateID  uuid.UUID
+	ActorID uuid.UUID
+	Hold    time.Duration
+}
+
+// OpenBarrier sends an open or hold command and records the outcome. A
+// guard with assigned gates may only open those. When the controller fails,
+// the recorded event is returned along with an error wrapping
+// ErrBarrierFailed.
+func (s *Service) OpenBarrier(ctx context.Context, input BarrierCommandInput) (repo.BarrierEvent, error) {
+	if input.Hold < 0 || input.Hold > maxBarrierHold {
+		return repo.BarrierEvent{}, ErrInvalidInput
+	}
+	config, err := s.GetGateBarrier(ctx, input.GateID)
+	if err != nil {
+		return repo.BarrierEvent{}, err
+	}
+	assigned, err := s.q.ListGuardGates(ctx, input.ActorID)
+	if err != nil {
+		return repo.BarrierEvent{}, err
+	}
+	if len(assigned) > 0 && !containsGate(assigned, input.GateID) {
+		return repo.BarrierEvent{}, ErrGateNotAssigned
+	}
+	event, err := s.runBarrier(ctx, config, input.Hold, uuid.Nil, input.ActorID)
+	if err != nil {
+		return repo.BarrierEvent{}, err
+	}
+	return event, barrierError(event)
+}
+
+// BarrierStatus asks the controller for the barrier state. Only failed
+// checks are recorded, so polling the status does not flood the log.
+func (s *Service) BarrierStatus(ctx context.Context, gateID, actor uuid.UUID) (barrier.Status, error) {
+	config, err := s.GetGateBarrier(ctx, gateID)
+	if err != nil {
+		return barrier.Status{}, err
+	}
+	driver, err := s.barrierDriver(config)
+	if err != nil {
+		return barrier.Status{}, err
+	}
+	started := s.now()
+	status, statusErr := driver.Status(ctx)
+	if statusErr == nil {
+		return status, nil
+	}
+	event, err := s.q.CreateBarrierEvent(ctx, repo.CreateBarrierEventParams{
+		GateID:     gateID,
+		Command:    BarrierCommandStatus,
+		Driver:     config.Driver,
+		UserID:     uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil},
+		Error:      sql.NullString{String: statusErr.Error(), Valid: true},
+		DurationMs: int32(s.now().Sub(started) / time.Millisecond),
+	})
+	if err != nil {
+		return barrier.Status{}, err
+	}
+	return barrier.Status{}, barrierError(event)
+}
+
+// TriggerBarrier opens the barrier for an entry logged at a gate whose
+// controller is set to open automatically. It returns nil when there is
+// nothing to trigger; a failing controller is recorded in the event rather
+// than returned, since the entry itself already stands.
+func (s *Service) TriggerBarrier(ctx context.Context, entry repo.EntryLog) (*repo.BarrierEvent, error) {
+	if entry.Action != EntryActionEntry || !entry.GateID.Valid {
+		return nil, nil
+	}
+	config, err := s.q.GetGateBarrier(ctx, entry.GateID.UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !config.AutoOpen {
+		return nil, nil
+	}
+	hold := time.Duration(config.HoldSeconds) * time.Second
+	event, err := s.runBarrier(ctx, config, hold, entry.ID, entry.GuardUserID)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (s *Service) ListBarrierEvents(ctx context.Context, gateID uuid.UUID, failedOnly bool, limit, offset int32) ([]repo.BarrierEvent, error) {
+	return s.q.ListBarrierEvents(ctx, repo.ListBarrierEventsParams{
+		GateID:     gateID,
+		FailedOnly: failedOnly,
+		PageSize:   limit,
+		PageOffset: offset,
+	})
+}
+
+// runBarrier sends open, or hold when hold is set, and records the event.
+func (s *Service) runBarrier(ctx context.Context, config repo.GateBarrier, hold time.Duration, entryLogID, actor uuid.UUID) (repo.BarrierEvent, error) {
+	driver, err := s.barrierDriver(config)
+	if err != nil {
+		return repo.BarrierEvent{}, err
+	}
+	command := BarrierCommandOpen
+	started := s.now()
+	var cmdErr error
+	if hold > 0 {
+		command = BarrierCommandHold
+		cmdErr = driver.HoldOpen(ctx, hold)
+	} else {
+		cmdErr = driver.Open(ctx)
+	}
+	params := repo.CreateBarrierEventParams{
+		GateID:     config.GateID,
+		Command:    command,
+		Driver:     config.Driver,
+		EntryLogID: uuid.NullUUID{UUID: entryLogID, Valid: entryLogID != uuid.Nil},
+		UserID:     uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil},
+		Success:    cmdErr == nil,
+		DurationMs: int32(s.now().Sub(started) / time.Millisecond),
+	}
+	if cmdErr != nil {
+		params.Error = sql.NullString{String: cmdErr.Error(), Valid: true}
+	} else {
+		params.State = sql.NullString{String: barrier.StateOpen, Valid: true}
+	}
+	return s.q.CreateBarrierEvent(ctx, params)
+}
+
+func (s *Service) barrierDriver(config repo.GateBarrier) (barrier.Driver, error) {
+	factory := s.barriers
+	if factory == nil {
+		factory = barrier.New
+	}
+	driver, err := factory(barrier.Config{
+		Driver:   config.Driver,
+		Address:  config.Address.String,
+		Topic:    config.Topic.String,
+		Username: config.Username.String,
+		Secret:   config.Secret.String,
+		Timeout:  s.settings.Barriers.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBarrierConfig, err)
+	}
+	return driver, nil
+}
+
+func barrierError(event repo.BarrierEvent) error {
+	if event.Success {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrBarrierFailed, event.Error.String)
+}
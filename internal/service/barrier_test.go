@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"pipo-edu-project/internal/barrier"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+type fakeBarrier struct {
+	calls []string
+	hold  time.Duration
+	err   error
+}
+
+func (f *fakeBarrier) Open(context.Context) error {
+	f.calls = append(f.calls, BarrierCommandOpen)
+	return f.err
+}
+
+func (f *fakeBarrier) HoldOpen(_ context.Context, d time.Duration) error {
+	f.calls = append(f.calls, BarrierCommandHold)
+	f.hold = d
+	return f.err
+}
+
+func (f *fakeBarrier) Status(context.Context) (barrier.Status, error) {
+	f.calls = append(f.calls, BarrierCommandStatus)
+	return barrier.Status{State: barrier.StateClosed}, f.err
+}
+
+// barrierStore keeps one gate and records the barrier events written.
+func barrierStore(gate uuid.UUID, config *repo.GateBarrier, events *[]repo.CreateBarrierEventParams) *mockStore {
+	return &mockStore{
+		getGateFn: func(_ context.Context, id uuid.UUID) (repo.Gate, error) {
+			if id != gate {
+				return repo.Gate{}, sql.ErrNoRows
+			}
+			return repo.Gate{ID: gate, Name: "Главные ворота"}, nil
+		},
+		getGateBarrierFn: func(_ context.Context, id uuid.UUID) (repo.GateBarrier, error) {
+			if config == nil || id != config.GateID {
+				return repo.GateBarrier{}, sql.ErrNoRows
+			}
+			return *config, nil
+		},
+		upsertGateBarrierFn: func(_ context.Context, arg repo.UpsertGateBarrierParams) (repo.GateBarrier, error) {
+			return repo.GateBarrier{GateID: arg.GateID, Driver: arg.Driver, Address: arg.Address, Topic: arg.Topic,
+				Username: arg.Username, Secret: arg.Secret, AutoOpen: arg.AutoOpen, HoldSeconds: arg.HoldSeconds}, nil
+		},
+		createBarrierEventFn: func(_ context.Context, arg repo.CreateBarrierEventParams) (repo.BarrierEvent, error) {
+			*events = append(*events, arg)
+			return repo.BarrierEvent{ID: uuid.New(), GateID: arg.GateID, Command: arg.Command, Driver: arg.Driver,
+				EntryLogID: arg.EntryLogID, UserID: arg.UserID, Success: arg.Success, State: arg.State, Error: arg.Error}, nil
+		},
+	}
+}
+
+func TestServiceUnit_GateBarrierConfig(t *testing.T) {
+	ctx := context.Background()
+	gate := uuid.New()
+	stored := repo.GateBarrier{GateID: gate, Driver: barrier.DriverHTTP, Secret: sql.NullString{String: "old", Valid: true}}
+	var events []repo.CreateBarrierEventParams
+	store := barrierStore(gate, &stored, &events)
+	var deleted int64 = 1
+	store.deleteGateBarrierFn = func(context.Context, uuid.UUID) (int64, error) { return deleted, nil }
+	svc := New(store)
+
+	_, err := svc.SetGateBarrier(ctx, GateBarrierInput{GateID: uuid.New(), Driver: barrier.DriverNoop})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.SetGateBarrier(ctx, GateBarrierInput{GateID: gate, Driver: "serial"})
+	require.ErrorIs(t, err, ErrBarrierConfig)
+	_, err = svc.SetGateBarrier(ctx, GateBarrierInput{GateID: gate, Driver: barrier.DriverMQTT, Address: "broker:1883"})
+	require.ErrorIs(t, err, ErrBarrierConfig)
+	_, err = svc.SetGateBarrier(ctx, GateBarrierInput{GateID: gate, Driver: barrier.DriverNoop, HoldSeconds: 7200})
+	require.ErrorIs(t, err, ErrBarrierConfig)
+
+	config, err := svc.SetGateBarrier(ctx, GateBarrierInput{GateID: gate, Driver: " http ", Address: "http://relay.local/gate", AutoOpen: true})
+	require.NoError(t, err)
+	require.Equal(t, barrier.DriverHTTP, config.Driver)
+	require.Equal(t, "old", config.Secret.String, "omitted secret is kept")
+	empty := ""
+	config, err = svc.SetGateBarrier(ctx, GateBarrierInput{GateID: gate, Driver: barrier.DriverHTTP, Address: "http://relay.local/gate", Secret: &empty})
+	require.NoError(t, err)
+	require.False(t, config.Secret.Valid)
+
+	_, err = svc.GetGateBarrier(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrBarrierNotConfigured)
+	require.NoError(t, svc.DeleteGateBarrier(ctx, gate))
+	deleted = 0
+	require.ErrorIs(t, svc.DeleteGateBarrier(ctx, gate), ErrBarrierNotConfigured)
+}
+
+func TestServiceUnit_TriggerBarrier(t *testing.T) {
+	ctx := context.Background()
+	gate := uuid.New()
+	guard := uuid.New()
+	config := repo.GateBarrier{GateID: gate, Driver: barrier.DriverNoop, AutoOpen: true}
+	var events []repo.CreateBarrierEventParams
+	driver := &fakeBarrier{}
+	var built barrier.Config
+	svc := New(barrierStore(gate, &config, &events), WithBarrierFactory(func(cfg barrier.Config) (barrier.Driver, error) {
+		built = cfg
+		return driver, nil
+	}), WithSettings(Settings{Barriers: BarrierRules{Timeout: 2 * time.Second}}))
+	entry := repo.EntryLog{ID: uuid.New(), Action: EntryActionEntry, GuardUserID: guard, GateID: uuid.NullUUID{UUID: gate, Valid: true}}
+
+	for name, skipped := range map[string]repo.EntryLog{
+		"exit":          {ID: uuid.New(), Action: EntryActionExit, GateID: entry.GateID},
+		"no gate":       {ID: uuid.New(), Action: EntryActionEntry},
+		"no controller": {ID: uuid.New(), Action: EntryActionEntry, GateID: uuid.NullUUID{UUID: uuid.New(), Valid: true}},
+	} {
+		event, err := svc.TriggerBarrier(ctx, skipped)
+		require.NoError(t, err, name)
+		require.Nil(t, event, name)
+	}
+	config.AutoOpen = false
+	event, err := svc.TriggerBarrier(ctx, entry)
+	require.NoError(t, err)
+	require.Nil(t, event)
+	require.Empty(t, driver.calls)
+
+	config.AutoOpen = true
+	event, err = svc.TriggerBarrier(ctx, entry)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	require.True(t, event.Success)
+	require.Equal(t, []string{BarrierCommandOpen}, driver.calls)
+	require.Equal(t, 2*time.Second, built.Timeout)
+	require.Equal(t, entry.ID, events[0].EntryLogID.UUID)
+	require.Equal(t, guard, events[0].UserID.UUID)
+	require.Equal(t, barrier.StateOpen, events[0].State.String)
+
+	config.HoldSeconds = 45
+	driver.err = errors.New("relay responded 503: busy")
+	event, err = svc.TriggerBarrier(ctx, entry)
+	require.NoError(t, err, "a failing controller does not fail the entry")
+	require.False(t, event.Success)
+	require.Equal(t, BarrierCommandHold, events[1].Command)
+	require.Equal(t, 45*time.Second, driver.hold)
+	require.Equal(t, "relay responded 503: busy", events[1].Error.String)
+}
+
+func TestServiceUnit_OpenBarrier(t *testing.T) {
+	ctx := context.Background()
+	gate := uuid.New()
+	actor := uuid.New()
+	config := repo.GateBarrier{GateID: gate, Driver: barrier.DriverHTTP, Address: sql.NullString{String: "http://relay.local", Valid: true}}
+	var events []repo.CreateBarrierEventParams
+	driver := &fakeBarrier{}
+	svc := New(barrierStore(gate, &config, &events), WithBarrierFactory(func(barrier.Config) (barrier.Driver, error) {
+		return driver, nil
+	}))
+
+	_, err := svc.OpenBarrier(ctx, BarrierCommandInput{GateID: gate, Hold: -time.Second})
+	require.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.OpenBarrier(ctx, BarrierCommandInput{GateID: uuid.New()})
+	require.ErrorIs(t, err, ErrBarrierNotConfigured)
+	otherGuard := uuid.New()
+	store := svc.q.(*mockStore)
+	store.listGuardGatesFn = func(_ context.Context, id uuid.UUID) ([]repo.Gate, error) {
+		if id == otherGuard {
+			return []repo.Gate{{ID: uuid.New()}}, nil
+		}
+		return nil, nil
+	}
+	_, err = svc.OpenBarrier(ctx, BarrierCommandInput{GateID: gate, ActorID: otherGuard})
+	require.ErrorIs(t, err, ErrGateNotAssigned)
+
+	event, err := svc.OpenBarrier(ctx, BarrierCommandInput{GateID: gate, ActorID: actor, Hold: time.Minute})
+	require.NoError(t, err)
+	require.True(t, event.Success)
+	require.Equal(t, BarrierCommandHold, events[0].Command)
+	require.False(t, events[0].EntryLogID.Valid)
+	require.Equal(t, actor, events[0].UserID.UUID)
+
+	status, err := svc.BarrierStatus(ctx, gate, actor)
+	require.NoError(t, err)
+	require.Equal(t, barrier.StateClosed, status.State)
+	require.Len(t, events, 1, "successful status checks are not recorded")
+
+	driver.err = errors.New("relay request: timeout")
+	event, err = svc.OpenBarrier(ctx, BarrierCommandInput{GateID: gate, ActorID: actor})
+	require.ErrorIs(t, err, ErrBarrierFailed)
+	require.False(t, event.Success)
+	require.Equal(t, BarrierCommandOpen, events[1].Command)
+	_, err = svc.BarrierStatus(ctx, gate, actor)
+	require.ErrorIs(t, err, ErrBarrierFailed)
+	require.Equal(t, BarrierCommandStatus, events[2].Command)
+	require.False(t, events[2].Success)
+
+	broken := New(barrierStore(gate, &config, &events), WithBarrierFactory(func(barrier.Config) (barrier.Driver, error) {
+		return nil, barrier.ErrUnknownDriver
+	}))
+	_, err = broken.OpenBarrier(ctx, BarrierCommandInput{GateID: gate})
+	require.ErrorIs(t, err, ErrBarrierConfig)
+	_, err = broken.BarrierStatus(ctx, gate, actor)
+	require.ErrorIs(t, err, ErrBarrierConfig)
+
+	var listed repo.ListBarrierEventsParams
+	store = barrierStore(gate, &config, &events)
+	store.listBarrierEventsFn = func(_ context.Context, arg repo.ListBarrierEventsParams) ([]repo.BarrierEvent, error) {
+		listed = arg
+		return nil, nil
+	}
+	_, err = New(store).ListBarrierEvents(ctx, gate, true, 20, 40)
+	require.NoError(t, err)
+	require.Equal(t, repo.ListBarrierEventsParams{GateID: gate, FailedOnly: true, PageSize: 20, PageOffset: 40}, listed)
+}
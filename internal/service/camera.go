@@ -0,0 +1,540 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	PlateDecisionAllow  = "allow"
+	PlateDecisionDeny   = "deny"
+	PlateDecisionReview = "manual_review"
+
+	PlateReviewPending = "pending"
+	PlateReviewAllowed = "allowed"
+	PlateReviewDenied  = "denied"
+
+	// DefaultMinPlateConfidence is the recognition confidence below which a
+	// read is left to a guard.
+	DefaultMinPlateConfidence = 0.8
+
+	cameraTokenBytes = 32
+)
+
+var (
+	ErrCameraExists       = errors.New("camera with this name already exists")
+	ErrCameraOperator     = errors.New("camera operator must be an active guard or admin")
+	ErrInvalidCameraToken = errors.New("invalid camera token")
+	ErrInvalidConfidence  = errors.New("confidence must be between 0 and 1")
+	ErrPlateReadResolved  = errors.New("plate read has already been reviewed")
+	ErrPlateReadTarget    = errors.New("choose the pass or guest request to let in")
+)
+
+type CameraRules struct {
+	// MinConfidence is the confidence below which a read goes to the guard
+	// review queue; zero means DefaultMinPlateConfidence.
+	MinConfidence float64
+}
+
+// CameraInput registers a plate-recognition camera. Automatic entry logs of
+// the camera are recorded on behalf of the operator, a guard or admin
+// account, at the camera's gate and in its direction.
+type CameraInput struct {
+	ID         uuid.UUID
+	Name       string
+	GateID     uuid.UUID
+	Direction  string
+	OperatorID uuid.UUID
+	Active     bool
+	ActorID    uuid.UUID
+}
+
+// CreateCamera registers a camera and returns its device token. Only a hash
+// of the token is stored, so it cannot be shown again.
+func (s *Service) CreateCamera(ctx context.Context, input CameraInput) (repo.Camera, string, error) {
+	name, err := s.validateCamera(ctx, input)
+	if err != nil {
+		return repo.Camera{}, "", err
+	}
+	token, hash, err := newCameraToken()
+	if err != nil {
+		return repo.Camera{}, "", err
+	}
+	camera, err := s.q.CreateCamera(ctx, repo.CreateCameraParams{
+		Name:           name,
+		GateID:         uuid.NullUUID{UUID: input.GateID, Valid: input.GateID != uuid.Nil},
+		Direction:      input.Direction,
+		OperatorUserID: input.OperatorID,
+		TokenHash:      hash,
+		CreatedBy:      uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	})
+	if err != nil {
+		return repo.Camera{}, "", err
+	}
+	return camera, token, nil
+}
+
+func (s *Service) GetCamera(ctx context.Context, id uuid.UUID) (repo.Camera, error) {
+	camera, err := s.q.GetCamera(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Camera{}, ErrNotFound
+	}
+	return camera, err
+}
+
+func (s *Service) ListCameras(ctx context.Context) ([]repo.Camera, error) {
+	return s.q.ListCameras(ctx)
+}
+
+func (s *Service) UpdateCamera(ctx context.Context, input CameraInput) (repo.Camera, error) {
+	name, err := s.validateCamera(ctx, input)
+	if err != nil {
+		return repo.Camera{}, err
+	}
+	camera, err := s.q.UpdateCamera(ctx, repo.UpdateCameraParams{
+		ID:             input.ID,
+		Name:           name,
+		GateID:         uuid.NullUUID{UUID: input.GateID, Valid: input.GateID != uuid.Nil},
+		Direction:      input.Direction,
+		OperatorUserID: input.OperatorID,
+		Active:         input.Active,
+		UpdatedBy:      uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Camera{}, ErrNotFound
+	}
+	return camera, err
+}
+
+// RotateCameraToken issues a new device token; the old one stops working at
+// once.
+func (s *Service) RotateCameraToken(ctx context.Context, id, actor uuid.UUID) (string, error) {
+	token, hash, err := newCameraToken()
+	if err != nil {
+		return "", err
+	}
+	affected, err := s.q.SetCameraToken(ctx, repo.SetCameraTokenParams{ID: id, TokenHash: hash, UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}})
+	if err != nil {
+		return "", err
+	}
+	if affected == 0 {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (s *Service) DeleteCamera(ctx context.Context, id, actor uuid.UUID) error {
+	affected, err := s.q.SoftDeleteCamera(ctx, repo.SoftDeleteCameraParams{ID: id, UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Service) validateCamera(ctx context.Context, input CameraInput) (string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return "", ErrInvalidInput
+	}
+	if input.Direction != EntryActionEntry && input.Direction != EntryActionExit {
+		return "", ErrInvalidEntryAction
+	}
+	if other, err := s.q.GetCameraByName(ctx, name); err == nil && other.ID != input.ID {
+		return "", ErrCameraExists
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	if input.GateID != uuid.Nil {
+		if _, err := s.q.GetGate(ctx, input.GateID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return "", ErrUnknownGate
+			}
+			return "", err
+		}
+	}
+	operator, err := s.q.GetUserByID(ctx, input.OperatorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrCameraOperator
+	}
+	if err != nil {
+		return "", err
+	}
+	if operator.BlockedAt.Valid || (operator.Role != string(auth.RoleGuard) && operator.Role != string(auth.RoleAdmin)) {
+		return "", ErrCameraOperator
+	}
+	return name, nil
+}
+
+// AuthenticateCamera finds the active camera a device token belongs to and
+// marks it as seen.
+func (s *Service) AuthenticateCamera(ctx context.Context, token string) (repo.Camera, error) {
+	if token == "" {
+		return repo.Camera{}, ErrInvalidCameraToken
+	}
+	camera, err := s.q.GetCameraByToken(ctx, hashCameraToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Camera{}, ErrInvalidCameraToken
+	}
+	if err != nil {
+		return repo.Camera{}, err
+	}
+	if err := s.q.TouchCamera(ctx, repo.TouchCameraParams{ID: camera.ID, LastSeenAt: sql.NullTime{Time: s.now(), Valid: true}}); err != nil {
+		return repo.Camera{}, err
+	}
+	return camera, nil
+}
+
+func newCameraToken() (token, hash string, err error) {
+	raw := make([]byte, cameraTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(raw)
+	return token, hashCameraToken(token), nil
+}
+
+func hashCameraToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PlateReadInput is a plate recognized by a camera. CapturedAt defaults to
+// the time the read arrives.
+type PlateReadInput struct {
+	Plate      string
+	Confidence float64
+	CapturedAt time.Time
+}
+
+// plateMatch is the outcome of matching a read before anything is logged.
+type plateMatch struct {
+	Decision  string
+	Reason    string
+	PassID    uuid.UUID
+	GuestID   uuid.UUID
+	Watchlist *WatchlistHit
+}
+
+// RecordPlateRead decides whether the vehicle a camera saw may pass and
+// keeps the read. Allowed vehicles get an entry log on behalf of the camera
+// operator; unreadable and low-confidence plates, as well as movements the
+// journal rejects, are queued for a guard.
+func (s *Service) RecordPlateRead(ctx context.Context, camera repo.Camera, input PlateReadInput) (repo.PlateRead, error) {
+	if math.IsNaN(input.Confidence) || input.Confidence < 0 || input.Confidence > 1 {
+		return repo.PlateRead{}, ErrInvalidConfidence
+	}
+	raw := strings.TrimSpace(input.Plate)
+	if raw == "" {
+		return repo.PlateRead{}, ErrInvalidPlate
+	}
+	capturedAt := input.CapturedAt
+	if capturedAt.IsZero() {
+		capturedAt = s.now()
+	}
+	plate := NormalizePlate(raw)
+	match, err := s.matchPlate(ctx, camera, plate, input.Confidence)
+	if err != nil {
+		return repo.PlateRead{}, err
+	}
+	var entryLogID uuid.NullUUID
+	if match.Decision == PlateDecisionAllow {
+		entry, err := s.recordCameraMovement(ctx, camera, camera.OperatorUserID, match.PassID, match.GuestID, "", match.Watchlist)
+		switch {
+		case err == nil:
+			entryLogID = uuid.NullUUID{UUID: entry.ID, Valid: true}
+			// A failing controller is kept in the barrier log; the camera
+			// still gets the decision, since the entry is already logged.
+			_, _ = s.TriggerBarrier(ctx, entry)
+		case movementRejected(err):
+			match.Decision = PlateDecisionReview
+			match.Reason = err.Error()
+		default:
+			return repo.PlateRead{}, err
+		}
+	}
+	return s.q.CreatePlateRead(ctx, repo.CreatePlateReadParams{
+		CameraID:       camera.ID,
+		PlateRaw:       raw,
+		PlateNumber:    plate,
+		Confidence:     input.Confidence,
+		CapturedAt:     capturedAt,
+		Decision:       match.Decision,
+		Reason:         match.Reason,
+		PassID:         uuid.NullUUID{UUID: match.PassID, Valid: match.PassID != uuid.Nil},
+		GuestRequestID: uuid.NullUUID{UUID: match.GuestID, Valid: match.GuestID != uuid.Nil},
+		EntryLogID:     entryLogID,
+		ReviewStatus:   sql.NullString{String: PlateReviewPending, Valid: match.Decision == PlateDecisionReview},
+	})
+}
+
+// matchPlate looks the plate up among active passes (entry) or passes on
+// site and not deleted (exit) and, failing that, guests expected now (entry)
+// or on site (exit). A car whose pass was suspended while it was inside can
+// still leave. The match is kept even when the read goes to review so the
+// guard sees who it probably is.
+func (s *Service) matchPlate(ctx context.Context, camera repo.Camera, plate string, confidence float64) (plateMatch, error) {
+	if err := ValidatePlate(plate); err != nil {
+		return plateMatch{Decision: PlateDecisionReview, Reason: "plate not recognized"}, nil
+	}
+	var (
+		match    plateMatch
+		inactive bool
+	)
+	listPasses := s.q.ListActivePassesByPlate
+	if camera.Direction == EntryActionExit {
+		listPasses = s.q.ListExitPassesByPlate
+	}
+	passes, err := listPasses(ctx, plate)
+	if err != nil {
+		return plateMatch{}, err
+	}
+	if len(passes) > 0 {
+		match.PassID = passes[0].ID
+		inactive = passes[0].Status != PassStatusActive || passes[0].DeletedAt.Valid
+	} else {
+		now := s.now()
+		if err := s.materializeGuestSeries(ctx, now, now); err != nil {
+			return plateMatch{}, err
+		}
+		guests, err := s.q.ListCurrentGuestsByPlate(ctx, repo.ListCurrentGuestsByPlateParams{PlateNumber: plate, At: now})
+		if err != nil {
+			return plateMatch{}, err
+		}
+		want := GuestStatusApproved
+		if camera.Direction == EntryActionExit {
+			want = GuestStatusArrived
+		}
+		for _, guest := range guests {
+			if guest.Status == want {
+				match.GuestID = guest.ID
+				break
+			}
+		}
+	}
+	if confidence < s.minPlateConfidence() {
+		match.Decision = PlateDecisionReview
+		match.Reason = fmt.Sprintf("low confidence %.2f", confidence)
+		return match, nil
+	}
+	match.Watchlist, err = s.CheckGate(ctx, GateCheckInput{PassID: match.PassID, GuestID: match.GuestID, PlateNumber: plate, UserID: camera.OperatorUserID, Source: camera.Direction})
+	if errors.Is(err, ErrPlateBlacklisted) {
+		return plateMatch{Decision: PlateDecisionDeny, Reason: err.Error(), PassID: match.PassID, GuestID: match.GuestID}, nil
+	}
+	if err != nil {
+		return plateMatch{}, err
+	}
+	switch {
+	case match.PassID != uuid.Nil:
+		if camera.Direction == EntryActionEntry {
+			status, err := s.CheckPassSchedule(ctx, match.PassID)
+			if err != nil {
+				return plateMatch{}, err
+			}
+			if !status.Within {
+				match.Decision = PlateDecisionReview
+				match.Reason = "outside the pass access schedule"
+				return match, nil
+			}
+		}
+		match.Decision = PlateDecisionAllow
+		match.Reason = "active pass"
+		if inactive {
+			match.Reason = "leaving on an inactive pass"
+		}
+	case match.GuestID != uuid.Nil:
+		match.Decision = PlateDecisionAllow
+		match.Reason = "guest request"
+	default:
+		match.Decision = PlateDecisionDeny
+		match.Reason = "no active pass or guest request"
+	}
+	return match, nil
+}
+
+func (s *Service) minPlateConfidence() float64 {
+	if s.settings.Cameras.MinConfidence > 0 {
+		return s.settings.Cameras.MinConfidence
+	}
+	return DefaultMinPlateConfidence
+}
+
+// recordCameraMovement logs the pass or guest passing the camera's gate in
+// its direction on behalf of guardID, together with the watchlist hit.
+func (s *Service) recordCameraMovement(ctx context.Context, camera repo.Camera, guardID, passID, guestID uuid.UUID, note string, hit *WatchlistHit) (repo.EntryLog, error) {
+	comment := "camera " + camera.Name
+	if note != "" {
+		comment += ": " + note
+	}
+	if passID != uuid.Nil {
+		return s.RecordPassMovement(ctx, PassMovementInput{
+			PassID:    passID,
+			GuardID:   guardID,
+			GateID:    camera.GateID.UUID,
+			Action:    camera.Direction,
+			Comment:   sql.NullString{String: comment, Valid: true},
+			Watchlist: hit,
+		})
+	}
+	visit := GuestVisitInput{
+		GuestID:   guestID,
+		GuardID:   guardID,
+		GateID:    camera.GateID.UUID,
+		Comment:   sql.NullString{String: comment, Valid: true},
+		Watchlist: hit,
+	}
+	if camera.Direction == EntryActionEntry {
+		return s.CheckInGuest(ctx, visit)
+	}
+	return s.CheckOutGuest(ctx, visit)
+}
+
+// movementRejected tells the journal refusing a movement apart from a
+// failure to write it.
+func movementRejected(err error) bool {
+	var conflict *PresenceConflictError
+	return errors.As(err, &conflict) ||
+		errors.Is(err, ErrUnknownGate) || errors.Is(err, ErrGateRequired) ||
+		errors.Is(err, ErrGateNotAllowed) || errors.Is(err, ErrGateNotAssigned) ||
+		errors.Is(err, ErrNoOpenShift) || errors.Is(err, ErrGuestTransition) ||
+		errors.Is(err, ErrOutsideGuestWindow) || errors.Is(err, ErrResidentInactive) ||
+		errors.Is(err, ErrPassInactive) || errors.Is(err, ErrNotFound)
+}
+
+// PlateReadFilter narrows the list of reads; zero fields are not applied.
+type PlateReadFilter struct {
+	ReviewStatus string
+	Decision     string
+	CameraID     uuid.UUID
+	GateID       uuid.UUID
+}
+
+func (s *Service) ListPlateReads(ctx context.Context, filter PlateReadFilter, limit, offset int32) ([]repo.ListPlateReadsRow, error) {
+	switch filter.ReviewStatus {
+	case "", PlateReviewPending, PlateReviewAllowed, PlateReviewDenied:
+	default:
+		return nil, ErrInvalidInput
+	}
+	switch filter.Decision {
+	case "", PlateDecisionAllow, PlateDecisionDeny, PlateDecisionReview:
+	default:
+		return nil, ErrInvalidInput
+	}
+	return s.q.ListPlateReads(ctx, repo.ListPlateReadsParams{
+		ReviewStatus: sql.NullString{String: filter.ReviewStatus, Valid: filter.ReviewStatus != ""},
+		Decision:     sql.NullString{String: filter.Decision, Valid: filter.Decision != ""},
+		CameraID:     uuid.NullUUID{UUID: filter.CameraID, Valid: filter.CameraID != uuid.Nil},
+		GateID:       uuid.NullUUID{UUID: filter.GateID, Valid: filter.GateID != uuid.Nil},
+		PageSize:     limit,
+		PageOffset:   offset,
+	})
+}
+
+// PlateReviewInput is a guard's verdict on a queued read. When allowing,
+// PassID or GuestID pick who is let in if the read matched no one or the
+// wrong vehicle.
+type PlateReviewInput struct {
+	ID      uuid.UUID
+	GuardID uuid.UUID
+	Allow   bool
+	PassID  uuid.UUID
+	GuestID uuid.UUID
+	Comment string
+}
+
+// ReviewPlateRead resolves a queued read. An allowed read is logged on
+// behalf of the reviewing guard; if the journal rejects the movement the
+// read goes back to the queue.
+func (s *Service) ReviewPlateRead(ctx context.Context, input PlateReviewInput) (repo.PlateRead, error) {
+	read, err := s.q.GetPlateRead(ctx, input.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.PlateRead{}, ErrNotFound
+	}
+	if err != nil {
+		return repo.PlateRead{}, err
+	}
+	if read.ReviewStatus.String != PlateReviewPending {
+		return repo.PlateRead{}, ErrPlateReadResolved
+	}
+	camera, err := s.GetCamera(ctx, read.CameraID)
+	if err != nil {
+		return repo.PlateRead{}, err
+	}
+	status := PlateReviewDenied
+	passID, guestID := read.PassID.UUID, read.GuestRequestID.UUID
+	var hit *WatchlistHit
+	if input.Allow {
+		status = PlateReviewAllowed
+		if input.PassID != uuid.Nil || input.GuestID != uuid.Nil {
+			passID, guestID = input.PassID, input.GuestID
+		}
+		if (passID == uuid.Nil) == (guestID == uuid.Nil) {
+			return repo.PlateRead{}, ErrPlateReadTarget
+		}
+		if hit, err = s.checkReviewedPlate(ctx, camera, input.GuardID, passID, guestID); err != nil {
+			return repo.PlateRead{}, err
+		}
+	}
+	comment := strings.TrimSpace(input.Comment)
+	claimed, err := s.q.ClaimPlateRead(ctx, repo.ClaimPlateReadParams{
+		ReviewStatus:  status,
+		ReviewedBy:    uuid.NullUUID{UUID: input.GuardID, Valid: input.GuardID != uuid.Nil},
+		ReviewComment: sql.NullString{String: comment, Valid: comment != ""},
+		ID:            read.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.PlateRead{}, ErrPlateReadResolved
+	}
+	if err != nil || !input.Allow {
+		return claimed, err
+	}
+	entry, err := s.recordCameraMovement(ctx, camera, input.GuardID, passID, guestID, comment, hit)
+	if err != nil {
+		if reopenErr := s.q.ReopenPlateRead(ctx, read.ID); reopenErr != nil {
+			return repo.PlateRead{}, errors.Join(err, reopenErr)
+		}
+		return repo.PlateRead{}, err
+	}
+	claimed.EntryLogID = uuid.NullUUID{UUID: entry.ID, Valid: true}
+	if err := s.q.SetPlateReadEntryLog(ctx, repo.SetPlateReadEntryLogParams{ID: read.ID, EntryLogID: claimed.EntryLogID}); err != nil {
+		return repo.PlateRead{}, err
+	}
+	_, _ = s.TriggerBarrier(ctx, entry)
+	return claimed, nil
+}
+
+// checkReviewedPlate runs the watchlist check for the vehicle the guard lets
+// in. The read itself may be wrong, so the plate of the pass or guest
+// request is checked.
+func (s *Service) checkReviewedPlate(ctx context.Context, camera repo.Camera, guardID, passID, guestID uuid.UUID) (*WatchlistHit, error) {
+	check := GateCheckInput{PassID: passID, GuestID: guestID, UserID: guardID, Source: camera.Direction}
+	if passID != uuid.Nil {
+		pass, err := s.GetPass(ctx, passID)
+		if err != nil {
+			return nil, err
+		}
+		check.PlateNumber = pass.PlateNumber
+	} else {
+		guest, err := s.GetGuestRequest(ctx, guestID)
+		if err != nil {
+			return nil, err
+		}
+		check.PlateNumber = guest.PlateNumber
+	}
+	return s.CheckGate(ctx, check)
+}
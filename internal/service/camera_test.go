@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_Cameras(t *testing.T) {
+	ctx := context.Background()
+	gateID := uuid.New()
+	guardID := uuid.New()
+	residentID := uuid.New()
+	blockedID := uuid.New()
+	var created repo.CreateCameraParams
+	store := &mockStore{
+		getGateFn: func(_ context.Context, id uuid.UUID) (repo.Gate, error) {
+			if id != gateID {
+				return repo.Gate{}, sql.ErrNoRows
+			}
+			return repo.Gate{ID: id}, nil
+		},
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			switch id {
+			case guardID:
+				return repo.User{ID: id, Role: "guard"}, nil
+			case residentID:
+				return repo.User{ID: id, Role: "resident"}, nil
+			case blockedID:
+				return repo.User{ID: id, Role: "guard", BlockedAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil
+			}
+			return repo.User{}, sql.ErrNoRows
+		},
+		getCameraByNameFn: func(_ context.Context, name string) (repo.Camera, error) {
+			if name == "Taken" {
+				return repo.Camera{ID: uuid.New(), Name: name}, nil
+			}
+			return repo.Camera{}, sql.ErrNoRows
+		},
+		createCameraFn: func(_ context.Context, arg repo.CreateCameraParams) (repo.Camera, error) {
+			created = arg
+			return repo.Camera{ID: uuid.New(), Name: arg.Name, Direction: arg.Direction, TokenHash: arg.TokenHash}, nil
+		},
+		getCameraByTokenFn: func(_ context.Context, hash string) (repo.Camera, error) {
+			if hash != created.TokenHash {
+				return repo.Camera{}, sql.ErrNoRows
+			}
+			return repo.Camera{ID: uuid.New(), Name: created.Name}, nil
+		},
+		updateCameraFn: func(context.Context, repo.UpdateCameraParams) (repo.Camera, error) {
+			return repo.Camera{}, sql.ErrNoRows
+		},
+		setCameraTokenFn:   func(context.Context, repo.SetCameraTokenParams) (int64, error) { return 0, nil },
+		softDeleteCameraFn: func(context.Context, repo.SoftDeleteCameraParams) (int64, error) { return 0, nil },
+	}
+	svc := New(store)
+	valid := CameraInput{Name: " North ANPR ", GateID: gateID, Direction: EntryActionEntry, OperatorID: guardID}
+
+	for name, tc := range map[string]struct {
+		edit func(*CameraInput)
+		err  error
+	}{
+		"empty name":        {func(in *CameraInput) { in.Name = " " }, ErrInvalidInput},
+		"bad direction":     {func(in *CameraInput) { in.Direction = "both" }, ErrInvalidEntryAction},
+		"duplicate name":    {func(in *CameraInput) { in.Name = "Taken" }, ErrCameraExists},
+		"unknown gate":      {func(in *CameraInput) { in.GateID = uuid.New() }, ErrUnknownGate},
+		"resident operator": {func(in *CameraInput) { in.OperatorID = residentID }, ErrCameraOperator},
+		"blocked operator":  {func(in *CameraInput) { in.OperatorID = blockedID }, ErrCameraOperator},
+		"missing operator":  {func(in *CameraInput) { in.OperatorID = uuid.New() }, ErrCameraOperator},
+	} {
+		input := valid
+		tc.edit(&input)
+		_, _, err := svc.CreateCamera(ctx, input)
+		require.ErrorIs(t, err, tc.err, name)
+	}
+
+	camera, token, err := svc.CreateCamera(ctx, valid)
+	require.NoError(t, err)
+	require.Equal(t, "North ANPR", camera.Name)
+	require.Equal(t, uuid.NullUUID{UUID: gateID, Valid: true}, created.GateID)
+	require.Len(t, token, 2*cameraTokenBytes)
+	require.NotEqual(t, token, created.TokenHash)
+
+	authed, err := svc.AuthenticateCamera(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, "North ANPR", authed.Name)
+	_, err = svc.AuthenticateCamera(ctx, "")
+	require.ErrorIs(t, err, ErrInvalidCameraToken)
+	_, err = svc.AuthenticateCamera(ctx, token+"0")
+	require.ErrorIs(t, err, ErrInvalidCameraToken)
+
+	_, err = svc.GetCamera(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.UpdateCamera(ctx, valid)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.RotateCameraToken(ctx, uuid.New(), guardID)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, svc.DeleteCamera(ctx, uuid.New(), guardID), ErrNotFound)
+}
+
+func TestServiceUnit_RecordPlateRead(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	operatorID := uuid.New()
+	passID := uuid.New()
+	guestID := uuid.New()
+	camera := repo.Camera{ID: uuid.New(), Name: "North", Direction: EntryActionEntry, OperatorUserID: operatorID}
+
+	type world struct {
+		passes    []repo.Pass
+		guests    []repo.GuestRequest
+		blacklist bool
+		onSite    bool
+		schedule  bool
+		exit      bool
+	}
+	run := func(t *testing.T, w world, input PlateReadInput) (repo.PlateRead, []repo.CreateEntryLogParams) {
+		t.Helper()
+		var logs []repo.CreateEntryLogParams
+		lock := func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			for _, pass := range w.passes {
+				if pass.ID == id {
+					return pass, nil
+				}
+			}
+			return repo.Pass{}, sql.ErrNoRows
+		}
+		store := &mockStore{
+			listActivePassesByPlateFn: func(context.Context, string) ([]repo.Pass, error) {
+				require.False(t, w.exit)
+				return w.passes, nil
+			},
+			listExitPassesByPlateFn: func(context.Context, string) ([]repo.Pass, error) {
+				require.True(t, w.exit)
+				return w.passes, nil
+			},
+			getPassByIDForUpdateFn:    lock,
+			getPassByIDAnyForUpdateFn: lock,
+			getUserByIDFn:             func(_ context.Context, id uuid.UUID) (repo.User, error) { return repo.User{ID: id}, nil },
+			listCurrentGuestsByPlateFn: func(_ context.Context, arg repo.ListCurrentGuestsByPlateParams) ([]repo.GuestRequest, error) {
+				require.Equal(t, now, arg.At)
+				return w.guests, nil
+			},
+			getWatchlistEntryByPlateFn: func(_ context.Context, plate string) (repo.PlateWatchlist, error) {
+				if !w.blacklist {
+					return repo.PlateWatchlist{}, sql.ErrNoRows
+				}
+				return repo.PlateWatchlist{ID: uuid.New(), PlateNumber: plate, Severity: SeverityBlacklist}, nil
+			},
+			createWatchlistHitFn: func(context.Context, repo.CreateWatchlistHitParams) (repo.WatchlistHit, error) {
+				return repo.WatchlistHit{}, nil
+			},
+			listPassSchedulesFn: func(context.Context, uuid.UUID) ([]repo.PassSchedule, error) {
+				if !w.schedule {
+					return nil, nil
+				}
+				// Only open at night.
+				return []repo.PassSchedule{{Weekdays: 127, StartMinute: 0, EndMinute: 360}}, nil
+			},
+			getPassPresenceFn: func(context.Context, uuid.NullUUID) (repo.SitePresence, error) {
+				if w.onSite {
+					return repo.SitePresence{EnteredAt: now.Add(-time.Hour)}, nil
+				}
+				return repo.SitePresence{}, sql.ErrNoRows
+			},
+			getGuestRequestByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+				for _, guest := range w.guests {
+					if guest.ID == id {
+						return guest, nil
+					}
+				}
+				return repo.GuestRequest{}, sql.ErrNoRows
+			},
+			setGuestRequestStatusFn: func(_ context.Context, arg repo.SetGuestRequestStatusParams) (repo.GuestRequest, error) {
+				return repo.GuestRequest{ID: arg.ID, Status: arg.Status}, nil
+			},
+			createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+				logs = append(logs, arg)
+				return repo.EntryLog{ID: uuid.New(), PassID: arg.PassID, GuestRequestID: arg.GuestRequestID, ActionAt: now}, nil
+			},
+			upsertPassPresenceFn: func(context.Context, repo.UpsertPassPresenceParams) error { return nil },
+			deletePassPresenceFn: func(context.Context, uuid.NullUUID) (int64, error) { return 1, nil },
+			createPlateReadFn: func(_ context.Context, arg repo.CreatePlateReadParams) (repo.PlateRead, error) {
+				return repo.PlateRead{
+					ID: uuid.New(), CameraID: arg.CameraID, PlateRaw: arg.PlateRaw, PlateNumber: arg.PlateNumber,
+					Confidence: arg.Confidence, CapturedAt: arg.CapturedAt, Decision: arg.Decision, Reason: arg.Reason,
+					PassID: arg.PassID, GuestRequestID: arg.GuestRequestID, EntryLogID: arg.EntryLogID, ReviewStatus: arg.ReviewStatus,
+				}, nil
+			},
+		}
+		svc := New(store, WithClock(func() time.Time { return now }))
+		cam := camera
+		if w.exit {
+			cam.Direction = EntryActionExit
+		}
+		read, err := svc.RecordPlateRead(ctx, cam, input)
+		require.NoError(t, err)
+		return read, logs
+	}
+	pass := repo.Pass{ID: passID, PlateNumber: "A123BC77", Status: "active"}
+	guest := repo.GuestRequest{ID: guestID, PlateNumber: "A123BC77", Status: GuestStatusApproved, ValidFrom: now.Add(-time.Hour), ValidTo: now.Add(time.Hour)}
+
+	t.Run("active pass", func(t *testing.T) {
+		read, logs := run(t, world{passes: []repo.Pass{pass}}, PlateReadInput{Plate: " a123bc77 ", Confidence: 0.93})
+		require.Equal(t, PlateDecisionAllow, read.Decision)
+		require.Equal(t, "A123BC77", read.PlateNumber)
+		require.Equal(t, "a123bc77", read.PlateRaw)
+		require.Equal(t, now, read.CapturedAt)
+		require.Equal(t, uuid.NullUUID{UUID: passID, Valid: true}, read.PassID)
+		require.True(t, read.EntryLogID.Valid)
+		require.False(t, read.ReviewStatus.Valid)
+		require.Len(t, logs, 1)
+		require.Equal(t, operatorID, logs[0].GuardUserID)
+		require.Equal(t, EntryActionEntry, logs[0].Action)
+		require.Equal(t, "camera North", logs[0].Comment.String)
+	})
+	t.Run("guest request", func(t *testing.T) {
+		read, logs := run(t, world{guests: []repo.GuestRequest{guest}}, PlateReadInput{Plate: "A123BC77", Confidence: 0.9, CapturedAt: now.Add(-time.Second)})
+		require.Equal(t, PlateDecisionAllow, read.Decision)
+		require.Equal(t, uuid.NullUUID{UUID: guestID, Valid: true}, read.GuestRequestID)
+		require.Equal(t, now.Add(-time.Second), read.CapturedAt)
+		require.Len(t, logs, 1)
+		require.Equal(t, EntryActionEntry, logs[0].Action)
+	})
+	t.Run("low confidence", func(t *testing.T) {
+		read, logs := run(t, world{passes: []repo.Pass{pass}}, PlateReadInput{Plate: "A123BC77", Confidence: 0.42})
+		require.Equal(t, PlateDecisionReview, read.Decision)
+		require.Equal(t, "low confidence 0.42", read.Reason)
+		require.Equal(t, PlateReviewPending, read.ReviewStatus.String)
+		require.Equal(t, passID, read.PassID.UUID)
+		require.Empty(t, logs)
+	})
+	t.Run("unreadable plate", func(t *testing.T) {
+		read, logs := run(t, world{}, PlateReadInput{Plate: "A12?", Confidence: 0.99})
+		require.Equal(t, PlateDecisionReview, read.Decision)
+		require.Equal(t, PlateReviewPending, read.ReviewStatus.String)
+		require.Empty(t, logs)
+	})
+	t.Run("blacklisted", func(t *testing.T) {
+		read, logs := run(t, world{passes: []repo.Pass{pass}, blacklist: true}, PlateReadInput{Plate: "A123BC77", Confidence: 0.99})
+		require.Equal(t, PlateDecisionDeny, read.Decision)
+		require.Equal(t, ErrPlateBlacklisted.Error(), read.Reason)
+		require.False(t, read.ReviewStatus.Valid)
+		require.Empty(t, logs)
+	})
+	t.Run("unknown plate", func(t *testing.T) {
+		read, logs := run(t, world{}, PlateReadInput{Plate: "A123BC77", Confidence: 0.99})
+		require.Equal(t, PlateDecisionDeny, read.Decision)
+		require.Empty(t, logs)
+	})
+	t.Run("outside schedule", func(t *testing.T) {
+		read, logs := run(t, world{passes: []repo.Pass{pass}, schedule: true}, PlateReadInput{Plate: "A123BC77", Confidence: 0.99})
+		require.Equal(t, PlateDecisionReview, read.Decision)
+		require.Empty(t, logs)
+	})
+	t.Run("journal rejects", func(t *testing.T) {
+		read, logs := run(t, world{passes: []repo.Pass{pass}, onSite: true}, PlateReadInput{Plate: "A123BC77", Confidence: 0.99})
+		require.Equal(t, PlateDecisionReview, read.Decision)
+		require.Equal(t, ErrAlreadyOnSite.Error(), read.Reason)
+		require.False(t, read.EntryLogID.Valid)
+		require.Empty(t, logs)
+	})
+	suspended := pass
+	suspended.Status = PassStatusSuspended
+	t.Run("suspended before the entry", func(t *testing.T) {
+		read, logs := run(t, world{passes: []repo.Pass{suspended}}, PlateReadInput{Plate: "A123BC77", Confidence: 0.99})
+		require.Equal(t, PlateDecisionReview, read.Decision)
+		require.Equal(t, ErrPassInactive.Error(), read.Reason)
+		require.Empty(t, logs)
+	})
+	t.Run("suspended car leaves", func(t *testing.T) {
+		read, logs := run(t, world{passes: []repo.Pass{suspended}, onSite: true, exit: true}, PlateReadInput{Plate: "A123BC77", Confidence: 0.99})
+		require.Equal(t, PlateDecisionAllow, read.Decision)
+		require.Equal(t, "leaving on an inactive pass", read.Reason)
+		require.True(t, read.EntryLogID.Valid)
+		require.Len(t, logs, 1)
+		require.Equal(t, EntryActionExit, logs[0].Action)
+	})
+
+	svc := New(&mockStore{})
+	for _, input := range []PlateReadInput{{Plate: "A123BC77", Confidence: 1.5}, {Plate: "A123BC77", Confidence: -0.1}} {
+		_, err := svc.RecordPlateRead(ctx, camera, input)
+		require.ErrorIs(t, err, ErrInvalidConfidence)
+	}
+	_, err := svc.RecordPlateRead(ctx, camera, PlateReadInput{Plate: " ", Confidence: 0.9})
+	require.ErrorIs(t, err, ErrInvalidPlate)
+}
+
+func TestServiceUnit_ReviewPlateRead(t *testing.T) {
+	ctx := context.Background()
+	guardID := uuid.New()
+	passID := uuid.New()
+	camera := repo.Camera{ID: uuid.New(), Name: "South", Direction: EntryActionExit}
+	pending := repo.PlateRead{ID: uuid.New(), CameraID: camera.ID, PlateNumber: "A123BC77", ReviewStatus: sql.NullString{String: PlateReviewPending, Valid: true}}
+	var (
+		read     repo.PlateRead
+		claimed  []repo.ClaimPlateReadParams
+		reopened bool
+		linked   uuid.NullUUID
+		onSite   bool
+	)
+	store := &mockStore{
+		getPlateReadFn: func(_ context.Context, id uuid.UUID) (repo.PlateRead, error) {
+			if id != read.ID {
+				return repo.PlateRead{}, sql.ErrNoRows
+			}
+			return read, nil
+		},
+		getCameraFn: func(context.Context, uuid.UUID) (repo.Camera, error) { return camera, nil },
+		getPassByIDFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			return repo.Pass{ID: id, PlateNumber: "A123BC77"}, nil
+		},
+		getPassByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			return repo.Pass{ID: id, PlateNumber: "A123BC77", Status: PassStatusActive}, nil
+		},
+		getPassByIDAnyForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			return repo.Pass{ID: id, PlateNumber: "A123BC77", Status: PassStatusActive}, nil
+		},
+		claimPlateReadFn: func(_ context.Context, arg repo.ClaimPlateReadParams) (repo.PlateRead, error) {
+			if read.ReviewStatus.String != PlateReviewPending {
+				return repo.PlateRead{}, sql.ErrNoRows
+			}
+			claimed = append(claimed, arg)
+			out := read
+			out.ReviewStatus = sql.NullString{String: arg.ReviewStatus, Valid: true}
+			out.ReviewedBy = arg.ReviewedBy
+			return out, nil
+		},
+		reopenPlateReadFn: func(context.Context, uuid.UUID) error { reopened = true; return nil },
+		getPassPresenceFn: func(context.Context, uuid.NullUUID) (repo.SitePresence, error) {
+			if onSite {
+				return repo.SitePresence{}, nil
+			}
+			return repo.SitePresence{}, sql.ErrNoRows
+		},
+		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+			require.Equal(t, guardID, arg.GuardUserID)
+			require.Equal(t, "camera South: waved through", arg.Comment.String)
+			return repo.EntryLog{ID: uuid.New()}, nil
+		},
+		deletePassPresenceFn: func(context.Context, uuid.NullUUID) (int64, error) { return 1, nil },
+		setPlateReadEntryLogFn: func(_ context.Context, arg repo.SetPlateReadEntryLogParams) error {
+			linked = arg.EntryLogID
+			return nil
+		},
+	}
+	svc := New(store)
+
+	_, err := svc.ReviewPlateRead(ctx, PlateReviewInput{ID: uuid.New(), GuardID: guardID})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	read = pending
+	_, err = svc.ReviewPlateRead(ctx, PlateReviewInput{ID: read.ID, GuardID: guardID, Allow: true})
+	require.ErrorIs(t, err, ErrPlateReadTarget)
+
+	denied, err := svc.ReviewPlateRead(ctx, PlateReviewInput{ID: read.ID, GuardID: guardID, Comment: " not ours "})
+	require.NoError(t, err)
+	require.Equal(t, PlateReviewDenied, denied.ReviewStatus.String)
+	require.Equal(t, "not ours", claimed[0].ReviewComment.String)
+
+	read.ReviewStatus.String = PlateReviewDenied
+	_, err = svc.ReviewPlateRead(ctx, PlateReviewInput{ID: read.ID, GuardID: guardID})
+	require.ErrorIs(t, err, ErrPlateReadResolved)
+
+	// The pass leaving is not on site, so the journal refuses and the read
+	// goes back to the queue.
+	read = pending
+	_, err = svc.ReviewPlateRead(ctx, PlateReviewInput{ID: read.ID, GuardID: guardID, Allow: true, PassID: passID, Comment: "waved through"})
+	require.ErrorIs(t, err, ErrNotOnSite)
+	require.True(t, reopened)
+	require.False(t, linked.Valid)
+
+	onSite = true
+	allowed, err := svc.ReviewPlateRead(ctx, PlateReviewInput{ID: read.ID, GuardID: guardID, Allow: true, PassID: passID, Comment: "waved through"})
+	require.NoError(t, err)
+	require.Equal(t, PlateReviewAllowed, allowed.ReviewStatus.String)
+	require.True(t, linked.Valid)
+	require.Equal(t, linked, allowed.EntryLogID)
+}
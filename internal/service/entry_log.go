@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	EntryActionEntry = "entry"
+	EntryActionExit  = "exit"
+)
+
+var ErrInvalidEntryAction = errors.New("invalid entry action")
+
+// EntryLogFilter narrows the gate journal; zero fields are not applied.
+// Plate matches any part of the plate number. A non-nil OwnerID limits the
+// journal to the passes and guests of one resident.
+type EntryLogFilter struct {
+	PassID  uuid.UUID
+	From    time.Time
+	To      time.Time
+	Action  string
+	GuardID uuid.UUID
+	Plate   string
+	Plot    string
+	GateID  uuid.UUID
+	OwnerID uuid.UUID
+}
+
+// entryLogConditions are the checked and normalized filter values shared by
+// the journal list and its export.
+type entryLogConditions struct {
+	passID, guardID, gateID, ownerID uuid.NullUUID
+	from, to                         sql.NullTime
+	action, platePattern, plot       sql.NullString
+}
+
+func (f EntryLogFilter) conditions() (entryLogConditions, error) {
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return entryLogConditions{}, ErrInvalidRange
+	}
+	switch f.Action {
+	case "", EntryActionEntry, EntryActionExit, EntryActionAmendment:
+	default:
+		return entryLogConditions{}, ErrInvalidEntryAction
+	}
+	plate := NormalizePlate(f.Plate)
+	plot := NormalizePlotNumber(f.Plot)
+	return entryLogConditions{
+		passID:       uuid.NullUUID{UUID: f.PassID, Valid: f.PassID != uuid.Nil},
+		guardID:      uuid.NullUUID{UUID: f.GuardID, Valid: f.GuardID != uuid.Nil},
+		gateID:       uuid.NullUUID{UUID: f.GateID, Valid: f.GateID != uuid.Nil},
+		ownerID:      uuid.NullUUID{UUID: f.OwnerID, Valid: f.OwnerID != uuid.Nil},
+		from:         sql.NullTime{Time: f.From, Valid: !f.From.IsZero()},
+		to:           sql.NullTime{Time: f.To, Valid: !f.To.IsZero()},
+		action:       sql.NullString{String: f.Action, Valid: f.Action != ""},
+		platePattern: sql.NullString{String: "%" + escapeLike(plate) + "%", Valid: plate != ""},
+		plot:         sql.NullString{String: plot, Valid: plot != ""},
+	}, nil
+}
+
+func (s *Service) ListEntryLogs(ctx context.Context, filter EntryLogFilter, limit, offset int32) ([]repo.ListEntryLogsRow, error) {
+	c, err := filter.conditions()
+	if err != nil {
+		return nil, err
+	}
+	return s.q.ListEntryLogs(ctx, repo.ListEntryLogsParams{
+		PassID:       c.passID,
+		FromTime:     c.from,
+		ToTime:       c.to,
+		Action:       c.action,
+		GuardUserID:  c.guardID,
+		PlatePattern: c.platePattern,
+		GateID:       c.gateID,
+		PlotNumber:   c.plot,
+		OwnerUserID:  c.ownerID,
+		PageSize:     limit,
+		PageOffset:   offset,
+	})
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	EntryActionAmendment = "amendment"
+
+	// AmendmentVoid marks the amended record as recorded in error.
+	AmendmentVoid = "void"
+
+	ChainBreakSeqGap   = "seq_gap"
+	ChainBreakPrevHash = "prev_hash_mismatch"
+	ChainBreakHash     = "hash_mismatch"
+
+	chainBatchSize = 500
+)
+
+// chainGenesis is the previous hash of the first journal record.
+var chainGenesis = strings.Repeat("0", 64)
+
+var (
+	ErrAmendmentReason = errors.New("amendment reason is required")
+	ErrAmendmentTarget = errors.New("amendments cannot be amended")
+	ErrEntryLogExists  = errors.New("entry log already exists")
+)
+
+// appendEntryLog seals a journal record onto the hash chain. The advisory
+// lock serializes writers until the surrounding transaction ends, so callers
+// must run it inside inTx.
+func (s *Service) appendEntryLog(ctx context.Context, q ServiceStore, params repo.CreateEntryLogParams) (repo.EntryLog, error) {
+	if err := q.LockEntryLogChain(ctx); err != nil {
+		return repo.EntryLog{}, err
+	}
+	prevHash := chainGenesis
+	var seq int64
+	head, err := q.GetEntryLogChainHead(ctx)
+	switch {
+	case err == nil:
+		seq, prevHash = head.Seq, head.Hash
+	case !errors.Is(err, sql.ErrNoRows):
+		return repo.EntryLog{}, err
+	}
+	if params.ID == uuid.Nil {
+		params.ID = uuid.New()
+	} else {
+		// Records synced from a device bring their own id; a retry that raced
+		// the first attempt stops here instead of on the primary key.
+		_, err := q.GetEntryLog(ctx, params.ID)
+		if err == nil {
+			return repo.EntryLog{}, ErrEntryLogExists
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return repo.EntryLog{}, err
+		}
+	}
+	// Postgres keeps microseconds; hash exactly what will be stored.
+	params.ActionAt = s.now().UTC().Truncate(time.Microsecond)
+	if params.DeviceAt.Valid {
+		params.DeviceAt.Time = params.DeviceAt.Time.UTC().Truncate(time.Microsecond)
+	}
+	params.Seq = seq + 1
+	params.PrevHash = prevHash
+	params.Hash = entryLogHash(prevHash, entryLogCanonical(repo.EntryLog{
+		ID:                params.ID,
+		PassID:            params.PassID,
+		GuardUserID:       params.GuardUserID,
+		Action:            params.Action,
+		ActionAt:          params.ActionAt,
+		Comment:           params.Comment,
+		GuestRequestID:    params.GuestRequestID,
+		Anomaly:           params.Anomaly,
+		OverrideReason:    params.OverrideReason,
+		Seq:               params.Seq,
+		AmendsID:          params.AmendsID,
+		CorrectedAction:   params.CorrectedAction,
+		CorrectedActionAt: params.CorrectedActionAt,
+		GateID:            params.GateID,
+		ShiftID:           params.ShiftID,
+		DeviceAt:          params.DeviceAt,
+	}))
+	return q.CreateEntryLog(ctx, params)
+}
+
+// entryLogCanonical is the byte form a record's hash covers: one
+// "key:byte length:value" line per field, NULL fields left out so that
+// columns added later do not invalidate older records. Migration 0015
+// computes the same form in SQL.
+func entryLogCanonical(e repo.EntryLog) string {
+	var b strings.Builder
+	field := func(key, value string) {
+		b.WriteString(key)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(len(value)))
+		b.WriteByte(':')
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+	optUUID := func(key string, v uuid.NullUUID) {
+		if v.Valid {
+			field(key, v.UUID.String())
+		}
+	}
+	optString := func(key string, v sql.NullString) {
+		if v.Valid {
+			field(key, v.String)
+		}
+	}
+	field("seq", strconv.FormatInt(e.Seq, 10))
+	field("id", e.ID.String())
+	optUUID("pass_id", e.PassID)
+	optUUID("guest_request_id", e.GuestRequestID)
+	field("guard_user_id", e.GuardUserID.String())
+	field("action", e.Action)
+	field("action_at", chainTime(e.ActionAt))
+	optString("comment", e.Comment)
+	optString("anomaly", e.Anomaly)
+	optString("override_reason", e.OverrideReason)
+	optUUID("amends_id", e.AmendsID)
+	optString("corrected_action", e.CorrectedAction)
+	if e.CorrectedActionAt.Valid {
+		field("corrected_action_at", chainTime(e.CorrectedActionAt.Time))
+	}
+	optUUID("gate_id", e.GateID)
+	optUUID("shift_id", e.ShiftID)
+	if e.DeviceAt.Valid {
+		field("device_at", chainTime(e.DeviceAt.Time))
+	}
+	return b.String()
+}
+
+func chainTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
+
+func entryLogHash(prevHash, canonical string) string {
+	sum := sha256.Sum256([]byte(prevHash + "\n" + canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainBreak is the first journal record that does not fit the chain.
+type ChainBreak struct {
+	Seq    int64
+	ID     uuid.UUID
+	Reason string
+}
+
+// ChainReport is the outcome of walking the whole journal.
+type ChainReport struct {
+	Checked  int64
+	HeadSeq  int64
+	HeadHash string
+	Break    *ChainBreak
+}
+
+func (r ChainReport) OK() bool {
+	return r.Break == nil
+}
+
+// VerifyEntryLogChain recomputes every hash in sequence order and stops at
+// the first record that was edited, removed or inserted out of band.
+func (s *Service) VerifyEntryLogChain(ctx context.Context) (ChainReport, error) {
+	report := ChainReport{HeadHash: chainGenesis}
+	for {
+		batch, err := s.q.ListEntryLogChain(ctx, repo.ListEntryLogChainParams{Seq: report.HeadSeq, Limit: chainBatchSize})
+		if err != nil {
+			return ChainReport{}, err
+		}
+		for _, e := range batch {
+			var reason string
+			switch {
+			case e.Seq != report.HeadSeq+1:
+				reason = ChainBreakSeqGap
+			case e.PrevHash != report.HeadHash:
+				reason = ChainBreakPrevHash
+			case e.Hash != entryLogHash(e.PrevHash, entryLogCanonical(e)):
+				reason = ChainBreakHash
+			}
+			if reason != "" {
+				report.Break = &ChainBreak{Seq: e.Seq, ID: e.ID, Reason: reason}
+				return report, nil
+			}
+			report.Checked++
+			report.HeadSeq, report.HeadHash = e.Seq, e.Hash
+		}
+		if len(batch) < chainBatchSize {
+			return report, nil
+		}
+	}
+}
+
+// EntryLogAmendment corrects a journal record without touching it: Action
+// replaces the recorded direction ("void" withdraws the record), ActionAt
+// the recorded time. Both may be left empty for a note-only amendment.
+type EntryLogAmendment struct {
+	EntryLogID uuid.UUID
+	AuthorID   uuid.UUID
+	Reason     string
+	Action     string
+	ActionAt   time.Time
+}
+
+// AmendEntryLog appends a correction record pointing at the original. The
+// original stays as it was and on-site presence is not recalculated.
+func (s *Service) AmendEntryLog(ctx context.Context, input EntryLogAmendment) (repo.EntryLog, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return repo.EntryLog{}, ErrAmendmentReason
+	}
+	switch input.Action {
+	case "", EntryActionEntry, EntryActionExit, AmendmentVoid:
+	default:
+		return repo.EntryLog{}, ErrInvalidEntryAction
+	}
+	if !input.ActionAt.IsZero() && input.ActionAt.After(s.now()) {
+		return repo.EntryLog{}, ErrInvalidRange
+	}
+	var amendment repo.EntryLog
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		original, err := q.GetEntryLog(ctx, input.EntryLogID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if original.AmendsID.Valid {
+			return ErrAmendmentTarget
+		}
+		amendment, err = s.appendEntryLog(ctx, q, repo.CreateEntryLogParams{
+			PassID:            original.PassID,
+			GuestRequestID:    original.GuestRequestID,
+			GuardUserID:       input.AuthorID,
+			Action:            EntryActionAmendment,
+			Comment:           sql.NullString{String: reason, Valid: true},
+			AmendsID:          uuid.NullUUID{UUID: original.ID, Valid: true},
+			CorrectedAction:   sql.NullString{String: input.Action, Valid: input.Action != ""},
+			CorrectedActionAt: sql.NullTime{Time: input.ActionAt.UTC().Truncate(time.Microsecond), Valid: !input.ActionAt.IsZero()},
+		})
+		return err
+	})
+	return amendment, err
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+// chainStore keeps the journal in memory the way entry_logs stores it.
+func chainStore(logs *[]repo.EntryLog) *mockStore {
+	return &mockStore{
+		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+			e := repo.EntryLog{
+				ID: arg.ID, PassID: arg.PassID, GuestRequestID: arg.GuestRequestID, GuardUserID: arg.GuardUserID,
+				Action: arg.Action, ActionAt: arg.ActionAt, Comment: arg.Comment, Anomaly: arg.Anomaly,
+				OverrideReason: arg.OverrideReason, Seq: arg.Seq, PrevHash: arg.PrevHash, Hash: arg.Hash,
+				AmendsID: arg.AmendsID, CorrectedAction: arg.CorrectedAction, CorrectedActionAt: arg.CorrectedActionAt,
+			}
+			*logs = append(*logs, e)
+			return e, nil
+		},
+		getEntryLogChainHeadFn: func(context.Context) (repo.GetEntryLogChainHeadRow, error) {
+			if len(*logs) == 0 {
+				return repo.GetEntryLogChainHeadRow{}, sql.ErrNoRows
+			}
+			last := (*logs)[len(*logs)-1]
+			return repo.GetEntryLogChainHeadRow{Seq: last.Seq, Hash: last.Hash}, nil
+		},
+		listEntryLogChainFn: func(_ context.Context, arg repo.ListEntryLogChainParams) ([]repo.EntryLog, error) {
+			var out []repo.EntryLog
+			for _, e := range *logs {
+				if e.Seq > arg.Seq && len(out) < int(arg.Limit) {
+					out = append(out, e)
+				}
+			}
+			return out, nil
+		},
+		getEntryLogFn: func(_ context.Context, id uuid.UUID) (repo.EntryLog, error) {
+			for _, e := range *logs {
+				if e.ID == id {
+					return e, nil
+				}
+			}
+			return repo.EntryLog{}, sql.ErrNoRows
+		},
+	}
+}
+
+func TestServiceUnit_EntryLogCanonical(t *testing.T) {
+	id := uuid.MustParse("7d0e4a55-4f5e-4a5f-9a43-6a0f5c1d2e01")
+	passID := uuid.MustParse("1b9b6f5a-3c44-4d1e-8f9e-2a7c6d5e4f03")
+	guardID := uuid.MustParse("c3a1e2d4-5b6f-4a7e-9c8d-0e1f2a3b4c05")
+	e := repo.EntryLog{
+		Seq:         7,
+		ID:          id,
+		PassID:      uuid.NullUUID{UUID: passID, Valid: true},
+		GuardUserID: guardID,
+		Action:      EntryActionEntry,
+		ActionAt:    time.Date(2025, 5, 1, 15, 4, 5, 123456000, time.FixedZone("MSK", 3*3600)),
+		Comment:     sql.NullString{String: "шлагбаум", Valid: true},
+	}
+	want := "seq:1:7\n" +
+		"id:36:" + id.String() + "\n" +
+		"pass_id:36:" + passID.String() + "\n" +
+		"guard_user_id:36:" + guardID.String() + "\n" +
+		"action:5:entry\n" +
+		"action_at:27:2025-05-01T12:04:05.123456Z\n" +
+		"comment:16:шлагбаум\n"
+	require.Equal(t, want, entryLogCanonical(e))
+	require.Len(t, entryLogHash(chainGenesis, want), 64)
+	require.NotEqual(t, entryLogHash(chainGenesis, want), entryLogHash(entryLogHash(chainGenesis, want), want))
+}
+
+func TestServiceUnit_EntryLogChain(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	var logs []repo.EntryLog
+	svc := New(chainStore(&logs), WithClock(func() time.Time { return now }))
+	passID, guardID := uuid.New(), uuid.New()
+
+	report, err := svc.VerifyEntryLogChain(ctx)
+	require.NoError(t, err)
+	require.True(t, report.OK())
+	require.Zero(t, report.Checked)
+
+	for i := 0; i < chainBatchSize+2; i++ {
+		_, err := svc.CreateEntryLog(ctx, passID, guardID, EntryActionEntry, sql.NullString{})
+		require.NoError(t, err)
+	}
+	require.Equal(t, chainGenesis, logs[0].PrevHash)
+	require.Equal(t, int64(1), logs[0].Seq)
+	require.Equal(t, logs[0].Hash, logs[1].PrevHash)
+	require.Equal(t, now.Truncate(time.Microsecond), logs[0].ActionAt)
+
+	report, err = svc.VerifyEntryLogChain(ctx)
+	require.NoError(t, err)
+	require.True(t, report.OK())
+	require.Equal(t, int64(len(logs)), report.Checked)
+	require.Equal(t, logs[len(logs)-1].Hash, report.HeadHash)
+
+	intact := append([]repo.EntryLog(nil), logs...)
+	cases := []struct {
+		name    string
+		tamper  func()
+		seq     int64
+		checked int64
+		reason  string
+	}{
+		{"edited field", func() { logs[3].Action = EntryActionExit }, 4, 3, ChainBreakHash},
+		{"edited and resealed", func() {
+			logs[3].Comment = sql.NullString{String: "rewritten", Valid: true}
+			logs[3].Hash = entryLogHash(logs[3].PrevHash, entryLogCanonical(logs[3]))
+		}, 5, 4, ChainBreakPrevHash},
+		{"deleted record", func() { logs = append(logs[:3:3], logs[4:]...) }, 5, 3, ChainBreakSeqGap},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs = append([]repo.EntryLog(nil), intact...)
+			tc.tamper()
+			report, err := svc.VerifyEntryLogChain(ctx)
+			require.NoError(t, err)
+			require.False(t, report.OK())
+			require.Equal(t, tc.checked, report.Checked)
+			require.Equal(t, tc.seq, report.Break.Seq)
+			require.Equal(t, tc.reason, report.Break.Reason)
+		})
+	}
+}
+
+func TestServiceUnit_AmendEntryLog(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	var logs []repo.EntryLog
+	svc := New(chainStore(&logs), WithClock(func() time.Time { return now }))
+	guardID, adminID := uuid.New(), uuid.New()
+	original, err := svc.CreateEntryLog(ctx, uuid.New(), guardID, EntryActionEntry, sql.NullString{})
+	require.NoError(t, err)
+
+	_, err = svc.AmendEntryLog(ctx, EntryLogAmendment{EntryLogID: original.ID, AuthorID: adminID, Reason: " "})
+	require.ErrorIs(t, err, ErrAmendmentReason)
+	_, err = svc.AmendEntryLog(ctx, EntryLogAmendment{EntryLogID: original.ID, AuthorID: adminID, Reason: "typo", Action: "parked"})
+	require.ErrorIs(t, err, ErrInvalidEntryAction)
+	_, err = svc.AmendEntryLog(ctx, EntryLogAmendment{EntryLogID: original.ID, AuthorID: adminID, Reason: "typo", ActionAt: now.Add(time.Hour)})
+	require.ErrorIs(t, err, ErrInvalidRange)
+	_, err = svc.AmendEntryLog(ctx, EntryLogAmendment{EntryLogID: uuid.New(), AuthorID: adminID, Reason: "typo"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	at := now.Add(-10 * time.Minute)
+	amendment, err := svc.AmendEntryLog(ctx, EntryLogAmendment{
+		EntryLogID: original.ID, AuthorID: adminID, Reason: " wrong button ", Action: EntryActionExit, ActionAt: at,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EntryActionAmendment, amendment.Action)
+	require.Equal(t, original.ID, amendment.AmendsID.UUID)
+	require.Equal(t, original.PassID, amendment.PassID)
+	require.Equal(t, adminID, amendment.GuardUserID)
+	require.Equal(t, "wrong button", amendment.Comment.String)
+	require.Equal(t, EntryActionExit, amendment.CorrectedAction.String)
+	require.Equal(t, at, amendment.CorrectedActionAt.Time)
+	require.Equal(t, original, logs[0])
+
+	_, err = svc.AmendEntryLog(ctx, EntryLogAmendment{EntryLogID: amendment.ID, AuthorID: adminID, Reason: "again", Action: AmendmentVoid})
+	require.ErrorIs(t, err, ErrAmendmentTarget)
+
+	report, err := svc.VerifyEntryLogChain(ctx)
+	require.NoError(t, err)
+	require.True(t, report.OK())
+	require.Equal(t, int64(2), report.Checked)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_ListEntryLogs(t *testing.T) {
+	ctx := context.Background()
+	var got repo.ListEntryLogsParams
+	svc := New(&mockStore{
+		listEntryLogsFn: func(_ context.Context, arg repo.ListEntryLogsParams) ([]repo.ListEntryLogsRow, error) {
+			got = arg
+			return []repo.ListEntryLogsRow{{ID: uuid.New(), PlateNumber: "A123BC77", GuardFullName: "Guard"}}, nil
+		},
+	})
+
+	rows, err := svc.ListEntryLogs(ctx, EntryLogFilter{}, 20, 40)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.False(t, got.PassID.Valid)
+	require.False(t, got.FromTime.Valid)
+	require.False(t, got.Action.Valid)
+	require.False(t, got.PlatePattern.Valid)
+	require.False(t, got.PlotNumber.Valid)
+	require.EqualValues(t, 20, got.PageSize)
+	require.EqualValues(t, 40, got.PageOffset)
+
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	guard := uuid.New()
+	_, err = svc.ListEntryLogs(ctx, EntryLogFilter{
+		From:    from,
+		To:      from.Add(24 * time.Hour),
+		Action:  EntryActionExit,
+		GuardID: guard,
+		Plate:   " a1_3 ",
+		Plot:    " 12 ",
+	}, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, from, got.FromTime.Time)
+	require.Equal(t, from.Add(24*time.Hour), got.ToTime.Time)
+	require.Equal(t, EntryActionExit, got.Action.String)
+	require.Equal(t, guard, got.GuardUserID.UUID)
+	require.Equal(t, `%A1\_3%`, got.PlatePattern.String)
+	require.Equal(t, "12", got.PlotNumber.String)
+
+	_, err = svc.ListEntryLogs(ctx, EntryLogFilter{Action: "checkin"}, 10, 0)
+	require.ErrorIs(t, err, ErrInvalidEntryAction)
+	_, err = svc.ListEntryLogs(ctx, EntryLogFilter{From: from, To: from}, 10, 0)
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const exportBatchSize = 500
+
+// ExportFilter mirrors the list endpoint filters. A non-nil OwnerID limits
+// passes and guest requests to a single resident.
+type ExportFilter struct {
+	IncludeDeleted bool
+	OwnerID        uuid.UUID
+}
+
+func (f ExportFilter) owner() uuid.NullUUID {
+	return uuid.NullUUID{UUID: f.OwnerID, Valid: f.OwnerID != uuid.Nil}
+}
+
+func (s *Service) Location() *time.Location {
+	return s.settings.Location
+}
+
+// Export methods page through the table with a keyset cursor and hand every
+// row to fn, so callers can stream arbitrarily large result sets.
+
+func (s *Service) ExportUsers(ctx context.Context, filter ExportFilter, fn func(repo.User) error) error {
+	params := repo.ExportUsersParams{IncludeDeleted: filter.IncludeDeleted, BatchSize: exportBatchSize}
+	for {
+		batch, err := s.q.ExportUsers(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, row := range batch {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+func (s *Service) ExportPasses(ctx context.Context, filter ExportFilter, fn func(repo.ExportPassesRow) error) error {
+	params := repo.ExportPassesParams{
+		IncludeDeleted: filter.IncludeDeleted,
+		OwnerUserID:    filter.owner(),
+		BatchSize:      exportBatchSize,
+	}
+	for {
+		batch, err := s.q.ExportPasses(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, row := range batch {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+func (s *Service) ExportGuestRequests(ctx context.Context, filter ExportFilter, fn func(repo.ExportGuestRequestsRow) error) error {
+	params := repo.ExportGuestRequestsParams{
+		IncludeDeleted: filter.IncludeDeleted,
+		ResidentUserID: filter.owner(),
+		BatchSize:      exportBatchSize,
+	}
+	for {
+		batch, err := s.q.ExportGuestRequests(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, row := range batch {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+// ExportEntryLogs takes the journal list filter, so a file holds exactly the
+// rows GET /entry-logs shows.
+func (s *Service) ExportEntryLogs(ctx context.Context, filter EntryLogFilter, fn func(repo.ExportEntryLogsRow) error) error {
+	c, err := filter.conditions()
+	if err != nil {
+		return err
+	}
+	params := repo.ExportEntryLogsParams{
+		OwnerUserID:  c.ownerID,
+		FromTime:     c.from,
+		ToTime:       c.to,
+		PassID:       c.passID,
+		Action:       c.action,
+		GuardUserID:  c.guardID,
+		PlatePattern: c.platePattern,
+		GateID:       c.gateID,
+		PlotNumber:   c.plot,
+		BatchSize:    exportBatchSize,
+	}
+	for {
+		batch, err := s.q.ExportEntryLogs(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, row := range batch {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		params.AfterActionAt, params.AfterID = last.ActionAt, last.ID
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_ExportUsersPaginates(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := make([]repo.User, exportBatchSize+3)
+	for i := range users {
+		users[i] = repo.User{ID: uuid.New(), CreatedAt: base.Add(time.Duration(i) * time.Second)}
+	}
+	var calls []repo.ExportUsersParams
+	svc := New(&mockStore{
+		exportUsersFn: func(_ context.Context, arg repo.ExportUsersParams) ([]repo.User, error) {
+			calls = append(calls, arg)
+			if arg.AfterID == uuid.Nil {
+				return users[:exportBatchSize], nil
+			}
+			return users[exportBatchSize:], nil
+		},
+	})
+
+	count := 0
+	err := svc.ExportUsers(ctx, ExportFilter{IncludeDeleted: true}, func(repo.User) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(users), count)
+	require.Len(t, calls, 2)
+	require.True(t, calls[0].IncludeDeleted)
+	require.Equal(t, int32(exportBatchSize), calls[0].BatchSize)
+	require.Equal(t, users[exportBatchSize-1].ID, calls[1].AfterID)
+	require.Equal(t, users[exportBatchSize-1].CreatedAt, calls[1].AfterCreatedAt)
+
+	stop := errors.New("stop")
+	err = svc.ExportUsers(ctx, ExportFilter{}, func(repo.User) error { return stop })
+	require.ErrorIs(t, err, stop)
+}
+
+func TestServiceUnit_ExportPassesAndGuests(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	var passParams repo.ExportPassesParams
+	var guestParams repo.ExportGuestRequestsParams
+	svc := New(&mockStore{
+		exportPassesFn: func(_ context.Context, arg repo.ExportPassesParams) ([]repo.ExportPassesRow, error) {
+			passParams = arg
+			return []repo.ExportPassesRow{{ID: uuid.New(), OwnerUserID: ownerID}}, nil
+		},
+		exportGuestRequestsFn: func(_ context.Context, arg repo.ExportGuestRequestsParams) ([]repo.ExportGuestRequestsRow, error) {
+			guestParams = arg
+			return []repo.ExportGuestRequestsRow{{ID: uuid.New()}, {ID: uuid.New()}}, nil
+		},
+	})
+
+	var passes []repo.ExportPassesRow
+	err := svc.ExportPasses(ctx, ExportFilter{OwnerID: ownerID}, func(row repo.ExportPassesRow) error {
+		passes = append(passes, row)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, passes, 1)
+	require.Equal(t, uuid.NullUUID{UUID: ownerID, Valid: true}, passParams.OwnerUserID)
+
+	guests := 0
+	err = svc.ExportGuestRequests(ctx, ExportFilter{IncludeDeleted: true}, func(repo.ExportGuestRequestsRow) error {
+		guests++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, guests)
+	require.False(t, guestParams.ResidentUserID.Valid)
+	require.True(t, guestParams.IncludeDeleted)
+}
+
+func TestServiceUnit_ExportEntryLogs(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	var params repo.ExportEntryLogsParams
+	svc := New(&mockStore{
+		exportEntryLogsFn: func(_ context.Context, arg repo.ExportEntryLogsParams) ([]repo.ExportEntryLogsRow, error) {
+			params = arg
+			return []repo.ExportEntryLogsRow{{ID: uuid.New(), Action: "entry"}}, nil
+		},
+	})
+
+	count := 0
+	ownerID, guardID := uuid.New(), uuid.New()
+	filter := EntryLogFilter{From: from, To: to, Action: EntryActionExit, GuardID: guardID, Plate: "a12", Plot: " 7 ", OwnerID: ownerID}
+	err := svc.ExportEntryLogs(ctx, filter, func(repo.ExportEntryLogsRow) error {
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, from, params.FromTime.Time)
+	require.True(t, params.ToTime.Valid)
+	require.Equal(t, EntryActionExit, params.Action.String)
+	require.Equal(t, guardID, params.GuardUserID.UUID)
+	require.Equal(t, "%A12%", params.PlatePattern.String)
+	require.Equal(t, "7", params.PlotNumber.String)
+	require.Equal(t, ownerID, params.OwnerUserID.UUID)
+	require.False(t, params.PassID.Valid)
+
+	err = svc.ExportEntryLogs(ctx, EntryLogFilter{}, func(repo.ExportEntryLogsRow) error { return nil })
+	require.NoError(t, err)
+	require.False(t, params.FromTime.Valid)
+	require.False(t, params.Action.Valid)
+	require.False(t, params.OwnerUserID.Valid)
+
+	err = svc.ExportEntryLogs(ctx, EntryLogFilter{From: to, To: from}, nil)
+	require.ErrorIs(t, err, ErrInvalidRange)
+	err = svc.ExportEntryLogs(ctx, EntryLogFilter{Action: "checkin"}, nil)
+	require.ErrorIs(t, err, ErrInvalidEntryAction)
+}
+
+func TestServiceUnit_ExportErrors(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repo failed")
+	svc := New(&mockStore{
+		exportUsersFn: func(context.Context, repo.ExportUsersParams) ([]repo.User, error) { return nil, repoErr },
+	})
+	require.ErrorIs(t, svc.ExportUsers(ctx, ExportFilter{}, nil), repoErr)
+	require.ErrorIs(t, svc.ExportPasses(ctx, ExportFilter{}, nil), errMockUnimplemented)
+	require.ErrorIs(t, svc.ExportGuestRequests(ctx, ExportFilter{}, nil), errMockUnimplemented)
+	require.ErrorIs(t, svc.ExportEntryLogs(ctx, EntryLogFilter{}, nil), errMockUnimplemented)
+	require.Equal(t, time.UTC, svc.Location())
+}
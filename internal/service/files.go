@@ -0,0 +1,139 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/storage"
+)
+
+const (
+	defaultAttachmentMaxBytes = 10 << 20
+	defaultFileLinkTTL        = 15 * time.Minute
+	maxFileNameLength         = 200
+)
+
+var (
+	ErrFilesDisabled      = errors.New("file storage is not configured")
+	ErrAttachmentTooLarge = errors.New("file is too large")
+	ErrAttachmentType     = errors.New("file type is not allowed")
+	ErrFileLinkInvalid    = errors.New("download link is invalid or expired")
+)
+
+// AttachmentRules limit uploaded files and the download links handed out
+// for them.
+type AttachmentRules struct {
+	// MaxBytes caps one file; zero means 10 MiB.
+	MaxBytes int64
+	// Types are the allowed content types, detected from the file content
+	// rather than taken from the client; nil means JPEG and PNG, the formats
+	// gate photos can be decoded and thumbnailed from.
+	Types []string
+	// LinkSecret signs download links, which stay valid for LinkTTL
+	// (15 minutes when zero).
+	LinkSecret []byte
+	LinkTTL    time.Duration
+}
+
+// FileLink is a signed, expiring permission to download one file without a
+// bearer token, so it can be used in an <img> tag.
+type FileLink struct {
+	Expires   time.Time
+	Signature string
+}
+
+func WithFileStore(store storage.Store) Option {
+	return func(s *Service) {
+		s.files = store
+	}
+}
+
+func (r AttachmentRules) maxBytes() int64 {
+	if r.MaxBytes <= 0 {
+		return defaultAttachmentMaxBytes
+	}
+	return r.MaxBytes
+}
+
+func (r AttachmentRules) types() []string {
+	if r.Types == nil {
+		return []string{"image/jpeg", "image/png"}
+	}
+	return r.Types
+}
+
+// checkUpload validates a file against the attachment rules and returns its
+// detected content type.
+func (s *Service) checkUpload(data []byte) (string, error) {
+	if s.files == nil {
+		return "", ErrFilesDisabled
+	}
+	if len(data) == 0 {
+		return "", ErrInvalidInput
+	}
+	if int64(len(data)) > s.settings.Attachments.maxBytes() {
+		return "", ErrAttachmentTooLarge
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	for _, allowed := range s.settings.Attachments.types() {
+		if contentType == allowed {
+			return contentType, nil
+		}
+	}
+	return "", ErrAttachmentType
+}
+
+// cleanFileName keeps the base name of an uploaded file for display and
+// the Content-Disposition header.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		name = string(runes[len(runes)-maxFileNameLength:])
+	}
+	return name
+}
+
+// SignFileLink issues a download link for a stored file.
+func (s *Service) SignFileLink(id uuid.UUID) FileLink {
+	ttl := s.settings.Attachments.LinkTTL
+	if ttl <= 0 {
+		ttl = defaultFileLinkTTL
+	}
+	expires := s.now().Add(ttl).Truncate(time.Second)
+	return FileLink{Expires: expires, Signature: s.fileSignature(id, expires.Unix())}
+}
+
+// VerifyFileLink checks a link issued by SignFileLink.
+func (s *Service) VerifyFileLink(id uuid.UUID, expires int64, signature string) error {
+	if !s.now().Before(time.Unix(expires, 0)) {
+		return ErrFileLinkInvalid
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.fileSignature(id, expires))) {
+		return ErrFileLinkInvalid
+	}
+	return nil
+}
+
+func (s *Service) fileSignature(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.settings.Attachments.LinkSecret)
+	mac.Write([]byte(id.String() + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
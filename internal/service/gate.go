@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+var (
+	ErrGateExists      = errors.New("gate with this name already exists")
+	ErrGateInUse       = errors.New("gate is still used by pass or guest type restrictions")
+	ErrUnknownGate     = errors.New("unknown gate")
+	ErrGateRequired    = errors.New("gate is required: access is restricted to specific gates")
+	ErrGateNotAllowed  = errors.New("access through this gate is not allowed")
+	ErrGateNotAssigned = errors.New("guard is not assigned to this gate")
+	ErrNotGuard        = errors.New("gates can only be assigned to guards")
+)
+
+type GateInput struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	ActorID     uuid.UUID
+}
+
+func (s *Service) CreateGate(ctx context.Context, input GateInput) (repo.Gate, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return repo.Gate{}, ErrInvalidInput
+	}
+	if _, err := s.q.GetGateByName(ctx, name); err == nil {
+		return repo.Gate{}, ErrGateExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return repo.Gate{}, err
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	return s.q.CreateGate(ctx, repo.CreateGateParams{
+		Name:        name,
+		Description: toNullNotes(input.Description),
+		CreatedBy:   actor,
+		UpdatedBy:   actor,
+	})
+}
+
+func (s *Service) GetGate(ctx context.Context, id uuid.UUID) (repo.Gate, error) {
+	gate, err := s.q.GetGate(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Gate{}, ErrNotFound
+	}
+	return gate, err
+}
+
+func (s *Service) ListGates(ctx context.Context) ([]repo.Gate, error) {
+	return s.q.ListGates(ctx)
+}
+
+func (s *Service) UpdateGate(ctx context.Context, input GateInput) (repo.Gate, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return repo.Gate{}, ErrInvalidInput
+	}
+	if other, err := s.q.GetGateByName(ctx, name); err == nil && other.ID != input.ID {
+		return repo.Gate{}, ErrGateExists
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return repo.Gate{}, err
+	}
+	gate, err := s.q.UpdateGate(ctx, repo.UpdateGateParams{
+		ID:          input.ID,
+		Name:        name,
+		Description: toNullNotes(input.Description),
+		UpdatedBy:   uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Gate{}, ErrNotFound
+	}
+	return gate, err
+}
+
+// DeleteGate closes a gate. Its journal records keep pointing at it; guard
+// assignments are dropped, but a gate that passes or guest types are
+// restricted to has to be taken out of those restrictions first.
+func (s *Service) DeleteGate(ctx context.Context, id, actor uuid.UUID) error {
+	return s.inTx(ctx, func(q ServiceStore) error {
+		restrictions, err := q.CountGateRestrictions(ctx, id)
+		if err != nil {
+			return err
+		}
+		if restrictions > 0 {
+			return ErrGateInUse
+		}
+		affected, err := q.SoftDeleteGate(ctx, repo.SoftDeleteGateParams{ID: id, UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrNotFound
+		}
+		return q.DeleteGateAssignments(ctx, id)
+	})
+}
+
+func (s *Service) GuardGates(ctx context.Context, userID uuid.UUID) ([]repo.Gate, error) {
+	return s.q.ListGuardGates(ctx, userID)
+}
+
+// SetGuardGates replaces the gates a guard works at. A guard without gates
+// may record movements at any gate.
+func (s *Service) SetGuardGates(ctx context.Context, userID uuid.UUID, gateIDs []uuid.UUID) ([]repo.Gate, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != string(auth.RoleGuard) {
+		return nil, ErrNotGuard
+	}
+	var gates []repo.Gate
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		if err := q.ClearGuardGates(ctx, userID); err != nil {
+			return err
+		}
+		return s.addGates(ctx, q, gateIDs, func(gateID uuid.UUID) error {
+			return q.AddGuardGate(ctx, repo.AddGuardGateParams{UserID: userID, GateID: gateID})
+		}, func() (err error) {
+			gates, err = q.ListGuardGates(ctx, userID)
+			return err
+		})
+	})
+	return gates, err
+}
+
+func (s *Service) PassGates(ctx context.Context, passID uuid.UUID) ([]repo.Gate, error) {
+	return s.q.ListPassGates(ctx, passID)
+}
+
+// SetPassGates restricts a pass to the given gates; an empty list lifts the
+// restriction.
+func (s *Service) SetPassGates(ctx context.Context, passID uuid.UUID, gateIDs []uuid.UUID) ([]repo.Gate, error) {
+	if _, err := s.GetPass(ctx, passID); err != nil {
+		return nil, err
+	}
+	var gates []repo.Gate
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if err := q.ClearPassGates(ctx, passID); err != nil {
+			return err
+		}
+		return s.addGates(ctx, q, gateIDs, func(gateID uuid.UUID) error {
+			return q.AddPassGate(ctx, repo.AddPassGateParams{PassID: passID, GateID: gateID})
+		}, func() (err error) {
+			gates, err = q.ListPassGates(ctx, passID)
+			return err
+		})
+	})
+	return gates, err
+}
+
+func (s *Service) GuestTypeGates(ctx context.Context) ([]repo.ListAllGuestTypeGatesRow, error) {
+	return s.q.ListAllGuestTypeGates(ctx)
+}
+
+// SetGuestTypeGates restricts guests of a type to the given gates, e.g.
+// deliveries to the service gate; an empty list lifts the restriction.
+func (s *Service) SetGuestTypeGates(ctx context.Context, guestType string, gateIDs []uuid.UUID) ([]repo.Gate, error) {
+	if err := ValidateGuestType(guestType); err != nil {
+		return nil, err
+	}
+	var gates []repo.Gate
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if err := q.ClearGuestTypeGates(ctx, guestType); err != nil {
+			return err
+		}
+		return s.addGates(ctx, q, gateIDs, func(gateID uuid.UUID) error {
+			return q.AddGuestTypeGate(ctx, repo.AddGuestTypeGateParams{GuestType: guestType, GateID: gateID})
+		}, func() (err error) {
+			gates, err = q.ListGuestTypeGates(ctx, guestType)
+			return err
+		})
+	})
+	return gates, err
+}
+
+// addGates checks that every gate exists and links it once, then reloads
+// the resulting list.
+func (s *Service) addGates(ctx context.Context, q ServiceStore, gateIDs []uuid.UUID, add func(uuid.UUID) error, reload func() error) error {
+	seen := make(map[uuid.UUID]bool, len(gateIDs))
+	for _, gateID := range gateIDs {
+		if seen[gateID] {
+			continue
+		}
+		seen[gateID] = true
+		if _, err := q.GetGate(ctx, gateID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUnknownGate
+			}
+			return err
+		}
+		if err := add(gateID); err != nil {
+			return err
+		}
+	}
+	return reload()
+}
+
+// gateAccess is who passes where: the gate the guard named (or uuid.Nil),
+// the gate of the guard's shift, and the gates the pass or guest type is
+// restricted to.
+type gateAccess struct {
+	GateID     uuid.UUID
+	GuardID    uuid.UUID
+	ShiftGate  uuid.NullUUID
+	Restricted []repo.Gate
+}
+
+// resolveGate decides which gate a movement is recorded at. A guard on a
+// shift at a gate or with a single assigned gate may omit it; a guard with
+// assignments may only work at those gates; a restricted pass or guest type
+// needs one of its gates.
+func resolveGate(ctx context.Context, q ServiceStore, access gateAccess) (uuid.NullUUID, error) {
+	assigned, err := q.ListGuardGates(ctx, access.GuardID)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	gateID := access.GateID
+	if gateID == uuid.Nil && access.ShiftGate.Valid {
+		gateID = access.ShiftGate.UUID
+	}
+	if gateID == uuid.Nil && len(assigned) == 1 {
+		gateID = assigned[0].ID
+	}
+	if gateID != uuid.Nil {
+		if _, err := q.GetGate(ctx, gateID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return uuid.NullUUID{}, ErrUnknownGate
+			}
+			return uuid.NullUUID{}, err
+		}
+		if len(assigned) > 0 && !containsGate(assigned, gateID) {
+			return uuid.NullUUID{}, ErrGateNotAssigned
+		}
+	}
+	if len(access.Restricted) > 0 {
+		if gateID == uuid.Nil {
+			return uuid.NullUUID{}, ErrGateRequired
+		}
+		if !containsGate(access.Restricted, gateID) {
+			return uuid.NullUUID{}, ErrGateNotAllowed
+		}
+	}
+	return uuid.NullUUID{UUID: gateID, Valid: gateID != uuid.Nil}, nil
+}
+
+func containsGate(gates []repo.Gate, id uuid.UUID) bool {
+	for _, gate := range gates {
+		if gate.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GateStats counts movements per gate, optionally within [from, to).
+// Closed gates are listed only when they saw traffic in the period.
+func (s *Service) GateStats(ctx context.Context, from, to time.Time) ([]repo.GateStatsRow, error) {
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+	return s.q.GateStats(ctx, repo.GateStatsParams{
+		FromTime: sql.NullTime{Time: from, Valid: !from.IsZero()},
+		ToTime:   sql.NullTime{Time: to, Valid: !to.IsZero()},
+	})
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_GateCRUD(t *testing.T) {
+	ctx := context.Background()
+	mainGate := repo.Gate{ID: uuid.New(), Name: "Главные ворота"}
+	serviceGate := repo.Gate{ID: uuid.New(), Name: "Хозяйственные ворота"}
+	var (
+		created     repo.CreateGateParams
+		deleted     bool
+		unassigned  bool
+		restriction int64
+	)
+	store := &mockStore{
+		getGateByNameFn: func(_ context.Context, name string) (repo.Gate, error) {
+			for _, gate := range []repo.Gate{mainGate, serviceGate} {
+				if gate.Name == name {
+					return gate, nil
+				}
+			}
+			return repo.Gate{}, sql.ErrNoRows
+		},
+		createGateFn: func(_ context.Context, arg repo.CreateGateParams) (repo.Gate, error) {
+			created = arg
+			return repo.Gate{ID: uuid.New(), Name: arg.Name, Description: arg.Description}, nil
+		},
+		updateGateFn: func(_ context.Context, arg repo.UpdateGateParams) (repo.Gate, error) {
+			if arg.ID != mainGate.ID {
+				return repo.Gate{}, sql.ErrNoRows
+			}
+			return repo.Gate{ID: arg.ID, Name: arg.Name}, nil
+		},
+		getGateFn: func(_ context.Context, id uuid.UUID) (repo.Gate, error) {
+			if id == mainGate.ID {
+				return mainGate, nil
+			}
+			return repo.Gate{}, sql.ErrNoRows
+		},
+		countGateRestrictionsFn: func(context.Context, uuid.UUID) (int64, error) {
+			return restriction, nil
+		},
+		softDeleteGateFn: func(_ context.Context, arg repo.SoftDeleteGateParams) (int64, error) {
+			if arg.ID != mainGate.ID {
+				return 0, nil
+			}
+			deleted = true
+			return 1, nil
+		},
+		deleteGateAssignmentsFn: func(context.Context, uuid.UUID) error {
+			unassigned = true
+			return nil
+		},
+		gateStatsFn: func(_ context.Context, arg repo.GateStatsParams) ([]repo.GateStatsRow, error) {
+			return []repo.GateStatsRow{{ID: mainGate.ID, Name: mainGate.Name, Entries: 3}}, nil
+		},
+	}
+	svc := New(store)
+	actor := uuid.New()
+
+	_, err := svc.CreateGate(ctx, GateInput{Name: "  "})
+	require.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.CreateGate(ctx, GateInput{Name: mainGate.Name})
+	require.ErrorIs(t, err, ErrGateExists)
+	gate, err := svc.CreateGate(ctx, GateInput{Name: " Калитка ", Description: " пешеходы ", ActorID: actor})
+	require.NoError(t, err)
+	require.Equal(t, "Калитка", gate.Name)
+	require.Equal(t, "пешеходы", created.Description.String)
+	require.Equal(t, actor, created.CreatedBy.UUID)
+
+	_, err = svc.UpdateGate(ctx, GateInput{ID: mainGate.ID, Name: serviceGate.Name})
+	require.ErrorIs(t, err, ErrGateExists)
+	updated, err := svc.UpdateGate(ctx, GateInput{ID: mainGate.ID, Name: mainGate.Name})
+	require.NoError(t, err)
+	require.Equal(t, mainGate.Name, updated.Name)
+	_, err = svc.UpdateGate(ctx, GateInput{ID: uuid.New(), Name: "Новые ворота"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.GetGate(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+
+	restriction = 2
+	require.ErrorIs(t, svc.DeleteGate(ctx, mainGate.ID, actor), ErrGateInUse)
+	require.False(t, deleted)
+	restriction = 0
+	require.ErrorIs(t, svc.DeleteGate(ctx, uuid.New(), actor), ErrNotFound)
+	require.NoError(t, svc.DeleteGate(ctx, mainGate.ID, actor))
+	require.True(t, deleted)
+	require.True(t, unassigned)
+
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err = svc.GateStats(ctx, from, from)
+	require.ErrorIs(t, err, ErrInvalidRange)
+	stats, err := svc.GateStats(ctx, from, from.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), stats[0].Entries)
+}
+
+func TestServiceUnit_GateAssignments(t *testing.T) {
+	ctx := context.Background()
+	gateA := repo.Gate{ID: uuid.New(), Name: "A"}
+	gateB := repo.Gate{ID: uuid.New(), Name: "B"}
+	guardID, residentID, passID := uuid.New(), uuid.New(), uuid.New()
+	links := map[string][]uuid.UUID{}
+	gatesOf := func(key string) []repo.Gate {
+		var out []repo.Gate
+		for _, id := range links[key] {
+			for _, gate := range []repo.Gate{gateA, gateB} {
+				if gate.ID == id {
+					out = append(out, gate)
+				}
+			}
+		}
+		return out
+	}
+	store := &mockStore{
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			switch id {
+			case guardID:
+				return repo.User{ID: id, Role: string(auth.RoleGuard)}, nil
+			case residentID:
+				return repo.User{ID: id, Role: string(auth.RoleResident)}, nil
+			}
+			return repo.User{}, sql.ErrNoRows
+		},
+		getPassByIDFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			if id != passID {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return repo.Pass{ID: id}, nil
+		},
+		getPassByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			if id != passID {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return repo.Pass{ID: id}, nil
+		},
+		getPassByIDAnyForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			if id != passID {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return repo.Pass{ID: id}, nil
+		},
+		getGateFn: func(_ context.Context, id uuid.UUID) (repo.Gate, error) {
+			for _, gate := range []repo.Gate{gateA, gateB} {
+				if gate.ID == id {
+					return gate, nil
+				}
+			}
+			return repo.Gate{}, sql.ErrNoRows
+		},
+		clearGuardGatesFn: func(context.Context, uuid.UUID) error {
+			links["guard"] = nil
+			return nil
+		},
+		addGuardGateFn: func(_ context.Context, arg repo.AddGuardGateParams) error {
+			links["guard"] = append(links["guard"], arg.GateID)
+			return nil
+		},
+		listGuardGatesFn: func(context.Context, uuid.UUID) ([]repo.Gate, error) {
+			return gatesOf("guard"), nil
+		},
+		clearPassGatesFn: func(context.Context, uuid.UUID) error {
+			links["pass"] = nil
+			return nil
+		},
+		addPassGateFn: func(_ context.Context, arg repo.AddPassGateParams) error {
+			links["pass"] = append(links["pass"], arg.GateID)
+			return nil
+		},
+		listPassGatesFn: func(context.Context, uuid.UUID) ([]repo.Gate, error) {
+			return gatesOf("pass"), nil
+		},
+		clearGuestTypeGatesFn: func(_ context.Context, guestType string) error {
+			links[guestType] = nil
+			return nil
+		},
+		addGuestTypeGateFn: func(_ context.Context, arg repo.AddGuestTypeGateParams) error {
+			links[arg.GuestType] = append(links[arg.GuestType], arg.GateID)
+			return nil
+		},
+		listGuestTypeGatesFn: func(_ context.Context, guestType string) ([]repo.Gate, error) {
+			return gatesOf(guestType), nil
+		},
+		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+			return repo.EntryLog{ID: arg.ID, PassID: arg.PassID, Action: arg.Action, GateID: arg.GateID}, nil
+		},
+	}
+	svc := New(store)
+
+	_, err := svc.SetGuardGates(ctx, residentID, []uuid.UUID{gateA.ID})
+	require.ErrorIs(t, err, ErrNotGuard)
+	_, err = svc.SetGuardGates(ctx, uuid.New(), nil)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.SetGuardGates(ctx, guardID, []uuid.UUID{uuid.New()})
+	require.ErrorIs(t, err, ErrUnknownGate)
+	_, err = svc.SetPassGates(ctx, uuid.New(), nil)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.SetGuestTypeGates(ctx, "truck", nil)
+	require.ErrorIs(t, err, ErrInvalidGuestType)
+
+	move := func(gateID uuid.UUID) (repo.EntryLog, error) {
+		return svc.RecordPassMovement(ctx, PassMovementInput{PassID: passID, GuardID: guardID, GateID: gateID, Action: EntryActionExit, OverrideReason: "test"})
+	}
+	entry, err := move(uuid.Nil)
+	require.NoError(t, err)
+	require.False(t, entry.GateID.Valid)
+	_, err = move(uuid.New())
+	require.ErrorIs(t, err, ErrUnknownGate)
+
+	gates, err := svc.SetGuardGates(ctx, guardID, []uuid.UUID{gateA.ID, gateA.ID})
+	require.NoError(t, err)
+	require.Equal(t, []repo.Gate{gateA}, gates)
+	entry, err = move(uuid.Nil)
+	require.NoError(t, err)
+	require.Equal(t, gateA.ID, entry.GateID.UUID)
+	_, err = move(gateB.ID)
+	require.ErrorIs(t, err, ErrGateNotAssigned)
+
+	_, err = svc.SetGuardGates(ctx, guardID, []uuid.UUID{gateA.ID, gateB.ID})
+	require.NoError(t, err)
+	gates, err = svc.SetPassGates(ctx, passID, []uuid.UUID{gateB.ID})
+	require.NoError(t, err)
+	require.Len(t, gates, 1)
+	_, err = move(uuid.Nil)
+	require.ErrorIs(t, err, ErrGateRequired)
+	_, err = move(gateA.ID)
+	require.ErrorIs(t, err, ErrGateNotAllowed)
+	entry, err = move(gateB.ID)
+	require.NoError(t, err)
+	require.Equal(t, gateB.ID, entry.GateID.UUID)
+
+	gates, err = svc.SetGuestTypeGates(ctx, GuestTypeDelivery, []uuid.UUID{gateB.ID})
+	require.NoError(t, err)
+	require.Equal(t, []repo.Gate{gateB}, gates)
+	restricted, err := resolveGate(ctx, store, gateAccess{GateID: gateA.ID, GuardID: guardID, Restricted: gates})
+	require.ErrorIs(t, err, ErrGateNotAllowed)
+	require.False(t, restricted.Valid)
+}
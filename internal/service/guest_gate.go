@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	// DefaultExpectedWindow is how far ahead guards see expected guests and
+	// how early a guest found by plate search may arrive.
+	DefaultExpectedWindow = 3 * time.Hour
+	MaxExpectedWindow     = 24 * time.Hour
+)
+
+var (
+	ErrOutsideGuestWindow = errors.New("guest request is not valid at this time")
+	ErrResidentInactive   = errors.New("resident is blocked or deleted")
+)
+
+// ExpectedGuests lists approved and arrived guest requests whose window
+// covers now or starts within ahead, optionally of one guest type.
+// Occurrences of recurring series in that window are materialized first.
+func (s *Service) ExpectedGuests(ctx context.Context, ahead time.Duration, guestType string, limit, offset int32) ([]repo.ListGateGuestsRow, error) {
+	if ahead <= 0 || ahead > MaxExpectedWindow {
+		return nil, ErrInvalidRange
+	}
+	typeFilter, err := guestTypeFilter(guestType)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if err := s.materializeGuestSeries(ctx, now, now.Add(ahead)); err != nil {
+		return nil, err
+	}
+	return s.q.ListGateGuests(ctx, repo.ListGateGuestsParams{
+		WindowStart: now,
+		WindowEnd:   now.Add(ahead),
+		GuestType:   typeFilter,
+		PageSize:    limit,
+		PageOffset:  offset,
+	})
+}
+
+// SearchGuestsByPlate finds approved and arrived guest requests for plate
+// that are active now or start within DefaultExpectedWindow.
+func (s *Service) SearchGuestsByPlate(ctx context.Context, plate, guestType string, limit, offset int32) ([]repo.ListGateGuestsRow, error) {
+	if err := ValidatePlate(plate); err != nil {
+		return nil, err
+	}
+	typeFilter, err := guestTypeFilter(guestType)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if err := s.materializeGuestSeries(ctx, now, now.Add(DefaultExpectedWindow)); err != nil {
+		return nil, err
+	}
+	return s.q.ListGateGuests(ctx, repo.ListGateGuestsParams{
+		WindowStart:  now,
+		WindowEnd:    now.Add(DefaultExpectedWindow),
+		PlatePattern: sql.NullString{String: "%" + NormalizePlate(plate) + "%", Valid: true},
+		GuestType:    typeFilter,
+		PageSize:     limit,
+		PageOffset:   offset,
+	})
+}
+
+func guestTypeFilter(guestType string) (sql.NullString, error) {
+	if guestType == "" {
+		return sql.NullString{}, nil
+	}
+	if err := ValidateGuestType(guestType); err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: guestType, Valid: true}, nil
+}
+
+// GuestVisitInput is a guest passing a gate. GateID may be uuid.Nil when
+// the guard works at a single gate or the site has none.
+type GuestVisitInput struct {
+	GuestID uuid.UUID
+	GuardID uuid.UUID
+	GateID  uuid.UUID
+	Comment sql.NullString
+	// Photo is an optional JPEG or PNG of the vehicle.
+	Photo []byte
+	// Watchlist is the hit CheckGate returned for the guest's vehicle; it is
+	// logged in the same transaction as the visit.
+	Watchlist *WatchlistHit
+	// ID and DeviceAt are set for visits logged on a guard device while it
+	// was offline; the window is then checked against the device time.
+	ID       uuid.UUID
+	DeviceAt time.Time
+}
+
+// CheckInGuest logs the guest's entry and marks an approved request as
+// arrived. The guest is only let in inside the requested window and while
+// the inviting resident is neither blocked nor deleted.
+func (s *Service) CheckInGuest(ctx context.Context, input GuestVisitInput) (repo.EntryLog, error) {
+	guest, err := s.GetGuestRequest(ctx, input.GuestID)
+	if err != nil {
+		return repo.EntryLog{}, err
+	}
+	if !CanTransitionGuest(guest.Status, GuestStatusArrived) {
+		return repo.EntryLog{}, ErrGuestTransition
+	}
+	at := s.now()
+	if !input.DeviceAt.IsZero() {
+		at = input.DeviceAt
+	}
+	if at.Before(guest.ValidFrom) || !at.Before(guest.ValidTo) {
+		return repo.EntryLog{}, ErrOutsideGuestWindow
+	}
+	return s.recordGuestVisit(ctx, guest, input, GuestStatusArrived, EntryActionEntry)
+}
+
+// CheckOutGuest logs the guest's exit and completes the request. Leaving
+// after the window has ended is allowed.
+func (s *Service) CheckOutGuest(ctx context.Context, input GuestVisitInput) (repo.EntryLog, error) {
+	guest, err := s.GetGuestRequest(ctx, input.GuestID)
+	if err != nil {
+		return repo.EntryLog{}, err
+	}
+	if !CanTransitionGuest(guest.Status, GuestStatusCompleted) {
+		return repo.EntryLog{}, ErrGuestTransition
+	}
+	return s.recordGuestVisit(ctx, guest, input, GuestStatusCompleted, EntryActionExit)
+}
+
+func (s *Service) recordGuestVisit(ctx context.Context, guest repo.GuestRequest, input GuestVisitInput, status, action string) (repo.EntryLog, error) {
+	guardID := input.GuardID
+	photo, err := s.storePhoto(ctx, guardID, input.Photo)
+	if err != nil {
+		return repo.EntryLog{}, err
+	}
+	var entry repo.EntryLog
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		shift, err := s.openShift(ctx, q, guardID)
+		if err != nil {
+			return err
+		}
+		restricted, err := q.ListGuestTypeGates(ctx, guest.GuestType)
+		if err != nil {
+			return err
+		}
+		gateID, err := resolveGate(ctx, q, gateAccess{GateID: input.GateID, GuardID: guardID, ShiftGate: shift.GateID, Restricted: restricted})
+		if err != nil {
+			return err
+		}
+		if status == GuestStatusArrived {
+			if err := checkGuestResident(ctx, q, guest); err != nil {
+				return err
+			}
+		}
+		_, err = q.SetGuestRequestStatus(ctx, repo.SetGuestRequestStatusParams{
+			Status:     status,
+			UpdatedBy:  uuid.NullUUID{UUID: guardID, Valid: guardID != uuid.Nil},
+			ID:         guest.ID,
+			FromStatus: guest.Status,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGuestTransition
+		}
+		if err != nil {
+			return err
+		}
+		// A guest PIN only lets the guest in once, whichever way they arrive.
+		if status == GuestStatusArrived {
+			if err := q.UseGuestPin(ctx, guest.ID); err != nil {
+				return err
+			}
+		}
+		guestID := uuid.NullUUID{UUID: guest.ID, Valid: true}
+		entry, err = s.appendEntryLog(ctx, q, repo.CreateEntryLogParams{
+			ID:             input.ID,
+			GuestRequestID: guestID,
+			GuardUserID:    guardID,
+			Action:         action,
+			Comment:        input.Comment,
+			GateID:         gateID,
+			ShiftID:        uuid.NullUUID{UUID: shift.ID, Valid: shift.ID != uuid.Nil},
+			DeviceAt:       sql.NullTime{Time: input.DeviceAt, Valid: !input.DeviceAt.IsZero()},
+		})
+		if err != nil {
+			return err
+		}
+		if err := savePhoto(ctx, q, photo, entry.ID); err != nil {
+			return err
+		}
+		if err := recordWatchlistHit(ctx, q, guestHit(input.Watchlist, guest.ID)); err != nil {
+			return err
+		}
+		if status == GuestStatusArrived {
+			return q.UpsertGuestPresence(ctx, repo.UpsertGuestPresenceParams{GuestRequestID: guestID, EntryLogID: entry.ID, EnteredAt: movedAt(entry)})
+		}
+		_, err = q.DeleteGuestPresence(ctx, guestID)
+		return err
+	})
+	if err != nil {
+		s.dropPhoto(ctx, photo)
+	}
+	return entry, err
+}
+
+// guestHit ties a watchlist hit on the guest's vehicle to the request.
+func guestHit(hit *WatchlistHit, guestID uuid.UUID) *WatchlistHit {
+	if hit == nil {
+		return nil
+	}
+	out := *hit
+	out.Check.GuestID = guestID
+	return &out
+}
+
+// checkGuestResident refuses guests of a resident blocked or deleted after
+// the request was approved. Leaving is never refused.
+func checkGuestResident(ctx context.Context, q ServiceStore, guest repo.GuestRequest) error {
+	resident, err := q.GetUserByID(ctx, guest.ResidentUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrResidentInactive
+	}
+	if err != nil {
+		return err
+	}
+	if resident.BlockedAt.Valid {
+		return ErrResidentInactive
+	}
+	return nil
+}
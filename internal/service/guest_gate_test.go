@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_GateGuests(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	var got repo.ListGateGuestsParams
+	svc := New(&mockStore{
+		listGateGuestsFn: func(_ context.Context, arg repo.ListGateGuestsParams) ([]repo.ListGateGuestsRow, error) {
+			got = arg
+			return []repo.ListGateGuestsRow{{PlateNumber: "A123BC77"}}, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	rows, err := svc.ExpectedGuests(ctx, 2*time.Hour, "", 20, 5)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, now, got.WindowStart)
+	require.Equal(t, now.Add(2*time.Hour), got.WindowEnd)
+	require.False(t, got.PlatePattern.Valid)
+	require.EqualValues(t, 20, got.PageSize)
+	require.EqualValues(t, 5, got.PageOffset)
+
+	_, err = svc.ExpectedGuests(ctx, 0, "", 20, 0)
+	require.ErrorIs(t, err, ErrInvalidRange)
+	_, err = svc.ExpectedGuests(ctx, 25*time.Hour, "", 20, 0)
+	require.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = svc.SearchGuestsByPlate(ctx, "a123bc77", "", 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, "%A123BC77%", got.PlatePattern.String)
+	require.Equal(t, now.Add(DefaultExpectedWindow), got.WindowEnd)
+
+	_, err = svc.SearchGuestsByPlate(ctx, "bad", "", 10, 0)
+	require.ErrorIs(t, err, ErrInvalidPlate)
+}
+
+func TestServiceUnit_GuestCheckInOut(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	guardID := uuid.New()
+	residentID, blockedID := uuid.New(), uuid.New()
+	guests := map[uuid.UUID]repo.GuestRequest{}
+	add := func(status string, from, to time.Time) uuid.UUID {
+		id := uuid.New()
+		guests[id] = repo.GuestRequest{ID: id, ResidentUserID: residentID, Status: status, ValidFrom: from, ValidTo: to}
+		return id
+	}
+	current := add(GuestStatusApproved, now.Add(-time.Hour), now.Add(time.Hour))
+	early := add(GuestStatusApproved, now.Add(time.Hour), now.Add(2*time.Hour))
+	ended := add(GuestStatusApproved, now.Add(-2*time.Hour), now)
+	pending := add(GuestStatusPending, now.Add(-time.Hour), now.Add(time.Hour))
+	arrived := add(GuestStatusArrived, now.Add(-3*time.Hour), now.Add(-time.Hour))
+	raced := add(GuestStatusApproved, now.Add(-time.Hour), now.Add(time.Hour))
+	ofBlocked := add(GuestStatusApproved, now.Add(-time.Hour), now.Add(time.Hour))
+	ofDeleted := add(GuestStatusApproved, now.Add(-time.Hour), now.Add(time.Hour))
+	blockedGuest, deletedGuest := guests[ofBlocked], guests[ofDeleted]
+	blockedGuest.ResidentUserID, deletedGuest.ResidentUserID = blockedID, uuid.New()
+	guests[ofBlocked], guests[ofDeleted] = blockedGuest, deletedGuest
+	var transitions []repo.SetGuestRequestStatusParams
+	var logs []repo.CreateEntryLogParams
+	var usedPins []uuid.UUID
+	var hits []repo.CreateWatchlistHitParams
+	onSite := map[uuid.UUID]bool{}
+	svc := New(&mockStore{
+		getGuestRequestByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+			guest, ok := guests[id]
+			if !ok {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			return guest, nil
+		},
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			switch id {
+			case residentID:
+				return repo.User{ID: id}, nil
+			case blockedID:
+				return repo.User{ID: id, BlockedAt: sql.NullTime{Time: now, Valid: true}}, nil
+			}
+			return repo.User{}, sql.ErrNoRows
+		},
+		setGuestRequestStatusFn: func(_ context.Context, arg repo.SetGuestRequestStatusParams) (repo.GuestRequest, error) {
+			if arg.ID == raced {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			transitions = append(transitions, arg)
+			return repo.GuestRequest{ID: arg.ID, Status: arg.Status}, nil
+		},
+		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+			logs = append(logs, arg)
+			return repo.EntryLog{ID: uuid.New(), GuestRequestID: arg.GuestRequestID, Action: arg.Action}, nil
+		},
+		createWatchlistHitFn: func(_ context.Context, arg repo.CreateWatchlistHitParams) (repo.WatchlistHit, error) {
+			hits = append(hits, arg)
+			return repo.WatchlistHit{}, nil
+		},
+		useGuestPinFn: func(_ context.Context, id uuid.UUID) error {
+			usedPins = append(usedPins, id)
+			return nil
+		},
+		upsertGuestPresenceFn: func(_ context.Context, arg repo.UpsertGuestPresenceParams) error {
+			onSite[arg.GuestRequestID.UUID] = true
+			return nil
+		},
+		deleteGuestPresenceFn: func(_ context.Context, id uuid.NullUUID) (int64, error) {
+			delete(onSite, id.UUID)
+			return 0, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	// The hit on the guest's car is logged against the request.
+	hit := &WatchlistHit{Entry: repo.PlateWatchlist{ID: uuid.New(), PlateNumber: "A123BC77"}, Check: GateCheckInput{UserID: guardID, Source: WatchlistSourceEntry}, Outcome: WatchlistFlagged}
+	entry, err := svc.CheckInGuest(ctx, GuestVisitInput{GuestID: current, GuardID: guardID, Comment: sql.NullString{String: "gate 1", Valid: true}, Watchlist: hit})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, uuid.NullUUID{UUID: current, Valid: true}, hits[0].GuestRequestID)
+	require.False(t, hits[0].PassID.Valid)
+	require.Equal(t, current, entry.GuestRequestID.UUID)
+	require.Equal(t, GuestStatusArrived, transitions[0].Status)
+	require.Equal(t, GuestStatusApproved, transitions[0].FromStatus)
+	require.Equal(t, guardID, transitions[0].UpdatedBy.UUID)
+	require.False(t, logs[0].PassID.Valid)
+	require.Equal(t, "entry", logs[0].Action)
+	require.Equal(t, "gate 1", logs[0].Comment.String)
+	require.Equal(t, []uuid.UUID{current}, usedPins)
+	require.True(t, onSite[current])
+	onSite[arrived] = true
+
+	entry, err = svc.CheckOutGuest(ctx, GuestVisitInput{GuestID: arrived, GuardID: guardID})
+	require.NoError(t, err)
+	require.Equal(t, "exit", entry.Action)
+	require.Equal(t, GuestStatusCompleted, transitions[1].Status)
+	require.Equal(t, GuestStatusArrived, transitions[1].FromStatus)
+	require.False(t, onSite[arrived])
+
+	_, err = svc.CheckInGuest(ctx, GuestVisitInput{GuestID: early, GuardID: guardID})
+	require.ErrorIs(t, err, ErrOutsideGuestWindow)
+	_, err = svc.CheckInGuest(ctx, GuestVisitInput{GuestID: ended, GuardID: guardID})
+	require.ErrorIs(t, err, ErrOutsideGuestWindow)
+	_, err = svc.CheckInGuest(ctx, GuestVisitInput{GuestID: pending, GuardID: guardID})
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.CheckInGuest(ctx, GuestVisitInput{GuestID: raced, GuardID: guardID})
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.CheckInGuest(ctx, GuestVisitInput{GuestID: ofBlocked, GuardID: guardID})
+	require.ErrorIs(t, err, ErrResidentInactive)
+	_, err = svc.CheckInGuest(ctx, GuestVisitInput{GuestID: ofDeleted, GuardID: guardID})
+	require.ErrorIs(t, err, ErrResidentInactive)
+	_, err = svc.CheckInGuest(ctx, GuestVisitInput{GuestID: uuid.New(), GuardID: guardID})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.CheckOutGuest(ctx, GuestVisitInput{GuestID: current, GuardID: guardID})
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.CheckOutGuest(ctx, GuestVisitInput{GuestID: uuid.New(), GuardID: guardID})
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Len(t, logs, 2)
+	require.Len(t, usedPins, 1)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	GuestPinDigits = 6
+
+	// A guard who enters guestPinMaxFailures wrong PINs within
+	// guestPinFailureWindow is locked out until the oldest failure ages out.
+	guestPinMaxFailures   = 5
+	guestPinFailureWindow = 15 * time.Minute
+	guestPinIssueAttempts = 5
+)
+
+var (
+	ErrGuestPinUnavailable = errors.New("guest PIN is only available for approved requests that have not ended")
+	ErrInvalidGuestPin     = errors.New("invalid or expired guest PIN")
+	ErrGuestPinAttempts    = errors.New("too many wrong guest PINs, try again later")
+)
+
+// GuestPin returns the PIN the resident forwards to the guest, issuing one
+// the first time it is asked for.
+func (s *Service) GuestPin(ctx context.Context, guest repo.GuestRequest, actor uuid.UUID) (repo.GuestPin, error) {
+	if err := s.checkPinAvailable(guest); err != nil {
+		return repo.GuestPin{}, err
+	}
+	pin, err := s.q.GetGuestPin(ctx, guest.ID)
+	if err == nil && !pin.UsedAt.Valid {
+		return pin, nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return repo.GuestPin{}, err
+	}
+	return s.issueGuestPin(ctx, guest.ID, actor)
+}
+
+// RegenerateGuestPin replaces the PIN of guest, e.g. when it was sent to the
+// wrong person; the old PIN stops working at once.
+func (s *Service) RegenerateGuestPin(ctx context.Context, guest repo.GuestRequest, actor uuid.UUID) (repo.GuestPin, error) {
+	if err := s.checkPinAvailable(guest); err != nil {
+		return repo.GuestPin{}, err
+	}
+	return s.issueGuestPin(ctx, guest.ID, actor)
+}
+
+func (s *Service) checkPinAvailable(guest repo.GuestRequest) error {
+	if guest.Status != GuestStatusApproved || !guest.ValidTo.After(s.now()) {
+		return ErrGuestPinUnavailable
+	}
+	return nil
+}
+
+func (s *Service) issueGuestPin(ctx context.Context, guestID, actor uuid.UUID) (repo.GuestPin, error) {
+	var pin repo.GuestPin
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if err := q.DeleteGuestPin(ctx, guestID); err != nil {
+			return err
+		}
+		for i := 0; i < guestPinIssueAttempts; i++ {
+			code, err := newGuestPinCode()
+			if err != nil {
+				return err
+			}
+			// Codes of used and expired PINs may be handed out again.
+			if err := q.ReleaseGuestPinCode(ctx, repo.ReleaseGuestPinCodeParams{Code: code, Now: s.now()}); err != nil {
+				return err
+			}
+			pin, err = q.CreateGuestPin(ctx, repo.CreateGuestPinParams{
+				GuestRequestID: guestID,
+				Code:           code,
+				CreatedBy:      uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil},
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				// The code belongs to another live PIN.
+				continue
+			}
+			return err
+		}
+		return errors.New("could not find a free guest PIN")
+	})
+	return pin, err
+}
+
+func newGuestPinCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < GuestPinDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", GuestPinDigits, n), nil
+}
+
+// GuestByPin finds the approved request a live PIN belongs to. Wrong PINs
+// count against the guard, see guestPinMaxFailures.
+func (s *Service) GuestByPin(ctx context.Context, code string, guardID uuid.UUID) (repo.GetGuestByPinRow, error) {
+	now := s.now()
+	if s.pins.locked(guardID, now) {
+		return repo.GetGuestByPinRow{}, ErrGuestPinAttempts
+	}
+	if !validGuestPin(code) {
+		s.pins.fail(guardID, now)
+		return repo.GetGuestByPinRow{}, ErrInvalidGuestPin
+	}
+	guest, err := s.q.GetGuestByPin(ctx, repo.GetGuestByPinParams{Code: code, Now: now})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.pins.fail(guardID, now)
+		return repo.GetGuestByPinRow{}, ErrInvalidGuestPin
+	}
+	return guest, err
+}
+
+func validGuestPin(code string) bool {
+	if len(code) != GuestPinDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pinAttempts remembers recent wrong PINs per guard. It lives in the API
+// process, so every replica counts on its own.
+type pinAttempts struct {
+	mu       sync.Mutex
+	failures map[uuid.UUID][]time.Time
+}
+
+func newPinAttempts() *pinAttempts {
+	return &pinAttempts{failures: make(map[uuid.UUID][]time.Time)}
+}
+
+func (p *pinAttempts) locked(guardID uuid.UUID, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.recent(guardID, now)) >= guestPinMaxFailures
+}
+
+func (p *pinAttempts) fail(guardID uuid.UUID, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures[guardID] = append(p.recent(guardID, now), now)
+}
+
+func (p *pinAttempts) recent(guardID uuid.UUID, now time.Time) []time.Time {
+	failures := p.failures[guardID]
+	cutoff := now.Add(-guestPinFailureWindow)
+	for len(failures) > 0 && !failures[0].After(cutoff) {
+		failures = failures[1:]
+	}
+	if len(failures) == 0 {
+		delete(p.failures, guardID)
+		return nil
+	}
+	p.failures[guardID] = failures
+	return failures
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_GuestPinIssue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	actor := uuid.New()
+	guest := repo.GuestRequest{ID: uuid.New(), Status: GuestStatusApproved, ValidFrom: now, ValidTo: now.Add(time.Hour)}
+	pins := map[uuid.UUID]repo.GuestPin{}
+	taken := map[string]bool{}
+	var released []string
+	store := &mockStore{
+		getGuestPinFn: func(_ context.Context, id uuid.UUID) (repo.GuestPin, error) {
+			pin, ok := pins[id]
+			if !ok {
+				return repo.GuestPin{}, sql.ErrNoRows
+			}
+			return pin, nil
+		},
+		deleteGuestPinFn: func(_ context.Context, id uuid.UUID) error {
+			delete(pins, id)
+			return nil
+		},
+		releaseGuestPinCodeFn: func(_ context.Context, arg repo.ReleaseGuestPinCodeParams) error {
+			require.Equal(t, now, arg.Now)
+			released = append(released, arg.Code)
+			return nil
+		},
+		createGuestPinFn: func(_ context.Context, arg repo.CreateGuestPinParams) (repo.GuestPin, error) {
+			// The first code drawn collides with a live PIN.
+			if len(taken) == 0 {
+				taken[arg.Code] = true
+				return repo.GuestPin{}, sql.ErrNoRows
+			}
+			pin := repo.GuestPin{GuestRequestID: arg.GuestRequestID, Code: arg.Code, CreatedBy: arg.CreatedBy}
+			pins[arg.GuestRequestID] = pin
+			return pin, nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+
+	pin, err := svc.GuestPin(ctx, guest, actor)
+	require.NoError(t, err)
+	require.Len(t, pin.Code, GuestPinDigits)
+	require.True(t, validGuestPin(pin.Code))
+	require.Equal(t, actor, pin.CreatedBy.UUID)
+	require.Len(t, released, 2)
+
+	again, err := svc.GuestPin(ctx, guest, actor)
+	require.NoError(t, err)
+	require.Equal(t, pin.Code, again.Code)
+	require.Len(t, released, 2)
+
+	regenerated, err := svc.RegenerateGuestPin(ctx, guest, actor)
+	require.NoError(t, err)
+	require.Len(t, released, 3)
+	require.Equal(t, regenerated, pins[guest.ID])
+
+	// A used PIN is replaced when asked for again.
+	used := pins[guest.ID]
+	used.UsedAt = sql.NullTime{Time: now, Valid: true}
+	pins[guest.ID] = used
+	_, err = svc.GuestPin(ctx, guest, actor)
+	require.NoError(t, err)
+	require.False(t, pins[guest.ID].UsedAt.Valid)
+
+	pending := guest
+	pending.Status = GuestStatusPending
+	_, err = svc.GuestPin(ctx, pending, actor)
+	require.ErrorIs(t, err, ErrGuestPinUnavailable)
+	ended := guest
+	ended.ValidTo = now
+	_, err = svc.RegenerateGuestPin(ctx, ended, actor)
+	require.ErrorIs(t, err, ErrGuestPinUnavailable)
+
+	store.createGuestPinFn = func(context.Context, repo.CreateGuestPinParams) (repo.GuestPin, error) {
+		return repo.GuestPin{}, sql.ErrNoRows
+	}
+	_, err = svc.RegenerateGuestPin(ctx, guest, actor)
+	require.Error(t, err)
+	store.getGuestPinFn = func(context.Context, uuid.UUID) (repo.GuestPin, error) {
+		return repo.GuestPin{}, sql.ErrConnDone
+	}
+	_, err = svc.GuestPin(ctx, guest, actor)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestServiceUnit_GuestPinVerify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	guard := uuid.New()
+	guestID := uuid.New()
+	var lookups int
+	svc := New(&mockStore{
+		getGuestByPinFn: func(_ context.Context, arg repo.GetGuestByPinParams) (repo.GetGuestByPinRow, error) {
+			lookups++
+			require.Equal(t, now, arg.Now)
+			if arg.Code != "042917" {
+				return repo.GetGuestByPinRow{}, sql.ErrNoRows
+			}
+			return repo.GetGuestByPinRow{ID: guestID}, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	guest, err := svc.GuestByPin(ctx, "042917", guard)
+	require.NoError(t, err)
+	require.Equal(t, guestID, guest.ID)
+
+	for _, code := range []string{"000000", "12345", "abcdef", "111111"} {
+		_, err = svc.GuestByPin(ctx, code, guard)
+		require.ErrorIs(t, err, ErrInvalidGuestPin)
+	}
+	require.Equal(t, 3, lookups)
+	_, err = svc.GuestByPin(ctx, "222222", guard)
+	require.ErrorIs(t, err, ErrInvalidGuestPin)
+
+	// Five failures lock the guard out, even for the right PIN.
+	_, err = svc.GuestByPin(ctx, "042917", guard)
+	require.ErrorIs(t, err, ErrGuestPinAttempts)
+	require.Equal(t, 4, lookups)
+	_, err = svc.GuestByPin(ctx, "042917", uuid.New())
+	require.NoError(t, err)
+
+	now = now.Add(guestPinFailureWindow)
+	_, err = svc.GuestByPin(ctx, "042917", guard)
+	require.NoError(t, err)
+	require.Empty(t, svc.pins.failures)
+}
@@ -0,0 +1,554 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	// DefaultOccurrenceDays is how far ahead occurrences are listed when no
+	// range is given; MaxOccurrenceDays caps a single listing.
+	DefaultOccurrenceDays = 28
+	MaxOccurrenceDays     = 92
+)
+
+// GuestSeriesInput describes a recurring visit. The recurrence is either an
+// RRULE or Weekdays with an Until day; days are YYYY-MM-DD and Start/End are
+// HH:MM in the site time zone, an End not after Start ends the next day.
+type GuestSeriesInput struct {
+	// ID is the series being edited; ignored on create.
+	ID          uuid.UUID
+	ResidentID  uuid.UUID
+	GuestName   string
+	PlateNumber string
+	RRule       string
+	Weekdays    []int
+	Until       string
+	StartsOn    string
+	Start       string
+	End         string
+	// Status as in GuestCreateInput: empty sends the series through review.
+	Status  string
+	ActorID uuid.UUID
+}
+
+// GuestOccurrenceInput edits a single materialized occurrence.
+type GuestOccurrenceInput struct {
+	ID          uuid.UUID
+	GuestName   string
+	PlateNumber string
+	ValidFrom   time.Time
+	ValidTo     time.Time
+	// Status as in GuestCreateInput: empty sends the edited visit through
+	// review again.
+	Status  string
+	ActorID uuid.UUID
+}
+
+// GuestOccurrence is one visit of a series. Request is set once the visit
+// has its own guest request, which happens when guards look at it or the
+// resident skips or edits it.
+type GuestOccurrence struct {
+	Day         time.Time
+	ValidFrom   time.Time
+	ValidTo     time.Time
+	GuestName   string
+	PlateNumber string
+	Status      string
+	Request     *repo.GuestRequest
+}
+
+type guestSeriesSpec struct {
+	rule        Recurrence
+	startsOn    time.Time
+	endsOn      sql.NullTime
+	startMinute int16
+	endMinute   int16
+}
+
+func parseGuestSeries(input GuestSeriesInput) (guestSeriesSpec, error) {
+	if err := ValidatePlate(input.PlateNumber); err != nil {
+		return guestSeriesSpec{}, err
+	}
+	if strings.TrimSpace(input.GuestName) == "" {
+		return guestSeriesSpec{}, ErrInvalidInput
+	}
+	startsOn, err := ParseDay(input.StartsOn)
+	if err != nil {
+		return guestSeriesSpec{}, err
+	}
+	var rule Recurrence
+	switch {
+	case input.RRule != "" && len(input.Weekdays) == 0 && input.Until == "":
+		rule, err = ParseRRule(input.RRule)
+	case input.RRule == "" && len(input.Weekdays) > 0:
+		var until time.Time
+		if until, err = ParseDay(input.Until); err != nil {
+			return guestSeriesSpec{}, ErrInvalidRecurrence
+		}
+		rule, err = WeeklyRecurrence(input.Weekdays, until)
+	default:
+		err = ErrInvalidRecurrence
+	}
+	if err != nil {
+		return guestSeriesSpec{}, err
+	}
+	spec := guestSeriesSpec{rule: rule, startsOn: startsOn}
+	if spec.startMinute, err = ParseClock(input.Start, false); err != nil {
+		return guestSeriesSpec{}, err
+	}
+	if spec.endMinute, err = ParseClock(input.End, true); err != nil {
+		return guestSeriesSpec{}, err
+	}
+	if spec.startMinute == spec.endMinute {
+		return guestSeriesSpec{}, ErrInvalidSchedule
+	}
+	if last, ok := rule.LastDay(startsOn); ok {
+		if last.Before(startsOn) || len(rule.Occurrences(startsOn, startsOn, last)) == 0 {
+			return guestSeriesSpec{}, ErrInvalidRecurrence
+		}
+		spec.endsOn = sql.NullTime{Time: last, Valid: true}
+	}
+	return spec, nil
+}
+
+// visit is the length of a single occurrence.
+func (spec guestSeriesSpec) visit() time.Duration {
+	minutes := int(spec.endMinute) - int(spec.startMinute)
+	if minutes <= 0 {
+		minutes += minutesPerDay
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func (s *Service) today() time.Time {
+	return dateOf(s.now().In(s.settings.Location))
+}
+
+// occurrenceWindow turns an occurrence day into the visit window in the site
+// time zone.
+func (s *Service) occurrenceWindow(series repo.GuestSeries, day time.Time) (time.Time, time.Time) {
+	loc := s.settings.Location
+	from := time.Date(day.Year(), day.Month(), day.Day(), int(series.StartMinute)/60, int(series.StartMinute)%60, 0, 0, loc)
+	end := day
+	if series.EndMinute <= series.StartMinute {
+		end = day.AddDate(0, 0, 1)
+	}
+	to := time.Date(end.Year(), end.Month(), end.Day(), int(series.EndMinute)/60, int(series.EndMinute)%60, 0, 0, loc)
+	return from, to
+}
+
+// seriesDays expands the series between first and last, honouring the end
+// set when the series was split by an edit.
+func seriesDays(series repo.GuestSeries, first, last time.Time) ([]time.Time, error) {
+	rule, err := ParseRRule(series.Rrule)
+	if err != nil {
+		return nil, err
+	}
+	if series.EndsOn.Valid && series.EndsOn.Time.Before(last) {
+		last = dateOf(series.EndsOn.Time)
+	}
+	return rule.Occurrences(dateOf(series.StartsOn), first, last), nil
+}
+
+func (s *Service) occurrenceParams(series repo.GuestSeries, day time.Time) repo.MaterializeGuestOccurrenceParams {
+	validFrom, validTo := s.occurrenceWindow(series, day)
+	return repo.MaterializeGuestOccurrenceParams{
+		ResidentUserID: series.ResidentUserID,
+		GuestFullName:  series.GuestFullName,
+		PlateNumber:    series.PlateNumber,
+		ValidFrom:      validFrom,
+		ValidTo:        validTo,
+		Status:         series.Status,
+		CreatedBy:      series.CreatedBy,
+		UpdatedBy:      series.CreatedBy,
+		ReviewedBy:     series.ReviewedBy,
+		ReviewedAt:     series.ReviewedAt,
+		ReviewReason:   series.ReviewReason,
+		SeriesID:       uuid.NullUUID{UUID: series.ID, Valid: true},
+		OccurrenceDate: sql.NullTime{Time: day, Valid: true},
+	}
+}
+
+// materializeGuestSeries creates guest requests for occurrences of approved
+// series that overlap [from, to], so guard views and check-in keep working
+// on plain guest requests. Occurrences that already exist, including skipped
+// and edited ones, are left as they are.
+func (s *Service) materializeGuestSeries(ctx context.Context, from, to time.Time) error {
+	loc := s.settings.Location
+	// An overnight visit that started yesterday may still be running.
+	first := dateOf(from.In(loc)).AddDate(0, 0, -1)
+	last := dateOf(to.In(loc))
+	series, err := s.q.ListActiveGuestSeries(ctx, repo.ListActiveGuestSeriesParams{LastDay: last, FirstDay: first})
+	if err != nil {
+		return err
+	}
+	for _, item := range series {
+		days, err := seriesDays(item, first, last)
+		if err != nil {
+			return err
+		}
+		for _, day := range days {
+			params := s.occurrenceParams(item, day)
+			if !params.ValidTo.After(from) || params.ValidFrom.After(to) {
+				continue
+			}
+			if err := s.q.MaterializeGuestOccurrence(ctx, params); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) CreateGuestSeries(ctx context.Context, input GuestSeriesInput) (repo.GuestSeries, error) {
+	spec, err := parseGuestSeries(input)
+	if err != nil {
+		return repo.GuestSeries{}, err
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	review, err := s.initialReview(input.Status, spec.visit(), actor)
+	if err != nil {
+		return repo.GuestSeries{}, err
+	}
+	return s.q.CreateGuestSeries(ctx, seriesParams(input.ResidentID, input, spec, review, actor, uuid.NullUUID{}))
+}
+
+func seriesParams(resident uuid.UUID, input GuestSeriesInput, spec guestSeriesSpec, review guestReview, actor, previous uuid.NullUUID) repo.CreateGuestSeriesParams {
+	return repo.CreateGuestSeriesParams{
+		ResidentUserID:   resident,
+		GuestFullName:    strings.TrimSpace(input.GuestName),
+		PlateNumber:      NormalizePlate(input.PlateNumber),
+		Rrule:            spec.rule.String(),
+		StartsOn:         spec.startsOn,
+		EndsOn:           spec.endsOn,
+		StartMinute:      spec.startMinute,
+		EndMinute:        spec.endMinute,
+		Status:           review.Status,
+		PreviousSeriesID: previous,
+		CreatedBy:        actor,
+		UpdatedBy:        actor,
+		ReviewedBy:       review.ReviewedBy,
+		ReviewedAt:       review.ReviewedAt,
+		ReviewReason:     review.ReviewReason,
+	}
+}
+
+func (s *Service) GetGuestSeries(ctx context.Context, id uuid.UUID) (repo.GuestSeries, error) {
+	series, err := s.q.GetGuestSeriesByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.GuestSeries{}, ErrNotFound
+	}
+	return series, err
+}
+
+func (s *Service) ListGuestSeries(ctx context.Context, limit, offset int32) ([]repo.GuestSeries, error) {
+	return s.q.ListGuestSeries(ctx, repo.ListGuestSeriesParams{Limit: limit, Offset: offset})
+}
+
+func (s *Service) ListGuestSeriesByResident(ctx context.Context, resident uuid.UUID, limit, offset int32) ([]repo.GuestSeries, error) {
+	return s.q.ListGuestSeriesByResident(ctx, repo.ListGuestSeriesByResidentParams{ResidentUserID: resident, Limit: limit, Offset: offset})
+}
+
+// UpdateGuestSeries edits a series. A series that has not started yet, or is
+// still pending, is changed in place. Otherwise the current version is ended
+// yesterday and a new version starting today replaces it, so past visits and
+// their entry logs stay with the rules they happened under. Upcoming
+// occurrences that were not used yet are regenerated from the new rules.
+func (s *Service) UpdateGuestSeries(ctx context.Context, input GuestSeriesInput) (repo.GuestSeries, error) {
+	current, err := s.GetGuestSeries(ctx, input.ID)
+	if err != nil {
+		return repo.GuestSeries{}, err
+	}
+	today := s.today()
+	if !GuestEditable(current.Status) || (current.EndsOn.Valid && current.EndsOn.Time.Before(today)) {
+		return repo.GuestSeries{}, ErrGuestTransition
+	}
+	spec, err := parseGuestSeries(input)
+	if err != nil {
+		return repo.GuestSeries{}, err
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	review, err := s.initialReview(input.Status, spec.visit(), actor)
+	if err != nil {
+		return repo.GuestSeries{}, err
+	}
+	upcoming := repo.DeleteGuestOccurrencesFromParams{
+		SeriesID:       uuid.NullUUID{UUID: current.ID, Valid: true},
+		OccurrenceDate: sql.NullTime{Time: today, Valid: true},
+	}
+	inPlace := current.Status == GuestStatusPending || !dateOf(current.StartsOn).Before(today)
+	if !inPlace && spec.startsOn.Before(today) {
+		spec.startsOn = today
+		if spec.endsOn.Valid && spec.endsOn.Time.Before(today) {
+			return repo.GuestSeries{}, ErrInvalidRange
+		}
+	}
+	var updated repo.GuestSeries
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		if inPlace {
+			updated, err = q.UpdateGuestSeries(ctx, repo.UpdateGuestSeriesParams{
+				ID:            current.ID,
+				GuestFullName: strings.TrimSpace(input.GuestName),
+				PlateNumber:   NormalizePlate(input.PlateNumber),
+				Rrule:         spec.rule.String(),
+				StartsOn:      spec.startsOn,
+				EndsOn:        spec.endsOn,
+				StartMinute:   spec.startMinute,
+				EndMinute:     spec.endMinute,
+				Status:        review.Status,
+				ReviewedBy:    review.ReviewedBy,
+				ReviewedAt:    review.ReviewedAt,
+				ReviewReason:  review.ReviewReason,
+				UpdatedBy:     actor,
+			})
+		} else {
+			_, err = q.EndGuestSeries(ctx, repo.EndGuestSeriesParams{
+				ID:        current.ID,
+				EndsOn:    sql.NullTime{Time: today.AddDate(0, 0, -1), Valid: true},
+				UpdatedBy: actor,
+			})
+			if err == nil {
+				previous := uuid.NullUUID{UUID: current.ID, Valid: true}
+				updated, err = q.CreateGuestSeries(ctx, seriesParams(current.ResidentUserID, input, spec, review, actor, previous))
+			}
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGuestTransition
+		}
+		if err != nil {
+			return err
+		}
+		_, err = q.DeleteGuestOccurrencesFrom(ctx, upcoming)
+		return err
+	})
+	return updated, err
+}
+
+func (s *Service) ApproveGuestSeries(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestSeries, error) {
+	return s.reviewGuestSeries(ctx, id, reviewer, GuestStatusApproved, reason)
+}
+
+func (s *Service) RejectGuestSeries(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestSeries, error) {
+	if strings.TrimSpace(reason) == "" {
+		return repo.GuestSeries{}, ErrReviewReason
+	}
+	return s.reviewGuestSeries(ctx, id, reviewer, GuestStatusRejected, reason)
+}
+
+func (s *Service) reviewGuestSeries(ctx context.Context, id, reviewer uuid.UUID, status, reason string) (repo.GuestSeries, error) {
+	series, err := s.GetGuestSeries(ctx, id)
+	if err != nil {
+		return repo.GuestSeries{}, err
+	}
+	if !CanTransitionGuest(series.Status, status) {
+		return repo.GuestSeries{}, ErrGuestTransition
+	}
+	reason = strings.TrimSpace(reason)
+	reviewedBy := uuid.NullUUID{UUID: reviewer, Valid: reviewer != uuid.Nil}
+	reviewReason := sql.NullString{String: reason, Valid: reason != ""}
+	var reviewed repo.GuestSeries
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		reviewed, err = q.ReviewGuestSeries(ctx, repo.ReviewGuestSeriesParams{ID: id, Status: status, ReviewedBy: reviewedBy, ReviewReason: reviewReason})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGuestTransition
+		}
+		if err != nil {
+			return err
+		}
+		// Occurrences edited while the series was pending follow its decision.
+		_, err = q.ReviewGuestOccurrences(ctx, repo.ReviewGuestOccurrencesParams{
+			SeriesID:     uuid.NullUUID{UUID: id, Valid: true},
+			Status:       status,
+			ReviewedBy:   reviewedBy,
+			ReviewReason: reviewReason,
+		})
+		return err
+	})
+	return reviewed, err
+}
+
+// CancelGuestSeries stops the series; visits from today on that were not
+// used yet are dropped.
+func (s *Service) CancelGuestSeries(ctx context.Context, id, actor uuid.UUID) (repo.GuestSeries, error) {
+	series, err := s.GetGuestSeries(ctx, id)
+	if err != nil {
+		return repo.GuestSeries{}, err
+	}
+	if !CanTransitionGuest(series.Status, GuestStatusCancelled) {
+		return repo.GuestSeries{}, ErrGuestTransition
+	}
+	var cancelled repo.GuestSeries
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		cancelled, err = q.SetGuestSeriesStatus(ctx, repo.SetGuestSeriesStatusParams{
+			Status:     GuestStatusCancelled,
+			UpdatedBy:  uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil},
+			ID:         id,
+			FromStatus: series.Status,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGuestTransition
+		}
+		if err != nil {
+			return err
+		}
+		_, err = q.DeleteGuestOccurrencesFrom(ctx, repo.DeleteGuestOccurrencesFromParams{
+			SeriesID:       uuid.NullUUID{UUID: id, Valid: true},
+			OccurrenceDate: sql.NullTime{Time: s.today(), Valid: true},
+		})
+		return err
+	})
+	return cancelled, err
+}
+
+// ListGuestOccurrences expands the series between the given days, both
+// inclusive, and merges in the occurrences that already have a guest
+// request. Empty days default to the next DefaultOccurrenceDays.
+func (s *Service) ListGuestOccurrences(ctx context.Context, seriesID uuid.UUID, from, to string) ([]GuestOccurrence, error) {
+	series, err := s.GetGuestSeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	first, last := s.today(), s.today().AddDate(0, 0, DefaultOccurrenceDays)
+	if from != "" {
+		if first, err = ParseDay(from); err != nil {
+			return nil, err
+		}
+		last = first.AddDate(0, 0, DefaultOccurrenceDays)
+	}
+	if to != "" {
+		if last, err = ParseDay(to); err != nil {
+			return nil, err
+		}
+	}
+	if last.Before(first) || last.Sub(first) > MaxOccurrenceDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+	rows, err := s.q.ListGuestOccurrences(ctx, repo.ListGuestOccurrencesParams{SeriesID: seriesID, FirstDay: first, LastDay: last})
+	if err != nil {
+		return nil, err
+	}
+	days, err := seriesDays(series, first, last)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[time.Time]repo.GuestRequest, len(rows))
+	for _, row := range rows {
+		existing[dateOf(row.OccurrenceDate.Time)] = row
+	}
+	occurrences := make([]GuestOccurrence, 0, len(days))
+	for _, day := range days {
+		if _, ok := existing[day]; ok {
+			continue
+		}
+		validFrom, validTo := s.occurrenceWindow(series, day)
+		occurrences = append(occurrences, GuestOccurrence{
+			Day:         day,
+			ValidFrom:   validFrom,
+			ValidTo:     validTo,
+			GuestName:   series.GuestFullName,
+			PlateNumber: series.PlateNumber,
+			Status:      series.Status,
+		})
+	}
+	for day, row := range existing {
+		occurrences = append(occurrences, GuestOccurrence{
+			Day:         day,
+			ValidFrom:   row.ValidFrom,
+			ValidTo:     row.ValidTo,
+			GuestName:   row.GuestFullName,
+			PlateNumber: row.PlateNumber,
+			Status:      row.Status,
+			Request:     &row,
+		})
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Day.Before(occurrences[j].Day) })
+	return occurrences, nil
+}
+
+// GetGuestOccurrence returns the guest request of one occurrence, creating
+// it if the series is still active.
+func (s *Service) GetGuestOccurrence(ctx context.Context, seriesID uuid.UUID, day string) (repo.GuestRequest, error) {
+	series, err := s.GetGuestSeries(ctx, seriesID)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	date, err := ParseDay(day)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	days, err := seriesDays(series, date, date)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	if len(days) == 0 {
+		return repo.GuestRequest{}, ErrNotFound
+	}
+	if GuestEditable(series.Status) {
+		if err := s.q.MaterializeGuestOccurrence(ctx, s.occurrenceParams(series, date)); err != nil {
+			return repo.GuestRequest{}, err
+		}
+	}
+	guest, err := s.q.GetGuestOccurrence(ctx, repo.GetGuestOccurrenceParams{
+		SeriesID:       uuid.NullUUID{UUID: seriesID, Valid: true},
+		OccurrenceDate: sql.NullTime{Time: date, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.GuestRequest{}, ErrGuestTransition
+	}
+	return guest, err
+}
+
+// SkipGuestOccurrence cancels a single visit; the rest of the series is not
+// affected.
+func (s *Service) SkipGuestOccurrence(ctx context.Context, seriesID uuid.UUID, day string, actor uuid.UUID) (repo.GuestRequest, error) {
+	guest, err := s.GetGuestOccurrence(ctx, seriesID, day)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	return s.CancelGuestRequest(ctx, guest.ID, actor)
+}
+
+// UpdateGuestOccurrence changes a single visit of a series; the visit goes
+// through review again unless Status says otherwise. A later edit of the
+// series regenerates upcoming visits, edited ones included.
+func (s *Service) UpdateGuestOccurrence(ctx context.Context, input GuestOccurrenceInput) (repo.GuestRequest, error) {
+	if err := ValidatePlate(input.PlateNumber); err != nil {
+		return repo.GuestRequest{}, err
+	}
+	if !input.ValidFrom.Before(input.ValidTo) {
+		return repo.GuestRequest{}, ErrInvalidRange
+	}
+	if err := s.checkGuestWindow(ctx, input.PlateNumber, input.ValidFrom, input.ValidTo, input.ID); err != nil {
+		return repo.GuestRequest{}, err
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	review, err := s.initialReview(input.Status, input.ValidTo.Sub(input.ValidFrom), actor)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	guest, err := s.q.UpdateGuestOccurrence(ctx, repo.UpdateGuestOccurrenceParams{
+		ID:            input.ID,
+		GuestFullName: input.GuestName,
+		PlateNumber:   NormalizePlate(input.PlateNumber),
+		ValidFrom:     input.ValidFrom,
+		ValidTo:       input.ValidTo,
+		Status:        review.Status,
+		ReviewedBy:    review.ReviewedBy,
+		ReviewedAt:    review.ReviewedAt,
+		ReviewReason:  review.ReviewReason,
+		UpdatedBy:     actor,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.GuestRequest{}, ErrGuestTransition
+	}
+	return guest, err
+}
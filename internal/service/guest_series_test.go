@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_GuestSeriesCreate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	actorID := uuid.New()
+	var created repo.CreateGuestSeriesParams
+	svc := New(&mockStore{
+		createGuestSeriesFn: func(_ context.Context, arg repo.CreateGuestSeriesParams) (repo.GuestSeries, error) {
+			created = arg
+			return repo.GuestSeries{ID: uuid.New(), Status: arg.Status}, nil
+		},
+	},
+		WithClock(func() time.Time { return now }),
+		WithSettings(Settings{GuestApproval: GuestApprovalRules{MaxDuration: 4 * time.Hour}}),
+	)
+	input := GuestSeriesInput{
+		ResidentID:  uuid.New(),
+		GuestName:   " Nanny ",
+		PlateNumber: "a123bc77",
+		Weekdays:    []int{2, 4},
+		Until:       "2025-06-30",
+		StartsOn:    "2025-05-01",
+		Start:       "09:00",
+		End:         "12:00",
+		ActorID:     actorID,
+	}
+
+	_, err := svc.CreateGuestSeries(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, "Nanny", created.GuestFullName)
+	require.Equal(t, "A123BC77", created.PlateNumber)
+	require.Equal(t, "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20250630", created.Rrule)
+	require.Equal(t, day("2025-06-30"), created.EndsOn.Time)
+	require.EqualValues(t, 540, created.StartMinute)
+	require.EqualValues(t, 720, created.EndMinute)
+	require.Equal(t, GuestStatusApproved, created.Status)
+	require.Equal(t, guestAutoApprovedReason, created.ReviewReason.String)
+	require.False(t, created.PreviousSeriesID.Valid)
+
+	input.Weekdays, input.Until = nil, ""
+	input.RRule = "FREQ=DAILY"
+	input.End = "08:00"
+	_, err = svc.CreateGuestSeries(ctx, input)
+	require.NoError(t, err)
+	require.False(t, created.EndsOn.Valid)
+	require.Equal(t, GuestStatusPending, created.Status)
+
+	bad := input
+	bad.Weekdays = []int{1}
+	_, err = svc.CreateGuestSeries(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidRecurrence)
+	bad = input
+	bad.RRule = ""
+	_, err = svc.CreateGuestSeries(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidRecurrence)
+	bad = input
+	bad.RRule = "FREQ=WEEKLY;BYDAY=MO;UNTIL=20250504"
+	_, err = svc.CreateGuestSeries(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidRecurrence)
+	bad = input
+	bad.End = bad.Start
+	_, err = svc.CreateGuestSeries(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	bad = input
+	bad.GuestName = " "
+	_, err = svc.CreateGuestSeries(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidInput)
+	bad = input
+	bad.Status = GuestStatusArrived
+	_, err = svc.CreateGuestSeries(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidGuestStatus)
+}
+
+func TestServiceUnit_GuestSeriesUpdate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 15, 12, 0, 0, 0, time.UTC)
+	actorID := uuid.New()
+	series := map[uuid.UUID]repo.GuestSeries{}
+	add := func(status, startsOn string) uuid.UUID {
+		id := uuid.New()
+		series[id] = repo.GuestSeries{ID: id, ResidentUserID: uuid.New(), Status: status, StartsOn: day(startsOn), Rrule: "FREQ=WEEKLY"}
+		return id
+	}
+	running := add(GuestStatusApproved, "2025-05-01")
+	upcoming := add(GuestStatusApproved, "2025-05-20")
+	pending := add(GuestStatusPending, "2025-05-01")
+	cancelled := add(GuestStatusCancelled, "2025-05-01")
+	var (
+		updated  []repo.UpdateGuestSeriesParams
+		ended    []repo.EndGuestSeriesParams
+		created  []repo.CreateGuestSeriesParams
+		deleted  []repo.DeleteGuestOccurrencesFromParams
+		endFails bool
+	)
+	svc := New(&mockStore{
+		getGuestSeriesByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestSeries, error) {
+			item, ok := series[id]
+			if !ok {
+				return repo.GuestSeries{}, sql.ErrNoRows
+			}
+			return item, nil
+		},
+		updateGuestSeriesFn: func(_ context.Context, arg repo.UpdateGuestSeriesParams) (repo.GuestSeries, error) {
+			updated = append(updated, arg)
+			return repo.GuestSeries{ID: arg.ID, Status: arg.Status}, nil
+		},
+		endGuestSeriesFn: func(_ context.Context, arg repo.EndGuestSeriesParams) (repo.GuestSeries, error) {
+			if endFails {
+				return repo.GuestSeries{}, sql.ErrNoRows
+			}
+			ended = append(ended, arg)
+			return repo.GuestSeries{ID: arg.ID}, nil
+		},
+		createGuestSeriesFn: func(_ context.Context, arg repo.CreateGuestSeriesParams) (repo.GuestSeries, error) {
+			created = append(created, arg)
+			return repo.GuestSeries{ID: uuid.New(), Status: arg.Status}, nil
+		},
+		deleteGuestOccurrencesFromFn: func(_ context.Context, arg repo.DeleteGuestOccurrencesFromParams) (int64, error) {
+			deleted = append(deleted, arg)
+			return 2, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+	input := GuestSeriesInput{
+		GuestName:   "Tutor",
+		PlateNumber: "M777MM77",
+		RRule:       "FREQ=WEEKLY;BYDAY=MO",
+		StartsOn:    "2025-05-01",
+		Start:       "17:00",
+		End:         "19:00",
+		Status:      GuestStatusApproved,
+		ActorID:     actorID,
+	}
+
+	input.ID = running
+	_, err := svc.UpdateGuestSeries(ctx, input)
+	require.NoError(t, err)
+	require.Empty(t, updated)
+	require.Len(t, ended, 1)
+	require.Equal(t, day("2025-05-14"), ended[0].EndsOn.Time)
+	require.Len(t, created, 1)
+	require.Equal(t, running, created[0].PreviousSeriesID.UUID)
+	require.Equal(t, series[running].ResidentUserID, created[0].ResidentUserID)
+	require.Equal(t, day("2025-05-15"), created[0].StartsOn)
+	require.Equal(t, actorID, created[0].ReviewedBy.UUID)
+	require.Len(t, deleted, 1)
+	require.Equal(t, running, deleted[0].SeriesID.UUID)
+	require.Equal(t, day("2025-05-15"), deleted[0].OccurrenceDate.Time)
+
+	for _, id := range []uuid.UUID{upcoming, pending} {
+		input.ID = id
+		input.StartsOn = "2025-05-22"
+		_, err = svc.UpdateGuestSeries(ctx, input)
+		require.NoError(t, err)
+	}
+	require.Len(t, updated, 2)
+	require.Equal(t, day("2025-05-22"), updated[1].StartsOn)
+	require.Len(t, ended, 1)
+	require.Len(t, deleted, 3)
+
+	input.ID = cancelled
+	_, err = svc.UpdateGuestSeries(ctx, input)
+	require.ErrorIs(t, err, ErrGuestTransition)
+	input.ID = uuid.New()
+	_, err = svc.UpdateGuestSeries(ctx, input)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	input.ID = running
+	input.RRule = "FREQ=WEEKLY;UNTIL=20250510"
+	input.StartsOn = "2025-05-01"
+	_, err = svc.UpdateGuestSeries(ctx, input)
+	require.ErrorIs(t, err, ErrInvalidRange)
+
+	input.RRule = "FREQ=WEEKLY"
+	endFails = true
+	_, err = svc.UpdateGuestSeries(ctx, input)
+	require.ErrorIs(t, err, ErrGuestTransition)
+}
+
+func TestServiceUnit_GuestSeriesReviewAndCancel(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 15, 12, 0, 0, 0, time.UTC)
+	reviewerID := uuid.New()
+	pending := repo.GuestSeries{ID: uuid.New(), Status: GuestStatusPending}
+	approved := repo.GuestSeries{ID: uuid.New(), Status: GuestStatusApproved}
+	var (
+		reviews     []repo.ReviewGuestOccurrencesParams
+		transitions []repo.SetGuestSeriesStatusParams
+		deleted     []repo.DeleteGuestOccurrencesFromParams
+	)
+	svc := New(&mockStore{
+		getGuestSeriesByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestSeries, error) {
+			for _, item := range []repo.GuestSeries{pending, approved} {
+				if item.ID == id {
+					return item, nil
+				}
+			}
+			return repo.GuestSeries{}, sql.ErrNoRows
+		},
+		reviewGuestSeriesFn: func(_ context.Context, arg repo.ReviewGuestSeriesParams) (repo.GuestSeries, error) {
+			return repo.GuestSeries{ID: arg.ID, Status: arg.Status, ReviewReason: arg.ReviewReason}, nil
+		},
+		reviewGuestOccurrencesFn: func(_ context.Context, arg repo.ReviewGuestOccurrencesParams) (int64, error) {
+			reviews = append(reviews, arg)
+			return 1, nil
+		},
+		setGuestSeriesStatusFn: func(_ context.Context, arg repo.SetGuestSeriesStatusParams) (repo.GuestSeries, error) {
+			transitions = append(transitions, arg)
+			return repo.GuestSeries{ID: arg.ID, Status: arg.Status}, nil
+		},
+		deleteGuestOccurrencesFromFn: func(_ context.Context, arg repo.DeleteGuestOccurrencesFromParams) (int64, error) {
+			deleted = append(deleted, arg)
+			return 0, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	_, err := svc.RejectGuestSeries(ctx, pending.ID, reviewerID, " ")
+	require.ErrorIs(t, err, ErrReviewReason)
+	rejected, err := svc.RejectGuestSeries(ctx, pending.ID, reviewerID, "no")
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusRejected, rejected.Status)
+	require.Equal(t, "no", rejected.ReviewReason.String)
+	got, err := svc.ApproveGuestSeries(ctx, pending.ID, reviewerID, "")
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusApproved, got.Status)
+	require.Len(t, reviews, 2)
+	require.Equal(t, pending.ID, reviews[1].SeriesID.UUID)
+	require.Equal(t, reviewerID, reviews[1].ReviewedBy.UUID)
+	require.False(t, reviews[1].ReviewReason.Valid)
+	_, err = svc.ApproveGuestSeries(ctx, approved.ID, reviewerID, "")
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.ApproveGuestSeries(ctx, uuid.New(), reviewerID, "")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	cancelled, err := svc.CancelGuestSeries(ctx, approved.ID, reviewerID)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusCancelled, cancelled.Status)
+	require.Equal(t, GuestStatusApproved, transitions[0].FromStatus)
+	require.Len(t, deleted, 1)
+	require.Equal(t, day("2025-05-15"), deleted[0].OccurrenceDate.Time)
+}
+
+func TestServiceUnit_GuestOccurrences(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+	residentID := uuid.New()
+	series := repo.GuestSeries{
+		ID:             uuid.New(),
+		ResidentUserID: residentID,
+		GuestFullName:  "Cleaner",
+		PlateNumber:    "B456CE99",
+		Rrule:          "FREQ=WEEKLY;BYDAY=TU,TH",
+		StartsOn:       day("2025-05-01"),
+		EndsOn:         sql.NullTime{Time: day("2025-05-13"), Valid: true},
+		StartMinute:    22 * 60,
+		EndMinute:      2 * 60,
+		Status:         GuestStatusApproved,
+	}
+	skippedID := uuid.New()
+	skipped := repo.GuestRequest{
+		ID:             skippedID,
+		Status:         GuestStatusCancelled,
+		SeriesID:       uuid.NullUUID{UUID: series.ID, Valid: true},
+		OccurrenceDate: sql.NullTime{Time: day("2025-05-06"), Valid: true},
+	}
+	var (
+		materialized []repo.MaterializeGuestOccurrenceParams
+		listed       repo.ListGuestOccurrencesParams
+		edited       repo.UpdateGuestOccurrenceParams
+		cancelled    []uuid.UUID
+	)
+	svc := New(&mockStore{
+		getGuestSeriesByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestSeries, error) {
+			if id != series.ID {
+				return repo.GuestSeries{}, sql.ErrNoRows
+			}
+			return series, nil
+		},
+		listGuestOccurrencesFn: func(_ context.Context, arg repo.ListGuestOccurrencesParams) ([]repo.GuestRequest, error) {
+			listed = arg
+			return []repo.GuestRequest{skipped}, nil
+		},
+		materializeGuestOccurrenceFn: func(_ context.Context, arg repo.MaterializeGuestOccurrenceParams) error {
+			materialized = append(materialized, arg)
+			return nil
+		},
+		getGuestOccurrenceFn: func(_ context.Context, arg repo.GetGuestOccurrenceParams) (repo.GuestRequest, error) {
+			if arg.OccurrenceDate.Time.Equal(day("2025-05-06")) {
+				return skipped, nil
+			}
+			return repo.GuestRequest{ID: uuid.New(), Status: GuestStatusApproved, OccurrenceDate: arg.OccurrenceDate}, nil
+		},
+		getGuestRequestByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+			return repo.GuestRequest{ID: id, Status: GuestStatusApproved}, nil
+		},
+		setGuestRequestStatusFn: func(_ context.Context, arg repo.SetGuestRequestStatusParams) (repo.GuestRequest, error) {
+			cancelled = append(cancelled, arg.ID)
+			return repo.GuestRequest{ID: arg.ID, Status: arg.Status}, nil
+		},
+		updateGuestOccurrenceFn: func(_ context.Context, arg repo.UpdateGuestOccurrenceParams) (repo.GuestRequest, error) {
+			edited = arg
+			if arg.ID == skippedID {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			return repo.GuestRequest{ID: arg.ID, Status: arg.Status}, nil
+		},
+	},
+		WithClock(func() time.Time { return now }),
+		WithSettings(Settings{Location: moscow}),
+	)
+
+	occurrences, err := svc.ListGuestOccurrences(ctx, series.ID, "", "")
+	require.NoError(t, err)
+	require.Equal(t, day("2025-05-01"), listed.FirstDay)
+	require.Equal(t, day("2025-05-29"), listed.LastDay)
+	require.Len(t, occurrences, 4)
+	require.Equal(t, day("2025-05-01"), occurrences[0].Day)
+	require.Nil(t, occurrences[0].Request)
+	require.Equal(t, time.Date(2025, 5, 1, 22, 0, 0, 0, moscow), occurrences[0].ValidFrom)
+	require.Equal(t, time.Date(2025, 5, 2, 2, 0, 0, 0, moscow), occurrences[0].ValidTo)
+	require.Equal(t, "Cleaner", occurrences[0].GuestName)
+	require.Equal(t, day("2025-05-06"), occurrences[1].Day)
+	require.Equal(t, GuestStatusCancelled, occurrences[1].Status)
+	require.Equal(t, skippedID, occurrences[1].Request.ID)
+	require.Equal(t, day("2025-05-13"), occurrences[3].Day)
+
+	_, err = svc.ListGuestOccurrences(ctx, series.ID, "2025-05-10", "2025-05-01")
+	require.ErrorIs(t, err, ErrInvalidRange)
+	_, err = svc.ListGuestOccurrences(ctx, series.ID, "2025-01-01", "2025-12-31")
+	require.ErrorIs(t, err, ErrInvalidRange)
+	_, err = svc.ListGuestOccurrences(ctx, series.ID, "May", "")
+	require.Error(t, err)
+	_, err = svc.ListGuestOccurrences(ctx, uuid.New(), "", "")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.GetGuestOccurrence(ctx, series.ID, "2025-05-05")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.GetGuestOccurrence(ctx, series.ID, "2025-05-15")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	guest, err := svc.SkipGuestOccurrence(ctx, series.ID, "2025-05-08", residentID)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusCancelled, guest.Status)
+	require.Len(t, materialized, 1)
+	require.Equal(t, day("2025-05-08"), materialized[0].OccurrenceDate.Time)
+	require.Equal(t, series.ID, materialized[0].SeriesID.UUID)
+	require.Equal(t, GuestStatusApproved, materialized[0].Status)
+	require.Equal(t, time.Date(2025, 5, 8, 22, 0, 0, 0, moscow), materialized[0].ValidFrom)
+	require.Equal(t, []uuid.UUID{guest.ID}, cancelled)
+
+	input := GuestOccurrenceInput{
+		ID:          uuid.New(),
+		GuestName:   "Cleaner",
+		PlateNumber: "b456ce99",
+		ValidFrom:   now.Add(24 * time.Hour),
+		ValidTo:     now.Add(26 * time.Hour),
+		ActorID:     residentID,
+	}
+	guest, err = svc.UpdateGuestOccurrence(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusPending, guest.Status)
+	require.Equal(t, "B456CE99", edited.PlateNumber)
+	input.ID = skippedID
+	_, err = svc.UpdateGuestOccurrence(ctx, input)
+	require.ErrorIs(t, err, ErrGuestTransition)
+	input.ValidTo = input.ValidFrom
+	_, err = svc.UpdateGuestOccurrence(ctx, input)
+	require.ErrorIs(t, err, ErrInvalidRange)
+	input.PlateNumber = "bad"
+	_, err = svc.UpdateGuestOccurrence(ctx, input)
+	require.ErrorIs(t, err, ErrInvalidPlate)
+}
+
+func TestServiceUnit_GuestSeriesMaterialization(t *testing.T) {
+	ctx := context.Background()
+	// Thursday 00:30 UTC: Wednesday's overnight visit is still running.
+	now := time.Date(2025, 5, 1, 0, 30, 0, 0, time.UTC)
+	active := repo.GuestSeries{
+		ID:          uuid.New(),
+		Rrule:       "FREQ=DAILY",
+		StartsOn:    day("2025-04-01"),
+		StartMinute: 23 * 60,
+		EndMinute:   60,
+		Status:      GuestStatusApproved,
+	}
+	var (
+		window       repo.ListActiveGuestSeriesParams
+		materialized []repo.MaterializeGuestOccurrenceParams
+	)
+	store := &mockStore{
+		listActiveGuestSeriesFn: func(_ context.Context, arg repo.ListActiveGuestSeriesParams) ([]repo.GuestSeries, error) {
+			window = arg
+			return []repo.GuestSeries{active}, nil
+		},
+		materializeGuestOccurrenceFn: func(_ context.Context, arg repo.MaterializeGuestOccurrenceParams) error {
+			materialized = append(materialized, arg)
+			return nil
+		},
+		listGateGuestsFn: func(context.Context, repo.ListGateGuestsParams) ([]repo.ListGateGuestsRow, error) {
+			return nil, nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+
+	_, err := svc.ExpectedGuests(ctx, 2*time.Hour, "", 20, 0)
+	require.NoError(t, err)
+	require.Equal(t, day("2025-04-30"), window.FirstDay)
+	require.Equal(t, day("2025-05-01"), window.LastDay)
+	require.Len(t, materialized, 1)
+	require.Equal(t, day("2025-04-30"), materialized[0].OccurrenceDate.Time)
+
+	materialized = nil
+	_, err = svc.SearchGuestsByPlate(ctx, "A123BC77", "", 20, 0)
+	require.NoError(t, err)
+	require.Len(t, materialized, 1)
+
+	materialized = nil
+	_, err = svc.ExpectedGuests(ctx, 23*time.Hour, "", 20, 0)
+	require.NoError(t, err)
+	require.Len(t, materialized, 2)
+	require.Equal(t, day("2025-05-01"), materialized[1].OccurrenceDate.Time)
+
+	store.listActiveGuestSeriesFn = func(context.Context, repo.ListActiveGuestSeriesParams) ([]repo.GuestSeries, error) {
+		return nil, sql.ErrConnDone
+	}
+	_, err = svc.ExpectedGuests(ctx, time.Hour, "", 20, 0)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
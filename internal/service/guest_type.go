@@ -0,0 +1,107 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	GuestTypeVehicle    = "vehicle"
+	GuestTypePedestrian = "pedestrian"
+	GuestTypeTaxi       = "taxi"
+	GuestTypeDelivery   = "delivery"
+	GuestTypeService    = "service"
+)
+
+var (
+	ErrInvalidGuestType    = errors.New("invalid guest type")
+	ErrPedestrianPlate     = errors.New("pedestrian guests have no plate")
+	ErrGuestCompanyMissing = errors.New("delivery requires a company name")
+)
+
+// GuestTypeRules are the per-type defaults of a guest request.
+type GuestTypeRules struct {
+	// DefaultDuration fills in valid_to when it is omitted; zero makes
+	// valid_to required.
+	DefaultDuration time.Duration
+}
+
+func DefaultGuestTypes() map[string]GuestTypeRules {
+	return map[string]GuestTypeRules{
+		GuestTypeTaxi:     {DefaultDuration: 30 * time.Minute},
+		GuestTypeDelivery: {DefaultDuration: time.Hour},
+	}
+}
+
+func ValidateGuestType(guestType string) error {
+	switch guestType {
+	case GuestTypeVehicle, GuestTypePedestrian, GuestTypeTaxi, GuestTypeDelivery, GuestTypeService:
+		return nil
+	default:
+		return ErrInvalidGuestType
+	}
+}
+
+// guestDetails are the type-dependent fields of a guest request after the
+// type rules and defaults were applied.
+type guestDetails struct {
+	Type        string
+	PlateNumber string
+	CompanyName sql.NullString
+	ValidFrom   time.Time
+	ValidTo     time.Time
+}
+
+// resolveGuestDetails checks the plate and company against the guest type
+// and fills in an omitted window: valid_from defaults to now and valid_to to
+// the type's DefaultDuration after it.
+func (s *Service) resolveGuestDetails(guestType, plate, company string, from, to time.Time) (guestDetails, error) {
+	details, err := resolveGuestSubject(guestType, plate, company)
+	if err != nil {
+		return guestDetails{}, err
+	}
+	if from.IsZero() {
+		from = s.now()
+	}
+	if to.IsZero() {
+		duration := s.settings.GuestTypes[details.Type].DefaultDuration
+		if duration <= 0 {
+			return guestDetails{}, ErrInvalidRange
+		}
+		to = from.Add(duration)
+	}
+	details.ValidFrom = from
+	details.ValidTo = to
+	return details, nil
+}
+
+// resolveGuestSubject applies the type rules to who is coming, without the
+// window. Only vehicle guests need a plate.
+func resolveGuestSubject(guestType, plate, company string) (guestDetails, error) {
+	if guestType == "" {
+		guestType = GuestTypeVehicle
+	}
+	if err := ValidateGuestType(guestType); err != nil {
+		return guestDetails{}, err
+	}
+	plate = NormalizePlate(plate)
+	switch {
+	case guestType == GuestTypePedestrian && plate != "":
+		return guestDetails{}, ErrPedestrianPlate
+	case guestType == GuestTypeVehicle || plate != "":
+		if err := ValidatePlate(plate); err != nil {
+			return guestDetails{}, err
+		}
+	}
+	company = strings.TrimSpace(company)
+	if guestType == GuestTypeDelivery && company == "" {
+		return guestDetails{}, ErrGuestCompanyMissing
+	}
+	return guestDetails{
+		Type:        guestType,
+		PlateNumber: plate,
+		CompanyName: sql.NullString{String: company, Valid: company != ""},
+	}, nil
+}
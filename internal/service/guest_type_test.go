@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_GuestTypes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	var (
+		created repo.CreateGuestRequestParams
+		updated repo.UpdateGuestRequestParams
+		overlap int
+		gate    repo.ListGateGuestsParams
+	)
+	store := &mockStore{
+		listOverlappingGuestRequestsFn: func(context.Context, repo.ListOverlappingGuestRequestsParams) ([]uuid.UUID, error) {
+			overlap++
+			return nil, nil
+		},
+		createGuestRequestFn: func(_ context.Context, arg repo.CreateGuestRequestParams) (repo.GuestRequest, error) {
+			created = arg
+			return repo.GuestRequest{ID: uuid.New(), GuestType: arg.GuestType, PlateNumber: arg.PlateNumber}, nil
+		},
+		updateGuestRequestFn: func(_ context.Context, arg repo.UpdateGuestRequestParams) (repo.GuestRequest, error) {
+			updated = arg
+			return repo.GuestRequest{ID: arg.ID, GuestType: arg.GuestType}, nil
+		},
+		listGateGuestsFn: func(_ context.Context, arg repo.ListGateGuestsParams) ([]repo.ListGateGuestsRow, error) {
+			gate = arg
+			return nil, nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+	base := GuestCreateInput{ResidentID: uuid.New(), GuestName: "Guest", ValidFrom: now.Add(time.Hour), ValidTo: now.Add(2 * time.Hour)}
+
+	pedestrian := base
+	pedestrian.GuestType = GuestTypePedestrian
+	guest, err := svc.CreateGuestRequest(ctx, pedestrian)
+	require.NoError(t, err)
+	require.Equal(t, GuestTypePedestrian, created.GuestType)
+	require.Empty(t, created.PlateNumber)
+	require.Zero(t, overlap)
+	conflicts, err := svc.GuestConflicts(ctx, guest)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+
+	pedestrian.PlateNumber = "A123BC77"
+	_, err = svc.CreateGuestRequest(ctx, pedestrian)
+	require.ErrorIs(t, err, ErrPedestrianPlate)
+
+	taxi := GuestCreateInput{ResidentID: base.ResidentID, GuestName: "Taxi", GuestType: GuestTypeTaxi}
+	_, err = svc.CreateGuestRequest(ctx, taxi)
+	require.NoError(t, err)
+	require.Equal(t, now, created.ValidFrom)
+	require.Equal(t, now.Add(30*time.Minute), created.ValidTo)
+	require.Empty(t, created.PlateNumber)
+
+	taxi.PlateNumber = " e111ee77 "
+	_, err = svc.CreateGuestRequest(ctx, taxi)
+	require.NoError(t, err)
+	require.Equal(t, "E111EE77", created.PlateNumber)
+
+	delivery := base
+	delivery.GuestType = GuestTypeDelivery
+	_, err = svc.CreateGuestRequest(ctx, delivery)
+	require.ErrorIs(t, err, ErrGuestCompanyMissing)
+	delivery.CompanyName = " Fast Food "
+	_, err = svc.CreateGuestRequest(ctx, delivery)
+	require.NoError(t, err)
+	require.Equal(t, "Fast Food", created.CompanyName.String)
+
+	vehicle := base
+	_, err = svc.CreateGuestRequest(ctx, vehicle)
+	require.ErrorIs(t, err, ErrInvalidPlate)
+	vehicle.PlateNumber = "A123BC77"
+	vehicle.ValidTo = time.Time{}
+	_, err = svc.CreateGuestRequest(ctx, vehicle)
+	require.ErrorIs(t, err, ErrInvalidRange)
+	vehicle.ValidTo = base.ValidTo
+	_, err = svc.CreateGuestRequest(ctx, vehicle)
+	require.NoError(t, err)
+	require.Equal(t, GuestTypeVehicle, created.GuestType)
+
+	bogus := base
+	bogus.GuestType = "bicycle"
+	_, err = svc.CreateGuestRequest(ctx, bogus)
+	require.ErrorIs(t, err, ErrInvalidGuestType)
+
+	_, err = svc.UpdateGuestRequest(ctx, GuestUpdateInput{
+		ID:        uuid.New(),
+		GuestName: "Service",
+		GuestType: GuestTypeService,
+		ValidFrom: base.ValidFrom,
+		ValidTo:   base.ValidTo,
+		Status:    GuestStatusApproved,
+	})
+	require.NoError(t, err)
+	require.Equal(t, GuestTypeService, updated.GuestType)
+
+	// Defaults come from settings, so a type can be given a window of its own.
+	custom := DefaultGuestTypes()
+	custom[GuestTypeService] = GuestTypeRules{DefaultDuration: 4 * time.Hour}
+	svc = New(store, WithClock(func() time.Time { return now }), WithSettings(Settings{GuestTypes: custom}))
+	_, err = svc.CreateGuestRequest(ctx, GuestCreateInput{GuestName: "Plumber", GuestType: GuestTypeService})
+	require.NoError(t, err)
+	require.Equal(t, now.Add(4*time.Hour), created.ValidTo)
+
+	_, err = svc.ExpectedGuests(ctx, time.Hour, GuestTypeDelivery, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, GuestTypeDelivery, gate.GuestType.String)
+	_, err = svc.SearchGuestsByPlate(ctx, "A123BC77", "", 10, 0)
+	require.NoError(t, err)
+	require.False(t, gate.GuestType.Valid)
+	_, err = svc.SearchGuestsByPlate(ctx, "A123BC77", "bicycle", 10, 0)
+	require.ErrorIs(t, err, ErrInvalidGuestType)
+}
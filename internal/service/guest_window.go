@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	GuestOverlapWarn   = "warn"
+	GuestOverlapReject = "reject"
+)
+
+var (
+	ErrGuestWindowPast    = errors.New("visit window is in the past")
+	ErrGuestWindowHorizon = errors.New("visit window is beyond the booking horizon")
+	ErrGuestConflict      = errors.New("plate already has a guest request for an overlapping window")
+)
+
+type GuestWindowRules struct {
+	// Horizon is how far ahead a visit may end; zero means no limit.
+	Horizon time.Duration
+	// Overlap is what happens when the plate already has an active request
+	// for an overlapping window: GuestOverlapWarn (the default) lets the
+	// request through, GuestOverlapReject fails with a GuestConflictError.
+	Overlap string
+}
+
+// GuestConflictError carries the active requests that overlap the rejected
+// one; it matches ErrGuestConflict.
+type GuestConflictError struct {
+	IDs []uuid.UUID
+}
+
+func (e *GuestConflictError) Error() string {
+	return ErrGuestConflict.Error()
+}
+
+func (e *GuestConflictError) Unwrap() error {
+	return ErrGuestConflict
+}
+
+// checkGuestWindow validates a visit window of plate before it is saved;
+// exclude is the request being edited. Guests without a plate never overlap.
+func (s *Service) checkGuestWindow(ctx context.Context, plate string, from, to time.Time, exclude uuid.UUID) error {
+	if from.After(to) {
+		return ErrInvalidRange
+	}
+	now := s.now()
+	if !to.After(now) {
+		return ErrGuestWindowPast
+	}
+	if horizon := s.settings.GuestWindow.Horizon; horizon > 0 && to.After(now.Add(horizon)) {
+		return ErrGuestWindowHorizon
+	}
+	if plate == "" || s.settings.GuestWindow.Overlap != GuestOverlapReject {
+		return nil
+	}
+	ids, err := s.overlappingGuests(ctx, plate, from, to, exclude)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return &GuestConflictError{IDs: ids}
+	}
+	return nil
+}
+
+func (s *Service) overlappingGuests(ctx context.Context, plate string, from, to time.Time, exclude uuid.UUID) ([]uuid.UUID, error) {
+	return s.q.ListOverlappingGuestRequests(ctx, repo.ListOverlappingGuestRequestsParams{
+		PlateNumber: NormalizePlate(plate),
+		ValidTo:     to,
+		ValidFrom:   from,
+		ExcludeID:   exclude,
+	})
+}
+
+// GuestConflicts lists the other active requests for the plate of guest
+// whose windows overlap it, for the warning shown when overlaps are allowed.
+func (s *Service) GuestConflicts(ctx context.Context, guest repo.GuestRequest) ([]uuid.UUID, error) {
+	if guest.PlateNumber == "" {
+		return nil, nil
+	}
+	return s.overlappingGuests(ctx, guest.PlateNumber, guest.ValidFrom, guest.ValidTo, guest.ID)
+}
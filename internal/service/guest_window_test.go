@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_GuestWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	existing := uuid.New()
+	var (
+		overlap repo.ListOverlappingGuestRequestsParams
+		created int
+		updated int
+	)
+	store := &mockStore{
+		listOverlappingGuestRequestsFn: func(_ context.Context, arg repo.ListOverlappingGuestRequestsParams) ([]uuid.UUID, error) {
+			overlap = arg
+			if arg.ExcludeID == existing {
+				return nil, nil
+			}
+			return []uuid.UUID{existing}, nil
+		},
+		createGuestRequestFn: func(_ context.Context, arg repo.CreateGuestRequestParams) (repo.GuestRequest, error) {
+			created++
+			return repo.GuestRequest{ID: uuid.New(), PlateNumber: arg.PlateNumber, ValidFrom: arg.ValidFrom, ValidTo: arg.ValidTo}, nil
+		},
+		updateGuestRequestFn: func(_ context.Context, arg repo.UpdateGuestRequestParams) (repo.GuestRequest, error) {
+			updated++
+			return repo.GuestRequest{ID: arg.ID}, nil
+		},
+	}
+	rules := GuestWindowRules{Horizon: 30 * 24 * time.Hour}
+	svc := New(store, WithClock(func() time.Time { return now }), WithSettings(Settings{GuestWindow: rules}))
+	input := GuestCreateInput{
+		ResidentID:  uuid.New(),
+		GuestName:   "Guest",
+		PlateNumber: "a123bc77",
+		ValidFrom:   now.Add(time.Hour),
+		ValidTo:     now.Add(2 * time.Hour),
+	}
+
+	guest, err := svc.CreateGuestRequest(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, 1, created)
+	conflicts, err := svc.GuestConflicts(ctx, guest)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{existing}, conflicts)
+	require.Equal(t, "A123BC77", overlap.PlateNumber)
+	require.Equal(t, guest.ID, overlap.ExcludeID)
+
+	past := input
+	past.ValidFrom, past.ValidTo = now.Add(-2*time.Hour), now
+	_, err = svc.CreateGuestRequest(ctx, past)
+	require.ErrorIs(t, err, ErrGuestWindowPast)
+	running := input
+	running.ValidFrom = now.Add(-time.Hour)
+	_, err = svc.CreateGuestRequest(ctx, running)
+	require.NoError(t, err)
+	far := input
+	far.ValidTo = now.Add(31 * 24 * time.Hour)
+	_, err = svc.CreateGuestRequest(ctx, far)
+	require.ErrorIs(t, err, ErrGuestWindowHorizon)
+	reversed := input
+	reversed.ValidFrom, reversed.ValidTo = input.ValidTo, input.ValidFrom
+	_, err = svc.CreateGuestRequest(ctx, reversed)
+	require.ErrorIs(t, err, ErrInvalidRange)
+	require.Equal(t, 2, created)
+
+	rules.Overlap = GuestOverlapReject
+	svc = New(store, WithClock(func() time.Time { return now }), WithSettings(Settings{GuestWindow: rules}))
+	_, err = svc.CreateGuestRequest(ctx, input)
+	require.ErrorIs(t, err, ErrGuestConflict)
+	var conflict *GuestConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, []uuid.UUID{existing}, conflict.IDs)
+	require.Equal(t, uuid.Nil, overlap.ExcludeID)
+	require.Equal(t, input.ValidTo, overlap.ValidTo)
+	require.Equal(t, 2, created)
+
+	// The request being edited does not conflict with itself.
+	_, err = svc.UpdateGuestRequest(ctx, GuestUpdateInput{
+		ID:          existing,
+		GuestName:   "Guest",
+		PlateNumber: "A123BC77",
+		ValidFrom:   input.ValidFrom,
+		ValidTo:     input.ValidTo,
+		Status:      GuestStatusPending,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, updated)
+	_, err = svc.UpdateGuestRequest(ctx, GuestUpdateInput{
+		ID:          uuid.New(),
+		GuestName:   "Guest",
+		PlateNumber: "A123BC77",
+		ValidFrom:   input.ValidFrom,
+		ValidTo:     input.ValidTo,
+		Status:      GuestStatusApproved,
+	})
+	require.ErrorIs(t, err, ErrGuestConflict)
+
+	store.listOverlappingGuestRequestsFn = func(context.Context, repo.ListOverlappingGuestRequestsParams) ([]uuid.UUID, error) {
+		return nil, sql.ErrConnDone
+	}
+	_, err = svc.CreateGuestRequest(ctx, input)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
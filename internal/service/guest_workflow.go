@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	GuestStatusPending   = "pending"
+	GuestStatusApproved  = "approved"
+	GuestStatusRejected  = "rejected"
+	GuestStatusCancelled = "cancelled"
+	GuestStatusExpired   = "expired"
+	GuestStatusArrived   = "arrived"
+	GuestStatusCompleted = "completed"
+	// GuestStatusSuspended is set while the resident is blocked; see
+	// CascadeRules.
+	GuestStatusSuspended = "suspended"
+
+	guestAutoApprovedReason = "auto-approved"
+)
+
+var (
+	ErrInvalidGuestStatus = errors.New("invalid guest request status")
+	ErrGuestTransition    = errors.New("guest request status transition is not allowed")
+	ErrGuestReviewed      = errors.New("only pending requests can be edited")
+	ErrReviewReason       = errors.New("rejection requires a reason")
+)
+
+// guestTransitions lists the statuses reachable from each status. Rejected,
+// cancelled, expired and completed requests are final; arrived and completed
+// are only reached through guest check-in and check-out.
+var guestTransitions = map[string][]string{
+	GuestStatusPending:  {GuestStatusApproved, GuestStatusRejected, GuestStatusCancelled, GuestStatusExpired},
+	GuestStatusApproved: {GuestStatusCancelled, GuestStatusExpired, GuestStatusArrived},
+	GuestStatusArrived:  {GuestStatusCompleted},
+	// A request suspended with its blocked resident can still be called off
+	// or run out; otherwise it waits for the resident to be unblocked.
+	GuestStatusSuspended: {GuestStatusCancelled, GuestStatusExpired},
+}
+
+// GuestApprovalRules decide which new requests skip manual review.
+type GuestApprovalRules struct {
+	// MaxDuration approves visits no longer than this on creation; zero
+	// disables auto-approval.
+	MaxDuration time.Duration
+}
+
+func ValidateGuestStatus(status string) error {
+	switch status {
+	case GuestStatusPending, GuestStatusApproved, GuestStatusRejected,
+		GuestStatusCancelled, GuestStatusExpired, GuestStatusArrived, GuestStatusCompleted, GuestStatusSuspended:
+		return nil
+	default:
+		return ErrInvalidGuestStatus
+	}
+}
+
+func CanTransitionGuest(from, to string) bool {
+	for _, next := range guestTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// GuestEditable reports whether the request details may still be changed.
+func GuestEditable(status string) bool {
+	return status == GuestStatusPending || status == GuestStatusApproved
+}
+
+func (s *Service) autoApproves(visit time.Duration) bool {
+	limit := s.settings.GuestApproval.MaxDuration
+	return limit > 0 && visit <= limit
+}
+
+// guestReview is the review state a new request or series starts with.
+type guestReview struct {
+	Status       string
+	ReviewedBy   uuid.NullUUID
+	ReviewedAt   sql.NullTime
+	ReviewReason sql.NullString
+}
+
+// initialReview resolves the requested status of a new visit: empty means
+// pending unless auto-approval applies, approved is recorded as reviewed by
+// the actor.
+func (s *Service) initialReview(status string, visit time.Duration, actor uuid.NullUUID) (guestReview, error) {
+	switch status {
+	case "":
+		if !s.autoApproves(visit) {
+			return guestReview{Status: GuestStatusPending}, nil
+		}
+		return guestReview{
+			Status:       GuestStatusApproved,
+			ReviewedAt:   sql.NullTime{Time: s.now(), Valid: true},
+			ReviewReason: sql.NullString{String: guestAutoApprovedReason, Valid: true},
+		}, nil
+	case GuestStatusPending:
+		return guestReview{Status: GuestStatusPending}, nil
+	case GuestStatusApproved:
+		return guestReview{Status: GuestStatusApproved, ReviewedBy: actor, ReviewedAt: sql.NullTime{Time: s.now(), Valid: true}}, nil
+	default:
+		return guestReview{}, ErrInvalidGuestStatus
+	}
+}
+
+func (s *Service) ApproveGuestRequest(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestRequest, error) {
+	return s.reviewGuestRequest(ctx, id, reviewer, GuestStatusApproved, reason)
+}
+
+func (s *Service) RejectGuestRequest(ctx context.Context, id, reviewer uuid.UUID, reason string) (repo.GuestRequest, error) {
+	if strings.TrimSpace(reason) == "" {
+		return repo.GuestRequest{}, ErrReviewReason
+	}
+	return s.reviewGuestRequest(ctx, id, reviewer, GuestStatusRejected, reason)
+}
+
+func (s *Service) reviewGuestRequest(ctx context.Context, id, reviewer uuid.UUID, status, reason string) (repo.GuestRequest, error) {
+	guest, err := s.GetGuestRequest(ctx, id)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	if !CanTransitionGuest(guest.Status, status) {
+		return repo.GuestRequest{}, ErrGuestTransition
+	}
+	reason = strings.TrimSpace(reason)
+	reviewed, err := s.q.ReviewGuestRequest(ctx, repo.ReviewGuestRequestParams{
+		ID:           id,
+		Status:       status,
+		ReviewedBy:   uuid.NullUUID{UUID: reviewer, Valid: reviewer != uuid.Nil},
+		ReviewReason: sql.NullString{String: reason, Valid: reason != ""},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The request left pending between the read and the update.
+		return repo.GuestRequest{}, ErrGuestTransition
+	}
+	return reviewed, err
+}
+
+func (s *Service) CancelGuestRequest(ctx context.Context, id, actor uuid.UUID) (repo.GuestRequest, error) {
+	return s.transitionGuestRequest(ctx, id, actor, GuestStatusCancelled)
+}
+
+func (s *Service) transitionGuestRequest(ctx context.Context, id, actor uuid.UUID, status string) (repo.GuestRequest, error) {
+	guest, err := s.GetGuestRequest(ctx, id)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	if !CanTransitionGuest(guest.Status, status) {
+		return repo.GuestRequest{}, ErrGuestTransition
+	}
+	updated, err := s.q.SetGuestRequestStatus(ctx, repo.SetGuestRequestStatusParams{
+		Status:     status,
+		UpdatedBy:  uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil},
+		ID:         id,
+		FromStatus: guest.Status,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.GuestRequest{}, ErrGuestTransition
+	}
+	return updated, err
+}
+
+// ExpireGuestRequests moves pending, approved and suspended requests whose
+// window has ended to expired and returns how many were changed.
+func (s *Service) ExpireGuestRequests(ctx context.Context) (int64, error) {
+	return s.q.ExpireGuestRequests(ctx, s.now())
+}
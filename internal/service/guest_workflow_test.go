@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_GuestTransitions(t *testing.T) {
+	require.True(t, CanTransitionGuest(GuestStatusPending, GuestStatusApproved))
+	require.True(t, CanTransitionGuest(GuestStatusApproved, GuestStatusArrived))
+	require.True(t, CanTransitionGuest(GuestStatusArrived, GuestStatusCompleted))
+	require.False(t, CanTransitionGuest(GuestStatusApproved, GuestStatusCompleted))
+	require.False(t, CanTransitionGuest(GuestStatusArrived, GuestStatusCancelled))
+	require.False(t, CanTransitionGuest(GuestStatusPending, GuestStatusCompleted))
+	require.False(t, CanTransitionGuest(GuestStatusRejected, GuestStatusApproved))
+	require.False(t, CanTransitionGuest(GuestStatusCancelled, GuestStatusPending))
+	require.True(t, CanTransitionGuest(GuestStatusSuspended, GuestStatusCancelled))
+	require.True(t, CanTransitionGuest(GuestStatusSuspended, GuestStatusExpired))
+	require.False(t, CanTransitionGuest(GuestStatusSuspended, GuestStatusApproved))
+	require.False(t, GuestEditable(GuestStatusSuspended))
+	require.True(t, GuestEditable(GuestStatusApproved))
+	require.False(t, GuestEditable(GuestStatusExpired))
+	require.False(t, GuestEditable(GuestStatusArrived))
+	require.NoError(t, ValidateGuestStatus(GuestStatusCompleted))
+	require.ErrorIs(t, ValidateGuestStatus("done"), ErrInvalidGuestStatus)
+}
+
+func TestServiceUnit_GuestAutoApproval(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	actorID := uuid.New()
+	var created repo.CreateGuestRequestParams
+	store := &mockStore{
+		createGuestRequestFn: func(_ context.Context, arg repo.CreateGuestRequestParams) (repo.GuestRequest, error) {
+			created = arg
+			return repo.GuestRequest{ID: uuid.New(), Status: arg.Status}, nil
+		},
+	}
+	svc := New(store,
+		WithClock(func() time.Time { return now }),
+		WithSettings(Settings{GuestApproval: GuestApprovalRules{MaxDuration: 4 * time.Hour}}),
+	)
+	input := GuestCreateInput{
+		ResidentID:  uuid.New(),
+		GuestName:   "Guest",
+		PlateNumber: "A123BC77",
+		ValidFrom:   now,
+		ValidTo:     now.Add(3 * time.Hour),
+		ActorID:     actorID,
+	}
+
+	_, err := svc.CreateGuestRequest(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusApproved, created.Status)
+	require.False(t, created.ReviewedBy.Valid)
+	require.Equal(t, now, created.ReviewedAt.Time)
+	require.Equal(t, guestAutoApprovedReason, created.ReviewReason.String)
+
+	input.ValidTo = now.Add(5 * time.Hour)
+	_, err = svc.CreateGuestRequest(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusPending, created.Status)
+	require.False(t, created.ReviewedAt.Valid)
+
+	input.Status = GuestStatusApproved
+	_, err = svc.CreateGuestRequest(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusApproved, created.Status)
+	require.Equal(t, actorID, created.ReviewedBy.UUID)
+	require.False(t, created.ReviewReason.Valid)
+
+	input.Status = GuestStatusCompleted
+	_, err = svc.CreateGuestRequest(ctx, input)
+	require.ErrorIs(t, err, ErrInvalidGuestStatus)
+
+	disabled := New(store, WithClock(func() time.Time { return now }))
+	input.Status = ""
+	input.ValidTo = now.Add(time.Hour)
+	_, err = disabled.CreateGuestRequest(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusPending, created.Status)
+}
+
+func TestServiceUnit_GuestReview(t *testing.T) {
+	ctx := context.Background()
+	reviewerID := uuid.New()
+	pendingID := uuid.New()
+	approvedID := uuid.New()
+	rejectedID := uuid.New()
+	racedID := uuid.New()
+	statuses := map[uuid.UUID]string{
+		pendingID:  GuestStatusPending,
+		approvedID: GuestStatusApproved,
+		rejectedID: GuestStatusRejected,
+		racedID:    GuestStatusPending,
+	}
+	var reviewed repo.ReviewGuestRequestParams
+	svc := New(&mockStore{
+		getGuestRequestByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+			status, ok := statuses[id]
+			if !ok {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			return repo.GuestRequest{ID: id, Status: status}, nil
+		},
+		reviewGuestRequestFn: func(_ context.Context, arg repo.ReviewGuestRequestParams) (repo.GuestRequest, error) {
+			if arg.ID == racedID {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			reviewed = arg
+			return repo.GuestRequest{ID: arg.ID, Status: arg.Status, ReviewedBy: arg.ReviewedBy, ReviewReason: arg.ReviewReason}, nil
+		},
+	})
+
+	guest, err := svc.ApproveGuestRequest(ctx, pendingID, reviewerID, "")
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusApproved, guest.Status)
+	require.Equal(t, reviewerID, reviewed.ReviewedBy.UUID)
+	require.False(t, reviewed.ReviewReason.Valid)
+
+	guest, err = svc.RejectGuestRequest(ctx, pendingID, reviewerID, "  unknown visitor ")
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusRejected, guest.Status)
+	require.Equal(t, "unknown visitor", reviewed.ReviewReason.String)
+
+	_, err = svc.RejectGuestRequest(ctx, pendingID, reviewerID, " ")
+	require.ErrorIs(t, err, ErrReviewReason)
+	_, err = svc.ApproveGuestRequest(ctx, approvedID, reviewerID, "")
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.RejectGuestRequest(ctx, rejectedID, reviewerID, "again")
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.ApproveGuestRequest(ctx, racedID, reviewerID, "")
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.ApproveGuestRequest(ctx, uuid.New(), reviewerID, "")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestServiceUnit_GuestCancelAndExpire(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	actorID := uuid.New()
+	approvedID := uuid.New()
+	cancelledID := uuid.New()
+	racedID := uuid.New()
+	statuses := map[uuid.UUID]string{
+		approvedID:  GuestStatusApproved,
+		cancelledID: GuestStatusCancelled,
+		racedID:     GuestStatusPending,
+	}
+	svc := New(&mockStore{
+		getGuestRequestByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+			status, ok := statuses[id]
+			if !ok {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			return repo.GuestRequest{ID: id, Status: status}, nil
+		},
+		setGuestRequestStatusFn: func(_ context.Context, arg repo.SetGuestRequestStatusParams) (repo.GuestRequest, error) {
+			if arg.ID == racedID {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			require.Equal(t, GuestStatusApproved, arg.FromStatus)
+			require.Equal(t, actorID, arg.UpdatedBy.UUID)
+			return repo.GuestRequest{ID: arg.ID, Status: arg.Status}, nil
+		},
+		expireGuestRequestsFn: func(_ context.Context, validTo time.Time) (int64, error) {
+			require.Equal(t, now, validTo)
+			return 3, nil
+		},
+		updateGuestRequestFn: func(context.Context, repo.UpdateGuestRequestParams) (repo.GuestRequest, error) {
+			t.Fatal("closed request must not be updated")
+			return repo.GuestRequest{}, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	guest, err := svc.CancelGuestRequest(ctx, approvedID, actorID)
+	require.NoError(t, err)
+	require.Equal(t, GuestStatusCancelled, guest.Status)
+	_, err = svc.CancelGuestRequest(ctx, cancelledID, actorID)
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.CancelGuestRequest(ctx, racedID, actorID)
+	require.ErrorIs(t, err, ErrGuestTransition)
+	_, err = svc.CancelGuestRequest(ctx, uuid.New(), actorID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	expired, err := svc.ExpireGuestRequests(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, expired)
+
+	_, err = svc.UpdateGuestRequest(ctx, GuestUpdateInput{
+		ID:          cancelledID,
+		GuestName:   "Guest",
+		PlateNumber: "A123BC77",
+		ValidFrom:   now,
+		ValidTo:     now.Add(time.Hour),
+		Status:      GuestStatusCancelled,
+	})
+	require.ErrorIs(t, err, ErrGuestTransition)
+}
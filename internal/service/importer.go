@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+var ErrImportHeader = errors.New("import file must have a header row with an email column")
+
+var importColumns = map[string]string{
+	"email":         "email",
+	"e-mail":        "email",
+	"почта":         "email",
+	"full_name":     "full_name",
+	"name":          "full_name",
+	"фио":           "full_name",
+	"role":          "role",
+	"роль":          "role",
+	"plot_number":   "plot_number",
+	"plot":          "plot_number",
+	"участок":       "plot_number",
+	"password":      "password",
+	"пароль":        "password",
+	"plate_number":  "plate_number",
+	"plate":         "plate_number",
+	"госномер":      "plate_number",
+	"vehicle_brand": "vehicle_brand",
+	"brand":         "vehicle_brand",
+	"марка":         "vehicle_brand",
+	"vehicle_color": "vehicle_color",
+	"color":         "vehicle_color",
+	"цвет":          "vehicle_color",
+}
+
+type ImportRow struct {
+	Line         int
+	Email        string
+	FullName     string
+	Role         string
+	PlotNumber   string
+	Password     string
+	PlateNumber  string
+	VehicleBrand string
+	VehicleColor string
+}
+
+type ImportRowError struct {
+	Line    int
+	Field   string
+	Message string
+}
+
+type ImportOptions struct {
+	DryRun  bool
+	ActorID uuid.UUID
+	// Restore lets a row bring back a deleted user with the same email;
+	// without it such rows fail validation.
+	Restore bool
+}
+
+type ImportReport struct {
+	DryRun        bool
+	Applied       bool
+	Rows          int
+	UsersCreated  int
+	UsersUpdated  int
+	UsersRestored int
+	PassesCreated int
+	PassesUpdated int
+	Errors        []ImportRowError
+}
+
+type importPass struct {
+	line   int
+	plate  string
+	brand  string
+	color  string
+	update bool
+}
+
+type importUser struct {
+	row          ImportRow
+	passwordHash string
+	existing     *repo.User
+	passes       []importPass
+}
+
+type importPlan struct {
+	users  []*importUser
+	errors []ImportRowError
+}
+
+func (p *importPlan) fail(line int, field, message string) {
+	p.errors = append(p.errors, ImportRowError{Line: line, Field: field, Message: message})
+}
+
+// ParseImportRows maps spreadsheet records to import rows using the header
+// row. Unknown columns are ignored, blank rows are skipped and emails are
+// lowercased so the same address matches whatever its spelling.
+func ParseImportRows(records [][]string) ([]ImportRow, error) {
+	if len(records) == 0 {
+		return nil, ErrImportHeader
+	}
+	columns := make(map[string]int)
+	for idx, name := range records[0] {
+		key, ok := importColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			continue
+		}
+		if _, seen := columns[key]; !seen {
+			columns[key] = idx
+		}
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, ErrImportHeader
+	}
+
+	rows := make([]ImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		cell := func(key string) string {
+			idx, ok := columns[key]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		row := ImportRow{
+			Line:         i + 2,
+			Email:        strings.ToLower(cell("email")),
+			FullName:     cell("full_name"),
+			Role:         strings.ToLower(cell("role")),
+			PlotNumber:   NormalizePlotNumber(cell("plot_number")),
+			Password:     cell("password"),
+			PlateNumber:  cell("plate_number"),
+			VehicleBrand: cell("vehicle_brand"),
+			VehicleColor: cell("vehicle_color"),
+		}
+		if row == (ImportRow{Line: row.Line}) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func buildImportPlan(rows []ImportRow) *importPlan {
+	plan := &importPlan{}
+	byEmail := make(map[string]*importUser)
+	for _, row := range rows {
+		if row.Email == "" || !strings.Contains(row.Email, "@") {
+			plan.fail(row.Line, "email", "invalid email")
+			continue
+		}
+		user, seen := byEmail[row.Email]
+		if !seen {
+			if row.Role == "" {
+				row.Role = "resident"
+			}
+			user = &importUser{row: row}
+			valid := true
+			if row.FullName == "" {
+				plan.fail(row.Line, "full_name", "required")
+				valid = false
+			}
+			if err := ValidateRole(row.Role); err != nil {
+				plan.fail(row.Line, "role", err.Error())
+				valid = false
+			}
+			if row.Role == "resident" && row.PlotNumber == "" {
+				plan.fail(row.Line, "plot_number", "required for residents")
+				valid = false
+			} else if row.PlotNumber != "" {
+				if err := ValidatePlotNumber(row.PlotNumber); err != nil {
+					plan.fail(row.Line, "plot_number", err.Error())
+					valid = false
+				}
+			}
+			byEmail[row.Email] = user
+			if valid {
+				plan.users = append(plan.users, user)
+			}
+		} else {
+			checkImportConflict(plan, user.row, row)
+		}
+
+		if row.PlateNumber == "" {
+			if row.VehicleBrand != "" || row.VehicleColor != "" {
+				plan.fail(row.Line, "plate_number", "required when vehicle data is set")
+			}
+			continue
+		}
+		if err := ValidatePlate(row.PlateNumber); err != nil {
+			plan.fail(row.Line, "plate_number", err.Error())
+			continue
+		}
+		plate := NormalizePlate(row.PlateNumber)
+		duplicate := false
+		for _, pass := range user.passes {
+			if pass.plate == plate {
+				plan.fail(row.Line, "plate_number", fmt.Sprintf("duplicate of line %d", pass.line))
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			user.passes = append(user.passes, importPass{
+				line:  row.Line,
+				plate: plate,
+				brand: row.VehicleBrand,
+				color: row.VehicleColor,
+			})
+		}
+	}
+	return plan
+}
+
+func checkImportConflict(plan *importPlan, first, row ImportRow) {
+	fields := []struct {
+		name          string
+		first, second string
+	}{
+		{"full_name", first.FullName, row.FullName},
+		{"role", first.Role, row.Role},
+		{"plot_number", first.PlotNumber, row.PlotNumber},
+		{"password", first.Password, row.Password},
+	}
+	for _, field := range fields {
+		if field.second != "" && field.second != field.first {
+			plan.fail(row.Line, field.name, fmt.Sprintf("conflicts with line %d", first.Line))
+		}
+	}
+}
+
+// resolveImport looks up existing users and passes so the report can tell
+// creates from updates. It never writes.
+func resolveImport(ctx context.Context, store ServiceStore, plan *importPlan, opts ImportOptions) error {
+	for _, user := range plan.users {
+		existing, err := store.GetUserByEmailAny(ctx, user.row.Email)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			user.existing = nil
+			if user.row.Password == "" {
+				plan.fail(user.row.Line, "password", "required for new users")
+			}
+			continue
+		case err != nil:
+			return err
+		}
+		user.existing = &existing
+		if existing.DeletedAt.Valid && !opts.Restore {
+			plan.fail(user.row.Line, "email", "belongs to a deleted user")
+		}
+		if existing.ID == opts.ActorID && existing.Role != user.row.Role {
+			plan.fail(user.row.Line, "role", "cannot change your own role")
+		}
+		for i := range user.passes {
+			_, err := store.GetPassByOwnerAndPlate(ctx, repo.GetPassByOwnerAndPlateParams{
+				OwnerUserID: existing.ID,
+				PlateNumber: user.passes[i].plate,
+			})
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				user.passes[i].update = false
+			case err != nil:
+				return err
+			default:
+				user.passes[i].update = true
+			}
+		}
+	}
+	return nil
+}
+
+// applyImport writes the resolved rows. A deleted user brought back by the
+// import gets the same restore cascade as RestoreUser when cascade is set,
+// before their passes are updated.
+func applyImport(ctx context.Context, store ServiceStore, plan *importPlan, actorID uuid.UUID, cascade bool) error {
+	actor := uuid.NullUUID{UUID: actorID, Valid: actorID != uuid.Nil}
+	for _, user := range plan.users {
+		plot := sql.NullString{String: user.row.PlotNumber, Valid: user.row.PlotNumber != ""}
+		if user.existing != nil {
+			if err := store.UnlinkPreviousUserPlot(ctx, repo.UnlinkPreviousUserPlotParams{UserID: user.existing.ID, PlotNumber: plot}); err != nil {
+				return fmt.Errorf("line %d: %w", user.row.Line, err)
+			}
+		}
+		saved, err := store.UpsertUserByEmail(ctx, repo.UpsertUserByEmailParams{
+			Email:           user.row.Email,
+			PasswordHash:    user.passwordHash,
+			Role:            user.row.Role,
+			FullName:        user.row.FullName,
+			PlotNumber:      plot,
+			ActorID:         actor,
+			ReplacePassword: user.passwordHash != "",
+		})
+		if err != nil {
+			return fmt.Errorf("line %d: %w", user.row.Line, err)
+		}
+		if cascade && user.existing != nil && user.existing.DeletedAt.Valid {
+			if _, err := restoreCascade(ctx, store, saved.ID, cascadeActionDelete, actor); err != nil {
+				return fmt.Errorf("line %d: %w", user.row.Line, err)
+			}
+		}
+		if err := attachUserPlot(ctx, store, saved, actor); err != nil {
+			return fmt.Errorf("line %d: %w", user.row.Line, err)
+		}
+		for _, pass := range user.passes {
+			existing, err := store.GetPassByOwnerAndPlate(ctx, repo.GetPassByOwnerAndPlateParams{
+				OwnerUserID: saved.ID,
+				PlateNumber: pass.plate,
+			})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("line %d: %w", pass.line, err)
+			}
+			if err == nil {
+				brand, color := existing.VehicleBrand, existing.VehicleColor
+				if pass.brand != "" {
+					brand = sql.NullString{String: pass.brand, Valid: true}
+				}
+				if pass.color != "" {
+					color = sql.NullString{String: pass.color, Valid: true}
+				}
+				_, err = store.UpdatePass(ctx, repo.UpdatePassParams{
+					ID:           existing.ID,
+					PlateNumber:  existing.PlateNumber,
+					VehicleBrand: brand,
+					VehicleColor: color,
+					Status:       existing.Status,
+					UpdatedBy:    actor,
+				})
+			} else {
+				_, err = store.CreatePass(ctx, repo.CreatePassParams{
+					OwnerUserID:  saved.ID,
+					PlateNumber:  pass.plate,
+					VehicleBrand: sql.NullString{String: pass.brand, Valid: pass.brand != ""},
+					VehicleColor: sql.NullString{String: pass.color, Valid: pass.color != ""},
+					Status:       "active",
+					CreatedBy:    actor,
+					UpdatedBy:    actor,
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("line %d: %w", pass.line, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *importPlan) report(rows int, opts ImportOptions) ImportReport {
+	report := ImportReport{DryRun: opts.DryRun, Rows: rows, Errors: p.errors}
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Line < report.Errors[j].Line })
+	for _, user := range p.users {
+		switch {
+		case user.existing == nil:
+			report.UsersCreated++
+		case user.existing.DeletedAt.Valid:
+			report.UsersRestored++
+		default:
+			report.UsersUpdated++
+		}
+		for _, pass := range user.passes {
+			if pass.update {
+				report.PassesUpdated++
+			} else {
+				report.PassesCreated++
+			}
+		}
+	}
+	return report
+}
+
+// ImportUsers validates rows and, unless DryRun is set, upserts users by
+// email together with their passes in a single transaction. Nothing is
+// written when any row fails validation. Deleted users are only brought
+// back with Restore and are counted apart from updates.
+func (s *Service) ImportUsers(ctx context.Context, rows []ImportRow, opts ImportOptions) (ImportReport, error) {
+	plan := buildImportPlan(rows)
+	if opts.DryRun || len(plan.errors) > 0 {
+		if err := resolveImport(ctx, s.q, plan, opts); err != nil {
+			return ImportReport{}, err
+		}
+		return plan.report(len(rows), opts), nil
+	}
+
+	for _, user := range plan.users {
+		if user.row.Password == "" {
+			continue
+		}
+		hash, err := auth.HashPassword(user.row.Password)
+		if err != nil {
+			return ImportReport{}, err
+		}
+		user.passwordHash = hash
+	}
+
+	applied := false
+	validated := len(plan.errors)
+	err := s.inTx(ctx, func(store ServiceStore) error {
+		// A retried transaction resolves the rows again from scratch.
+		plan.errors = plan.errors[:validated]
+		if err := resolveImport(ctx, store, plan, opts); err != nil {
+			return err
+		}
+		if len(plan.errors) > 0 {
+			return nil
+		}
+		applied = true
+		return applyImport(ctx, store, plan, opts.ActorID, s.settings.Cascade.Restore)
+	})
+	if err != nil {
+		return ImportReport{}, err
+	}
+	report := plan.report(len(rows), opts)
+	report.Applied = applied
+	return report, nil
+}
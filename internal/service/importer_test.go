@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+type fakeTxRunner struct {
+	calls int
+	err   error
+}
+
+func (r *fakeTxRunner) RunInTx(ctx context.Context, fn func(ServiceStore) error) error {
+	r.calls++
+	if r.err != nil {
+		return r.err
+	}
+	return fn(nil)
+}
+
+type storeTxRunner struct {
+	store ServiceStore
+	calls int
+}
+
+func (r *storeTxRunner) RunInTx(ctx context.Context, fn func(ServiceStore) error) error {
+	r.calls++
+	return fn(r.store)
+}
+
+func TestServiceUnit_ParseImportRows(t *testing.T) {
+	rows, err := ParseImportRows([][]string{
+		{"E-mail", "ФИО", "Роль", "Участок", "Пароль", "Госномер", "Марка", "Цвет", "Комментарий"},
+		{" A@Example.com ", "Иван", "Resident", "12", "secret", "а123вс77", "Lada", "White", "x"},
+		{"", "", "", "", "", "", "", "", ""},
+		{"b@example.com"},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, ImportRow{
+		Line:         2,
+		Email:        "a@example.com",
+		FullName:     "Иван",
+		Role:         "resident",
+		PlotNumber:   "12",
+		Password:     "secret",
+		PlateNumber:  "а123вс77",
+		VehicleBrand: "Lada",
+		VehicleColor: "White",
+	}, rows[0])
+	require.Equal(t, 4, rows[1].Line)
+
+	_, err = ParseImportRows(nil)
+	require.ErrorIs(t, err, ErrImportHeader)
+	_, err = ParseImportRows([][]string{{"name", "plate"}})
+	require.ErrorIs(t, err, ErrImportHeader)
+}
+
+func TestServiceUnit_ImportUsersValidation(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	existingID := uuid.New()
+	store := &mockStore{
+		getUserByEmailAnyFn: func(_ context.Context, email string) (repo.User, error) {
+			switch email {
+			case "old@example.com":
+				return repo.User{ID: existingID, Email: email, Role: "resident"}, nil
+			case "me@example.com":
+				return repo.User{ID: actorID, Email: email, Role: "admin"}, nil
+			}
+			return repo.User{}, sql.ErrNoRows
+		},
+		getPassByOwnerAndPlateFn: func(context.Context, repo.GetPassByOwnerAndPlateParams) (repo.Pass, error) {
+			return repo.Pass{}, sql.ErrNoRows
+		},
+	}
+	runner := &fakeTxRunner{}
+	svc := New(store, WithTxRunner(runner))
+
+	rows := []ImportRow{
+		{Line: 2, Email: "bad"},
+		{Line: 3, Email: "new@example.com", FullName: "New", PlotNumber: "1"},
+		{Line: 4, Email: "x@example.com", Role: "root", PlotNumber: "1"},
+		{Line: 5, Email: "old@example.com", FullName: "Old", PlotNumber: "2", PlateNumber: "bad"},
+		{Line: 6, Email: "old@example.com", FullName: "Other", VehicleBrand: "Lada"},
+		{Line: 7, Email: "old@example.com", PlateNumber: "A123BC77"},
+		{Line: 8, Email: "old@example.com", PlateNumber: "a123bc77"},
+		{Line: 9, Email: "me@example.com", FullName: "Me", PlotNumber: "3"},
+		{Line: 10, Email: "guard@example.com", FullName: "Guard", Role: "guard", Password: "pwd"},
+		{Line: 11, Email: "river@example.com", FullName: "River", PlotNumber: "дом у реки", Password: "pwd"},
+	}
+	report, err := svc.ImportUsers(ctx, rows, ImportOptions{ActorID: actorID})
+	require.NoError(t, err)
+	require.False(t, report.Applied)
+	require.Zero(t, runner.calls)
+	require.Equal(t, 10, report.Rows)
+
+	got := map[int][]string{}
+	for _, rowErr := range report.Errors {
+		got[rowErr.Line] = append(got[rowErr.Line], rowErr.Field)
+	}
+	require.Equal(t, map[int][]string{
+		2:  {"email"},
+		3:  {"password"},
+		4:  {"full_name", "role"},
+		5:  {"plate_number"},
+		6:  {"full_name", "plate_number"},
+		8:  {"plate_number"},
+		9:  {"role"},
+		11: {"plot_number"},
+	}, got)
+	for i := 1; i < len(report.Errors); i++ {
+		require.LessOrEqual(t, report.Errors[i-1].Line, report.Errors[i].Line)
+	}
+}
+
+func TestServiceUnit_ImportUsersDryRunAndApply(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	existingID := uuid.New()
+	existingPassID := uuid.New()
+	var upserts []repo.UpsertUserByEmailParams
+	var created []repo.CreatePassParams
+	var updated []repo.UpdatePassParams
+
+	store := &mockStore{
+		getUserByEmailAnyFn: func(_ context.Context, email string) (repo.User, error) {
+			if email == "old@example.com" {
+				return repo.User{ID: existingID, Email: email, Role: "resident"}, nil
+			}
+			return repo.User{}, sql.ErrNoRows
+		},
+		getPassByOwnerAndPlateFn: func(_ context.Context, arg repo.GetPassByOwnerAndPlateParams) (repo.Pass, error) {
+			if arg.OwnerUserID == existingID && arg.PlateNumber == "A123BC77" {
+				return repo.Pass{
+					ID:           existingPassID,
+					OwnerUserID:  existingID,
+					PlateNumber:  arg.PlateNumber,
+					VehicleBrand: sql.NullString{String: "Lada", Valid: true},
+					Status:       "inactive",
+				}, nil
+			}
+			return repo.Pass{}, sql.ErrNoRows
+		},
+		upsertUserByEmailFn: func(_ context.Context, arg repo.UpsertUserByEmailParams) (repo.User, error) {
+			upserts = append(upserts, arg)
+			if arg.Email == "old@example.com" {
+				return repo.User{ID: existingID, Email: arg.Email}, nil
+			}
+			return repo.User{ID: uuid.New(), Email: arg.Email}, nil
+		},
+		createPassFn: func(_ context.Context, arg repo.CreatePassParams) (repo.Pass, error) {
+			created = append(created, arg)
+			return repo.Pass{ID: uuid.New()}, nil
+		},
+		updatePassFn: func(_ context.Context, arg repo.UpdatePassParams) (repo.Pass, error) {
+			updated = append(updated, arg)
+			return repo.Pass{ID: arg.ID}, nil
+		},
+	}
+	runner := &storeTxRunner{store: store}
+	svc := New(store, WithTxRunner(runner))
+
+	rows := []ImportRow{
+		{Line: 2, Email: "old@example.com", FullName: "Old", PlotNumber: "2", PlateNumber: "A123BC77", VehicleColor: "Black"},
+		{Line: 3, Email: "old@example.com", PlateNumber: "M777MM77"},
+		{Line: 4, Email: "new@example.com", FullName: "New", PlotNumber: "5", Password: "secret", PlateNumber: "B456CE99", VehicleBrand: "Kia"},
+	}
+
+	report, err := svc.ImportUsers(ctx, rows, ImportOptions{DryRun: true, ActorID: actorID})
+	require.NoError(t, err)
+	require.Equal(t, ImportReport{
+		DryRun:        true,
+		Rows:          3,
+		UsersCreated:  1,
+		UsersUpdated:  1,
+		PassesCreated: 2,
+		PassesUpdated: 1,
+	}, report)
+	require.Zero(t, runner.calls)
+	require.Empty(t, upserts)
+
+	report, err = svc.ImportUsers(ctx, rows, ImportOptions{ActorID: actorID})
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	require.Equal(t, 1, runner.calls)
+	require.Equal(t, 1, report.UsersCreated)
+	require.Equal(t, 1, report.PassesUpdated)
+
+	require.Len(t, upserts, 2)
+	require.False(t, upserts[0].ReplacePassword)
+	require.Equal(t, "resident", upserts[0].Role)
+	require.True(t, upserts[1].ReplacePassword)
+	require.NotEmpty(t, upserts[1].PasswordHash)
+	require.Equal(t, actorID, upserts[1].ActorID.UUID)
+
+	require.Len(t, updated, 1)
+	require.Equal(t, existingPassID, updated[0].ID)
+	require.Equal(t, "inactive", updated[0].Status)
+	require.Equal(t, "Lada", updated[0].VehicleBrand.String)
+	require.Equal(t, "Black", updated[0].VehicleColor.String)
+
+	require.Len(t, created, 2)
+	require.Equal(t, "M777MM77", created[0].PlateNumber)
+	require.Equal(t, "active", created[1].Status)
+	require.Equal(t, "Kia", created[1].VehicleBrand.String)
+}
+
+func TestServiceUnit_ImportUsersRestore(t *testing.T) {
+	ctx := context.Background()
+	deletedID, actorID := uuid.New(), uuid.New()
+	var (
+		upserts  []repo.UpsertUserByEmailParams
+		restored []repo.RestoreCascadePassesParams
+	)
+	store := &mockStore{
+		getUserByEmailAnyFn: func(_ context.Context, email string) (repo.User, error) {
+			if email == "gone@example.com" {
+				return repo.User{ID: deletedID, Email: email, Role: "resident", DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil
+			}
+			return repo.User{}, sql.ErrNoRows
+		},
+		upsertUserByEmailFn: func(_ context.Context, arg repo.UpsertUserByEmailParams) (repo.User, error) {
+			upserts = append(upserts, arg)
+			return repo.User{ID: deletedID, Email: arg.Email}, nil
+		},
+		restoreCascadePassesFn: func(_ context.Context, arg repo.RestoreCascadePassesParams) ([]uuid.UUID, error) {
+			restored = append(restored, arg)
+			return nil, nil
+		},
+		restoreCascadeGuestRequestsFn: func(_ context.Context, arg repo.RestoreCascadeGuestRequestsParams) ([]uuid.UUID, error) {
+			return nil, nil
+		},
+	}
+	runner := &storeTxRunner{store: store}
+	svc := New(store, WithTxRunner(runner), WithSettings(Settings{Cascade: CascadeRules{Restore: true}}))
+	rows := []ImportRow{{Line: 2, Email: "gone@example.com", FullName: "Gone", PlotNumber: "4"}}
+
+	// A deleted user is not brought back by accident.
+	report, err := svc.ImportUsers(ctx, rows, ImportOptions{})
+	require.NoError(t, err)
+	require.False(t, report.Applied)
+	require.Equal(t, []ImportRowError{{Line: 2, Field: "email", Message: "belongs to a deleted user"}}, report.Errors)
+	require.Empty(t, upserts)
+
+	report, err = svc.ImportUsers(ctx, rows, ImportOptions{DryRun: true, Restore: true})
+	require.NoError(t, err)
+	require.Empty(t, report.Errors)
+	require.Equal(t, 1, report.UsersRestored)
+	require.Zero(t, report.UsersUpdated)
+
+	require.Empty(t, restored)
+
+	// Restoring by import brings back what the delete cascade took away.
+	report, err = svc.ImportUsers(ctx, rows, ImportOptions{Restore: true, ActorID: actorID})
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	require.Equal(t, 1, report.UsersRestored)
+	require.Len(t, upserts, 1)
+	require.Equal(t, []repo.RestoreCascadePassesParams{{UserID: deletedID, Action: cascadeActionDelete, ActorID: uuid.NullUUID{UUID: actorID, Valid: true}}}, restored)
+}
+
+func TestServiceUnit_ImportUsersErrors(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repo failed")
+	rows := []ImportRow{{Line: 2, Email: "a@example.com", FullName: "A", PlotNumber: "1", Password: "pwd", PlateNumber: "A123BC77"}}
+	notFoundUser := func(context.Context, string) (repo.User, error) { return repo.User{}, sql.ErrNoRows }
+
+	t.Run("lookup error", func(t *testing.T) {
+		svc := New(&mockStore{
+			getUserByEmailAnyFn: func(context.Context, string) (repo.User, error) { return repo.User{}, repoErr },
+		})
+		_, err := svc.ImportUsers(ctx, rows, ImportOptions{DryRun: true})
+		require.ErrorIs(t, err, repoErr)
+		_, err = svc.ImportUsers(ctx, rows, ImportOptions{})
+		require.ErrorIs(t, err, repoErr)
+	})
+
+	t.Run("pass lookup error", func(t *testing.T) {
+		svc := New(&mockStore{
+			getUserByEmailAnyFn: func(_ context.Context, email string) (repo.User, error) {
+				return repo.User{ID: uuid.New(), Email: email, Role: "resident"}, nil
+			},
+			getPassByOwnerAndPlateFn: func(context.Context, repo.GetPassByOwnerAndPlateParams) (repo.Pass, error) {
+				return repo.Pass{}, repoErr
+			},
+		})
+		_, err := svc.ImportUsers(ctx, rows, ImportOptions{DryRun: true})
+		require.ErrorIs(t, err, repoErr)
+	})
+
+	t.Run("transaction error", func(t *testing.T) {
+		svc := New(&mockStore{getUserByEmailAnyFn: notFoundUser}, WithTxRunner(&fakeTxRunner{err: repoErr}))
+		_, err := svc.ImportUsers(ctx, rows, ImportOptions{})
+		require.ErrorIs(t, err, repoErr)
+	})
+
+	t.Run("upsert error", func(t *testing.T) {
+		svc := New(&mockStore{
+			getUserByEmailAnyFn: notFoundUser,
+			upsertUserByEmailFn: func(context.Context, repo.UpsertUserByEmailParams) (repo.User, error) {
+				return repo.User{}, repoErr
+			},
+		})
+		_, err := svc.ImportUsers(ctx, rows, ImportOptions{})
+		require.ErrorIs(t, err, repoErr)
+	})
+
+	t.Run("pass write errors", func(t *testing.T) {
+		store := &mockStore{
+			getUserByEmailAnyFn: notFoundUser,
+			upsertUserByEmailFn: func(context.Context, repo.UpsertUserByEmailParams) (repo.User, error) {
+				return repo.User{ID: uuid.New()}, nil
+			},
+			getPassByOwnerAndPlateFn: func(context.Context, repo.GetPassByOwnerAndPlateParams) (repo.Pass, error) {
+				return repo.Pass{}, repoErr
+			},
+		}
+		_, err := New(store).ImportUsers(ctx, rows, ImportOptions{})
+		require.ErrorIs(t, err, repoErr)
+
+		store.getPassByOwnerAndPlateFn = func(context.Context, repo.GetPassByOwnerAndPlateParams) (repo.Pass, error) {
+			return repo.Pass{}, sql.ErrNoRows
+		}
+		store.createPassFn = func(context.Context, repo.CreatePassParams) (repo.Pass, error) { return repo.Pass{}, repoErr }
+		_, err = New(store).ImportUsers(ctx, rows, ImportOptions{})
+		require.ErrorIs(t, err, repoErr)
+	})
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/storage"
+)
+
+const (
+	IncidentCategoryBarrierDamage     = "barrier_damage"
+	IncidentCategoryAggressiveVisitor = "aggressive_visitor"
+	IncidentCategoryUnknownVehicle    = "unknown_vehicle"
+	IncidentCategoryPropertyDamage    = "property_damage"
+	IncidentCategoryOther             = "other"
+
+	IncidentSeverityLow      = "low"
+	IncidentSeverityMedium   = "medium"
+	IncidentSeverityHigh     = "high"
+	IncidentSeverityCritical = "critical"
+
+	IncidentStatusOpen       = "open"
+	IncidentStatusInProgress = "in_progress"
+	IncidentStatusResolved   = "resolved"
+	IncidentStatusDismissed  = "dismissed"
+)
+
+var (
+	ErrInvalidIncidentCategory = errors.New("invalid incident category")
+	ErrInvalidIncidentSeverity = errors.New("invalid incident severity")
+	ErrInvalidIncidentStatus   = errors.New("invalid incident status")
+	ErrIncidentTransition      = errors.New("incident cannot move to this status")
+	ErrIncidentLink            = errors.New("linked pass or guest request not found")
+)
+
+// incidentTransitions lists the statuses each status can move to. Closed
+// incidents can only be reopened.
+var incidentTransitions = map[string][]string{
+	IncidentStatusOpen:       {IncidentStatusInProgress, IncidentStatusResolved, IncidentStatusDismissed},
+	IncidentStatusInProgress: {IncidentStatusOpen, IncidentStatusResolved, IncidentStatusDismissed},
+	IncidentStatusResolved:   {IncidentStatusOpen},
+	IncidentStatusDismissed:  {IncidentStatusOpen},
+}
+
+// IncidentInput is a report filed by a guard or an admin. The pass, guest
+// request and gate are optional; OccurredAt defaults to now.
+type IncidentInput struct {
+	ReporterID  uuid.UUID
+	Category    string
+	Severity    string
+	Title       string
+	Description string
+	OccurredAt  time.Time
+	GateID      uuid.UUID
+	PassID      uuid.UUID
+	GuestID     uuid.UUID
+	PlateNumber string
+}
+
+type IncidentFilter struct {
+	Status     string
+	Category   string
+	Severity   string
+	ReporterID uuid.UUID
+	Plate      string
+	From       time.Time
+	To         time.Time
+}
+
+type IncidentStatusInput struct {
+	ID      uuid.UUID
+	Status  string
+	Comment string
+	ActorID uuid.UUID
+}
+
+type IncidentCommentInput struct {
+	IncidentID uuid.UUID
+	AuthorID   uuid.UUID
+	Body       string
+}
+
+type AttachmentInput struct {
+	IncidentID uuid.UUID
+	ActorID    uuid.UUID
+	FileName   string
+	Data       []byte
+}
+
+// IncidentDetails is an incident with its comment thread and attachments.
+type IncidentDetails struct {
+	repo.Incident
+	Comments    []repo.ListIncidentCommentsRow
+	Attachments []repo.IncidentAttachment
+}
+
+func ValidateIncidentCategory(category string) error {
+	switch category {
+	case IncidentCategoryBarrierDamage, IncidentCategoryAggressiveVisitor, IncidentCategoryUnknownVehicle,
+		IncidentCategoryPropertyDamage, IncidentCategoryOther:
+		return nil
+	default:
+		return ErrInvalidIncidentCategory
+	}
+}
+
+func ValidateIncidentSeverity(severity string) error {
+	switch severity {
+	case IncidentSeverityLow, IncidentSeverityMedium, IncidentSeverityHigh, IncidentSeverityCritical:
+		return nil
+	default:
+		return ErrInvalidIncidentSeverity
+	}
+}
+
+func ValidateIncidentStatus(status string) error {
+	if _, ok := incidentTransitions[status]; !ok {
+		return ErrInvalidIncidentStatus
+	}
+	return nil
+}
+
+// CreateIncident files a report. A linked pass or guest request fills in the
+// plate when none is given, and the reporter's open shift supplies the shift
+// and, when omitted, the gate. Plates are normalized but not validated, since
+// an unknown car may carry a foreign plate.
+func (s *Service) CreateIncident(ctx context.Context, input IncidentInput) (repo.Incident, error) {
+	if err := ValidateIncidentCategory(input.Category); err != nil {
+		return repo.Incident{}, err
+	}
+	if err := ValidateIncidentSeverity(input.Severity); err != nil {
+		return repo.Incident{}, err
+	}
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return repo.Incident{}, ErrInvalidInput
+	}
+	occurredAt := input.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = s.now()
+	}
+	if occurredAt.After(s.now()) {
+		return repo.Incident{}, ErrInvalidRange
+	}
+	plate := NormalizePlate(input.PlateNumber)
+	if input.PassID != uuid.Nil {
+		pass, err := s.q.GetPassByIDAny(ctx, input.PassID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.Incident{}, ErrIncidentLink
+		}
+		if err != nil {
+			return repo.Incident{}, err
+		}
+		if plate == "" {
+			plate = pass.PlateNumber
+		}
+	}
+	if input.GuestID != uuid.Nil {
+		guest, err := s.q.GetGuestRequestByIDAny(ctx, input.GuestID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.Incident{}, ErrIncidentLink
+		}
+		if err != nil {
+			return repo.Incident{}, err
+		}
+		if plate == "" {
+			plate = guest.PlateNumber
+		}
+	}
+	shift, err := s.q.GetOpenGuardShift(ctx, input.ReporterID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return repo.Incident{}, err
+	}
+	gateID := shift.GateID
+	if input.GateID != uuid.Nil {
+		if _, err := s.q.GetGate(ctx, input.GateID); errors.Is(err, sql.ErrNoRows) {
+			return repo.Incident{}, ErrUnknownGate
+		} else if err != nil {
+			return repo.Incident{}, err
+		}
+		gateID = uuid.NullUUID{UUID: input.GateID, Valid: true}
+	}
+	description := strings.TrimSpace(input.Description)
+	return s.q.CreateIncident(ctx, repo.CreateIncidentParams{
+		Category:       input.Category,
+		Severity:       input.Severity,
+		Title:          title,
+		Description:    sql.NullString{String: description, Valid: description != ""},
+		OccurredAt:     occurredAt,
+		ReportedBy:     input.ReporterID,
+		GateID:         gateID,
+		ShiftID:        uuid.NullUUID{UUID: shift.ID, Valid: shift.ID != uuid.Nil},
+		PassID:         uuid.NullUUID{UUID: input.PassID, Valid: input.PassID != uuid.Nil},
+		GuestRequestID: uuid.NullUUID{UUID: input.GuestID, Valid: input.GuestID != uuid.Nil},
+		PlateNumber:    sql.NullString{String: plate, Valid: plate != ""},
+	})
+}
+
+func (s *Service) GetIncident(ctx context.Context, id uuid.UUID) (IncidentDetails, error) {
+	incident, err := s.getIncident(ctx, s.q, id)
+	if err != nil {
+		return IncidentDetails{}, err
+	}
+	details := IncidentDetails{Incident: incident}
+	if details.Comments, err = s.q.ListIncidentComments(ctx, id); err != nil {
+		return IncidentDetails{}, err
+	}
+	if details.Attachments, err = s.q.ListIncidentAttachments(ctx, id); err != nil {
+		return IncidentDetails{}, err
+	}
+	return details, nil
+}
+
+func (s *Service) ListIncidents(ctx context.Context, filter IncidentFilter, limit, offset int32) ([]repo.ListIncidentsRow, error) {
+	if filter.Status != "" {
+		if err := ValidateIncidentStatus(filter.Status); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Category != "" {
+		if err := ValidateIncidentCategory(filter.Category); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Severity != "" {
+		if err := ValidateIncidentSeverity(filter.Severity); err != nil {
+			return nil, err
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, ErrInvalidRange
+	}
+	plate := NormalizePlate(filter.Plate)
+	return s.q.ListIncidents(ctx, repo.ListIncidentsParams{
+		Status:      sql.NullString{String: filter.Status, Valid: filter.Status != ""},
+		Category:    sql.NullString{String: filter.Category, Valid: filter.Category != ""},
+		Severity:    sql.NullString{String: filter.Severity, Valid: filter.Severity != ""},
+		ReportedBy:  uuid.NullUUID{UUID: filter.ReporterID, Valid: filter.ReporterID != uuid.Nil},
+		PlateNumber: sql.NullString{String: plate, Valid: plate != ""},
+		FromTime:    sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()},
+		ToTime:      sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()},
+		PageSize:    limit,
+		PageOffset:  offset,
+	})
+}
+
+// ChangeIncidentStatus moves an incident along the workflow and records the
+// change, with the optional comment, in its thread.
+func (s *Service) ChangeIncidentStatus(ctx context.Context, input IncidentStatusInput) (repo.Incident, error) {
+	if err := ValidateIncidentStatus(input.Status); err != nil {
+		return repo.Incident{}, err
+	}
+	var updated repo.Incident
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		incident, err := s.getIncident(ctx, q, input.ID)
+		if err != nil {
+			return err
+		}
+		allowed := false
+		for _, next := range incidentTransitions[incident.Status] {
+			allowed = allowed || next == input.Status
+		}
+		if !allowed {
+			return ErrIncidentTransition
+		}
+		var resolvedAt sql.NullTime
+		if input.Status == IncidentStatusResolved || input.Status == IncidentStatusDismissed {
+			resolvedAt = sql.NullTime{Time: s.now(), Valid: true}
+		}
+		updated, err = q.UpdateIncidentStatus(ctx, repo.UpdateIncidentStatusParams{
+			Status:        input.Status,
+			ResolvedAt:    resolvedAt,
+			UpdatedBy:     uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+			ID:            incident.ID,
+			CurrentStatus: incident.Status,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Someone else changed the status since it was read.
+			return ErrIncidentTransition
+		}
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateIncidentComment(ctx, repo.CreateIncidentCommentParams{
+			IncidentID: incident.ID,
+			AuthorID:   input.ActorID,
+			Body:       strings.TrimSpace(input.Comment),
+			StatusFrom: sql.NullString{String: incident.Status, Valid: true},
+			StatusTo:   sql.NullString{String: input.Status, Valid: true},
+		})
+		return err
+	})
+	return updated, err
+}
+
+func (s *Service) AddIncidentComment(ctx context.Context, input IncidentCommentInput) (repo.IncidentComment, error) {
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return repo.IncidentComment{}, ErrInvalidInput
+	}
+	if _, err := s.getIncident(ctx, s.q, input.IncidentID); err != nil {
+		return repo.IncidentComment{}, err
+	}
+	return s.q.CreateIncidentComment(ctx, repo.CreateIncidentCommentParams{
+		IncidentID: input.IncidentID,
+		AuthorID:   input.AuthorID,
+		Body:       body,
+	})
+}
+
+// AddIncidentAttachment stores a file and then its record; the file is removed
+// again when the record cannot be saved.
+func (s *Service) AddIncidentAttachment(ctx context.Context, input AttachmentInput) (repo.IncidentAttachment, error) {
+	contentType, err := s.checkUpload(input.Data)
+	if err != nil {
+		return repo.IncidentAttachment{}, err
+	}
+	if _, err := s.getIncident(ctx, s.q, input.IncidentID); err != nil {
+		return repo.IncidentAttachment{}, err
+	}
+	id := uuid.New()
+	key := "incidents/" + input.IncidentID.String() + "/" + id.String()
+	if err := s.files.Put(ctx, key, bytes.NewReader(input.Data)); err != nil {
+		return repo.IncidentAttachment{}, err
+	}
+	attachment, err := s.q.CreateIncidentAttachment(ctx, repo.CreateIncidentAttachmentParams{
+		ID:          id,
+		IncidentID:  input.IncidentID,
+		FileName:    cleanFileName(input.FileName),
+		ContentType: contentType,
+		SizeBytes:   int64(len(input.Data)),
+		StorageKey:  key,
+		UploadedBy:  input.ActorID,
+	})
+	if err != nil {
+		_ = s.files.Delete(ctx, key)
+		return repo.IncidentAttachment{}, err
+	}
+	return attachment, nil
+}
+
+// OpenIncidentAttachment returns an attachment with its content; the caller
+// closes the reader.
+func (s *Service) OpenIncidentAttachment(ctx context.Context, id uuid.UUID) (repo.IncidentAttachment, io.ReadCloser, error) {
+	if s.files == nil {
+		return repo.IncidentAttachment{}, nil, ErrFilesDisabled
+	}
+	attachment, err := s.q.GetIncidentAttachment(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.IncidentAttachment{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return repo.IncidentAttachment{}, nil, err
+	}
+	content, err := s.files.Open(ctx, attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return repo.IncidentAttachment{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return repo.IncidentAttachment{}, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (s *Service) DeleteIncidentAttachment(ctx context.Context, incidentID, id uuid.UUID) error {
+	if s.files == nil {
+		return ErrFilesDisabled
+	}
+	attachment, err := s.q.GetIncidentAttachment(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && attachment.IncidentID != incidentID) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := s.q.DeleteIncidentAttachment(ctx, id); err != nil {
+		return err
+	}
+	return s.files.Delete(ctx, attachment.StorageKey)
+}
+
+func (s *Service) getIncident(ctx context.Context, q ServiceStore, id uuid.UUID) (repo.Incident, error) {
+	incident, err := q.GetIncident(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Incident{}, ErrNotFound
+	}
+	return incident, err
+}
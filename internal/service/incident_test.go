@@ -0,0 +1,313 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/storage"
+)
+
+// memFiles is an in-memory storage.Store.
+type memFiles map[string][]byte
+
+func (m memFiles) Put(_ context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	m[key] = data
+	return err
+}
+
+func (m memFiles) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m memFiles) Delete(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestServiceUnit_IncidentCreate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 22, 0, 0, 0, time.UTC)
+	guardID, passID, guestID, gateID, shiftID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	var created repo.CreateIncidentParams
+	store := &mockStore{
+		getPassByIDAnyFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			if id != passID {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return repo.Pass{ID: id, PlateNumber: "А123ВС77"}, nil
+		},
+		getGuestRequestByIDAnyFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+			return repo.GuestRequest{ID: id, PlateNumber: "В456ОР99"}, nil
+		},
+		getOpenGuardShiftFn: func(_ context.Context, id uuid.UUID) (repo.GuardShift, error) {
+			if id != guardID {
+				return repo.GuardShift{}, sql.ErrNoRows
+			}
+			return repo.GuardShift{ID: shiftID, GateID: uuid.NullUUID{UUID: gateID, Valid: true}}, nil
+		},
+		getGateFn: func(_ context.Context, id uuid.UUID) (repo.Gate, error) {
+			return repo.Gate{}, sql.ErrNoRows
+		},
+		createIncidentFn: func(_ context.Context, arg repo.CreateIncidentParams) (repo.Incident, error) {
+			created = arg
+			return repo.Incident{ID: uuid.New(), Status: IncidentStatusOpen, PlateNumber: arg.PlateNumber}, nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+	valid := IncidentInput{ReporterID: guardID, Category: IncidentCategoryBarrierDamage, Severity: IncidentSeverityHigh, Title: "Шлагбаум сбит"}
+
+	bad := valid
+	bad.Category = "fire"
+	_, err := svc.CreateIncident(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidIncidentCategory)
+	bad = valid
+	bad.Severity = "urgent"
+	_, err = svc.CreateIncident(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidIncidentSeverity)
+	bad = valid
+	bad.Title = "  "
+	_, err = svc.CreateIncident(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidInput)
+	bad = valid
+	bad.OccurredAt = now.Add(time.Hour)
+	_, err = svc.CreateIncident(ctx, bad)
+	require.ErrorIs(t, err, ErrInvalidRange)
+	bad = valid
+	bad.PassID = uuid.New()
+	_, err = svc.CreateIncident(ctx, bad)
+	require.ErrorIs(t, err, ErrIncidentLink)
+	bad = valid
+	bad.GateID = uuid.New()
+	_, err = svc.CreateIncident(ctx, bad)
+	require.ErrorIs(t, err, ErrUnknownGate)
+
+	input := valid
+	input.PassID = passID
+	input.Description = "  задний ход  "
+	incident, err := svc.CreateIncident(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, IncidentStatusOpen, incident.Status)
+	require.Equal(t, "А123ВС77", created.PlateNumber.String)
+	require.Equal(t, now, created.OccurredAt)
+	require.Equal(t, "задний ход", created.Description.String)
+	require.Equal(t, shiftID, created.ShiftID.UUID)
+	require.Equal(t, gateID, created.GateID.UUID)
+
+	input = IncidentInput{ReporterID: uuid.New(), Category: IncidentCategoryUnknownVehicle, Severity: IncidentSeverityLow, Title: "Чужая машина", GuestID: guestID, PlateNumber: " ab1234 "}
+	_, err = svc.CreateIncident(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, "AB1234", created.PlateNumber.String)
+	require.Equal(t, guestID, created.GuestRequestID.UUID)
+	require.False(t, created.ShiftID.Valid)
+	require.False(t, created.GateID.Valid)
+
+	for _, filter := range []IncidentFilter{{Status: "closed"}, {Category: "fire"}, {Severity: "urgent"}, {From: now, To: now}} {
+		_, err := svc.ListIncidents(ctx, filter, 10, 0)
+		require.Error(t, err)
+	}
+	store.listIncidentsFn = func(_ context.Context, arg repo.ListIncidentsParams) ([]repo.ListIncidentsRow, error) {
+		require.Equal(t, "A123BC77", arg.PlateNumber.String)
+		require.Equal(t, IncidentStatusOpen, arg.Status.String)
+		return []repo.ListIncidentsRow{{ID: uuid.New()}}, nil
+	}
+	rows, err := svc.ListIncidents(ctx, IncidentFilter{Status: IncidentStatusOpen, Plate: "a123bc77"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+}
+
+func TestServiceUnit_IncidentWorkflow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC)
+	adminID := uuid.New()
+	incident := repo.Incident{ID: uuid.New(), Status: IncidentStatusOpen}
+	var comments []repo.CreateIncidentCommentParams
+	store := &mockStore{
+		getIncidentFn: func(_ context.Context, id uuid.UUID) (repo.Incident, error) {
+			if id != incident.ID {
+				return repo.Incident{}, sql.ErrNoRows
+			}
+			return incident, nil
+		},
+		updateIncidentStatusFn: func(_ context.Context, arg repo.UpdateIncidentStatusParams) (repo.Incident, error) {
+			if arg.CurrentStatus != incident.Status {
+				return repo.Incident{}, sql.ErrNoRows
+			}
+			incident.Status, incident.ResolvedAt = arg.Status, arg.ResolvedAt
+			return incident, nil
+		},
+		createIncidentCommentFn: func(_ context.Context, arg repo.CreateIncidentCommentParams) (repo.IncidentComment, error) {
+			comments = append(comments, arg)
+			return repo.IncidentComment{ID: uuid.New(), IncidentID: arg.IncidentID, Body: arg.Body}, nil
+		},
+		listIncidentCommentsFn: func(_ context.Context, id uuid.UUID) ([]repo.ListIncidentCommentsRow, error) {
+			return make([]repo.ListIncidentCommentsRow, len(comments)), nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+	change := func(status string) (repo.Incident, error) {
+		return svc.ChangeIncidentStatus(ctx, IncidentStatusInput{ID: incident.ID, Status: status, Comment: " вызвали мастера ", ActorID: adminID})
+	}
+
+	_, err := change("closed")
+	require.ErrorIs(t, err, ErrInvalidIncidentStatus)
+	_, err = svc.ChangeIncidentStatus(ctx, IncidentStatusInput{ID: uuid.New(), Status: IncidentStatusResolved})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = change(IncidentStatusOpen)
+	require.ErrorIs(t, err, ErrIncidentTransition)
+
+	updated, err := change(IncidentStatusInProgress)
+	require.NoError(t, err)
+	require.Equal(t, IncidentStatusInProgress, updated.Status)
+	require.False(t, updated.ResolvedAt.Valid)
+	updated, err = change(IncidentStatusResolved)
+	require.NoError(t, err)
+	require.Equal(t, now, updated.ResolvedAt.Time)
+	_, err = change(IncidentStatusDismissed)
+	require.ErrorIs(t, err, ErrIncidentTransition)
+	updated, err = change(IncidentStatusOpen)
+	require.NoError(t, err)
+	require.False(t, updated.ResolvedAt.Valid)
+	require.Len(t, comments, 3)
+	require.Equal(t, "вызвали мастера", comments[0].Body)
+	require.Equal(t, IncidentStatusOpen, comments[0].StatusFrom.String)
+	require.Equal(t, IncidentStatusInProgress, comments[0].StatusTo.String)
+
+	// A status changed by someone else between the read and the update.
+	store.updateIncidentStatusFn = func(context.Context, repo.UpdateIncidentStatusParams) (repo.Incident, error) {
+		return repo.Incident{}, sql.ErrNoRows
+	}
+	_, err = change(IncidentStatusResolved)
+	require.ErrorIs(t, err, ErrIncidentTransition)
+
+	_, err = svc.AddIncidentComment(ctx, IncidentCommentInput{IncidentID: incident.ID, AuthorID: adminID, Body: " "})
+	require.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.AddIncidentComment(ctx, IncidentCommentInput{IncidentID: uuid.New(), AuthorID: adminID, Body: "?"})
+	require.ErrorIs(t, err, ErrNotFound)
+	comment, err := svc.AddIncidentComment(ctx, IncidentCommentInput{IncidentID: incident.ID, AuthorID: adminID, Body: "Передано в УК"})
+	require.NoError(t, err)
+	require.Equal(t, "Передано в УК", comment.Body)
+	require.False(t, comments[len(comments)-1].StatusTo.Valid)
+
+	details, err := svc.GetIncident(ctx, incident.ID)
+	require.NoError(t, err)
+	require.Len(t, details.Comments, 4)
+	_, err = svc.GetIncident(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestServiceUnit_IncidentAttachments(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC)
+	incidentID, guardID := uuid.New(), uuid.New()
+	attachments := map[uuid.UUID]repo.IncidentAttachment{}
+	store := &mockStore{
+		getIncidentFn: func(_ context.Context, id uuid.UUID) (repo.Incident, error) {
+			if id != incidentID {
+				return repo.Incident{}, sql.ErrNoRows
+			}
+			return repo.Incident{ID: id}, nil
+		},
+		createIncidentAttachmentFn: func(_ context.Context, arg repo.CreateIncidentAttachmentParams) (repo.IncidentAttachment, error) {
+			attachment := repo.IncidentAttachment{ID: arg.ID, IncidentID: arg.IncidentID, FileName: arg.FileName, ContentType: arg.ContentType, SizeBytes: arg.SizeBytes, StorageKey: arg.StorageKey}
+			attachments[arg.ID] = attachment
+			return attachment, nil
+		},
+		getIncidentAttachmentFn: func(_ context.Context, id uuid.UUID) (repo.IncidentAttachment, error) {
+			attachment, ok := attachments[id]
+			if !ok {
+				return repo.IncidentAttachment{}, sql.ErrNoRows
+			}
+			return attachment, nil
+		},
+		deleteIncidentAttachmentFn: func(_ context.Context, id uuid.UUID) (int64, error) {
+			delete(attachments, id)
+			return 1, nil
+		},
+	}
+	photo := append(append([]byte{}, pngHeader...), make([]byte, 64)...)
+	upload := AttachmentInput{IncidentID: incidentID, ActorID: guardID, FileName: `C:\photos\"barrier".png`, Data: photo}
+
+	disabled := New(store)
+	_, err := disabled.AddIncidentAttachment(ctx, upload)
+	require.ErrorIs(t, err, ErrFilesDisabled)
+	_, _, err = disabled.OpenIncidentAttachment(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrFilesDisabled)
+
+	files := memFiles{}
+	svc := New(store, WithFileStore(files), WithClock(func() time.Time { return now }),
+		WithSettings(Settings{Attachments: AttachmentRules{MaxBytes: 100, LinkSecret: []byte("secret")}}))
+	tooBig := upload
+	tooBig.Data = append(append([]byte{}, pngHeader...), make([]byte, 200)...)
+	_, err = svc.AddIncidentAttachment(ctx, tooBig)
+	require.ErrorIs(t, err, ErrAttachmentTooLarge)
+	script := upload
+	script.Data = []byte("<html><script>alert(1)</script></html>")
+	_, err = svc.AddIncidentAttachment(ctx, script)
+	require.ErrorIs(t, err, ErrAttachmentType)
+	webp := upload
+	webp.Data = append([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), make([]byte, 16)...)
+	_, err = svc.AddIncidentAttachment(ctx, webp)
+	require.ErrorIs(t, err, ErrAttachmentType, "WebP is not in the default allowlist")
+	empty := upload
+	empty.Data = nil
+	_, err = svc.AddIncidentAttachment(ctx, empty)
+	require.ErrorIs(t, err, ErrInvalidInput)
+	missing := upload
+	missing.IncidentID = uuid.New()
+	_, err = svc.AddIncidentAttachment(ctx, missing)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	attachment, err := svc.AddIncidentAttachment(ctx, upload)
+	require.NoError(t, err)
+	require.Equal(t, "image/png", attachment.ContentType)
+	require.Equal(t, "barrier.png", attachment.FileName)
+	require.Equal(t, int64(len(photo)), attachment.SizeBytes)
+	require.Equal(t, photo, files[attachment.StorageKey])
+
+	stored, content, err := svc.OpenIncidentAttachment(ctx, attachment.ID)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(content)
+	require.NoError(t, content.Close())
+	require.Equal(t, photo, data)
+	require.Equal(t, attachment.ID, stored.ID)
+	_, _, err = svc.OpenIncidentAttachment(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+
+	link := svc.SignFileLink(attachment.ID)
+	require.Equal(t, now.Add(defaultFileLinkTTL), link.Expires)
+	require.NoError(t, svc.VerifyFileLink(attachment.ID, link.Expires.Unix(), link.Signature))
+	require.ErrorIs(t, svc.VerifyFileLink(uuid.New(), link.Expires.Unix(), link.Signature), ErrFileLinkInvalid)
+	require.ErrorIs(t, svc.VerifyFileLink(attachment.ID, link.Expires.Add(time.Hour).Unix(), link.Signature), ErrFileLinkInvalid)
+	now = now.Add(defaultFileLinkTTL)
+	require.ErrorIs(t, svc.VerifyFileLink(attachment.ID, link.Expires.Unix(), link.Signature), ErrFileLinkInvalid)
+
+	require.ErrorIs(t, svc.DeleteIncidentAttachment(ctx, uuid.New(), attachment.ID), ErrNotFound)
+	require.NoError(t, svc.DeleteIncidentAttachment(ctx, incidentID, attachment.ID))
+	require.Empty(t, files)
+
+	// A record that cannot be saved leaves no file behind.
+	store.createIncidentAttachmentFn = func(context.Context, repo.CreateIncidentAttachmentParams) (repo.IncidentAttachment, error) {
+		return repo.IncidentAttachment{}, errors.New("db down")
+	}
+	_, err = svc.AddIncidentAttachment(ctx, upload)
+	require.Error(t, err)
+	require.Empty(t, files)
+
+	require.Equal(t, "file", cleanFileName(" ../ "))
+}
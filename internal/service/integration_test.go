@@ -44,10 +44,12 @@ func TestServiceCRUDIntegration(t *testing.T) {
 	}, residentHash)
 	require.NoError(t, err)
 
-	require.NoError(t, svc.BlockUser(ctx, resident.ID, admin.ID))
+	_, err = svc.BlockUser(ctx, resident.ID, admin.ID)
+	require.NoError(t, err)
 	_, err = svc.Authenticate(ctx, resident.Email, "resident123")
 	require.ErrorIs(t, err, ErrBlocked)
-	require.NoError(t, svc.UnblockUser(ctx, resident.ID, admin.ID))
+	_, err = svc.UnblockUser(ctx, resident.ID, admin.ID)
+	require.NoError(t, err)
 	_, err = svc.Authenticate(ctx, resident.Email, "resident123")
 	require.NoError(t, err)
 
@@ -68,10 +70,12 @@ func TestServiceCRUDIntegration(t *testing.T) {
 	require.True(t, updatedUser.PlotNumber.Valid)
 	require.Equal(t, "14B", updatedUser.PlotNumber.String)
 
-	require.NoError(t, svc.SoftDeleteUser(ctx, resident.ID, admin.ID))
+	_, err = svc.SoftDeleteUser(ctx, resident.ID, admin.ID)
+	require.NoError(t, err)
 	_, err = svc.GetUser(ctx, resident.ID)
 	require.Error(t, err)
-	require.NoError(t, svc.RestoreUser(ctx, resident.ID, admin.ID))
+	_, err = svc.RestoreUser(ctx, resident.ID, admin.ID)
+	require.NoError(t, err)
 	_, err = svc.GetUser(ctx, resident.ID)
 	require.NoError(t, err)
 
@@ -124,11 +128,57 @@ func TestServiceCRUDIntegration(t *testing.T) {
 		PlateNumber: "A123BC77",
 		ValidFrom:   guest.ValidFrom,
 		ValidTo:     guest.ValidTo.Add(1 * time.Hour),
-		Status:      "approved",
+		Status:      guest.Status,
 		ActorID:     admin.ID,
 	})
 	require.NoError(t, err)
+	require.Equal(t, "pending", guest.Status)
+
+	guest, err = svc.ApproveGuestRequest(ctx, guest.ID, admin.ID, "known visitor")
+	require.NoError(t, err)
 	require.Equal(t, "approved", guest.Status)
+	require.Equal(t, admin.ID, guest.ReviewedBy.UUID)
+	require.True(t, guest.ReviewedAt.Valid)
+	_, err = svc.RejectGuestRequest(ctx, guest.ID, admin.ID, "too late")
+	require.ErrorIs(t, err, ErrGuestTransition)
+
+	staleInput := GuestCreateInput{
+		ResidentID:  resident.ID,
+		GuestName:   "Stale Guest",
+		PlateNumber: "A123BC77",
+		ValidFrom:   time.Now().Add(-3 * time.Hour),
+		ValidTo:     time.Now().Add(-2 * time.Hour),
+		ActorID:     resident.ID,
+	}
+	_, err = svc.CreateGuestRequest(ctx, staleInput)
+	require.ErrorIs(t, err, ErrGuestWindowPast)
+	// Filed before the visit, the request is now stale.
+	earlier := New(tdb.Queries, WithClock(func() time.Time { return time.Now().Add(-4 * time.Hour) }))
+	stale, err := earlier.CreateGuestRequest(ctx, staleInput)
+	require.NoError(t, err)
+	conflicts, err := svc.GuestConflicts(ctx, guest)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+	strict := New(tdb.Queries, WithSettings(Settings{GuestWindow: GuestWindowRules{Overlap: GuestOverlapReject}}))
+	_, err = strict.CreateGuestRequest(ctx, GuestCreateInput{
+		ResidentID:  resident.ID,
+		GuestName:   "Same car",
+		PlateNumber: "a123bc77",
+		ValidFrom:   guest.ValidFrom.Add(30 * time.Minute),
+		ValidTo:     guest.ValidTo.Add(time.Hour),
+		ActorID:     resident.ID,
+	})
+	var conflict *GuestConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, []uuid.UUID{guest.ID}, conflict.IDs)
+	expired, err := svc.ExpireGuestRequests(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, expired)
+	stale, err = svc.GetGuestRequest(ctx, stale.ID)
+	require.NoError(t, err)
+	require.Equal(t, "expired", stale.Status)
+	_, err = svc.CancelGuestRequest(ctx, stale.ID, resident.ID)
+	require.ErrorIs(t, err, ErrGuestTransition)
 
 	require.NoError(t, svc.SoftDeleteGuestRequest(ctx, guest.ID, admin.ID))
 	_, err = svc.GetGuestRequest(ctx, guest.ID)
@@ -152,7 +202,7 @@ func TestServiceCRUDIntegration(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "entry", logEntry.Action)
 
-	logs, err := svc.ListEntryLogs(ctx, pass.ID, 10, 0)
+	logs, err := svc.ListEntryLogs(ctx, EntryLogFilter{PassID: pass.ID}, 10, 0)
 	require.NoError(t, err)
 	require.Len(t, logs, 1)
 }
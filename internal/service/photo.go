@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/storage"
+)
+
+const (
+	thumbnailSize = 320
+	// maxPhotoPixels guards against images that are small on disk but
+	// huge once decoded.
+	maxPhotoPixels   = 50_000_000
+	photoPurgeBatch  = 100
+	thumbnailQuality = 80
+)
+
+// PhotoRules control vehicle photos taken at the gate.
+type PhotoRules struct {
+	// Retention is how long photos are kept; zero keeps them forever.
+	Retention time.Duration
+}
+
+// storePhoto validates a gate photo and saves it with a thumbnail. The row
+// is inserted by the caller once the entry log exists; on failure the
+// caller removes the files with dropPhoto. It returns nil without a photo.
+func (s *Service) storePhoto(ctx context.Context, actorID uuid.UUID, data []byte) (*repo.CreateEntryPhotoParams, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	contentType, err := s.checkUpload(data)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAttachmentType
+	}
+	if config.Width*config.Height > maxPhotoPixels {
+		return nil, ErrAttachmentTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAttachmentType
+	}
+	thumbnail, err := makeThumbnail(img)
+	if err != nil {
+		return nil, err
+	}
+	id := uuid.New()
+	photo := &repo.CreateEntryPhotoParams{
+		ID:           id,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+		Width:        int32(config.Width),
+		Height:       int32(config.Height),
+		StorageKey:   "entry-photos/" + id.String(),
+		ThumbnailKey: "entry-photos/" + id.String() + "-thumb",
+		UploadedBy:   actorID,
+	}
+	if err := s.files.Put(ctx, photo.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.files.Put(ctx, photo.ThumbnailKey, bytes.NewReader(thumbnail)); err != nil {
+		_ = s.files.Delete(ctx, photo.StorageKey)
+		return nil, err
+	}
+	return photo, nil
+}
+
+// savePhoto links a stored photo to its entry log inside the movement's
+// transaction.
+func savePhoto(ctx context.Context, q ServiceStore, photo *repo.CreateEntryPhotoParams, entryLogID uuid.UUID) error {
+	if photo == nil {
+		return nil
+	}
+	photo.EntryLogID = entryLogID
+	_, err := q.CreateEntryPhoto(ctx, *photo)
+	return err
+}
+
+func (s *Service) dropPhoto(ctx context.Context, photo *repo.CreateEntryPhotoParams) {
+	if photo == nil {
+		return
+	}
+	_ = s.files.Delete(ctx, photo.StorageKey)
+	_ = s.files.Delete(ctx, photo.ThumbnailKey)
+}
+
+// makeThumbnail scales an image down to fit thumbnailSize by averaging the
+// pixels each thumbnail pixel covers, sampling large areas sparsely.
+func makeThumbnail(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if longest := max(w, h); longest > thumbnailSize {
+		tw = max(w*thumbnailSize/longest, 1)
+		th = max(h*thumbnailSize/longest, 1)
+	}
+	thumb := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		stepY := max((y1-y0)/4, 1)
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			stepX := max((x1-x0)/4, 1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy += stepY {
+				for sx := x0; sx < x1; sx += stepX {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			thumb.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EntryPhoto returns the photo taken with an entry log.
+func (s *Service) EntryPhoto(ctx context.Context, entryLogID uuid.UUID) (repo.EntryPhoto, error) {
+	photo, err := s.q.GetEntryPhotoByLog(ctx, entryLogID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.EntryPhoto{}, ErrNotFound
+	}
+	return photo, err
+}
+
+// OpenEntryPhoto returns a photo, or its JPEG thumbnail, with its content;
+// the caller closes the reader.
+func (s *Service) OpenEntryPhoto(ctx context.Context, id uuid.UUID, thumbnail bool) (repo.EntryPhoto, io.ReadCloser, error) {
+	if s.files == nil {
+		return repo.EntryPhoto{}, nil, ErrFilesDisabled
+	}
+	photo, err := s.q.GetEntryPhoto(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.EntryPhoto{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return repo.EntryPhoto{}, nil, err
+	}
+	key := photo.StorageKey
+	if thumbnail {
+		key = photo.ThumbnailKey
+	}
+	content, err := s.files.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return repo.EntryPhoto{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return repo.EntryPhoto{}, nil, err
+	}
+	return photo, content, nil
+}
+
+// PurgeEntryPhotos deletes photos older than the retention period and
+// reports how many were removed. Files go first so that a failure leaves
+// the row to be retried rather than an orphaned file.
+func (s *Service) PurgeEntryPhotos(ctx context.Context) (int64, error) {
+	retention := s.settings.Photos.Retention
+	if retention <= 0 || s.files == nil {
+		return 0, nil
+	}
+	cutoff := s.now().Add(-retention)
+	var purged int64
+	for {
+		photos, err := s.q.ListEntryPhotosBefore(ctx, repo.ListEntryPhotosBeforeParams{CreatedAt: cutoff, Limit: photoPurgeBatch})
+		if err != nil {
+			return purged, err
+		}
+		for _, photo := range photos {
+			if err := s.files.Delete(ctx, photo.StorageKey); err != nil {
+				return purged, err
+			}
+			if err := s.files.Delete(ctx, photo.ThumbnailKey); err != nil {
+				return purged, err
+			}
+			if err := s.q.DeleteEntryPhoto(ctx, photo.ID); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(photos) < photoPurgeBatch {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func testPhoto(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestServiceUnit_EntryPhoto(t *testing.T) {
+	ctx := context.Background()
+	passID := uuid.New()
+	guardID := uuid.New()
+	logID := uuid.New()
+	files := memFiles{}
+	var (
+		saved    repo.CreateEntryPhotoParams
+		onSite   bool
+		photoRow repo.EntryPhoto
+	)
+	store := &mockStore{
+		getPassByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			return repo.Pass{ID: id, Status: PassStatusActive}, nil
+		},
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) { return repo.User{ID: id}, nil },
+		getPassPresenceFn: func(context.Context, uuid.NullUUID) (repo.SitePresence, error) {
+			if onSite {
+				return repo.SitePresence{}, nil
+			}
+			return repo.SitePresence{}, sql.ErrNoRows
+		},
+		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+			return repo.EntryLog{ID: logID, PassID: arg.PassID, Action: arg.Action}, nil
+		},
+		upsertPassPresenceFn: func(context.Context, repo.UpsertPassPresenceParams) error { return nil },
+		createEntryPhotoFn: func(_ context.Context, arg repo.CreateEntryPhotoParams) (repo.EntryPhoto, error) {
+			saved = arg
+			photoRow = repo.EntryPhoto{ID: arg.ID, EntryLogID: arg.EntryLogID, ContentType: arg.ContentType, StorageKey: arg.StorageKey, ThumbnailKey: arg.ThumbnailKey}
+			return photoRow, nil
+		},
+		getEntryPhotoFn: func(_ context.Context, id uuid.UUID) (repo.EntryPhoto, error) {
+			if id != photoRow.ID {
+				return repo.EntryPhoto{}, sql.ErrNoRows
+			}
+			return photoRow, nil
+		},
+	}
+	move := func(svc *Service, photo []byte) error {
+		_, err := svc.RecordPassMovement(ctx, PassMovementInput{PassID: passID, GuardID: guardID, Action: EntryActionEntry, Photo: photo})
+		return err
+	}
+
+	svc := New(store)
+	require.ErrorIs(t, move(svc, testPhoto(t, 8, 8)), ErrFilesDisabled)
+	_, _, err := svc.OpenEntryPhoto(ctx, uuid.New(), false)
+	require.ErrorIs(t, err, ErrFilesDisabled)
+
+	svc = New(store, WithFileStore(files))
+	require.NoError(t, move(svc, nil))
+	require.Empty(t, files)
+
+	data := testPhoto(t, 800, 400)
+	require.NoError(t, move(svc, data))
+	require.Equal(t, logID, saved.EntryLogID)
+	require.Equal(t, guardID, saved.UploadedBy)
+	require.Equal(t, "image/png", saved.ContentType)
+	require.Equal(t, int64(len(data)), saved.SizeBytes)
+	require.Equal(t, int32(800), saved.Width)
+	require.Equal(t, int32(400), saved.Height)
+	require.Equal(t, data, files[saved.StorageKey])
+	thumb, err := jpeg.Decode(bytes.NewReader(files[saved.ThumbnailKey]))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, thumbnailSize, thumbnailSize/2), thumb.Bounds())
+
+	photo, content, err := svc.OpenEntryPhoto(ctx, saved.ID, true)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(content)
+	require.NoError(t, content.Close())
+	require.Equal(t, saved.ID, photo.ID)
+	require.Equal(t, files[saved.ThumbnailKey], got)
+	_, _, err = svc.OpenEntryPhoto(ctx, uuid.New(), false)
+	require.ErrorIs(t, err, ErrNotFound)
+	delete(files, saved.StorageKey)
+	_, _, err = svc.OpenEntryPhoto(ctx, saved.ID, false)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// A rejected movement leaves no files behind.
+	clear(files)
+	onSite = true
+	require.ErrorIs(t, move(svc, data), ErrAlreadyOnSite)
+	require.Empty(t, files)
+	onSite = false
+
+	require.ErrorIs(t, move(svc, []byte("plain text, not a photo")), ErrAttachmentType)
+	// WebP is not allowed by default, and a photo still needs a decoder
+	// when an operator adds it to the allowlist.
+	webp := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), make([]byte, 16)...)
+	require.ErrorIs(t, move(svc, webp), ErrAttachmentType)
+	svc = New(store, WithFileStore(files), WithSettings(Settings{Attachments: AttachmentRules{Types: []string{"image/webp"}}}))
+	require.ErrorIs(t, move(svc, webp), ErrAttachmentType)
+	require.Empty(t, files)
+	svc = New(store, WithFileStore(files), WithSettings(Settings{Attachments: AttachmentRules{MaxBytes: 100}}))
+	require.ErrorIs(t, move(svc, data), ErrAttachmentTooLarge)
+	require.Empty(t, files)
+
+	_, err = svc.EntryPhoto(ctx, logID)
+	require.ErrorIs(t, err, ErrNotFound)
+	store.getEntryPhotoByLogFn = func(_ context.Context, id uuid.UUID) (repo.EntryPhoto, error) {
+		return repo.EntryPhoto{ID: uuid.New(), EntryLogID: id}, nil
+	}
+	photo, err = svc.EntryPhoto(ctx, logID)
+	require.NoError(t, err)
+	require.Equal(t, logID, photo.EntryLogID)
+}
+
+func TestServiceUnit_PurgeEntryPhotos(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	files := memFiles{}
+	old := []repo.EntryPhoto{
+		{ID: uuid.New(), StorageKey: "entry-photos/a", ThumbnailKey: "entry-photos/a-thumb"},
+		{ID: uuid.New(), StorageKey: "entry-photos/b", ThumbnailKey: "entry-photos/b-thumb"},
+	}
+	for _, photo := range old {
+		files[photo.StorageKey] = []byte{1}
+		files[photo.ThumbnailKey] = []byte{1}
+	}
+	files["entry-photos/c"] = []byte{1}
+	var (
+		cutoff  time.Time
+		deleted []uuid.UUID
+	)
+	store := &mockStore{
+		listEntryPhotosBeforeFn: func(_ context.Context, arg repo.ListEntryPhotosBeforeParams) ([]repo.EntryPhoto, error) {
+			cutoff = arg.CreatedAt
+			if len(deleted) > 0 {
+				return nil, nil
+			}
+			return old, nil
+		},
+		deleteEntryPhotoFn: func(_ context.Context, id uuid.UUID) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+	clock := WithClock(func() time.Time { return now })
+
+	purged, err := New(store, WithFileStore(files), clock).PurgeEntryPhotos(ctx)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+	require.Len(t, files, 5)
+
+	svc := New(store, WithFileStore(files), clock, WithSettings(Settings{Photos: PhotoRules{Retention: 30 * 24 * time.Hour}}))
+	purged, err = svc.PurgeEntryPhotos(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), purged)
+	require.Equal(t, now.AddDate(0, 0, -30), cutoff)
+	require.Equal(t, []uuid.UUID{old[0].ID, old[1].ID}, deleted)
+	require.Equal(t, memFiles{"entry-photos/c": []byte{1}}, files)
+
+	store.listEntryPhotosBeforeFn = func(context.Context, repo.ListEntryPhotosBeforeParams) ([]repo.EntryPhoto, error) {
+		return nil, sql.ErrConnDone
+	}
+	_, err = svc.PurgeEntryPhotos(ctx)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
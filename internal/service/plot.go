@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	PlotStatusActive   = "active"
+	PlotStatusArchived = "archived"
+
+	PlotRelationOwner  = "owner"
+	PlotRelationFamily = "family"
+	PlotRelationTenant = "tenant"
+)
+
+var (
+	ErrInvalidPlot       = errors.New("invalid plot number")
+	ErrPlotExists        = errors.New("plot with this number already exists")
+	ErrPlotArchived      = errors.New("plot is archived")
+	ErrPlotNotLinked     = errors.New("user is not a resident of this plot")
+	ErrInvalidRelation   = errors.New("relation must be owner, family or tenant")
+	ErrInvalidPlotStatus = errors.New("plot status must be active or archived")
+)
+
+type PlotInput struct {
+	ID           uuid.UUID
+	Number       string
+	Street       string
+	ContactName  string
+	ContactPhone string
+	ContactEmail string
+	Status       string
+	ActorID      uuid.UUID
+}
+
+type PlotFilter struct {
+	Status string
+	// Number matches plots whose normalized number starts with it.
+	Number string
+	Limit  int32
+	Offset int32
+}
+
+type PlotResidentInput struct {
+	PlotID   uuid.UUID
+	UserID   uuid.UUID
+	Relation string
+	ActorID  uuid.UUID
+}
+
+func (s *Service) CreatePlot(ctx context.Context, input PlotInput) (repo.Plot, error) {
+	number, status, err := validatePlotInput(input)
+	if err != nil {
+		return repo.Plot{}, err
+	}
+	if _, err := s.q.GetPlotByNumber(ctx, number); err == nil {
+		return repo.Plot{}, ErrPlotExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return repo.Plot{}, err
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	return s.q.CreatePlot(ctx, repo.CreatePlotParams{
+		Number:       number,
+		Street:       toNullNotes(input.Street),
+		ContactName:  toNullNotes(input.ContactName),
+		ContactPhone: toNullNotes(input.ContactPhone),
+		ContactEmail: toNullNotes(input.ContactEmail),
+		Status:       status,
+		CreatedBy:    actor,
+		UpdatedBy:    actor,
+	})
+}
+
+func (s *Service) GetPlot(ctx context.Context, id uuid.UUID) (repo.Plot, error) {
+	plot, err := s.q.GetPlot(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Plot{}, ErrNotFound
+	}
+	return plot, err
+}
+
+func (s *Service) ListPlots(ctx context.Context, filter PlotFilter) ([]repo.Plot, error) {
+	if filter.Status != "" && filter.Status != PlotStatusActive && filter.Status != PlotStatusArchived {
+		return nil, ErrInvalidPlotStatus
+	}
+	number := NormalizePlotNumber(filter.Number)
+	return s.q.ListPlots(ctx, repo.ListPlotsParams{
+		Status:       sql.NullString{String: filter.Status, Valid: filter.Status != ""},
+		NumberPrefix: sql.NullString{String: number, Valid: number != ""},
+		PageSize:     filter.Limit,
+		PageOffset:   filter.Offset,
+	})
+}
+
+// UpdatePlot edits a plot. Renumbering it also renames the plot number of
+// the residents whose primary plot it is.
+func (s *Service) UpdatePlot(ctx context.Context, input PlotInput) (repo.Plot, error) {
+	number, status, err := validatePlotInput(input)
+	if err != nil {
+		return repo.Plot{}, err
+	}
+	var plot repo.Plot
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		current, err := q.GetPlot(ctx, input.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if other, err := q.GetPlotByNumber(ctx, number); err == nil && other.ID != input.ID {
+			return ErrPlotExists
+		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		plot, err = q.UpdatePlot(ctx, repo.UpdatePlotParams{
+			ID:           input.ID,
+			Number:       number,
+			Street:       toNullNotes(input.Street),
+			ContactName:  toNullNotes(input.ContactName),
+			ContactPhone: toNullNotes(input.ContactPhone),
+			ContactEmail: toNullNotes(input.ContactEmail),
+			Status:       status,
+			UpdatedBy:    uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+		})
+		if err != nil || plot.Number == current.Number {
+			return err
+		}
+		return q.RenameUserPlotNumbers(ctx, repo.RenameUserPlotNumbersParams{
+			NewNumber: sql.NullString{String: plot.Number, Valid: true},
+			OldNumber: sql.NullString{String: current.Number, Valid: true},
+		})
+	})
+	return plot, err
+}
+
+func validatePlotInput(input PlotInput) (string, string, error) {
+	number := NormalizePlotNumber(input.Number)
+	if err := ValidatePlotNumber(number); err != nil {
+		return "", "", err
+	}
+	status := input.Status
+	if status == "" {
+		status = PlotStatusActive
+	}
+	if status != PlotStatusActive && status != PlotStatusArchived {
+		return "", "", ErrInvalidPlotStatus
+	}
+	return number, status, nil
+}
+
+func (s *Service) ListPlotResidents(ctx context.Context, plotID uuid.UUID) ([]repo.ListPlotResidentsRow, error) {
+	return s.q.ListPlotResidents(ctx, plotID)
+}
+
+func (s *Service) ListUserPlots(ctx context.Context, userID uuid.UUID) ([]repo.ListUserPlotsRow, error) {
+	return s.q.ListUserPlots(ctx, userID)
+}
+
+// SetPlotResident links a user to a plot or changes the relation of an
+// existing link. A user without a plot number gets this one.
+func (s *Service) SetPlotResident(ctx context.Context, input PlotResidentInput) (repo.PlotResident, error) {
+	switch input.Relation {
+	case PlotRelationOwner, PlotRelationFamily, PlotRelationTenant:
+	default:
+		return repo.PlotResident{}, ErrInvalidRelation
+	}
+	var resident repo.PlotResident
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		plot, err := q.GetPlot(ctx, input.PlotID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := q.GetUserByID(ctx, input.UserID); errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		} else if err != nil {
+			return err
+		}
+		_, err = q.GetUserPlot(ctx, repo.GetUserPlotParams{ID: plot.ID, UserID: input.UserID})
+		if errors.Is(err, sql.ErrNoRows) && plot.Status == PlotStatusArchived {
+			return ErrPlotArchived
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		resident, err = q.UpsertPlotResident(ctx, repo.UpsertPlotResidentParams{
+			PlotID:    plot.ID,
+			UserID:    input.UserID,
+			Relation:  input.Relation,
+			CreatedBy: uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+		})
+		if err != nil {
+			return err
+		}
+		return q.RefreshUserPlotNumber(ctx, repo.RefreshUserPlotNumberParams{UserID: input.UserID})
+	})
+	return resident, err
+}
+
+// RemovePlotResident unlinks a user from a plot. When it was the user's
+// primary plot, the plot number moves to another of their plots, owned ones
+// first, or is cleared.
+func (s *Service) RemovePlotResident(ctx context.Context, plotID, userID uuid.UUID) error {
+	return s.inTx(ctx, func(q ServiceStore) error {
+		plot, err := q.GetPlot(ctx, plotID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		removed, err := q.DeletePlotResident(ctx, repo.DeletePlotResidentParams{PlotID: plotID, UserID: userID})
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return ErrNotFound
+		}
+		return q.RefreshUserPlotNumber(ctx, repo.RefreshUserPlotNumberParams{
+			UserID:      userID,
+			StaleNumber: sql.NullString{String: plot.Number, Valid: true},
+		})
+	})
+}
+
+func (s *Service) ListPlotPasses(ctx context.Context, plotID uuid.UUID, limit, offset int32) ([]repo.Pass, error) {
+	return s.q.ListPassesByPlot(ctx, repo.ListPassesByPlotParams{
+		PlotID: uuid.NullUUID{UUID: plotID, Valid: true},
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (s *Service) ListPlotGuestRequests(ctx context.Context, plotID uuid.UUID, limit, offset int32) ([]repo.GuestRequest, error) {
+	return s.q.ListGuestRequestsByPlot(ctx, repo.ListGuestRequestsByPlotParams{
+		PlotID: uuid.NullUUID{UUID: plotID, Valid: true},
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// checkUserPlot lets a pass or a guest request be attributed to a plot only
+// by one of its residents, and not to an archived plot.
+func checkUserPlot(ctx context.Context, q ServiceStore, plotID, userID uuid.UUID) error {
+	plot, err := q.GetUserPlot(ctx, repo.GetUserPlotParams{ID: plotID, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPlotNotLinked
+	}
+	if err != nil {
+		return err
+	}
+	if plot.Status == PlotStatusArchived {
+		return ErrPlotArchived
+	}
+	return nil
+}
+
+// attachUserPlot links a saved user to the plot in their plot number,
+// creating the plot on first use. An existing link keeps its relation;
+// a new one is an owner link and cannot go to an archived plot.
+func attachUserPlot(ctx context.Context, q ServiceStore, user repo.User, actor uuid.NullUUID) error {
+	if !user.PlotNumber.Valid || strings.TrimSpace(user.PlotNumber.String) == "" {
+		return nil
+	}
+	plot, err := q.EnsurePlot(ctx, repo.EnsurePlotParams{Number: user.PlotNumber.String, ActorID: actor})
+	if err != nil {
+		return err
+	}
+	added, err := q.AddPlotResident(ctx, repo.AddPlotResidentParams{
+		PlotID:    plot.ID,
+		UserID:    user.ID,
+		Relation:  PlotRelationOwner,
+		CreatedBy: actor,
+	})
+	if err != nil {
+		return err
+	}
+	if added > 0 && plot.Status == PlotStatusArchived {
+		return ErrPlotArchived
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_CreatePlot(t *testing.T) {
+	ctx := context.Background()
+	var created repo.CreatePlotParams
+	svc := New(&mockStore{
+		getPlotByNumberFn: func(_ context.Context, number string) (repo.Plot, error) {
+			if number == "12A" {
+				return repo.Plot{ID: uuid.New(), Number: number}, nil
+			}
+			return repo.Plot{}, sql.ErrNoRows
+		},
+		createPlotFn: func(_ context.Context, arg repo.CreatePlotParams) (repo.Plot, error) {
+			created = arg
+			return repo.Plot{ID: uuid.New(), Number: arg.Number, Status: arg.Status}, nil
+		},
+	})
+
+	_, err := svc.CreatePlot(ctx, PlotInput{Number: "дом у реки"})
+	require.ErrorIs(t, err, ErrInvalidPlot)
+	_, err = svc.CreatePlot(ctx, PlotInput{Number: "14", Status: "sold"})
+	require.ErrorIs(t, err, ErrInvalidPlotStatus)
+	_, err = svc.CreatePlot(ctx, PlotInput{Number: "уч. 12а"})
+	require.ErrorIs(t, err, ErrPlotExists)
+
+	plot, err := svc.CreatePlot(ctx, PlotInput{Number: "Участок 14", Street: " Лесная ", ContactPhone: " ", ActorID: uuid.New()})
+	require.NoError(t, err)
+	require.Equal(t, "14", plot.Number)
+	require.Equal(t, PlotStatusActive, created.Status)
+	require.Equal(t, "Лесная", created.Street.String)
+	require.False(t, created.ContactPhone.Valid)
+	require.True(t, created.CreatedBy.Valid)
+
+	var filter repo.ListPlotsParams
+	svc = New(&mockStore{listPlotsFn: func(_ context.Context, arg repo.ListPlotsParams) ([]repo.Plot, error) {
+		filter = arg
+		return nil, nil
+	}})
+	_, err = svc.ListPlots(ctx, PlotFilter{Status: "sold"})
+	require.ErrorIs(t, err, ErrInvalidPlotStatus)
+	_, err = svc.ListPlots(ctx, PlotFilter{Number: "уч. 1", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, "1", filter.NumberPrefix.String)
+	require.False(t, filter.Status.Valid)
+}
+
+func TestServiceUnit_UpdatePlot(t *testing.T) {
+	ctx := context.Background()
+	plotID := uuid.New()
+	var renamed *repo.RenameUserPlotNumbersParams
+	store := &mockStore{
+		getPlotFn: func(_ context.Context, id uuid.UUID) (repo.Plot, error) {
+			if id != plotID {
+				return repo.Plot{}, sql.ErrNoRows
+			}
+			return repo.Plot{ID: id, Number: "12", Status: PlotStatusActive}, nil
+		},
+		getPlotByNumberFn: func(_ context.Context, number string) (repo.Plot, error) {
+			if number == "15" {
+				return repo.Plot{ID: uuid.New(), Number: number}, nil
+			}
+			return repo.Plot{}, sql.ErrNoRows
+		},
+		updatePlotFn: func(_ context.Context, arg repo.UpdatePlotParams) (repo.Plot, error) {
+			return repo.Plot{ID: arg.ID, Number: arg.Number, Status: arg.Status}, nil
+		},
+		renameUserPlotNumbersFn: func(_ context.Context, arg repo.RenameUserPlotNumbersParams) error {
+			renamed = &arg
+			return nil
+		},
+	}
+	svc := New(store)
+
+	_, err := svc.UpdatePlot(ctx, PlotInput{ID: uuid.New(), Number: "12"})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.UpdatePlot(ctx, PlotInput{ID: plotID, Number: "15"})
+	require.ErrorIs(t, err, ErrPlotExists)
+
+	_, err = svc.UpdatePlot(ctx, PlotInput{ID: plotID, Number: "12", Status: PlotStatusArchived})
+	require.NoError(t, err)
+	require.Nil(t, renamed, "residents keep their number when it does not change")
+
+	plot, err := svc.UpdatePlot(ctx, PlotInput{ID: plotID, Number: "12Б"})
+	require.NoError(t, err)
+	require.Equal(t, "12Б", plot.Number)
+	require.NotNil(t, renamed)
+	require.Equal(t, "12", renamed.OldNumber.String)
+	require.Equal(t, "12Б", renamed.NewNumber.String)
+}
+
+func TestServiceUnit_PlotResidents(t *testing.T) {
+	ctx := context.Background()
+	active := repo.Plot{ID: uuid.New(), Number: "12", Status: PlotStatusActive}
+	archived := repo.Plot{ID: uuid.New(), Number: "13", Status: PlotStatusArchived}
+	userID := uuid.New()
+	linked := map[uuid.UUID]bool{archived.ID: true}
+	var refreshed []repo.RefreshUserPlotNumberParams
+	store := &mockStore{
+		getPlotFn: func(_ context.Context, id uuid.UUID) (repo.Plot, error) {
+			switch id {
+			case active.ID:
+				return active, nil
+			case archived.ID:
+				return archived, nil
+			}
+			return repo.Plot{}, sql.ErrNoRows
+		},
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			if id != userID {
+				return repo.User{}, sql.ErrNoRows
+			}
+			return repo.User{ID: id}, nil
+		},
+		getUserPlotFn: func(_ context.Context, arg repo.GetUserPlotParams) (repo.GetUserPlotRow, error) {
+			if !linked[arg.ID] {
+				return repo.GetUserPlotRow{}, sql.ErrNoRows
+			}
+			return repo.GetUserPlotRow{ID: arg.ID}, nil
+		},
+		upsertPlotResidentFn: func(_ context.Context, arg repo.UpsertPlotResidentParams) (repo.PlotResident, error) {
+			linked[arg.PlotID] = true
+			return repo.PlotResident{PlotID: arg.PlotID, UserID: arg.UserID, Relation: arg.Relation}, nil
+		},
+		deletePlotResidentFn: func(_ context.Context, arg repo.DeletePlotResidentParams) (int64, error) {
+			if !linked[arg.PlotID] {
+				return 0, nil
+			}
+			delete(linked, arg.PlotID)
+			return 1, nil
+		},
+		refreshUserPlotNumberFn: func(_ context.Context, arg repo.RefreshUserPlotNumberParams) error {
+			refreshed = append(refreshed, arg)
+			return nil
+		},
+	}
+	svc := New(store)
+
+	_, err := svc.SetPlotResident(ctx, PlotResidentInput{PlotID: active.ID, UserID: userID, Relation: "friend"})
+	require.ErrorIs(t, err, ErrInvalidRelation)
+	_, err = svc.SetPlotResident(ctx, PlotResidentInput{PlotID: uuid.New(), UserID: userID, Relation: PlotRelationFamily})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.SetPlotResident(ctx, PlotResidentInput{PlotID: active.ID, UserID: uuid.New(), Relation: PlotRelationFamily})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	resident, err := svc.SetPlotResident(ctx, PlotResidentInput{PlotID: active.ID, UserID: userID, Relation: PlotRelationTenant})
+	require.NoError(t, err)
+	require.Equal(t, PlotRelationTenant, resident.Relation)
+	require.Len(t, refreshed, 1)
+	require.False(t, refreshed[0].StaleNumber.Valid, "linking only fills an empty plot number")
+
+	// An existing link to an archived plot can still change its relation,
+	// a new one cannot be made.
+	_, err = svc.SetPlotResident(ctx, PlotResidentInput{PlotID: archived.ID, UserID: userID, Relation: PlotRelationFamily})
+	require.NoError(t, err)
+	delete(linked, archived.ID)
+	_, err = svc.SetPlotResident(ctx, PlotResidentInput{PlotID: archived.ID, UserID: userID, Relation: PlotRelationFamily})
+	require.ErrorIs(t, err, ErrPlotArchived)
+
+	refreshed = nil
+	require.NoError(t, svc.RemovePlotResident(ctx, active.ID, userID))
+	require.Equal(t, "12", refreshed[0].StaleNumber.String)
+	require.ErrorIs(t, svc.RemovePlotResident(ctx, active.ID, userID), ErrNotFound)
+	require.ErrorIs(t, svc.RemovePlotResident(ctx, uuid.New(), userID), ErrNotFound)
+}
+
+func TestServiceUnit_UserPlotLink(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+	var (
+		ensured  []string
+		added    []repo.AddPlotResidentParams
+		unlinked []repo.UnlinkPreviousUserPlotParams
+		status   = PlotStatusActive
+	)
+	store := &mockStore{
+		createUserFn: func(_ context.Context, arg repo.CreateUserParams) (repo.User, error) {
+			return repo.User{ID: userID, PlotNumber: arg.PlotNumber}, nil
+		},
+		updateUserFn: func(_ context.Context, arg repo.UpdateUserParams) (repo.User, error) {
+			return repo.User{ID: arg.ID, PlotNumber: arg.PlotNumber}, nil
+		},
+		ensurePlotFn: func(_ context.Context, arg repo.EnsurePlotParams) (repo.Plot, error) {
+			ensured = append(ensured, arg.Number)
+			return repo.Plot{ID: uuid.New(), Number: arg.Number, Status: status}, nil
+		},
+		addPlotResidentFn: func(_ context.Context, arg repo.AddPlotResidentParams) (int64, error) {
+			added = append(added, arg)
+			return 1, nil
+		},
+		unlinkPreviousUserPlotFn: func(_ context.Context, arg repo.UnlinkPreviousUserPlotParams) error {
+			unlinked = append(unlinked, arg)
+			return nil
+		},
+	}
+	svc := New(store)
+
+	_, err := svc.CreateUser(ctx, UserCreateInput{Email: "r@example.com", Password: "secret", Role: "resident", FullName: "R", PlotNumber: "у реки"}, "hash")
+	require.ErrorIs(t, err, ErrInvalidPlot)
+
+	user, err := svc.CreateUser(ctx, UserCreateInput{Email: "r@example.com", Password: "secret", Role: "resident", FullName: "R", PlotNumber: "уч. 12а", ActorID: actorID}, "hash")
+	require.NoError(t, err)
+	require.Equal(t, "12A", user.PlotNumber.String)
+	require.Equal(t, []string{"12A"}, ensured)
+	require.Equal(t, PlotRelationOwner, added[0].Relation)
+	require.Equal(t, actorID, added[0].CreatedBy.UUID)
+
+	_, err = svc.CreateUser(ctx, UserCreateInput{Email: "g@example.com", Password: "secret", Role: "guard", FullName: "G"}, "hash")
+	require.NoError(t, err)
+	require.Len(t, ensured, 1, "users without a plot number are not linked")
+
+	_, err = svc.UpdateUser(ctx, UserUpdateInput{ID: userID, Email: "r@example.com", Role: "resident", FullName: "R", PlotNumber: "15"})
+	require.NoError(t, err)
+	require.Equal(t, userID, unlinked[0].UserID)
+	require.Equal(t, "15", unlinked[0].PlotNumber.String)
+
+	status = PlotStatusArchived
+	_, err = svc.UpdateUser(ctx, UserUpdateInput{ID: userID, Email: "r@example.com", Role: "resident", FullName: "R", PlotNumber: "16"})
+	require.ErrorIs(t, err, ErrPlotArchived)
+
+	store.addPlotResidentFn = func(context.Context, repo.AddPlotResidentParams) (int64, error) { return 0, nil }
+	_, err = svc.UpdateUser(ctx, UserUpdateInput{ID: userID, Email: "r@example.com", Role: "resident", FullName: "R", PlotNumber: "16"})
+	require.NoError(t, err, "a resident already on an archived plot can still be edited")
+}
+
+func TestServiceUnit_PassAndGuestPlot(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	active := uuid.New()
+	archived := uuid.New()
+	passID := uuid.New()
+	guestID := uuid.New()
+	var (
+		createdPass  repo.CreatePassParams
+		updatedPass  repo.UpdatePassParams
+		createdGuest repo.CreateGuestRequestParams
+		updatedGuest repo.UpdateGuestRequestParams
+	)
+	store := &mockStore{
+		getUserPlotFn: func(_ context.Context, arg repo.GetUserPlotParams) (repo.GetUserPlotRow, error) {
+			if arg.UserID != ownerID {
+				return repo.GetUserPlotRow{}, sql.ErrNoRows
+			}
+			switch arg.ID {
+			case active:
+				return repo.GetUserPlotRow{ID: arg.ID, Status: PlotStatusActive}, nil
+			case archived:
+				return repo.GetUserPlotRow{ID: arg.ID, Status: PlotStatusArchived}, nil
+			}
+			return repo.GetUserPlotRow{}, sql.ErrNoRows
+		},
+		createPassFn: func(_ context.Context, arg repo.CreatePassParams) (repo.Pass, error) {
+			createdPass = arg
+			return repo.Pass{ID: passID, OwnerUserID: arg.OwnerUserID, PlotID: arg.PlotID}, nil
+		},
+		getPassByIDFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			if id != passID {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return repo.Pass{ID: id, OwnerUserID: ownerID}, nil
+		},
+		updatePassFn: func(_ context.Context, arg repo.UpdatePassParams) (repo.Pass, error) {
+			updatedPass = arg
+			return repo.Pass{ID: arg.ID, PlotID: arg.PlotID}, nil
+		},
+		createGuestRequestFn: func(_ context.Context, arg repo.CreateGuestRequestParams) (repo.GuestRequest, error) {
+			createdGuest = arg
+			return repo.GuestRequest{ID: guestID, PlotID: arg.PlotID}, nil
+		},
+		getGuestRequestByIDFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+			return repo.GuestRequest{ID: id, ResidentUserID: ownerID, Status: GuestStatusPending}, nil
+		},
+		updateGuestRequestFn: func(_ context.Context, arg repo.UpdateGuestRequestParams) (repo.GuestRequest, error) {
+			updatedGuest = arg
+			return repo.GuestRequest{ID: arg.ID, PlotID: arg.PlotID}, nil
+		},
+	}
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	svc := New(store, WithClock(func() time.Time { return now }))
+
+	_, err := svc.CreatePass(ctx, PassCreateInput{OwnerID: ownerID, PlateNumber: "A123BC77"})
+	require.NoError(t, err)
+	require.False(t, createdPass.PlotID.Valid, "the primary plot is picked by the query")
+	_, err = svc.CreatePass(ctx, PassCreateInput{OwnerID: ownerID, PlateNumber: "A123BC77", PlotID: uuid.New()})
+	require.ErrorIs(t, err, ErrPlotNotLinked)
+	_, err = svc.CreatePass(ctx, PassCreateInput{OwnerID: ownerID, PlateNumber: "A123BC77", PlotID: archived})
+	require.ErrorIs(t, err, ErrPlotArchived)
+	pass, err := svc.CreatePass(ctx, PassCreateInput{OwnerID: ownerID, PlateNumber: "A123BC77", PlotID: active})
+	require.NoError(t, err)
+	require.Equal(t, active, pass.PlotID.UUID)
+
+	_, err = svc.UpdatePass(ctx, PassUpdateInput{ID: uuid.New(), PlateNumber: "A123BC77", PlotID: active})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.UpdatePass(ctx, PassUpdateInput{ID: passID, PlateNumber: "A123BC77", PlotID: active})
+	require.NoError(t, err)
+	require.Equal(t, active, updatedPass.PlotID.UUID)
+	_, err = svc.UpdatePass(ctx, PassUpdateInput{ID: passID, PlateNumber: "A123BC77"})
+	require.NoError(t, err)
+	require.False(t, updatedPass.PlotID.Valid, "no plot keeps the current one")
+
+	_, err = svc.CreateGuestRequest(ctx, GuestCreateInput{ResidentID: uuid.New(), GuestName: "Guest", PlateNumber: "A123BC77",
+		ValidFrom: now, ValidTo: now.Add(time.Hour), PlotID: active})
+	require.ErrorIs(t, err, ErrPlotNotLinked)
+	_, err = svc.CreateGuestRequest(ctx, GuestCreateInput{ResidentID: ownerID, GuestName: "Guest", PlateNumber: "A123BC77",
+		ValidFrom: now, ValidTo: now.Add(time.Hour), PlotID: active})
+	require.NoError(t, err)
+	require.Equal(t, active, createdGuest.PlotID.UUID)
+
+	_, err = svc.UpdateGuestRequest(ctx, GuestUpdateInput{ID: guestID, GuestName: "Guest", PlateNumber: "A123BC77",
+		ValidFrom: now, ValidTo: now.Add(time.Hour), Status: GuestStatusPending, PlotID: archived})
+	require.ErrorIs(t, err, ErrPlotArchived)
+	_, err = svc.UpdateGuestRequest(ctx, GuestUpdateInput{ID: guestID, GuestName: "Guest", PlateNumber: "A123BC77",
+		ValidFrom: now, ValidTo: now.Add(time.Hour), Status: GuestStatusPending, PlotID: active})
+	require.NoError(t, err)
+	require.Equal(t, active, updatedGuest.PlotID.UUID)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	PresenceReject = "reject"
+	PresenceFlag   = "flag"
+
+	EntryAnomalyDoubleEntry      = "double_entry"
+	EntryAnomalyExitWithoutEntry = "exit_without_entry"
+)
+
+var (
+	ErrAlreadyOnSite = errors.New("vehicle is already on site")
+	ErrNotOnSite     = errors.New("vehicle is not on site")
+)
+
+// PresenceRules decide what happens to an entry of a vehicle that is already
+// on site and to an exit of one that never entered.
+type PresenceRules struct {
+	// Policy PresenceReject (the default) refuses such actions unless the
+	// guard gives an override reason; PresenceFlag records them flagged.
+	Policy string
+}
+
+// PresenceConflictError reports an inconsistent entry or exit; it matches
+// ErrAlreadyOnSite or ErrNotOnSite. EnteredAt is set for a double entry.
+type PresenceConflictError struct {
+	Anomaly   string
+	EnteredAt time.Time
+}
+
+func (e *PresenceConflictError) Error() string {
+	return e.Unwrap().Error()
+}
+
+func (e *PresenceConflictError) Unwrap() error {
+	if e.Anomaly == EntryAnomalyDoubleEntry {
+		return ErrAlreadyOnSite
+	}
+	return ErrNotOnSite
+}
+
+type PassMovementInput struct {
+	PassID  uuid.UUID
+	GuardID uuid.UUID
+	// GateID may be uuid.Nil when the guard works at a single gate or the
+	// site has none.
+	GateID  uuid.UUID
+	Action  string
+	Comment sql.NullString
+	// OverrideReason lets an inconsistent entry or exit through; it is kept
+	// in the journal next to the anomaly.
+	OverrideReason string
+	// Photo is an optional JPEG or PNG of the vehicle.
+	Photo []byte
+	// Watchlist is the hit CheckGate returned for the vehicle; it is logged
+	// in the same transaction as the movement.
+	Watchlist *WatchlistHit
+	// ID and DeviceAt are set for movements logged on a guard device while
+	// it was offline: the client-generated record id and the device time.
+	ID       uuid.UUID
+	DeviceAt time.Time
+}
+
+// RecordPassMovement logs a vehicle entering or leaving and keeps the site
+// presence in step. An entry while the pass is on site or an exit while it is
+// not is an anomaly handled by the presence policy.
+func (s *Service) RecordPassMovement(ctx context.Context, input PassMovementInput) (repo.EntryLog, error) {
+	if input.Action != EntryActionEntry && input.Action != EntryActionExit {
+		return repo.EntryLog{}, ErrInvalidEntryAction
+	}
+	reason := strings.TrimSpace(input.OverrideReason)
+	passID := uuid.NullUUID{UUID: input.PassID, Valid: true}
+	photo, err := s.storePhoto(ctx, input.GuardID, input.Photo)
+	if err != nil {
+		return repo.EntryLog{}, err
+	}
+	var entry repo.EntryLog
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		shift, err := s.openShift(ctx, q, input.GuardID)
+		if err != nil {
+			return err
+		}
+		restricted, err := q.ListPassGates(ctx, input.PassID)
+		if err != nil {
+			return err
+		}
+		gateID, err := resolveGate(ctx, q, gateAccess{GateID: input.GateID, GuardID: input.GuardID, ShiftGate: shift.GateID, Restricted: restricted})
+		if err != nil {
+			return err
+		}
+		// The pass row stays locked until the movement is logged, so two
+		// guards recording the same car cannot both find it outside. A car
+		// may leave on a pass deleted while it was inside.
+		lock := q.GetPassByIDForUpdate
+		if input.Action == EntryActionExit {
+			lock = q.GetPassByIDAnyForUpdate
+		}
+		pass, err := lock(ctx, input.PassID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		// An exit is always recorded, so a car inside can still leave.
+		if input.Action == EntryActionEntry {
+			if err := checkPassActive(ctx, q, pass); err != nil {
+				return err
+			}
+		}
+		presence, err := q.GetPassPresence(ctx, passID)
+		onSite := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		var conflict *PresenceConflictError
+		switch {
+		case input.Action == EntryActionEntry && onSite:
+			conflict = &PresenceConflictError{Anomaly: EntryAnomalyDoubleEntry, EnteredAt: presence.EnteredAt}
+		case input.Action == EntryActionExit && !onSite:
+			conflict = &PresenceConflictError{Anomaly: EntryAnomalyExitWithoutEntry}
+		}
+		params := repo.CreateEntryLogParams{
+			ID:          input.ID,
+			PassID:      passID,
+			GuardUserID: input.GuardID,
+			Action:      input.Action,
+			Comment:     input.Comment,
+			GateID:      gateID,
+			ShiftID:     uuid.NullUUID{UUID: shift.ID, Valid: shift.ID != uuid.Nil},
+			DeviceAt:    sql.NullTime{Time: input.DeviceAt, Valid: !input.DeviceAt.IsZero()},
+		}
+		if conflict != nil {
+			if reason == "" && s.settings.Presence.Policy != PresenceFlag {
+				return conflict
+			}
+			params.Anomaly = sql.NullString{String: conflict.Anomaly, Valid: true}
+			params.OverrideReason = sql.NullString{String: reason, Valid: reason != ""}
+		}
+		entry, err = s.appendEntryLog(ctx, q, params)
+		if err != nil {
+			return err
+		}
+		if err := savePhoto(ctx, q, photo, entry.ID); err != nil {
+			return err
+		}
+		if err := recordWatchlistHit(ctx, q, input.Watchlist); err != nil {
+			return err
+		}
+		if input.Action == EntryActionEntry {
+			return q.UpsertPassPresence(ctx, repo.UpsertPassPresenceParams{PassID: passID, EntryLogID: entry.ID, EnteredAt: movedAt(entry)})
+		}
+		_, err = q.DeletePassPresence(ctx, passID)
+		return err
+	})
+	if err != nil {
+		s.dropPhoto(ctx, photo)
+	}
+	return entry, err
+}
+
+// movedAt is when the vehicle actually passed: the device time of a record
+// synced from an offline device, the journal time otherwise.
+func movedAt(entry repo.EntryLog) time.Time {
+	if entry.DeviceAt.Valid {
+		return entry.DeviceAt.Time
+	}
+	return entry.ActionAt
+}
+
+// OnSiteEntry is a vehicle or guest on site and how long it has been there.
+type OnSiteEntry struct {
+	repo.ListSitePresenceRow
+	Duration time.Duration
+}
+
+// OnSite is the roll-call list: everyone who entered and has not left yet,
+// longest present first, optionally of one plot.
+func (s *Service) OnSite(ctx context.Context, plot string) ([]OnSiteEntry, error) {
+	plot = NormalizePlotNumber(plot)
+	rows, err := s.q.ListSitePresence(ctx, sql.NullString{String: plot, Valid: plot != ""})
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	entries := make([]OnSiteEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, OnSiteEntry{ListSitePresenceRow: row, Duration: max(now.Sub(row.EnteredAt), 0)})
+	}
+	return entries, nil
+}
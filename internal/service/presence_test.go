@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_RecordPassMovement(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	passID := uuid.New()
+	guardID := uuid.New()
+	ownerID, blockedID := uuid.New(), uuid.New()
+	passes := map[uuid.UUID]repo.Pass{passID: {ID: passID, OwnerUserID: ownerID, Status: PassStatusActive}}
+	var (
+		presence *repo.SitePresence
+		created  repo.CreateEntryLogParams
+		locked   bool
+		hits     []repo.CreateWatchlistHitParams
+	)
+	store := &mockStore{
+		getPassByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			pass, ok := passes[id]
+			if !ok {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			locked = true
+			return pass, nil
+		},
+		getPassByIDAnyForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			pass, ok := passes[id]
+			if !ok {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			locked = true
+			return pass, nil
+		},
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			if id == blockedID {
+				return repo.User{ID: id, BlockedAt: sql.NullTime{Time: now, Valid: true}}, nil
+			}
+			return repo.User{ID: id}, nil
+		},
+		getPassPresenceFn: func(_ context.Context, id uuid.NullUUID) (repo.SitePresence, error) {
+			require.Equal(t, passID, id.UUID)
+			require.True(t, locked, "presence is read under the pass lock")
+			locked = false
+			if presence == nil {
+				return repo.SitePresence{}, sql.ErrNoRows
+			}
+			return *presence, nil
+		},
+		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+			created = arg
+			return repo.EntryLog{ID: uuid.New(), PassID: arg.PassID, Action: arg.Action, ActionAt: now, Anomaly: arg.Anomaly, OverrideReason: arg.OverrideReason}, nil
+		},
+		upsertPassPresenceFn: func(_ context.Context, arg repo.UpsertPassPresenceParams) error {
+			presence = &repo.SitePresence{PassID: arg.PassID, EntryLogID: arg.EntryLogID, EnteredAt: arg.EnteredAt}
+			return nil
+		},
+		deletePassPresenceFn: func(context.Context, uuid.NullUUID) (int64, error) {
+			presence = nil
+			return 1, nil
+		},
+		createWatchlistHitFn: func(_ context.Context, arg repo.CreateWatchlistHitParams) (repo.WatchlistHit, error) {
+			hits = append(hits, arg)
+			return repo.WatchlistHit{}, nil
+		},
+	}
+	svc := New(store)
+	move := func(action, reason string) (repo.EntryLog, error) {
+		return svc.RecordPassMovement(ctx, PassMovementInput{PassID: passID, GuardID: guardID, Action: action, OverrideReason: reason})
+	}
+
+	entry, err := move(EntryActionEntry, "")
+	require.NoError(t, err)
+	require.False(t, entry.Anomaly.Valid)
+	require.NotNil(t, presence)
+	require.Equal(t, entry.ID, presence.EntryLogID)
+
+	// A supervisor's watchlist override is only logged for a movement the
+	// journal accepted.
+	override := &WatchlistHit{Entry: repo.PlateWatchlist{ID: uuid.New()}, Check: GateCheckInput{PassID: passID, Source: WatchlistSourceEntry, Override: true, Comment: "escort"}, Outcome: WatchlistOverridden}
+	_, err = svc.RecordPassMovement(ctx, PassMovementInput{PassID: passID, GuardID: guardID, Action: EntryActionEntry, Watchlist: override})
+	require.ErrorIs(t, err, ErrAlreadyOnSite)
+	require.Empty(t, hits)
+
+	_, err = move(EntryActionEntry, "")
+	require.ErrorIs(t, err, ErrAlreadyOnSite)
+	var conflict *PresenceConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Equal(t, now, conflict.EnteredAt)
+
+	entry, err = svc.RecordPassMovement(ctx, PassMovementInput{PassID: passID, GuardID: guardID, Action: EntryActionEntry, OverrideReason: " exit was not recorded ", Watchlist: override})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, WatchlistOverridden, hits[0].Outcome)
+	require.Equal(t, "escort", hits[0].Comment.String)
+	require.Equal(t, EntryAnomalyDoubleEntry, entry.Anomaly.String)
+	require.Equal(t, "exit was not recorded", entry.OverrideReason.String)
+
+	entry, err = move(EntryActionExit, "ignored")
+	require.NoError(t, err)
+	require.False(t, entry.Anomaly.Valid)
+	require.False(t, created.OverrideReason.Valid)
+	require.Nil(t, presence)
+
+	_, err = move(EntryActionExit, "")
+	require.ErrorIs(t, err, ErrNotOnSite)
+	_, err = move("checkin", "")
+	require.ErrorIs(t, err, ErrInvalidEntryAction)
+
+	// Suspended passes and passes of blocked owners do not get in, but a car
+	// already inside may still leave.
+	suspendedID, blockedPassID := uuid.New(), uuid.New()
+	passes[suspendedID] = repo.Pass{ID: suspendedID, OwnerUserID: ownerID, Status: PassStatusSuspended}
+	passes[blockedPassID] = repo.Pass{ID: blockedPassID, OwnerUserID: blockedID, Status: PassStatusActive}
+	created = repo.CreateEntryLogParams{}
+	for _, id := range []uuid.UUID{suspendedID, blockedPassID} {
+		_, err = svc.RecordPassMovement(ctx, PassMovementInput{PassID: id, GuardID: guardID, Action: EntryActionEntry})
+		require.ErrorIs(t, err, ErrPassInactive)
+	}
+	require.Equal(t, repo.CreateEntryLogParams{}, created)
+	_, err = svc.RecordPassMovement(ctx, PassMovementInput{PassID: uuid.New(), GuardID: guardID, Action: EntryActionEntry})
+	require.ErrorIs(t, err, ErrNotFound)
+	presence = &repo.SitePresence{PassID: uuid.NullUUID{UUID: passID, Valid: true}}
+	passes[passID] = repo.Pass{ID: passID, OwnerUserID: blockedID, Status: PassStatusSuspended}
+	_, err = move(EntryActionExit, "")
+	require.NoError(t, err)
+	passes[passID] = repo.Pass{ID: passID, OwnerUserID: ownerID, Status: PassStatusActive}
+
+	// The flag policy lets inconsistent actions through and marks them.
+	svc = New(store, WithSettings(Settings{Presence: PresenceRules{Policy: PresenceFlag}}))
+	entry, err = move(EntryActionExit, "")
+	require.NoError(t, err)
+	require.Equal(t, EntryAnomalyExitWithoutEntry, entry.Anomaly.String)
+	require.False(t, entry.OverrideReason.Valid)
+
+	store.getPassPresenceFn = func(context.Context, uuid.NullUUID) (repo.SitePresence, error) {
+		return repo.SitePresence{}, sql.ErrConnDone
+	}
+	_, err = move(EntryActionEntry, "")
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestServiceUnit_OnSite(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	var plot sql.NullString
+	svc := New(&mockStore{
+		listSitePresenceFn: func(_ context.Context, arg sql.NullString) ([]repo.ListSitePresenceRow, error) {
+			plot = arg
+			return []repo.ListSitePresenceRow{
+				{PlateNumber: "A123BC77", EnteredAt: now.Add(-95 * time.Minute)},
+				{PlateNumber: "B456CD77", EnteredAt: now.Add(time.Minute)},
+			}, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	entries, err := svc.OnSite(ctx, " 12 ")
+	require.NoError(t, err)
+	require.Equal(t, "12", plot.String)
+	require.Len(t, entries, 2)
+	require.Equal(t, 95*time.Minute, entries[0].Duration)
+	require.Zero(t, entries[1].Duration)
+
+	_, err = svc.OnSite(ctx, "")
+	require.NoError(t, err)
+	require.False(t, plot.Valid)
+
+	svc = New(&mockStore{})
+	_, err = svc.OnSite(ctx, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+const (
+	FreqDaily  = "DAILY"
+	FreqWeekly = "WEEKLY"
+
+	maxRecurrenceCount    = 1000
+	maxRecurrenceInterval = 52
+)
+
+var rruleWeekdays = []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// Recurrence is the supported subset of an iCalendar RRULE: DAILY or WEEKLY
+// frequency with INTERVAL, BYDAY and at most one of UNTIL and COUNT. Weeks
+// start on Monday, dates are calendar days in the site time zone.
+type Recurrence struct {
+	Freq     string
+	Interval int
+	// Weekdays is an ISO weekday mask as in pass schedules; zero means the
+	// weekday of the first occurrence.
+	Weekdays int16
+	// Until is the last allowed day, inclusive; zero when not set.
+	Until time.Time
+	Count int
+}
+
+// ParseRRule parses an RRULE value such as
+// "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20250630", with or without the "RRULE:"
+// prefix.
+func ParseRRule(value string) (Recurrence, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	rec := Recurrence{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" || seen[key] {
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+		seen[key] = true
+		switch key {
+		case "FREQ":
+			if val != FreqDaily && val != FreqWeekly {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+			rec.Freq = val
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 || n > maxRecurrenceInterval {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+			rec.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 || n > maxRecurrenceCount {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+			rec.Count = n
+		case "UNTIL":
+			// Only the date matters: occurrences are whole days.
+			until, err := time.Parse("20060102", val[:min(len(val), 8)])
+			if err != nil {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+			rec.Until = until
+		case "BYDAY":
+			days := []int{}
+			for _, name := range strings.Split(val, ",") {
+				day := rruleWeekday(name)
+				if day == 0 {
+					return Recurrence{}, ErrInvalidRecurrence
+				}
+				days = append(days, day)
+			}
+			mask, err := WeekdayMask(days)
+			if err != nil {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+			rec.Weekdays = mask
+		case "WKST":
+			if val != "MO" {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+		default:
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+	}
+	if rec.Freq == "" || (rec.Count > 0 && !rec.Until.IsZero()) {
+		return Recurrence{}, ErrInvalidRecurrence
+	}
+	return rec, nil
+}
+
+// WeeklyRecurrence builds the rule for the simple "these weekdays until that
+// day" pattern.
+func WeeklyRecurrence(weekdays []int, until time.Time) (Recurrence, error) {
+	if until.IsZero() {
+		return Recurrence{}, ErrInvalidRecurrence
+	}
+	mask, err := WeekdayMask(weekdays)
+	if err != nil {
+		return Recurrence{}, ErrInvalidRecurrence
+	}
+	return Recurrence{Freq: FreqWeekly, Interval: 1, Weekdays: mask, Until: dateOf(until)}, nil
+}
+
+func rruleWeekday(name string) int {
+	for i, day := range rruleWeekdays {
+		if day == name {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// String returns the normalized RRULE stored with the series.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Weekdays != 0 {
+		names := []string{}
+		for _, day := range MaskWeekdays(r.Weekdays) {
+			names = append(names, rruleWeekdays[day-1])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(names, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (r Recurrence) matches(start, day time.Time) bool {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	mask := r.Weekdays
+	switch r.Freq {
+	case FreqDaily:
+		if int(day.Sub(start).Hours()/24)%interval != 0 {
+			return false
+		}
+		if mask == 0 {
+			return true
+		}
+	case FreqWeekly:
+		week := int(mondayOf(day).Sub(mondayOf(start)).Hours() / 24 / 7)
+		if week%interval != 0 {
+			return false
+		}
+		if mask == 0 {
+			mask = 1 << (isoWeekday(start.Weekday()) - 1)
+		}
+	default:
+		return false
+	}
+	return mask&(1<<(isoWeekday(day.Weekday())-1)) != 0
+}
+
+// Occurrences returns the occurrence days of a series starting on start that
+// fall within [from, to]. All values are dates as returned by dateOf.
+func (r Recurrence) Occurrences(start, from, to time.Time) []time.Time {
+	if !r.Until.IsZero() && r.Until.Before(to) {
+		to = r.Until
+	}
+	days := []time.Time{}
+	count := 0
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !r.matches(start, day) {
+			continue
+		}
+		count++
+		if r.Count > 0 && count > r.Count {
+			break
+		}
+		if !day.Before(from) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// LastDay returns the day of the final occurrence, or false for open-ended
+// rules.
+func (r Recurrence) LastDay(start time.Time) (time.Time, bool) {
+	switch {
+	case r.Count > 0:
+		days := r.Occurrences(start, start, start.AddDate(0, 0, r.Count*7*max(r.Interval, 1)))
+		if len(days) == 0 {
+			return time.Time{}, false
+		}
+		return days[len(days)-1], true
+	case !r.Until.IsZero():
+		return r.Until, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func mondayOf(day time.Time) time.Time {
+	return day.AddDate(0, 0, 1-isoWeekday(day.Weekday()))
+}
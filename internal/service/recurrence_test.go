@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func day(value string) time.Time {
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func days(values ...string) []time.Time {
+	out := []time.Time{}
+	for _, value := range values {
+		out = append(out, day(value))
+	}
+	return out
+}
+
+func TestServiceUnit_Recurrence(t *testing.T) {
+	rule, err := ParseRRule("RRULE:freq=weekly;byday=TH,TU;until=20250515T235959Z;wkst=MO")
+	require.NoError(t, err)
+	require.Equal(t, "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20250515", rule.String())
+	// 2025-05-01 is a Thursday.
+	require.Equal(t, days("2025-05-01", "2025-05-06", "2025-05-08", "2025-05-13", "2025-05-15"),
+		rule.Occurrences(day("2025-05-01"), day("2025-05-01"), day("2025-06-01")))
+	require.Equal(t, days("2025-05-08", "2025-05-13"),
+		rule.Occurrences(day("2025-05-01"), day("2025-05-07"), day("2025-05-14")))
+	last, ok := rule.LastDay(day("2025-05-01"))
+	require.True(t, ok)
+	require.Equal(t, day("2025-05-15"), last)
+
+	rule, err = ParseRRule("FREQ=WEEKLY;INTERVAL=2;COUNT=3")
+	require.NoError(t, err)
+	require.Equal(t, "FREQ=WEEKLY;INTERVAL=2;COUNT=3", rule.String())
+	require.Equal(t, days("2025-05-01", "2025-05-15", "2025-05-29"),
+		rule.Occurrences(day("2025-05-01"), day("2025-05-01"), day("2025-07-01")))
+	last, ok = rule.LastDay(day("2025-05-01"))
+	require.True(t, ok)
+	require.Equal(t, day("2025-05-29"), last)
+
+	rule, err = ParseRRule("FREQ=DAILY;INTERVAL=3")
+	require.NoError(t, err)
+	require.Equal(t, days("2025-05-04", "2025-05-07"),
+		rule.Occurrences(day("2025-05-01"), day("2025-05-02"), day("2025-05-08")))
+	_, ok = rule.LastDay(day("2025-05-01"))
+	require.False(t, ok)
+
+	rule, err = ParseRRule("FREQ=DAILY;BYDAY=SA,SU")
+	require.NoError(t, err)
+	require.Equal(t, days("2025-05-03", "2025-05-04"),
+		rule.Occurrences(day("2025-05-01"), day("2025-05-01"), day("2025-05-05")))
+
+	rule, err = WeeklyRecurrence([]int{1, 3}, day("2025-05-12"))
+	require.NoError(t, err)
+	require.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20250512", rule.String())
+	require.Equal(t, days("2025-05-05", "2025-05-07", "2025-05-12"),
+		rule.Occurrences(day("2025-05-01"), day("2025-05-01"), day("2025-06-01")))
+	_, err = WeeklyRecurrence([]int{1}, time.Time{})
+	require.ErrorIs(t, err, ErrInvalidRecurrence)
+	_, err = WeeklyRecurrence([]int{8}, day("2025-05-12"))
+	require.ErrorIs(t, err, ErrInvalidRecurrence)
+
+	for _, value := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=MONTHLY",
+		"FREQ=WEEKLY;COUNT=2;UNTIL=20250601",
+		"FREQ=WEEKLY;INTERVAL=0",
+		"FREQ=WEEKLY;COUNT=5000",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;UNTIL=2025",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=WEEKLY;BYMONTH=5",
+		"FREQ=WEEKLY;FREQ=DAILY",
+	} {
+		_, err := ParseRRule(value)
+		require.ErrorIs(t, err, ErrInvalidRecurrence, value)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	// A guest is suggested for the address book after
+	// guestSuggestionMinVisits requests within guestSuggestionPeriod.
+	guestSuggestionMinVisits = 2
+	guestSuggestionPeriod    = 180 * 24 * time.Hour
+	MaxGuestSuggestions      = 20
+)
+
+type SavedGuestInput struct {
+	ID          uuid.UUID
+	ResidentID  uuid.UUID
+	GuestName   string
+	GuestType   string
+	PlateNumber string
+	CompanyName string
+	Notes       string
+}
+
+func (s *Service) CreateSavedGuest(ctx context.Context, input SavedGuestInput) (repo.SavedGuest, error) {
+	details, name, err := resolveSavedGuest(input)
+	if err != nil {
+		return repo.SavedGuest{}, err
+	}
+	return s.q.CreateSavedGuest(ctx, repo.CreateSavedGuestParams{
+		ResidentUserID: input.ResidentID,
+		GuestFullName:  name,
+		GuestType:      details.Type,
+		PlateNumber:    details.PlateNumber,
+		CompanyName:    details.CompanyName,
+		Notes:          toNullNotes(input.Notes),
+	})
+}
+
+// GetSavedGuest returns an entry of the resident's address book; entries of
+// other residents are reported as ErrNotFound.
+func (s *Service) GetSavedGuest(ctx context.Context, id, resident uuid.UUID) (repo.SavedGuest, error) {
+	guest, err := s.q.GetSavedGuest(ctx, repo.GetSavedGuestParams{ID: id, ResidentUserID: resident})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.SavedGuest{}, ErrNotFound
+	}
+	return guest, err
+}
+
+func (s *Service) ListSavedGuests(ctx context.Context, resident uuid.UUID, limit, offset int32) ([]repo.SavedGuest, error) {
+	return s.q.ListSavedGuests(ctx, repo.ListSavedGuestsParams{ResidentUserID: resident, Limit: limit, Offset: offset})
+}
+
+func (s *Service) UpdateSavedGuest(ctx context.Context, input SavedGuestInput) (repo.SavedGuest, error) {
+	details, name, err := resolveSavedGuest(input)
+	if err != nil {
+		return repo.SavedGuest{}, err
+	}
+	guest, err := s.q.UpdateSavedGuest(ctx, repo.UpdateSavedGuestParams{
+		ID:             input.ID,
+		ResidentUserID: input.ResidentID,
+		GuestFullName:  name,
+		GuestType:      details.Type,
+		PlateNumber:    details.PlateNumber,
+		CompanyName:    details.CompanyName,
+		Notes:          toNullNotes(input.Notes),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.SavedGuest{}, ErrNotFound
+	}
+	return guest, err
+}
+
+func (s *Service) DeleteSavedGuest(ctx context.Context, id, resident uuid.UUID) error {
+	affected, err := s.q.DeleteSavedGuest(ctx, repo.DeleteSavedGuestParams{ID: id, ResidentUserID: resident})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SuggestSavedGuests lists guests the resident invited repeatedly over the
+// last months and has not saved yet, most frequent first.
+func (s *Service) SuggestSavedGuests(ctx context.Context, resident uuid.UUID, limit int32) ([]repo.ListFrequentGuestsRow, error) {
+	if limit <= 0 || limit > MaxGuestSuggestions {
+		limit = MaxGuestSuggestions
+	}
+	return s.q.ListFrequentGuests(ctx, repo.ListFrequentGuestsParams{
+		ResidentUserID: resident,
+		Since:          s.now().Add(-guestSuggestionPeriod),
+		MinVisits:      guestSuggestionMinVisits,
+		PageSize:       limit,
+	})
+}
+
+func resolveSavedGuest(input SavedGuestInput) (guestDetails, string, error) {
+	name := strings.TrimSpace(input.GuestName)
+	if name == "" {
+		return guestDetails{}, "", ErrInvalidInput
+	}
+	details, err := resolveGuestSubject(input.GuestType, input.PlateNumber, input.CompanyName)
+	return details, name, err
+}
+
+func toNullNotes(notes string) sql.NullString {
+	notes = strings.TrimSpace(notes)
+	return sql.NullString{String: notes, Valid: notes != ""}
+}
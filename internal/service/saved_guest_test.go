@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_SavedGuests(t *testing.T) {
+	ctx := context.Background()
+	resident := uuid.New()
+	saved := map[uuid.UUID]repo.SavedGuest{}
+	store := &mockStore{
+		createSavedGuestFn: func(_ context.Context, arg repo.CreateSavedGuestParams) (repo.SavedGuest, error) {
+			guest := repo.SavedGuest{
+				ID:             uuid.New(),
+				ResidentUserID: arg.ResidentUserID,
+				GuestFullName:  arg.GuestFullName,
+				GuestType:      arg.GuestType,
+				PlateNumber:    arg.PlateNumber,
+				CompanyName:    arg.CompanyName,
+				Notes:          arg.Notes,
+			}
+			saved[guest.ID] = guest
+			return guest, nil
+		},
+		getSavedGuestFn: func(_ context.Context, arg repo.GetSavedGuestParams) (repo.SavedGuest, error) {
+			guest, ok := saved[arg.ID]
+			if !ok || guest.ResidentUserID != arg.ResidentUserID {
+				return repo.SavedGuest{}, sql.ErrNoRows
+			}
+			return guest, nil
+		},
+		listSavedGuestsFn: func(_ context.Context, arg repo.ListSavedGuestsParams) ([]repo.SavedGuest, error) {
+			require.Equal(t, resident, arg.ResidentUserID)
+			return []repo.SavedGuest{}, nil
+		},
+		updateSavedGuestFn: func(_ context.Context, arg repo.UpdateSavedGuestParams) (repo.SavedGuest, error) {
+			guest, ok := saved[arg.ID]
+			if !ok || guest.ResidentUserID != arg.ResidentUserID {
+				return repo.SavedGuest{}, sql.ErrNoRows
+			}
+			guest.GuestFullName = arg.GuestFullName
+			guest.GuestType = arg.GuestType
+			guest.PlateNumber = arg.PlateNumber
+			guest.Notes = arg.Notes
+			saved[arg.ID] = guest
+			return guest, nil
+		},
+		deleteSavedGuestFn: func(_ context.Context, arg repo.DeleteSavedGuestParams) (int64, error) {
+			guest, ok := saved[arg.ID]
+			if !ok || guest.ResidentUserID != arg.ResidentUserID {
+				return 0, nil
+			}
+			delete(saved, arg.ID)
+			return 1, nil
+		},
+	}
+	svc := New(store)
+
+	nanny, err := svc.CreateSavedGuest(ctx, SavedGuestInput{
+		ResidentID:  resident,
+		GuestName:   " Anna ",
+		PlateNumber: "a123bc77",
+		Notes:       " comes on Tuesdays ",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Anna", nanny.GuestFullName)
+	require.Equal(t, GuestTypeVehicle, nanny.GuestType)
+	require.Equal(t, "A123BC77", nanny.PlateNumber)
+	require.Equal(t, "comes on Tuesdays", nanny.Notes.String)
+
+	for _, input := range []SavedGuestInput{
+		{ResidentID: resident, GuestName: " ", PlateNumber: "A123BC77"},
+		{ResidentID: resident, GuestName: "Anna"},
+		{ResidentID: resident, GuestName: "Anna", GuestType: GuestTypeDelivery},
+		{ResidentID: resident, GuestName: "Anna", GuestType: "bicycle"},
+	} {
+		_, err := svc.CreateSavedGuest(ctx, input)
+		require.Error(t, err)
+	}
+
+	got, err := svc.GetSavedGuest(ctx, nanny.ID, resident)
+	require.NoError(t, err)
+	require.Equal(t, nanny, got)
+	_, err = svc.GetSavedGuest(ctx, nanny.ID, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.ListSavedGuests(ctx, resident, 20, 0)
+	require.NoError(t, err)
+
+	updated, err := svc.UpdateSavedGuest(ctx, SavedGuestInput{ID: nanny.ID, ResidentID: resident, GuestName: "Anna", GuestType: GuestTypePedestrian})
+	require.NoError(t, err)
+	require.Equal(t, GuestTypePedestrian, updated.GuestType)
+	require.Empty(t, updated.PlateNumber)
+	require.False(t, updated.Notes.Valid)
+	_, err = svc.UpdateSavedGuest(ctx, SavedGuestInput{ID: nanny.ID, ResidentID: uuid.New(), GuestName: "Anna", GuestType: GuestTypePedestrian})
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = svc.UpdateSavedGuest(ctx, SavedGuestInput{ID: nanny.ID, ResidentID: resident, GuestName: "Anna", GuestType: GuestTypePedestrian, PlateNumber: "A123BC77"})
+	require.ErrorIs(t, err, ErrPedestrianPlate)
+
+	require.ErrorIs(t, svc.DeleteSavedGuest(ctx, nanny.ID, uuid.New()), ErrNotFound)
+	require.NoError(t, svc.DeleteSavedGuest(ctx, nanny.ID, resident))
+	require.ErrorIs(t, svc.DeleteSavedGuest(ctx, nanny.ID, resident), ErrNotFound)
+	store.deleteSavedGuestFn = func(context.Context, repo.DeleteSavedGuestParams) (int64, error) {
+		return 0, sql.ErrConnDone
+	}
+	require.ErrorIs(t, svc.DeleteSavedGuest(ctx, nanny.ID, resident), sql.ErrConnDone)
+}
+
+func TestServiceUnit_SuggestSavedGuests(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	resident := uuid.New()
+	var got repo.ListFrequentGuestsParams
+	svc := New(&mockStore{
+		listFrequentGuestsFn: func(_ context.Context, arg repo.ListFrequentGuestsParams) ([]repo.ListFrequentGuestsRow, error) {
+			got = arg
+			return []repo.ListFrequentGuestsRow{{GuestFullName: "Anna", Visits: 3}}, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	rows, err := svc.SuggestSavedGuests(ctx, resident, 5)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, resident, got.ResidentUserID)
+	require.Equal(t, now.Add(-guestSuggestionPeriod), got.Since)
+	require.EqualValues(t, guestSuggestionMinVisits, got.MinVisits)
+	require.EqualValues(t, 5, got.PageSize)
+
+	_, err = svc.SuggestSavedGuests(ctx, resident, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, MaxGuestSuggestions, got.PageSize)
+	_, err = svc.SuggestSavedGuests(ctx, resident, 500)
+	require.NoError(t, err)
+	require.EqualValues(t, MaxGuestSuggestions, got.PageSize)
+}
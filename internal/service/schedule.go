@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+const minutesPerDay = 24 * 60
+
+type PassScheduleInput struct {
+	PassID       uuid.UUID
+	Weekdays     []int
+	Start        string
+	End          string
+	SkipHolidays bool
+	ActorID      uuid.UUID
+}
+
+type HolidayInput struct {
+	Day     string
+	Name    string
+	ActorID uuid.UUID
+}
+
+type ScheduleStatus struct {
+	Restricted bool
+	Within     bool
+	Holiday    bool
+	CheckedAt  time.Time
+}
+
+func ParseClock(value string, allowEndOfDay bool) (int16, error) {
+	value = strings.TrimSpace(value)
+	if allowEndOfDay && value == "24:00" {
+		return minutesPerDay, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, ErrInvalidSchedule
+	}
+	return int16(parsed.Hour()*60 + parsed.Minute()), nil
+}
+
+func FormatClock(minute int16) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+func WeekdayMask(days []int) (int16, error) {
+	if len(days) == 0 {
+		return 0, ErrInvalidSchedule
+	}
+	var mask int16
+	for _, day := range days {
+		if day < 1 || day > 7 {
+			return 0, ErrInvalidSchedule
+		}
+		mask |= 1 << (day - 1)
+	}
+	return mask, nil
+}
+
+func MaskWeekdays(mask int16) []int {
+	days := []int{}
+	for day := 1; day <= 7; day++ {
+		if mask&(1<<(day-1)) != 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func ParseDay(value string) (time.Time, error) {
+	day, err := time.Parse(time.DateOnly, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, ErrInvalidInput
+	}
+	return day, nil
+}
+
+func isoWeekday(day time.Weekday) int {
+	if day == time.Sunday {
+		return 7
+	}
+	return int(day)
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func scheduleAllowsDay(window repo.PassSchedule, day time.Time, holiday bool) bool {
+	if window.SkipHolidays && holiday {
+		return false
+	}
+	return window.Weekdays&(1<<(isoWeekday(day.Weekday())-1)) != 0
+}
+
+func withinSchedule(windows []repo.PassSchedule, local time.Time, holidayToday, holidayYesterday bool) bool {
+	minute := int16(local.Hour()*60 + local.Minute())
+	yesterday := local.AddDate(0, 0, -1)
+	for _, window := range windows {
+		if window.StartMinute < window.EndMinute {
+			if minute >= window.StartMinute && minute < window.EndMinute && scheduleAllowsDay(window, local, holidayToday) {
+				return true
+			}
+			continue
+		}
+		if minute >= window.StartMinute && scheduleAllowsDay(window, local, holidayToday) {
+			return true
+		}
+		if minute < window.EndMinute && scheduleAllowsDay(window, yesterday, holidayYesterday) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) CreatePassSchedule(ctx context.Context, input PassScheduleInput) (repo.PassSchedule, error) {
+	mask, err := WeekdayMask(input.Weekdays)
+	if err != nil {
+		return repo.PassSchedule{}, err
+	}
+	start, err := ParseClock(input.Start, false)
+	if err != nil {
+		return repo.PassSchedule{}, err
+	}
+	end, err := ParseClock(input.End, true)
+	if err != nil {
+		return repo.PassSchedule{}, err
+	}
+	if start == end {
+		return repo.PassSchedule{}, ErrInvalidSchedule
+	}
+	return s.q.CreatePassSchedule(ctx, repo.CreatePassScheduleParams{
+		PassID:       input.PassID,
+		Weekdays:     mask,
+		StartMinute:  start,
+		EndMinute:    end,
+		SkipHolidays: input.SkipHolidays,
+		CreatedBy:    uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	})
+}
+
+func (s *Service) ListPassSchedules(ctx context.Context, passID uuid.UUID) ([]repo.PassSchedule, error) {
+	return s.q.ListPassSchedules(ctx, passID)
+}
+
+func (s *Service) DeletePassSchedule(ctx context.Context, passID, scheduleID uuid.UUID) error {
+	affected, err := s.q.DeletePassSchedule(ctx, repo.DeletePassScheduleParams{ID: scheduleID, PassID: passID})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Service) CheckPassSchedule(ctx context.Context, passID uuid.UUID) (ScheduleStatus, error) {
+	local := s.now().In(s.settings.Location)
+	status := ScheduleStatus{Within: true, CheckedAt: local}
+	windows, err := s.q.ListPassSchedules(ctx, passID)
+	if err != nil {
+		return ScheduleStatus{}, err
+	}
+	if len(windows) == 0 {
+		return status, nil
+	}
+	status.Restricted = true
+
+	needHolidays := false
+	for _, window := range windows {
+		if window.SkipHolidays {
+			needHolidays = true
+			break
+		}
+	}
+	holidayYesterday := false
+	if needHolidays {
+		if status.Holiday, err = s.q.IsHoliday(ctx, dateOf(local)); err != nil {
+			return ScheduleStatus{}, err
+		}
+		if holidayYesterday, err = s.q.IsHoliday(ctx, dateOf(local.AddDate(0, 0, -1))); err != nil {
+			return ScheduleStatus{}, err
+		}
+	}
+	status.Within = withinSchedule(windows, local, status.Holiday, holidayYesterday)
+	return status, nil
+}
+
+func (s *Service) CreateHoliday(ctx context.Context, input HolidayInput) (repo.Holiday, error) {
+	day, err := ParseDay(input.Day)
+	if err != nil {
+		return repo.Holiday{}, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return repo.Holiday{}, ErrInvalidInput
+	}
+	return s.q.CreateHoliday(ctx, repo.CreateHolidayParams{
+		Day:       day,
+		Name:      name,
+		CreatedBy: uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	})
+}
+
+func (s *Service) ListHolidays(ctx context.Context, from, to time.Time) ([]repo.Holiday, error) {
+	if from.After(to) {
+		return nil, ErrInvalidRange
+	}
+	return s.q.ListHolidays(ctx, repo.ListHolidaysParams{FromDay: dateOf(from), ToDay: dateOf(to)})
+}
+
+func (s *Service) DeleteHoliday(ctx context.Context, day time.Time) error {
+	affected, err := s.q.DeleteHoliday(ctx, dateOf(day))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
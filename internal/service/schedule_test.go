@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_ParseClock(t *testing.T) {
+	minute, err := ParseClock("08:30", false)
+	require.NoError(t, err)
+	require.Equal(t, int16(510), minute)
+
+	minute, err = ParseClock("24:00", true)
+	require.NoError(t, err)
+	require.Equal(t, int16(1440), minute)
+
+	_, err = ParseClock("24:00", false)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = ParseClock("8h", false)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+
+	require.Equal(t, "08:30", FormatClock(510))
+	require.Equal(t, "24:00", FormatClock(1440))
+}
+
+func TestServiceUnit_WeekdayMask(t *testing.T) {
+	mask, err := WeekdayMask([]int{1, 2, 3, 4, 5})
+	require.NoError(t, err)
+	require.Equal(t, int16(31), mask)
+	require.Equal(t, []int{1, 2, 3, 4, 5}, MaskWeekdays(mask))
+
+	_, err = WeekdayMask(nil)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = WeekdayMask([]int{0})
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = WeekdayMask([]int{8})
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+}
+
+func TestServiceUnit_ParseDay(t *testing.T) {
+	day, err := ParseDay("2026-01-07")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC), day)
+
+	_, err = ParseDay("07.01.2026")
+	require.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestServiceUnit_WithinSchedule(t *testing.T) {
+	weekdays := repo.PassSchedule{Weekdays: 31, StartMinute: 480, EndMinute: 1200, SkipHolidays: true}
+	overnight := repo.PassSchedule{Weekdays: 1 << 4, StartMinute: 1320, EndMinute: 360}
+
+	// 2026-10-16 is a Friday.
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 16, hour, minute, 0, 0, time.UTC)
+	}
+
+	require.True(t, withinSchedule([]repo.PassSchedule{weekdays}, friday(8, 0), false, false))
+	require.False(t, withinSchedule([]repo.PassSchedule{weekdays}, friday(20, 0), false, false))
+	require.False(t, withinSchedule([]repo.PassSchedule{weekdays}, friday(12, 0), true, false))
+	require.False(t, withinSchedule([]repo.PassSchedule{weekdays}, friday(12, 0).AddDate(0, 0, 1), false, false))
+
+	require.True(t, withinSchedule([]repo.PassSchedule{overnight}, friday(23, 0), false, false))
+	require.True(t, withinSchedule([]repo.PassSchedule{overnight}, friday(3, 0).AddDate(0, 0, 1), false, false))
+	require.False(t, withinSchedule([]repo.PassSchedule{overnight}, friday(3, 0), false, false))
+	require.False(t, withinSchedule([]repo.PassSchedule{overnight}, friday(12, 0), false, false))
+}
+
+func TestServiceUnit_PassScheduleMethods(t *testing.T) {
+	ctx := context.Background()
+	passID := uuid.New()
+	actorID := uuid.New()
+
+	svc := New(&mockStore{
+		createPassScheduleFn: func(_ context.Context, arg repo.CreatePassScheduleParams) (repo.PassSchedule, error) {
+			require.Equal(t, passID, arg.PassID)
+			require.Equal(t, int16(31), arg.Weekdays)
+			require.Equal(t, int16(480), arg.StartMinute)
+			require.Equal(t, int16(1440), arg.EndMinute)
+			require.True(t, arg.CreatedBy.Valid)
+			return repo.PassSchedule{ID: uuid.New(), PassID: arg.PassID}, nil
+		},
+		deletePassScheduleFn: func(_ context.Context, arg repo.DeletePassScheduleParams) (int64, error) {
+			if arg.PassID != passID {
+				return 0, nil
+			}
+			return 1, nil
+		},
+	})
+
+	_, err := svc.CreatePassSchedule(ctx, PassScheduleInput{
+		PassID:   passID,
+		Weekdays: []int{1, 2, 3, 4, 5},
+		Start:    "08:00",
+		End:      "24:00",
+		ActorID:  actorID,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CreatePassSchedule(ctx, PassScheduleInput{PassID: passID, Start: "08:00", End: "20:00"})
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = svc.CreatePassSchedule(ctx, PassScheduleInput{PassID: passID, Weekdays: []int{1}, Start: "x", End: "20:00"})
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = svc.CreatePassSchedule(ctx, PassScheduleInput{PassID: passID, Weekdays: []int{1}, Start: "08:00", End: "x"})
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = svc.CreatePassSchedule(ctx, PassScheduleInput{PassID: passID, Weekdays: []int{1}, Start: "08:00", End: "08:00"})
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+
+	schedules, err := svc.ListPassSchedules(ctx, passID)
+	require.NoError(t, err)
+	require.Empty(t, schedules)
+
+	require.NoError(t, svc.DeletePassSchedule(ctx, passID, uuid.New()))
+	require.ErrorIs(t, svc.DeletePassSchedule(ctx, uuid.New(), uuid.New()), ErrNotFound)
+
+	repoErr := errors.New("repo failed")
+	failing := New(&mockStore{
+		deletePassScheduleFn: func(context.Context, repo.DeletePassScheduleParams) (int64, error) { return 0, repoErr },
+	})
+	require.ErrorIs(t, failing.DeletePassSchedule(ctx, passID, uuid.New()), repoErr)
+}
+
+func TestServiceUnit_CheckPassSchedule(t *testing.T) {
+	ctx := context.Background()
+	passID := uuid.New()
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// 2026-10-16 18:30 UTC is Friday 21:30 in Moscow.
+	clock := func() time.Time { return time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC) }
+
+	t.Run("unrestricted", func(t *testing.T) {
+		svc := New(&mockStore{}, WithClock(clock))
+		status, err := svc.CheckPassSchedule(ctx, passID)
+		require.NoError(t, err)
+		require.False(t, status.Restricted)
+		require.True(t, status.Within)
+	})
+
+	t.Run("site time zone", func(t *testing.T) {
+		store := &mockStore{
+			listPassSchedulesFn: func(context.Context, uuid.UUID) ([]repo.PassSchedule, error) {
+				return []repo.PassSchedule{{Weekdays: 31, StartMinute: 480, EndMinute: 1260}}, nil
+			},
+		}
+		utc := New(store, WithClock(clock))
+		status, err := utc.CheckPassSchedule(ctx, passID)
+		require.NoError(t, err)
+		require.True(t, status.Restricted)
+		require.True(t, status.Within)
+
+		local := New(store, WithClock(clock), WithSettings(Settings{Location: moscow}))
+		status, err = local.CheckPassSchedule(ctx, passID)
+		require.NoError(t, err)
+		require.False(t, status.Within)
+		require.Equal(t, 21, status.CheckedAt.Hour())
+	})
+
+	t.Run("holiday", func(t *testing.T) {
+		svc := New(&mockStore{
+			listPassSchedulesFn: func(context.Context, uuid.UUID) ([]repo.PassSchedule, error) {
+				return []repo.PassSchedule{{Weekdays: 127, StartMinute: 0, EndMinute: 1440, SkipHolidays: true}}, nil
+			},
+			isHolidayFn: func(_ context.Context, day time.Time) (bool, error) {
+				return day.Day() == 16, nil
+			},
+		}, WithClock(clock))
+		status, err := svc.CheckPassSchedule(ctx, passID)
+		require.NoError(t, err)
+		require.True(t, status.Holiday)
+		require.False(t, status.Within)
+	})
+
+	t.Run("repository errors", func(t *testing.T) {
+		repoErr := errors.New("repo failed")
+		svc := New(&mockStore{
+			listPassSchedulesFn: func(context.Context, uuid.UUID) ([]repo.PassSchedule, error) { return nil, repoErr },
+		})
+		_, err := svc.CheckPassSchedule(ctx, passID)
+		require.ErrorIs(t, err, repoErr)
+
+		calls := 0
+		svc = New(&mockStore{
+			listPassSchedulesFn: func(context.Context, uuid.UUID) ([]repo.PassSchedule, error) {
+				return []repo.PassSchedule{{Weekdays: 127, StartMinute: 0, EndMinute: 1440, SkipHolidays: true}}, nil
+			},
+			isHolidayFn: func(context.Context, time.Time) (bool, error) {
+				calls++
+				if calls == 2 {
+					return false, repoErr
+				}
+				return false, nil
+			},
+		})
+		_, err = svc.CheckPassSchedule(ctx, passID)
+		require.ErrorIs(t, err, repoErr)
+	})
+}
+
+func TestServiceUnit_HolidayMethods(t *testing.T) {
+	ctx := context.Background()
+	svc := New(&mockStore{
+		createHolidayFn: func(_ context.Context, arg repo.CreateHolidayParams) (repo.Holiday, error) {
+			require.Equal(t, "Новый год", arg.Name)
+			return repo.Holiday{Day: arg.Day, Name: arg.Name}, nil
+		},
+		listHolidaysFn: func(_ context.Context, arg repo.ListHolidaysParams) ([]repo.Holiday, error) {
+			return []repo.Holiday{{Day: arg.FromDay, Name: "x"}}, nil
+		},
+		deleteHolidayFn: func(_ context.Context, day time.Time) (int64, error) {
+			if day.Day() == 1 {
+				return 1, nil
+			}
+			return 0, nil
+		},
+	})
+
+	holiday, err := svc.CreateHoliday(ctx, HolidayInput{Day: "2027-01-01", Name: " Новый год ", ActorID: uuid.New()})
+	require.NoError(t, err)
+	require.Equal(t, 2027, holiday.Day.Year())
+	_, err = svc.CreateHoliday(ctx, HolidayInput{Day: "bad", Name: "x"})
+	require.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.CreateHoliday(ctx, HolidayInput{Day: "2027-01-01", Name: " "})
+	require.ErrorIs(t, err, ErrInvalidInput)
+
+	from := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	holidays, err := svc.ListHolidays(ctx, from, from.AddDate(0, 0, 10))
+	require.NoError(t, err)
+	require.Len(t, holidays, 1)
+	_, err = svc.ListHolidays(ctx, from, from.AddDate(0, 0, -1))
+	require.ErrorIs(t, err, ErrInvalidRange)
+
+	require.NoError(t, svc.DeleteHoliday(ctx, from))
+	require.ErrorIs(t, svc.DeleteHoliday(ctx, from.AddDate(0, 0, 1)), ErrNotFound)
+
+	repoErr := errors.New("repo failed")
+	failing := New(&mockStore{
+		deleteHolidayFn: func(context.Context, time.Time) (int64, error) { return 0, repoErr },
+	})
+	require.ErrorIs(t, failing.DeleteHoliday(ctx, from), repoErr)
+}
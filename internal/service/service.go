@@ -4,13 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"pipo-edu-project/internal/auth"
 	repo "pipo-edu-project/internal/repository/sqlc"
+	"pipo-edu-project/internal/storage"
 )
 
 var (
@@ -23,11 +23,21 @@ var (
 )
 
 type Service struct {
-	q ServiceStore
-}
-
-func New(q ServiceStore) *Service {
-	return &Service{q: q}
+	q        ServiceStore
+	tx       TxRunner
+	settings Settings
+	now      func() time.Time
+	pins     *pinAttempts
+	files    storage.Store
+	barriers BarrierFactory
+}
+
+func New(q ServiceStore, opts ...Option) *Service {
+	s := &Service{q: q, settings: DefaultSettings(), now: time.Now, pins: newPinAttempts()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type UserCreateInput struct {
@@ -60,7 +70,9 @@ type PassCreateInput struct {
 	VehicleBrand sql.NullString
 	VehicleColor sql.NullString
 	Status       string
-	ActorID      uuid.UUID
+	// PlotID defaults to the owner's primary plot.
+	PlotID  uuid.UUID
+	ActorID uuid.UUID
 }
 
 type PassUpdateInput struct {
@@ -69,27 +81,39 @@ type PassUpdateInput struct {
 	VehicleBrand sql.NullString
 	VehicleColor sql.NullString
 	Status       string
-	ActorID      uuid.UUID
+	// PlotID keeps the current plot when nil.
+	PlotID  uuid.UUID
+	ActorID uuid.UUID
 }
 
 type GuestCreateInput struct {
 	ResidentID  uuid.UUID
 	GuestName   string
+	GuestType   string
 	PlateNumber string
+	CompanyName string
 	ValidFrom   time.Time
 	ValidTo     time.Time
 	Status      string
-	ActorID     uuid.UUID
+	// PlotID defaults to the resident's primary plot.
+	PlotID  uuid.UUID
+	ActorID uuid.UUID
 }
 
 type GuestUpdateInput struct {
 	ID          uuid.UUID
 	GuestName   string
+	GuestType   string
 	PlateNumber string
+	CompanyName string
 	ValidFrom   time.Time
 	ValidTo     time.Time
-	Status      string
-	ActorID     uuid.UUID
+	// Status is the current status; only pending and approved requests can
+	// be edited.
+	Status string
+	// PlotID keeps the current plot when nil.
+	PlotID  uuid.UUID
+	ActorID uuid.UUID
 }
 
 func (s *Service) Authenticate(ctx context.Context, email, password string) (repo.User, error) {
@@ -120,18 +144,32 @@ func (s *Service) CreateUser(ctx context.Context, input UserCreateInput, passwor
 	if err := ValidateRole(input.Role); err != nil {
 		return repo.User{}, err
 	}
-	plot := strings.TrimSpace(input.PlotNumber)
+	plot := NormalizePlotNumber(input.PlotNumber)
 	if input.Role == "resident" && plot == "" {
 		return repo.User{}, ErrInvalidInput
 	}
-	user, err := s.q.CreateUser(ctx, repo.CreateUserParams{
-		Email:        input.Email,
-		PasswordHash: passwordHash,
-		Role:         input.Role,
-		FullName:     input.FullName,
-		PlotNumber:   sql.NullString{String: plot, Valid: plot != ""},
-		CreatedBy:    uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
-		UpdatedBy:    uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	if plot != "" {
+		if err := ValidatePlotNumber(plot); err != nil {
+			return repo.User{}, err
+		}
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	var user repo.User
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		var err error
+		user, err = q.CreateUser(ctx, repo.CreateUserParams{
+			Email:        input.Email,
+			PasswordHash: passwordHash,
+			Role:         input.Role,
+			FullName:     input.FullName,
+			PlotNumber:   sql.NullString{String: plot, Valid: plot != ""},
+			CreatedBy:    actor,
+			UpdatedBy:    actor,
+		})
+		if err != nil {
+			return err
+		}
+		return attachUserPlot(ctx, q, user, actor)
 	})
 	if err != nil {
 		return repo.User{}, err
@@ -139,6 +177,34 @@ func (s *Service) CreateUser(ctx context.Context, input UserCreateInput, passwor
 	return user, nil
 }
 
+// BootstrapAdmin creates the first admin of an empty database. It reports
+// false when users already exist; concurrent starts are serialized by an
+// advisory lock, so only one of them creates the admin.
+func (s *Service) BootstrapAdmin(ctx context.Context, email, fullName, passwordHash string) (bool, error) {
+	created := false
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		created = false
+		if err := q.LockUserBootstrap(ctx); err != nil {
+			return err
+		}
+		count, err := q.CountUsers(ctx)
+		if err != nil || count > 0 {
+			return err
+		}
+		if _, err := q.CreateUser(ctx, repo.CreateUserParams{
+			Email:        email,
+			PasswordHash: passwordHash,
+			Role:         "admin",
+			FullName:     fullName,
+		}); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
 func (s *Service) GetUser(ctx context.Context, id uuid.UUID) (repo.User, error) {
 	user, err := s.q.GetUserByID(ctx, id)
 	if err != nil {
@@ -176,18 +242,37 @@ func (s *Service) UpdateUser(ctx context.Context, input UserUpdateInput) (repo.U
 	if err := ValidateRole(input.Role); err != nil {
 		return repo.User{}, err
 	}
-	plot := strings.TrimSpace(input.PlotNumber)
+	plot := NormalizePlotNumber(input.PlotNumber)
 	if input.Role == "resident" && plot == "" {
 		return repo.User{}, ErrInvalidInput
 	}
-	user, err := s.q.UpdateUser(ctx, repo.UpdateUserParams{
-		ID:         input.ID,
-		Email:      input.Email,
-		Role:       input.Role,
-		FullName:   input.FullName,
-		PlotNumber: sql.NullString{String: plot, Valid: plot != ""},
-		UpdatedBy: uuid.NullUUID{UUID: input.ActorID,
-			Valid: input.ActorID != uuid.Nil},
+	if plot != "" {
+		if err := ValidatePlotNumber(plot); err != nil {
+			return repo.User{}, err
+		}
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	plotNumber := sql.NullString{String: plot, Valid: plot != ""}
+	var user repo.User
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		// Changing the plot number moves the user off the old plot; their
+		// other plots stay linked.
+		if err := q.UnlinkPreviousUserPlot(ctx, repo.UnlinkPreviousUserPlotParams{UserID: input.ID, PlotNumber: plotNumber}); err != nil {
+			return err
+		}
+		var err error
+		user, err = q.UpdateUser(ctx, repo.UpdateUserParams{
+			ID:         input.ID,
+			Email:      input.Email,
+			Role:       input.Role,
+			FullName:   input.FullName,
+			PlotNumber: plotNumber,
+			UpdatedBy:  actor,
+		})
+		if err != nil {
+			return err
+		}
+		return attachUserPlot(ctx, q, user, actor)
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -216,32 +301,22 @@ func (s *Service) UpdateUserPassword(ctx context.Context, input UserPasswordInpu
 	return user, nil
 }
 
-func (s *Service) SoftDeleteUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return s.q.SoftDeleteUser(ctx, repo.SoftDeleteUserParams{ID: id, UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}})
-}
-
-func (s *Service) RestoreUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return s.q.RestoreUser(ctx, repo.RestoreUserParams{ID: id, UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}})
-}
-
-func (s *Service) BlockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return s.q.BlockUser(ctx, repo.BlockUserParams{ID: id, UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}})
-}
-
-func (s *Service) UnblockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
-	return s.q.UnblockUser(ctx, repo.UnblockUserParams{ID: id, UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}})
-}
-
 func (s *Service) CreatePass(ctx context.Context, input PassCreateInput) (repo.Pass, error) {
 	if err := ValidatePlate(input.PlateNumber); err != nil {
 		return repo.Pass{}, err
 	}
+	if input.PlotID != uuid.Nil {
+		if err := checkUserPlot(ctx, s.q, input.PlotID, input.OwnerID); err != nil {
+			return repo.Pass{}, err
+		}
+	}
 	pass, err := s.q.CreatePass(ctx, repo.CreatePassParams{
 		OwnerUserID:  input.OwnerID,
 		PlateNumber:  NormalizePlate(input.PlateNumber),
 		VehicleBrand: input.VehicleBrand,
 		VehicleColor: input.VehicleColor,
 		Status:       input.Status,
+		PlotID:       uuid.NullUUID{UUID: input.PlotID, Valid: input.PlotID != uuid.Nil},
 		CreatedBy:    uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
 		UpdatedBy:    uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
 	})
@@ -302,21 +377,73 @@ func (s *Service) UpdatePass(ctx context.Context, input PassUpdateInput) (repo.P
 	if err := ValidatePlate(input.PlateNumber); err != nil {
 		return repo.Pass{}, err
 	}
-	pass, err := s.q.UpdatePass(ctx, repo.UpdatePassParams{
+	var pass repo.Pass
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if input.PlotID != uuid.Nil {
+			current, err := q.GetPassByID(ctx, input.ID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if err := checkUserPlot(ctx, q, input.PlotID, current.OwnerUserID); err != nil {
+				return err
+			}
+		}
+		var err error
+		pass, err = updatePass(ctx, q, input)
+		return err
+	})
+	return pass, err
+}
+
+// ModifyPass updates a pass from its current state. The pass row stays
+// locked while fn builds the update, so a concurrent change such as a
+// cascade suspension is not overwritten with a stale copy.
+func (s *Service) ModifyPass(ctx context.Context, id uuid.UUID, fn func(current repo.Pass) (PassUpdateInput, error)) (repo.Pass, error) {
+	var pass repo.Pass
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		current, err := q.GetPassByIDForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		input, err := fn(current)
+		if err != nil {
+			return err
+		}
+		input.ID = id
+		if err := ValidatePlate(input.PlateNumber); err != nil {
+			return err
+		}
+		if input.PlotID != uuid.Nil {
+			if err := checkUserPlot(ctx, q, input.PlotID, current.OwnerUserID); err != nil {
+				return err
+			}
+		}
+		pass, err = updatePass(ctx, q, input)
+		return err
+	})
+	return pass, err
+}
+
+func updatePass(ctx context.Context, q ServiceStore, input PassUpdateInput) (repo.Pass, error) {
+	pass, err := q.UpdatePass(ctx, repo.UpdatePassParams{
 		ID:           input.ID,
 		PlateNumber:  NormalizePlate(input.PlateNumber),
 		VehicleBrand: input.VehicleBrand,
 		VehicleColor: input.VehicleColor,
 		Status:       input.Status,
+		PlotID:       uuid.NullUUID{UUID: input.PlotID, Valid: input.PlotID != uuid.Nil},
 		UpdatedBy:    uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
 	})
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return repo.Pass{}, ErrNotFound
-		}
-		return repo.Pass{}, err
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.Pass{}, ErrNotFound
 	}
-	return pass, nil
+	return pass, err
 }
 
 func (s *Service) SoftDeletePass(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
@@ -328,21 +455,38 @@ func (s *Service) RestorePass(ctx context.Context, id uuid.UUID, actor uuid.UUID
 }
 
 func (s *Service) CreateGuestRequest(ctx context.Context, input GuestCreateInput) (repo.GuestRequest, error) {
-	if err := ValidatePlate(input.PlateNumber); err != nil {
+	details, err := s.resolveGuestDetails(input.GuestType, input.PlateNumber, input.CompanyName, input.ValidFrom, input.ValidTo)
+	if err != nil {
+		return repo.GuestRequest{}, err
+	}
+	if err := s.checkGuestWindow(ctx, details.PlateNumber, details.ValidFrom, details.ValidTo, uuid.Nil); err != nil {
 		return repo.GuestRequest{}, err
 	}
-	if input.ValidFrom.After(input.ValidTo) {
-		return repo.GuestRequest{}, ErrInvalidRange
+	if input.PlotID != uuid.Nil {
+		if err := checkUserPlot(ctx, s.q, input.PlotID, input.ResidentID); err != nil {
+			return repo.GuestRequest{}, err
+		}
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	review, err := s.initialReview(input.Status, details.ValidTo.Sub(details.ValidFrom), actor)
+	if err != nil {
+		return repo.GuestRequest{}, err
 	}
 	guest, err := s.q.CreateGuestRequest(ctx, repo.CreateGuestRequestParams{
 		ResidentUserID: input.ResidentID,
 		GuestFullName:  input.GuestName,
-		PlateNumber:    NormalizePlate(input.PlateNumber),
-		ValidFrom:      input.ValidFrom,
-		ValidTo:        input.ValidTo,
-		Status:         input.Status,
-		CreatedBy:      uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
-		UpdatedBy:      uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+		PlateNumber:    details.PlateNumber,
+		ValidFrom:      details.ValidFrom,
+		ValidTo:        details.ValidTo,
+		Status:         review.Status,
+		CreatedBy:      actor,
+		UpdatedBy:      actor,
+		ReviewedBy:     review.ReviewedBy,
+		ReviewedAt:     review.ReviewedAt,
+		ReviewReason:   review.ReviewReason,
+		GuestType:      details.Type,
+		CompanyName:    details.CompanyName,
+		PlotID:         uuid.NullUUID{UUID: input.PlotID, Valid: input.PlotID != uuid.Nil},
 	})
 	if err != nil {
 		return repo.GuestRequest{}, err
@@ -386,28 +530,85 @@ func (s *Service) ListGuestRequests(ctx context.Context, includeDeleted bool, li
 }
 
 func (s *Service) UpdateGuestRequest(ctx context.Context, input GuestUpdateInput) (repo.GuestRequest, error) {
-	if err := ValidatePlate(input.PlateNumber); err != nil {
+	details, err := s.checkGuestUpdate(ctx, input)
+	if err != nil {
 		return repo.GuestRequest{}, err
 	}
-	if input.ValidFrom.After(input.ValidTo) {
-		return repo.GuestRequest{}, ErrInvalidRange
+	if input.PlotID != uuid.Nil {
+		current, err := s.GetGuestRequest(ctx, input.ID)
+		if err != nil {
+			return repo.GuestRequest{}, err
+		}
+		if err := checkUserPlot(ctx, s.q, input.PlotID, current.ResidentUserID); err != nil {
+			return repo.GuestRequest{}, err
+		}
 	}
-	guest, err := s.q.UpdateGuestRequest(ctx, repo.UpdateGuestRequestParams{
+	return updateGuestRequest(ctx, s.q, input, details)
+}
+
+// ModifyGuestRequest updates a guest request from its current state. The
+// row stays locked while fn builds the update, so a review landing in
+// between cannot be edited over; the status always stays the current one.
+func (s *Service) ModifyGuestRequest(ctx context.Context, id uuid.UUID, fn func(current repo.GuestRequest) (GuestUpdateInput, error)) (repo.GuestRequest, error) {
+	var guest repo.GuestRequest
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		current, err := q.GetGuestRequestByIDForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		input, err := fn(current)
+		if err != nil {
+			return err
+		}
+		input.ID, input.Status = id, current.Status
+		details, err := s.checkGuestUpdate(ctx, input)
+		if err != nil {
+			return err
+		}
+		if input.PlotID != uuid.Nil {
+			if err := checkUserPlot(ctx, q, input.PlotID, current.ResidentUserID); err != nil {
+				return err
+			}
+		}
+		guest, err = updateGuestRequest(ctx, q, input, details)
+		return err
+	})
+	return guest, err
+}
+
+func (s *Service) checkGuestUpdate(ctx context.Context, input GuestUpdateInput) (guestDetails, error) {
+	details, err := s.resolveGuestDetails(input.GuestType, input.PlateNumber, input.CompanyName, input.ValidFrom, input.ValidTo)
+	if err != nil {
+		return guestDetails{}, err
+	}
+	if !GuestEditable(input.Status) {
+		return guestDetails{}, ErrGuestTransition
+	}
+	if err := s.checkGuestWindow(ctx, details.PlateNumber, details.ValidFrom, details.ValidTo, input.ID); err != nil {
+		return guestDetails{}, err
+	}
+	return details, nil
+}
+
+func updateGuestRequest(ctx context.Context, q ServiceStore, input GuestUpdateInput, details guestDetails) (repo.GuestRequest, error) {
+	guest, err := q.UpdateGuestRequest(ctx, repo.UpdateGuestRequestParams{
 		ID:            input.ID,
 		GuestFullName: input.GuestName,
-		PlateNumber:   NormalizePlate(input.PlateNumber),
-		ValidFrom:     input.ValidFrom,
-		ValidTo:       input.ValidTo,
-		Status:        input.Status,
+		PlateNumber:   details.PlateNumber,
+		ValidFrom:     details.ValidFrom,
+		ValidTo:       details.ValidTo,
+		GuestType:     details.Type,
+		CompanyName:   details.CompanyName,
+		PlotID:        uuid.NullUUID{UUID: input.PlotID, Valid: input.PlotID != uuid.Nil},
 		UpdatedBy:     uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
 	})
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return repo.GuestRequest{}, ErrNotFound
-		}
-		return repo.GuestRequest{}, err
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.GuestRequest{}, ErrNotFound
 	}
-	return guest, nil
+	return guest, err
 }
 
 func (s *Service) SoftDeleteGuestRequest(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
@@ -419,9 +620,11 @@ func (s *Service) RestoreGuestRequest(ctx context.Context, id uuid.UUID, actor u
 }
 
 func (s *Service) CreateEntryLog(ctx context.Context, passID, guardID uuid.UUID, action string, comment sql.NullString) (repo.EntryLog, error) {
-	return s.q.CreateEntryLog(ctx, repo.CreateEntryLogParams{PassID: passID, GuardUserID: guardID, Action: action, Comment: comment})
-}
-
-func (s *Service) ListEntryLogs(ctx context.Context, passID uuid.UUID, limit, offset int32) ([]repo.EntryLog, error) {
-	return s.q.ListEntryLogsByPass(ctx, repo.ListEntryLogsByPassParams{PassID: passID, Limit: limit, Offset: offset})
+	var entry repo.EntryLog
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		var err error
+		entry, err = s.appendEntryLog(ctx, q, repo.CreateEntryLogParams{PassID: uuid.NullUUID{UUID: passID, Valid: true}, GuardUserID: guardID, Action: action, Comment: comment})
+		return err
+	})
+	return entry, err
 }
@@ -17,36 +17,196 @@ import (
 var errMockUnimplemented = errors.New("mock method not implemented")
 
 type mockStore struct {
-	createUserFn                  func(context.Context, repo.CreateUserParams) (repo.User, error)
-	getUserByEmailFn              func(context.Context, string) (repo.User, error)
-	getUserByIDFn                 func(context.Context, uuid.UUID) (repo.User, error)
-	getUserByIDAnyFn              func(context.Context, uuid.UUID) (repo.User, error)
-	listUsersFn                   func(context.Context, repo.ListUsersParams) ([]repo.User, error)
-	updateUserFn                  func(context.Context, repo.UpdateUserParams) (repo.User, error)
-	updateUserPasswordFn          func(context.Context, repo.UpdateUserPasswordParams) (repo.User, error)
-	restoreUserFn                 func(context.Context, repo.RestoreUserParams) error
-	blockUserFn                   func(context.Context, repo.BlockUserParams) error
-	unblockUserFn                 func(context.Context, repo.UnblockUserParams) error
-	softDeleteUserFn              func(context.Context, repo.SoftDeleteUserParams) error
-	createPassFn                  func(context.Context, repo.CreatePassParams) (repo.Pass, error)
-	getPassByIDFn                 func(context.Context, uuid.UUID) (repo.Pass, error)
-	getPassByIDAnyFn              func(context.Context, uuid.UUID) (repo.Pass, error)
-	listPassesFn                  func(context.Context, repo.ListPassesParams) ([]repo.Pass, error)
-	listPassesByOwnerFn           func(context.Context, repo.ListPassesByOwnerParams) ([]repo.Pass, error)
-	searchPassesByPlateFn         func(context.Context, repo.SearchPassesByPlateParams) ([]repo.Pass, error)
-	updatePassFn                  func(context.Context, repo.UpdatePassParams) (repo.Pass, error)
-	restorePassFn                 func(context.Context, repo.RestorePassParams) error
-	softDeletePassFn              func(context.Context, repo.SoftDeletePassParams) error
-	createGuestRequestFn          func(context.Context, repo.CreateGuestRequestParams) (repo.GuestRequest, error)
-	getGuestRequestByIDFn         func(context.Context, uuid.UUID) (repo.GuestRequest, error)
-	getGuestRequestByIDAnyFn      func(context.Context, uuid.UUID) (repo.GuestRequest, error)
-	listGuestRequestsFn           func(context.Context, repo.ListGuestRequestsParams) ([]repo.GuestRequest, error)
-	listGuestRequestsByResidentFn func(context.Context, repo.ListGuestRequestsByResidentParams) ([]repo.GuestRequest, error)
-	updateGuestRequestFn          func(context.Context, repo.UpdateGuestRequestParams) (repo.GuestRequest, error)
-	restoreGuestRequestFn         func(context.Context, repo.RestoreGuestRequestParams) error
-	softDeleteGuestRequestFn      func(context.Context, repo.SoftDeleteGuestRequestParams) error
-	createEntryLogFn              func(context.Context, repo.CreateEntryLogParams) (repo.EntryLog, error)
-	listEntryLogsByPassFn         func(context.Context, repo.ListEntryLogsByPassParams) ([]repo.EntryLog, error)
+	createUserFn                   func(context.Context, repo.CreateUserParams) (repo.User, error)
+	getUserByEmailFn               func(context.Context, string) (repo.User, error)
+	getUserByIDFn                  func(context.Context, uuid.UUID) (repo.User, error)
+	getUserByIDAnyFn               func(context.Context, uuid.UUID) (repo.User, error)
+	listUsersFn                    func(context.Context, repo.ListUsersParams) ([]repo.User, error)
+	updateUserFn                   func(context.Context, repo.UpdateUserParams) (repo.User, error)
+	updateUserPasswordFn           func(context.Context, repo.UpdateUserPasswordParams) (repo.User, error)
+	restoreUserFn                  func(context.Context, repo.RestoreUserParams) error
+	blockUserFn                    func(context.Context, repo.BlockUserParams) error
+	unblockUserFn                  func(context.Context, repo.UnblockUserParams) error
+	softDeleteUserFn               func(context.Context, repo.SoftDeleteUserParams) error
+	createPassFn                   func(context.Context, repo.CreatePassParams) (repo.Pass, error)
+	getPassByIDFn                  func(context.Context, uuid.UUID) (repo.Pass, error)
+	getPassByIDAnyFn               func(context.Context, uuid.UUID) (repo.Pass, error)
+	listPassesFn                   func(context.Context, repo.ListPassesParams) ([]repo.Pass, error)
+	listPassesByOwnerFn            func(context.Context, repo.ListPassesByOwnerParams) ([]repo.Pass, error)
+	searchPassesByPlateFn          func(context.Context, repo.SearchPassesByPlateParams) ([]repo.Pass, error)
+	updatePassFn                   func(context.Context, repo.UpdatePassParams) (repo.Pass, error)
+	restorePassFn                  func(context.Context, repo.RestorePassParams) error
+	softDeletePassFn               func(context.Context, repo.SoftDeletePassParams) error
+	createGuestRequestFn           func(context.Context, repo.CreateGuestRequestParams) (repo.GuestRequest, error)
+	getGuestRequestByIDFn          func(context.Context, uuid.UUID) (repo.GuestRequest, error)
+	getGuestRequestByIDAnyFn       func(context.Context, uuid.UUID) (repo.GuestRequest, error)
+	listGuestRequestsFn            func(context.Context, repo.ListGuestRequestsParams) ([]repo.GuestRequest, error)
+	listGuestRequestsByResidentFn  func(context.Context, repo.ListGuestRequestsByResidentParams) ([]repo.GuestRequest, error)
+	updateGuestRequestFn           func(context.Context, repo.UpdateGuestRequestParams) (repo.GuestRequest, error)
+	restoreGuestRequestFn          func(context.Context, repo.RestoreGuestRequestParams) error
+	softDeleteGuestRequestFn       func(context.Context, repo.SoftDeleteGuestRequestParams) error
+	reviewGuestRequestFn           func(context.Context, repo.ReviewGuestRequestParams) (repo.GuestRequest, error)
+	setGuestRequestStatusFn        func(context.Context, repo.SetGuestRequestStatusParams) (repo.GuestRequest, error)
+	expireGuestRequestsFn          func(context.Context, time.Time) (int64, error)
+	listGateGuestsFn               func(context.Context, repo.ListGateGuestsParams) ([]repo.ListGateGuestsRow, error)
+	createEntryLogFn               func(context.Context, repo.CreateEntryLogParams) (repo.EntryLog, error)
+	listEntryLogsFn                func(context.Context, repo.ListEntryLogsParams) ([]repo.ListEntryLogsRow, error)
+	createPassScheduleFn           func(context.Context, repo.CreatePassScheduleParams) (repo.PassSchedule, error)
+	listPassSchedulesFn            func(context.Context, uuid.UUID) ([]repo.PassSchedule, error)
+	deletePassScheduleFn           func(context.Context, repo.DeletePassScheduleParams) (int64, error)
+	createHolidayFn                func(context.Context, repo.CreateHolidayParams) (repo.Holiday, error)
+	listHolidaysFn                 func(context.Context, repo.ListHolidaysParams) ([]repo.Holiday, error)
+	isHolidayFn                    func(context.Context, time.Time) (bool, error)
+	deleteHolidayFn                func(context.Context, time.Time) (int64, error)
+	getUserByEmailAnyFn            func(context.Context, string) (repo.User, error)
+	upsertUserByEmailFn            func(context.Context, repo.UpsertUserByEmailParams) (repo.User, error)
+	getPassByOwnerAndPlateFn       func(context.Context, repo.GetPassByOwnerAndPlateParams) (repo.Pass, error)
+	exportUsersFn                  func(context.Context, repo.ExportUsersParams) ([]repo.User, error)
+	exportPassesFn                 func(context.Context, repo.ExportPassesParams) ([]repo.ExportPassesRow, error)
+	exportGuestRequestsFn          func(context.Context, repo.ExportGuestRequestsParams) ([]repo.ExportGuestRequestsRow, error)
+	exportEntryLogsFn              func(context.Context, repo.ExportEntryLogsParams) ([]repo.ExportEntryLogsRow, error)
+	createWatchlistEntryFn         func(context.Context, repo.CreateWatchlistEntryParams) (repo.PlateWatchlist, error)
+	getWatchlistEntryByIDFn        func(context.Context, uuid.UUID) (repo.PlateWatchlist, error)
+	getWatchlistEntryByPlateFn     func(context.Context, string) (repo.PlateWatchlist, error)
+	listWatchlistFn                func(context.Context, repo.ListWatchlistParams) ([]repo.PlateWatchlist, error)
+	updateWatchlistEntryFn         func(context.Context, repo.UpdateWatchlistEntryParams) (repo.PlateWatchlist, error)
+	softDeleteWatchlistEntryFn     func(context.Context, repo.SoftDeleteWatchlistEntryParams) (int64, error)
+	createWatchlistHitFn           func(context.Context, repo.CreateWatchlistHitParams) (repo.WatchlistHit, error)
+	listWatchlistHitsFn            func(context.Context, repo.ListWatchlistHitsParams) ([]repo.WatchlistHit, error)
+	listOverlappingGuestRequestsFn func(context.Context, repo.ListOverlappingGuestRequestsParams) ([]uuid.UUID, error)
+	materializeGuestOccurrenceFn   func(context.Context, repo.MaterializeGuestOccurrenceParams) error
+	getGuestOccurrenceFn           func(context.Context, repo.GetGuestOccurrenceParams) (repo.GuestRequest, error)
+	listGuestOccurrencesFn         func(context.Context, repo.ListGuestOccurrencesParams) ([]repo.GuestRequest, error)
+	updateGuestOccurrenceFn        func(context.Context, repo.UpdateGuestOccurrenceParams) (repo.GuestRequest, error)
+	deleteGuestOccurrencesFromFn   func(context.Context, repo.DeleteGuestOccurrencesFromParams) (int64, error)
+	reviewGuestOccurrencesFn       func(context.Context, repo.ReviewGuestOccurrencesParams) (int64, error)
+	createGuestSeriesFn            func(context.Context, repo.CreateGuestSeriesParams) (repo.GuestSeries, error)
+	getGuestSeriesByIDFn           func(context.Context, uuid.UUID) (repo.GuestSeries, error)
+	listGuestSeriesFn              func(context.Context, repo.ListGuestSeriesParams) ([]repo.GuestSeries, error)
+	listGuestSeriesByResidentFn    func(context.Context, repo.ListGuestSeriesByResidentParams) ([]repo.GuestSeries, error)
+	listActiveGuestSeriesFn        func(context.Context, repo.ListActiveGuestSeriesParams) ([]repo.GuestSeries, error)
+	updateGuestSeriesFn            func(context.Context, repo.UpdateGuestSeriesParams) (repo.GuestSeries, error)
+	endGuestSeriesFn               func(context.Context, repo.EndGuestSeriesParams) (repo.GuestSeries, error)
+	reviewGuestSeriesFn            func(context.Context, repo.ReviewGuestSeriesParams) (repo.GuestSeries, error)
+	setGuestSeriesStatusFn         func(context.Context, repo.SetGuestSeriesStatusParams) (repo.GuestSeries, error)
+	getGuestPinFn                  func(context.Context, uuid.UUID) (repo.GuestPin, error)
+	deleteGuestPinFn               func(context.Context, uuid.UUID) error
+	releaseGuestPinCodeFn          func(context.Context, repo.ReleaseGuestPinCodeParams) error
+	createGuestPinFn               func(context.Context, repo.CreateGuestPinParams) (repo.GuestPin, error)
+	getGuestByPinFn                func(context.Context, repo.GetGuestByPinParams) (repo.GetGuestByPinRow, error)
+	useGuestPinFn                  func(context.Context, uuid.UUID) error
+	createSavedGuestFn             func(context.Context, repo.CreateSavedGuestParams) (repo.SavedGuest, error)
+	getSavedGuestFn                func(context.Context, repo.GetSavedGuestParams) (repo.SavedGuest, error)
+	listSavedGuestsFn              func(context.Context, repo.ListSavedGuestsParams) ([]repo.SavedGuest, error)
+	updateSavedGuestFn             func(context.Context, repo.UpdateSavedGuestParams) (repo.SavedGuest, error)
+	deleteSavedGuestFn             func(context.Context, repo.DeleteSavedGuestParams) (int64, error)
+	listFrequentGuestsFn           func(context.Context, repo.ListFrequentGuestsParams) ([]repo.ListFrequentGuestsRow, error)
+	getPassPresenceFn              func(context.Context, uuid.NullUUID) (repo.SitePresence, error)
+	upsertPassPresenceFn           func(context.Context, repo.UpsertPassPresenceParams) error
+	deletePassPresenceFn           func(context.Context, uuid.NullUUID) (int64, error)
+	upsertGuestPresenceFn          func(context.Context, repo.UpsertGuestPresenceParams) error
+	deleteGuestPresenceFn          func(context.Context, uuid.NullUUID) (int64, error)
+	listSitePresenceFn             func(context.Context, sql.NullString) ([]repo.ListSitePresenceRow, error)
+	getEntryLogFn                  func(context.Context, uuid.UUID) (repo.EntryLog, error)
+	listEntryLogChainFn            func(context.Context, repo.ListEntryLogChainParams) ([]repo.EntryLog, error)
+	lockEntryLogChainFn            func(context.Context) error
+	getEntryLogChainHeadFn         func(context.Context) (repo.GetEntryLogChainHeadRow, error)
+	createGateFn                   func(context.Context, repo.CreateGateParams) (repo.Gate, error)
+	getGateFn                      func(context.Context, uuid.UUID) (repo.Gate, error)
+	getGateByNameFn                func(context.Context, string) (repo.Gate, error)
+	updateGateFn                   func(context.Context, repo.UpdateGateParams) (repo.Gate, error)
+	softDeleteGateFn               func(context.Context, repo.SoftDeleteGateParams) (int64, error)
+	countGateRestrictionsFn        func(context.Context, uuid.UUID) (int64, error)
+	listGuardGatesFn               func(context.Context, uuid.UUID) ([]repo.Gate, error)
+	addGuardGateFn                 func(context.Context, repo.AddGuardGateParams) error
+	clearGuardGatesFn              func(context.Context, uuid.UUID) error
+	deleteGateAssignmentsFn        func(context.Context, uuid.UUID) error
+	listPassGatesFn                func(context.Context, uuid.UUID) ([]repo.Gate, error)
+	addPassGateFn                  func(context.Context, repo.AddPassGateParams) error
+	clearPassGatesFn               func(context.Context, uuid.UUID) error
+	listGuestTypeGatesFn           func(context.Context, string) ([]repo.Gate, error)
+	addGuestTypeGateFn             func(context.Context, repo.AddGuestTypeGateParams) error
+	clearGuestTypeGatesFn          func(context.Context, string) error
+	gateStatsFn                    func(context.Context, repo.GateStatsParams) ([]repo.GateStatsRow, error)
+	listGatesFn                    func(context.Context) ([]repo.Gate, error)
+	listAllGuestTypeGatesFn        func(context.Context) ([]repo.ListAllGuestTypeGatesRow, error)
+	createGuardShiftFn             func(context.Context, repo.CreateGuardShiftParams) (repo.GuardShift, error)
+	getGuardShiftFn                func(context.Context, uuid.UUID) (repo.GuardShift, error)
+	getOpenGuardShiftFn            func(context.Context, uuid.UUID) (repo.GuardShift, error)
+	getPendingHandoverFn           func(context.Context, uuid.NullUUID) (repo.GuardShift, error)
+	endGuardShiftFn                func(context.Context, repo.EndGuardShiftParams) (repo.GuardShift, error)
+	listGuardShiftsFn              func(context.Context, repo.ListGuardShiftsParams) ([]repo.ListGuardShiftsRow, error)
+	guardShiftSummaryFn            func(context.Context, repo.GuardShiftSummaryParams) (repo.GuardShiftSummaryRow, error)
+	countVehiclesOnSiteFn          func(context.Context) (int64, error)
+	createIncidentFn               func(context.Context, repo.CreateIncidentParams) (repo.Incident, error)
+	getIncidentFn                  func(context.Context, uuid.UUID) (repo.Incident, error)
+	listIncidentsFn                func(context.Context, repo.ListIncidentsParams) ([]repo.ListIncidentsRow, error)
+	updateIncidentStatusFn         func(context.Context, repo.UpdateIncidentStatusParams) (repo.Incident, error)
+	createIncidentCommentFn        func(context.Context, repo.CreateIncidentCommentParams) (repo.IncidentComment, error)
+	listIncidentCommentsFn         func(context.Context, uuid.UUID) ([]repo.ListIncidentCommentsRow, error)
+	createIncidentAttachmentFn     func(context.Context, repo.CreateIncidentAttachmentParams) (repo.IncidentAttachment, error)
+	getIncidentAttachmentFn        func(context.Context, uuid.UUID) (repo.IncidentAttachment, error)
+	listIncidentAttachmentsFn      func(context.Context, uuid.UUID) ([]repo.IncidentAttachment, error)
+	deleteIncidentAttachmentFn     func(context.Context, uuid.UUID) (int64, error)
+	createEntryPhotoFn             func(context.Context, repo.CreateEntryPhotoParams) (repo.EntryPhoto, error)
+	getEntryPhotoFn                func(context.Context, uuid.UUID) (repo.EntryPhoto, error)
+	getEntryPhotoByLogFn           func(context.Context, uuid.UUID) (repo.EntryPhoto, error)
+	listEntryPhotosBeforeFn        func(context.Context, repo.ListEntryPhotosBeforeParams) ([]repo.EntryPhoto, error)
+	deleteEntryPhotoFn             func(context.Context, uuid.UUID) error
+	createCameraFn                 func(context.Context, repo.CreateCameraParams) (repo.Camera, error)
+	getCameraFn                    func(context.Context, uuid.UUID) (repo.Camera, error)
+	getCameraByNameFn              func(context.Context, string) (repo.Camera, error)
+	getCameraByTokenFn             func(context.Context, string) (repo.Camera, error)
+	listCamerasFn                  func(context.Context) ([]repo.Camera, error)
+	updateCameraFn                 func(context.Context, repo.UpdateCameraParams) (repo.Camera, error)
+	setCameraTokenFn               func(context.Context, repo.SetCameraTokenParams) (int64, error)
+	touchCameraFn                  func(context.Context, repo.TouchCameraParams) error
+	softDeleteCameraFn             func(context.Context, repo.SoftDeleteCameraParams) (int64, error)
+	listActivePassesByPlateFn      func(context.Context, string) ([]repo.Pass, error)
+	listCurrentGuestsByPlateFn     func(context.Context, repo.ListCurrentGuestsByPlateParams) ([]repo.GuestRequest, error)
+	createPlateReadFn              func(context.Context, repo.CreatePlateReadParams) (repo.PlateRead, error)
+	getPlateReadFn                 func(context.Context, uuid.UUID) (repo.PlateRead, error)
+	listPlateReadsFn               func(context.Context, repo.ListPlateReadsParams) ([]repo.ListPlateReadsRow, error)
+	claimPlateReadFn               func(context.Context, repo.ClaimPlateReadParams) (repo.PlateRead, error)
+	reopenPlateReadFn              func(context.Context, uuid.UUID) error
+	setPlateReadEntryLogFn         func(context.Context, repo.SetPlateReadEntryLogParams) error
+	upsertGateBarrierFn            func(context.Context, repo.UpsertGateBarrierParams) (repo.GateBarrier, error)
+	getGateBarrierFn               func(context.Context, uuid.UUID) (repo.GateBarrier, error)
+	deleteGateBarrierFn            func(context.Context, uuid.UUID) (int64, error)
+	createBarrierEventFn           func(context.Context, repo.CreateBarrierEventParams) (repo.BarrierEvent, error)
+	listBarrierEventsFn            func(context.Context, repo.ListBarrierEventsParams) ([]repo.BarrierEvent, error)
+	listSyncPassesFn               func(context.Context, sql.NullTime) ([]repo.ListSyncPassesRow, error)
+	listSyncGuestsFn               func(context.Context, repo.ListSyncGuestsParams) ([]repo.ListSyncGuestsRow, error)
+	createPlotFn                   func(context.Context, repo.CreatePlotParams) (repo.Plot, error)
+	ensurePlotFn                   func(context.Context, repo.EnsurePlotParams) (repo.Plot, error)
+	getPlotFn                      func(context.Context, uuid.UUID) (repo.Plot, error)
+	getPlotByNumberFn              func(context.Context, string) (repo.Plot, error)
+	listPlotsFn                    func(context.Context, repo.ListPlotsParams) ([]repo.Plot, error)
+	updatePlotFn                   func(context.Context, repo.UpdatePlotParams) (repo.Plot, error)
+	renameUserPlotNumbersFn        func(context.Context, repo.RenameUserPlotNumbersParams) error
+	listPlotResidentsFn            func(context.Context, uuid.UUID) ([]repo.ListPlotResidentsRow, error)
+	listUserPlotsFn                func(context.Context, uuid.UUID) ([]repo.ListUserPlotsRow, error)
+	getUserPlotFn                  func(context.Context, repo.GetUserPlotParams) (repo.GetUserPlotRow, error)
+	upsertPlotResidentFn           func(context.Context, repo.UpsertPlotResidentParams) (repo.PlotResident, error)
+	addPlotResidentFn              func(context.Context, repo.AddPlotResidentParams) (int64, error)
+	deletePlotResidentFn           func(context.Context, repo.DeletePlotResidentParams) (int64, error)
+	unlinkPreviousUserPlotFn       func(context.Context, repo.UnlinkPreviousUserPlotParams) error
+	refreshUserPlotNumberFn        func(context.Context, repo.RefreshUserPlotNumberParams) error
+	listPassesByPlotFn             func(context.Context, repo.ListPassesByPlotParams) ([]repo.Pass, error)
+	listGuestRequestsByPlotFn      func(context.Context, repo.ListGuestRequestsByPlotParams) ([]repo.GuestRequest, error)
+	suspendUserPassesFn            func(context.Context, repo.SuspendUserPassesParams) ([]uuid.UUID, error)
+	revokeUserPassesFn             func(context.Context, repo.RevokeUserPassesParams) ([]uuid.UUID, error)
+	suspendUserGuestRequestsFn     func(context.Context, repo.SuspendUserGuestRequestsParams) ([]uuid.UUID, error)
+	cancelUserGuestRequestsFn      func(context.Context, repo.CancelUserGuestRequestsParams) ([]uuid.UUID, error)
+	restoreCascadePassesFn         func(context.Context, repo.RestoreCascadePassesParams) ([]uuid.UUID, error)
+	restoreCascadeGuestRequestsFn  func(context.Context, repo.RestoreCascadeGuestRequestsParams) ([]uuid.UUID, error)
+	getUserByIDForUpdateFn         func(context.Context, uuid.UUID) (repo.User, error)
+	getPassByIDForUpdateFn         func(context.Context, uuid.UUID) (repo.Pass, error)
+	countUsersFn                   func(context.Context) (int64, error)
+	lockUserBootstrapFn            func(context.Context) error
+	getGuestRequestByIDForUpdateFn func(context.Context, uuid.UUID) (repo.GuestRequest, error)
+	getPassByIDAnyForUpdateFn      func(context.Context, uuid.UUID) (repo.Pass, error)
+	listExitPassesByPlateFn        func(context.Context, string) ([]repo.Pass, error)
 }
 
 func (m *mockStore) CreateUser(ctx context.Context, arg repo.CreateUserParams) (repo.User, error) {
@@ -73,6 +233,24 @@ func (m *mockStore) GetUserByIDAny(ctx context.Context, id uuid.UUID) (repo.User
 	}
 	return m.getUserByIDAnyFn(ctx, id)
 }
+func (m *mockStore) GetUserByIDForUpdate(ctx context.Context, id uuid.UUID) (repo.User, error) {
+	if m.getUserByIDForUpdateFn == nil {
+		return repo.User{}, errMockUnimplemented
+	}
+	return m.getUserByIDForUpdateFn(ctx, id)
+}
+func (m *mockStore) CountUsers(ctx context.Context) (int64, error) {
+	if m.countUsersFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.countUsersFn(ctx)
+}
+func (m *mockStore) LockUserBootstrap(ctx context.Context) error {
+	if m.lockUserBootstrapFn == nil {
+		return nil
+	}
+	return m.lockUserBootstrapFn(ctx)
+}
 func (m *mockStore) ListUsers(ctx context.Context, arg repo.ListUsersParams) ([]repo.User, error) {
 	if m.listUsersFn == nil {
 		return nil, errMockUnimplemented
@@ -133,6 +311,18 @@ func (m *mockStore) GetPassByIDAny(ctx context.Context, id uuid.UUID) (repo.Pass
 	}
 	return m.getPassByIDAnyFn(ctx, id)
 }
+func (m *mockStore) GetPassByIDAnyForUpdate(ctx context.Context, id uuid.UUID) (repo.Pass, error) {
+	if m.getPassByIDAnyForUpdateFn == nil {
+		return repo.Pass{}, errMockUnimplemented
+	}
+	return m.getPassByIDAnyForUpdateFn(ctx, id)
+}
+func (m *mockStore) GetPassByIDForUpdate(ctx context.Context, id uuid.UUID) (repo.Pass, error) {
+	if m.getPassByIDForUpdateFn == nil {
+		return repo.Pass{}, errMockUnimplemented
+	}
+	return m.getPassByIDForUpdateFn(ctx, id)
+}
 func (m *mockStore) ListPasses(ctx context.Context, arg repo.ListPassesParams) ([]repo.Pass, error) {
 	if m.listPassesFn == nil {
 		return nil, errMockUnimplemented
@@ -187,6 +377,12 @@ func (m *mockStore) GetGuestRequestByIDAny(ctx context.Context, id uuid.UUID) (r
 	}
 	return m.getGuestRequestByIDAnyFn(ctx, id)
 }
+func (m *mockStore) GetGuestRequestByIDForUpdate(ctx context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+	if m.getGuestRequestByIDForUpdateFn == nil {
+		return repo.GuestRequest{}, errMockUnimplemented
+	}
+	return m.getGuestRequestByIDForUpdateFn(ctx, id)
+}
 func (m *mockStore) ListGuestRequests(ctx context.Context, arg repo.ListGuestRequestsParams) ([]repo.GuestRequest, error) {
 	if m.listGuestRequestsFn == nil {
 		return nil, errMockUnimplemented
@@ -205,6 +401,199 @@ func (m *mockStore) UpdateGuestRequest(ctx context.Context, arg repo.UpdateGuest
 	}
 	return m.updateGuestRequestFn(ctx, arg)
 }
+func (m *mockStore) ReviewGuestRequest(ctx context.Context, arg repo.ReviewGuestRequestParams) (repo.GuestRequest, error) {
+	if m.reviewGuestRequestFn == nil {
+		return repo.GuestRequest{}, errMockUnimplemented
+	}
+	return m.reviewGuestRequestFn(ctx, arg)
+}
+func (m *mockStore) SetGuestRequestStatus(ctx context.Context, arg repo.SetGuestRequestStatusParams) (repo.GuestRequest, error) {
+	if m.setGuestRequestStatusFn == nil {
+		return repo.GuestRequest{}, errMockUnimplemented
+	}
+	return m.setGuestRequestStatusFn(ctx, arg)
+}
+func (m *mockStore) ExpireGuestRequests(ctx context.Context, validTo time.Time) (int64, error) {
+	if m.expireGuestRequestsFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.expireGuestRequestsFn(ctx, validTo)
+}
+func (m *mockStore) ListGateGuests(ctx context.Context, arg repo.ListGateGuestsParams) ([]repo.ListGateGuestsRow, error) {
+	if m.listGateGuestsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listGateGuestsFn(ctx, arg)
+}
+func (m *mockStore) ListOverlappingGuestRequests(ctx context.Context, arg repo.ListOverlappingGuestRequestsParams) ([]uuid.UUID, error) {
+	if m.listOverlappingGuestRequestsFn == nil {
+		return nil, nil
+	}
+	return m.listOverlappingGuestRequestsFn(ctx, arg)
+}
+
+func (m *mockStore) MaterializeGuestOccurrence(ctx context.Context, arg repo.MaterializeGuestOccurrenceParams) error {
+	if m.materializeGuestOccurrenceFn == nil {
+		return errMockUnimplemented
+	}
+	return m.materializeGuestOccurrenceFn(ctx, arg)
+}
+func (m *mockStore) GetGuestOccurrence(ctx context.Context, arg repo.GetGuestOccurrenceParams) (repo.GuestRequest, error) {
+	if m.getGuestOccurrenceFn == nil {
+		return repo.GuestRequest{}, errMockUnimplemented
+	}
+	return m.getGuestOccurrenceFn(ctx, arg)
+}
+func (m *mockStore) ListGuestOccurrences(ctx context.Context, arg repo.ListGuestOccurrencesParams) ([]repo.GuestRequest, error) {
+	if m.listGuestOccurrencesFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listGuestOccurrencesFn(ctx, arg)
+}
+func (m *mockStore) UpdateGuestOccurrence(ctx context.Context, arg repo.UpdateGuestOccurrenceParams) (repo.GuestRequest, error) {
+	if m.updateGuestOccurrenceFn == nil {
+		return repo.GuestRequest{}, errMockUnimplemented
+	}
+	return m.updateGuestOccurrenceFn(ctx, arg)
+}
+func (m *mockStore) DeleteGuestOccurrencesFrom(ctx context.Context, arg repo.DeleteGuestOccurrencesFromParams) (int64, error) {
+	if m.deleteGuestOccurrencesFromFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.deleteGuestOccurrencesFromFn(ctx, arg)
+}
+func (m *mockStore) ReviewGuestOccurrences(ctx context.Context, arg repo.ReviewGuestOccurrencesParams) (int64, error) {
+	if m.reviewGuestOccurrencesFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.reviewGuestOccurrencesFn(ctx, arg)
+}
+func (m *mockStore) CreateGuestSeries(ctx context.Context, arg repo.CreateGuestSeriesParams) (repo.GuestSeries, error) {
+	if m.createGuestSeriesFn == nil {
+		return repo.GuestSeries{}, errMockUnimplemented
+	}
+	return m.createGuestSeriesFn(ctx, arg)
+}
+func (m *mockStore) GetGuestSeriesByID(ctx context.Context, id uuid.UUID) (repo.GuestSeries, error) {
+	if m.getGuestSeriesByIDFn == nil {
+		return repo.GuestSeries{}, errMockUnimplemented
+	}
+	return m.getGuestSeriesByIDFn(ctx, id)
+}
+func (m *mockStore) ListGuestSeries(ctx context.Context, arg repo.ListGuestSeriesParams) ([]repo.GuestSeries, error) {
+	if m.listGuestSeriesFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listGuestSeriesFn(ctx, arg)
+}
+func (m *mockStore) ListGuestSeriesByResident(ctx context.Context, arg repo.ListGuestSeriesByResidentParams) ([]repo.GuestSeries, error) {
+	if m.listGuestSeriesByResidentFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listGuestSeriesByResidentFn(ctx, arg)
+}
+func (m *mockStore) ListActiveGuestSeries(ctx context.Context, arg repo.ListActiveGuestSeriesParams) ([]repo.GuestSeries, error) {
+	if m.listActiveGuestSeriesFn == nil {
+		return nil, nil
+	}
+	return m.listActiveGuestSeriesFn(ctx, arg)
+}
+func (m *mockStore) UpdateGuestSeries(ctx context.Context, arg repo.UpdateGuestSeriesParams) (repo.GuestSeries, error) {
+	if m.updateGuestSeriesFn == nil {
+		return repo.GuestSeries{}, errMockUnimplemented
+	}
+	return m.updateGuestSeriesFn(ctx, arg)
+}
+func (m *mockStore) EndGuestSeries(ctx context.Context, arg repo.EndGuestSeriesParams) (repo.GuestSeries, error) {
+	if m.endGuestSeriesFn == nil {
+		return repo.GuestSeries{}, errMockUnimplemented
+	}
+	return m.endGuestSeriesFn(ctx, arg)
+}
+func (m *mockStore) ReviewGuestSeries(ctx context.Context, arg repo.ReviewGuestSeriesParams) (repo.GuestSeries, error) {
+	if m.reviewGuestSeriesFn == nil {
+		return repo.GuestSeries{}, errMockUnimplemented
+	}
+	return m.reviewGuestSeriesFn(ctx, arg)
+}
+func (m *mockStore) SetGuestSeriesStatus(ctx context.Context, arg repo.SetGuestSeriesStatusParams) (repo.GuestSeries, error) {
+	if m.setGuestSeriesStatusFn == nil {
+		return repo.GuestSeries{}, errMockUnimplemented
+	}
+	return m.setGuestSeriesStatusFn(ctx, arg)
+}
+func (m *mockStore) GetGuestPin(ctx context.Context, guestRequestID uuid.UUID) (repo.GuestPin, error) {
+	if m.getGuestPinFn == nil {
+		return repo.GuestPin{}, errMockUnimplemented
+	}
+	return m.getGuestPinFn(ctx, guestRequestID)
+}
+func (m *mockStore) DeleteGuestPin(ctx context.Context, guestRequestID uuid.UUID) error {
+	if m.deleteGuestPinFn == nil {
+		return errMockUnimplemented
+	}
+	return m.deleteGuestPinFn(ctx, guestRequestID)
+}
+func (m *mockStore) ReleaseGuestPinCode(ctx context.Context, arg repo.ReleaseGuestPinCodeParams) error {
+	if m.releaseGuestPinCodeFn == nil {
+		return errMockUnimplemented
+	}
+	return m.releaseGuestPinCodeFn(ctx, arg)
+}
+func (m *mockStore) CreateGuestPin(ctx context.Context, arg repo.CreateGuestPinParams) (repo.GuestPin, error) {
+	if m.createGuestPinFn == nil {
+		return repo.GuestPin{}, errMockUnimplemented
+	}
+	return m.createGuestPinFn(ctx, arg)
+}
+func (m *mockStore) GetGuestByPin(ctx context.Context, arg repo.GetGuestByPinParams) (repo.GetGuestByPinRow, error) {
+	if m.getGuestByPinFn == nil {
+		return repo.GetGuestByPinRow{}, errMockUnimplemented
+	}
+	return m.getGuestByPinFn(ctx, arg)
+}
+func (m *mockStore) UseGuestPin(ctx context.Context, guestRequestID uuid.UUID) error {
+	if m.useGuestPinFn == nil {
+		return nil
+	}
+	return m.useGuestPinFn(ctx, guestRequestID)
+}
+func (m *mockStore) CreateSavedGuest(ctx context.Context, arg repo.CreateSavedGuestParams) (repo.SavedGuest, error) {
+	if m.createSavedGuestFn == nil {
+		return repo.SavedGuest{}, errMockUnimplemented
+	}
+	return m.createSavedGuestFn(ctx, arg)
+}
+func (m *mockStore) GetSavedGuest(ctx context.Context, arg repo.GetSavedGuestParams) (repo.SavedGuest, error) {
+	if m.getSavedGuestFn == nil {
+		return repo.SavedGuest{}, errMockUnimplemented
+	}
+	return m.getSavedGuestFn(ctx, arg)
+}
+func (m *mockStore) ListSavedGuests(ctx context.Context, arg repo.ListSavedGuestsParams) ([]repo.SavedGuest, error) {
+	if m.listSavedGuestsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listSavedGuestsFn(ctx, arg)
+}
+func (m *mockStore) UpdateSavedGuest(ctx context.Context, arg repo.UpdateSavedGuestParams) (repo.SavedGuest, error) {
+	if m.updateSavedGuestFn == nil {
+		return repo.SavedGuest{}, errMockUnimplemented
+	}
+	return m.updateSavedGuestFn(ctx, arg)
+}
+func (m *mockStore) DeleteSavedGuest(ctx context.Context, arg repo.DeleteSavedGuestParams) (int64, error) {
+	if m.deleteSavedGuestFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.deleteSavedGuestFn(ctx, arg)
+}
+func (m *mockStore) ListFrequentGuests(ctx context.Context, arg repo.ListFrequentGuestsParams) ([]repo.ListFrequentGuestsRow, error) {
+	if m.listFrequentGuestsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listFrequentGuestsFn(ctx, arg)
+}
 func (m *mockStore) RestoreGuestRequest(ctx context.Context, arg repo.RestoreGuestRequestParams) error {
 	if m.restoreGuestRequestFn == nil {
 		return errMockUnimplemented
@@ -223,11 +612,755 @@ func (m *mockStore) CreateEntryLog(ctx context.Context, arg repo.CreateEntryLogP
 	}
 	return m.createEntryLogFn(ctx, arg)
 }
-func (m *mockStore) ListEntryLogsByPass(ctx context.Context, arg repo.ListEntryLogsByPassParams) ([]repo.EntryLog, error) {
-	if m.listEntryLogsByPassFn == nil {
+func (m *mockStore) ListEntryLogs(ctx context.Context, arg repo.ListEntryLogsParams) ([]repo.ListEntryLogsRow, error) {
+	if m.listEntryLogsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listEntryLogsFn(ctx, arg)
+}
+func (m *mockStore) GetEntryLog(ctx context.Context, id uuid.UUID) (repo.EntryLog, error) {
+	if m.getEntryLogFn == nil {
+		return repo.EntryLog{}, errMockUnimplemented
+	}
+	return m.getEntryLogFn(ctx, id)
+}
+func (m *mockStore) ListEntryLogChain(ctx context.Context, arg repo.ListEntryLogChainParams) ([]repo.EntryLog, error) {
+	if m.listEntryLogChainFn == nil {
 		return nil, errMockUnimplemented
 	}
-	return m.listEntryLogsByPassFn(ctx, arg)
+	return m.listEntryLogChainFn(ctx, arg)
+}
+func (m *mockStore) CreateGate(ctx context.Context, arg repo.CreateGateParams) (repo.Gate, error) {
+	if m.createGateFn == nil {
+		return repo.Gate{}, errMockUnimplemented
+	}
+	return m.createGateFn(ctx, arg)
+}
+func (m *mockStore) GetGate(ctx context.Context, id uuid.UUID) (repo.Gate, error) {
+	if m.getGateFn == nil {
+		return repo.Gate{}, errMockUnimplemented
+	}
+	return m.getGateFn(ctx, id)
+}
+func (m *mockStore) GetGateByName(ctx context.Context, name string) (repo.Gate, error) {
+	if m.getGateByNameFn == nil {
+		return repo.Gate{}, sql.ErrNoRows
+	}
+	return m.getGateByNameFn(ctx, name)
+}
+func (m *mockStore) UpdateGate(ctx context.Context, arg repo.UpdateGateParams) (repo.Gate, error) {
+	if m.updateGateFn == nil {
+		return repo.Gate{}, errMockUnimplemented
+	}
+	return m.updateGateFn(ctx, arg)
+}
+func (m *mockStore) SoftDeleteGate(ctx context.Context, arg repo.SoftDeleteGateParams) (int64, error) {
+	if m.softDeleteGateFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.softDeleteGateFn(ctx, arg)
+}
+func (m *mockStore) CountGateRestrictions(ctx context.Context, gateID uuid.UUID) (int64, error) {
+	if m.countGateRestrictionsFn == nil {
+		return 0, nil
+	}
+	return m.countGateRestrictionsFn(ctx, gateID)
+}
+func (m *mockStore) ListGuardGates(ctx context.Context, userID uuid.UUID) ([]repo.Gate, error) {
+	if m.listGuardGatesFn == nil {
+		return nil, nil
+	}
+	return m.listGuardGatesFn(ctx, userID)
+}
+func (m *mockStore) AddGuardGate(ctx context.Context, arg repo.AddGuardGateParams) error {
+	if m.addGuardGateFn == nil {
+		return nil
+	}
+	return m.addGuardGateFn(ctx, arg)
+}
+func (m *mockStore) ClearGuardGates(ctx context.Context, userID uuid.UUID) error {
+	if m.clearGuardGatesFn == nil {
+		return nil
+	}
+	return m.clearGuardGatesFn(ctx, userID)
+}
+func (m *mockStore) DeleteGateAssignments(ctx context.Context, gateID uuid.UUID) error {
+	if m.deleteGateAssignmentsFn == nil {
+		return nil
+	}
+	return m.deleteGateAssignmentsFn(ctx, gateID)
+}
+func (m *mockStore) ListPassGates(ctx context.Context, passID uuid.UUID) ([]repo.Gate, error) {
+	if m.listPassGatesFn == nil {
+		return nil, nil
+	}
+	return m.listPassGatesFn(ctx, passID)
+}
+func (m *mockStore) AddPassGate(ctx context.Context, arg repo.AddPassGateParams) error {
+	if m.addPassGateFn == nil {
+		return nil
+	}
+	return m.addPassGateFn(ctx, arg)
+}
+func (m *mockStore) ClearPassGates(ctx context.Context, passID uuid.UUID) error {
+	if m.clearPassGatesFn == nil {
+		return nil
+	}
+	return m.clearPassGatesFn(ctx, passID)
+}
+func (m *mockStore) ListGuestTypeGates(ctx context.Context, guestType string) ([]repo.Gate, error) {
+	if m.listGuestTypeGatesFn == nil {
+		return nil, nil
+	}
+	return m.listGuestTypeGatesFn(ctx, guestType)
+}
+func (m *mockStore) AddGuestTypeGate(ctx context.Context, arg repo.AddGuestTypeGateParams) error {
+	if m.addGuestTypeGateFn == nil {
+		return nil
+	}
+	return m.addGuestTypeGateFn(ctx, arg)
+}
+func (m *mockStore) ClearGuestTypeGates(ctx context.Context, guestType string) error {
+	if m.clearGuestTypeGatesFn == nil {
+		return nil
+	}
+	return m.clearGuestTypeGatesFn(ctx, guestType)
+}
+func (m *mockStore) GateStats(ctx context.Context, arg repo.GateStatsParams) ([]repo.GateStatsRow, error) {
+	if m.gateStatsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.gateStatsFn(ctx, arg)
+}
+func (m *mockStore) CreateGuardShift(ctx context.Context, arg repo.CreateGuardShiftParams) (repo.GuardShift, error) {
+	if m.createGuardShiftFn == nil {
+		return repo.GuardShift{}, errMockUnimplemented
+	}
+	return m.createGuardShiftFn(ctx, arg)
+}
+func (m *mockStore) GetGuardShift(ctx context.Context, id uuid.UUID) (repo.GuardShift, error) {
+	if m.getGuardShiftFn == nil {
+		return repo.GuardShift{}, sql.ErrNoRows
+	}
+	return m.getGuardShiftFn(ctx, id)
+}
+func (m *mockStore) GetOpenGuardShift(ctx context.Context, guardUserID uuid.UUID) (repo.GuardShift, error) {
+	if m.getOpenGuardShiftFn == nil {
+		return repo.GuardShift{}, sql.ErrNoRows
+	}
+	return m.getOpenGuardShiftFn(ctx, guardUserID)
+}
+func (m *mockStore) GetPendingHandover(ctx context.Context, gateID uuid.NullUUID) (repo.GuardShift, error) {
+	if m.getPendingHandoverFn == nil {
+		return repo.GuardShift{}, sql.ErrNoRows
+	}
+	return m.getPendingHandoverFn(ctx, gateID)
+}
+func (m *mockStore) EndGuardShift(ctx context.Context, arg repo.EndGuardShiftParams) (repo.GuardShift, error) {
+	if m.endGuardShiftFn == nil {
+		return repo.GuardShift{}, errMockUnimplemented
+	}
+	return m.endGuardShiftFn(ctx, arg)
+}
+func (m *mockStore) ListGuardShifts(ctx context.Context, arg repo.ListGuardShiftsParams) ([]repo.ListGuardShiftsRow, error) {
+	if m.listGuardShiftsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listGuardShiftsFn(ctx, arg)
+}
+func (m *mockStore) GuardShiftSummary(ctx context.Context, arg repo.GuardShiftSummaryParams) (repo.GuardShiftSummaryRow, error) {
+	if m.guardShiftSummaryFn == nil {
+		return repo.GuardShiftSummaryRow{}, nil
+	}
+	return m.guardShiftSummaryFn(ctx, arg)
+}
+func (m *mockStore) CountVehiclesOnSite(ctx context.Context) (int64, error) {
+	if m.countVehiclesOnSiteFn == nil {
+		return 0, nil
+	}
+	return m.countVehiclesOnSiteFn(ctx)
+}
+func (m *mockStore) CreateIncident(ctx context.Context, arg repo.CreateIncidentParams) (repo.Incident, error) {
+	if m.createIncidentFn == nil {
+		return repo.Incident{}, errMockUnimplemented
+	}
+	return m.createIncidentFn(ctx, arg)
+}
+func (m *mockStore) GetIncident(ctx context.Context, id uuid.UUID) (repo.Incident, error) {
+	if m.getIncidentFn == nil {
+		return repo.Incident{}, sql.ErrNoRows
+	}
+	return m.getIncidentFn(ctx, id)
+}
+func (m *mockStore) ListIncidents(ctx context.Context, arg repo.ListIncidentsParams) ([]repo.ListIncidentsRow, error) {
+	if m.listIncidentsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listIncidentsFn(ctx, arg)
+}
+func (m *mockStore) UpdateIncidentStatus(ctx context.Context, arg repo.UpdateIncidentStatusParams) (repo.Incident, error) {
+	if m.updateIncidentStatusFn == nil {
+		return repo.Incident{}, errMockUnimplemented
+	}
+	return m.updateIncidentStatusFn(ctx, arg)
+}
+func (m *mockStore) CreateIncidentComment(ctx context.Context, arg repo.CreateIncidentCommentParams) (repo.IncidentComment, error) {
+	if m.createIncidentCommentFn == nil {
+		return repo.IncidentComment{}, errMockUnimplemented
+	}
+	return m.createIncidentCommentFn(ctx, arg)
+}
+func (m *mockStore) ListIncidentComments(ctx context.Context, incidentID uuid.UUID) ([]repo.ListIncidentCommentsRow, error) {
+	if m.listIncidentCommentsFn == nil {
+		return nil, nil
+	}
+	return m.listIncidentCommentsFn(ctx, incidentID)
+}
+func (m *mockStore) CreateIncidentAttachment(ctx context.Context, arg repo.CreateIncidentAttachmentParams) (repo.IncidentAttachment, error) {
+	if m.createIncidentAttachmentFn == nil {
+		return repo.IncidentAttachment{}, errMockUnimplemented
+	}
+	return m.createIncidentAttachmentFn(ctx, arg)
+}
+func (m *mockStore) GetIncidentAttachment(ctx context.Context, id uuid.UUID) (repo.IncidentAttachment, error) {
+	if m.getIncidentAttachmentFn == nil {
+		return repo.IncidentAttachment{}, sql.ErrNoRows
+	}
+	return m.getIncidentAttachmentFn(ctx, id)
+}
+func (m *mockStore) ListIncidentAttachments(ctx context.Context, incidentID uuid.UUID) ([]repo.IncidentAttachment, error) {
+	if m.listIncidentAttachmentsFn == nil {
+		return nil, nil
+	}
+	return m.listIncidentAttachmentsFn(ctx, incidentID)
+}
+func (m *mockStore) DeleteIncidentAttachment(ctx context.Context, id uuid.UUID) (int64, error) {
+	if m.deleteIncidentAttachmentFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.deleteIncidentAttachmentFn(ctx, id)
+}
+func (m *mockStore) CreateEntryPhoto(ctx context.Context, arg repo.CreateEntryPhotoParams) (repo.EntryPhoto, error) {
+	if m.createEntryPhotoFn == nil {
+		return repo.EntryPhoto{}, errMockUnimplemented
+	}
+	return m.createEntryPhotoFn(ctx, arg)
+}
+func (m *mockStore) GetEntryPhoto(ctx context.Context, id uuid.UUID) (repo.EntryPhoto, error) {
+	if m.getEntryPhotoFn == nil {
+		return repo.EntryPhoto{}, sql.ErrNoRows
+	}
+	return m.getEntryPhotoFn(ctx, id)
+}
+func (m *mockStore) GetEntryPhotoByLog(ctx context.Context, entryLogID uuid.UUID) (repo.EntryPhoto, error) {
+	if m.getEntryPhotoByLogFn == nil {
+		return repo.EntryPhoto{}, sql.ErrNoRows
+	}
+	return m.getEntryPhotoByLogFn(ctx, entryLogID)
+}
+func (m *mockStore) ListEntryPhotosBefore(ctx context.Context, arg repo.ListEntryPhotosBeforeParams) ([]repo.EntryPhoto, error) {
+	if m.listEntryPhotosBeforeFn == nil {
+		return nil, nil
+	}
+	return m.listEntryPhotosBeforeFn(ctx, arg)
+}
+func (m *mockStore) DeleteEntryPhoto(ctx context.Context, id uuid.UUID) error {
+	if m.deleteEntryPhotoFn == nil {
+		return errMockUnimplemented
+	}
+	return m.deleteEntryPhotoFn(ctx, id)
+}
+func (m *mockStore) CreateCamera(ctx context.Context, arg repo.CreateCameraParams) (repo.Camera, error) {
+	if m.createCameraFn == nil {
+		return repo.Camera{}, errMockUnimplemented
+	}
+	return m.createCameraFn(ctx, arg)
+}
+func (m *mockStore) GetCamera(ctx context.Context, id uuid.UUID) (repo.Camera, error) {
+	if m.getCameraFn == nil {
+		return repo.Camera{}, sql.ErrNoRows
+	}
+	return m.getCameraFn(ctx, id)
+}
+func (m *mockStore) GetCameraByName(ctx context.Context, name string) (repo.Camera, error) {
+	if m.getCameraByNameFn == nil {
+		return repo.Camera{}, sql.ErrNoRows
+	}
+	return m.getCameraByNameFn(ctx, name)
+}
+func (m *mockStore) GetCameraByToken(ctx context.Context, tokenHash string) (repo.Camera, error) {
+	if m.getCameraByTokenFn == nil {
+		return repo.Camera{}, sql.ErrNoRows
+	}
+	return m.getCameraByTokenFn(ctx, tokenHash)
+}
+func (m *mockStore) ListCameras(ctx context.Context) ([]repo.Camera, error) {
+	if m.listCamerasFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listCamerasFn(ctx)
+}
+func (m *mockStore) UpdateCamera(ctx context.Context, arg repo.UpdateCameraParams) (repo.Camera, error) {
+	if m.updateCameraFn == nil {
+		return repo.Camera{}, errMockUnimplemented
+	}
+	return m.updateCameraFn(ctx, arg)
+}
+func (m *mockStore) SetCameraToken(ctx context.Context, arg repo.SetCameraTokenParams) (int64, error) {
+	if m.setCameraTokenFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.setCameraTokenFn(ctx, arg)
+}
+func (m *mockStore) TouchCamera(ctx context.Context, arg repo.TouchCameraParams) error {
+	if m.touchCameraFn == nil {
+		return nil
+	}
+	return m.touchCameraFn(ctx, arg)
+}
+func (m *mockStore) SoftDeleteCamera(ctx context.Context, arg repo.SoftDeleteCameraParams) (int64, error) {
+	if m.softDeleteCameraFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.softDeleteCameraFn(ctx, arg)
+}
+func (m *mockStore) ListActivePassesByPlate(ctx context.Context, plateNumber string) ([]repo.Pass, error) {
+	if m.listActivePassesByPlateFn == nil {
+		return nil, nil
+	}
+	return m.listActivePassesByPlateFn(ctx, plateNumber)
+}
+func (m *mockStore) ListExitPassesByPlate(ctx context.Context, plateNumber string) ([]repo.Pass, error) {
+	if m.listExitPassesByPlateFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listExitPassesByPlateFn(ctx, plateNumber)
+}
+func (m *mockStore) ListCurrentGuestsByPlate(ctx context.Context, arg repo.ListCurrentGuestsByPlateParams) ([]repo.GuestRequest, error) {
+	if m.listCurrentGuestsByPlateFn == nil {
+		return nil, nil
+	}
+	return m.listCurrentGuestsByPlateFn(ctx, arg)
+}
+func (m *mockStore) CreatePlateRead(ctx context.Context, arg repo.CreatePlateReadParams) (repo.PlateRead, error) {
+	if m.createPlateReadFn == nil {
+		return repo.PlateRead{}, errMockUnimplemented
+	}
+	return m.createPlateReadFn(ctx, arg)
+}
+func (m *mockStore) GetPlateRead(ctx context.Context, id uuid.UUID) (repo.PlateRead, error) {
+	if m.getPlateReadFn == nil {
+		return repo.PlateRead{}, sql.ErrNoRows
+	}
+	return m.getPlateReadFn(ctx, id)
+}
+func (m *mockStore) ListPlateReads(ctx context.Context, arg repo.ListPlateReadsParams) ([]repo.ListPlateReadsRow, error) {
+	if m.listPlateReadsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listPlateReadsFn(ctx, arg)
+}
+func (m *mockStore) ClaimPlateRead(ctx context.Context, arg repo.ClaimPlateReadParams) (repo.PlateRead, error) {
+	if m.claimPlateReadFn == nil {
+		return repo.PlateRead{}, errMockUnimplemented
+	}
+	return m.claimPlateReadFn(ctx, arg)
+}
+func (m *mockStore) ReopenPlateRead(ctx context.Context, id uuid.UUID) error {
+	if m.reopenPlateReadFn == nil {
+		return nil
+	}
+	return m.reopenPlateReadFn(ctx, id)
+}
+func (m *mockStore) SetPlateReadEntryLog(ctx context.Context, arg repo.SetPlateReadEntryLogParams) error {
+	if m.setPlateReadEntryLogFn == nil {
+		return nil
+	}
+	return m.setPlateReadEntryLogFn(ctx, arg)
+}
+func (m *mockStore) UpsertGateBarrier(ctx context.Context, arg repo.UpsertGateBarrierParams) (repo.GateBarrier, error) {
+	if m.upsertGateBarrierFn == nil {
+		return repo.GateBarrier{}, errMockUnimplemented
+	}
+	return m.upsertGateBarrierFn(ctx, arg)
+}
+func (m *mockStore) GetGateBarrier(ctx context.Context, gateID uuid.UUID) (repo.GateBarrier, error) {
+	if m.getGateBarrierFn == nil {
+		return repo.GateBarrier{}, sql.ErrNoRows
+	}
+	return m.getGateBarrierFn(ctx, gateID)
+}
+func (m *mockStore) DeleteGateBarrier(ctx context.Context, gateID uuid.UUID) (int64, error) {
+	if m.deleteGateBarrierFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.deleteGateBarrierFn(ctx, gateID)
+}
+func (m *mockStore) CreateBarrierEvent(ctx context.Context, arg repo.CreateBarrierEventParams) (repo.BarrierEvent, error) {
+	if m.createBarrierEventFn == nil {
+		return repo.BarrierEvent{}, errMockUnimplemented
+	}
+	return m.createBarrierEventFn(ctx, arg)
+}
+func (m *mockStore) ListBarrierEvents(ctx context.Context, arg repo.ListBarrierEventsParams) ([]repo.BarrierEvent, error) {
+	if m.listBarrierEventsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listBarrierEventsFn(ctx, arg)
+}
+func (m *mockStore) ListSyncPasses(ctx context.Context, since sql.NullTime) ([]repo.ListSyncPassesRow, error) {
+	if m.listSyncPassesFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listSyncPassesFn(ctx, since)
+}
+func (m *mockStore) ListSyncGuests(ctx context.Context, arg repo.ListSyncGuestsParams) ([]repo.ListSyncGuestsRow, error) {
+	if m.listSyncGuestsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listSyncGuestsFn(ctx, arg)
+}
+func (m *mockStore) CreatePlot(ctx context.Context, arg repo.CreatePlotParams) (repo.Plot, error) {
+	if m.createPlotFn == nil {
+		return repo.Plot{}, errMockUnimplemented
+	}
+	return m.createPlotFn(ctx, arg)
+}
+func (m *mockStore) EnsurePlot(ctx context.Context, arg repo.EnsurePlotParams) (repo.Plot, error) {
+	if m.ensurePlotFn == nil {
+		return repo.Plot{ID: uuid.New(), Number: arg.Number, Status: PlotStatusActive}, nil
+	}
+	return m.ensurePlotFn(ctx, arg)
+}
+func (m *mockStore) GetPlot(ctx context.Context, id uuid.UUID) (repo.Plot, error) {
+	if m.getPlotFn == nil {
+		return repo.Plot{}, errMockUnimplemented
+	}
+	return m.getPlotFn(ctx, id)
+}
+func (m *mockStore) GetPlotByNumber(ctx context.Context, number string) (repo.Plot, error) {
+	if m.getPlotByNumberFn == nil {
+		return repo.Plot{}, errMockUnimplemented
+	}
+	return m.getPlotByNumberFn(ctx, number)
+}
+func (m *mockStore) ListPlots(ctx context.Context, arg repo.ListPlotsParams) ([]repo.Plot, error) {
+	if m.listPlotsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listPlotsFn(ctx, arg)
+}
+func (m *mockStore) UpdatePlot(ctx context.Context, arg repo.UpdatePlotParams) (repo.Plot, error) {
+	if m.updatePlotFn == nil {
+		return repo.Plot{}, errMockUnimplemented
+	}
+	return m.updatePlotFn(ctx, arg)
+}
+func (m *mockStore) RenameUserPlotNumbers(ctx context.Context, arg repo.RenameUserPlotNumbersParams) error {
+	if m.renameUserPlotNumbersFn == nil {
+		return errMockUnimplemented
+	}
+	return m.renameUserPlotNumbersFn(ctx, arg)
+}
+func (m *mockStore) ListPlotResidents(ctx context.Context, plotID uuid.UUID) ([]repo.ListPlotResidentsRow, error) {
+	if m.listPlotResidentsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listPlotResidentsFn(ctx, plotID)
+}
+func (m *mockStore) ListUserPlots(ctx context.Context, userID uuid.UUID) ([]repo.ListUserPlotsRow, error) {
+	if m.listUserPlotsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listUserPlotsFn(ctx, userID)
+}
+func (m *mockStore) GetUserPlot(ctx context.Context, arg repo.GetUserPlotParams) (repo.GetUserPlotRow, error) {
+	if m.getUserPlotFn == nil {
+		return repo.GetUserPlotRow{}, errMockUnimplemented
+	}
+	return m.getUserPlotFn(ctx, arg)
+}
+func (m *mockStore) UpsertPlotResident(ctx context.Context, arg repo.UpsertPlotResidentParams) (repo.PlotResident, error) {
+	if m.upsertPlotResidentFn == nil {
+		return repo.PlotResident{}, errMockUnimplemented
+	}
+	return m.upsertPlotResidentFn(ctx, arg)
+}
+func (m *mockStore) AddPlotResident(ctx context.Context, arg repo.AddPlotResidentParams) (int64, error) {
+	if m.addPlotResidentFn == nil {
+		return 1, nil
+	}
+	return m.addPlotResidentFn(ctx, arg)
+}
+func (m *mockStore) DeletePlotResident(ctx context.Context, arg repo.DeletePlotResidentParams) (int64, error) {
+	if m.deletePlotResidentFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.deletePlotResidentFn(ctx, arg)
+}
+func (m *mockStore) UnlinkPreviousUserPlot(ctx context.Context, arg repo.UnlinkPreviousUserPlotParams) error {
+	if m.unlinkPreviousUserPlotFn == nil {
+		return nil
+	}
+	return m.unlinkPreviousUserPlotFn(ctx, arg)
+}
+func (m *mockStore) RefreshUserPlotNumber(ctx context.Context, arg repo.RefreshUserPlotNumberParams) error {
+	if m.refreshUserPlotNumberFn == nil {
+		return errMockUnimplemented
+	}
+	return m.refreshUserPlotNumberFn(ctx, arg)
+}
+func (m *mockStore) ListPassesByPlot(ctx context.Context, arg repo.ListPassesByPlotParams) ([]repo.Pass, error) {
+	if m.listPassesByPlotFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listPassesByPlotFn(ctx, arg)
+}
+func (m *mockStore) ListGuestRequestsByPlot(ctx context.Context, arg repo.ListGuestRequestsByPlotParams) ([]repo.GuestRequest, error) {
+	if m.listGuestRequestsByPlotFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listGuestRequestsByPlotFn(ctx, arg)
+}
+func (m *mockStore) SuspendUserPasses(ctx context.Context, arg repo.SuspendUserPassesParams) ([]uuid.UUID, error) {
+	if m.suspendUserPassesFn == nil {
+		return nil, nil
+	}
+	return m.suspendUserPassesFn(ctx, arg)
+}
+func (m *mockStore) RevokeUserPasses(ctx context.Context, arg repo.RevokeUserPassesParams) ([]uuid.UUID, error) {
+	if m.revokeUserPassesFn == nil {
+		return nil, nil
+	}
+	return m.revokeUserPassesFn(ctx, arg)
+}
+func (m *mockStore) SuspendUserGuestRequests(ctx context.Context, arg repo.SuspendUserGuestRequestsParams) ([]uuid.UUID, error) {
+	if m.suspendUserGuestRequestsFn == nil {
+		return nil, nil
+	}
+	return m.suspendUserGuestRequestsFn(ctx, arg)
+}
+func (m *mockStore) CancelUserGuestRequests(ctx context.Context, arg repo.CancelUserGuestRequestsParams) ([]uuid.UUID, error) {
+	if m.cancelUserGuestRequestsFn == nil {
+		return nil, nil
+	}
+	return m.cancelUserGuestRequestsFn(ctx, arg)
+}
+func (m *mockStore) RestoreCascadePasses(ctx context.Context, arg repo.RestoreCascadePassesParams) ([]uuid.UUID, error) {
+	if m.restoreCascadePassesFn == nil {
+		return nil, nil
+	}
+	return m.restoreCascadePassesFn(ctx, arg)
+}
+func (m *mockStore) RestoreCascadeGuestRequests(ctx context.Context, arg repo.RestoreCascadeGuestRequestsParams) ([]uuid.UUID, error) {
+	if m.restoreCascadeGuestRequestsFn == nil {
+		return nil, nil
+	}
+	return m.restoreCascadeGuestRequestsFn(ctx, arg)
+}
+func (m *mockStore) LockEntryLogChain(ctx context.Context) error {
+	if m.lockEntryLogChainFn == nil {
+		return nil
+	}
+	return m.lockEntryLogChainFn(ctx)
+}
+func (m *mockStore) GetEntryLogChainHead(ctx context.Context) (repo.GetEntryLogChainHeadRow, error) {
+	if m.getEntryLogChainHeadFn == nil {
+		return repo.GetEntryLogChainHeadRow{}, sql.ErrNoRows
+	}
+	return m.getEntryLogChainHeadFn(ctx)
+}
+func (m *mockStore) ListGates(ctx context.Context) ([]repo.Gate, error) {
+	if m.listGatesFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listGatesFn(ctx)
+}
+func (m *mockStore) ListAllGuestTypeGates(ctx context.Context) ([]repo.ListAllGuestTypeGatesRow, error) {
+	if m.listAllGuestTypeGatesFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listAllGuestTypeGatesFn(ctx)
+}
+func (m *mockStore) GetPassPresence(ctx context.Context, passID uuid.NullUUID) (repo.SitePresence, error) {
+	if m.getPassPresenceFn == nil {
+		return repo.SitePresence{}, sql.ErrNoRows
+	}
+	return m.getPassPresenceFn(ctx, passID)
+}
+func (m *mockStore) UpsertPassPresence(ctx context.Context, arg repo.UpsertPassPresenceParams) error {
+	if m.upsertPassPresenceFn == nil {
+		return nil
+	}
+	return m.upsertPassPresenceFn(ctx, arg)
+}
+func (m *mockStore) DeletePassPresence(ctx context.Context, passID uuid.NullUUID) (int64, error) {
+	if m.deletePassPresenceFn == nil {
+		return 0, nil
+	}
+	return m.deletePassPresenceFn(ctx, passID)
+}
+func (m *mockStore) UpsertGuestPresence(ctx context.Context, arg repo.UpsertGuestPresenceParams) error {
+	if m.upsertGuestPresenceFn == nil {
+		return nil
+	}
+	return m.upsertGuestPresenceFn(ctx, arg)
+}
+func (m *mockStore) DeleteGuestPresence(ctx context.Context, guestRequestID uuid.NullUUID) (int64, error) {
+	if m.deleteGuestPresenceFn == nil {
+		return 0, nil
+	}
+	return m.deleteGuestPresenceFn(ctx, guestRequestID)
+}
+func (m *mockStore) ListSitePresence(ctx context.Context, plotNumber sql.NullString) ([]repo.ListSitePresenceRow, error) {
+	if m.listSitePresenceFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listSitePresenceFn(ctx, plotNumber)
+}
+func (m *mockStore) CreatePassSchedule(ctx context.Context, arg repo.CreatePassScheduleParams) (repo.PassSchedule, error) {
+	if m.createPassScheduleFn == nil {
+		return repo.PassSchedule{}, errMockUnimplemented
+	}
+	return m.createPassScheduleFn(ctx, arg)
+}
+func (m *mockStore) ListPassSchedules(ctx context.Context, passID uuid.UUID) ([]repo.PassSchedule, error) {
+	if m.listPassSchedulesFn == nil {
+		return nil, nil
+	}
+	return m.listPassSchedulesFn(ctx, passID)
+}
+func (m *mockStore) DeletePassSchedule(ctx context.Context, arg repo.DeletePassScheduleParams) (int64, error) {
+	if m.deletePassScheduleFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.deletePassScheduleFn(ctx, arg)
+}
+func (m *mockStore) CreateHoliday(ctx context.Context, arg repo.CreateHolidayParams) (repo.Holiday, error) {
+	if m.createHolidayFn == nil {
+		return repo.Holiday{}, errMockUnimplemented
+	}
+	return m.createHolidayFn(ctx, arg)
+}
+func (m *mockStore) ListHolidays(ctx context.Context, arg repo.ListHolidaysParams) ([]repo.Holiday, error) {
+	if m.listHolidaysFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listHolidaysFn(ctx, arg)
+}
+func (m *mockStore) IsHoliday(ctx context.Context, day time.Time) (bool, error) {
+	if m.isHolidayFn == nil {
+		return false, nil
+	}
+	return m.isHolidayFn(ctx, day)
+}
+func (m *mockStore) DeleteHoliday(ctx context.Context, day time.Time) (int64, error) {
+	if m.deleteHolidayFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.deleteHolidayFn(ctx, day)
+}
+func (m *mockStore) GetUserByEmailAny(ctx context.Context, email string) (repo.User, error) {
+	if m.getUserByEmailAnyFn == nil {
+		return repo.User{}, errMockUnimplemented
+	}
+	return m.getUserByEmailAnyFn(ctx, email)
+}
+func (m *mockStore) UpsertUserByEmail(ctx context.Context, arg repo.UpsertUserByEmailParams) (repo.User, error) {
+	if m.upsertUserByEmailFn == nil {
+		return repo.User{}, errMockUnimplemented
+	}
+	return m.upsertUserByEmailFn(ctx, arg)
+}
+func (m *mockStore) GetPassByOwnerAndPlate(ctx context.Context, arg repo.GetPassByOwnerAndPlateParams) (repo.Pass, error) {
+	if m.getPassByOwnerAndPlateFn == nil {
+		return repo.Pass{}, errMockUnimplemented
+	}
+	return m.getPassByOwnerAndPlateFn(ctx, arg)
+}
+
+func (m *mockStore) ExportUsers(ctx context.Context, arg repo.ExportUsersParams) ([]repo.User, error) {
+	if m.exportUsersFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.exportUsersFn(ctx, arg)
+}
+
+func (m *mockStore) ExportPasses(ctx context.Context, arg repo.ExportPassesParams) ([]repo.ExportPassesRow, error) {
+	if m.exportPassesFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.exportPassesFn(ctx, arg)
+}
+
+func (m *mockStore) ExportGuestRequests(ctx context.Context, arg repo.ExportGuestRequestsParams) ([]repo.ExportGuestRequestsRow, error) {
+	if m.exportGuestRequestsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.exportGuestRequestsFn(ctx, arg)
+}
+
+func (m *mockStore) ExportEntryLogs(ctx context.Context, arg repo.ExportEntryLogsParams) ([]repo.ExportEntryLogsRow, error) {
+	if m.exportEntryLogsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.exportEntryLogsFn(ctx, arg)
+}
+
+func (m *mockStore) CreateWatchlistEntry(ctx context.Context, arg repo.CreateWatchlistEntryParams) (repo.PlateWatchlist, error) {
+	if m.createWatchlistEntryFn == nil {
+		return repo.PlateWatchlist{}, errMockUnimplemented
+	}
+	return m.createWatchlistEntryFn(ctx, arg)
+}
+
+func (m *mockStore) GetWatchlistEntryByID(ctx context.Context, id uuid.UUID) (repo.PlateWatchlist, error) {
+	if m.getWatchlistEntryByIDFn == nil {
+		return repo.PlateWatchlist{}, errMockUnimplemented
+	}
+	return m.getWatchlistEntryByIDFn(ctx, id)
+}
+
+func (m *mockStore) GetWatchlistEntryByPlate(ctx context.Context, plateNumber string) (repo.PlateWatchlist, error) {
+	if m.getWatchlistEntryByPlateFn == nil {
+		return repo.PlateWatchlist{}, sql.ErrNoRows
+	}
+	return m.getWatchlistEntryByPlateFn(ctx, plateNumber)
+}
+
+func (m *mockStore) ListWatchlist(ctx context.Context, arg repo.ListWatchlistParams) ([]repo.PlateWatchlist, error) {
+	if m.listWatchlistFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listWatchlistFn(ctx, arg)
+}
+
+func (m *mockStore) UpdateWatchlistEntry(ctx context.Context, arg repo.UpdateWatchlistEntryParams) (repo.PlateWatchlist, error) {
+	if m.updateWatchlistEntryFn == nil {
+		return repo.PlateWatchlist{}, errMockUnimplemented
+	}
+	return m.updateWatchlistEntryFn(ctx, arg)
+}
+
+func (m *mockStore) SoftDeleteWatchlistEntry(ctx context.Context, arg repo.SoftDeleteWatchlistEntryParams) (int64, error) {
+	if m.softDeleteWatchlistEntryFn == nil {
+		return 0, errMockUnimplemented
+	}
+	return m.softDeleteWatchlistEntryFn(ctx, arg)
+}
+
+func (m *mockStore) CreateWatchlistHit(ctx context.Context, arg repo.CreateWatchlistHitParams) (repo.WatchlistHit, error) {
+	if m.createWatchlistHitFn == nil {
+		return repo.WatchlistHit{}, errMockUnimplemented
+	}
+	return m.createWatchlistHitFn(ctx, arg)
+}
+
+func (m *mockStore) ListWatchlistHits(ctx context.Context, arg repo.ListWatchlistHitsParams) ([]repo.WatchlistHit, error) {
+	if m.listWatchlistHitsFn == nil {
+		return nil, errMockUnimplemented
+	}
+	return m.listWatchlistHitsFn(ctx, arg)
 }
 
 func TestServiceUnit_Authenticate(t *testing.T) {
@@ -429,6 +1562,9 @@ func TestServiceUnit_UserStateMethods(t *testing.T) {
 	actorID := uuid.New()
 
 	svc := New(&mockStore{
+		getUserByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			return repo.User{ID: id}, nil
+		},
 		softDeleteUserFn: func(_ context.Context, arg repo.SoftDeleteUserParams) error {
 			require.Equal(t, userID, arg.ID)
 			require.Equal(t, actorID, arg.UpdatedBy.UUID)
@@ -447,10 +1583,14 @@ func TestServiceUnit_UserStateMethods(t *testing.T) {
 			return nil
 		},
 	})
-	require.NoError(t, svc.SoftDeleteUser(ctx, userID, actorID))
-	require.NoError(t, svc.RestoreUser(ctx, userID, actorID))
-	require.NoError(t, svc.BlockUser(ctx, userID, actorID))
-	require.NoError(t, svc.UnblockUser(ctx, userID, actorID))
+	_, err := svc.SoftDeleteUser(ctx, userID, actorID)
+	require.NoError(t, err)
+	_, err = svc.RestoreUser(ctx, userID, actorID)
+	require.NoError(t, err)
+	_, err = svc.BlockUser(ctx, userID, actorID)
+	require.NoError(t, err)
+	_, err = svc.UnblockUser(ctx, userID, actorID)
+	require.NoError(t, err)
 }
 
 func TestServiceUnit_PassMethods(t *testing.T) {
@@ -639,18 +1779,19 @@ func TestServiceUnit_EntryLogMethods(t *testing.T) {
 
 	svc := New(&mockStore{
 		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
-			require.Equal(t, passID, arg.PassID)
+			require.Equal(t, passID, arg.PassID.UUID)
+			require.False(t, arg.GuestRequestID.Valid)
 			require.Equal(t, "entry", arg.Action)
 			return repo.EntryLog{ID: entryID, PassID: arg.PassID, GuardUserID: arg.GuardUserID, Action: arg.Action}, nil
 		},
-		listEntryLogsByPassFn: func(_ context.Context, arg repo.ListEntryLogsByPassParams) ([]repo.EntryLog, error) {
-			require.Equal(t, passID, arg.PassID)
-			return []repo.EntryLog{{ID: entryID, PassID: passID, GuardUserID: guardID, Action: "entry"}}, nil
+		listEntryLogsFn: func(_ context.Context, arg repo.ListEntryLogsParams) ([]repo.ListEntryLogsRow, error) {
+			require.Equal(t, passID, arg.PassID.UUID)
+			return []repo.ListEntryLogsRow{{ID: entryID, PassID: arg.PassID, GuardUserID: guardID, Action: "entry"}}, nil
 		},
 	})
 	_, err := svc.CreateEntryLog(ctx, passID, guardID, "entry", sql.NullString{Valid: false})
 	require.NoError(t, err)
-	_, err = svc.ListEntryLogs(ctx, passID, 10, 0)
+	_, err = svc.ListEntryLogs(ctx, EntryLogFilter{PassID: passID}, 10, 0)
 	require.NoError(t, err)
 }
 
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"pipo-edu-project/internal/config"
+)
+
+type Settings struct {
+	Location      *time.Location
+	GuestApproval GuestApprovalRules
+	GuestWindow   GuestWindowRules
+	// GuestTypes holds the defaults of each guest type; nil means
+	// DefaultGuestTypes.
+	GuestTypes map[string]GuestTypeRules
+	Presence   PresenceRules
+	Shifts     ShiftRules
+	// Attachments limit uploaded files; zero values use the defaults.
+	Attachments AttachmentRules
+	// Photos are vehicle snapshots taken at the gate; they share the
+	// attachment size limit.
+	Photos PhotoRules
+	// Cameras tune how plate-recognition reads are decided.
+	Cameras CameraRules
+	// Barriers bound the wait for gate barrier controllers.
+	Barriers BarrierRules
+	// Cascade carries user blocks and deletions over to their passes and
+	// guest requests.
+	Cascade CascadeRules
+}
+
+func DefaultSettings() Settings {
+	return Settings{Location: time.UTC, GuestTypes: DefaultGuestTypes()}
+}
+
+// SettingsFromConfig builds the settings the server and the CLI run with, so
+// that both apply the same rules to the same database.
+func SettingsFromConfig(cfg config.Config) (Settings, error) {
+	guestTypes := DefaultGuestTypes()
+	for guestType, duration := range cfg.GuestTypeDurations {
+		if err := ValidateGuestType(guestType); err != nil {
+			return Settings{}, fmt.Errorf("invalid GUEST_TYPE_DURATIONS: %q: %w", guestType, err)
+		}
+		guestTypes[guestType] = GuestTypeRules{DefaultDuration: duration}
+	}
+	return Settings{
+		Location:      cfg.SiteLocation,
+		GuestApproval: GuestApprovalRules{MaxDuration: cfg.GuestAutoApprove},
+		GuestWindow:   GuestWindowRules{Horizon: cfg.GuestHorizon, Overlap: cfg.GuestOverlap},
+		GuestTypes:    guestTypes,
+		Presence:      PresenceRules{Policy: cfg.PresencePolicy},
+		Shifts:        ShiftRules{Required: cfg.ShiftRequired},
+		Attachments: AttachmentRules{
+			MaxBytes:   cfg.AttachmentMaxBytes,
+			Types:      cfg.AttachmentTypes,
+			LinkSecret: []byte(cfg.FileLinkSecret),
+			LinkTTL:    cfg.FileLinkTTL,
+		},
+		Photos:   PhotoRules{Retention: time.Duration(cfg.PhotoRetentionDays) * 24 * time.Hour},
+		Cameras:  CameraRules{MinConfidence: cfg.ANPRMinConfidence},
+		Barriers: BarrierRules{Timeout: cfg.BarrierTimeout},
+		Cascade: CascadeRules{
+			OnBlock:  cfg.BlockCascade,
+			OnDelete: cfg.DeleteCascade,
+			Restore:  cfg.RestoreCascade,
+		},
+	}, nil
+}
+
+type Option func(*Service)
+
+func WithSettings(settings Settings) Option {
+	return func(s *Service) {
+		if settings.Location == nil {
+			settings.Location = time.UTC
+		}
+		if settings.GuestTypes == nil {
+			settings.GuestTypes = DefaultGuestTypes()
+		}
+		s.settings = settings
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
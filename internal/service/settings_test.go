@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"pipo-edu-project/internal/config"
+)
+
+func TestServiceUnit_SettingsFromConfig(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	cfg := config.Config{
+		SiteLocation:       location,
+		GuestTypeDurations: map[string]time.Duration{GuestTypeTaxi: 20 * time.Minute},
+		PhotoRetentionDays: 2,
+		BlockCascade:       CascadeSuspend,
+		DeleteCascade:      CascadeRevoke,
+		RestoreCascade:     true,
+	}
+	settings, err := SettingsFromConfig(cfg)
+	require.NoError(t, err)
+	require.Equal(t, location, settings.Location)
+	require.Equal(t, 20*time.Minute, settings.GuestTypes[GuestTypeTaxi].DefaultDuration)
+	require.Equal(t, DefaultGuestTypes()[GuestTypeVehicle], settings.GuestTypes[GuestTypeVehicle])
+	require.Equal(t, 48*time.Hour, settings.Photos.Retention)
+	require.Equal(t, CascadeRules{OnBlock: CascadeSuspend, OnDelete: CascadeRevoke, Restore: true}, settings.Cascade)
+
+	cfg.GuestTypeDurations = map[string]time.Duration{"plumber": time.Hour}
+	_, err = SettingsFromConfig(cfg)
+	require.ErrorIs(t, err, ErrInvalidGuestType)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+var (
+	ErrShiftOpen   = errors.New("guard already has an open shift")
+	ErrNoOpenShift = errors.New("no open shift")
+)
+
+// ShiftRules decide whether guards must be on a shift to record movements.
+type ShiftRules struct {
+	// Required refuses entries and exits from guards without an open shift.
+	// Admins never need one.
+	Required bool
+}
+
+type ShiftStartInput struct {
+	GuardID uuid.UUID
+	// GateID may be uuid.Nil for a guard assigned to a single gate or when
+	// the site has no gates.
+	GateID uuid.UUID
+}
+
+// ShiftEndInput closes ShiftID, or the open shift of GuardID when ShiftID is
+// uuid.Nil.
+type ShiftEndInput struct {
+	ShiftID      uuid.UUID
+	GuardID      uuid.UUID
+	ActorID      uuid.UUID
+	HandoverNote string
+}
+
+type ShiftFilter struct {
+	GuardID  uuid.UUID
+	From     time.Time
+	To       time.Time
+	OpenOnly bool
+}
+
+// ShiftSummary is what happened during a shift. VehiclesOnSite is taken when
+// the shift ends; for an open shift it is the current count.
+type ShiftSummary struct {
+	Entries         int64
+	Exits           int64
+	GuestEntries    int64
+	Anomalies       int64
+	WatchlistAlerts int64
+	Incidents       int64
+	VehiclesOnSite  int64
+}
+
+// ShiftReport is a shift with its summary and the shift it took over from.
+type ShiftReport struct {
+	repo.GuardShift
+	Summary  ShiftSummary
+	Handover *repo.GuardShift
+}
+
+// StartShift opens a shift for a guard at a gate. The latest handover note
+// left at that gate and not yet picked up is handed to the new shift.
+func (s *Service) StartShift(ctx context.Context, input ShiftStartInput) (ShiftReport, error) {
+	user, err := s.GetUser(ctx, input.GuardID)
+	if err != nil {
+		return ShiftReport{}, err
+	}
+	if user.Role != string(auth.RoleGuard) {
+		return ShiftReport{}, ErrNotGuard
+	}
+	var report ShiftReport
+	err = s.inTx(ctx, func(q ServiceStore) error {
+		if _, err := q.GetOpenGuardShift(ctx, input.GuardID); err == nil {
+			return ErrShiftOpen
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		gateID, err := resolveGate(ctx, q, gateAccess{GateID: input.GateID, GuardID: input.GuardID})
+		if err != nil {
+			return err
+		}
+		var handoverID uuid.NullUUID
+		handover, err := q.GetPendingHandover(ctx, gateID)
+		switch {
+		case err == nil:
+			report.Handover = &handover
+			handoverID = uuid.NullUUID{UUID: handover.ID, Valid: true}
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+		report.GuardShift, err = q.CreateGuardShift(ctx, repo.CreateGuardShiftParams{
+			GuardUserID:    input.GuardID,
+			GateID:         gateID,
+			StartedAt:      s.now(),
+			HandoverFromID: handoverID,
+		})
+		return err
+	})
+	return report, err
+}
+
+// EndShift closes a shift, freezing the count of vehicles left on site, and
+// stores the note for the next guard at the gate.
+func (s *Service) EndShift(ctx context.Context, input ShiftEndInput) (ShiftReport, error) {
+	var report ShiftReport
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		var (
+			shift repo.GuardShift
+			err   error
+		)
+		if input.ShiftID == uuid.Nil {
+			shift, err = q.GetOpenGuardShift(ctx, input.GuardID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoOpenShift
+			}
+		} else {
+			shift, err = q.GetGuardShift(ctx, input.ShiftID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if shift.EndedAt.Valid {
+			return ErrNoOpenShift
+		}
+		vehicles, err := q.CountVehiclesOnSite(ctx)
+		if err != nil {
+			return err
+		}
+		note := strings.TrimSpace(input.HandoverNote)
+		shift, err = q.EndGuardShift(ctx, repo.EndGuardShiftParams{
+			ID:           shift.ID,
+			EndedAt:      sql.NullTime{Time: s.now(), Valid: true},
+			EndedBy:      uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+			HandoverNote: sql.NullString{String: note, Valid: note != ""},
+			OnSiteAtEnd:  sql.NullInt32{Int32: int32(vehicles), Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoOpenShift
+		}
+		if err != nil {
+			return err
+		}
+		report, err = s.shiftReport(ctx, q, shift)
+		return err
+	})
+	return report, err
+}
+
+// CurrentShift is the guard's open shift with its running summary.
+func (s *Service) CurrentShift(ctx context.Context, guardID uuid.UUID) (ShiftReport, error) {
+	shift, err := s.q.GetOpenGuardShift(ctx, guardID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ShiftReport{}, ErrNoOpenShift
+	}
+	if err != nil {
+		return ShiftReport{}, err
+	}
+	return s.shiftReport(ctx, s.q, shift)
+}
+
+func (s *Service) GetShift(ctx context.Context, id uuid.UUID) (ShiftReport, error) {
+	shift, err := s.q.GetGuardShift(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ShiftReport{}, ErrNotFound
+	}
+	if err != nil {
+		return ShiftReport{}, err
+	}
+	return s.shiftReport(ctx, s.q, shift)
+}
+
+func (s *Service) ListShifts(ctx context.Context, filter ShiftFilter, limit, offset int32) ([]repo.ListGuardShiftsRow, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, ErrInvalidRange
+	}
+	return s.q.ListGuardShifts(ctx, repo.ListGuardShiftsParams{
+		GuardUserID: uuid.NullUUID{UUID: filter.GuardID, Valid: filter.GuardID != uuid.Nil},
+		FromTime:    sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()},
+		ToTime:      sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()},
+		OpenOnly:    filter.OpenOnly,
+		PageSize:    limit,
+		PageOffset:  offset,
+	})
+}
+
+func (s *Service) shiftReport(ctx context.Context, q ServiceStore, shift repo.GuardShift) (ShiftReport, error) {
+	report := ShiftReport{GuardShift: shift}
+	row, err := q.GuardShiftSummary(ctx, repo.GuardShiftSummaryParams{
+		GuardUserID: uuid.NullUUID{UUID: shift.GuardUserID, Valid: true},
+		FromTime:    shift.StartedAt,
+		ToTime:      shift.EndedAt,
+		ShiftID:     uuid.NullUUID{UUID: shift.ID, Valid: true},
+	})
+	if err != nil {
+		return ShiftReport{}, err
+	}
+	report.Summary = ShiftSummary{
+		Entries:         row.Entries,
+		Exits:           row.Exits,
+		GuestEntries:    row.GuestEntries,
+		Anomalies:       row.Anomalies,
+		WatchlistAlerts: row.WatchlistAlerts,
+		Incidents:       row.Incidents,
+		VehiclesOnSite:  int64(shift.OnSiteAtEnd.Int32),
+	}
+	if !shift.OnSiteAtEnd.Valid {
+		if report.Summary.VehiclesOnSite, err = q.CountVehiclesOnSite(ctx); err != nil {
+			return ShiftReport{}, err
+		}
+	}
+	if shift.HandoverFromID.Valid {
+		handover, err := q.GetGuardShift(ctx, shift.HandoverFromID.UUID)
+		if err != nil {
+			return ShiftReport{}, err
+		}
+		report.Handover = &handover
+	}
+	return report, nil
+}
+
+// openShift finds the shift a movement is recorded on. Without one the zero
+// shift is returned, unless shifts are required and the actor is a guard.
+func (s *Service) openShift(ctx context.Context, q ServiceStore, guardID uuid.UUID) (repo.GuardShift, error) {
+	shift, err := q.GetOpenGuardShift(ctx, guardID)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return shift, err
+	}
+	if !s.settings.Shifts.Required {
+		return repo.GuardShift{}, nil
+	}
+	user, err := q.GetUserByID(ctx, guardID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return repo.GuardShift{}, err
+	}
+	if user.Role == string(auth.RoleAdmin) {
+		return repo.GuardShift{}, nil
+	}
+	return repo.GuardShift{}, ErrNoOpenShift
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"pipo-edu-project/internal/auth"
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+// shiftStore keeps guard shifts in memory; the other queries use the mock
+// defaults.
+func shiftStore(shifts *[]repo.GuardShift, roles map[uuid.UUID]auth.Role) *mockStore {
+	find := func(match func(repo.GuardShift) bool) (repo.GuardShift, error) {
+		for i := len(*shifts) - 1; i >= 0; i-- {
+			if match((*shifts)[i]) {
+				return (*shifts)[i], nil
+			}
+		}
+		return repo.GuardShift{}, sql.ErrNoRows
+	}
+	return &mockStore{
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			role, ok := roles[id]
+			if !ok {
+				return repo.User{}, sql.ErrNoRows
+			}
+			return repo.User{ID: id, Role: string(role)}, nil
+		},
+		getGateFn: func(_ context.Context, id uuid.UUID) (repo.Gate, error) {
+			return repo.Gate{ID: id}, nil
+		},
+		getPassByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			return repo.Pass{ID: id}, nil
+		},
+		getPassByIDAnyForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			return repo.Pass{ID: id}, nil
+		},
+		createGuardShiftFn: func(_ context.Context, arg repo.CreateGuardShiftParams) (repo.GuardShift, error) {
+			shift := repo.GuardShift{ID: uuid.New(), GuardUserID: arg.GuardUserID, GateID: arg.GateID, StartedAt: arg.StartedAt, HandoverFromID: arg.HandoverFromID}
+			*shifts = append(*shifts, shift)
+			return shift, nil
+		},
+		getGuardShiftFn: func(_ context.Context, id uuid.UUID) (repo.GuardShift, error) {
+			return find(func(s repo.GuardShift) bool { return s.ID == id })
+		},
+		getOpenGuardShiftFn: func(_ context.Context, guardID uuid.UUID) (repo.GuardShift, error) {
+			return find(func(s repo.GuardShift) bool { return s.GuardUserID == guardID && !s.EndedAt.Valid })
+		},
+		getPendingHandoverFn: func(_ context.Context, gateID uuid.NullUUID) (repo.GuardShift, error) {
+			return find(func(s repo.GuardShift) bool {
+				if !s.EndedAt.Valid || !s.HandoverNote.Valid || s.GateID != gateID {
+					return false
+				}
+				for _, other := range *shifts {
+					if other.HandoverFromID.UUID == s.ID {
+						return false
+					}
+				}
+				return true
+			})
+		},
+		endGuardShiftFn: func(_ context.Context, arg repo.EndGuardShiftParams) (repo.GuardShift, error) {
+			for i, s := range *shifts {
+				if s.ID == arg.ID && !s.EndedAt.Valid {
+					s.EndedAt, s.EndedBy, s.HandoverNote, s.OnSiteAtEnd = arg.EndedAt, arg.EndedBy, arg.HandoverNote, arg.OnSiteAtEnd
+					(*shifts)[i] = s
+					return s, nil
+				}
+			}
+			return repo.GuardShift{}, sql.ErrNoRows
+		},
+	}
+}
+
+func TestServiceUnit_ShiftLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	outgoing, incoming, resident := uuid.New(), uuid.New(), uuid.New()
+	gateID := uuid.New()
+	var shifts []repo.GuardShift
+	var logged repo.CreateEntryLogParams
+	store := shiftStore(&shifts, map[uuid.UUID]auth.Role{outgoing: auth.RoleGuard, incoming: auth.RoleGuard, resident: auth.RoleResident})
+	store.createEntryLogFn = func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+		logged = arg
+		return repo.EntryLog{ID: arg.ID, GateID: arg.GateID, ShiftID: arg.ShiftID}, nil
+	}
+	var summary repo.GuardShiftSummaryParams
+	store.guardShiftSummaryFn = func(_ context.Context, arg repo.GuardShiftSummaryParams) (repo.GuardShiftSummaryRow, error) {
+		summary = arg
+		return repo.GuardShiftSummaryRow{Entries: 12, Exits: 9, GuestEntries: 3, Anomalies: 1, WatchlistAlerts: 2, Incidents: 1}, nil
+	}
+	vehicles := int64(4)
+	store.countVehiclesOnSiteFn = func(context.Context) (int64, error) { return vehicles, nil }
+	svc := New(store, WithClock(func() time.Time { return now }))
+
+	_, err := svc.StartShift(ctx, ShiftStartInput{GuardID: resident})
+	require.ErrorIs(t, err, ErrNotGuard)
+	_, err = svc.CurrentShift(ctx, outgoing)
+	require.ErrorIs(t, err, ErrNoOpenShift)
+
+	started, err := svc.StartShift(ctx, ShiftStartInput{GuardID: outgoing, GateID: gateID})
+	require.NoError(t, err)
+	require.Equal(t, gateID, started.GateID.UUID)
+	require.Nil(t, started.Handover)
+	_, err = svc.StartShift(ctx, ShiftStartInput{GuardID: outgoing})
+	require.ErrorIs(t, err, ErrShiftOpen)
+
+	_, err = svc.RecordPassMovement(ctx, PassMovementInput{PassID: uuid.New(), GuardID: outgoing, Action: EntryActionExit, OverrideReason: "test"})
+	require.NoError(t, err)
+	require.Equal(t, started.ID, logged.ShiftID.UUID)
+	require.Equal(t, gateID, logged.GateID.UUID)
+
+	current, err := svc.CurrentShift(ctx, outgoing)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), current.Summary.Entries)
+	require.Equal(t, int64(1), current.Summary.Incidents)
+	require.Equal(t, int64(4), current.Summary.VehiclesOnSite)
+	require.False(t, summary.ToTime.Valid)
+
+	now = now.Add(12 * time.Hour)
+	ended, err := svc.EndShift(ctx, ShiftEndInput{GuardID: outgoing, ActorID: outgoing, HandoverNote: "  шлагбаум заедает  "})
+	require.NoError(t, err)
+	require.Equal(t, now, ended.EndedAt.Time)
+	require.Equal(t, "шлагбаум заедает", ended.HandoverNote.String)
+	require.Equal(t, int32(4), ended.OnSiteAtEnd.Int32)
+	require.Equal(t, now, summary.ToTime.Time)
+	_, err = svc.EndShift(ctx, ShiftEndInput{GuardID: outgoing})
+	require.ErrorIs(t, err, ErrNoOpenShift)
+	_, err = svc.EndShift(ctx, ShiftEndInput{ShiftID: started.ID})
+	require.ErrorIs(t, err, ErrNoOpenShift)
+	_, err = svc.EndShift(ctx, ShiftEndInput{ShiftID: uuid.New()})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	vehicles = 7
+	report, err := svc.GetShift(ctx, started.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), report.Summary.VehiclesOnSite)
+	_, err = svc.GetShift(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+
+	next, err := svc.StartShift(ctx, ShiftStartInput{GuardID: incoming, GateID: gateID})
+	require.NoError(t, err)
+	require.NotNil(t, next.Handover)
+	require.Equal(t, started.ID, next.Handover.ID)
+	require.Equal(t, "шлагбаум заедает", next.Handover.HandoverNote.String)
+	current, err = svc.CurrentShift(ctx, incoming)
+	require.NoError(t, err)
+	require.NotNil(t, current.Handover)
+	require.Equal(t, int64(7), current.Summary.VehiclesOnSite)
+
+	// The note is handed over once.
+	_, err = svc.EndShift(ctx, ShiftEndInput{GuardID: incoming})
+	require.NoError(t, err)
+	again, err := svc.StartShift(ctx, ShiftStartInput{GuardID: outgoing, GateID: gateID})
+	require.NoError(t, err)
+	require.Nil(t, again.Handover)
+}
+
+func TestServiceUnit_ShiftRequired(t *testing.T) {
+	ctx := context.Background()
+	guardID, adminID := uuid.New(), uuid.New()
+	var shifts []repo.GuardShift
+	store := shiftStore(&shifts, map[uuid.UUID]auth.Role{guardID: auth.RoleGuard, adminID: auth.RoleAdmin})
+	store.createEntryLogFn = func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+		return repo.EntryLog{ID: arg.ID, ShiftID: arg.ShiftID}, nil
+	}
+	svc := New(store, WithSettings(Settings{Shifts: ShiftRules{Required: true}}))
+	move := func(actor uuid.UUID) (repo.EntryLog, error) {
+		return svc.RecordPassMovement(ctx, PassMovementInput{PassID: uuid.New(), GuardID: actor, Action: EntryActionExit, OverrideReason: "test"})
+	}
+
+	_, err := move(guardID)
+	require.ErrorIs(t, err, ErrNoOpenShift)
+	entry, err := move(adminID)
+	require.NoError(t, err)
+	require.False(t, entry.ShiftID.Valid)
+
+	shift, err := svc.StartShift(ctx, ShiftStartInput{GuardID: guardID})
+	require.NoError(t, err)
+	require.False(t, shift.GateID.Valid)
+	entry, err = move(guardID)
+	require.NoError(t, err)
+	require.Equal(t, shift.ID, entry.ShiftID.UUID)
+
+	_, err = svc.ListShifts(ctx, ShiftFilter{From: time.Now(), To: time.Now().Add(-time.Hour)}, 10, 0)
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
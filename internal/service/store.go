@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -12,11 +14,16 @@ import (
 type ServiceStore interface {
 	CreateUser(ctx context.Context, arg repo.CreateUserParams) (repo.User, error)
 	GetUserByEmail(ctx context.Context, email string) (repo.User, error)
+	GetUserByEmailAny(ctx context.Context, email string) (repo.User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (repo.User, error)
 	GetUserByIDAny(ctx context.Context, id uuid.UUID) (repo.User, error)
+	GetUserByIDForUpdate(ctx context.Context, id uuid.UUID) (repo.User, error)
+	CountUsers(ctx context.Context) (int64, error)
+	LockUserBootstrap(ctx context.Context) error
 	ListUsers(ctx context.Context, arg repo.ListUsersParams) ([]repo.User, error)
 	UpdateUser(ctx context.Context, arg repo.UpdateUserParams) (repo.User, error)
 	UpdateUserPassword(ctx context.Context, arg repo.UpdateUserPasswordParams) (repo.User, error)
+	UpsertUserByEmail(ctx context.Context, arg repo.UpsertUserByEmailParams) (repo.User, error)
 	RestoreUser(ctx context.Context, arg repo.RestoreUserParams) error
 	BlockUser(ctx context.Context, arg repo.BlockUserParams) error
 	UnblockUser(ctx context.Context, arg repo.UnblockUserParams) error
@@ -25,6 +32,9 @@ type ServiceStore interface {
 	CreatePass(ctx context.Context, arg repo.CreatePassParams) (repo.Pass, error)
 	GetPassByID(ctx context.Context, id uuid.UUID) (repo.Pass, error)
 	GetPassByIDAny(ctx context.Context, id uuid.UUID) (repo.Pass, error)
+	GetPassByIDAnyForUpdate(ctx context.Context, id uuid.UUID) (repo.Pass, error)
+	GetPassByIDForUpdate(ctx context.Context, id uuid.UUID) (repo.Pass, error)
+	GetPassByOwnerAndPlate(ctx context.Context, arg repo.GetPassByOwnerAndPlateParams) (repo.Pass, error)
 	ListPasses(ctx context.Context, arg repo.ListPassesParams) ([]repo.Pass, error)
 	ListPassesByOwner(ctx context.Context, arg repo.ListPassesByOwnerParams) ([]repo.Pass, error)
 	SearchPassesByPlate(ctx context.Context, arg repo.SearchPassesByPlateParams) ([]repo.Pass, error)
@@ -35,12 +45,179 @@ type ServiceStore interface {
 	CreateGuestRequest(ctx context.Context, arg repo.CreateGuestRequestParams) (repo.GuestRequest, error)
 	GetGuestRequestByID(ctx context.Context, id uuid.UUID) (repo.GuestRequest, error)
 	GetGuestRequestByIDAny(ctx context.Context, id uuid.UUID) (repo.GuestRequest, error)
+	GetGuestRequestByIDForUpdate(ctx context.Context, id uuid.UUID) (repo.GuestRequest, error)
 	ListGuestRequests(ctx context.Context, arg repo.ListGuestRequestsParams) ([]repo.GuestRequest, error)
 	ListGuestRequestsByResident(ctx context.Context, arg repo.ListGuestRequestsByResidentParams) ([]repo.GuestRequest, error)
 	UpdateGuestRequest(ctx context.Context, arg repo.UpdateGuestRequestParams) (repo.GuestRequest, error)
+	ReviewGuestRequest(ctx context.Context, arg repo.ReviewGuestRequestParams) (repo.GuestRequest, error)
+	SetGuestRequestStatus(ctx context.Context, arg repo.SetGuestRequestStatusParams) (repo.GuestRequest, error)
+	ExpireGuestRequests(ctx context.Context, validTo time.Time) (int64, error)
+	ListGateGuests(ctx context.Context, arg repo.ListGateGuestsParams) ([]repo.ListGateGuestsRow, error)
+	ListOverlappingGuestRequests(ctx context.Context, arg repo.ListOverlappingGuestRequestsParams) ([]uuid.UUID, error)
+	MaterializeGuestOccurrence(ctx context.Context, arg repo.MaterializeGuestOccurrenceParams) error
+	GetGuestOccurrence(ctx context.Context, arg repo.GetGuestOccurrenceParams) (repo.GuestRequest, error)
+	ListGuestOccurrences(ctx context.Context, arg repo.ListGuestOccurrencesParams) ([]repo.GuestRequest, error)
+	UpdateGuestOccurrence(ctx context.Context, arg repo.UpdateGuestOccurrenceParams) (repo.GuestRequest, error)
+	DeleteGuestOccurrencesFrom(ctx context.Context, arg repo.DeleteGuestOccurrencesFromParams) (int64, error)
+	ReviewGuestOccurrences(ctx context.Context, arg repo.ReviewGuestOccurrencesParams) (int64, error)
+
+	CreateGuestSeries(ctx context.Context, arg repo.CreateGuestSeriesParams) (repo.GuestSeries, error)
+	GetGuestSeriesByID(ctx context.Context, id uuid.UUID) (repo.GuestSeries, error)
+	ListGuestSeries(ctx context.Context, arg repo.ListGuestSeriesParams) ([]repo.GuestSeries, error)
+	ListGuestSeriesByResident(ctx context.Context, arg repo.ListGuestSeriesByResidentParams) ([]repo.GuestSeries, error)
+	ListActiveGuestSeries(ctx context.Context, arg repo.ListActiveGuestSeriesParams) ([]repo.GuestSeries, error)
+	UpdateGuestSeries(ctx context.Context, arg repo.UpdateGuestSeriesParams) (repo.GuestSeries, error)
+	EndGuestSeries(ctx context.Context, arg repo.EndGuestSeriesParams) (repo.GuestSeries, error)
+	ReviewGuestSeries(ctx context.Context, arg repo.ReviewGuestSeriesParams) (repo.GuestSeries, error)
+	SetGuestSeriesStatus(ctx context.Context, arg repo.SetGuestSeriesStatusParams) (repo.GuestSeries, error)
 	RestoreGuestRequest(ctx context.Context, arg repo.RestoreGuestRequestParams) error
 	SoftDeleteGuestRequest(ctx context.Context, arg repo.SoftDeleteGuestRequestParams) error
 
+	GetGuestPin(ctx context.Context, guestRequestID uuid.UUID) (repo.GuestPin, error)
+	DeleteGuestPin(ctx context.Context, guestRequestID uuid.UUID) error
+	ReleaseGuestPinCode(ctx context.Context, arg repo.ReleaseGuestPinCodeParams) error
+	CreateGuestPin(ctx context.Context, arg repo.CreateGuestPinParams) (repo.GuestPin, error)
+	GetGuestByPin(ctx context.Context, arg repo.GetGuestByPinParams) (repo.GetGuestByPinRow, error)
+	UseGuestPin(ctx context.Context, guestRequestID uuid.UUID) error
+
+	CreateSavedGuest(ctx context.Context, arg repo.CreateSavedGuestParams) (repo.SavedGuest, error)
+	GetSavedGuest(ctx context.Context, arg repo.GetSavedGuestParams) (repo.SavedGuest, error)
+	ListSavedGuests(ctx context.Context, arg repo.ListSavedGuestsParams) ([]repo.SavedGuest, error)
+	UpdateSavedGuest(ctx context.Context, arg repo.UpdateSavedGuestParams) (repo.SavedGuest, error)
+	DeleteSavedGuest(ctx context.Context, arg repo.DeleteSavedGuestParams) (int64, error)
+	ListFrequentGuests(ctx context.Context, arg repo.ListFrequentGuestsParams) ([]repo.ListFrequentGuestsRow, error)
+
+	CreatePassSchedule(ctx context.Context, arg repo.CreatePassScheduleParams) (repo.PassSchedule, error)
+	ListPassSchedules(ctx context.Context, passID uuid.UUID) ([]repo.PassSchedule, error)
+	DeletePassSchedule(ctx context.Context, arg repo.DeletePassScheduleParams) (int64, error)
+
+	CreateHoliday(ctx context.Context, arg repo.CreateHolidayParams) (repo.Holiday, error)
+	ListHolidays(ctx context.Context, arg repo.ListHolidaysParams) ([]repo.Holiday, error)
+	IsHoliday(ctx context.Context, day time.Time) (bool, error)
+	DeleteHoliday(ctx context.Context, day time.Time) (int64, error)
+
 	CreateEntryLog(ctx context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error)
-	ListEntryLogsByPass(ctx context.Context, arg repo.ListEntryLogsByPassParams) ([]repo.EntryLog, error)
+	ListEntryLogs(ctx context.Context, arg repo.ListEntryLogsParams) ([]repo.ListEntryLogsRow, error)
+	GetEntryLog(ctx context.Context, id uuid.UUID) (repo.EntryLog, error)
+	LockEntryLogChain(ctx context.Context) error
+	GetEntryLogChainHead(ctx context.Context) (repo.GetEntryLogChainHeadRow, error)
+	ListEntryLogChain(ctx context.Context, arg repo.ListEntryLogChainParams) ([]repo.EntryLog, error)
+
+	GetPassPresence(ctx context.Context, passID uuid.NullUUID) (repo.SitePresence, error)
+	UpsertPassPresence(ctx context.Context, arg repo.UpsertPassPresenceParams) error
+	DeletePassPresence(ctx context.Context, passID uuid.NullUUID) (int64, error)
+	UpsertGuestPresence(ctx context.Context, arg repo.UpsertGuestPresenceParams) error
+	DeleteGuestPresence(ctx context.Context, guestRequestID uuid.NullUUID) (int64, error)
+	ListSitePresence(ctx context.Context, plotNumber sql.NullString) ([]repo.ListSitePresenceRow, error)
+
+	CreateWatchlistEntry(ctx context.Context, arg repo.CreateWatchlistEntryParams) (repo.PlateWatchlist, error)
+	GetWatchlistEntryByID(ctx context.Context, id uuid.UUID) (repo.PlateWatchlist, error)
+	GetWatchlistEntryByPlate(ctx context.Context, plateNumber string) (repo.PlateWatchlist, error)
+	ListWatchlist(ctx context.Context, arg repo.ListWatchlistParams) ([]repo.PlateWatchlist, error)
+	UpdateWatchlistEntry(ctx context.Context, arg repo.UpdateWatchlistEntryParams) (repo.PlateWatchlist, error)
+	SoftDeleteWatchlistEntry(ctx context.Context, arg repo.SoftDeleteWatchlistEntryParams) (int64, error)
+	CreateWatchlistHit(ctx context.Context, arg repo.CreateWatchlistHitParams) (repo.WatchlistHit, error)
+	ListWatchlistHits(ctx context.Context, arg repo.ListWatchlistHitsParams) ([]repo.WatchlistHit, error)
+
+	CreateGate(ctx context.Context, arg repo.CreateGateParams) (repo.Gate, error)
+	GetGate(ctx context.Context, id uuid.UUID) (repo.Gate, error)
+	GetGateByName(ctx context.Context, name string) (repo.Gate, error)
+	ListGates(ctx context.Context) ([]repo.Gate, error)
+	UpdateGate(ctx context.Context, arg repo.UpdateGateParams) (repo.Gate, error)
+	SoftDeleteGate(ctx context.Context, arg repo.SoftDeleteGateParams) (int64, error)
+	CountGateRestrictions(ctx context.Context, gateID uuid.UUID) (int64, error)
+	ListGuardGates(ctx context.Context, userID uuid.UUID) ([]repo.Gate, error)
+	AddGuardGate(ctx context.Context, arg repo.AddGuardGateParams) error
+	ClearGuardGates(ctx context.Context, userID uuid.UUID) error
+	DeleteGateAssignments(ctx context.Context, gateID uuid.UUID) error
+	ListPassGates(ctx context.Context, passID uuid.UUID) ([]repo.Gate, error)
+	AddPassGate(ctx context.Context, arg repo.AddPassGateParams) error
+	ClearPassGates(ctx context.Context, passID uuid.UUID) error
+	ListGuestTypeGates(ctx context.Context, guestType string) ([]repo.Gate, error)
+	ListAllGuestTypeGates(ctx context.Context) ([]repo.ListAllGuestTypeGatesRow, error)
+	AddGuestTypeGate(ctx context.Context, arg repo.AddGuestTypeGateParams) error
+	ClearGuestTypeGates(ctx context.Context, guestType string) error
+	GateStats(ctx context.Context, arg repo.GateStatsParams) ([]repo.GateStatsRow, error)
+	CreateGuardShift(ctx context.Context, arg repo.CreateGuardShiftParams) (repo.GuardShift, error)
+	GetGuardShift(ctx context.Context, id uuid.UUID) (repo.GuardShift, error)
+	GetOpenGuardShift(ctx context.Context, guardUserID uuid.UUID) (repo.GuardShift, error)
+	GetPendingHandover(ctx context.Context, gateID uuid.NullUUID) (repo.GuardShift, error)
+	EndGuardShift(ctx context.Context, arg repo.EndGuardShiftParams) (repo.GuardShift, error)
+	ListGuardShifts(ctx context.Context, arg repo.ListGuardShiftsParams) ([]repo.ListGuardShiftsRow, error)
+	GuardShiftSummary(ctx context.Context, arg repo.GuardShiftSummaryParams) (repo.GuardShiftSummaryRow, error)
+	CountVehiclesOnSite(ctx context.Context) (int64, error)
+
+	CreateIncident(ctx context.Context, arg repo.CreateIncidentParams) (repo.Incident, error)
+	GetIncident(ctx context.Context, id uuid.UUID) (repo.Incident, error)
+	ListIncidents(ctx context.Context, arg repo.ListIncidentsParams) ([]repo.ListIncidentsRow, error)
+	UpdateIncidentStatus(ctx context.Context, arg repo.UpdateIncidentStatusParams) (repo.Incident, error)
+	CreateIncidentComment(ctx context.Context, arg repo.CreateIncidentCommentParams) (repo.IncidentComment, error)
+	ListIncidentComments(ctx context.Context, incidentID uuid.UUID) ([]repo.ListIncidentCommentsRow, error)
+	CreateIncidentAttachment(ctx context.Context, arg repo.CreateIncidentAttachmentParams) (repo.IncidentAttachment, error)
+	GetIncidentAttachment(ctx context.Context, id uuid.UUID) (repo.IncidentAttachment, error)
+	ListIncidentAttachments(ctx context.Context, incidentID uuid.UUID) ([]repo.IncidentAttachment, error)
+	DeleteIncidentAttachment(ctx context.Context, id uuid.UUID) (int64, error)
+
+	CreateEntryPhoto(ctx context.Context, arg repo.CreateEntryPhotoParams) (repo.EntryPhoto, error)
+	GetEntryPhoto(ctx context.Context, id uuid.UUID) (repo.EntryPhoto, error)
+	GetEntryPhotoByLog(ctx context.Context, entryLogID uuid.UUID) (repo.EntryPhoto, error)
+	ListEntryPhotosBefore(ctx context.Context, arg repo.ListEntryPhotosBeforeParams) ([]repo.EntryPhoto, error)
+	DeleteEntryPhoto(ctx context.Context, id uuid.UUID) error
+
+	CreateCamera(ctx context.Context, arg repo.CreateCameraParams) (repo.Camera, error)
+	GetCamera(ctx context.Context, id uuid.UUID) (repo.Camera, error)
+	GetCameraByName(ctx context.Context, name string) (repo.Camera, error)
+	GetCameraByToken(ctx context.Context, tokenHash string) (repo.Camera, error)
+	ListCameras(ctx context.Context) ([]repo.Camera, error)
+	UpdateCamera(ctx context.Context, arg repo.UpdateCameraParams) (repo.Camera, error)
+	SetCameraToken(ctx context.Context, arg repo.SetCameraTokenParams) (int64, error)
+	TouchCamera(ctx context.Context, arg repo.TouchCameraParams) error
+	SoftDeleteCamera(ctx context.Context, arg repo.SoftDeleteCameraParams) (int64, error)
+	ListActivePassesByPlate(ctx context.Context, plateNumber string) ([]repo.Pass, error)
+	ListExitPassesByPlate(ctx context.Context, plateNumber string) ([]repo.Pass, error)
+	ListCurrentGuestsByPlate(ctx context.Context, arg repo.ListCurrentGuestsByPlateParams) ([]repo.GuestRequest, error)
+	CreatePlateRead(ctx context.Context, arg repo.CreatePlateReadParams) (repo.PlateRead, error)
+	GetPlateRead(ctx context.Context, id uuid.UUID) (repo.PlateRead, error)
+	ListPlateReads(ctx context.Context, arg repo.ListPlateReadsParams) ([]repo.ListPlateReadsRow, error)
+	ClaimPlateRead(ctx context.Context, arg repo.ClaimPlateReadParams) (repo.PlateRead, error)
+	ReopenPlateRead(ctx context.Context, id uuid.UUID) error
+	SetPlateReadEntryLog(ctx context.Context, arg repo.SetPlateReadEntryLogParams) error
+
+	UpsertGateBarrier(ctx context.Context, arg repo.UpsertGateBarrierParams) (repo.GateBarrier, error)
+	GetGateBarrier(ctx context.Context, gateID uuid.UUID) (repo.GateBarrier, error)
+	DeleteGateBarrier(ctx context.Context, gateID uuid.UUID) (int64, error)
+	CreateBarrierEvent(ctx context.Context, arg repo.CreateBarrierEventParams) (repo.BarrierEvent, error)
+	ListBarrierEvents(ctx context.Context, arg repo.ListBarrierEventsParams) ([]repo.BarrierEvent, error)
+	ListSyncPasses(ctx context.Context, since sql.NullTime) ([]repo.ListSyncPassesRow, error)
+	ListSyncGuests(ctx context.Context, arg repo.ListSyncGuestsParams) ([]repo.ListSyncGuestsRow, error)
+
+	CreatePlot(ctx context.Context, arg repo.CreatePlotParams) (repo.Plot, error)
+	EnsurePlot(ctx context.Context, arg repo.EnsurePlotParams) (repo.Plot, error)
+	GetPlot(ctx context.Context, id uuid.UUID) (repo.Plot, error)
+	GetPlotByNumber(ctx context.Context, number string) (repo.Plot, error)
+	ListPlots(ctx context.Context, arg repo.ListPlotsParams) ([]repo.Plot, error)
+	UpdatePlot(ctx context.Context, arg repo.UpdatePlotParams) (repo.Plot, error)
+	RenameUserPlotNumbers(ctx context.Context, arg repo.RenameUserPlotNumbersParams) error
+	ListPlotResidents(ctx context.Context, plotID uuid.UUID) ([]repo.ListPlotResidentsRow, error)
+	ListUserPlots(ctx context.Context, userID uuid.UUID) ([]repo.ListUserPlotsRow, error)
+	GetUserPlot(ctx context.Context, arg repo.GetUserPlotParams) (repo.GetUserPlotRow, error)
+	UpsertPlotResident(ctx context.Context, arg repo.UpsertPlotResidentParams) (repo.PlotResident, error)
+	AddPlotResident(ctx context.Context, arg repo.AddPlotResidentParams) (int64, error)
+	DeletePlotResident(ctx context.Context, arg repo.DeletePlotResidentParams) (int64, error)
+	UnlinkPreviousUserPlot(ctx context.Context, arg repo.UnlinkPreviousUserPlotParams) error
+	RefreshUserPlotNumber(ctx context.Context, arg repo.RefreshUserPlotNumberParams) error
+	ListPassesByPlot(ctx context.Context, arg repo.ListPassesByPlotParams) ([]repo.Pass, error)
+	ListGuestRequestsByPlot(ctx context.Context, arg repo.ListGuestRequestsByPlotParams) ([]repo.GuestRequest, error)
+
+	SuspendUserPasses(ctx context.Context, arg repo.SuspendUserPassesParams) ([]uuid.UUID, error)
+	RevokeUserPasses(ctx context.Context, arg repo.RevokeUserPassesParams) ([]uuid.UUID, error)
+	SuspendUserGuestRequests(ctx context.Context, arg repo.SuspendUserGuestRequestsParams) ([]uuid.UUID, error)
+	CancelUserGuestRequests(ctx context.Context, arg repo.CancelUserGuestRequestsParams) ([]uuid.UUID, error)
+	RestoreCascadePasses(ctx context.Context, arg repo.RestoreCascadePassesParams) ([]uuid.UUID, error)
+	RestoreCascadeGuestRequests(ctx context.Context, arg repo.RestoreCascadeGuestRequestsParams) ([]uuid.UUID, error)
+
+	ExportUsers(ctx context.Context, arg repo.ExportUsersParams) ([]repo.User, error)
+	ExportPasses(ctx context.Context, arg repo.ExportPassesParams) ([]repo.ExportPassesRow, error)
+	ExportGuestRequests(ctx context.Context, arg repo.ExportGuestRequestsParams) ([]repo.ExportGuestRequestsRow, error)
+	ExportEntryLogs(ctx context.Context, arg repo.ExportEntryLogsParams) ([]repo.ExportEntryLogsRow, error)
 }
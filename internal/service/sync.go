@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	PassStatusActive = "active"
+
+	SyncStatusCreated   = "created"
+	SyncStatusDuplicate = "duplicate"
+	SyncStatusConflict  = "conflict"
+	SyncStatusInvalid   = "invalid"
+
+	SyncConflictNotFound         = "not_found"
+	SyncConflictPassInactive     = "pass_inactive"
+	SyncConflictResidentInactive = "resident_inactive"
+	SyncConflictGuestStatus      = "guest_status"
+	SyncConflictOutsideWindow    = "outside_window"
+	SyncConflictWatchlist        = "watchlist_blocked"
+	SyncConflictGate             = "gate"
+	SyncConflictNoShift          = "no_shift"
+	SyncConflictIDTaken          = "id_taken"
+
+	// MaxSyncBatch caps the entries accepted in one upload.
+	MaxSyncBatch = 200
+	// SyncHorizon is how far ahead the offline guest list reaches.
+	SyncHorizon = 24 * time.Hour
+
+	// syncClockSkew tolerates device clocks running a little ahead.
+	syncClockSkew = 5 * time.Minute
+	// syncCursorLag moves the delta cursor back so rows written by
+	// transactions still open at snapshot time are picked up next time.
+	syncCursorLag = time.Minute
+)
+
+var (
+	ErrSyncBatchSize = errors.New("batch must hold between 1 and 200 entries")
+	ErrPassInactive  = errors.New("pass is not active")
+)
+
+// SyncEntryInput is a movement a guard device logged while offline. ID is
+// generated on the device and makes uploading the same entry again a no-op.
+// Exactly one of PassID and GuestID is set.
+type SyncEntryInput struct {
+	ID             uuid.UUID
+	PassID         uuid.UUID
+	GuestID        uuid.UUID
+	Action         string
+	DeviceAt       time.Time
+	GateID         uuid.UUID
+	Comment        string
+	OverrideReason string
+}
+
+// SyncResult is the outcome of one uploaded entry. Conflict names why the
+// server refused an entry that was valid on the device; Entry is set for
+// created and duplicate entries.
+type SyncResult struct {
+	ID       uuid.UUID
+	Status   string
+	Conflict string
+	Error    string
+	Entry    *repo.EntryLog
+}
+
+// SyncEntryLogs records entries uploaded by a guard device after it was
+// offline. Entries are applied oldest first, each in its own transaction, so
+// a refused entry does not hold back the rest; results come back in upload
+// order. An error means the batch stopped half way and can simply be sent
+// again.
+func (s *Service) SyncEntryLogs(ctx context.Context, guardID uuid.UUID, items []SyncEntryInput) ([]SyncResult, error) {
+	if len(items) == 0 || len(items) > MaxSyncBatch {
+		return nil, ErrSyncBatchSize
+	}
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return items[order[a]].DeviceAt.Before(items[order[b]].DeviceAt)
+	})
+	results := make([]SyncResult, len(items))
+	for _, i := range order {
+		result, err := s.syncEntry(ctx, guardID, items[i])
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func (s *Service) syncEntry(ctx context.Context, guardID uuid.UUID, item SyncEntryInput) (SyncResult, error) {
+	result := SyncResult{ID: item.ID}
+	if reason := s.validateSyncEntry(item); reason != "" {
+		result.Status, result.Error = SyncStatusInvalid, reason
+		return result, nil
+	}
+	existing, err := s.q.GetEntryLog(ctx, item.ID)
+	if err == nil {
+		return syncExisting(result, item, existing), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return SyncResult{}, err
+	}
+	entry, err := s.recordSyncedEntry(ctx, guardID, item)
+	if errors.Is(err, ErrEntryLogExists) {
+		if existing, err = s.q.GetEntryLog(ctx, item.ID); err != nil {
+			return SyncResult{}, err
+		}
+		return syncExisting(result, item, existing), nil
+	}
+	if conflict := syncConflict(err); conflict != "" {
+		result.Status, result.Conflict, result.Error = SyncStatusConflict, conflict, err.Error()
+		return result, nil
+	}
+	if err != nil {
+		return SyncResult{}, err
+	}
+	result.Status, result.Entry = SyncStatusCreated, &entry
+	return result, nil
+}
+
+func (s *Service) validateSyncEntry(item SyncEntryInput) string {
+	switch {
+	case item.ID == uuid.Nil:
+		return "id is required"
+	case (item.PassID == uuid.Nil) == (item.GuestID == uuid.Nil):
+		return "exactly one of pass_id and guest_request_id is required"
+	case item.Action != EntryActionEntry && item.Action != EntryActionExit:
+		return ErrInvalidEntryAction.Error()
+	case item.DeviceAt.IsZero():
+		return "device_time is required"
+	case item.DeviceAt.After(s.now().Add(syncClockSkew)):
+		return "device_time is in the future"
+	}
+	return ""
+}
+
+// syncExisting answers a retried upload. The same id for another movement
+// means the device reused it, which is reported rather than overwritten.
+func syncExisting(result SyncResult, item SyncEntryInput, existing repo.EntryLog) SyncResult {
+	same := existing.Action == item.Action &&
+		existing.PassID == (uuid.NullUUID{UUID: item.PassID, Valid: item.PassID != uuid.Nil}) &&
+		existing.GuestRequestID == (uuid.NullUUID{UUID: item.GuestID, Valid: item.GuestID != uuid.Nil})
+	if !same {
+		result.Status, result.Conflict = SyncStatusConflict, SyncConflictIDTaken
+		result.Error = "id belongs to another journal record"
+		return result
+	}
+	result.Status, result.Entry = SyncStatusDuplicate, &existing
+	return result
+}
+
+// recordSyncedEntry runs the checks a guard online would have hit: an entry
+// needs an active pass and the plate not blacklisted. Offline entries cannot
+// be overridden by a supervisor, so a blacklisted entry is refused. An exit
+// is recorded even for a pass suspended or deleted while the car was inside.
+func (s *Service) recordSyncedEntry(ctx context.Context, guardID uuid.UUID, item SyncEntryInput) (repo.EntryLog, error) {
+	comment := strings.TrimSpace(item.Comment)
+	if item.PassID != uuid.Nil {
+		var pass repo.Pass
+		var err error
+		if item.Action == EntryActionEntry {
+			pass, err = s.GetPass(ctx, item.PassID)
+		} else {
+			pass, err = s.GetPassAny(ctx, item.PassID)
+		}
+		if err != nil {
+			return repo.EntryLog{}, err
+		}
+		if item.Action == EntryActionEntry {
+			if err := checkPassActive(ctx, s.q, pass); err != nil {
+				return repo.EntryLog{}, err
+			}
+		}
+		hit, err := s.CheckGate(ctx, GateCheckInput{PassID: pass.ID, PlateNumber: pass.PlateNumber, UserID: guardID, Source: item.Action, Comment: comment})
+		if err != nil {
+			return repo.EntryLog{}, err
+		}
+		return s.RecordPassMovement(ctx, PassMovementInput{
+			ID:             item.ID,
+			PassID:         pass.ID,
+			GuardID:        guardID,
+			GateID:         item.GateID,
+			Action:         item.Action,
+			Comment:        toNullNotes(comment),
+			OverrideReason: item.OverrideReason,
+			Watchlist:      hit,
+			DeviceAt:       item.DeviceAt,
+		})
+	}
+	guest, err := s.GetGuestRequest(ctx, item.GuestID)
+	if err != nil {
+		return repo.EntryLog{}, err
+	}
+	hit, err := s.CheckGate(ctx, GateCheckInput{GuestID: guest.ID, PlateNumber: guest.PlateNumber, UserID: guardID, Source: item.Action, Comment: comment})
+	if err != nil {
+		return repo.EntryLog{}, err
+	}
+	visit := GuestVisitInput{
+		ID:        item.ID,
+		GuestID:   guest.ID,
+		GuardID:   guardID,
+		GateID:    item.GateID,
+		Comment:   toNullNotes(comment),
+		Watchlist: hit,
+		DeviceAt:  item.DeviceAt,
+	}
+	if item.Action == EntryActionEntry {
+		return s.CheckInGuest(ctx, visit)
+	}
+	return s.CheckOutGuest(ctx, visit)
+}
+
+// checkPassActive refuses passes suspended, revoked or belonging to a
+// blocked or removed owner.
+func checkPassActive(ctx context.Context, q ServiceStore, pass repo.Pass) error {
+	if pass.Status != PassStatusActive {
+		return ErrPassInactive
+	}
+	owner, err := q.GetUserByID(ctx, pass.OwnerUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPassInactive
+	}
+	if err != nil {
+		return err
+	}
+	if owner.BlockedAt.Valid {
+		return ErrPassInactive
+	}
+	return nil
+}
+
+// syncConflict names the reason the journal refused an uploaded entry, or
+// returns "" for errors that are not about the entry itself.
+func syncConflict(err error) string {
+	var presence *PresenceConflictError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &presence):
+		return presence.Anomaly
+	case errors.Is(err, ErrNotFound):
+		return SyncConflictNotFound
+	case errors.Is(err, ErrPassInactive):
+		return SyncConflictPassInactive
+	case errors.Is(err, ErrResidentInactive):
+		return SyncConflictResidentInactive
+	case errors.Is(err, ErrGuestTransition):
+		return SyncConflictGuestStatus
+	case errors.Is(err, ErrOutsideGuestWindow):
+		return SyncConflictOutsideWindow
+	case errors.Is(err, ErrPlateBlacklisted):
+		return SyncConflictWatchlist
+	case errors.Is(err, ErrUnknownGate), errors.Is(err, ErrGateRequired),
+		errors.Is(err, ErrGateNotAllowed), errors.Is(err, ErrGateNotAssigned):
+		return SyncConflictGate
+	case errors.Is(err, ErrNoOpenShift):
+		return SyncConflictNoShift
+	}
+	return ""
+}
+
+// SyncSnapshot is what a guard device needs to check vehicles offline. A
+// full snapshot holds the active passes and the guests expected within
+// SyncHorizon; a delta holds everything that changed since the last cursor,
+// including passes and guests that stopped being active.
+type SyncSnapshot struct {
+	Full   bool
+	Cursor time.Time
+	Passes []repo.ListSyncPassesRow
+	Guests []repo.ListSyncGuestsRow
+}
+
+// SyncDelta returns the changes since the cursor of the previous call, or a
+// full snapshot when since is zero. Devices drop guests whose window has
+// ended themselves; occurrences removed from a series are only gone after
+// the next full snapshot.
+func (s *Service) SyncDelta(ctx context.Context, since time.Time) (SyncSnapshot, error) {
+	now := s.now()
+	if since.After(now) {
+		return SyncSnapshot{}, ErrInvalidRange
+	}
+	if err := s.materializeGuestSeries(ctx, now, now.Add(SyncHorizon)); err != nil {
+		return SyncSnapshot{}, err
+	}
+	sinceParam := sql.NullTime{Time: since, Valid: !since.IsZero()}
+	passes, err := s.q.ListSyncPasses(ctx, sinceParam)
+	if err != nil {
+		return SyncSnapshot{}, err
+	}
+	guests, err := s.q.ListSyncGuests(ctx, repo.ListSyncGuestsParams{
+		WindowEnd:   now.Add(SyncHorizon),
+		WindowStart: now,
+		Since:       sinceParam,
+		// Guests whose window moved into the horizon since the last call
+		// are new to the device even if nothing about them changed.
+		UpcomingFrom: sql.NullTime{Time: since.Add(SyncHorizon), Valid: !since.IsZero()},
+	})
+	if err != nil {
+		return SyncSnapshot{}, err
+	}
+	return SyncSnapshot{
+		Full:   since.IsZero(),
+		Cursor: now.Add(-syncCursorLag),
+		Passes: passes,
+		Guests: guests,
+	}, nil
+}
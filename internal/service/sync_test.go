@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_SyncEntryLogs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 15, 0, 0, 0, time.UTC)
+	guardID := uuid.New()
+	owner := repo.User{ID: uuid.New()}
+	blocked := repo.User{ID: uuid.New(), BlockedAt: sql.NullTime{Time: now, Valid: true}}
+	active := repo.Pass{ID: uuid.New(), OwnerUserID: owner.ID, PlateNumber: "A123BC77", Status: PassStatusActive}
+	revoked := repo.Pass{ID: uuid.New(), OwnerUserID: owner.ID, PlateNumber: "B234CD77", Status: "revoked"}
+	ownerBlocked := repo.Pass{ID: uuid.New(), OwnerUserID: blocked.ID, PlateNumber: "C345EF77", Status: PassStatusActive}
+	listed := repo.Pass{ID: uuid.New(), OwnerUserID: owner.ID, PlateNumber: "E456KM77", Status: PassStatusActive}
+	suspended := repo.Pass{ID: uuid.New(), OwnerUserID: owner.ID, PlateNumber: "K678MN77", Status: "suspended"}
+	guest := repo.GuestRequest{ID: uuid.New(), Status: GuestStatusApproved, PlateNumber: "H567OP77",
+		ValidFrom: now.Add(-5 * time.Hour), ValidTo: now.Add(-3 * time.Hour)}
+
+	passes := map[uuid.UUID]repo.Pass{active.ID: active, revoked.ID: revoked, ownerBlocked.ID: ownerBlocked, listed.ID: listed, suspended.ID: suspended}
+	entries := map[uuid.UUID]repo.EntryLog{}
+	var (
+		presence  []repo.UpsertPassPresenceParams
+		enteredAt time.Time
+	)
+	store := &mockStore{
+		getPassByIDFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			pass, ok := passes[id]
+			if !ok {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return pass, nil
+		},
+		getPassByIDAnyFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			pass, ok := passes[id]
+			if !ok {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return pass, nil
+		},
+		getPassByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			pass, ok := passes[id]
+			if !ok {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return pass, nil
+		},
+		getPassByIDAnyForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			pass, ok := passes[id]
+			if !ok {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return pass, nil
+		},
+		getUserByIDFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			if id == blocked.ID {
+				return blocked, nil
+			}
+			return owner, nil
+		},
+		getGuestRequestByIDFn: func(context.Context, uuid.UUID) (repo.GuestRequest, error) {
+			return guest, nil
+		},
+		setGuestRequestStatusFn: func(_ context.Context, arg repo.SetGuestRequestStatusParams) (repo.GuestRequest, error) {
+			guest.Status = arg.Status
+			return guest, nil
+		},
+		getWatchlistEntryByPlateFn: func(_ context.Context, plate string) (repo.PlateWatchlist, error) {
+			if plate != listed.PlateNumber {
+				return repo.PlateWatchlist{}, sql.ErrNoRows
+			}
+			return repo.PlateWatchlist{ID: uuid.New(), PlateNumber: plate, Severity: SeverityBlacklist}, nil
+		},
+		createWatchlistHitFn: func(context.Context, repo.CreateWatchlistHitParams) (repo.WatchlistHit, error) {
+			return repo.WatchlistHit{}, nil
+		},
+		getPassPresenceFn: func(_ context.Context, id uuid.NullUUID) (repo.SitePresence, error) {
+			for _, p := range presence {
+				if p.PassID == id {
+					return repo.SitePresence{PassID: id, EnteredAt: p.EnteredAt}, nil
+				}
+			}
+			return repo.SitePresence{}, sql.ErrNoRows
+		},
+		upsertPassPresenceFn: func(_ context.Context, arg repo.UpsertPassPresenceParams) error {
+			presence = append(presence, arg)
+			enteredAt = arg.EnteredAt
+			return nil
+		},
+		deletePassPresenceFn: func(context.Context, uuid.NullUUID) (int64, error) {
+			presence = nil
+			return 1, nil
+		},
+		getEntryLogFn: func(_ context.Context, id uuid.UUID) (repo.EntryLog, error) {
+			entry, ok := entries[id]
+			if !ok {
+				return repo.EntryLog{}, sql.ErrNoRows
+			}
+			return entry, nil
+		},
+		createEntryLogFn: func(_ context.Context, arg repo.CreateEntryLogParams) (repo.EntryLog, error) {
+			entry := repo.EntryLog{ID: arg.ID, PassID: arg.PassID, GuestRequestID: arg.GuestRequestID, GuardUserID: arg.GuardUserID,
+				Action: arg.Action, ActionAt: arg.ActionAt, DeviceAt: arg.DeviceAt, Hash: arg.Hash}
+			entries[arg.ID] = entry
+			return entry, nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+
+	_, err := svc.SyncEntryLogs(ctx, guardID, nil)
+	require.ErrorIs(t, err, ErrSyncBatchSize)
+
+	entryID, exitID, guestID := uuid.New(), uuid.New(), uuid.New()
+	items := []SyncEntryInput{
+		{ID: exitID, PassID: active.ID, Action: EntryActionExit, DeviceAt: now.Add(-3 * time.Hour)},
+		{ID: entryID, PassID: active.ID, Action: EntryActionEntry, DeviceAt: now.Add(-4 * time.Hour), Comment: " offline "},
+		{ID: guestID, GuestID: guest.ID, Action: EntryActionEntry, DeviceAt: now.Add(-4 * time.Hour)},
+		{ID: uuid.New(), PassID: revoked.ID, Action: EntryActionEntry, DeviceAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), PassID: ownerBlocked.ID, Action: EntryActionEntry, DeviceAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), PassID: listed.ID, Action: EntryActionEntry, DeviceAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), PassID: uuid.New(), Action: EntryActionEntry, DeviceAt: now.Add(-time.Hour)},
+		{ID: uuid.New(), PassID: active.ID, Action: EntryActionExit, DeviceAt: now.Add(-time.Hour)},
+		{PassID: active.ID, Action: EntryActionEntry, DeviceAt: now},
+		{ID: uuid.New(), PassID: active.ID, GuestID: guest.ID, Action: EntryActionEntry, DeviceAt: now},
+		{ID: uuid.New(), PassID: active.ID, Action: EntryActionEntry, DeviceAt: now.Add(time.Hour)},
+	}
+	results, err := svc.SyncEntryLogs(ctx, guardID, items)
+	require.NoError(t, err)
+	require.Len(t, results, len(items))
+	statuses := make([]string, 0, len(results))
+	for _, result := range results {
+		statuses = append(statuses, result.Status+":"+result.Conflict)
+	}
+	require.Equal(t, []string{
+		"created:", "created:", "created:",
+		"conflict:pass_inactive", "conflict:pass_inactive", "conflict:watchlist_blocked", "conflict:not_found",
+		"conflict:exit_without_entry",
+		"invalid:", "invalid:", "invalid:",
+	}, statuses)
+	require.Equal(t, exitID, results[0].ID)
+
+	// The entry went in before the exit although it was uploaded second, and
+	// presence started at the device time.
+	entry := entries[entryID]
+	require.Equal(t, now, entry.ActionAt)
+	require.Equal(t, now.Add(-4*time.Hour), entry.DeviceAt.Time)
+	require.Equal(t, now.Add(-4*time.Hour), enteredAt)
+	require.Nil(t, presence)
+	require.Equal(t, GuestStatusArrived, guest.Status, "the guest window is checked against the device time")
+
+	results, err = svc.SyncEntryLogs(ctx, guardID, []SyncEntryInput{
+		items[1],
+		{ID: entryID, PassID: active.ID, Action: EntryActionExit, DeviceAt: now},
+	})
+	require.NoError(t, err)
+	require.Equal(t, SyncStatusDuplicate, results[0].Status)
+	require.Equal(t, entryID, results[0].Entry.ID)
+	require.Equal(t, SyncStatusConflict, results[1].Status)
+	require.Equal(t, SyncConflictIDTaken, results[1].Conflict)
+
+	// A car whose pass was suspended while it was inside can still leave.
+	presence = []repo.UpsertPassPresenceParams{{PassID: uuid.NullUUID{UUID: suspended.ID, Valid: true}, EnteredAt: now.Add(-2 * time.Hour)}}
+	results, err = svc.SyncEntryLogs(ctx, guardID, []SyncEntryInput{
+		{ID: uuid.New(), PassID: suspended.ID, Action: EntryActionExit, DeviceAt: now},
+		{ID: uuid.New(), PassID: suspended.ID, Action: EntryActionEntry, DeviceAt: now},
+	})
+	require.NoError(t, err)
+	require.Equal(t, SyncStatusCreated, results[0].Status)
+	require.Equal(t, EntryActionExit, results[0].Entry.Action)
+	require.Nil(t, presence)
+	require.Equal(t, SyncConflictPassInactive, results[1].Conflict)
+
+	// A retry that raced the first upload is caught inside the transaction.
+	racedID := uuid.New()
+	lookups := 0
+	store.getEntryLogFn = func(_ context.Context, id uuid.UUID) (repo.EntryLog, error) {
+		lookups++
+		if lookups == 1 {
+			return repo.EntryLog{}, sql.ErrNoRows
+		}
+		return repo.EntryLog{ID: id, PassID: uuid.NullUUID{UUID: active.ID, Valid: true}, Action: EntryActionEntry}, nil
+	}
+	results, err = svc.SyncEntryLogs(ctx, guardID, []SyncEntryInput{{ID: racedID, PassID: active.ID, Action: EntryActionEntry, DeviceAt: now}})
+	require.NoError(t, err)
+	require.Equal(t, SyncStatusDuplicate, results[0].Status)
+
+	store.getEntryLogFn = func(context.Context, uuid.UUID) (repo.EntryLog, error) {
+		return repo.EntryLog{}, sql.ErrConnDone
+	}
+	_, err = svc.SyncEntryLogs(ctx, guardID, items[:1])
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.Contains(t, entryLogCanonical(entry), "device_at:", "the device time is sealed into the chain")
+}
+
+func TestServiceUnit_SyncDelta(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 15, 0, 0, 0, time.UTC)
+	var (
+		passSince sql.NullTime
+		guests    repo.ListSyncGuestsParams
+	)
+	svc := New(&mockStore{
+		listSyncPassesFn: func(_ context.Context, since sql.NullTime) ([]repo.ListSyncPassesRow, error) {
+			passSince = since
+			return []repo.ListSyncPassesRow{{ID: uuid.New(), Active: true}}, nil
+		},
+		listSyncGuestsFn: func(_ context.Context, arg repo.ListSyncGuestsParams) ([]repo.ListSyncGuestsRow, error) {
+			guests = arg
+			return nil, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	snapshot, err := svc.SyncDelta(ctx, time.Time{})
+	require.NoError(t, err)
+	require.True(t, snapshot.Full)
+	require.Len(t, snapshot.Passes, 1)
+	require.False(t, passSince.Valid)
+	require.False(t, guests.Since.Valid)
+	require.Equal(t, now, guests.WindowStart)
+	require.Equal(t, now.Add(SyncHorizon), guests.WindowEnd)
+	require.True(t, snapshot.Cursor.Before(now))
+
+	since := snapshot.Cursor
+	snapshot, err = svc.SyncDelta(ctx, since)
+	require.NoError(t, err)
+	require.False(t, snapshot.Full)
+	require.Equal(t, since, passSince.Time)
+	require.Equal(t, since, guests.Since.Time)
+	require.Equal(t, since.Add(SyncHorizon), guests.UpcomingFrom.Time)
+
+	_, err = svc.SyncDelta(ctx, now.Add(time.Minute))
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
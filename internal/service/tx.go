@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+
+	defaultTxAttempts = 3
+	txRetryBackoff    = 20 * time.Millisecond
+)
+
+// TxRunner runs fn in a transaction with a store bound to it. fn may run
+// more than once: a transaction that lost a serialization conflict or a
+// deadlock is rolled back and tried again, so fn must not keep state from
+// a failed attempt.
+type TxRunner interface {
+	RunInTx(ctx context.Context, fn func(ServiceStore) error) error
+}
+
+type TxOption func(*sqlTxRunner)
+
+// TxIsolation sets the isolation level of every transaction; by default the
+// database default is used, read committed in Postgres.
+func TxIsolation(level sql.IsolationLevel) TxOption {
+	return func(r *sqlTxRunner) {
+		r.opts.Isolation = level
+	}
+}
+
+// TxAttempts bounds how many times a transaction is tried.
+func TxAttempts(attempts int) TxOption {
+	return func(r *sqlTxRunner) {
+		if attempts > 0 {
+			r.attempts = attempts
+		}
+	}
+}
+
+type sqlTxRunner struct {
+	db       *sql.DB
+	opts     sql.TxOptions
+	attempts int
+}
+
+func NewTxRunner(db *sql.DB, opts ...TxOption) TxRunner {
+	r := &sqlTxRunner{db: db, attempts: defaultTxAttempts}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *sqlTxRunner) RunInTx(ctx context.Context, fn func(ServiceStore) error) error {
+	return retryTx(ctx, r.attempts, txRetryBackoff, func() error {
+		tx, err := r.db.BeginTx(ctx, &r.opts)
+		if err != nil {
+			return err
+		}
+		if err := fn(repo.New(tx)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// retryTx runs attempt until it succeeds, fails with an error a retry
+// cannot fix, or runs out of attempts. The wait grows with every attempt.
+func retryTx(ctx context.Context, attempts int, backoff time.Duration, attempt func() error) error {
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil || i >= attempts || !retryableTx(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(i) * backoff):
+		}
+	}
+}
+
+// retryableTx reports whether the transaction failed only because it
+// collided with a concurrent one.
+func retryableTx(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
+
+func WithTxRunner(runner TxRunner) Option {
+	return func(s *Service) {
+		if runner != nil {
+			s.tx = runner
+		}
+	}
+}
+
+// inTx runs fn inside a transaction when a runner is configured and against
+// the plain store otherwise, which keeps mock-based tests working.
+func (s *Service) inTx(ctx context.Context, fn func(ServiceStore) error) error {
+	if s.tx == nil {
+		return fn(s.q)
+	}
+	return s.tx.RunInTx(ctx, fn)
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_RetryTx(t *testing.T) {
+	ctx := context.Background()
+	conflict := &pgconn.PgError{Code: pgSerializationFailure}
+
+	attempts := 0
+	err := retryTx(ctx, 3, 0, func() error {
+		attempts++
+		if attempts < 3 {
+			return conflict
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = retryTx(ctx, 2, 0, func() error {
+		attempts++
+		return &pgconn.PgError{Code: pgDeadlockDetected}
+	})
+	require.ErrorAs(t, err, new(*pgconn.PgError))
+	require.Equal(t, 2, attempts, "the attempts are bounded")
+
+	attempts = 0
+	err = retryTx(ctx, 3, 0, func() error {
+		attempts++
+		return ErrNotFound
+	})
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Equal(t, 1, attempts, "only conflicts are retried")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	attempts = 0
+	err = retryTx(canceled, 3, time.Hour, func() error {
+		attempts++
+		return conflict
+	})
+	require.ErrorIs(t, err, conflict)
+	require.Equal(t, 1, attempts)
+
+	require.False(t, retryableTx(&pgconn.PgError{Code: "23505"}))
+	require.False(t, retryableTx(nil))
+}
+
+func TestServiceUnit_ModifyPass(t *testing.T) {
+	ctx := context.Background()
+	passID, ownerID, plotID := uuid.New(), uuid.New(), uuid.New()
+	var updated repo.UpdatePassParams
+	store := &mockStore{
+		getPassByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.Pass, error) {
+			if id != passID {
+				return repo.Pass{}, sql.ErrNoRows
+			}
+			return repo.Pass{ID: id, OwnerUserID: ownerID, PlateNumber: "A123BC77", Status: PassStatusSuspended}, nil
+		},
+		getUserPlotFn: func(_ context.Context, arg repo.GetUserPlotParams) (repo.GetUserPlotRow, error) {
+			if arg.ID != plotID || arg.UserID != ownerID {
+				return repo.GetUserPlotRow{}, sql.ErrNoRows
+			}
+			return repo.GetUserPlotRow{ID: plotID, Status: PlotStatusActive}, nil
+		},
+		updatePassFn: func(_ context.Context, arg repo.UpdatePassParams) (repo.Pass, error) {
+			updated = arg
+			return repo.Pass{ID: arg.ID, PlateNumber: arg.PlateNumber, Status: arg.Status}, nil
+		},
+	}
+	runner := &storeTxRunner{store: store}
+	svc := New(store, WithTxRunner(runner))
+
+	pass, err := svc.ModifyPass(ctx, passID, func(current repo.Pass) (PassUpdateInput, error) {
+		return PassUpdateInput{PlateNumber: "в456ке77", Status: current.Status, PlotID: plotID}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, PassStatusSuspended, pass.Status, "the update starts from the locked row")
+	require.Equal(t, passID, updated.ID)
+	require.Equal(t, "В456КЕ77", updated.PlateNumber)
+	require.Equal(t, plotID, updated.PlotID.UUID)
+	require.Equal(t, 1, runner.calls)
+
+	_, err = svc.ModifyPass(ctx, uuid.New(), func(repo.Pass) (PassUpdateInput, error) {
+		t.Fatal("fn must not run for a missing pass")
+		return PassUpdateInput{}, nil
+	})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	updated = repo.UpdatePassParams{}
+	_, err = svc.ModifyPass(ctx, passID, func(repo.Pass) (PassUpdateInput, error) {
+		return PassUpdateInput{}, ErrForbidden
+	})
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.ModifyPass(ctx, passID, func(current repo.Pass) (PassUpdateInput, error) {
+		return PassUpdateInput{PlateNumber: "bad"}, nil
+	})
+	require.Error(t, err)
+	_, err = svc.ModifyPass(ctx, passID, func(current repo.Pass) (PassUpdateInput, error) {
+		return PassUpdateInput{PlateNumber: current.PlateNumber, PlotID: uuid.New()}, nil
+	})
+	require.ErrorIs(t, err, ErrPlotNotLinked)
+	require.Equal(t, repo.UpdatePassParams{}, updated, "a refused change writes nothing")
+
+	store.updatePassFn = func(context.Context, repo.UpdatePassParams) (repo.Pass, error) {
+		return repo.Pass{}, sql.ErrNoRows
+	}
+	_, err = svc.ModifyPass(ctx, passID, func(current repo.Pass) (PassUpdateInput, error) {
+		return PassUpdateInput{PlateNumber: current.PlateNumber}, nil
+	})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestServiceUnit_BootstrapAdmin(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	users := int64(0)
+	store := &mockStore{
+		lockUserBootstrapFn: func(context.Context) error {
+			calls = append(calls, "lock")
+			return nil
+		},
+		countUsersFn: func(context.Context) (int64, error) {
+			calls = append(calls, "count")
+			return users, nil
+		},
+		createUserFn: func(_ context.Context, arg repo.CreateUserParams) (repo.User, error) {
+			require.Equal(t, "admin@example.com", arg.Email)
+			require.Equal(t, "admin", arg.Role)
+			require.Equal(t, "hash", arg.PasswordHash)
+			calls = append(calls, "create")
+			users++
+			return repo.User{ID: uuid.New()}, nil
+		},
+	}
+	runner := &storeTxRunner{store: store}
+	svc := New(store, WithTxRunner(runner))
+
+	created, err := svc.BootstrapAdmin(ctx, "admin@example.com", "Admin", "hash")
+	require.NoError(t, err)
+	require.True(t, created)
+	created, err = svc.BootstrapAdmin(ctx, "admin@example.com", "Admin", "hash")
+	require.NoError(t, err)
+	require.False(t, created, "existing users skip the bootstrap")
+	require.Equal(t, []string{"lock", "count", "create", "lock", "count"}, calls)
+	require.Equal(t, 2, runner.calls)
+
+	store.countUsersFn = func(context.Context) (int64, error) { return 0, sql.ErrConnDone }
+	_, err = svc.BootstrapAdmin(ctx, "admin@example.com", "Admin", "hash")
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	store.lockUserBootstrapFn = func(context.Context) error { return sql.ErrConnDone }
+	_, err = svc.BootstrapAdmin(ctx, "admin@example.com", "Admin", "hash")
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestServiceUnit_ModifyGuestRequest(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	guestID, residentID := uuid.New(), uuid.New()
+	current := repo.GuestRequest{ID: guestID, ResidentUserID: residentID, GuestFullName: "Guest", PlateNumber: "A123BC77",
+		GuestType: GuestTypeVehicle, Status: GuestStatusApproved, ValidFrom: now, ValidTo: now.Add(2 * time.Hour)}
+	var updated repo.UpdateGuestRequestParams
+	store := &mockStore{
+		getGuestRequestByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.GuestRequest, error) {
+			if id != guestID {
+				return repo.GuestRequest{}, sql.ErrNoRows
+			}
+			return current, nil
+		},
+		getUserPlotFn: func(context.Context, repo.GetUserPlotParams) (repo.GetUserPlotRow, error) {
+			return repo.GetUserPlotRow{}, sql.ErrNoRows
+		},
+		updateGuestRequestFn: func(_ context.Context, arg repo.UpdateGuestRequestParams) (repo.GuestRequest, error) {
+			updated = arg
+			return repo.GuestRequest{ID: arg.ID, GuestFullName: arg.GuestFullName, Status: current.Status}, nil
+		},
+	}
+	runner := &storeTxRunner{store: store}
+	svc := New(store, WithTxRunner(runner), WithClock(func() time.Time { return now }))
+	edit := func(guest repo.GuestRequest) (GuestUpdateInput, error) {
+		return GuestUpdateInput{GuestName: "Tutor", PlateNumber: guest.PlateNumber, ValidFrom: guest.ValidFrom,
+			ValidTo: guest.ValidTo, Status: GuestStatusPending}, nil
+	}
+
+	guest, err := svc.ModifyGuestRequest(ctx, guestID, edit)
+	require.NoError(t, err)
+	require.Equal(t, "Tutor", guest.GuestFullName)
+	require.Equal(t, GuestStatusApproved, guest.Status, "the status comes from the locked row")
+	require.Equal(t, guestID, updated.ID)
+	require.Equal(t, 1, runner.calls)
+
+	// A review that landed before the lock is seen by fn.
+	updated = repo.UpdateGuestRequestParams{}
+	_, err = svc.ModifyGuestRequest(ctx, guestID, func(guest repo.GuestRequest) (GuestUpdateInput, error) {
+		if guest.Status != GuestStatusPending {
+			return GuestUpdateInput{}, ErrGuestReviewed
+		}
+		return edit(guest)
+	})
+	require.ErrorIs(t, err, ErrGuestReviewed)
+	current.Status = GuestStatusArrived
+	_, err = svc.ModifyGuestRequest(ctx, guestID, edit)
+	require.ErrorIs(t, err, ErrGuestTransition)
+	current.Status = GuestStatusApproved
+	_, err = svc.ModifyGuestRequest(ctx, guestID, func(guest repo.GuestRequest) (GuestUpdateInput, error) {
+		input, _ := edit(guest)
+		input.PlotID = uuid.New()
+		return input, nil
+	})
+	require.ErrorIs(t, err, ErrPlotNotLinked)
+	require.Equal(t, repo.UpdateGuestRequestParams{}, updated, "a refused change writes nothing")
+
+	_, err = svc.ModifyGuestRequest(ctx, uuid.New(), edit)
+	require.ErrorIs(t, err, ErrNotFound)
+	store.updateGuestRequestFn = func(context.Context, repo.UpdateGuestRequestParams) (repo.GuestRequest, error) {
+		return repo.GuestRequest{}, sql.ErrNoRows
+	}
+	_, err = svc.ModifyGuestRequest(ctx, guestID, edit)
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	PassStatusSuspended = "suspended"
+	PassStatusRevoked   = "revoked"
+
+	CascadeNone    = "none"
+	CascadeSuspend = "suspend"
+	CascadeRevoke  = "revoke"
+
+	cascadeActionBlock  = "block"
+	cascadeActionDelete = "delete"
+)
+
+// CascadeRules decide what blocking or deleting a user does to their passes
+// and pending guest requests. Empty policies leave them untouched.
+type CascadeRules struct {
+	// OnBlock is CascadeSuspend or CascadeNone.
+	OnBlock string
+	// OnDelete is CascadeRevoke or CascadeNone.
+	OnDelete string
+	// Restore brings back what the cascade changed when the user is
+	// unblocked or restored. Passes and requests changed by hand since are
+	// left alone.
+	Restore bool
+}
+
+// CascadeReport lists the passes and guest requests whose status changed
+// together with the user.
+type CascadeReport struct {
+	Passes        []uuid.UUID
+	GuestRequests []uuid.UUID
+}
+
+// BlockUser blocks a user and, with CascadeSuspend, suspends their active
+// passes and pending guest requests in the same transaction.
+func (s *Service) BlockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (CascadeReport, error) {
+	var report CascadeReport
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if _, err := lockUser(ctx, q, id, false); err != nil {
+			return err
+		}
+		actorID := uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}
+		if err := q.BlockUser(ctx, repo.BlockUserParams{ID: id, UpdatedBy: actorID}); err != nil {
+			return err
+		}
+		if s.settings.Cascade.OnBlock != CascadeSuspend {
+			return nil
+		}
+		var err error
+		report.Passes, err = q.SuspendUserPasses(ctx, repo.SuspendUserPassesParams{UserID: id, ActorID: actorID})
+		if err != nil {
+			return err
+		}
+		report.GuestRequests, err = q.SuspendUserGuestRequests(ctx, repo.SuspendUserGuestRequestsParams{UserID: id, ActorID: actorID})
+		return err
+	})
+	return report, err
+}
+
+func (s *Service) UnblockUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (CascadeReport, error) {
+	var report CascadeReport
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if _, err := lockUser(ctx, q, id, false); err != nil {
+			return err
+		}
+		actorID := uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}
+		if err := q.UnblockUser(ctx, repo.UnblockUserParams{ID: id, UpdatedBy: actorID}); err != nil {
+			return err
+		}
+		if !s.settings.Cascade.Restore {
+			return nil
+		}
+		var err error
+		report, err = restoreCascade(ctx, q, id, cascadeActionBlock, actorID)
+		return err
+	})
+	return report, err
+}
+
+// SoftDeleteUser deletes a user and, with CascadeRevoke, revokes their
+// active and suspended passes and cancels their pending, approved and
+// suspended guest requests in the same transaction.
+func (s *Service) SoftDeleteUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (CascadeReport, error) {
+	var report CascadeReport
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if _, err := lockUser(ctx, q, id, false); err != nil {
+			return err
+		}
+		actorID := uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}
+		if err := q.SoftDeleteUser(ctx, repo.SoftDeleteUserParams{ID: id, UpdatedBy: actorID}); err != nil {
+			return err
+		}
+		if s.settings.Cascade.OnDelete != CascadeRevoke {
+			return nil
+		}
+		var err error
+		report.Passes, err = q.RevokeUserPasses(ctx, repo.RevokeUserPassesParams{UserID: id, ActorID: actorID})
+		if err != nil {
+			return err
+		}
+		report.GuestRequests, err = q.CancelUserGuestRequests(ctx, repo.CancelUserGuestRequestsParams{UserID: id, ActorID: actorID})
+		return err
+	})
+	return report, err
+}
+
+func (s *Service) RestoreUser(ctx context.Context, id uuid.UUID, actor uuid.UUID) (CascadeReport, error) {
+	var report CascadeReport
+	err := s.inTx(ctx, func(q ServiceStore) error {
+		if _, err := lockUser(ctx, q, id, true); err != nil {
+			return err
+		}
+		actorID := uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil}
+		if err := q.RestoreUser(ctx, repo.RestoreUserParams{ID: id, UpdatedBy: actorID}); err != nil {
+			return err
+		}
+		if !s.settings.Cascade.Restore {
+			return nil
+		}
+		var err error
+		report, err = restoreCascade(ctx, q, id, cascadeActionDelete, actorID)
+		return err
+	})
+	return report, err
+}
+
+// lockUser locks the user row until the transaction ends, so that blocks,
+// deletions and their cascades of one user run one after another. Deleted
+// users are only found with deleted set.
+func lockUser(ctx context.Context, q ServiceStore, id uuid.UUID, deleted bool) (repo.User, error) {
+	user, err := q.GetUserByIDForUpdate(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user.DeletedAt.Valid && !deleted) {
+		return repo.User{}, ErrNotFound
+	}
+	return user, err
+}
+
+// restoreCascade puts back the statuses a block or a delete changed, unless
+// they were changed again since.
+func restoreCascade(ctx context.Context, q ServiceStore, id uuid.UUID, action string, actor uuid.NullUUID) (CascadeReport, error) {
+	passes, err := q.RestoreCascadePasses(ctx, repo.RestoreCascadePassesParams{UserID: id, Action: action, ActorID: actor})
+	if err != nil {
+		return CascadeReport{}, err
+	}
+	guests, err := q.RestoreCascadeGuestRequests(ctx, repo.RestoreCascadeGuestRequestsParams{UserID: id, Action: action, ActorID: actor})
+	if err != nil {
+		return CascadeReport{}, err
+	}
+	return CascadeReport{Passes: passes, GuestRequests: guests}, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_UserCascade(t *testing.T) {
+	ctx := context.Background()
+	userID, actorID := uuid.New(), uuid.New()
+	passID, guestID, deletedID := uuid.New(), uuid.New(), uuid.New()
+	var calls []string
+	store := &mockStore{
+		getUserByIDForUpdateFn: func(_ context.Context, id uuid.UUID) (repo.User, error) {
+			switch id {
+			case userID:
+				return repo.User{ID: id}, nil
+			case deletedID:
+				return repo.User{ID: id, DeletedAt: sql.NullTime{Valid: true}}, nil
+			}
+			return repo.User{}, sql.ErrNoRows
+		},
+		blockUserFn: func(context.Context, repo.BlockUserParams) error {
+			calls = append(calls, "block")
+			return nil
+		},
+		unblockUserFn: func(context.Context, repo.UnblockUserParams) error {
+			calls = append(calls, "unblock")
+			return nil
+		},
+		softDeleteUserFn: func(context.Context, repo.SoftDeleteUserParams) error {
+			calls = append(calls, "delete")
+			return nil
+		},
+		restoreUserFn: func(context.Context, repo.RestoreUserParams) error {
+			calls = append(calls, "restore")
+			return nil
+		},
+		suspendUserPassesFn: func(_ context.Context, arg repo.SuspendUserPassesParams) ([]uuid.UUID, error) {
+			require.Equal(t, userID, arg.UserID)
+			require.Equal(t, actorID, arg.ActorID.UUID)
+			calls = append(calls, "suspend passes")
+			return []uuid.UUID{passID}, nil
+		},
+		suspendUserGuestRequestsFn: func(_ context.Context, arg repo.SuspendUserGuestRequestsParams) ([]uuid.UUID, error) {
+			calls = append(calls, "suspend guests")
+			return []uuid.UUID{guestID}, nil
+		},
+		revokeUserPassesFn: func(_ context.Context, arg repo.RevokeUserPassesParams) ([]uuid.UUID, error) {
+			require.Equal(t, userID, arg.UserID)
+			calls = append(calls, "revoke passes")
+			return []uuid.UUID{passID}, nil
+		},
+		cancelUserGuestRequestsFn: func(_ context.Context, arg repo.CancelUserGuestRequestsParams) ([]uuid.UUID, error) {
+			calls = append(calls, "cancel guests")
+			return nil, nil
+		},
+		restoreCascadePassesFn: func(_ context.Context, arg repo.RestoreCascadePassesParams) ([]uuid.UUID, error) {
+			calls = append(calls, "restore passes "+arg.Action)
+			return []uuid.UUID{passID}, nil
+		},
+		restoreCascadeGuestRequestsFn: func(_ context.Context, arg repo.RestoreCascadeGuestRequestsParams) ([]uuid.UUID, error) {
+			calls = append(calls, "restore guests "+arg.Action)
+			return []uuid.UUID{guestID}, nil
+		},
+	}
+	runner := &storeTxRunner{store: store}
+	svc := New(store, WithTxRunner(runner), WithSettings(Settings{Cascade: CascadeRules{
+		OnBlock:  CascadeSuspend,
+		OnDelete: CascadeRevoke,
+		Restore:  true,
+	}}))
+
+	report, err := svc.BlockUser(ctx, userID, actorID)
+	require.NoError(t, err)
+	require.Equal(t, CascadeReport{Passes: []uuid.UUID{passID}, GuestRequests: []uuid.UUID{guestID}}, report)
+	report, err = svc.UnblockUser(ctx, userID, actorID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{passID}, report.Passes)
+	report, err = svc.SoftDeleteUser(ctx, userID, actorID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{passID}, report.Passes)
+	require.Empty(t, report.GuestRequests)
+	_, err = svc.RestoreUser(ctx, userID, actorID)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"block", "suspend passes", "suspend guests",
+		"unblock", "restore passes block", "restore guests block",
+		"delete", "revoke passes", "cancel guests",
+		"restore", "restore passes delete", "restore guests delete",
+	}, calls)
+	require.Equal(t, 4, runner.calls)
+
+	// Unknown users are refused before anything changes.
+	calls = nil
+	for _, op := range []func(context.Context, uuid.UUID, uuid.UUID) (CascadeReport, error){
+		svc.BlockUser, svc.UnblockUser, svc.SoftDeleteUser, svc.RestoreUser,
+	} {
+		_, err = op(ctx, uuid.New(), actorID)
+		require.ErrorIs(t, err, ErrNotFound)
+	}
+	require.Empty(t, calls)
+	_, err = svc.BlockUser(ctx, deletedID, actorID)
+	require.ErrorIs(t, err, ErrNotFound, "a deleted user is restored, not blocked")
+	_, err = svc.RestoreUser(ctx, deletedID, actorID)
+	require.NoError(t, err)
+
+	// A failed cascade fails the whole operation, so the transaction rolls
+	// the user change back too.
+	store.suspendUserPassesFn = func(context.Context, repo.SuspendUserPassesParams) ([]uuid.UUID, error) {
+		return nil, sql.ErrConnDone
+	}
+	store.restoreCascadeGuestRequestsFn = func(context.Context, repo.RestoreCascadeGuestRequestsParams) ([]uuid.UUID, error) {
+		return nil, sql.ErrConnDone
+	}
+	_, err = svc.BlockUser(ctx, userID, actorID)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	_, err = svc.RestoreUser(ctx, userID, actorID)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// Without policies only the user row changes.
+	calls = nil
+	svc = New(store)
+	for _, op := range []func(context.Context, uuid.UUID, uuid.UUID) (CascadeReport, error){
+		svc.BlockUser, svc.UnblockUser, svc.SoftDeleteUser, svc.RestoreUser,
+	} {
+		report, err = op(ctx, userID, actorID)
+		require.NoError(t, err)
+		require.Equal(t, CascadeReport{}, report)
+	}
+	require.Equal(t, []string{"block", "unblock", "delete", "restore"}, calls)
+}
@@ -7,6 +7,15 @@ import (
 
 var plateRegexp = regexp.MustCompile(`^[ABEKMHOPCTYXАВЕКМНОРСТУХ]{1}\d{3}[ABEKMHOPCTYXАВЕКМНОРСТУХ]{2}\d{2,3}$`)
 
+var (
+	plotPrefixRegexp = regexp.MustCompile(`^(?:(?:УЧАСТОК|УЧ\.?|№|#)\s*)+`)
+	plotNumberRegexp = regexp.MustCompile(`^\d{1,5}[A-ZА-ЯЁ]{0,2}(?:[/-](?:\d{1,5}[A-ZА-ЯЁ]{0,2}|[A-ZА-ЯЁ]{1,2}))?$`)
+	// plotLookalikes writes Cyrillic letters that look like Latin ones in
+	// Latin, so "12а" typed on a Russian keyboard and "12a" are one plot.
+	plotLookalikes = strings.NewReplacer("А", "A", "В", "B", "Е", "E", "К", "K", "М", "M", "Н", "H",
+		"О", "O", "Р", "P", "С", "C", "Т", "T", "У", "Y", "Х", "X")
+)
+
 func NormalizePlate(input string) string {
 	upper := strings.ToUpper(strings.TrimSpace(input))
 	return upper
@@ -20,6 +29,23 @@ func ValidatePlate(input string) error {
 	return nil
 }
 
+// NormalizePlotNumber brings a plot number to the form stored in plots:
+// "уч. 12а", "12 A" and "12A" all become "12A". Migration 0023 applies the
+// same rules to existing values.
+func NormalizePlotNumber(input string) string {
+	value := strings.ToUpper(strings.TrimSpace(input))
+	value = plotPrefixRegexp.ReplaceAllString(value, "")
+	value = strings.Join(strings.Fields(value), "")
+	return plotLookalikes.Replace(value)
+}
+
+func ValidatePlotNumber(input string) error {
+	if !plotNumberRegexp.MatchString(NormalizePlotNumber(input)) {
+		return ErrInvalidPlot
+	}
+	return nil
+}
+
 func ValidateRole(role string) error {
 	switch role {
 	case "admin", "guard", "resident":
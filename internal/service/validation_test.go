@@ -19,6 +19,35 @@ func TestValidatePlate(t *testing.T) {
 	}
 }
 
+func TestValidatePlotNumber(t *testing.T) {
+	normalized := map[string]string{
+		"12A":          "12A",
+		" уч. 12а ":    "12A",
+		"Участок № 12": "12",
+		"12 Б":         "12Б",
+		"#7-1":         "7-1",
+		"":             "",
+	}
+	for input, want := range normalized {
+		if got := NormalizePlotNumber(input); got != want {
+			t.Fatalf("NormalizePlotNumber(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	valid := []string{"1", "12A", "уч. 12а", "15/2", "104-Б"}
+	invalid := []string{"", "уч.", "A12", "12ABC", "дом у реки"}
+	for _, plot := range valid {
+		if err := ValidatePlotNumber(plot); err != nil {
+			t.Fatalf("expected valid plot %s, got %v", plot, err)
+		}
+	}
+	for _, plot := range invalid {
+		if err := ValidatePlotNumber(plot); err == nil {
+			t.Fatalf("expected invalid plot %s", plot)
+		}
+	}
+}
+
 func TestValidateRole(t *testing.T) {
 	valid := []string{"admin", "guard", "resident"}
 	invalid := []string{"", "owner", "user"}
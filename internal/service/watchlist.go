@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+const (
+	SeverityInfo      = "info"
+	SeverityWarning   = "warning"
+	SeverityBlacklist = "blacklist"
+
+	WatchlistSourceSearch = "search"
+	WatchlistSourceEntry  = "entry"
+	WatchlistSourceExit   = "exit"
+
+	WatchlistFlagged    = "flagged"
+	WatchlistBlocked    = "blocked"
+	WatchlistOverridden = "overridden"
+)
+
+var (
+	ErrInvalidSeverity  = errors.New("invalid severity")
+	ErrWatchlistExists  = errors.New("plate is already on the watchlist")
+	ErrPlateBlacklisted = errors.New("plate is blacklisted")
+	ErrOverrideComment  = errors.New("override requires a comment")
+)
+
+type WatchlistInput struct {
+	ID          uuid.UUID
+	PlateNumber string
+	Severity    string
+	Reason      string
+	ExpiresAt   sql.NullTime
+	ActorID     uuid.UUID
+}
+
+// GateCheckInput describes an entry or exit attempt of a resident's car
+// (PassID) or a guest's (GuestID). Override is honoured only for supervisors
+// and must carry a comment.
+type GateCheckInput struct {
+	PassID      uuid.UUID
+	GuestID     uuid.UUID
+	PlateNumber string
+	UserID      uuid.UUID
+	Source      string
+	Override    bool
+	Comment     string
+}
+
+// WatchlistHit is a watchlist match on an entry or exit. A vehicle that is
+// turned away is logged by CheckGate itself; a hit that lets the vehicle
+// through is logged with the movement, so the log never shows a car passing
+// that the journal refused.
+type WatchlistHit struct {
+	Entry   repo.PlateWatchlist
+	Check   GateCheckInput
+	Outcome string
+}
+
+func ValidateSeverity(severity string) error {
+	switch severity {
+	case SeverityInfo, SeverityWarning, SeverityBlacklist:
+		return nil
+	default:
+		return ErrInvalidSeverity
+	}
+}
+
+func (s *Service) validateWatchlist(input WatchlistInput) error {
+	if err := ValidatePlate(input.PlateNumber); err != nil {
+		return err
+	}
+	if err := ValidateSeverity(input.Severity); err != nil {
+		return err
+	}
+	if strings.TrimSpace(input.Reason) == "" {
+		return ErrInvalidInput
+	}
+	if input.ExpiresAt.Valid && !input.ExpiresAt.Time.After(s.now()) {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+func (s *Service) CreateWatchlistEntry(ctx context.Context, input WatchlistInput) (repo.PlateWatchlist, error) {
+	if err := s.validateWatchlist(input); err != nil {
+		return repo.PlateWatchlist{}, err
+	}
+	plate := NormalizePlate(input.PlateNumber)
+	if _, err := s.q.GetWatchlistEntryByPlate(ctx, plate); err == nil {
+		return repo.PlateWatchlist{}, ErrWatchlistExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return repo.PlateWatchlist{}, err
+	}
+	actor := uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil}
+	return s.q.CreateWatchlistEntry(ctx, repo.CreateWatchlistEntryParams{
+		PlateNumber: plate,
+		Severity:    input.Severity,
+		Reason:      strings.TrimSpace(input.Reason),
+		ExpiresAt:   input.ExpiresAt,
+		CreatedBy:   actor,
+		UpdatedBy:   actor,
+	})
+}
+
+func (s *Service) GetWatchlistEntry(ctx context.Context, id uuid.UUID) (repo.PlateWatchlist, error) {
+	entry, err := s.q.GetWatchlistEntryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.PlateWatchlist{}, ErrNotFound
+		}
+		return repo.PlateWatchlist{}, err
+	}
+	return entry, nil
+}
+
+func (s *Service) ListWatchlist(ctx context.Context, includeExpired bool, limit, offset int32) ([]repo.PlateWatchlist, error) {
+	return s.q.ListWatchlist(ctx, repo.ListWatchlistParams{Column1: includeExpired, Limit: limit, Offset: offset})
+}
+
+func (s *Service) UpdateWatchlistEntry(ctx context.Context, input WatchlistInput) (repo.PlateWatchlist, error) {
+	if err := s.validateWatchlist(input); err != nil {
+		return repo.PlateWatchlist{}, err
+	}
+	plate := NormalizePlate(input.PlateNumber)
+	if existing, err := s.q.GetWatchlistEntryByPlate(ctx, plate); err == nil && existing.ID != input.ID {
+		return repo.PlateWatchlist{}, ErrWatchlistExists
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return repo.PlateWatchlist{}, err
+	}
+	entry, err := s.q.UpdateWatchlistEntry(ctx, repo.UpdateWatchlistEntryParams{
+		ID:          input.ID,
+		PlateNumber: plate,
+		Severity:    input.Severity,
+		Reason:      strings.TrimSpace(input.Reason),
+		ExpiresAt:   input.ExpiresAt,
+		UpdatedBy:   uuid.NullUUID{UUID: input.ActorID, Valid: input.ActorID != uuid.Nil},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.PlateWatchlist{}, ErrNotFound
+	}
+	return entry, err
+}
+
+func (s *Service) DeleteWatchlistEntry(ctx context.Context, id uuid.UUID, actor uuid.UUID) error {
+	affected, err := s.q.SoftDeleteWatchlistEntry(ctx, repo.SoftDeleteWatchlistEntryParams{
+		ID:        id,
+		UpdatedBy: uuid.NullUUID{UUID: actor, Valid: actor != uuid.Nil},
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Service) ListWatchlistHits(ctx context.Context, id uuid.UUID, limit, offset int32) ([]repo.WatchlistHit, error) {
+	return s.q.ListWatchlistHits(ctx, repo.ListWatchlistHitsParams{WatchlistID: id, Limit: limit, Offset: offset})
+}
+
+// activeWatchlistEntry returns the unexpired entry for plate, or nil.
+func (s *Service) activeWatchlistEntry(ctx context.Context, plate string) (*repo.PlateWatchlist, error) {
+	entry, err := s.q.GetWatchlistEntryByPlate(ctx, NormalizePlate(plate))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if entry.ExpiresAt.Valid && !entry.ExpiresAt.Time.After(s.now()) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// recordWatchlistHit logs hit; a nil hit, a vehicle not on the watchlist,
+// is skipped.
+func recordWatchlistHit(ctx context.Context, q ServiceStore, hit *WatchlistHit) error {
+	if hit == nil {
+		return nil
+	}
+	input := hit.Check
+	_, err := q.CreateWatchlistHit(ctx, repo.CreateWatchlistHitParams{
+		WatchlistID:    hit.Entry.ID,
+		PlateNumber:    hit.Entry.PlateNumber,
+		PassID:         uuid.NullUUID{UUID: input.PassID, Valid: input.PassID != uuid.Nil},
+		GuestRequestID: uuid.NullUUID{UUID: input.GuestID, Valid: input.GuestID != uuid.Nil},
+		UserID:         uuid.NullUUID{UUID: input.UserID, Valid: input.UserID != uuid.Nil},
+		Source:         input.Source,
+		Outcome:        hit.Outcome,
+		Comment:        sql.NullString{String: strings.TrimSpace(input.Comment), Valid: strings.TrimSpace(input.Comment) != ""},
+	})
+	return err
+}
+
+// FlagPasses looks up watchlist entries for search results and logs a hit for
+// every flagged pass. The result is keyed by pass id.
+func (s *Service) FlagPasses(ctx context.Context, passes []repo.Pass, userID uuid.UUID) (map[uuid.UUID]repo.PlateWatchlist, error) {
+	flags := map[uuid.UUID]repo.PlateWatchlist{}
+	byPlate := map[string]*repo.PlateWatchlist{}
+	for _, pass := range passes {
+		entry, seen := byPlate[pass.PlateNumber]
+		if !seen {
+			var err error
+			if entry, err = s.activeWatchlistEntry(ctx, pass.PlateNumber); err != nil {
+				return nil, err
+			}
+			byPlate[pass.PlateNumber] = entry
+		}
+		if entry == nil {
+			continue
+		}
+		hit := &WatchlistHit{Entry: *entry, Check: GateCheckInput{PassID: pass.ID, UserID: userID, Source: WatchlistSourceSearch}, Outcome: WatchlistFlagged}
+		if err := recordWatchlistHit(ctx, s.q, hit); err != nil {
+			return nil, err
+		}
+		flags[pass.ID] = *entry
+	}
+	return flags, nil
+}
+
+// CheckGate matches an entry or exit attempt against the watchlist and
+// returns the hit, or nil for a plate not on it. Entries for blacklisted
+// plates fail with ErrPlateBlacklisted unless overridden, and the refusal is
+// logged at once. Any other hit is passed on with the movement and logged
+// only once the journal has recorded it.
+func (s *Service) CheckGate(ctx context.Context, input GateCheckInput) (*WatchlistHit, error) {
+	if input.PlateNumber == "" {
+		// Pedestrian guests have no plate to match.
+		return nil, nil
+	}
+	entry, err := s.activeWatchlistEntry(ctx, input.PlateNumber)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	hit := &WatchlistHit{Entry: *entry, Check: input, Outcome: WatchlistFlagged}
+	if entry.Severity == SeverityBlacklist && input.Source == WatchlistSourceEntry {
+		hit.Outcome = WatchlistBlocked
+		if input.Override {
+			if strings.TrimSpace(input.Comment) == "" {
+				return hit, ErrOverrideComment
+			}
+			hit.Outcome = WatchlistOverridden
+		}
+	}
+	if hit.Outcome == WatchlistBlocked {
+		if err := recordWatchlistHit(ctx, s.q, hit); err != nil {
+			return nil, err
+		}
+		return hit, ErrPlateBlacklisted
+	}
+	return hit, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	repo "pipo-edu-project/internal/repository/sqlc"
+)
+
+func TestServiceUnit_WatchlistCRUD(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	actorID := uuid.New()
+	existingID := uuid.New()
+	var created repo.CreateWatchlistEntryParams
+	var updated repo.UpdateWatchlistEntryParams
+	store := &mockStore{
+		getWatchlistEntryByPlateFn: func(_ context.Context, plate string) (repo.PlateWatchlist, error) {
+			if plate == "M777MM77" {
+				return repo.PlateWatchlist{ID: existingID, PlateNumber: plate}, nil
+			}
+			return repo.PlateWatchlist{}, sql.ErrNoRows
+		},
+		createWatchlistEntryFn: func(_ context.Context, arg repo.CreateWatchlistEntryParams) (repo.PlateWatchlist, error) {
+			created = arg
+			return repo.PlateWatchlist{ID: uuid.New(), PlateNumber: arg.PlateNumber}, nil
+		},
+		updateWatchlistEntryFn: func(_ context.Context, arg repo.UpdateWatchlistEntryParams) (repo.PlateWatchlist, error) {
+			updated = arg
+			if arg.ID == existingID {
+				return repo.PlateWatchlist{ID: arg.ID}, nil
+			}
+			return repo.PlateWatchlist{}, sql.ErrNoRows
+		},
+		getWatchlistEntryByIDFn: func(_ context.Context, id uuid.UUID) (repo.PlateWatchlist, error) {
+			if id == existingID {
+				return repo.PlateWatchlist{ID: id}, nil
+			}
+			return repo.PlateWatchlist{}, sql.ErrNoRows
+		},
+		softDeleteWatchlistEntryFn: func(_ context.Context, arg repo.SoftDeleteWatchlistEntryParams) (int64, error) {
+			if arg.ID == existingID {
+				return 1, nil
+			}
+			return 0, nil
+		},
+		listWatchlistFn: func(_ context.Context, arg repo.ListWatchlistParams) ([]repo.PlateWatchlist, error) {
+			require.True(t, arg.Column1)
+			return []repo.PlateWatchlist{{ID: existingID}}, nil
+		},
+		listWatchlistHitsFn: func(_ context.Context, arg repo.ListWatchlistHitsParams) ([]repo.WatchlistHit, error) {
+			return []repo.WatchlistHit{{WatchlistID: arg.WatchlistID}}, nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+
+	entry, err := svc.CreateWatchlistEntry(ctx, WatchlistInput{
+		PlateNumber: " a123bc77 ",
+		Severity:    SeverityBlacklist,
+		Reason:      " stolen ",
+		ExpiresAt:   sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+		ActorID:     actorID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "A123BC77", entry.PlateNumber)
+	require.Equal(t, "stolen", created.Reason)
+	require.Equal(t, actorID, created.CreatedBy.UUID)
+
+	invalid := []WatchlistInput{
+		{PlateNumber: "bad", Severity: SeverityInfo, Reason: "x"},
+		{PlateNumber: "A123BC77", Severity: "critical", Reason: "x"},
+		{PlateNumber: "A123BC77", Severity: SeverityInfo, Reason: " "},
+		{PlateNumber: "A123BC77", Severity: SeverityInfo, Reason: "x", ExpiresAt: sql.NullTime{Time: now, Valid: true}},
+	}
+	for _, input := range invalid {
+		_, err := svc.CreateWatchlistEntry(ctx, input)
+		require.Error(t, err)
+		_, err = svc.UpdateWatchlistEntry(ctx, input)
+		require.Error(t, err)
+	}
+	_, err = svc.CreateWatchlistEntry(ctx, WatchlistInput{PlateNumber: "M777MM77", Severity: SeverityInfo, Reason: "x"})
+	require.ErrorIs(t, err, ErrWatchlistExists)
+
+	_, err = svc.UpdateWatchlistEntry(ctx, WatchlistInput{ID: existingID, PlateNumber: "M777MM77", Severity: SeverityWarning, Reason: "check"})
+	require.NoError(t, err)
+	require.Equal(t, SeverityWarning, updated.Severity)
+	_, err = svc.UpdateWatchlistEntry(ctx, WatchlistInput{ID: uuid.New(), PlateNumber: "M777MM77", Severity: SeverityInfo, Reason: "x"})
+	require.ErrorIs(t, err, ErrWatchlistExists)
+	_, err = svc.UpdateWatchlistEntry(ctx, WatchlistInput{ID: uuid.New(), PlateNumber: "C333CC77", Severity: SeverityInfo, Reason: "x"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.GetWatchlistEntry(ctx, existingID)
+	require.NoError(t, err)
+	_, err = svc.GetWatchlistEntry(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, svc.DeleteWatchlistEntry(ctx, existingID, actorID))
+	require.ErrorIs(t, svc.DeleteWatchlistEntry(ctx, uuid.New(), actorID), ErrNotFound)
+
+	list, err := svc.ListWatchlist(ctx, true, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	hits, err := svc.ListWatchlistHits(ctx, existingID, 20, 0)
+	require.NoError(t, err)
+	require.Equal(t, existingID, hits[0].WatchlistID)
+}
+
+func TestServiceUnit_WatchlistLookupErrors(t *testing.T) {
+	ctx := context.Background()
+	repoErr := errors.New("repo failed")
+	store := &mockStore{
+		getWatchlistEntryByPlateFn: func(context.Context, string) (repo.PlateWatchlist, error) { return repo.PlateWatchlist{}, repoErr },
+		getWatchlistEntryByIDFn:    func(context.Context, uuid.UUID) (repo.PlateWatchlist, error) { return repo.PlateWatchlist{}, repoErr },
+		softDeleteWatchlistEntryFn: func(context.Context, repo.SoftDeleteWatchlistEntryParams) (int64, error) { return 0, repoErr },
+	}
+	svc := New(store)
+	input := WatchlistInput{PlateNumber: "A123BC77", Severity: SeverityInfo, Reason: "x"}
+	_, err := svc.CreateWatchlistEntry(ctx, input)
+	require.ErrorIs(t, err, repoErr)
+	_, err = svc.UpdateWatchlistEntry(ctx, input)
+	require.ErrorIs(t, err, repoErr)
+	_, err = svc.GetWatchlistEntry(ctx, uuid.New())
+	require.ErrorIs(t, err, repoErr)
+	require.ErrorIs(t, svc.DeleteWatchlistEntry(ctx, uuid.New(), uuid.Nil), repoErr)
+	_, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "A123BC77", Source: WatchlistSourceEntry})
+	require.ErrorIs(t, err, repoErr)
+	_, err = svc.FlagPasses(ctx, []repo.Pass{{ID: uuid.New(), PlateNumber: "A123BC77"}}, uuid.Nil)
+	require.ErrorIs(t, err, repoErr)
+}
+
+func TestServiceUnit_CheckGate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := map[string]repo.PlateWatchlist{
+		"A123BC77": {ID: uuid.New(), PlateNumber: "A123BC77", Severity: SeverityBlacklist},
+		"M777MM77": {ID: uuid.New(), PlateNumber: "M777MM77", Severity: SeverityWarning},
+		"C333CC77": {ID: uuid.New(), PlateNumber: "C333CC77", Severity: SeverityBlacklist, ExpiresAt: sql.NullTime{Time: now, Valid: true}},
+	}
+	var hits []repo.CreateWatchlistHitParams
+	store := &mockStore{
+		getWatchlistEntryByPlateFn: func(_ context.Context, plate string) (repo.PlateWatchlist, error) {
+			if entry, ok := entries[plate]; ok {
+				return entry, nil
+			}
+			return repo.PlateWatchlist{}, sql.ErrNoRows
+		},
+		createWatchlistHitFn: func(_ context.Context, arg repo.CreateWatchlistHitParams) (repo.WatchlistHit, error) {
+			hits = append(hits, arg)
+			return repo.WatchlistHit{}, nil
+		},
+	}
+	svc := New(store, WithClock(func() time.Time { return now }))
+	guardID := uuid.New()
+	passID := uuid.New()
+
+	hit, err := svc.CheckGate(ctx, GateCheckInput{PassID: passID, PlateNumber: "a123bc77", UserID: guardID, Source: WatchlistSourceEntry})
+	require.ErrorIs(t, err, ErrPlateBlacklisted)
+	require.NotNil(t, hit)
+	require.Equal(t, WatchlistBlocked, hits[0].Outcome)
+	require.Equal(t, passID, hits[0].PassID.UUID)
+
+	_, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "A123BC77", Source: WatchlistSourceEntry, Override: true, Comment: " "})
+	require.ErrorIs(t, err, ErrOverrideComment)
+	require.Len(t, hits, 1)
+
+	// Hits that let the vehicle through are left to the movement to log.
+	hit, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "A123BC77", Source: WatchlistSourceEntry, Override: true, Comment: "police escort"})
+	require.NoError(t, err)
+	require.Equal(t, WatchlistOverridden, hit.Outcome)
+	require.Equal(t, "police escort", hit.Check.Comment)
+
+	hit, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "A123BC77", Source: WatchlistSourceExit})
+	require.NoError(t, err)
+	require.Equal(t, WatchlistFlagged, hit.Outcome)
+
+	hit, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "M777MM77", Source: WatchlistSourceEntry})
+	require.NoError(t, err)
+	require.Equal(t, SeverityWarning, hit.Entry.Severity)
+	require.Len(t, hits, 1)
+	require.NoError(t, recordWatchlistHit(ctx, store, hit))
+	require.Equal(t, WatchlistFlagged, hits[1].Outcome)
+	require.Equal(t, hit.Entry.ID, hits[1].WatchlistID)
+	require.NoError(t, recordWatchlistHit(ctx, store, nil))
+
+	hit, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "C333CC77", Source: WatchlistSourceEntry})
+	require.NoError(t, err)
+	require.Nil(t, hit)
+	hit, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "B456CE99", Source: WatchlistSourceEntry})
+	require.NoError(t, err)
+	require.Nil(t, hit)
+	require.Len(t, hits, 2)
+
+	passes := []repo.Pass{
+		{ID: uuid.New(), PlateNumber: "A123BC77"},
+		{ID: uuid.New(), PlateNumber: "A123BC77"},
+		{ID: uuid.New(), PlateNumber: "B456CE99"},
+	}
+	flags, err := svc.FlagPasses(ctx, passes, guardID)
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	require.Equal(t, WatchlistSourceSearch, hits[len(hits)-1].Source)
+
+	store.createWatchlistHitFn = func(context.Context, repo.CreateWatchlistHitParams) (repo.WatchlistHit, error) {
+		return repo.WatchlistHit{}, errors.New("write failed")
+	}
+	_, err = svc.CheckGate(ctx, GateCheckInput{PlateNumber: "A123BC77", Source: WatchlistSourceEntry})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrPlateBlacklisted)
+	_, err = svc.FlagPasses(ctx, passes, guardID)
+	require.Error(t, err)
+}
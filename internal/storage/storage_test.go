@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "incidents/a/b", strings.NewReader("photo")))
+	file, err := store.Open(ctx, "incidents/a/b")
+	require.NoError(t, err)
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.Equal(t, "photo", string(data))
+
+	require.NoError(t, store.Put(ctx, "incidents/a/b", strings.NewReader("replaced")))
+	file, err = store.Open(ctx, "incidents/a/b")
+	require.NoError(t, err)
+	data, _ = io.ReadAll(file)
+	file.Close()
+	require.Equal(t, "replaced", string(data))
+
+	require.NoError(t, store.Delete(ctx, "incidents/a/b"))
+	require.NoError(t, store.Delete(ctx, "incidents/a/b"))
+	_, err = store.Open(ctx, "incidents/a/b")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "a/../../b", "a//b", `a\b`} {
+		require.ErrorIs(t, store.Put(ctx, key, strings.NewReader("x")), ErrInvalidKey, key)
+	}
+}
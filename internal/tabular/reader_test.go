@@ -0,0 +1,72 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func buildWorkbook(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestReadCSV(t *testing.T) {
+	rows, err := Read([]byte("\xef\xbb\xbfemail;full_name\na@example.com;Иванов, Иван\n"))
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"email", "full_name"}, {"a@example.com", "Иванов, Иван"}}, rows)
+
+	rows, err = Read([]byte("email,plate\nb@example.com,A123BC77\nc@example.com\n"))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, []string{"c@example.com"}, rows[2])
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := buildWorkbook(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Жители" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId7" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/residents.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>email</t></si><si><t>plot</t></si><si><r><t>Пётр </t></r><r><t>Петров</t></r></si></sst>`,
+		"xl/worksheets/residents.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>p@example.com</t></is></c><c r="B2" t="s"><v>2</v></c><c r="C2"><v>12</v></c></row>
+</sheetData></worksheet>`,
+	})
+	require.Equal(t, FormatXLSX, DetectFormat(data))
+
+	rows, err := Read(data)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"email", "", "plot"},
+		{"p@example.com", "Пётр Петров", "12"},
+	}, rows)
+}
+
+func TestReadXLSXInvalid(t *testing.T) {
+	_, err := ReadXLSX([]byte("PK\x03\x04broken"))
+	require.ErrorIs(t, err, ErrInvalidWorkbook)
+
+	data := buildWorkbook(t, map[string]string{"docProps/app.xml": "<Properties/>"})
+	_, err = ReadXLSX(data)
+	require.ErrorIs(t, err, ErrInvalidWorkbook)
+
+	data = buildWorkbook(t, map[string]string{
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row><c t="s"><v>3</v></c></row></sheetData></worksheet>`,
+	})
+	_, err = ReadXLSX(data)
+	require.ErrorIs(t, err, ErrInvalidWorkbook)
+}
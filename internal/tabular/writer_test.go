@@ -0,0 +1,51 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"Email", "ФИО"},
+		{"a@example.com", "Иванов; <Иван> & Co"},
+		{"b@example.com", ""},
+	}
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		writer, err := NewWriter(&buf, format)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, writer.WriteRow(row))
+		}
+		require.NoError(t, writer.Close())
+
+		require.Equal(t, format, DetectFormat(buf.Bytes()))
+		got, err := Read(buf.Bytes())
+		require.NoError(t, err, format)
+		require.Equal(t, rows, got, format)
+	}
+
+	_, err := NewWriter(&bytes.Buffer{}, "pdf")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+	require.Equal(t, "AB", columnName(27))
+	require.Contains(t, ContentType(FormatXLSX), "spreadsheetml")
+}
+
+func TestWriterEscapesFormulas(t *testing.T) {
+	row := []string{"=HYPERLINK(\"http://x\")", "+7 999", "-1", "@SUM(A1)", "\tcmd", "\rcmd", "A123BC77", "a=b", ""}
+	want := []string{"'=HYPERLINK(\"http://x\")", "'+7 999", "'-1", "'@SUM(A1)", "'\tcmd", "'\rcmd", "A123BC77", "a=b", ""}
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		writer, err := NewWriter(&buf, format)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteRow(row))
+		require.NoError(t, writer.Close())
+
+		got, err := Read(buf.Bytes())
+		require.NoError(t, err, format)
+		require.Equal(t, [][]string{want}, got, format)
+	}
+}
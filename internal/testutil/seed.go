@@ -97,6 +97,7 @@ func SeedGuestRequest(t *testing.T, q *repo.Queries, residentID, actorID uuid.UU
 		Status:         "pending",
 		CreatedBy:      uuid.NullUUID{UUID: actorID, Valid: true},
 		UpdatedBy:      uuid.NullUUID{UUID: actorID, Valid: true},
+		GuestType:      "vehicle",
 	})
 	require.NoError(t, err)
 	return guest